@@ -0,0 +1,70 @@
+// Package builder constructs Mermaid diagrams programmatically.
+//
+// Each builder assembles the ir structures the parser would produce, so a
+// built graph can be rendered directly with gomd2svg.RenderGraph, skipping
+// parsing, or serialized back to Mermaid source with Mermaid.
+//
+// Builder methods return the receiver for chaining. The first invalid call
+// (for example an identifier the parser could not read back) is recorded
+// and reported by Build and Mermaid; later calls are still applied.
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/printer"
+)
+
+// base holds the graph under construction and the first recorded error.
+type base struct {
+	graph *ir.Graph
+	err   error
+}
+
+// newBase returns a base for a fresh graph of the given kind.
+func newBase(kind ir.DiagramKind) base {
+	graph := ir.NewGraph()
+	graph.Kind = kind
+	return base{graph: graph}
+}
+
+// fail records err unless an earlier error is already recorded.
+func (b *base) fail(format string, args ...any) {
+	if b.err == nil {
+		b.err = fmt.Errorf("builder: "+format, args...)
+	}
+}
+
+// checkID records an error if id cannot be written as a bare identifier.
+func (b *base) checkID(what, id string) bool {
+	if !printer.ValidID(id) {
+		b.fail("invalid %s ID %q (use letters, digits and underscores)", what, id)
+		return false
+	}
+	return true
+}
+
+// build returns the graph or the first recorded error.
+func (b *base) build() (*ir.Graph, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.graph, nil
+}
+
+// mermaid serializes the graph or returns the first recorded error.
+func (b *base) mermaid() (string, error) {
+	graph, err := b.build()
+	if err != nil {
+		return "", err
+	}
+	return printer.Print(graph)
+}
+
+// validWord reports whether text is a non-empty single token without
+// whitespace or Mermaid delimiters.
+func validWord(text string) bool {
+	return text != "" && !strings.ContainsAny(text, " \t\n<>{}[]()\":")
+}
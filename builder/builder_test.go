@@ -0,0 +1,40 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// mermaidFrom fetches the Mermaid source and checks that the parser
+// accepts it, returning both the text and the re-parsed graph.
+func mermaidFrom(t *testing.T, source func() (string, error)) (string, *ir.Graph) {
+	t.Helper()
+	text, err := source()
+	if err != nil {
+		t.Fatalf("Mermaid() error: %v", err)
+	}
+	parsed, err := parser.Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(Mermaid()) error: %v\n%s", err, text)
+	}
+	return text, parsed.Graph
+}
+
+func TestBuilderRecordsFirstError(t *testing.T) {
+	fc := NewFlowchart(ir.TopDown).
+		Node("bad id", "x", ir.Rectangle).
+		Node("also-bad", "y", ir.Rectangle)
+	_, err := fc.Build()
+	if err == nil {
+		t.Fatal("Build() expected error")
+	}
+	if !strings.Contains(err.Error(), "bad id") {
+		t.Errorf("error = %v, want first invalid ID", err)
+	}
+	if _, err := fc.Mermaid(); err == nil {
+		t.Error("Mermaid() expected error")
+	}
+}
@@ -0,0 +1,192 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// Relation identifies a class diagram relationship arrow.
+type Relation int
+
+const (
+	Inheritance Relation = iota // <|--
+	Composition                 // *--
+	Aggregation                 // o--
+	Association                 // -->
+	Dependency                  // ..>
+	Realization                 // ..|>
+	Link                        // --
+	DashedLink                  // ..
+)
+
+// RelationOption customizes a class relationship.
+type RelationOption func(*ir.Edge)
+
+// ClassDiagram builds a class diagram.
+type ClassDiagram struct {
+	base
+}
+
+// NewClassDiagram returns an empty class diagram builder.
+func NewClassDiagram() *ClassDiagram {
+	return &ClassDiagram{base: newBase(ir.Class)}
+}
+
+// Direction sets the layout direction.
+func (cb *ClassDiagram) Direction(dir ir.Direction) *ClassDiagram {
+	cb.graph.Direction = dir
+	return cb
+}
+
+// Class declares a class with optional members. Calling Class again for
+// the same ID appends members.
+func (cb *ClassDiagram) Class(id string, members ...ir.ClassMember) *ClassDiagram {
+	if !cb.ensure(id) {
+		return cb
+	}
+	for _, member := range members {
+		if strings.ContainsAny(member.Name+member.Type+member.Params, "\n{}") {
+			cb.fail("class %q member %q must be a single line without braces", id, member.Name)
+			return cb
+		}
+		if member.IsMethod {
+			cb.graph.Members[id].Methods = append(cb.graph.Members[id].Methods, member)
+		} else {
+			cb.graph.Members[id].Attributes = append(cb.graph.Members[id].Attributes, member)
+		}
+	}
+	return cb
+}
+
+// Annotate sets a stereotype such as "interface" on a class.
+func (cb *ClassDiagram) Annotate(id, annotation string) *ClassDiagram {
+	if !cb.ensure(id) {
+		return cb
+	}
+	if !validWord(annotation) {
+		cb.fail("invalid annotation %q", annotation)
+		return cb
+	}
+	cb.graph.Annotations[id] = annotation
+	return cb
+}
+
+// Namespace groups already-declared or new classes under a name.
+func (cb *ClassDiagram) Namespace(name string, classIDs ...string) *ClassDiagram {
+	if !cb.checkID("namespace", name) {
+		return cb
+	}
+	ns := &ir.Namespace{Name: name}
+	for _, id := range classIDs {
+		if !cb.ensure(id) {
+			return cb
+		}
+		ns.Classes = append(ns.Classes, id)
+	}
+	cb.graph.Namespaces = append(cb.graph.Namespaces, ns)
+	return cb
+}
+
+// Relate connects two classes with the given relationship.
+func (cb *ClassDiagram) Relate(from, to string, rel Relation, opts ...RelationOption) *ClassDiagram {
+	if !cb.ensure(from) || !cb.ensure(to) {
+		return cb
+	}
+	edge := relationEdge(from, to, rel)
+	for _, opt := range opts {
+		opt(edge)
+	}
+	cb.graph.Edges = append(cb.graph.Edges, edge)
+	return cb
+}
+
+// Note attaches a note to a class, or a floating note if target is empty.
+func (cb *ClassDiagram) Note(target, text string) *ClassDiagram {
+	if target != "" && !cb.ensure(target) {
+		return cb
+	}
+	if strings.ContainsAny(text, "\"\n") {
+		cb.fail("note text %q must be a single line without double quotes", text)
+		return cb
+	}
+	cb.graph.Notes = append(cb.graph.Notes, &ir.DiagramNote{Text: text, Target: target})
+	return cb
+}
+
+// Build returns the assembled graph.
+func (cb *ClassDiagram) Build() (*ir.Graph, error) {
+	return cb.build()
+}
+
+// Mermaid returns the class diagram as Mermaid source.
+func (cb *ClassDiagram) Mermaid() (string, error) {
+	return cb.mermaid()
+}
+
+// ensure declares a class node and its member list on first use.
+func (cb *ClassDiagram) ensure(id string) bool {
+	if !cb.checkID("class", id) {
+		return false
+	}
+	cb.graph.EnsureNode(id, nil, nil)
+	if cb.graph.Members[id] == nil {
+		cb.graph.Members[id] = &ir.ClassMembers{}
+	}
+	return true
+}
+
+// RelationLabel sets the text shown on the relationship.
+func RelationLabel(label string) RelationOption {
+	return func(edge *ir.Edge) {
+		edge.Label = &label
+	}
+}
+
+// Cardinality sets the multiplicity shown at each end, for example "1"
+// and "*". Empty strings leave that end unlabelled.
+func Cardinality(start, end string) RelationOption {
+	return func(edge *ir.Edge) {
+		if start != "" {
+			edge.StartLabel = &start
+		}
+		if end != "" {
+			edge.EndLabel = &end
+		}
+	}
+}
+
+// relationEdge builds the edge the class parser produces for rel.
+func relationEdge(from, to string, rel Relation) *ir.Edge {
+	edge := &ir.Edge{From: from, To: to, Style: ir.Solid}
+	setStart := func(kind ir.EdgeArrowhead) {
+		edge.Directed = true
+		edge.ArrowStart = true
+		edge.ArrowStartKind = &kind
+	}
+	setEnd := func(kind ir.EdgeArrowhead) {
+		edge.Directed = true
+		edge.ArrowEnd = true
+		edge.ArrowEndKind = &kind
+	}
+	switch rel {
+	case Inheritance:
+		setStart(ir.ClosedTriangle)
+	case Composition:
+		setStart(ir.FilledDiamond)
+	case Aggregation:
+		setStart(ir.OpenDiamond)
+	case Association:
+		setEnd(ir.OpenTriangle)
+	case Dependency:
+		setEnd(ir.ClassDependency)
+		edge.Style = ir.Dotted
+	case Realization:
+		setEnd(ir.ClosedTriangle)
+		edge.Style = ir.Dotted
+	case DashedLink:
+		edge.Style = ir.Dotted
+	case Link:
+	}
+	return edge
+}
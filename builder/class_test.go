@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestClassBuilder(t *testing.T) {
+	cb := NewClassDiagram().
+		Class("Animal",
+			ir.ClassMember{Name: "name", Type: "String", Visibility: ir.VisPublic},
+			ir.ClassMember{Name: "eat", Params: "food", Type: "bool", IsMethod: true, Visibility: ir.VisPublic},
+		).
+		Annotate("Animal", "interface").
+		Relate("Animal", "Dog", Inheritance).
+		Relate("Dog", "Leg", Composition, RelationLabel("has"), Cardinality("1", "many")).
+		Relate("Dog", "Bone", Dependency).
+		Note("Dog", "good boy")
+
+	if _, err := cb.Build(); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	_, parsed := mermaidFrom(t, cb.Mermaid)
+	members := parsed.Members["Animal"]
+	if members == nil || len(members.Attributes) != 1 || len(members.Methods) != 1 {
+		t.Fatalf("re-parsed members = %+v", members)
+	}
+	if parsed.Annotations["Animal"] != "interface" {
+		t.Errorf("annotation = %q", parsed.Annotations["Animal"])
+	}
+	if len(parsed.Edges) != 3 {
+		t.Fatalf("Edges = %d, want 3", len(parsed.Edges))
+	}
+	comp := parsed.Edges[1]
+	if comp.ArrowStartKind == nil || *comp.ArrowStartKind != ir.FilledDiamond {
+		t.Error("composition arrowhead lost")
+	}
+	if comp.EndLabel == nil || *comp.EndLabel != "many" {
+		t.Error("cardinality lost")
+	}
+	if parsed.Edges[2].Style != ir.Dotted {
+		t.Error("dependency should be dotted")
+	}
+	if len(parsed.Notes) != 1 {
+		t.Errorf("Notes = %d, want 1", len(parsed.Notes))
+	}
+}
+
+func TestClassNoteRejectsQuotes(t *testing.T) {
+	if _, err := NewClassDiagram().Note("A", `say "hi"`).Build(); err == nil {
+		t.Error("Build() expected error for quoted note text")
+	}
+}
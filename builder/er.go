@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// ERDiagram builds an entity relationship diagram.
+type ERDiagram struct {
+	base
+}
+
+// NewERDiagram returns an empty ER diagram builder.
+func NewERDiagram() *ERDiagram {
+	return &ERDiagram{base: newBase(ir.Er)}
+}
+
+// Direction sets the layout direction.
+func (eb *ERDiagram) Direction(dir ir.Direction) *ERDiagram {
+	eb.graph.Direction = dir
+	return eb
+}
+
+// Entity declares an entity with an optional display alias and
+// attributes. Calling Entity again for the same ID appends attributes.
+func (eb *ERDiagram) Entity(id, alias string, attrs ...ir.EntityAttribute) *ERDiagram {
+	entity := eb.ensure(id)
+	if entity == nil {
+		return eb
+	}
+	if strings.ContainsAny(alias, "\"\n") {
+		eb.fail("entity %q alias must be a single line without double quotes", id)
+		return eb
+	}
+	if alias != "" {
+		entity.Label = alias
+	}
+	for _, attr := range attrs {
+		if !validWord(attr.Type) || !validWord(attr.Name) || strings.ContainsAny(attr.Comment, "\"\n") {
+			eb.fail("entity %q has invalid attribute %q", id, attr.Name)
+			return eb
+		}
+		entity.Attributes = append(entity.Attributes, attr)
+	}
+	return eb
+}
+
+// Relationship connects two entities with crow's-foot cardinalities
+// (ir.DecCrowsFootOne, ZeroOne, Many or ZeroMany). Non-identifying
+// relationships are drawn dashed.
+func (eb *ERDiagram) Relationship(from string, fromCard ir.EdgeDecoration, to string, toCard ir.EdgeDecoration, label string, identifying bool) *ERDiagram {
+	if eb.ensure(from) == nil || eb.ensure(to) == nil {
+		return eb
+	}
+	if strings.Contains(label, "\n") {
+		eb.fail("relationship label %q must be a single line", label)
+		return eb
+	}
+	style := ir.Solid
+	if !identifying {
+		style = ir.Dotted
+	}
+	eb.graph.Edges = append(eb.graph.Edges, &ir.Edge{
+		From:            from,
+		To:              to,
+		Label:           &label,
+		StartDecoration: &fromCard,
+		EndDecoration:   &toCard,
+		Style:           style,
+	})
+	return eb
+}
+
+// Build returns the assembled graph.
+func (eb *ERDiagram) Build() (*ir.Graph, error) {
+	return eb.build()
+}
+
+// Mermaid returns the ER diagram as Mermaid source.
+func (eb *ERDiagram) Mermaid() (string, error) {
+	return eb.mermaid()
+}
+
+// ensure returns the entity with the given ID, declaring it on first use.
+func (eb *ERDiagram) ensure(id string) *ir.Entity {
+	if !eb.checkID("entity", id) {
+		return nil
+	}
+	entity, ok := eb.graph.Entities[id]
+	if !ok {
+		entity = &ir.Entity{ID: id}
+		eb.graph.Entities[id] = entity
+	}
+	eb.graph.EnsureNode(id, nil, nil)
+	return entity
+}
@@ -0,0 +1,41 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestERBuilder(t *testing.T) {
+	eb := NewERDiagram().
+		Entity("CUSTOMER", "Customer",
+			ir.EntityAttribute{Type: "string", Name: "id", Keys: []ir.AttributeKey{ir.KeyPrimary}},
+			ir.EntityAttribute{Type: "string", Name: "email", Comment: "login"},
+		).
+		Relationship("CUSTOMER", ir.DecCrowsFootOne, "ORDER", ir.DecCrowsFootZeroMany, "places", true).
+		Relationship("ORDER", ir.DecCrowsFootMany, "LINE_ITEM", ir.DecCrowsFootMany, "contains items", false)
+
+	if _, err := eb.Build(); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	_, parsed := mermaidFrom(t, eb.Mermaid)
+	customer := parsed.Entities["CUSTOMER"]
+	if customer == nil || customer.Label != "Customer" || len(customer.Attributes) != 2 {
+		t.Fatalf("CUSTOMER = %+v", customer)
+	}
+	if customer.Attributes[1].Comment != "login" {
+		t.Errorf("comment = %q", customer.Attributes[1].Comment)
+	}
+	if len(parsed.Edges) != 2 {
+		t.Fatalf("Edges = %d, want 2", len(parsed.Edges))
+	}
+	if *parsed.Edges[0].EndDecoration != ir.DecCrowsFootZeroMany {
+		t.Error("end cardinality lost")
+	}
+	if parsed.Edges[1].Style != ir.Dotted {
+		t.Error("non-identifying relationship should be dotted")
+	}
+	if *parsed.Edges[1].Label != "contains items" {
+		t.Errorf("label = %q", *parsed.Edges[1].Label)
+	}
+}
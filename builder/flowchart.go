@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// Flowchart builds a flowchart diagram.
+type Flowchart struct {
+	base
+	subgraphStack []int
+}
+
+// EdgeOption customizes a flowchart edge.
+type EdgeOption func(*ir.Edge)
+
+// NewFlowchart returns a flowchart builder laid out in the given direction.
+func NewFlowchart(dir ir.Direction) *Flowchart {
+	fc := &Flowchart{base: newBase(ir.Flowchart)}
+	fc.graph.Direction = dir
+	return fc
+}
+
+// Node declares a node with a label and shape. Declaring an existing node
+// updates its label and shape.
+func (fc *Flowchart) Node(id, label string, shape ir.NodeShape) *Flowchart {
+	if !fc.checkID("node", id) {
+		return fc
+	}
+	fc.graph.EnsureNode(id, &label, &shape)
+	fc.addToSubgraphs(id)
+	return fc
+}
+
+// Edge connects two nodes, creating them with default labels if needed.
+// Edges are directed solid arrows unless changed by options.
+func (fc *Flowchart) Edge(from, to string, opts ...EdgeOption) *Flowchart {
+	if !fc.checkID("node", from) || !fc.checkID("node", to) {
+		return fc
+	}
+	for _, id := range []string{from, to} {
+		fc.graph.EnsureNode(id, nil, nil)
+		fc.addToSubgraphs(id)
+	}
+	edge := &ir.Edge{
+		From:     from,
+		To:       to,
+		Directed: true,
+		ArrowEnd: true,
+		Style:    ir.Solid,
+	}
+	for _, opt := range opts {
+		opt(edge)
+	}
+	fc.graph.Edges = append(fc.graph.Edges, edge)
+	return fc
+}
+
+// Subgraph groups the nodes declared inside fn. An empty id creates an
+// anonymous subgraph identified only by its label.
+func (fc *Flowchart) Subgraph(id, label string, fn func(*Flowchart)) *Flowchart {
+	sg := &ir.Subgraph{Label: label}
+	if id != "" {
+		if !fc.checkID("subgraph", id) {
+			return fc
+		}
+		sg.ID = &id
+		if label == "" {
+			sg.Label = id
+		}
+	}
+	fc.graph.Subgraphs = append(fc.graph.Subgraphs, sg)
+	fc.subgraphStack = append(fc.subgraphStack, len(fc.graph.Subgraphs)-1)
+	fn(fc)
+	fc.subgraphStack = fc.subgraphStack[:len(fc.subgraphStack)-1]
+	return fc
+}
+
+// Direction sets the direction of the innermost open subgraph, or of the
+// whole chart when called outside Subgraph.
+func (fc *Flowchart) Direction(dir ir.Direction) *Flowchart {
+	if len(fc.subgraphStack) == 0 {
+		fc.graph.Direction = dir
+		return fc
+	}
+	fc.graph.Subgraphs[fc.subgraphStack[len(fc.subgraphStack)-1]].Direction = &dir
+	return fc
+}
+
+// Build returns the assembled graph.
+func (fc *Flowchart) Build() (*ir.Graph, error) {
+	return fc.build()
+}
+
+// Mermaid returns the flowchart as Mermaid source.
+func (fc *Flowchart) Mermaid() (string, error) {
+	return fc.mermaid()
+}
+
+// addToSubgraphs adds a node to every open subgraph, as the parser does.
+func (fc *Flowchart) addToSubgraphs(id string) {
+	for _, idx := range fc.subgraphStack {
+		sg := fc.graph.Subgraphs[idx]
+		found := false
+		for _, existing := range sg.Nodes {
+			if existing == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sg.Nodes = append(sg.Nodes, id)
+		}
+	}
+}
+
+// WithLabel sets the edge label.
+func WithLabel(label string) EdgeOption {
+	return func(edge *ir.Edge) {
+		edge.Label = &label
+	}
+}
+
+// WithStyle sets the line style (solid, dotted or thick).
+func WithStyle(style ir.EdgeStyle) EdgeOption {
+	return func(edge *ir.Edge) {
+		edge.Style = style
+	}
+}
+
+// Undirected removes the arrowhead.
+func Undirected() EdgeOption {
+	return func(edge *ir.Edge) {
+		edge.Directed = false
+		edge.ArrowEnd = false
+		edge.ArrowStart = false
+	}
+}
+
+// Bidirectional draws arrowheads at both ends.
+func Bidirectional() EdgeOption {
+	return func(edge *ir.Edge) {
+		edge.Directed = true
+		edge.ArrowStart = true
+		edge.ArrowEnd = true
+	}
+}
@@ -0,0 +1,48 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestFlowchartBuilder(t *testing.T) {
+	fc := NewFlowchart(ir.LeftRight).
+		Node("A", "Start", ir.Stadium).
+		Node("B", `Is it "ok"?`, ir.Diamond).
+		Edge("A", "B").
+		Edge("B", "C", WithLabel("yes | no"), WithStyle(ir.Dotted)).
+		Subgraph("grp", "Group", func(sub *Flowchart) {
+			sub.Direction(ir.TopDown)
+			sub.Edge("C", "D", Undirected())
+		})
+
+	graph, err := fc.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if len(graph.Edges) != 3 {
+		t.Errorf("Edges = %d, want 3", len(graph.Edges))
+	}
+	if got := graph.Subgraphs[0].Nodes; len(got) != 2 {
+		t.Errorf("subgraph nodes = %v, want [C D]", got)
+	}
+
+	text, parsed := mermaidFrom(t, fc.Mermaid)
+	if !strings.Contains(text, "flowchart LR") {
+		t.Errorf("missing header:\n%s", text)
+	}
+	if got := parsed.Nodes["B"].Label; got != `Is it "ok"?` {
+		t.Errorf("re-parsed label = %q", got)
+	}
+	if got := *parsed.Edges[1].Label; got != "yes | no" {
+		t.Errorf("re-parsed edge label = %q", got)
+	}
+	if parsed.Edges[2].Directed {
+		t.Error("undirected edge re-parsed as directed")
+	}
+	if len(parsed.Subgraphs) != 1 || len(parsed.Subgraphs[0].Nodes) != 2 {
+		t.Errorf("re-parsed subgraphs = %+v", parsed.Subgraphs)
+	}
+}
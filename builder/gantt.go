@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// TaskOption customizes a gantt task.
+type TaskOption func(*ir.GanttTask)
+
+// Gantt builds a gantt chart.
+type Gantt struct {
+	base
+	section *ir.GanttSection
+}
+
+// NewGantt returns an empty gantt chart builder using the YYYY-MM-DD date
+// format.
+func NewGantt() *Gantt {
+	gb := &Gantt{base: newBase(ir.Gantt)}
+	gb.graph.GanttDateFormat = "YYYY-MM-DD"
+	return gb
+}
+
+// Title sets the chart title.
+func (gb *Gantt) Title(title string) *Gantt {
+	if strings.Contains(title, "\n") {
+		gb.fail("title must be a single line")
+		return gb
+	}
+	gb.graph.GanttTitle = title
+	return gb
+}
+
+// DateFormat sets the format used to read task dates.
+func (gb *Gantt) DateFormat(format string) *Gantt {
+	gb.graph.GanttDateFormat = format
+	return gb
+}
+
+// AxisFormat sets the strftime-style format of axis tick labels.
+func (gb *Gantt) AxisFormat(format string) *Gantt {
+	gb.graph.GanttAxisFormat = format
+	return gb
+}
+
+// Excludes skips the given dates or day names ("weekends", "sunday")
+// when computing task durations.
+func (gb *Gantt) Excludes(values ...string) *Gantt {
+	gb.graph.GanttExcludes = append(gb.graph.GanttExcludes, values...)
+	return gb
+}
+
+// InclusiveEndDates makes explicit end dates include the whole end day.
+func (gb *Gantt) InclusiveEndDates() *Gantt {
+	gb.graph.GanttInclusiveEndDates = true
+	return gb
+}
+
+// TopAxis repeats the date labels above the chart.
+func (gb *Gantt) TopAxis() *Gantt {
+	gb.graph.GanttTopAxis = true
+	return gb
+}
+
+// Link makes a task's bar a link to url.
+func (gb *Gantt) Link(taskID, url string) *Gantt {
+	if strings.ContainsAny(url, "\"\n") {
+		gb.fail("link URL %q must not contain quotes or newlines", url)
+		return gb
+	}
+	gb.graph.NodeLinks[taskID] = &ir.NodeLink{URL: url}
+	return gb
+}
+
+// Call binds a JavaScript callback to a task's bar. Rendered SVGs record
+// it in data attributes for the host page to bind.
+func (gb *Gantt) Call(taskID, function string, args ...string) *Gantt {
+	if function == "" || strings.ContainsAny(function, "() \n") {
+		gb.fail("callback name %q must be a non-empty identifier", function)
+		return gb
+	}
+	if gb.graph.GanttCallbacks == nil {
+		gb.graph.GanttCallbacks = make(map[string]*ir.GanttCallback)
+	}
+	gb.graph.GanttCallbacks[taskID] = &ir.GanttCallback{Name: function, Args: args}
+	return gb
+}
+
+// Section starts a new section; tasks added afterwards belong to it.
+func (gb *Gantt) Section(title string) *Gantt {
+	if title == "" || strings.Contains(title, "\n") {
+		gb.fail("section title %q must be a non-empty single line", title)
+		return gb
+	}
+	gb.section = &ir.GanttSection{Title: title}
+	gb.graph.GanttSections = append(gb.graph.GanttSections, gb.section)
+	return gb
+}
+
+// Task adds a task to the current section. Tasks need a duration or end
+// date, and a start unless they follow the previous task.
+func (gb *Gantt) Task(label string, opts ...TaskOption) *Gantt {
+	if label == "" || strings.ContainsAny(label, ":\n") {
+		gb.fail("task label %q must be a non-empty single line without colons", label)
+		return gb
+	}
+	task := &ir.GanttTask{Label: label}
+	for _, opt := range opts {
+		opt(task)
+	}
+	if task.EndStr == "" && task.UntilID == "" {
+		gb.fail("task %q needs a duration, end date or until", label)
+		return gb
+	}
+	if task.ID != "" && !gb.checkID("task", task.ID) {
+		return gb
+	}
+	if gb.section == nil {
+		gb.section = &ir.GanttSection{}
+		gb.graph.GanttSections = append(gb.graph.GanttSections, gb.section)
+	}
+	gb.section.Tasks = append(gb.section.Tasks, task)
+	return gb
+}
+
+// Build returns the assembled graph.
+func (gb *Gantt) Build() (*ir.Graph, error) {
+	return gb.build()
+}
+
+// Mermaid returns the gantt chart as Mermaid source.
+func (gb *Gantt) Mermaid() (string, error) {
+	return gb.mermaid()
+}
+
+// TaskID names a task so others can refer to it with After or Until.
+func TaskID(id string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.ID = id
+	}
+}
+
+// Start sets an explicit start date in the chart's date format.
+func Start(date string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.StartStr = date
+		task.AfterIDs = nil
+	}
+}
+
+// After starts the task when all the given tasks have finished.
+func After(ids ...string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.AfterIDs = ids
+		task.StartStr = "after " + strings.Join(ids, " ")
+	}
+}
+
+// Until ends the task when the given task starts.
+func Until(id string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.UntilID = id
+	}
+}
+
+// Duration sets the task length, for example "3d", "1w", "1.5h" or "30m".
+func Duration(dur string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.EndStr = dur
+	}
+}
+
+// End sets an explicit end date in the chart's date format.
+func End(date string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.EndStr = date
+	}
+}
+
+// Tags marks the task as done, active, crit or milestone, or as a vert
+// marker line across the chart.
+func Tags(tags ...string) TaskOption {
+	return func(task *ir.GanttTask) {
+		task.Tags = append(task.Tags, tags...)
+	}
+}
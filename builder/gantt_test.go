@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGanttBuilder(t *testing.T) {
+	gb := NewGantt().
+		Title("Release").
+		Excludes("weekends").
+		Section("Build").
+		Task("Design", Tags("done"), TaskID("des"), Start("2024-01-01"), Duration("3d")).
+		Task("Code", TaskID("code"), After("des"), Duration("5d")).
+		Section("Ship").
+		Task("Release", Tags("milestone"), Start("2024-01-15"), Duration("0d"))
+
+	if _, err := gb.Build(); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	text, parsed := mermaidFrom(t, gb.Mermaid)
+	if !strings.Contains(text, "Code : code, after des, 5d") {
+		t.Errorf("unexpected task line:\n%s", text)
+	}
+	if parsed.GanttTitle != "Release" || len(parsed.GanttSections) != 2 {
+		t.Fatalf("re-parsed title %q, sections %d", parsed.GanttTitle, len(parsed.GanttSections))
+	}
+	code := parsed.GanttSections[0].Tasks[1]
+	if code.ID != "code" || len(code.AfterIDs) != 1 || code.AfterIDs[0] != "des" || code.EndStr != "5d" {
+		t.Errorf("Code task = %+v", code)
+	}
+}
+
+func TestGanttTaskValidation(t *testing.T) {
+	if _, err := NewGantt().Task("a: b", Duration("1d")).Build(); err == nil {
+		t.Error("expected error for colon in label")
+	}
+	if _, err := NewGantt().Task("a").Build(); err == nil {
+		t.Error("expected error for missing duration")
+	}
+}
+
+func TestGanttClicks(t *testing.T) {
+	gb := NewGantt().
+		TopAxis().
+		InclusiveEndDates().
+		Task("Design", TaskID("des"), Start("2024-01-01"), End("2024-01-03")).
+		Task("Freeze", Tags("vert"), Start("2024-01-02"), Duration("0d")).
+		Link("des", "https://example.com").
+		Call("des", "show", "des")
+
+	text, parsed := mermaidFrom(t, gb.Mermaid)
+	if !parsed.GanttTopAxis || !parsed.GanttInclusiveEndDates {
+		t.Errorf("options lost in:\n%s", text)
+	}
+	if link := parsed.NodeLinks["des"]; link == nil || link.URL != "https://example.com" {
+		t.Errorf("link lost in:\n%s", text)
+	}
+	if callback := parsed.GanttCallbacks["des"]; callback == nil || callback.Name != "show" {
+		t.Errorf("callback lost in:\n%s", text)
+	}
+	if _, err := NewGantt().Call("des", "show()").Build(); err == nil {
+		t.Error("expected error for parentheses in callback name")
+	}
+}
@@ -0,0 +1,210 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// Sequence builds a sequence diagram.
+type Sequence struct {
+	base
+	index  map[string]int
+	frames []ir.SeqFrameKind
+}
+
+// NewSequence returns an empty sequence diagram builder.
+func NewSequence() *Sequence {
+	return &Sequence{
+		base:  newBase(ir.Sequence),
+		index: make(map[string]int),
+	}
+}
+
+// Participant declares a participant box. alias may be empty.
+func (sb *Sequence) Participant(id, alias string) *Sequence {
+	return sb.ParticipantKind(id, alias, ir.ParticipantBox)
+}
+
+// Actor declares a stick-figure participant. alias may be empty.
+func (sb *Sequence) Actor(id, alias string) *Sequence {
+	return sb.ParticipantKind(id, alias, ir.ActorStickFigure)
+}
+
+// ParticipantKind declares a participant of any kind.
+func (sb *Sequence) ParticipantKind(id, alias string, kind ir.SeqParticipantKind) *Sequence {
+	participant := sb.ensure(id)
+	if participant == nil {
+		return sb
+	}
+	if strings.ContainsAny(alias, "\n") {
+		sb.fail("participant %q alias must be a single line", id)
+		return sb
+	}
+	participant.Alias = alias
+	participant.Kind = kind
+	return sb
+}
+
+// Link attaches a clickable link to a participant.
+func (sb *Sequence) Link(id, label, url string) *Sequence {
+	participant := sb.ensure(id)
+	if participant == nil {
+		return sb
+	}
+	if strings.Contains(label, "@") {
+		sb.fail("link label %q must not contain @", label)
+		return sb
+	}
+	participant.Links = append(participant.Links, ir.SeqLink{Label: label, URL: url})
+	return sb
+}
+
+// Autonumber numbers messages in order.
+func (sb *Sequence) Autonumber() *Sequence {
+	sb.graph.Autonumber = true
+	return sb
+}
+
+// Wrap wraps long message and note text, like the %%{wrap}%% directive.
+func (sb *Sequence) Wrap() *Sequence {
+	sb.graph.SeqWrap = true
+	return sb
+}
+
+// Message sends a message between participants, declaring them if needed.
+func (sb *Sequence) Message(from, to, text string, kind ir.SeqMessageKind) *Sequence {
+	if sb.ensure(from) == nil || sb.ensure(to) == nil {
+		return sb
+	}
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{
+		Kind: ir.EvMessage,
+		Message: &ir.SeqMessage{
+			From: from,
+			To:   to,
+			Text: text,
+			Kind: kind,
+		},
+	})
+	return sb
+}
+
+// Note places a note beside or over one or more participants.
+func (sb *Sequence) Note(pos ir.SeqNotePosition, text string, participants ...string) *Sequence {
+	if len(participants) == 0 {
+		sb.fail("note needs at least one participant")
+		return sb
+	}
+	for _, id := range participants {
+		if sb.ensure(id) == nil {
+			return sb
+		}
+	}
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{
+		Kind: ir.EvNote,
+		Note: &ir.SeqNote{
+			Position:     pos,
+			Participants: participants,
+			Text:         text,
+		},
+	})
+	return sb
+}
+
+// Activate starts an activation bar on a participant.
+func (sb *Sequence) Activate(id string) *Sequence {
+	return sb.targetEvent(ir.EvActivate, id)
+}
+
+// Deactivate ends the innermost activation bar on a participant.
+func (sb *Sequence) Deactivate(id string) *Sequence {
+	return sb.targetEvent(ir.EvDeactivate, id)
+}
+
+// Frame wraps the events added by fn in a combined fragment (loop, alt,
+// opt, par, critical or break). Use Else inside fn to add dividers.
+func (sb *Sequence) Frame(kind ir.SeqFrameKind, label string, fn func(*Sequence)) *Sequence {
+	if kind == ir.FrameRect {
+		sb.fail("use Rect for highlighted regions")
+		return sb
+	}
+	return sb.frame(&ir.SeqFrame{Kind: kind, Label: label}, fn)
+}
+
+// Rect highlights the events added by fn with a background color such as
+// "rgb(200, 220, 255)".
+func (sb *Sequence) Rect(color string, fn func(*Sequence)) *Sequence {
+	return sb.frame(&ir.SeqFrame{Kind: ir.FrameRect, Color: color}, fn)
+}
+
+// Else adds a divider to the innermost frame: "else" for alt, "and" for
+// par and "option" for critical.
+func (sb *Sequence) Else(label string) *Sequence {
+	if len(sb.frames) == 0 {
+		sb.fail("Else called outside a frame")
+		return sb
+	}
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{
+		Kind:  ir.EvFrameMiddle,
+		Frame: &ir.SeqFrame{Label: label},
+	})
+	return sb
+}
+
+// Box groups participants under a labelled, colored box. color may be
+// empty or any CSS color the renderer accepts.
+func (sb *Sequence) Box(label, color string, participants ...string) *Sequence {
+	box := &ir.SeqBox{Label: label, Color: color}
+	for _, id := range participants {
+		if sb.ensure(id) == nil {
+			return sb
+		}
+		box.Participants = append(box.Participants, id)
+	}
+	sb.graph.Boxes = append(sb.graph.Boxes, box)
+	return sb
+}
+
+// Build returns the assembled graph.
+func (sb *Sequence) Build() (*ir.Graph, error) {
+	return sb.build()
+}
+
+// Mermaid returns the sequence diagram as Mermaid source.
+func (sb *Sequence) Mermaid() (string, error) {
+	return sb.mermaid()
+}
+
+// frame appends start and end events around fn.
+func (sb *Sequence) frame(frame *ir.SeqFrame, fn func(*Sequence)) *Sequence {
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{Kind: ir.EvFrameStart, Frame: frame})
+	sb.frames = append(sb.frames, frame.Kind)
+	fn(sb)
+	sb.frames = sb.frames[:len(sb.frames)-1]
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{Kind: ir.EvFrameEnd})
+	return sb
+}
+
+// targetEvent appends an event that applies to a single participant.
+func (sb *Sequence) targetEvent(kind ir.SeqEventKind, id string) *Sequence {
+	if sb.ensure(id) == nil {
+		return sb
+	}
+	sb.graph.Events = append(sb.graph.Events, &ir.SeqEvent{Kind: kind, Target: id})
+	return sb
+}
+
+// ensure returns the participant with the given ID, declaring it on first
+// use. It returns nil and records an error for invalid IDs.
+func (sb *Sequence) ensure(id string) *ir.SeqParticipant {
+	if idx, ok := sb.index[id]; ok {
+		return sb.graph.Participants[idx]
+	}
+	if !sb.checkID("participant", id) {
+		return nil
+	}
+	participant := &ir.SeqParticipant{ID: id, Kind: ir.ParticipantBox}
+	sb.index[id] = len(sb.graph.Participants)
+	sb.graph.Participants = append(sb.graph.Participants, participant)
+	return participant
+}
@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestSequenceBuilder(t *testing.T) {
+	sb := NewSequence().
+		Actor("U", "User").
+		Participant("S", "Server").
+		Autonumber().
+		Message("U", "S", "request", ir.MsgSolidArrow).
+		Activate("S").
+		Frame(ir.FrameAlt, "ok", func(seq *Sequence) {
+			seq.Message("S", "U", "data", ir.MsgDottedArrow)
+			seq.Else("failure")
+			seq.Message("S", "U", "error", ir.MsgDottedArrow)
+		}).
+		Deactivate("S").
+		Note(ir.NoteOver, "done", "U", "S")
+
+	graph, err := sb.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	_, parsed := mermaidFrom(t, sb.Mermaid)
+	if len(parsed.Participants) != 2 || parsed.Participants[0].Kind != ir.ActorStickFigure {
+		t.Errorf("re-parsed participants = %+v", parsed.Participants)
+	}
+	if len(parsed.Events) != len(graph.Events) {
+		t.Errorf("re-parsed events = %d, want %d", len(parsed.Events), len(graph.Events))
+	}
+	if !parsed.Autonumber {
+		t.Error("autonumber lost")
+	}
+}
+
+func TestSequenceElseOutsideFrame(t *testing.T) {
+	if _, err := NewSequence().Else("x").Build(); err == nil {
+		t.Error("Build() expected error for Else outside a frame")
+	}
+}
+
+func TestSequenceWrap(t *testing.T) {
+	graph, err := NewSequence().Wrap().Message("A", "B", "hi", ir.MsgSolidArrow).Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !graph.SeqWrap {
+		t.Error("SeqWrap = false, want true")
+	}
+}
@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// StartEnd is the pseudo-state written as [*]. As a transition source it
+// is the initial state; as a target it is the final state.
+const StartEnd = "[*]"
+
+// Note positions accepted by StateDiagram.Note.
+const (
+	NoteLeftOf  = "left of"
+	NoteRightOf = "right of"
+)
+
+// StateDiagram builds a state diagram.
+type StateDiagram struct {
+	base
+	current *ir.Graph
+}
+
+// NewStateDiagram returns an empty state diagram builder.
+func NewStateDiagram() *StateDiagram {
+	sd := &StateDiagram{base: newBase(ir.State)}
+	sd.current = sd.graph
+	return sd
+}
+
+// Direction sets the layout direction of the current state machine.
+func (sd *StateDiagram) Direction(dir ir.Direction) *StateDiagram {
+	sd.current.Direction = dir
+	return sd
+}
+
+// State declares a state, optionally with a description shown in place
+// of its ID.
+func (sd *StateDiagram) State(id, description string) *StateDiagram {
+	if !sd.ensure(id) {
+		return sd
+	}
+	if description != "" {
+		sd.current.StateDescriptions[id] = description
+	}
+	return sd
+}
+
+// Annotate marks a state as a choice, fork or join pseudo-state.
+func (sd *StateDiagram) Annotate(id string, ann ir.StateAnnotation) *StateDiagram {
+	if !sd.ensure(id) {
+		return sd
+	}
+	sd.current.StateAnnotations[id] = ann
+	return sd
+}
+
+// Transition connects two states. Use StartEnd for the initial and final
+// pseudo-states; label may be empty.
+func (sd *StateDiagram) Transition(from, to, label string) *StateDiagram {
+	from = starID(from, true)
+	to = starID(to, false)
+	if !sd.ensure(from) || !sd.ensure(to) {
+		return sd
+	}
+	if strings.Contains(label, "\n") {
+		sd.fail("transition label %q must be a single line", label)
+		return sd
+	}
+	edge := &ir.Edge{From: from, To: to, Directed: true, ArrowEnd: true}
+	if label != "" {
+		edge.Label = &label
+	}
+	sd.current.Edges = append(sd.current.Edges, edge)
+	return sd
+}
+
+// Composite declares a state containing the nested state machine built
+// by each function in regions. More than one function creates concurrent
+// regions.
+func (sd *StateDiagram) Composite(id string, regions ...func(*StateDiagram)) *StateDiagram {
+	if !sd.ensure(id) {
+		return sd
+	}
+	if len(regions) == 0 {
+		sd.fail("composite state %q needs at least one region", id)
+		return sd
+	}
+	cs := &ir.CompositeState{ID: id, Label: id}
+	parent := sd.current
+	for _, fn := range regions {
+		inner := ir.NewGraph()
+		inner.Kind = ir.State
+		sd.current = inner
+		fn(sd)
+		if len(regions) == 1 {
+			cs.Inner = inner
+		} else {
+			cs.Regions = append(cs.Regions, inner)
+		}
+	}
+	sd.current = parent
+	parent.CompositeStates[id] = cs
+	return sd
+}
+
+// Note attaches a note to a state. position is NoteLeftOf or NoteRightOf.
+func (sd *StateDiagram) Note(position, target, text string) *StateDiagram {
+	if position != NoteLeftOf && position != NoteRightOf {
+		sd.fail("invalid note position %q", position)
+		return sd
+	}
+	if !sd.ensure(target) {
+		return sd
+	}
+	sd.current.Notes = append(sd.current.Notes, &ir.DiagramNote{
+		Position: position,
+		Target:   target,
+		Text:     text,
+	})
+	return sd
+}
+
+// Build returns the assembled graph.
+func (sd *StateDiagram) Build() (*ir.Graph, error) {
+	return sd.build()
+}
+
+// Mermaid returns the state diagram as Mermaid source.
+func (sd *StateDiagram) Mermaid() (string, error) {
+	return sd.mermaid()
+}
+
+// ensure declares a state in the current state machine.
+func (sd *StateDiagram) ensure(id string) bool {
+	if id != "__start__" && id != "__end__" && !sd.checkID("state", id) {
+		return false
+	}
+	sd.current.EnsureNode(id, nil, nil)
+	return true
+}
+
+// starID maps [*] to the pseudo-state IDs the parser uses.
+func starID(id string, isSource bool) string {
+	if id != StartEnd {
+		return id
+	}
+	if isSource {
+		return "__start__"
+	}
+	return "__end__"
+}
@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestStateBuilder(t *testing.T) {
+	sd := NewStateDiagram().
+		Transition(StartEnd, "Idle", "").
+		State("Idle", "Waiting for input").
+		Annotate("Check", ir.StateChoice).
+		Transition("Idle", "Check", "submit").
+		Transition("Check", "Active", "").
+		Composite("Active", func(inner *StateDiagram) {
+			inner.Transition(StartEnd, "Running", "")
+			inner.Transition("Running", StartEnd, "")
+		}).
+		Transition("Active", StartEnd, "").
+		Note(NoteRightOf, "Idle", "ready")
+
+	graph, err := sd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if graph.Edges[0].From != "__start__" {
+		t.Errorf("start edge From = %q", graph.Edges[0].From)
+	}
+	_, parsed := mermaidFrom(t, sd.Mermaid)
+	if parsed.StateDescriptions["Idle"] != "Waiting for input" {
+		t.Errorf("description = %q", parsed.StateDescriptions["Idle"])
+	}
+	if ann, ok := parsed.StateAnnotations["Check"]; !ok || ann != ir.StateChoice {
+		t.Error("choice annotation lost")
+	}
+	cs := parsed.CompositeStates["Active"]
+	if cs == nil || cs.Inner == nil || len(cs.Inner.Edges) != 2 {
+		t.Fatalf("composite state = %+v", cs)
+	}
+	if len(parsed.Edges) != len(graph.Edges) {
+		t.Errorf("Edges = %d, want %d", len(parsed.Edges), len(graph.Edges))
+	}
+}
+
+func TestStateConcurrentRegions(t *testing.T) {
+	sd := NewStateDiagram().Composite("Par",
+		func(inner *StateDiagram) { inner.Transition(StartEnd, "A", "") },
+		func(inner *StateDiagram) { inner.Transition(StartEnd, "B", "") },
+	)
+	_, parsed := mermaidFrom(t, sd.Mermaid)
+	if got := len(parsed.CompositeStates["Par"].Regions); got != 2 {
+		t.Errorf("Regions = %d, want 2", got)
+	}
+}
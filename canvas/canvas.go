@@ -0,0 +1,617 @@
+// Package canvas interprets the SVG produced by the render package as a
+// sequence of drawing operations, so that raster backends draw exactly
+// what the SVG shows. Its paths, colours and typesetter also serve
+// backends that draw from the layout directly, such as PDF.
+//
+// It understands the subset of SVG that render emits: basic shapes, paths,
+// arrowhead markers, icon symbols drawn with <use>, group transforms,
+// opacity and text. Gradient paints are drawn in the average colour of
+// their stops. It is not a general SVG renderer; unknown elements are
+// skipped.
+//
+// Text is laid out with the font textmetrics measured it with, falling
+// back to the embedded Go fonts when textmetrics used its width heuristic,
+// and each run is fitted to the measured width so glyphs land where the
+// layout placed them.
+package canvas
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Default SVG property values.
+const (
+	defaultFontSize    = 16.0
+	defaultMarkerSize  = 3.0
+	defaultStrokeWidth = 1.0
+	// epsilon treats shorter distances as coincident points.
+	epsilon = 1e-9
+)
+
+// ErrNotSVG is returned when the input has no <svg> root element.
+var ErrNotSVG = errors.New("canvas: input is not an SVG document")
+
+// Backend receives drawing operations in device space.
+type Backend interface {
+	// Fill fills a path using the nonzero winding rule.
+	Fill(shape Path, paint color.NRGBA)
+	// Stroke outlines a path. Width and dash lengths are in device units.
+	Stroke(shape Path, stroke Stroke, paint color.NRGBA)
+	// Text draws a run of glyphs.
+	Text(run TextRun)
+}
+
+// Stroke describes how a path is outlined.
+type Stroke struct {
+	Width float64
+	Dash  []float64
+	Cap   string // butt, round or square
+	Join  string // miter, round or bevel
+}
+
+// Options configures Draw.
+type Options struct {
+	// Embeddable restricts text to fonts whose data is available for
+	// embedding, which means the bundled Go fonts.
+	Embeddable bool
+}
+
+// Document is a parsed SVG document.
+type Document struct {
+	// Width and Height are the document size in user units.
+	Width, Height float64
+
+	root    *element
+	base    Matrix // maps the viewBox to the width x height box
+	markers map[string]*element
+	symbols map[string]*element
+}
+
+// element is a parsed SVG element.
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+	text     strings.Builder
+}
+
+// Parse reads an SVG document. Inline style declarations are merged into
+// the attributes they override.
+func Parse(svg string) (*Document, error) {
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	var root *element
+	var stack []*element
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("canvas: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			el := &element{name: tok.Name.Local, attrs: make(map[string]string, len(tok.Attr))}
+			for _, attr := range tok.Attr {
+				el.attrs[attr.Name.Local] = attr.Value
+			}
+			for _, decl := range strings.Split(el.attrs["style"], ";") {
+				if name, value, ok := strings.Cut(decl, ":"); ok {
+					el.attrs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			} else if root == nil {
+				root = el
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			}
+		}
+	}
+	if root == nil || root.name != "svg" {
+		return nil, ErrNotSVG
+	}
+
+	doc := &Document{root: root, base: Identity(), markers: make(map[string]*element), symbols: make(map[string]*element)}
+	viewBox := parseNumbers(root.attrs["viewBox"])
+	doc.Width, doc.Height = length(root.attrs["width"]), length(root.attrs["height"])
+	if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 { //nolint:mnd // min-x, min-y, width, height.
+		if doc.Width <= 0 {
+			doc.Width = viewBox[2]
+		}
+		if doc.Height <= 0 {
+			doc.Height = viewBox[3]
+		}
+		doc.base = Scaling(doc.Width/viewBox[2], doc.Height/viewBox[3]).
+			Multiply(Translate(-viewBox[0], -viewBox[1]))
+	}
+	gradients := make(map[string]string)
+	doc.collectDefinitions(root, gradients)
+	resolveGradients(root, gradients)
+	return doc, nil
+}
+
+// collectDefinitions indexes marker and symbol definitions by ID, and
+// records the average colour of each gradient.
+func (d *Document) collectDefinitions(el *element, gradients map[string]string) {
+	for _, child := range el.children {
+		if id := child.attrs["id"]; id != "" {
+			switch child.name {
+			case "marker":
+				d.markers[id] = child
+			case "symbol":
+				d.symbols[id] = child
+			case "linearGradient", "radialGradient":
+				gradients[id] = averageStopColor(child)
+			}
+		}
+		d.collectDefinitions(child, gradients)
+	}
+}
+
+// averageStopColor returns the mean colour of a gradient's stops, or none
+// when it has none.
+func averageStopColor(gradient *element) string {
+	var red, green, blue, alpha float64
+	count := 0
+	for _, stop := range gradient.children {
+		if stop.name != "stop" {
+			continue
+		}
+		value, ok := stop.attrs["stop-color"]
+		if !ok {
+			value = "black"
+		}
+		col, ok := ParseColor(value)
+		if !ok {
+			continue
+		}
+		opacity := 1.0
+		if level, ok := stop.attrs["stop-opacity"]; ok {
+			opacity = fraction(level)
+		}
+		red += float64(col.R)
+		green += float64(col.G)
+		blue += float64(col.B)
+		alpha += float64(col.A) / 255 * opacity //nolint:mnd // alpha as a fraction.
+		count++
+	}
+	if count == 0 {
+		return "none"
+	}
+	mean := func(sum float64) int { return int(math.Round(sum / float64(count))) }
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", mean(red), mean(green), mean(blue),
+		strconv.FormatFloat(alpha/float64(count), 'f', -1, 64))
+}
+
+// resolveGradients replaces fill and stroke references to gradients with
+// the gradients' average colours, since backends paint in solid colours.
+func resolveGradients(el *element, gradients map[string]string) {
+	for _, name := range []string{"fill", "stroke"} {
+		if id := markerID(el.attrs[name]); id != "" {
+			if average, ok := gradients[id]; ok {
+				el.attrs[name] = average
+			}
+		}
+	}
+	for _, child := range el.children {
+		resolveGradients(child, gradients)
+	}
+}
+
+// Draw sends the document's drawing operations to backend. The device
+// transform maps the Width x Height document box to device space.
+func (d *Document) Draw(backend Backend, device Matrix, opts Options) {
+	walk := &walker{
+		doc:     d,
+		backend: backend,
+		text:    NewTypesetter(opts),
+	}
+	walk.drawChildren(d.root, defaultStyle().inherit(d.root.attrs), device.Multiply(d.base))
+}
+
+// length parses a length attribute, ignoring a px suffix.
+func length(value string) float64 {
+	num, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "px"), 64)
+	if err != nil {
+		return 0
+	}
+	return num
+}
+
+// style holds the inherited presentation properties.
+type style struct {
+	color         string // the currentColor value
+	fill          string
+	stroke        string
+	strokeWidth   float64
+	dash          []float64
+	lineCap       string
+	lineJoin      string
+	opacity       float64 // product of ancestor group opacities
+	fillOpacity   float64
+	strokeOpacity float64
+	font          Font
+	markerStart   string
+	markerEnd     string
+}
+
+// defaultStyle returns the SVG initial values.
+func defaultStyle() style {
+	return style{
+		color:         "black",
+		fill:          "black",
+		stroke:        "none",
+		strokeWidth:   defaultStrokeWidth,
+		lineCap:       "butt",
+		lineJoin:      "miter",
+		opacity:       1,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		font:          Font{Family: "sans-serif", Size: defaultFontSize, Anchor: "start"},
+	}
+}
+
+// inherit returns the style of a child element with the given attributes.
+func (s style) inherit(attrs map[string]string) style {
+	out := s
+	out.markerStart, out.markerEnd = "", ""
+	// Apply color first so fill and stroke of currentColor see it.
+	if value, ok := attrs["color"]; ok && !strings.EqualFold(value, "currentColor") {
+		out.color = value
+	}
+	for name, value := range attrs {
+		switch name {
+		case "fill":
+			out.fill = out.current(value)
+		case "stroke":
+			out.stroke = out.current(value)
+		case "stroke-width":
+			out.strokeWidth = length(value)
+		case "stroke-dasharray":
+			out.dash = nil
+			if value != "none" {
+				out.dash = parseNumbers(value)
+			}
+		case "stroke-linecap":
+			out.lineCap = value
+		case "stroke-linejoin":
+			out.lineJoin = value
+		case "opacity":
+			out.opacity *= fraction(value)
+		case "fill-opacity":
+			out.fillOpacity = fraction(value)
+		case "stroke-opacity":
+			out.strokeOpacity = fraction(value)
+		case "font-family":
+			out.font.Family = value
+		case "font-size":
+			out.font.Size = length(value)
+		case "font-weight":
+			out.font.Bold = value == "bold" || value == "bolder" || length(value) >= 600 //nolint:mnd // CSS semi-bold and heavier.
+		case "font-style":
+			out.font.Italic = value == "italic" || value == "oblique"
+		case "text-anchor":
+			out.font.Anchor = value
+		case "dominant-baseline":
+			out.font.Baseline = value
+		case "marker-start":
+			out.markerStart = markerID(value)
+		case "marker-end":
+			out.markerEnd = markerID(value)
+		}
+	}
+	return out
+}
+
+// current resolves a currentColor paint value to the color property.
+func (s style) current(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), "currentColor") {
+		return s.color
+	}
+	return value
+}
+
+// paint resolves a paint value and opacity to a color, reporting false
+// when nothing should be drawn.
+func paint(value string, opacity float64) (color.NRGBA, bool) {
+	col, ok := ParseColor(value)
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	col.A = uint8(math.Round(float64(col.A) * opacity)) //nolint:gosec // opacity is within [0, 1].
+	return col, col.A > 0
+}
+
+// fraction parses an opacity value, clamped to [0, 1].
+func fraction(value string) float64 {
+	level, ok := parseLevel(strings.TrimSpace(value), 1)
+	if !ok {
+		return 1
+	}
+	return math.Max(0, math.Min(1, level))
+}
+
+// markerID extracts the ID from a url(#id) reference, such as a marker or
+// a gradient.
+func markerID(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "url(") || !strings.HasSuffix(value, ")") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.Trim(value[4:len(value)-1], `"' `), "#")
+}
+
+// maxUseDepth bounds nested <use> references so cycles terminate.
+const maxUseDepth = 8
+
+// walker traverses the element tree and emits drawing operations.
+type walker struct {
+	doc     *Document
+	backend Backend
+	text    *Typesetter
+	depth   int // nesting of <use> elements being drawn
+}
+
+// drawChildren draws the children of a container element.
+func (w *walker) drawChildren(el *element, parent style, ctm Matrix) {
+	for _, child := range el.children {
+		w.draw(child, parent, ctm)
+	}
+}
+
+// draw renders one element and its subtree.
+func (w *walker) draw(el *element, parent style, ctm Matrix) {
+	if el.attrs["display"] == "none" || el.attrs["visibility"] == "hidden" {
+		return
+	}
+	current := parent.inherit(el.attrs)
+	if transform, ok := el.attrs["transform"]; ok {
+		ctm = ctm.Multiply(parseTransform(transform))
+	}
+	attr := func(name string) float64 { return length(el.attrs[name]) }
+
+	var shape Path
+	switch el.name {
+	case "g", "a", "switch":
+		w.drawChildren(el, current, ctm)
+		return
+	case "svg":
+		w.drawChildren(el, current, ctm.Multiply(Translate(attr("x"), attr("y"))))
+		return
+	case "text":
+		w.drawText(el, current, ctm)
+		return
+	case "use":
+		w.drawUse(el, current, ctm)
+		return
+	case "rect":
+		radiusX, radiusY := attr("rx"), attr("ry")
+		if _, ok := el.attrs["ry"]; !ok {
+			radiusY = radiusX
+		}
+		if _, ok := el.attrs["rx"]; !ok {
+			radiusX = radiusY
+		}
+		shape = RectPath(attr("x"), attr("y"), attr("width"), attr("height"), radiusX, radiusY)
+	case "circle":
+		shape = EllipsePath(Point{attr("cx"), attr("cy")}, attr("r"), attr("r"))
+	case "ellipse":
+		shape = EllipsePath(Point{attr("cx"), attr("cy")}, attr("rx"), attr("ry"))
+	case "line":
+		// A line has no interior, so only its stroke is drawn.
+		current.fill = "none"
+		shape.MoveTo(Point{attr("x1"), attr("y1")})
+		shape.LineTo(Point{attr("x2"), attr("y2")})
+	case "polyline":
+		shape = pointsPath(el.attrs["points"], false)
+	case "polygon":
+		shape = pointsPath(el.attrs["points"], true)
+	case "path":
+		// Draw what parsed before any error, as browsers do.
+		shape, _ = parsePathData(el.attrs["d"])
+	default:
+		// defs, marker, symbol, title and anything unsupported draw
+		// nothing here.
+		return
+	}
+	w.drawShape(shape, current, ctm)
+}
+
+// drawUse draws the symbol a <use> element references. The symbol's
+// viewBox is fitted into the use's x, y, width and height box, uniformly
+// and centred as with the default preserveAspectRatio.
+func (w *walker) drawUse(el *element, current style, ctm Matrix) {
+	symbol := w.doc.symbols[strings.TrimPrefix(el.attrs["href"], "#")]
+	if symbol == nil || w.depth >= maxUseDepth {
+		return
+	}
+	x, y := length(el.attrs["x"]), length(el.attrs["y"])
+	place := ctm.Multiply(Translate(x, y))
+	viewBox := parseNumbers(symbol.attrs["viewBox"])
+	width, height := length(el.attrs["width"]), length(el.attrs["height"])
+	if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 { //nolint:mnd // viewBox has four numbers.
+		if _, ok := el.attrs["width"]; !ok {
+			width = viewBox[2]
+		}
+		if _, ok := el.attrs["height"]; !ok {
+			height = viewBox[3]
+		}
+		fit := math.Min(width/viewBox[2], height/viewBox[3])
+		place = place.
+			Multiply(Translate((width-viewBox[2]*fit)/2, (height-viewBox[3]*fit)/2)). //nolint:mnd // centred in the box.
+			Multiply(Scaling(fit, fit)).
+			Multiply(Translate(-viewBox[0], -viewBox[1]))
+	}
+	w.depth++
+	w.drawChildren(symbol, current.inherit(symbol.attrs), place)
+	w.depth--
+}
+
+// drawShape fills and strokes a path, then draws its markers.
+func (w *walker) drawShape(shape Path, current style, ctm Matrix) {
+	if len(shape) == 0 {
+		return
+	}
+	device := shape.Transform(ctm)
+	if fill, ok := paint(current.fill, current.opacity*current.fillOpacity); ok {
+		w.backend.Fill(device, fill)
+	}
+	if stroke, ok := paint(current.stroke, current.opacity*current.strokeOpacity); ok && current.strokeWidth > 0 {
+		factor := ctm.ScaleFactor()
+		var dash []float64
+		for _, length := range current.dash {
+			dash = append(dash, length*factor)
+		}
+		w.backend.Stroke(device, Stroke{
+			Width: current.strokeWidth * factor,
+			Dash:  dash,
+			Cap:   current.lineCap,
+			Join:  current.lineJoin,
+		}, stroke)
+	}
+	w.drawMarkers(device, current, ctm)
+}
+
+// drawMarkers places marker-start and marker-end at the ends of the first
+// and last subpaths, oriented along the path.
+func (w *walker) drawMarkers(device Path, current style, ctm Matrix) {
+	startMarker := w.doc.markers[current.markerStart]
+	endMarker := w.doc.markers[current.markerEnd]
+	if startMarker == nil && endMarker == nil {
+		return
+	}
+	lines := device.Flatten()
+	if len(lines) == 0 {
+		return
+	}
+	if startMarker != nil {
+		if at, toward, ok := endpoint(lines[0].Points, true); ok {
+			direction := toward.Sub(at)
+			if startMarker.attrs["orient"] == "auto-start-reverse" {
+				direction = direction.Scale(-1)
+			}
+			w.drawMarker(startMarker, at, direction, current, ctm)
+		}
+	}
+	if endMarker != nil {
+		if at, from, ok := endpoint(lines[len(lines)-1].Points, false); ok {
+			w.drawMarker(endMarker, at, at.Sub(from), current, ctm)
+		}
+	}
+}
+
+// endpoint returns the first (or last) point of a polyline and the
+// nearest distinct neighbor that gives its direction.
+func endpoint(pts []Point, first bool) (Point, Point, bool) {
+	if len(pts) < 2 { //nolint:mnd // a direction needs two points.
+		return Point{}, Point{}, false
+	}
+	if first {
+		for _, pt := range pts[1:] {
+			if pt.Sub(pts[0]).Len() > epsilon {
+				return pts[0], pt, true
+			}
+		}
+		return Point{}, Point{}, false
+	}
+	last := pts[len(pts)-1]
+	for idx := len(pts) - 2; idx >= 0; idx-- {
+		if pts[idx].Sub(last).Len() > epsilon {
+			return last, pts[idx], true
+		}
+	}
+	return Point{}, Point{}, false
+}
+
+// drawMarker draws a marker's content at a device-space vertex. The
+// marker's viewBox is scaled to markerWidth by markerHeight, uniformly as
+// with the default preserveAspectRatio, and its refX/refY point is placed
+// on the vertex.
+func (w *walker) drawMarker(marker *element, at, direction Point, owner style, ctm Matrix) {
+	attrs := marker.attrs
+	markerWidth, markerHeight := defaultMarkerSize, defaultMarkerSize
+	if _, ok := attrs["markerWidth"]; ok {
+		markerWidth = length(attrs["markerWidth"])
+	}
+	if _, ok := attrs["markerHeight"]; ok {
+		markerHeight = length(attrs["markerHeight"])
+	}
+	fit := 1.0
+	if viewBox := parseNumbers(attrs["viewBox"]); len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 { //nolint:mnd // viewBox has four numbers.
+		fit = math.Min(markerWidth/viewBox[2], markerHeight/viewBox[3])
+	}
+	units := ctm.ScaleFactor()
+	if attrs["markerUnits"] != "userSpaceOnUse" {
+		units *= owner.strokeWidth
+	}
+
+	angle := 0.0
+	switch orient := attrs["orient"]; orient {
+	case "auto", "auto-start-reverse":
+		angle = math.Atan2(direction.Y, direction.X)
+	default:
+		if degrees, err := strconv.ParseFloat(orient, 64); err == nil {
+			angle = degrees * math.Pi / 180
+		}
+	}
+
+	place := Translate(at.X, at.Y).
+		Multiply(Rotation(angle)).
+		Multiply(Scaling(units*fit, units*fit)).
+		Multiply(Translate(-length(attrs["refX"]), -length(attrs["refY"])))
+	w.drawChildren(marker, defaultStyle().inherit(attrs), place)
+}
+
+// drawText lays out a text element's content and sends it as a glyph run.
+func (w *walker) drawText(el *element, current style, ctm Matrix) {
+	content := el.text.String()
+	for _, child := range el.children {
+		if child.name == "tspan" {
+			content += child.text.String()
+		}
+	}
+	content = collapseSpace(content)
+	if content == "" {
+		return
+	}
+	fill, ok := paint(current.fill, current.opacity*current.fillOpacity)
+	if !ok {
+		return
+	}
+	origin := Point{firstNumber(el.attrs["x"]), firstNumber(el.attrs["y"])}
+	glyphs, offset := w.text.Layout(content, current.font)
+	if len(glyphs) == 0 {
+		return
+	}
+	w.backend.Text(TextRun{
+		Glyphs:    glyphs,
+		Size:      current.font.Size,
+		Transform: ctm.Multiply(Translate(origin.X+offset.X, origin.Y+offset.Y)),
+		Paint:     fill,
+	})
+}
+
+// firstNumber returns the first value of a coordinate list.
+func firstNumber(value string) float64 {
+	if nums := parseNumbers(value); len(nums) > 0 {
+		return nums[0]
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package canvas
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// recorder is a Backend that records the operations it receives.
+type recorder struct {
+	fills      []color.NRGBA
+	fillShapes []Path
+	strokes    []Stroke
+	runs       []TextRun
+}
+
+func (r *recorder) Fill(shape Path, paint color.NRGBA) {
+	r.fills = append(r.fills, paint)
+	r.fillShapes = append(r.fillShapes, shape)
+}
+func (r *recorder) Stroke(_ Path, stroke Stroke, _ color.NRGBA) {
+	r.strokes = append(r.strokes, stroke)
+}
+func (r *recorder) Text(run TextRun) { r.runs = append(r.runs, run) }
+
+func TestParseSize(t *testing.T) {
+	doc, err := Parse(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 40 20"></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Width != 40 || doc.Height != 20 {
+		t.Errorf("size = %gx%g, want 40x20", doc.Width, doc.Height)
+	}
+	if _, err := Parse(`<html></html>`); !errors.Is(err, ErrNotSVG) {
+		t.Errorf("Parse(html) error = %v, want ErrNotSVG", err)
+	}
+}
+
+func TestDrawOperations(t *testing.T) {
+	doc, err := Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50">
+<defs><marker id="arrow" viewBox="0 0 10 10" markerWidth="5" markerHeight="5" orient="auto">
+<path d="M0,0 L10,5 L0,10 z" fill="red"/></marker></defs>
+<g opacity="0.5" transform="scale(2)">
+<rect width="10" height="10" fill="#00f" stroke="black" stroke-width="1.5" stroke-dasharray="2 1"/>
+</g>
+<line x1="0" y1="40" x2="50" y2="40" stroke="black" marker-end="url(#arrow)"/>
+<text x="10" y="30" text-anchor="middle" style="font-size: 12px">Hi</text>
+</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	doc.Draw(rec, Identity(), Options{Embeddable: true})
+
+	if len(rec.fills) != 2 || rec.fills[0].A != 128 || rec.fills[1] != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("fills = %v, want half-transparent rect then red marker", rec.fills)
+	}
+	if len(rec.strokes) != 2 {
+		t.Fatalf("strokes = %d, want 2", len(rec.strokes))
+	}
+	if got := rec.strokes[0]; got.Width != 3 || len(got.Dash) != 2 || got.Dash[0] != 4 {
+		t.Errorf("scaled stroke = %+v, want width 3 and dash 4 2", got)
+	}
+	if len(rec.runs) != 1 {
+		t.Fatalf("text runs = %d, want 1", len(rec.runs))
+	}
+	run := rec.runs[0]
+	if run.Size != 12 || len(run.Glyphs) != 2 || run.Glyphs[0].Face.Data == nil {
+		t.Errorf("run = %+v, want two embeddable 12px glyphs", run)
+	}
+	if start := run.Transform.Apply(Point{}); start.X >= 10 || math.Abs(start.Y-30) > 1e-9 {
+		t.Errorf("run starts at %v, want left of the middle anchor on y=30", start)
+	}
+}
+
+func TestDrawUseSymbol(t *testing.T) {
+	doc, err := Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+<defs><symbol id="icon" viewBox="0 0 10 20"><rect width="10" height="20" fill="currentColor"/></symbol></defs>
+<use href="#icon" x="10" y="10" width="40" height="40" color="#00ff00"/>
+<use href="#missing" x="0" y="0" width="10" height="10"/>
+</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	doc.Draw(rec, Identity(), Options{})
+
+	if len(rec.fills) != 1 || rec.fills[0] != (color.NRGBA{0, 255, 0, 255}) {
+		t.Fatalf("fills = %v, want one green fill from currentColor", rec.fills)
+	}
+	// The 10x20 viewBox fits the 40x40 box at scale 2, centred horizontally.
+	lines := rec.fillShapes[0].Flatten()
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, line := range lines {
+		for _, pt := range line.Points {
+			minX, minY = math.Min(minX, pt.X), math.Min(minY, pt.Y)
+			maxX, maxY = math.Max(maxX, pt.X), math.Max(maxY, pt.Y)
+		}
+	}
+	if minX != 20 || maxX != 40 || minY != 10 || maxY != 50 {
+		t.Errorf("symbol bounds = (%g,%g)-(%g,%g), want (20,10)-(40,50)", minX, minY, maxX, maxY)
+	}
+}
+
+func TestDrawGradientFill(t *testing.T) {
+	doc, err := Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="20" height="20">
+<defs><linearGradient id="fade"><stop offset="0%" stop-color="#ff0000"/><stop offset="100%" stop-color="#0000ff"/></linearGradient></defs>
+<rect width="10" height="10" fill="url(#fade)"/>
+</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	doc.Draw(rec, Identity(), Options{})
+
+	if len(rec.fills) != 1 {
+		t.Fatalf("fills = %d, want 1", len(rec.fills))
+	}
+	if got := rec.fills[0]; got.G != 0 || got.A != 255 || got.R < 127 || got.R > 128 || got.B < 127 || got.B > 128 {
+		t.Errorf("gradient fill = %v, want the purple average of its stops", got)
+	}
+}
@@ -0,0 +1,175 @@
+package canvas
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// Color parsing constants.
+const (
+	channelMax  = 255.0
+	percentMax  = 100.0
+	degreesFull = 360.0
+	hueSextant  = 60.0
+)
+
+// ParseColor converts an SVG paint value to a color. It reports false for
+// "none", "transparent" and values it does not understand, which are not
+// painted.
+func ParseColor(value string) (color.NRGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "" || value == "none" || value == "transparent":
+		return color.NRGBA{}, false
+	case value == "currentcolor":
+		return color.NRGBA{A: 255}, true
+	case strings.HasPrefix(value, "#"):
+		return parseHexColor(value[1:])
+	case strings.HasPrefix(value, "rgb"):
+		return parseRGBFunc(value)
+	case strings.HasPrefix(value, "hsl"):
+		return parseHSLFunc(value)
+	}
+	named, ok := colornames.Map[value]
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: named.R, G: named.G, B: named.B, A: named.A}, true
+}
+
+// parseHexColor parses #rgb, #rgba, #rrggbb and #rrggbbaa digits.
+func parseHexColor(hex string) (color.NRGBA, bool) {
+	if len(hex) == 3 || len(hex) == 4 { //nolint:mnd // short forms #rgb and #rgba.
+		var long strings.Builder
+		for _, ch := range hex {
+			long.WriteRune(ch)
+			long.WriteRune(ch)
+		}
+		hex = long.String()
+	}
+	if len(hex) == 6 { //nolint:mnd // #rrggbb has no alpha.
+		hex += "ff"
+	}
+	if len(hex) != 8 { //nolint:mnd // #rrggbbaa.
+		return color.NRGBA{}, false
+	}
+	packed, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{
+		R: uint8(packed >> 24), //nolint:gosec,mnd // byte extraction.
+		G: uint8(packed >> 16), //nolint:gosec,mnd // byte extraction.
+		B: uint8(packed >> 8),  //nolint:gosec,mnd // byte extraction.
+		A: uint8(packed),       //nolint:gosec // byte extraction.
+	}, true
+}
+
+// funcArgs splits the arguments of a CSS color function such as
+// "rgba(1, 2, 3, 0.5)" or "rgb(1 2 3 / 50%)".
+func funcArgs(value string) []string {
+	open := strings.IndexByte(value, '(')
+	if open < 0 || !strings.HasSuffix(value, ")") {
+		return nil
+	}
+	inner := strings.NewReplacer(",", " ", "/", " ").Replace(value[open+1 : len(value)-1])
+	return strings.Fields(inner)
+}
+
+// parseRGBFunc parses rgb() and rgba().
+func parseRGBFunc(value string) (color.NRGBA, bool) {
+	args := funcArgs(value)
+	if len(args) != 3 && len(args) != 4 { //nolint:mnd // three channels plus optional alpha.
+		return color.NRGBA{}, false
+	}
+	var channels [3]uint8
+	for idx := range channels {
+		level, ok := parseLevel(args[idx], channelMax)
+		if !ok {
+			return color.NRGBA{}, false
+		}
+		channels[idx] = toByte(level)
+	}
+	alpha := 1.0
+	if len(args) == 4 { //nolint:mnd // explicit alpha.
+		var ok bool
+		if alpha, ok = parseLevel(args[3], 1); !ok {
+			return color.NRGBA{}, false
+		}
+	}
+	return color.NRGBA{R: channels[0], G: channels[1], B: channels[2], A: toByte(alpha * channelMax)}, true
+}
+
+// parseHSLFunc parses hsl() and hsla().
+func parseHSLFunc(value string) (color.NRGBA, bool) {
+	args := funcArgs(value)
+	if len(args) != 3 && len(args) != 4 { //nolint:mnd // three components plus optional alpha.
+		return color.NRGBA{}, false
+	}
+	hue, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	sat, satOK := parseLevel(args[1], 1)
+	light, lightOK := parseLevel(args[2], 1)
+	if !satOK || !lightOK {
+		return color.NRGBA{}, false
+	}
+	alpha := 1.0
+	if len(args) == 4 { //nolint:mnd // explicit alpha.
+		var ok bool
+		if alpha, ok = parseLevel(args[3], 1); !ok {
+			return color.NRGBA{}, false
+		}
+	}
+
+	hue = math.Mod(math.Mod(hue, degreesFull)+degreesFull, degreesFull)
+	chroma := (1 - math.Abs(2*light-1)) * sat
+	second := chroma * (1 - math.Abs(math.Mod(hue/hueSextant, 2)-1))
+	var red, green, blue float64
+	switch int(hue / hueSextant) {
+	case 0:
+		red, green = chroma, second
+	case 1:
+		red, green = second, chroma
+	case 2: //nolint:mnd // third sextant.
+		green, blue = chroma, second
+	case 3: //nolint:mnd // fourth sextant.
+		green, blue = second, chroma
+	case 4: //nolint:mnd // fifth sextant.
+		red, blue = second, chroma
+	default:
+		red, blue = chroma, second
+	}
+	base := light - chroma/2
+	return color.NRGBA{
+		R: toByte((red + base) * channelMax),
+		G: toByte((green + base) * channelMax),
+		B: toByte((blue + base) * channelMax),
+		A: toByte(alpha * channelMax),
+	}, true
+}
+
+// parseLevel parses a number or percentage. Percentages are scaled to
+// full; bare numbers are returned as is.
+func parseLevel(arg string, full float64) (float64, bool) {
+	scale := 1.0
+	if strings.HasSuffix(arg, "%") {
+		arg = strings.TrimSuffix(arg, "%")
+		scale = full / percentMax
+	}
+	level, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, false
+	}
+	return level * scale, true
+}
+
+// toByte rounds and clamps a 0-255 level to a byte.
+func toByte(level float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(channelMax, level)))) //nolint:gosec // clamped to byte range.
+}
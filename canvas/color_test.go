@@ -0,0 +1,35 @@
+package canvas
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+		ok   bool
+	}{
+		{"#fff", color.NRGBA{255, 255, 255, 255}, true},
+		{"#336699", color.NRGBA{0x33, 0x66, 0x99, 255}, true},
+		{"#33669980", color.NRGBA{0x33, 0x66, 0x99, 0x80}, true},
+		{"rgb(10, 20, 30)", color.NRGBA{10, 20, 30, 255}, true},
+		{"rgba(0,0,0,0.5)", color.NRGBA{0, 0, 0, 128}, true},
+		{"rgb(100%, 0%, 50%)", color.NRGBA{255, 0, 127, 255}, true},
+		{"hsl(0, 100%, 50%)", color.NRGBA{255, 0, 0, 255}, true},
+		{"hsl(120.00, 100.00%, 25.00%)", color.NRGBA{0, 128, 0, 255}, true},
+		{"hsla(240, 100%, 50%, 0.5)", color.NRGBA{0, 0, 255, 128}, true},
+		{"SteelBlue", color.NRGBA{70, 130, 180, 255}, true},
+		{"none", color.NRGBA{}, false},
+		{"transparent", color.NRGBA{}, false},
+		{"#12", color.NRGBA{}, false},
+		{"nonsense", color.NRGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseColor(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
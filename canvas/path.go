@@ -0,0 +1,573 @@
+package canvas
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// errPathSyntax is returned for malformed path data.
+var errPathSyntax = errors.New("canvas: malformed path data")
+
+// Geometry constants.
+const (
+	// kappa places cubic control points to approximate a quarter ellipse.
+	kappa = 0.5522847498
+	// FlattenStep is the target device-space length of one flattened
+	// curve segment, in pixels.
+	FlattenStep = 2.0
+	// maxCurveSteps caps the segments produced for one curve.
+	maxCurveSteps = 128
+)
+
+// Point is a 2D coordinate. The Y axis increases down, as in SVG.
+type Point struct{ X, Y float64 }
+
+// Add returns p+q.
+func (p Point) Add(q Point) Point { return Point{p.X + q.X, p.Y + q.Y} }
+
+// Sub returns p-q.
+func (p Point) Sub(q Point) Point { return Point{p.X - q.X, p.Y - q.Y} }
+
+// Scale returns p multiplied by factor.
+func (p Point) Scale(factor float64) Point { return Point{p.X * factor, p.Y * factor} }
+
+// Dot returns the dot product of p and q.
+func (p Point) Dot(q Point) float64 { return p.X*q.X + p.Y*q.Y }
+
+// Len returns the distance from the origin to p.
+func (p Point) Len() float64 { return math.Hypot(p.X, p.Y) }
+
+// Normalize returns p scaled to unit length, or the zero point.
+func (p Point) Normalize() Point {
+	if length := p.Len(); length > 0 {
+		return p.Scale(1 / length)
+	}
+	return Point{}
+}
+
+// Matrix is an affine transform [a b c d e f] mapping (x, y) to
+// (a*x + c*y + e, b*x + d*y + f), as in SVG and PDF.
+type Matrix [6]float64
+
+// Identity returns the transform that leaves points unchanged.
+func Identity() Matrix { return Matrix{1, 0, 0, 1, 0, 0} }
+
+// Translate returns a translation by (dx, dy).
+func Translate(dx, dy float64) Matrix { return Matrix{1, 0, 0, 1, dx, dy} }
+
+// Scaling returns a scale by sx horizontally and sy vertically.
+func Scaling(sx, sy float64) Matrix { return Matrix{sx, 0, 0, sy, 0, 0} }
+
+// Rotation returns a rotation by angle radians about the origin.
+func Rotation(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+	return Matrix{cos, sin, -sin, cos, 0, 0}
+}
+
+// Multiply returns the transform that applies other first, then m.
+func (m Matrix) Multiply(other Matrix) Matrix {
+	return Matrix{
+		m[0]*other[0] + m[2]*other[1],
+		m[1]*other[0] + m[3]*other[1],
+		m[0]*other[2] + m[2]*other[3],
+		m[1]*other[2] + m[3]*other[3],
+		m[0]*other[4] + m[2]*other[5] + m[4],
+		m[1]*other[4] + m[3]*other[5] + m[5],
+	}
+}
+
+// Apply transforms a point.
+func (m Matrix) Apply(p Point) Point {
+	return Point{m[0]*p.X + m[2]*p.Y + m[4], m[1]*p.X + m[3]*p.Y + m[5]}
+}
+
+// ScaleFactor returns the average linear scale of the transform, used for
+// stroke widths and dash lengths.
+func (m Matrix) ScaleFactor() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// parseTransform parses an SVG transform list.
+func parseTransform(value string) Matrix {
+	result := Identity()
+	for {
+		open := strings.IndexByte(value, '(')
+		closing := strings.IndexByte(value, ')')
+		if open < 0 || closing < open {
+			return result
+		}
+		name := strings.TrimSpace(strings.Trim(value[:open], ", \t\n"))
+		args := parseNumbers(value[open+1 : closing])
+		value = value[closing+1:]
+
+		arg := func(idx int, fallback float64) float64 {
+			if idx < len(args) {
+				return args[idx]
+			}
+			return fallback
+		}
+		var step Matrix
+		switch name {
+		case "translate":
+			step = Translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			step = Scaling(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0) //nolint:mnd // optional rotation center.
+			step = Translate(cx, cy).Multiply(Rotation(arg(0, 0) * math.Pi / 180)).Multiply(Translate(-cx, -cy))
+		case "matrix":
+			if len(args) != len(step) {
+				continue
+			}
+			copy(step[:], args)
+		case "skewX":
+			step = Matrix{1, 0, math.Tan(arg(0, 0) * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			step = Matrix{1, math.Tan(arg(0, 0) * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		result = result.Multiply(step)
+	}
+}
+
+// parseNumbers parses a comma- or space-separated number list such as a
+// points attribute.
+func parseNumbers(value string) []float64 {
+	scan := numberScanner{src: value}
+	var nums []float64
+	for {
+		num, ok := scan.number()
+		if !ok {
+			return nums
+		}
+		nums = append(nums, num)
+	}
+}
+
+// numberScanner reads SVG numbers, which may run together as in "10-5"
+// or "0.5.5".
+type numberScanner struct {
+	src string
+	pos int
+}
+
+// skipSeparators advances past whitespace and at most one comma.
+func (s *numberScanner) skipSeparators() {
+	comma := false
+	for s.pos < len(s.src) {
+		switch ch := s.src[s.pos]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+		case ch == ',' && !comma:
+			comma = true
+		default:
+			return
+		}
+		s.pos++
+	}
+}
+
+// number reads the next number, reporting false at the end of input or
+// on a non-numeric token.
+func (s *numberScanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.pos
+	if s.pos < len(s.src) && (s.src[s.pos] == '-' || s.src[s.pos] == '+') {
+		s.pos++
+	}
+	dot, digits := false, false
+mantissa:
+	for s.pos < len(s.src) {
+		switch ch := s.src[s.pos]; {
+		case ch >= '0' && ch <= '9':
+			digits = true
+		case ch == '.' && !dot:
+			dot = true
+		default:
+			break mantissa
+		}
+		s.pos++
+	}
+	if digits && s.pos < len(s.src) && (s.src[s.pos] == 'e' || s.src[s.pos] == 'E') {
+		mark := s.pos
+		s.pos++
+		if s.pos < len(s.src) && (s.src[s.pos] == '-' || s.src[s.pos] == '+') {
+			s.pos++
+		}
+		expDigits := false
+		for s.pos < len(s.src) && s.src[s.pos] >= '0' && s.src[s.pos] <= '9' {
+			s.pos++
+			expDigits = true
+		}
+		if !expDigits {
+			s.pos = mark
+		}
+	}
+	if !digits {
+		s.pos = start
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(s.src[start:s.pos], 64)
+	return num, err == nil
+}
+
+// flag reads an arc flag, which may be written without a separator.
+func (s *numberScanner) flag() (bool, bool) {
+	s.skipSeparators()
+	if s.pos < len(s.src) && (s.src[s.pos] == '0' || s.src[s.pos] == '1') {
+		s.pos++
+		return s.src[s.pos-1] == '1', true
+	}
+	return false, false
+}
+
+// Op is a path segment operator.
+type Op byte
+
+// Path segment operators.
+const (
+	OpMoveTo  Op = 'M'
+	OpLineTo  Op = 'L'
+	OpCubicTo Op = 'C'
+	OpClose   Op = 'Z'
+)
+
+// Segment is one drawing command. OpMoveTo and OpLineTo use Pts[0];
+// OpCubicTo uses two control points and the end point; OpClose uses none.
+type Segment struct {
+	Op  Op
+	Pts [3]Point
+}
+
+// Path is a sequence of segments. Quadratic curves and arcs are stored as
+// cubic curves.
+type Path []Segment
+
+// MoveTo starts a new subpath.
+func (p *Path) MoveTo(pt Point) { *p = append(*p, Segment{Op: OpMoveTo, Pts: [3]Point{pt}}) }
+
+// LineTo appends a straight line.
+func (p *Path) LineTo(pt Point) { *p = append(*p, Segment{Op: OpLineTo, Pts: [3]Point{pt}}) }
+
+// Close closes the current subpath.
+func (p *Path) Close() { *p = append(*p, Segment{Op: OpClose}) }
+
+// CubicTo appends a cubic Bézier curve.
+func (p *Path) CubicTo(ctrl1, ctrl2, end Point) {
+	*p = append(*p, Segment{Op: OpCubicTo, Pts: [3]Point{ctrl1, ctrl2, end}})
+}
+
+// QuadTo appends a quadratic curve from start as the equivalent cubic.
+func (p *Path) QuadTo(start, ctrl, end Point) {
+	p.CubicTo(start.Add(ctrl.Sub(start).Scale(2.0/3)), end.Add(ctrl.Sub(end).Scale(2.0/3)), end)
+}
+
+// Transform returns the path with every point mapped through m.
+func (p Path) Transform(m Matrix) Path {
+	out := make(Path, len(p))
+	for idx, seg := range p {
+		out[idx].Op = seg.Op
+		for ptIdx := range seg.Pts {
+			out[idx].Pts[ptIdx] = m.Apply(seg.Pts[ptIdx])
+		}
+	}
+	return out
+}
+
+// Polyline is a flattened subpath.
+type Polyline struct {
+	Points []Point
+	Closed bool
+}
+
+// Flatten converts the path to polylines, splitting curves into segments
+// about FlattenStep long. It should be called on device-space paths.
+func (p Path) Flatten() []Polyline {
+	var lines []Polyline
+	var current *Polyline
+	var pen, start Point
+	begin := func(at Point) {
+		lines = append(lines, Polyline{Points: []Point{at}})
+		current = &lines[len(lines)-1]
+	}
+	for _, seg := range p {
+		switch seg.Op {
+		case OpMoveTo:
+			pen, start = seg.Pts[0], seg.Pts[0]
+			begin(pen)
+		case OpLineTo:
+			if current == nil {
+				begin(pen)
+			}
+			pen = seg.Pts[0]
+			current.Points = append(current.Points, pen)
+		case OpCubicTo:
+			if current == nil {
+				begin(pen)
+			}
+			ctrl1, ctrl2, end := seg.Pts[0], seg.Pts[1], seg.Pts[2]
+			hull := ctrl1.Sub(pen).Len() + ctrl2.Sub(ctrl1).Len() + end.Sub(ctrl2).Len()
+			steps := min(max(int(math.Ceil(hull/FlattenStep)), 1), maxCurveSteps)
+			for step := 1; step <= steps; step++ {
+				current.Points = append(current.Points, cubicPoint(pen, ctrl1, ctrl2, end, float64(step)/float64(steps)))
+			}
+			pen = end
+		case OpClose:
+			if current != nil {
+				current.Closed = true
+			}
+			pen = start
+			current = nil
+		}
+	}
+	return lines
+}
+
+// cubicPoint evaluates a cubic Bézier at t.
+func cubicPoint(start, ctrl1, ctrl2, end Point, t float64) Point {
+	inv := 1 - t
+	return start.Scale(inv * inv * inv).
+		Add(ctrl1.Scale(3 * inv * inv * t)).
+		Add(ctrl2.Scale(3 * inv * t * t)).
+		Add(end.Scale(t * t * t))
+}
+
+// parsePathData parses the d attribute of a path element.
+func parsePathData(data string) (Path, error) {
+	scan := numberScanner{src: data}
+	var out Path
+	var pen, start, lastCtrl Point
+	var prevOp byte
+	var op byte
+	for {
+		scan.skipSeparators()
+		if scan.pos >= len(scan.src) {
+			return out, nil
+		}
+		if ch := scan.src[scan.pos]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", ch) >= 0 {
+			op = ch
+			scan.pos++
+		} else if op == 0 {
+			return out, errPathSyntax
+		}
+
+		relative := op >= 'a'
+		base := Point{}
+		if relative {
+			base = pen
+		}
+		readPoint := func() (Point, bool) {
+			x, okX := scan.number()
+			y, okY := scan.number()
+			return base.Add(Point{x, y}), okX && okY
+		}
+
+		ok := true
+		switch op {
+		case 'M', 'm':
+			var pt Point
+			if pt, ok = readPoint(); ok {
+				out.MoveTo(pt)
+				pen, start = pt, pt
+				// Further coordinate pairs are implicit line commands.
+				op = 'L'
+				if relative {
+					op = 'l'
+				}
+			}
+		case 'L', 'l':
+			var pt Point
+			if pt, ok = readPoint(); ok {
+				out.LineTo(pt)
+				pen = pt
+			}
+		case 'H', 'h':
+			var x float64
+			if x, ok = scan.number(); ok {
+				pen = Point{base.X + x, pen.Y}
+				out.LineTo(pen)
+			}
+		case 'V', 'v':
+			var y float64
+			if y, ok = scan.number(); ok {
+				pen = Point{pen.X, base.Y + y}
+				out.LineTo(pen)
+			}
+		case 'C', 'c':
+			ctrl1, ok1 := readPoint()
+			ctrl2, ok2 := readPoint()
+			end, ok3 := readPoint()
+			if ok = ok1 && ok2 && ok3; ok {
+				out.CubicTo(ctrl1, ctrl2, end)
+				pen, lastCtrl = end, ctrl2
+			}
+		case 'S', 's':
+			ctrl1 := pen
+			if strings.IndexByte("CcSs", prevOp) >= 0 {
+				ctrl1 = pen.Add(pen.Sub(lastCtrl))
+			}
+			ctrl2, ok1 := readPoint()
+			end, ok2 := readPoint()
+			if ok = ok1 && ok2; ok {
+				out.CubicTo(ctrl1, ctrl2, end)
+				pen, lastCtrl = end, ctrl2
+			}
+		case 'Q', 'q':
+			ctrl, ok1 := readPoint()
+			end, ok2 := readPoint()
+			if ok = ok1 && ok2; ok {
+				out.QuadTo(pen, ctrl, end)
+				pen, lastCtrl = end, ctrl
+			}
+		case 'T', 't':
+			ctrl := pen
+			if strings.IndexByte("QqTt", prevOp) >= 0 {
+				ctrl = pen.Add(pen.Sub(lastCtrl))
+			}
+			var end Point
+			if end, ok = readPoint(); ok {
+				out.QuadTo(pen, ctrl, end)
+				pen, lastCtrl = end, ctrl
+			}
+		case 'A', 'a':
+			radiusX, ok1 := scan.number()
+			radiusY, ok2 := scan.number()
+			angle, ok3 := scan.number()
+			large, ok4 := scan.flag()
+			sweep, ok5 := scan.flag()
+			var end Point
+			end, ok = readPoint()
+			if ok = ok && ok1 && ok2 && ok3 && ok4 && ok5; ok {
+				out.ArcTo(pen, radiusX, radiusY, angle, large, sweep, end)
+				pen = end
+			}
+		case 'Z', 'z':
+			out.Close()
+			pen = start
+		}
+		if !ok {
+			return out, errPathSyntax
+		}
+		prevOp = op
+	}
+}
+
+// ArcTo appends an SVG elliptical arc from start to end as cubic curves,
+// following the endpoint-to-center conversion in the SVG specification.
+func (p *Path) ArcTo(start Point, radiusX, radiusY, angleDeg float64, large, sweep bool, end Point) {
+	radiusX, radiusY = math.Abs(radiusX), math.Abs(radiusY)
+	if radiusX == 0 || radiusY == 0 || start == end {
+		p.LineTo(end)
+		return
+	}
+	sin, cos := math.Sincos(angleDeg * math.Pi / 180)
+	half := start.Sub(end).Scale(0.5)
+	x1 := cos*half.X + sin*half.Y
+	y1 := -sin*half.X + cos*half.Y
+
+	// Scale radii up when they cannot span the endpoints.
+	if lambda := x1*x1/(radiusX*radiusX) + y1*y1/(radiusY*radiusY); lambda > 1 {
+		radiusX *= math.Sqrt(lambda)
+		radiusY *= math.Sqrt(lambda)
+	}
+	rx2, ry2 := radiusX*radiusX, radiusY*radiusY
+	numerator := rx2*ry2 - rx2*y1*y1 - ry2*x1*x1
+	coef := math.Sqrt(math.Max(0, numerator/(rx2*y1*y1+ry2*x1*x1)))
+	if large == sweep {
+		coef = -coef
+	}
+	cxp := coef * radiusX * y1 / radiusY
+	cyp := -coef * radiusY * x1 / radiusX
+	mid := start.Add(end).Scale(0.5)
+	center := Point{cos*cxp - sin*cyp + mid.X, sin*cxp + cos*cyp + mid.Y}
+
+	angleOf := func(ux, uy float64) float64 { return math.Atan2(uy, ux) }
+	theta1 := angleOf((x1-cxp)/radiusX, (y1-cyp)/radiusY)
+	delta := angleOf((-x1-cxp)/radiusX, (-y1-cyp)/radiusY) - theta1
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	p.ellipseArc(center, radiusX, radiusY, angleDeg*math.Pi/180, theta1, delta)
+}
+
+// ellipseArc appends cubic curves along an ellipse from angle theta by
+// delta radians, splitting it into pieces of at most a quarter turn.
+func (p *Path) ellipseArc(center Point, radiusX, radiusY, rotate, theta, delta float64) {
+	pieces := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(pieces)
+	handle := 4.0 / 3 * math.Tan(step/4) //nolint:mnd // standard arc-to-cubic handle length.
+	rot := Rotation(rotate)
+	onEllipse := func(angle float64) (Point, Point) {
+		sin, cos := math.Sincos(angle)
+		pos := rot.Apply(Point{radiusX * cos, radiusY * sin}).Add(center)
+		tangent := rot.Apply(Point{-radiusX * sin, radiusY * cos})
+		return pos, tangent
+	}
+	from, fromTangent := onEllipse(theta)
+	for range pieces {
+		theta += step
+		to, toTangent := onEllipse(theta)
+		p.CubicTo(from.Add(fromTangent.Scale(handle)), to.Sub(toTangent.Scale(handle)), to)
+		from, fromTangent = to, toTangent
+	}
+}
+
+// EllipsePath returns a closed ellipse.
+func EllipsePath(center Point, radiusX, radiusY float64) Path {
+	var out Path
+	out.MoveTo(Point{center.X + radiusX, center.Y})
+	out.ellipseArc(center, radiusX, radiusY, 0, 0, 2*math.Pi)
+	out.Close()
+	return out
+}
+
+// RectPath returns a closed rectangle with optional rounded corners.
+func RectPath(x, y, width, height, radiusX, radiusY float64) Path {
+	var out Path
+	radiusX = math.Min(radiusX, width/2)
+	radiusY = math.Min(radiusY, height/2)
+	if radiusX <= 0 || radiusY <= 0 {
+		out.MoveTo(Point{x, y})
+		out.LineTo(Point{x + width, y})
+		out.LineTo(Point{x + width, y + height})
+		out.LineTo(Point{x, y + height})
+		out.Close()
+		return out
+	}
+	handleX, handleY := radiusX*kappa, radiusY*kappa
+	right, bottom := x+width, y+height
+	out.MoveTo(Point{x + radiusX, y})
+	out.LineTo(Point{right - radiusX, y})
+	out.CubicTo(Point{right - radiusX + handleX, y}, Point{right, y + radiusY - handleY}, Point{right, y + radiusY})
+	out.LineTo(Point{right, bottom - radiusY})
+	out.CubicTo(Point{right, bottom - radiusY + handleY}, Point{right - radiusX + handleX, bottom}, Point{right - radiusX, bottom})
+	out.LineTo(Point{x + radiusX, bottom})
+	out.CubicTo(Point{x + radiusX - handleX, bottom}, Point{x, bottom - radiusY + handleY}, Point{x, bottom - radiusY})
+	out.LineTo(Point{x, y + radiusY})
+	out.CubicTo(Point{x, y + radiusY - handleY}, Point{x + radiusX - handleX, y}, Point{x + radiusX, y})
+	out.Close()
+	return out
+}
+
+// pointsPath returns the path through a points attribute, closed for
+// polygons.
+func pointsPath(value string, closed bool) Path {
+	nums := parseNumbers(value)
+	var out Path
+	for idx := 0; idx+1 < len(nums); idx += 2 {
+		pt := Point{nums[idx], nums[idx+1]}
+		if idx == 0 {
+			out.MoveTo(pt)
+		} else {
+			out.LineTo(pt)
+		}
+	}
+	if closed && len(out) > 0 {
+		out.Close()
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package canvas
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseNumbers(t *testing.T) {
+	got := parseNumbers("10,20 -5-6 .5.5 1e2")
+	want := []float64{10, 20, -5, -6, 0.5, 0.5, 100}
+	if len(got) != len(want) {
+		t.Fatalf("parseNumbers = %v, want %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("parseNumbers[%d] = %v, want %v", idx, got[idx], want[idx])
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	m := parseTransform("translate(10,5) rotate(90)")
+	got := m.Apply(Point{1, 0})
+	if math.Abs(got.X-10) > 1e-9 || math.Abs(got.Y-6) > 1e-9 {
+		t.Errorf("apply = %v, want (10, 6)", got)
+	}
+	m = parseTransform("rotate(180, 5, 5)")
+	got = m.Apply(Point{0, 0})
+	if math.Abs(got.X-10) > 1e-9 || math.Abs(got.Y-10) > 1e-9 {
+		t.Errorf("rotate about center = %v, want (10, 10)", got)
+	}
+}
+
+func TestParsePathDataRelative(t *testing.T) {
+	shape, err := parsePathData("m 10 10 h 5 v 5 l -5 0 z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := shape.Flatten()
+	if len(lines) != 1 || !lines[0].Closed {
+		t.Fatalf("flatten = %v, want one closed Polyline", lines)
+	}
+	want := []Point{{10, 10}, {15, 10}, {15, 15}, {10, 15}}
+	for idx, pt := range want {
+		if lines[0].Points[idx] != pt {
+			t.Errorf("Point %d = %v, want %v", idx, lines[0].Points[idx], pt)
+		}
+	}
+}
+
+func TestParsePathDataImplicitLineTo(t *testing.T) {
+	shape, err := parsePathData("M0 0 10 0 10 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pts := shape.Flatten()[0].Points; len(pts) != 3 || pts[2] != (Point{10, 10}) {
+		t.Errorf("points = %v, want three points ending at (10, 10)", pts)
+	}
+}
+
+func TestParsePathDataArc(t *testing.T) {
+	shape, err := parsePathData("M 0 10 A 10 10 0 0 1 20 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pts := shape.Flatten()[0].Points
+	last := pts[len(pts)-1]
+	if math.Abs(last.X-20) > 1e-6 || math.Abs(last.Y-10) > 1e-6 {
+		t.Errorf("arc ends at %v, want (20, 10)", last)
+	}
+	// A clockwise half circle from (0,10) to (20,10) bulges upward.
+	minY := math.Inf(1)
+	for _, pt := range pts {
+		minY = math.Min(minY, pt.Y)
+	}
+	if math.Abs(minY) > 0.1 {
+		t.Errorf("arc top = %v, want about 0", minY)
+	}
+}
+
+func TestParsePathDataError(t *testing.T) {
+	if _, err := parsePathData("M 0 0 L 5"); err == nil {
+		t.Error("expected error for missing coordinate")
+	}
+	if _, err := parsePathData("10 10"); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
+
+func TestRectPathRounded(t *testing.T) {
+	lines := RectPath(0, 0, 20, 10, 5, 5).Flatten()
+	for _, pt := range lines[0].Points {
+		if pt.X < -1e-9 || pt.X > 20+1e-9 || pt.Y < -1e-9 || pt.Y > 10+1e-9 {
+			t.Fatalf("Point %v outside the rectangle", pt)
+		}
+	}
+	if area := math.Abs(signedArea(lines[0].Points)); area >= 200 || area < 175 {
+		t.Errorf("area = %v, want slightly under 200", area)
+	}
+}
@@ -0,0 +1,223 @@
+package canvas
+
+import "math"
+
+// Stroke constants.
+const (
+	// miterLimit is the SVG default stroke-miterlimit.
+	miterLimit = 4.0
+	// minCircleSteps and maxCircleSteps bound the polygon used for round
+	// joins and caps.
+	minCircleSteps = 8
+	maxCircleSteps = 64
+)
+
+// Outline converts polylines into polygons whose union is the stroked
+// outline. Every polygon winds the same way so overlapping pieces add up
+// under the nonzero rule instead of cancelling out.
+func (s Stroke) Outline(lines []Polyline) [][]Point {
+	if s.Width <= 0 {
+		return nil
+	}
+	if len(s.Dash) > 0 {
+		lines = dashLines(lines, s.Dash)
+	}
+	var polys [][]Point
+	add := func(poly []Point) {
+		if area := signedArea(poly); math.Abs(area) > epsilon {
+			if area < 0 {
+				reverse(poly)
+			}
+			polys = append(polys, poly)
+		}
+	}
+	half := s.Width / 2
+	for _, line := range lines {
+		pts := dedupe(line.Points, line.Closed)
+		if len(pts) == 1 {
+			if !line.Closed && s.Cap == "round" {
+				add(circlePolygon(pts[0], half))
+			}
+			if !line.Closed && s.Cap == "square" {
+				add([]Point{
+					pts[0].Add(Point{-half, -half}), pts[0].Add(Point{half, -half}),
+					pts[0].Add(Point{half, half}), pts[0].Add(Point{-half, half}),
+				})
+			}
+			continue
+		}
+		count := len(pts) - 1
+		if line.Closed {
+			pts = append(pts, pts[0])
+			count++
+		}
+		for idx := range count {
+			from, to := pts[idx], pts[idx+1]
+			normal := perpendicular(to.Sub(from)).Scale(half)
+			add([]Point{from.Add(normal), to.Add(normal), to.Sub(normal), from.Sub(normal)})
+		}
+		for idx := 1; idx < count; idx++ {
+			add(joinPolygon(pts[idx-1], pts[idx], pts[idx+1], half, s.Join))
+		}
+		if line.Closed {
+			add(joinPolygon(pts[count-1], pts[0], pts[1], half, s.Join))
+			continue
+		}
+		add(capPolygon(pts[1], pts[0], half, s.Cap))
+		add(capPolygon(pts[count-1], pts[count], half, s.Cap))
+	}
+	return polys
+}
+
+// perpendicular returns the unit normal to the left of direction.
+func perpendicular(direction Point) Point {
+	unit := direction.Normalize()
+	return Point{-unit.Y, unit.X}
+}
+
+// joinPolygon returns the wedge that fills the outer corner where the
+// segment prev-corner meets corner-next.
+func joinPolygon(prev, corner, next Point, half float64, join string) []Point {
+	dirIn := corner.Sub(prev).Normalize()
+	dirOut := next.Sub(corner).Normalize()
+	if math.Abs(dirIn.X*dirOut.Y-dirIn.Y*dirOut.X) < epsilon && dirIn.Dot(dirOut) > 0 {
+		return nil
+	}
+	if join == "round" {
+		return circlePolygon(corner, half)
+	}
+	// The outer side of each segment is the one the other segment turns
+	// away from.
+	outerIn := perpendicular(dirIn)
+	if outerIn.Dot(dirOut) > 0 {
+		outerIn = outerIn.Scale(-1)
+	}
+	outerOut := perpendicular(dirOut)
+	if outerOut.Dot(dirIn) < 0 {
+		outerOut = outerOut.Scale(-1)
+	}
+	edgeIn := corner.Add(outerIn.Scale(half))
+	edgeOut := corner.Add(outerOut.Scale(half))
+	if join == "bevel" {
+		return []Point{corner, edgeIn, edgeOut}
+	}
+	bisector := outerIn.Add(outerOut).Normalize()
+	cosHalf := bisector.Dot(outerIn)
+	if cosHalf < epsilon || 1/cosHalf > miterLimit {
+		return []Point{corner, edgeIn, edgeOut}
+	}
+	return []Point{corner, edgeIn, corner.Add(bisector.Scale(half / cosHalf)), edgeOut}
+}
+
+// capPolygon returns the cap added beyond end, for a line arriving from
+// inner.
+func capPolygon(inner, end Point, half float64, lineCap string) []Point {
+	switch lineCap {
+	case "round":
+		return circlePolygon(end, half)
+	case "square":
+		direction := end.Sub(inner).Normalize().Scale(half)
+		normal := perpendicular(direction).Scale(half)
+		return []Point{
+			end.Add(normal), end.Add(normal).Add(direction),
+			end.Sub(normal).Add(direction), end.Sub(normal),
+		}
+	default:
+		return nil
+	}
+}
+
+// circlePolygon approximates a circle.
+func circlePolygon(center Point, radius float64) []Point {
+	steps := min(max(int(math.Ceil(2*math.Pi*radius/FlattenStep)), minCircleSteps), maxCircleSteps)
+	poly := make([]Point, steps)
+	for idx := range poly {
+		sin, cos := math.Sincos(2 * math.Pi * float64(idx) / float64(steps))
+		poly[idx] = Point{center.X + radius*cos, center.Y + radius*sin}
+	}
+	return poly
+}
+
+// dashLines splits polylines into the "on" pieces of a dash pattern.
+func dashLines(lines []Polyline, pattern []float64) []Polyline {
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern, pattern...)
+	}
+	total := 0.0
+	for _, length := range pattern {
+		total += length
+	}
+	if total <= 0 {
+		return lines
+	}
+	var out []Polyline
+	for _, line := range lines {
+		pts := line.Points
+		if line.Closed && len(pts) > 1 {
+			pts = append(append([]Point{}, pts...), pts[0])
+		}
+		dashIdx, remaining, on := 0, pattern[0], true
+		var current []Point
+		if on {
+			current = []Point{pts[0]}
+		}
+		for idx := 1; idx < len(pts); idx++ {
+			from, to := pts[idx-1], pts[idx]
+			segLen := to.Sub(from).Len()
+			pos := 0.0
+			for segLen-pos > remaining {
+				pos += remaining
+				cut := from.Add(to.Sub(from).Scale(pos / segLen))
+				if on {
+					out = append(out, Polyline{Points: append(current, cut)})
+					current = nil
+				} else {
+					current = []Point{cut}
+				}
+				on = !on
+				dashIdx = (dashIdx + 1) % len(pattern)
+				remaining = pattern[dashIdx]
+			}
+			remaining -= segLen - pos
+			if on {
+				current = append(current, to)
+			}
+		}
+		if on && len(current) > 1 {
+			out = append(out, Polyline{Points: current})
+		}
+	}
+	return out
+}
+
+// dedupe drops consecutive coincident points, and the closing point of a
+// closed polyline that repeats the first.
+func dedupe(pts []Point, closed bool) []Point {
+	out := make([]Point, 0, len(pts))
+	for _, pt := range pts {
+		if len(out) == 0 || pt.Sub(out[len(out)-1]).Len() > epsilon {
+			out = append(out, pt)
+		}
+	}
+	if closed && len(out) > 1 && out[0].Sub(out[len(out)-1]).Len() <= epsilon {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// signedArea returns the shoelace area of a polygon.
+func signedArea(poly []Point) float64 {
+	area := 0.0
+	for idx, pt := range poly {
+		next := poly[(idx+1)%len(poly)]
+		area += pt.X*next.Y - next.X*pt.Y
+	}
+	return area / 2 //nolint:mnd // shoelace formula halves the cross sum.
+}
+
+// reverse reverses a polygon in place.
+func reverse(poly []Point) {
+	for left, right := 0, len(poly)-1; left < right; left, right = left+1, right-1 {
+		poly[left], poly[right] = poly[right], poly[left]
+	}
+}
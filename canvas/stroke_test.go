@@ -0,0 +1,58 @@
+package canvas
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStrokeOutlineWinding(t *testing.T) {
+	lines := []Polyline{{Points: []Point{{0, 0}, {10, 0}, {10, 10}}}}
+	for _, join := range []string{"miter", "round", "bevel"} {
+		polys := Stroke{Width: 2, Cap: "round", Join: join}.Outline(lines)
+		if len(polys) == 0 {
+			t.Fatalf("%s: no polygons", join)
+		}
+		for _, poly := range polys {
+			if signedArea(poly) <= 0 {
+				t.Errorf("%s: polygon %v winds the wrong way", join, poly)
+			}
+		}
+	}
+}
+
+func TestJoinPolygonMiter(t *testing.T) {
+	poly := joinPolygon(Point{0, 0}, Point{10, 0}, Point{10, 10}, 1, "miter")
+	if len(poly) != 4 {
+		t.Fatalf("miter join = %v, want four points", poly)
+	}
+	if tip := poly[2]; math.Abs(tip.X-11) > 1e-9 || math.Abs(tip.Y+1) > 1e-9 {
+		t.Errorf("miter tip = %v, want (11, -1)", tip)
+	}
+	// A hairpin turn exceeds the miter limit and falls back to a bevel.
+	if poly := joinPolygon(Point{0, 0}, Point{10, 0}, Point{0, 0.5}, 1, "miter"); len(poly) != 3 {
+		t.Errorf("sharp join = %v, want a bevel", poly)
+	}
+}
+
+func TestDashLines(t *testing.T) {
+	lines := []Polyline{{Points: []Point{{0, 0}, {10, 0}}}}
+	dashes := dashLines(lines, []float64{3, 2})
+	if len(dashes) != 2 {
+		t.Fatalf("dashes = %v, want 2", dashes)
+	}
+	first := dashes[0].Points
+	if first[0] != (Point{0, 0}) || first[len(first)-1] != (Point{3, 0}) {
+		t.Errorf("first dash = %v, want (0,0)-(3,0)", first)
+	}
+	second := dashes[1].Points
+	if second[0] != (Point{5, 0}) || second[len(second)-1] != (Point{8, 0}) {
+		t.Errorf("second dash = %v, want (5,0)-(8,0)", second)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	got := dedupe([]Point{{0, 0}, {0, 0}, {1, 0}, {0, 0}}, true)
+	if len(got) != 2 {
+		t.Errorf("dedupe = %v, want two points", got)
+	}
+}
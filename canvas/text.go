@@ -0,0 +1,266 @@
+package canvas
+
+import (
+	"image/color"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/jamesainslie/gomd2svg/textmetrics"
+)
+
+// fixedScale converts fixed.Int26_6 values to floats.
+const fixedScale = 64.0
+
+// Face is a font used to draw text.
+type Face struct {
+	Font *sfnt.Font
+	// Data is the font file, or nil when the font cannot be embedded.
+	Data []byte
+	// Name identifies the font, for example in PDF font dictionaries.
+	Name         string
+	Bold, Italic bool
+}
+
+// Glyph is one glyph of a text run.
+type Glyph struct {
+	Face  *Face
+	Index sfnt.GlyphIndex
+	// Rune is the character the glyph draws.
+	Rune rune
+	// X is the glyph origin along the baseline, in run units.
+	X float64
+	// ScaleX stretches the glyph shape horizontally about its origin, as
+	// Layout does when it fits a run to a measured width. Zero means 1.
+	ScaleX float64
+}
+
+// Stretch returns the horizontal scale the glyph is drawn with.
+func (g Glyph) Stretch() float64 {
+	if g.ScaleX == 0 {
+		return 1
+	}
+	return g.ScaleX
+}
+
+// TextRun is a line of positioned glyphs.
+type TextRun struct {
+	Glyphs []Glyph
+	// Size is the font size in run units.
+	Size float64
+	// Transform maps run space, whose origin is the start of the baseline
+	// and whose Y axis points down, to device space.
+	Transform Matrix
+	Paint     color.NRGBA
+}
+
+// Outlines returns the glyph shapes of the run in device space.
+func (r TextRun) Outlines() Path {
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(r.Size * fixedScale)
+	var out Path
+	for _, glyph := range r.Glyphs {
+		segments, err := glyph.Face.Font.LoadGlyph(&buf, glyph.Index, ppem, nil)
+		if err == nil {
+			appendGlyph(&out, segments, Point{glyph.X, 0}, glyph.Stretch())
+		}
+	}
+	return out.Transform(r.Transform)
+}
+
+// goFaces are the embedded Go fonts, used when textmetrics measured a
+// family with its heuristic or when fonts must be embeddable. They are
+// keyed by bold and italic.
+//
+//nolint:gochecknoglobals // parsed once and shared read-only.
+var goFaces = sync.OnceValue(func() map[[2]bool]*Face {
+	faces := make(map[[2]bool]*Face)
+	for key, face := range map[[2]bool]*Face{
+		{false, false}: {Data: goregular.TTF, Name: "GoRegular"},
+		{true, false}:  {Data: gobold.TTF, Name: "GoBold", Bold: true},
+		{false, true}:  {Data: goitalic.TTF, Name: "GoItalic", Italic: true},
+		{true, true}:   {Data: gobolditalic.TTF, Name: "GoBoldItalic", Bold: true, Italic: true},
+	} {
+		parsed, err := sfnt.Parse(face.Data)
+		if err != nil {
+			panic("canvas: embedded font: " + err.Error())
+		}
+		face.Font = parsed
+		faces[key] = face
+	}
+	return faces
+})
+
+// Font is the font state for a run of text.
+type Font struct {
+	Family   string
+	Size     float64
+	Bold     bool
+	Italic   bool
+	Anchor   string // start, middle or end
+	Baseline string // dominant-baseline value
+}
+
+// Typesetter lays out text runs as glyphs.
+type Typesetter struct {
+	measurer   *textmetrics.Measurer
+	embeddable bool
+	buf        sfnt.Buffer
+	system     map[*sfnt.Font]*Face
+}
+
+// NewTypesetter returns a typesetter that fits runs to the widths
+// textmetrics measures, for backends that draw text without an SVG
+// document.
+func NewTypesetter(opts Options) *Typesetter {
+	return &Typesetter{measurer: textmetrics.New(), embeddable: opts.Embeddable}
+}
+
+// face returns the face that draws a style.
+func (t *Typesetter) face(style Font) *Face {
+	if !t.embeddable {
+		if font := t.measurer.Font(style.Family); font != nil {
+			if t.system == nil {
+				t.system = make(map[*sfnt.Font]*Face)
+			}
+			if t.system[font] == nil {
+				t.system[font] = &Face{Font: font, Name: style.Family, Bold: style.Bold, Italic: style.Italic}
+			}
+			return t.system[font]
+		}
+	}
+	return goFaces()[[2]bool{style.Bold, style.Italic}]
+}
+
+// Layout returns the glyphs of text and the offset from the text anchor
+// to the start of the baseline.
+//
+// Each run is fitted to the width textmetrics reports for it, which is
+// the width the layout reserved: glyph advances and shapes are scaled
+// horizontally to match, so the text is neither letterspaced nor
+// squeezed together. When textmetrics measured with a real font, that
+// same font draws the glyphs and no fitting is needed.
+func (t *Typesetter) Layout(text string, style Font) ([]Glyph, Point) {
+	if text == "" || style.Size <= 0 {
+		return nil, Point{}
+	}
+	face := t.face(style)
+	ppem := fixed.Int26_6(style.Size * fixedScale)
+
+	glyphs := make([]Glyph, 0, len(text))
+	advances := make([]float64, 0, len(text))
+	natural := 0.0
+	for _, ch := range text {
+		current := face
+		index, err := current.Font.GlyphIndex(&t.buf, ch)
+		if err != nil || index == 0 {
+			current = goFaces()[[2]bool{false, false}]
+			index, _ = current.Font.GlyphIndex(&t.buf, ch)
+		}
+		advance, err := current.Font.GlyphAdvance(&t.buf, index, ppem, font.HintingNone)
+		if err != nil {
+			advance = 0
+		}
+		glyphs = append(glyphs, Glyph{Face: current, Index: index, Rune: ch})
+		advances = append(advances, float64(advance)/fixedScale)
+		natural += float64(advance) / fixedScale
+	}
+
+	width := float64(t.measurer.Width(text, float32(style.Size), style.Family))
+	fit := 1.0
+	if natural > 0 && width > 0 {
+		fit = width / natural
+	}
+	penX := 0.0
+	for idx := range glyphs {
+		glyphs[idx].X = penX
+		glyphs[idx].ScaleX = fit
+		penX += advances[idx] * fit
+	}
+
+	var offset Point
+	switch style.Anchor {
+	case "middle":
+		offset.X = -width / 2
+	case "end":
+		offset.X = -width
+	}
+	offset.Y = t.baselineShift(face.Font, ppem, style)
+	return glyphs, offset
+}
+
+// baselineShift returns how far below the anchor y the alphabetic
+// baseline sits for the style's dominant-baseline.
+func (t *Typesetter) baselineShift(face *sfnt.Font, ppem fixed.Int26_6, style Font) float64 {
+	metrics, err := face.Metrics(&t.buf, ppem, font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	ascent := float64(metrics.Ascent) / fixedScale
+	descent := float64(metrics.Descent) / fixedScale
+	xHeight := float64(metrics.XHeight) / fixedScale
+	if xHeight <= 0 {
+		xHeight = style.Size / 2 //nolint:mnd // typical x-height when the font omits it.
+	}
+	switch style.Baseline {
+	case "middle":
+		return xHeight / 2
+	case "central":
+		return (ascent - descent) / 2
+	case "hanging", "text-before-edge", "text-top":
+		return ascent
+	case "ideographic", "text-after-edge", "text-bottom":
+		return -descent
+	default:
+		return 0
+	}
+}
+
+// appendGlyph adds glyph outline segments, stretched horizontally by
+// scaleX and offset to origin, as closed subpaths.
+func appendGlyph(out *Path, segments sfnt.Segments, origin Point, scaleX float64) {
+	toPoint := func(pt fixed.Point26_6) Point {
+		return Point{origin.X + scaleX*float64(pt.X)/fixedScale, origin.Y + float64(pt.Y)/fixedScale}
+	}
+	var pen Point
+	started := false
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			if started {
+				out.Close()
+			}
+			pen = toPoint(seg.Args[0])
+			out.MoveTo(pen)
+			started = true
+		case sfnt.SegmentOpLineTo:
+			pen = toPoint(seg.Args[0])
+			out.LineTo(pen)
+		case sfnt.SegmentOpQuadTo:
+			end := toPoint(seg.Args[1])
+			out.QuadTo(pen, toPoint(seg.Args[0]), end)
+			pen = end
+		case sfnt.SegmentOpCubeTo:
+			end := toPoint(seg.Args[2])
+			out.CubicTo(toPoint(seg.Args[0]), toPoint(seg.Args[1]), end)
+			pen = end
+		}
+	}
+	if started {
+		out.Close()
+	}
+}
+
+// collapseSpace applies SVG's default whitespace handling: newlines are
+// removed, tabs become spaces, and runs of spaces collapse to one.
+func collapseSpace(text string) string {
+	text = strings.NewReplacer("\r", "", "\n", "", "\t", " ").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
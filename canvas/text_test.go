@@ -0,0 +1,98 @@
+package canvas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/textmetrics"
+)
+
+// bounds returns the horizontal extent of a flattened path.
+func bounds(shape Path) (float64, float64) {
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, line := range shape.Flatten() {
+		for _, pt := range line.Points {
+			minX = math.Min(minX, pt.X)
+			maxX = math.Max(maxX, pt.X)
+		}
+	}
+	return minX, maxX
+}
+
+// textPath lays out text anchored at origin and returns its outlines.
+func textPath(setter *Typesetter, text string, style Font, origin Point) Path {
+	glyphs, offset := setter.Layout(text, style)
+	run := TextRun{Glyphs: glyphs, Size: style.Size, Transform: Translate(origin.X+offset.X, origin.Y+offset.Y)}
+	return run.Outlines()
+}
+
+func TestLayoutFitsMeasuredWidth(t *testing.T) {
+	measurer := textmetrics.New()
+	setter := Typesetter{measurer: measurer}
+	style := Font{Family: "test-family", Size: 20, Anchor: "start"}
+	text := "Hello World"
+	want := float64(measurer.Width(text, 20, "test-family"))
+
+	minX, maxX := bounds(textPath(&setter, text, style, Point{100, 50}))
+	if minX < 100-1 || maxX > 100+want+1 {
+		t.Errorf("glyphs span %.1f-%.1f, want within 100-%.1f", minX, maxX, 100+want)
+	}
+	if maxX-minX < want*0.8 {
+		t.Errorf("glyphs span %.1f, want close to measured %.1f", maxX-minX, want)
+	}
+}
+
+func TestLayoutStretchesGlyphShapes(t *testing.T) {
+	setter := Typesetter{measurer: textmetrics.New()}
+	style := Font{Family: "test-family", Size: 20, Anchor: "start"}
+	glyphs, _ := setter.Layout("m", style)
+	if len(glyphs) != 1 {
+		t.Fatalf("glyphs = %d, want 1", len(glyphs))
+	}
+	fit := glyphs[0].Stretch()
+	if math.Abs(fit-1) < 0.01 {
+		t.Skipf("measured width matches the Go font (fit %.3f)", fit)
+	}
+
+	// The fitted glyph is its natural shape scaled by the fit, so its
+	// ink keeps the same share of the advance.
+	fitted := TextRun{Glyphs: glyphs, Size: style.Size}
+	natural := glyphs[0]
+	natural.ScaleX = 1
+	plain := TextRun{Glyphs: []Glyph{natural}, Size: style.Size}
+	fitMin, fitMax := bounds(fitted.Outlines())
+	plainMin, plainMax := bounds(plain.Outlines())
+	if got, want := fitMax-fitMin, (plainMax-plainMin)*fit; math.Abs(got-want) > 0.01 {
+		t.Errorf("fitted glyph spans %.2f, want %.2f (natural %.2f x fit %.3f)", got, want, plainMax-plainMin, fit)
+	}
+}
+
+func TestLayoutAnchor(t *testing.T) {
+	setter := Typesetter{measurer: textmetrics.New()}
+	style := Font{Family: "test-family", Size: 20, Anchor: "middle"}
+	minX, maxX := bounds(textPath(&setter, "ABC", style, Point{100, 50}))
+	if center := (minX + maxX) / 2; math.Abs(center-100) > 3 {
+		t.Errorf("middle-anchored center = %.1f, want about 100", center)
+	}
+	style.Anchor = "end"
+	if _, maxX := bounds(textPath(&setter, "ABC", style, Point{100, 50})); maxX > 100+1 {
+		t.Errorf("end-anchored text reaches %.1f, want at most 100", maxX)
+	}
+}
+
+func TestCollapseSpace(t *testing.T) {
+	if got := collapseSpace("  a \n b\t\tc  "); got != "a b c" {
+		t.Errorf("collapseSpace = %q", got)
+	}
+}
+
+func TestEmbeddableUsesGoFonts(t *testing.T) {
+	setter := Typesetter{measurer: textmetrics.New(), embeddable: true}
+	glyphs, _ := setter.Layout("Hi", Font{Family: "Helvetica", Size: 12, Bold: true})
+	if len(glyphs) != 2 {
+		t.Fatalf("glyphs = %d, want 2", len(glyphs))
+	}
+	if face := glyphs[0].Face; face.Data == nil || !face.Bold || glyphs[1].X <= 0 {
+		t.Errorf("glyph face = %+v, want embeddable bold face with advancing glyphs", face)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is one line of an edit script.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns a unified diff turning before into after, or an
+// empty string when they are equal.
+func unifiedDiff(oldName, newName, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		hunkStart := max(start-diffContext, 0)
+		// Extend the hunk while changes are within 2*context of each other.
+		end := start
+		for idx := start; idx < len(ops); idx++ {
+			if ops[idx].kind != ' ' {
+				end = idx + 1
+				continue
+			}
+			if idx-end >= 2*diffContext {
+				break
+			}
+		}
+		hunkEnd := min(end+diffContext, len(ops))
+		writeHunk(&out, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+// writeHunk writes one @@ hunk covering ops[from:to].
+func writeHunk(out *strings.Builder, ops []diffOp, from, to int) {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+	for _, op := range ops[from:to] {
+		out.WriteByte(op.kind)
+		out.WriteString(op.text)
+		out.WriteByte('\n')
+	}
+}
+
+// hunkRange formats a hunk position as "start,count".
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a line edit script from the longest common
+// subsequence. Diagram sources are small, so the quadratic table is fine.
+func diffLines(before, after []string) []diffOp {
+	lcs := make([][]int, len(before)+1)
+	for idx := range lcs {
+		lcs[idx] = make([]int, len(after)+1)
+	}
+	for oldIdx := len(before) - 1; oldIdx >= 0; oldIdx-- {
+		for newIdx := len(after) - 1; newIdx >= 0; newIdx-- {
+			if before[oldIdx] == after[newIdx] {
+				lcs[oldIdx][newIdx] = lcs[oldIdx+1][newIdx+1] + 1
+			} else {
+				lcs[oldIdx][newIdx] = max(lcs[oldIdx+1][newIdx], lcs[oldIdx][newIdx+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(before)+len(after))
+	oldIdx, newIdx := 0, 0
+	for oldIdx < len(before) && newIdx < len(after) {
+		switch {
+		case before[oldIdx] == after[newIdx]:
+			ops = append(ops, diffOp{' ', before[oldIdx]})
+			oldIdx++
+			newIdx++
+		case lcs[oldIdx+1][newIdx] >= lcs[oldIdx][newIdx+1]:
+			ops = append(ops, diffOp{'-', before[oldIdx]})
+			oldIdx++
+		default:
+			ops = append(ops, diffOp{'+', after[newIdx]})
+			newIdx++
+		}
+	}
+	for ; oldIdx < len(before); oldIdx++ {
+		ops = append(ops, diffOp{'-', before[oldIdx]})
+	}
+	for ; newIdx < len(after); newIdx++ {
+		ops = append(ops, diffOp{'+', after[newIdx]})
+	}
+	return ops
+}
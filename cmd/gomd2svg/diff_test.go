@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiffEqual(t *testing.T) {
+	if got := unifiedDiff("a", "b", "x\n", "x\n"); got != "" {
+		t.Errorf("unifiedDiff(equal) = %q, want empty", got)
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\n11\nTWELVE\n"
+	want := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ 1
+-2
++TWO
+ 3
+ 4
+ 5
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++TWELVE
+`
+	if got := unifiedDiff("a", "b", before, after); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/jamesainslie/gomd2svg"
+	"github.com/jamesainslie/gomd2svg/lint"
+	"github.com/jamesainslie/gomd2svg/printer"
+	"github.com/jamesainslie/gomd2svg/raster"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
@@ -30,6 +35,10 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	switch args[0] {
 	case "render":
 		return runRender(args[1:], stdin, stdout, stderr)
+	case "fmt":
+		return runFmt(args[1:], stdin, stdout, stderr)
+	case "lint":
+		return runLint(args[1:], stdin, stdout, stderr)
 	case "themes":
 		return runThemes(stdout)
 	case "version":
@@ -48,9 +57,41 @@ func runRender(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	output := fs.String("o", "", "output file (default: stdout)")
 	themeName := fs.String("theme", "", "theme name (modern|default|dark|forest|neutral)")
 	timing := fs.Bool("timing", false, "print timing info to stderr")
+	format := fs.String("format", "", "output format (svg|png|pdf; default: from -o extension, else svg)")
+	scale := fs.Float64("scale", 1, "pixel scale for png output, page scale for pdf output")
+	paper := fs.String("paper", "", "pdf paper size (a3|a4|a5|letter|legal|tabloid; default: sized to diagram)")
+	landscape := fs.Bool("landscape", false, "use landscape orientation for pdf paper")
+	pageHeight := fs.Float64("page-height", 0, "split long sequence diagrams into pages of at most this many pixels")
+	packetJSON := fs.String("packet-json", "", "also write a packet diagram's field table as JSON to this file")
+	var iconSets []string
+	fs.Func("icons", "load an Iconify JSON icon set file (repeatable)", func(path string) error {
+		iconSets = append(iconSets, path)
+		return nil
+	})
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if *format == "" {
+		*format = "svg"
+		switch strings.ToLower(filepath.Ext(*output)) {
+		case ".png":
+			*format = "png"
+		case ".pdf":
+			*format = "pdf"
+		}
+	}
+	if *format != "svg" && *format != "png" && *format != "pdf" {
+		return fmt.Errorf("unknown render format: %s", *format)
+	}
+	if *scale <= 0 {
+		return fmt.Errorf("invalid scale: %g", *scale)
+	}
+	if *pageHeight < 0 {
+		return fmt.Errorf("invalid page height: %g", *pageHeight)
+	}
+	if *pageHeight > 0 && *format == "pdf" {
+		return errors.New("-page-height is not supported for pdf output")
+	}
 
 	var input []byte
 	var err error
@@ -70,10 +111,24 @@ func runRender(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 		return errors.New("empty input")
 	}
 
+	if *packetJSON != "" {
+		if err := writePacketJSON(*packetJSON, string(input)); err != nil {
+			return err
+		}
+	}
+
 	opts := gomd2svg.Options{}
 	if *themeName != "" {
 		opts.ThemeName = *themeName
 	}
+	if len(iconSets) > 0 {
+		opts.Icons = gomd2svg.NewIconRegistry()
+		for _, path := range iconSets {
+			if err := opts.Icons.LoadFile(path); err != nil {
+				return err
+			}
+		}
+	}
 
 	if *timing {
 		result, err := gomd2svg.RenderWithTiming(string(input), opts)
@@ -82,19 +137,213 @@ func runRender(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 		}
 		fmt.Fprintf(stderr, "parse: %dus  layout: %dus  render: %dus  total: %.1fms\n",
 			result.ParseUs, result.LayoutUs, result.RenderUs, result.TotalMs())
-		return writeOutput(*output, result.SVG, stdout)
+		if *format != "pdf" && *pageHeight == 0 {
+			return writeRendered(*output, result.SVG, *format, *scale, stdout)
+		}
+	}
+
+	if *format == "pdf" {
+		opts.Scale = *scale
+		opts.Paper = *paper
+		opts.Landscape = *landscape
+		data, err := gomd2svg.RenderPDF(string(input), opts)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, data, stdout)
+	}
+
+	if *pageHeight > 0 {
+		opts.PageHeight = float32(*pageHeight)
+		pages, err := gomd2svg.RenderPages(string(input), opts)
+		if err != nil {
+			return err
+		}
+		return writePages(*output, pages, *format, *scale, stdout)
 	}
 
 	svg, err := gomd2svg.RenderWithOptions(string(input), opts)
 	if err != nil {
 		return err
 	}
-	return writeOutput(*output, svg, stdout)
+	return writeRendered(*output, svg, *format, *scale, stdout)
+}
+
+// writePages writes the pages of a split diagram. A single page goes to
+// path itself; several pages go to numbered files such as out-1.svg and
+// out-2.svg.
+func writePages(path string, pages []string, format string, scale float64, stdout io.Writer) error {
+	if len(pages) == 1 {
+		return writeRendered(path, pages[0], format, scale, stdout)
+	}
+	if path == "" {
+		return fmt.Errorf("diagram has %d pages; use -o to name the output files", len(pages))
+	}
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for idx, svg := range pages {
+		if err := writeRendered(fmt.Sprintf("%s-%d%s", stem, idx+1, ext), svg, format, scale, stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePacketJSON writes the field table of a packet diagram to path.
+func writePacketJSON(path, input string) error {
+	fields, err := gomd2svg.PacketFields(input)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, append(data, '\n'), nil)
+}
+
+// writeRendered writes svg in the requested output format.
+func writeRendered(path, svg, format string, scale float64, stdout io.Writer) error {
+	if format != "png" {
+		return writeOutput(path, []byte(svg), stdout)
+	}
+	data, err := raster.PNG(svg, scale)
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, data, stdout)
+}
+
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	write := fs.Bool("w", false, "write result to the source file instead of stdout")
+	diff := fs.Bool("d", false, "print a diff instead of the formatted source")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		if *write {
+			return errors.New("cannot use -w with standard input")
+		}
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		return formatSource("<stdin>", string(input), false, *diff, stdout)
+	}
+
+	var failed bool
+	for _, path := range fs.Args() {
+		input, err := os.ReadFile(path)
+		if err == nil {
+			err = formatSource(path, string(input), *write, *diff, stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			failed = true
+		}
+	}
+	if failed {
+		return errors.New("fmt: some files could not be formatted")
+	}
+	return nil
+}
+
+// formatSource formats one diagram and writes, diffs or prints the result.
+func formatSource(path, input string, write, diff bool, stdout io.Writer) error {
+	formatted, err := printer.Format(input)
+	if err != nil {
+		return err
+	}
+	if diff {
+		if _, err := io.WriteString(stdout, unifiedDiff(path+".orig", path, input, formatted)); err != nil {
+			return err
+		}
+	}
+	if write {
+		if formatted == input {
+			return nil
+		}
+		return os.WriteFile(path, []byte(formatted), 0o600)
+	}
+	if !diff {
+		_, err = io.WriteString(stdout, formatted)
+	}
+	return err
+}
+
+// lintResult is one diagnostic tagged with the file it came from.
+type lintResult struct {
+	File string `json:"file"`
+	lint.Diagnostic
 }
 
-func writeOutput(path, svg string, stdout io.Writer) error {
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format (text|json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown lint format: %s", *format)
+	}
+
+	type source struct{ path, text string }
+	var sources []source
+	if fs.NArg() == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{"<stdin>", string(input)})
+	}
+	for _, path := range fs.Args() {
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{path, string(input)})
+	}
+
+	results := []lintResult{}
+	for _, src := range sources {
+		diags, err := lint.Lint(src.text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src.path, err)
+		}
+		for _, diag := range diags {
+			results = append(results, lintResult{File: src.path, Diagnostic: diag})
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, res := range results {
+			location := res.File
+			if res.Line > 0 {
+				location += ":" + strconv.Itoa(res.Line)
+			}
+			fmt.Fprintf(stdout, "%s: %s\n", location, res.Diagnostic)
+		}
+	}
+	if len(results) > 0 {
+		return fmt.Errorf("lint: %d problem(s) found", len(results))
+	}
+	return nil
+}
+
+func writeOutput(path string, data []byte, stdout io.Writer) error {
 	if path == "" {
-		_, err := io.WriteString(stdout, svg)
+		_, err := stdout.Write(data)
 		return err
 	}
 	dir := filepath.Dir(path)
@@ -103,7 +352,7 @@ func writeOutput(path, svg string, stdout io.Writer) error {
 			return err
 		}
 	}
-	return os.WriteFile(path, []byte(svg), 0o600)
+	return os.WriteFile(path, data, 0o600)
 }
 
 func runThemes(w io.Writer) error {
@@ -117,7 +366,9 @@ func usage(w io.Writer) error {
 	fmt.Fprintln(w, `Usage: gomd2svg <command> [options]
 
 Commands:
-  render [file]   Render a .mmd file to SVG
+  render [file]   Render a .mmd file to SVG, PNG or PDF
+  fmt [files]     Format .mmd files in canonical style
+  lint [files]    Check .mmd files for likely mistakes
   themes          List available themes
   version         Print version
 
@@ -125,11 +376,31 @@ Render options:
   -o <file>       Output file (default: stdout)
   -theme <name>   Theme: modern, default, dark, forest, neutral
   -timing         Print timing info to stderr
+  -format <fmt>   Output format: svg, png, pdf (default: from -o extension, else svg)
+  -scale <n>      Pixel scale for png output, page scale for pdf output (default 1)
+  -paper <size>   PDF paper size: a3, a4, a5, letter, legal, tabloid
+                  (default: page sized to the diagram)
+  -landscape      Turn the PDF paper size sideways
+  -page-height <n>
+                  Split long sequence diagrams into pages of at most n
+                  pixels, written as out-1.svg, out-2.svg, ...
+
+Fmt options:
+  -w              Write result back to each file
+  -d              Print a diff instead of the formatted source
+
+Lint options:
+  -format <fmt>   Output format: text, json (exit status 1 on findings)
 
 Examples:
   gomd2svg render diagram.mmd -o diagram.svg
   gomd2svg render -theme dark diagram.mmd > out.svg
   cat diagram.mmd | gomd2svg render > out.svg
-  gomd2svg render -theme forest -timing diagram.mmd -o out.svg`)
+  gomd2svg render -theme forest -timing diagram.mmd -o out.svg
+  gomd2svg render -format png -scale 2 diagram.mmd -o diagram.png
+  gomd2svg render -paper a4 -landscape diagram.mmd -o diagram.pdf
+  gomd2svg render -page-height 1200 protocol.mmd -o protocol.svg
+  gomd2svg fmt -w diagrams/*.mmd
+  gomd2svg lint -format json diagrams/*.mmd`)
 	return nil
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
@@ -69,6 +71,248 @@ func TestRenderTiming(t *testing.T) {
 	}
 }
 
+func TestRenderPNG(t *testing.T) {
+	var small, large, stderr bytes.Buffer
+	src := "flowchart LR\n  A-->B"
+	if err := run([]string{"render", "-format", "png"}, strings.NewReader(src), &small, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"render", "-format", "png", "-scale", "2"}, strings.NewReader(src), &large, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	smallCfg, err := png.DecodeConfig(&small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	largeCfg, err := png.DecodeConfig(&large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if largeCfg.Width < 2*smallCfg.Width-1 || largeCfg.Width > 2*smallCfg.Width {
+		t.Errorf("scaled width = %d, want about 2x %d", largeCfg.Width, smallCfg.Width)
+	}
+}
+
+func TestRenderPNGFromExtension(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.png")
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "-o", out, "../../testdata/fixtures/flowchart-simple.mmd"}, nil, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("expected PNG data in output file")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "-format", "pdf", "-paper", "letter", "-landscape"},
+		strings.NewReader("flowchart LR\n  A-->B"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(stdout.Bytes(), []byte("%PDF-")) {
+		t.Error("expected PDF data on stdout")
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("/MediaBox [0 0 792 612]")) {
+		t.Error("expected a landscape letter page")
+	}
+}
+
+func TestRenderPDFFromExtension(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.pdf")
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "-o", out, "../../testdata/fixtures/flowchart-simple.mmd"}, nil, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Error("expected PDF data in output file")
+	}
+}
+
+func TestRenderPages(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("sequenceDiagram\n")
+	for range 30 {
+		src.WriteString("    A->>B: ping\n")
+	}
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "-page-height", "500", "-o", filepath.Join(dir, "out.svg")},
+		strings.NewReader(src.String()), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"out-1.svg", "out-2.svg"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, []byte("<svg")) {
+			t.Errorf("%s: expected SVG data", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.svg")); err == nil {
+		t.Error("unsplit output file should not be written")
+	}
+
+	err = run([]string{"render", "-page-height", "500"}, strings.NewReader(src.String()), &stdout, &stderr)
+	if err == nil {
+		t.Error("expected error for several pages without -o")
+	}
+}
+
+func TestRenderPacketJSON(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "fields.json")
+	stdin := strings.NewReader("packet-beta\n0-15: \"Source Port\"\n+16: \"Destination Port\"")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"render", "-packet-json", out}, stdin, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "<svg") {
+		t.Error("expected SVG output alongside the field table")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields []struct {
+		Start, End, Bits int
+		Description      string
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields[1].Start != 16 || fields[1].End != 31 || fields[1].Bits != 16 {
+		t.Errorf("fields = %+v, want the destination port at bits 16-31", fields)
+	}
+
+	stdin = strings.NewReader("flowchart LR\n  A-->B")
+	if err := run([]string{"render", "-packet-json", out}, stdin, &stdout, &stderr); err == nil {
+		t.Error("expected an error for a flowchart")
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"render", "-format", "gif"}, strings.NewReader("flowchart LR\n  A-->B"), &stdout, &stderr)
+	if err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestFmtStdin(t *testing.T) {
+	stdin := strings.NewReader("graph LR\nA-->B")
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"fmt"}, stdin, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "flowchart LR\n    A --> B\n"; got != want {
+		t.Errorf("fmt output = %q, want %q", got, want)
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.mmd")
+	if err := os.WriteFile(path, []byte("graph LR\nA-->B"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"fmt", "-w", path}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "flowchart LR\n    A --> B\n" {
+		t.Errorf("file = %q", data)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("unexpected stdout %q", stdout.String())
+	}
+}
+
+func TestFmtDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.mmd")
+	if err := os.WriteFile(path, []byte("graph LR\nA-->B\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"fmt", "-d", path}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-graph LR", "+flowchart LR", "+    A --> B"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("diff missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestFmtParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.mmd")
+	if err := os.WriteFile(path, []byte("zenuml\n  A.run() {"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"fmt", path}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for unparsable diagram")
+	}
+	if !strings.Contains(stderr.String(), path) {
+		t.Errorf("stderr should name the file: %q", stderr.String())
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"lint", "../../testdata/fixtures/flowchart-simple.mmd"}, nil, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("lint error: %v\n%s", err, stdout.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
+
+func TestLintFindings(t *testing.T) {
+	stdin := strings.NewReader("flowchart LR\n    A --> B\n    class X hot\n")
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"lint"}, stdin, &stdout, &stderr)
+	if err == nil {
+		t.Fatal("expected error when lint finds problems")
+	}
+	want := `<stdin>:3: error: class statement references undefined node "X" (undefined-reference)`
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestLintJSON(t *testing.T) {
+	stdin := strings.NewReader("sankey-beta\nA,B,1\nB,A,1\n")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"lint", "-format", "json"}, stdin, &stdout, &stderr); err == nil {
+		t.Fatal("expected error when lint finds problems")
+	}
+	var results []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(results) != 1 || results[0]["rule"] != "sankey-cycle" || results[0]["file"] != "<stdin>" {
+		t.Errorf("results = %v", results)
+	}
+}
+
 func TestThemes(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	err := run([]string{"themes"}, nil, &stdout, &stderr)
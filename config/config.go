@@ -1,5 +1,11 @@
 package config
 
+import (
+	"time"
+
+	"github.com/jamesainslie/gomd2svg/icons"
+)
+
 // Layout holds all configuration for diagram layout computation.
 type Layout struct {
 	NodeSpacing          float32
@@ -29,6 +35,10 @@ type Layout struct {
 	C4                   C4Config
 	Journey              JourneyConfig
 	Architecture         ArchitectureConfig
+	// Icons resolves icon names used by architecture services and groups,
+	// flowchart icon nodes and mindmap ::icon() decorations. Nil leaves
+	// only the built-in architecture glyphs.
+	Icons *icons.Registry
 }
 
 // FlowchartConfig holds flowchart-specific layout options.
@@ -68,6 +78,8 @@ type ERConfig struct {
 
 // SequenceConfig holds sequence diagram layout options.
 type SequenceConfig struct {
+	// ParticipantSpacing is the smallest gap between participant boxes.
+	// Gaps grow to fit the messages and notes drawn between them.
 	ParticipantSpacing float32
 	MessageSpacing     float32
 	ActivationWidth    float32
@@ -76,6 +88,12 @@ type SequenceConfig struct {
 	FramePadding       float32
 	HeaderHeight       float32
 	SelfMessageWidth   float32
+	// Wrap wraps message and note text that does not say nowrap:, as the
+	// %%{wrap}%% directive does.
+	Wrap bool
+	// WrapWidth is the widest a wrapped message label may be. Wrapped
+	// notes are limited by NoteMaxWidth instead.
+	WrapWidth float32
 }
 
 // KanbanConfig holds Kanban diagram layout options.
@@ -92,8 +110,10 @@ type PacketConfig struct {
 	BitWidth   float32
 	BitsPerRow int
 	ShowBits   bool
-	PaddingX   float32
-	PaddingY   float32
+	// BitRuler draws a ruler of absolute bit numbers above every row.
+	BitRuler bool
+	PaddingX float32
+	PaddingY float32
 }
 
 // PieConfig holds pie chart layout options.
@@ -103,8 +123,21 @@ type PieConfig struct {
 	TextPosition float32
 	PaddingX     float32
 	PaddingY     float32
+	// LegendPosition places the colour legend beside or below the pie,
+	// or hides it.
+	LegendPosition LegendPosition
 }
 
+// LegendPosition says where a chart draws its legend.
+type LegendPosition string
+
+// Legend positions.
+const (
+	LegendRight  LegendPosition = "right"
+	LegendBottom LegendPosition = "bottom"
+	LegendNone   LegendPosition = "none"
+)
+
 // QuadrantConfig holds quadrant chart layout options.
 type QuadrantConfig struct {
 	ChartWidth            float32
@@ -135,6 +168,12 @@ type GanttConfig struct {
 	FontSize             float32
 	SectionFontSize      float32
 	NumberSectionStyles  int
+	// TopAxis repeats the date labels above the chart, as the topAxis
+	// keyword does for a single chart.
+	TopAxis bool
+	// Now returns the time the today marker shows. Nil means time.Now;
+	// tests set a fixed clock so output is deterministic.
+	Now func() time.Time
 }
 
 // GitGraphConfig holds GitGraph diagram layout options.
@@ -145,6 +184,9 @@ type GitGraphConfig struct {
 	PaddingX      float32
 	PaddingY      float32
 	TagFontSize   float32
+	// ParallelCommits places commits by their distance from the branch
+	// point instead of in statement order, so parallel work lines up.
+	ParallelCommits bool
 }
 
 // XYChartConfig holds XY chart layout options.
@@ -157,6 +199,15 @@ type XYChartConfig struct {
 	TickLength    float32
 	AxisFontSize  float32
 	TitleFontSize float32
+	// LegendPosition places the series legend beside or below the chart,
+	// or hides it. The legend is drawn only when a series has a name.
+	LegendPosition LegendPosition
+	// ShowDataLabels labels each bar and line point with its value.
+	ShowDataLabels bool
+	// NumberFormat formats axis ticks and data labels with a d3-style
+	// specifier such as ".2f", ",d", ".1%" or ".2s" (1.2k). Empty means
+	// the shortest plain number.
+	NumberFormat string
 }
 
 // RadarConfig holds radar chart layout options.
@@ -176,8 +227,26 @@ type MindmapConfig struct {
 	PaddingX      float32
 	PaddingY      float32
 	NodePadding   float32
+	// SiblingSpacing separates neighbouring subtrees in the tree layout.
+	SiblingSpacing float32
+	// Layout selects the radial or the two-sided tree layout. A diagram's
+	// init directive overrides it.
+	Layout MindmapLayoutMode
 }
 
+// MindmapLayoutMode selects how mindmaps place their nodes.
+type MindmapLayoutMode string
+
+// Mindmap layout modes.
+const (
+	// MindmapLayoutRadial spreads branches around the root by angle.
+	MindmapLayoutRadial MindmapLayoutMode = "radial"
+	// MindmapLayoutTree grows branches left and right of the root in
+	// columns, packing subtrees tightly without overlaps. It suits deep
+	// mindmaps.
+	MindmapLayoutTree MindmapLayoutMode = "tree"
+)
+
 // SankeyConfig holds Sankey diagram layout options.
 type SankeyConfig struct {
 	ChartWidth  float32
@@ -186,7 +255,39 @@ type SankeyConfig struct {
 	NodePadding float32
 	PaddingX    float32
 	PaddingY    float32
-}
+	// NodeAlignment picks each node's column. A diagram's init directive
+	// overrides it.
+	NodeAlignment SankeyAlignment
+	// LinkColor colours the links: "source" or "target" for the colour of
+	// the node at that end, "gradient" to blend from one to the other, a
+	// colour such as "#aaa", or empty for the theme's link colour. A
+	// diagram's init directive overrides it.
+	LinkColor string
+	// ShowValues adds each node's value to its label. A diagram's init
+	// directive overrides it.
+	ShowValues bool
+	// Iterations is how many relaxation passes move nodes toward the
+	// nodes they link to, shortening links and removing crossings.
+	Iterations int
+}
+
+// SankeyAlignment selects the column of each Sankey node, as d3-sankey's
+// node alignments do.
+type SankeyAlignment string
+
+// Sankey node alignments.
+const (
+	// SankeyAlignJustify puts sources at the left, nodes without outgoing
+	// links in the last column and others by their distance from a source.
+	SankeyAlignJustify SankeyAlignment = "justify"
+	// SankeyAlignLeft places nodes by their distance from a source.
+	SankeyAlignLeft SankeyAlignment = "left"
+	// SankeyAlignRight places nodes by their distance to a sink.
+	SankeyAlignRight SankeyAlignment = "right"
+	// SankeyAlignCenter is left alignment with each source moved next to
+	// its nearest target.
+	SankeyAlignCenter SankeyAlignment = "center"
+)
 
 // TreemapConfig holds Treemap diagram layout options.
 type TreemapConfig struct {
@@ -198,6 +299,14 @@ type TreemapConfig struct {
 	PaddingY      float32
 	LabelFontSize float32
 	ValueFontSize float32
+	// ValueFormat is a d3-format specifier for leaf values and section
+	// totals, such as ",.0f", "$,.2f" or ".1%". A diagram's init directive
+	// overrides it.
+	ValueFormat string
+	// MaxDepth is how many levels below the root are drawn; sections at
+	// the last level are drawn whole, with their total. Zero draws all
+	// levels. A diagram's init directive overrides it.
+	MaxDepth int
 }
 
 // RequirementConfig holds requirement diagram layout options.
@@ -227,7 +336,31 @@ type C4Config struct {
 	BoundaryPadding float32
 	PaddingX        float32
 	PaddingY        float32
-}
+	// ShapeInRow and BoundaryInRow are how many elements and boundaries
+	// are placed side by side before starting a new row. A diagram's
+	// UpdateLayoutConfig overrides them.
+	ShapeInRow    int
+	BoundaryInRow int
+	// ShapeMargin separates neighbouring elements and boundaries.
+	ShapeMargin float32
+	// Layout selects row packing or the layered graph layout. A diagram's
+	// init directive overrides it.
+	Layout C4LayoutMode
+}
+
+// C4LayoutMode selects how C4 diagrams place their elements.
+type C4LayoutMode string
+
+// C4 layout modes.
+const (
+	// C4LayoutRows packs elements into rows inside their boundaries, the
+	// way Mermaid does.
+	C4LayoutRows C4LayoutMode = "rows"
+	// C4LayoutGraph ranks elements by their relationships, keeps each
+	// boundary's elements together and routes relationships around
+	// elements. It suits large container and component views.
+	C4LayoutGraph C4LayoutMode = "graph"
+)
 
 // JourneyConfig holds journey diagram layout options.
 type JourneyConfig struct {
@@ -303,6 +436,7 @@ const (
 	defaultSeqFramePadding       = 10
 	defaultSeqHeaderHeight       = 40
 	defaultSeqSelfMessageWidth   = 40
+	defaultSeqWrapWidth          = 200
 )
 
 // Kanban defaults.
@@ -396,11 +530,12 @@ const (
 
 // Mindmap defaults.
 const (
-	defaultMindmapBranchSpacing = 80
-	defaultMindmapLevelSpacing  = 60
-	defaultMindmapPaddingX      = 40
-	defaultMindmapPaddingY      = 40
-	defaultMindmapNodePadding   = 12
+	defaultMindmapBranchSpacing  = 80
+	defaultMindmapLevelSpacing   = 60
+	defaultMindmapPaddingX       = 40
+	defaultMindmapPaddingY       = 40
+	defaultMindmapNodePadding    = 12
+	defaultMindmapSiblingSpacing = 16
 )
 
 // Sankey defaults.
@@ -411,6 +546,7 @@ const (
 	defaultSankeyNodePadding = 10
 	defaultSankeyPaddingX    = 40
 	defaultSankeyPaddingY    = 20
+	defaultSankeyIterations  = 6
 )
 
 // Treemap defaults.
@@ -452,6 +588,9 @@ const (
 	defaultC4BoundaryPadding = 20
 	defaultC4PaddingX        = 20
 	defaultC4PaddingY        = 20
+	defaultC4ShapeInRow      = 4
+	defaultC4BoundaryInRow   = 2
+	defaultC4ShapeMargin     = 50
 )
 
 // Journey diagram defaults.
@@ -527,6 +666,7 @@ func defaultSequenceConfig() SequenceConfig {
 		FramePadding:       defaultSeqFramePadding,
 		HeaderHeight:       defaultSeqHeaderHeight,
 		SelfMessageWidth:   defaultSeqSelfMessageWidth,
+		WrapWidth:          defaultSeqWrapWidth,
 	}
 }
 
@@ -552,11 +692,12 @@ func defaultPacketConfig() PacketConfig {
 
 func defaultPieConfig() PieConfig {
 	return PieConfig{
-		Radius:       defaultPieRadius,
-		InnerRadius:  0,
-		TextPosition: defaultPieTextPosition,
-		PaddingX:     defaultPiePaddingX,
-		PaddingY:     defaultPiePaddingY,
+		Radius:         defaultPieRadius,
+		InnerRadius:    0,
+		TextPosition:   defaultPieTextPosition,
+		PaddingX:       defaultPiePaddingX,
+		PaddingY:       defaultPiePaddingY,
+		LegendPosition: LegendRight,
 	}
 }
 
@@ -608,14 +749,15 @@ func defaultGitGraphConfig() GitGraphConfig {
 
 func defaultXYChartConfig() XYChartConfig {
 	return XYChartConfig{
-		ChartWidth:    defaultXYChartWidth,
-		ChartHeight:   defaultXYChartHeight,
-		PaddingX:      defaultXYPaddingX,
-		PaddingY:      defaultXYPaddingY,
-		BarWidth:      defaultXYBarWidth,
-		TickLength:    defaultXYTickLength,
-		AxisFontSize:  defaultXYAxisFontSize,
-		TitleFontSize: defaultXYTitleFontSize,
+		ChartWidth:     defaultXYChartWidth,
+		ChartHeight:    defaultXYChartHeight,
+		PaddingX:       defaultXYPaddingX,
+		PaddingY:       defaultXYPaddingY,
+		BarWidth:       defaultXYBarWidth,
+		TickLength:     defaultXYTickLength,
+		AxisFontSize:   defaultXYAxisFontSize,
+		TitleFontSize:  defaultXYTitleFontSize,
+		LegendPosition: LegendRight,
 	}
 }
 
@@ -632,11 +774,13 @@ func defaultRadarConfig() RadarConfig {
 
 func defaultMindmapConfig() MindmapConfig {
 	return MindmapConfig{
-		BranchSpacing: defaultMindmapBranchSpacing,
-		LevelSpacing:  defaultMindmapLevelSpacing,
-		PaddingX:      defaultMindmapPaddingX,
-		PaddingY:      defaultMindmapPaddingY,
-		NodePadding:   defaultMindmapNodePadding,
+		BranchSpacing:  defaultMindmapBranchSpacing,
+		LevelSpacing:   defaultMindmapLevelSpacing,
+		PaddingX:       defaultMindmapPaddingX,
+		PaddingY:       defaultMindmapPaddingY,
+		NodePadding:    defaultMindmapNodePadding,
+		SiblingSpacing: defaultMindmapSiblingSpacing,
+		Layout:         MindmapLayoutRadial,
 	}
 }
 
@@ -648,6 +792,10 @@ func defaultSankeyConfig() SankeyConfig {
 		NodePadding: defaultSankeyNodePadding,
 		PaddingX:    defaultSankeyPaddingX,
 		PaddingY:    defaultSankeyPaddingY,
+		// Mermaid's defaults.
+		NodeAlignment: SankeyAlignJustify,
+		ShowValues:    true,
+		Iterations:    defaultSankeyIterations,
 	}
 }
 
@@ -661,6 +809,7 @@ func defaultTreemapConfig() TreemapConfig {
 		PaddingY:      defaultTreemapPaddingY,
 		LabelFontSize: defaultTreemapLabelFontSize,
 		ValueFontSize: defaultTreemapValueFontSize,
+		ValueFormat:   ",",
 	}
 }
 
@@ -693,6 +842,10 @@ func defaultC4Config() C4Config {
 		BoundaryPadding: defaultC4BoundaryPadding,
 		PaddingX:        defaultC4PaddingX,
 		PaddingY:        defaultC4PaddingY,
+		ShapeInRow:      defaultC4ShapeInRow,
+		BoundaryInRow:   defaultC4BoundaryInRow,
+		ShapeMargin:     defaultC4ShapeMargin,
+		Layout:          C4LayoutRows,
 	}
 }
 
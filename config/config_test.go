@@ -55,6 +55,9 @@ func TestDefaultLayoutPieConfig(t *testing.T) {
 	if cfg.Pie.PaddingX != 20 {
 		t.Errorf("Pie.PaddingX = %f, want 20", cfg.Pie.PaddingX)
 	}
+	if cfg.Pie.LegendPosition != LegendRight {
+		t.Errorf("Pie.LegendPosition = %q, want %q", cfg.Pie.LegendPosition, LegendRight)
+	}
 }
 
 func TestDefaultLayoutQuadrantConfig(t *testing.T) {
@@ -114,6 +117,9 @@ func TestXYChartConfigDefaults(t *testing.T) {
 	if cfg.XYChart.BarWidth != 0.6 {
 		t.Errorf("XYChart.BarWidth = %v, want 0.6", cfg.XYChart.BarWidth)
 	}
+	if cfg.XYChart.LegendPosition != LegendRight {
+		t.Errorf("XYChart.LegendPosition = %q, want %q", cfg.XYChart.LegendPosition, LegendRight)
+	}
 }
 
 func TestDefaultLayoutMindmapConfig(t *testing.T) {
@@ -178,6 +184,15 @@ func TestDefaultLayoutC4Config(t *testing.T) {
 	if cfg.C4.PersonWidth <= 0 {
 		t.Error("PersonWidth should be positive")
 	}
+	if cfg.C4.ShapeInRow != 4 || cfg.C4.BoundaryInRow != 2 {
+		t.Errorf("C4 rows = %d shapes, %d boundaries; want 4, 2", cfg.C4.ShapeInRow, cfg.C4.BoundaryInRow)
+	}
+	if cfg.C4.ShapeMargin != 50 {
+		t.Errorf("C4.ShapeMargin = %v, want 50", cfg.C4.ShapeMargin)
+	}
+	if cfg.C4.Layout != C4LayoutRows {
+		t.Errorf("C4.Layout = %q, want %q", cfg.C4.Layout, C4LayoutRows)
+	}
 }
 
 func TestDefaultLayoutJourneyConfig(t *testing.T) {
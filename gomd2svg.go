@@ -9,8 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jamesainslie/gomd2svg/ir"
 	"github.com/jamesainslie/gomd2svg/layout"
 	"github.com/jamesainslie/gomd2svg/parser"
+	"github.com/jamesainslie/gomd2svg/pdf"
+	"github.com/jamesainslie/gomd2svg/raster"
 	"github.com/jamesainslie/gomd2svg/render"
 )
 
@@ -31,9 +34,97 @@ func RenderWithOptions(input string, opts Options) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("parse: %w", err)
 	}
+	if err = checkGraph(parsed.Graph); err != nil {
+		return "", err
+	}
+
+	th := opts.resolveTheme(parsed.Directive)
+	l := layout.ComputeLayout(parsed.Graph, th, cfg)
+	svg := render.RenderSVG(l, th, cfg)
+	return svg, nil
+}
+
+// RenderPages parses a Mermaid diagram string and returns one SVG per
+// page. Sequence diagrams taller than opts.PageHeight are split between
+// events, repeating the participant headers on every page; every other
+// diagram is a single page.
+func RenderPages(input string, opts Options) ([]string, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("mermaid: empty input")
+	}
+
+	cfg := opts.layoutOrDefault()
+
+	parsed, err := parser.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	if err = checkGraph(parsed.Graph); err != nil {
+		return nil, err
+	}
+
+	th := opts.resolveTheme(parsed.Directive)
+	l := layout.ComputeLayout(parsed.Graph, th, cfg)
+	pages := layout.SplitSequence(l, th, cfg, opts.PageHeight)
+	svgs := make([]string, len(pages))
+	for idx, page := range pages {
+		svgs[idx] = render.RenderSVG(page, th, cfg)
+	}
+	return svgs, nil
+}
+
+// RenderPNG parses a Mermaid diagram string and returns it as a PNG image
+// scaled by opts.Scale. The SVG output is rasterised in pure Go, with text
+// drawn in the fonts the layout was measured with.
+func RenderPNG(input string, opts Options) ([]byte, error) {
+	svg, err := RenderWithOptions(input, opts)
+	if err != nil {
+		return nil, err
+	}
+	return raster.PNG(svg, opts.Scale)
+}
+
+// RenderPDF parses a Mermaid diagram string and returns it as a vector PDF
+// document with embedded fonts. Nodes with click or link URLs become
+// clickable links. The page is sized to the diagram unless opts.Paper
+// selects a paper size.
+func RenderPDF(input string, opts Options) ([]byte, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("mermaid: empty input")
+	}
+
+	cfg := opts.layoutOrDefault()
+
+	parsed, err := parser.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	if err = checkGraph(parsed.Graph); err != nil {
+		return nil, err
+	}
 
 	th := opts.resolveTheme(parsed.Directive)
 	l := layout.ComputeLayout(parsed.Graph, th, cfg)
+	return pdf.Render(l, th, cfg, pdf.Options{
+		Paper:     opts.Paper,
+		Landscape: opts.Landscape,
+		Scale:     opts.Scale,
+	})
+}
+
+// RenderGraph renders an already-built diagram graph, such as one produced
+// by the builder package, skipping the parse stage.
+func RenderGraph(graph *ir.Graph, opts Options) (string, error) {
+	if graph == nil {
+		return "", errors.New("mermaid: nil graph")
+	}
+	if err := checkGraph(graph); err != nil {
+		return "", err
+	}
+
+	cfg := opts.layoutOrDefault()
+	th := opts.resolveTheme(parser.Directive{})
+	l := layout.ComputeLayout(graph, th, cfg)
 	svg := render.RenderSVG(l, th, cfg)
 	return svg, nil
 }
@@ -51,6 +142,9 @@ func RenderWithTiming(input string, opts Options) (*Result, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse: %w", err)
 	}
+	if err = checkGraph(parsed.Graph); err != nil {
+		return nil, err
+	}
 	parseUs := time.Since(t0).Microseconds()
 
 	th := opts.resolveTheme(parsed.Directive)
@@ -70,3 +164,20 @@ func RenderWithTiming(input string, opts Options) (*Result, error) {
 		RenderUs: renderUs,
 	}, nil
 }
+
+// checkGraph reports diagrams that would render wrongly rather than
+// drawing them, such as packet fields that overlap.
+func checkGraph(graph *ir.Graph) error {
+	if graph.Kind != ir.Packet {
+		return nil
+	}
+	problems := parser.CheckPacketFields(graph.Fields)
+	if len(problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(problems))
+	for idx, problem := range problems {
+		errs[idx] = problem
+	}
+	return fmt.Errorf("packet: %w", errors.Join(errs...))
+}
@@ -6,12 +6,19 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
 var updateGolden = flag.Bool("update", false, "update golden files")
 
+// goldenNow is the fixed clock for golden renders, so gantt today markers
+// do not depend on the day the tests run.
+func goldenNow() time.Time {
+	return time.Date(2025, time.March, 12, 9, 0, 0, 0, time.UTC)
+}
+
 func TestGolden(t *testing.T) {
 	t.Parallel()
 	fixtures, err := filepath.Glob("testdata/fixtures/*.mmd")
@@ -36,7 +43,7 @@ func TestGolden(t *testing.T) {
 			t.Run(name, func(t *testing.T) {
 				t.Parallel()
 
-				svg, err := RenderWithOptions(string(input), Options{ThemeName: themeName})
+				svg, err := RenderWithOptions(string(input), Options{ThemeName: themeName, Now: goldenNow})
 				if err != nil {
 					t.Fatalf("RenderWithOptions(%s, %s): %v", base, themeName, err)
 				}
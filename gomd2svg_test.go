@@ -1,9 +1,16 @@
 package gomd2svg
 
 import (
+	"bytes"
+	"errors"
+	"image/png"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/parser"
 )
 
 func TestRender(t *testing.T) {
@@ -30,6 +37,36 @@ func TestRenderWithOptions(t *testing.T) {
 	}
 }
 
+func TestRenderPNG(t *testing.T) {
+	data, err := RenderPNG("flowchart TD; X-->Y", Options{Scale: 2})
+	if err != nil {
+		t.Fatalf("RenderPNG() error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() < 2 || img.Bounds().Dy() < 2 {
+		t.Errorf("image size = %v, want a non-trivial image", img.Bounds())
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	data, err := RenderPDF("flowchart TD\n  X-->Y\n  click X \"https://example.com\"", Options{Paper: "a4"})
+	if err != nil {
+		t.Fatalf("RenderPDF() error: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Errorf("output starts %q, want a PDF header", data[:min(len(data), 8)])
+	}
+	if !bytes.Contains(data, []byte("/URI (https://example.com)")) {
+		t.Error("missing link annotation for clicked node")
+	}
+	if _, err := RenderPDF("flowchart TD; X-->Y", Options{Paper: "napkin"}); err == nil {
+		t.Error("RenderPDF() with unknown paper should fail")
+	}
+}
+
 func TestRenderWithTiming(t *testing.T) {
 	result, err := RenderWithTiming("flowchart LR; A-->B", Options{})
 	if err != nil {
@@ -360,6 +397,43 @@ func TestRenderPacketBitCount(t *testing.T) {
 	}
 }
 
+func TestRenderPacketFieldProblems(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"overlap", "packet\n0-15: \"A\"\n8-23: \"B\"", `field "B" (bits 8-23) overlaps field "A" (bits 0-15)`},
+		{"gap", "packet\n0-7: \"A\"\n16-23: \"B\"", `bits 8-15 before field "B" are not covered by any field`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render(tt.input)
+			var problem *parser.PacketFieldError
+			if !errors.As(err, &problem) {
+				t.Fatalf("Render error = %v, want a PacketFieldError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Render error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPacketFields(t *testing.T) {
+	fields, err := PacketFields(readFixture(t, "packet-bitcount.mmd"))
+	if err != nil {
+		t.Fatalf("PacketFields: %v", err)
+	}
+	want := PacketField{Start: 48, End: 63, Bits: 16, Description: "Checksum"}
+	if len(fields) != 4 || fields[3] != want {
+		t.Errorf("fields = %+v, want the checksum last as %+v", fields, want)
+	}
+	if _, err := PacketFields("flowchart LR\n  A-->B"); err == nil {
+		t.Error("expected an error for a flowchart")
+	}
+}
+
 func TestRenderPieFixture(t *testing.T) {
 	input := readFixture(t, "pie-basic.mmd")
 	svg, err := Render(input)
@@ -947,3 +1021,105 @@ func TestThemeNameOverridesThemeField(t *testing.T) {
 		t.Error("expected dark background color in SVG")
 	}
 }
+
+func TestRenderGraph(t *testing.T) {
+	parsed, err := parser.Parse("flowchart LR; A-->B")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	svg, err := RenderGraph(parsed.Graph, Options{})
+	if err != nil {
+		t.Fatalf("RenderGraph() error: %v", err)
+	}
+	if !strings.Contains(svg, "<svg") {
+		t.Error("missing <svg")
+	}
+	if _, err := RenderGraph(nil, Options{}); err == nil {
+		t.Error("RenderGraph(nil) expected error")
+	}
+}
+
+func TestRenderPages(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("sequenceDiagram\n    participant A\n    participant B\n")
+	for range 40 {
+		src.WriteString("    A->>B: ping\n    B-->>A: pong\n")
+	}
+
+	single, err := RenderPages(src.String(), Options{})
+	if err != nil {
+		t.Fatalf("RenderPages() error: %v", err)
+	}
+	if len(single) != 1 {
+		t.Errorf("pages without PageHeight = %d, want 1", len(single))
+	}
+
+	pages, err := RenderPages(src.String(), Options{PageHeight: 600})
+	if err != nil {
+		t.Fatalf("RenderPages() error: %v", err)
+	}
+	if len(pages) < 2 {
+		t.Fatalf("pages = %d, want several", len(pages))
+	}
+	for idx, svg := range pages {
+		if !strings.HasPrefix(svg, "<svg") || strings.Count(svg, ">A</text>") != 2 {
+			t.Errorf("page %d lacks participant headers and footers", idx+1)
+		}
+	}
+	if !strings.Contains(pages[0], "continued on page 2") || !strings.Contains(pages[1], "continued from page 1") {
+		t.Error("missing continued markers")
+	}
+
+	flow, err := RenderPages("flowchart LR; A-->B", Options{PageHeight: 10})
+	if err != nil || len(flow) != 1 {
+		t.Errorf("flowchart pages = %d, %v; want 1", len(flow), err)
+	}
+}
+
+func TestRenderWithClock(t *testing.T) {
+	input := "gantt\n  Design :2024-01-01, 10d"
+	clock := func() time.Time { return time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC) }
+	cfg := config.DefaultLayout()
+
+	svg, err := RenderWithOptions(input, Options{Layout: cfg, Now: clock})
+	if err != nil {
+		t.Fatalf("RenderWithOptions() error: %v", err)
+	}
+	if !strings.Contains(svg, `class="today"`) {
+		t.Error("missing today marker for a clock inside the chart")
+	}
+	if cfg.Gantt.Now != nil {
+		t.Error("Options.Now changed the caller's layout")
+	}
+
+	svg, err = RenderWithOptions(input, Options{Now: func() time.Time { return clock().AddDate(1, 0, 0) }})
+	if err != nil {
+		t.Fatalf("RenderWithOptions() error: %v", err)
+	}
+	if strings.Contains(svg, `class="today"`) {
+		t.Error("today marker shown for a clock after the chart")
+	}
+}
+
+func TestRenderWithIcons(t *testing.T) {
+	reg := NewIconRegistry()
+	if err := reg.Load([]byte(`{"prefix": "demo", "icons": {"db": {"body": "<rect width=\"16\" height=\"16\"/>"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	input := "architecture-beta\n  service db(demo:db)[Database]\n  service api(server)[API]"
+	cfg := config.DefaultLayout()
+
+	svg, err := RenderWithOptions(input, Options{Layout: cfg, Icons: reg})
+	if err != nil {
+		t.Fatalf("RenderWithOptions() error: %v", err)
+	}
+	if !strings.Contains(svg, `<symbol id="icon-demo--db"`) || !strings.Contains(svg, `href="#icon-demo--db"`) {
+		t.Error("registered icon not inlined")
+	}
+	if strings.Count(svg, "<symbol") != 1 {
+		t.Error("built-in icon should not be inlined as a symbol")
+	}
+	if cfg.Icons != nil {
+		t.Error("Options.Icons changed the caller's layout")
+	}
+}
@@ -0,0 +1,14 @@
+package gomd2svg
+
+import "github.com/jamesainslie/gomd2svg/icons"
+
+// IconRegistry holds Iconify JSON icon sets by prefix. Load sets with its
+// Load, LoadFile and LoadFS methods, then pass it in Options.Icons.
+// Icons it resolves are written once as <symbol> elements in the SVG's
+// <defs> and drawn with <use>.
+type IconRegistry = icons.Registry
+
+// NewIconRegistry returns an empty icon registry.
+func NewIconRegistry() *IconRegistry {
+	return icons.NewRegistry()
+}
@@ -0,0 +1,306 @@
+// Package icons loads icon sets in the Iconify JSON format and resolves
+// icon names such as "logos:aws-lambda" to SVG markup.
+//
+// An Iconify set is a JSON object with a prefix, a map of icons holding
+// SVG bodies, optional aliases and default dimensions:
+//
+//	{"prefix": "mdi", "width": 24, "height": 24,
+//	 "icons": {"home": {"body": "<path d=\"...\"/>"}},
+//	 "aliases": {"house": {"parent": "home"}}}
+package icons
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultSize is the Iconify default width and height of an icon.
+const defaultSize = 16
+
+// maxAliasDepth bounds alias chains so that cycles cannot loop forever.
+const maxAliasDepth = 8
+
+// Icon is a resolved icon: an SVG fragment drawn in its viewBox.
+type Icon struct {
+	// Body is the SVG markup inside the icon's <svg> element. It usually
+	// paints with currentColor.
+	Body string
+	// Left, Top, Width and Height are the icon's viewBox.
+	Left, Top, Width, Height float32
+}
+
+// ViewBox returns the icon's viewBox attribute value.
+func (i Icon) ViewBox() string {
+	return fmt.Sprintf("%s %s %s %s", number(i.Left), number(i.Top), number(i.Width), number(i.Height))
+}
+
+// number formats a coordinate, writing negative zero as 0.
+func number(value float32) string {
+	if value == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(value), 'g', -1, 32)
+}
+
+// Set is a parsed Iconify icon set.
+type Set struct {
+	// Prefix names the set in icon references ("prefix:name").
+	Prefix  string
+	icons   map[string]iconJSON
+	aliases map[string]iconJSON
+	width   float32
+	height  float32
+	left    float32
+	top     float32
+}
+
+// iconJSON is an icon or alias entry of an Iconify set.
+type iconJSON struct {
+	Body   string   `json:"body"`
+	Parent string   `json:"parent"`
+	Left   *float32 `json:"left"`
+	Top    *float32 `json:"top"`
+	Width  *float32 `json:"width"`
+	Height *float32 `json:"height"`
+	Rotate int      `json:"rotate"`
+	HFlip  bool     `json:"hFlip"`
+	VFlip  bool     `json:"vFlip"`
+}
+
+// setJSON is the top level of an Iconify set.
+type setJSON struct {
+	Prefix  string              `json:"prefix"`
+	Icons   map[string]iconJSON `json:"icons"`
+	Aliases map[string]iconJSON `json:"aliases"`
+	Left    float32             `json:"left"`
+	Top     float32             `json:"top"`
+	Width   float32             `json:"width"`
+	Height  float32             `json:"height"`
+}
+
+// ParseSet parses an Iconify JSON icon set.
+func ParseSet(data []byte) (*Set, error) {
+	var raw setJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("icons: %w", err)
+	}
+	if raw.Prefix == "" {
+		return nil, errors.New("icons: icon set has no prefix")
+	}
+	set := &Set{
+		Prefix:  raw.Prefix,
+		icons:   raw.Icons,
+		aliases: raw.Aliases,
+		width:   raw.Width,
+		height:  raw.Height,
+		left:    raw.Left,
+		top:     raw.Top,
+	}
+	if set.width <= 0 {
+		set.width = defaultSize
+	}
+	if set.height <= 0 {
+		set.height = defaultSize
+	}
+	return set, nil
+}
+
+// Len returns the number of icons and aliases in the set.
+func (s *Set) Len() int {
+	return len(s.icons) + len(s.aliases)
+}
+
+// Icon resolves an icon or alias by name, applying the flips and
+// rotations of the alias chain.
+func (s *Set) Icon(name string) (Icon, bool) {
+	// Collect the alias chain from name down to a real icon.
+	var chain []iconJSON
+	for range maxAliasDepth {
+		if entry, ok := s.icons[name]; ok {
+			chain = append(chain, entry)
+			return s.build(chain), true
+		}
+		alias, ok := s.aliases[name]
+		if !ok {
+			return Icon{}, false
+		}
+		chain = append(chain, alias)
+		name = alias.Parent
+	}
+	return Icon{}, false
+}
+
+// build merges an alias chain, ending in the icon itself, into an Icon.
+// Dimensions nearer the start of the chain win; flips and rotations
+// accumulate.
+func (s *Set) build(chain []iconJSON) Icon {
+	icon := Icon{Left: s.left, Top: s.top, Width: s.width, Height: s.height}
+	var hFlip, vFlip bool
+	rotate := 0
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		entry := chain[idx]
+		if entry.Body != "" {
+			icon.Body = entry.Body
+		}
+		if entry.Left != nil {
+			icon.Left = *entry.Left
+		}
+		if entry.Top != nil {
+			icon.Top = *entry.Top
+		}
+		if entry.Width != nil {
+			icon.Width = *entry.Width
+		}
+		if entry.Height != nil {
+			icon.Height = *entry.Height
+		}
+		hFlip = hFlip != entry.HFlip
+		vFlip = vFlip != entry.VFlip
+		rotate += entry.Rotate
+	}
+	return transform(icon, hFlip, vFlip, rotate)
+}
+
+// transform applies Iconify flips and quarter-turn rotations to an icon,
+// wrapping its body in a group and adjusting the viewBox as Iconify does.
+func transform(icon Icon, hFlip, vFlip bool, rotate int) Icon {
+	var transforms []string
+	if hFlip {
+		transforms = append(transforms, "translate("+number(icon.Width+icon.Left)+" "+number(-icon.Top)+") scale(-1 1)")
+		icon.Left, icon.Top = 0, 0
+	}
+	if vFlip {
+		transforms = append(transforms, "translate("+number(-icon.Left)+" "+number(icon.Height+icon.Top)+") scale(1 -1)")
+		icon.Left, icon.Top = 0, 0
+	}
+	const quarterTurns = 4
+	rotate = ((rotate % quarterTurns) + quarterTurns) % quarterTurns
+	switch rotate {
+	case 1:
+		center := icon.Height/2 + icon.Top //nolint:mnd // centre of the box.
+		transforms = append([]string{"rotate(90 " + number(center) + " " + number(center) + ")"}, transforms...)
+	case 2: //nolint:mnd // half a turn.
+		transforms = append([]string{"rotate(180 " + number(icon.Width/2+icon.Left) + " " + number(icon.Height/2+icon.Top) + ")"}, transforms...) //nolint:mnd // centre of the box.
+	case 3: //nolint:mnd // three quarter turns.
+		center := icon.Width/2 + icon.Left //nolint:mnd // centre of the box.
+		transforms = append([]string{"rotate(-90 " + number(center) + " " + number(center) + ")"}, transforms...)
+	}
+	if rotate%2 == 1 {
+		icon.Left, icon.Top = icon.Top, icon.Left
+		icon.Width, icon.Height = icon.Height, icon.Width
+	}
+	if len(transforms) > 0 {
+		icon.Body = `<g transform="` + strings.Join(transforms, " ") + `">` + icon.Body + `</g>`
+	}
+	return icon
+}
+
+// Registry holds icon sets by prefix. It is safe for concurrent use, and
+// a nil *Registry resolves no icons.
+type Registry struct {
+	mu   sync.RWMutex
+	sets map[string]*Set
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{sets: make(map[string]*Set)}
+}
+
+// Add registers a set under its prefix, replacing any set with the same
+// prefix.
+func (r *Registry) Add(set *Set) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sets == nil {
+		r.sets = make(map[string]*Set)
+	}
+	r.sets[set.Prefix] = set
+}
+
+// Load parses an Iconify JSON icon set and registers it.
+func (r *Registry) Load(data []byte) error {
+	set, err := ParseSet(data)
+	if err != nil {
+		return err
+	}
+	r.Add(set)
+	return nil
+}
+
+// LoadFile registers the Iconify JSON icon set in the named file.
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // the caller chooses which icon set to load.
+	if err != nil {
+		return fmt.Errorf("icons: %w", err)
+	}
+	if err := r.Load(data); err != nil {
+		return fmt.Errorf("%w (%s)", err, path)
+	}
+	return nil
+}
+
+// LoadFS registers every Iconify JSON icon set in fsys matching the glob
+// pattern, such as "icons/*.json" in an embed.FS.
+func (r *Registry) LoadFS(fsys fs.FS, pattern string) error {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("icons: %w", err)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("icons: no icon sets match %q", pattern)
+	}
+	for _, path := range paths {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("icons: %w", err)
+		}
+		if err := r.Load(data); err != nil {
+			return fmt.Errorf("%w (%s)", err, path)
+		}
+	}
+	return nil
+}
+
+// Lookup resolves an icon reference. References are "prefix:name", as in
+// architecture services and flowchart icon nodes, or CSS class lists such
+// as "fa fa-book" or "mdi mdi-home", as in mindmap ::icon() decorations.
+func (r *Registry) Lookup(ref string) (Icon, bool) {
+	if r == nil {
+		return Icon{}, false
+	}
+	prefix, name, ok := SplitName(ref)
+	if !ok {
+		return Icon{}, false
+	}
+	r.mu.RLock()
+	set := r.sets[prefix]
+	r.mu.RUnlock()
+	if set == nil {
+		return Icon{}, false
+	}
+	return set.Icon(name)
+}
+
+// SplitName splits an icon reference into its set prefix and icon name.
+// It accepts "prefix:name" and class lists whose last class is
+// "prefix-name" and whose first class is the prefix, like "fa fa-book".
+func SplitName(ref string) (string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	if prefix, name, ok := strings.Cut(ref, ":"); ok {
+		return prefix, name, prefix != "" && name != ""
+	}
+	classes := strings.Fields(ref)
+	if len(classes) < 2 { //nolint:mnd // a prefix class and an icon class.
+		return "", "", false
+	}
+	prefix, last := classes[0], classes[len(classes)-1]
+	name, ok := strings.CutPrefix(last, prefix+"-")
+	return prefix, name, ok && name != ""
+}
@@ -0,0 +1,133 @@
+package icons
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testSet = `{
+	"prefix": "test",
+	"width": 24,
+	"height": 24,
+	"icons": {
+		"home": {"body": "<path d=\"M0 0h24v24H0z\"/>"},
+		"wide": {"body": "<rect width=\"32\" height=\"16\"/>", "width": 32, "height": 16}
+	},
+	"aliases": {
+		"house": {"parent": "home"},
+		"mirror": {"parent": "home", "hFlip": true},
+		"turned": {"parent": "wide", "rotate": 1},
+		"loop": {"parent": "loop"}
+	}
+}`
+
+func TestParseSet(t *testing.T) {
+	set, err := ParseSet([]byte(testSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Prefix != "test" || set.Len() != 6 {
+		t.Errorf("set = %q with %d entries, want test with 6", set.Prefix, set.Len())
+	}
+
+	home, ok := set.Icon("home")
+	if !ok || home.Width != 24 || home.Height != 24 || home.ViewBox() != "0 0 24 24" {
+		t.Errorf("home = %+v, %v; want a 24x24 icon", home, ok)
+	}
+	wide, _ := set.Icon("wide")
+	if wide.ViewBox() != "0 0 32 16" {
+		t.Errorf("wide viewBox = %q, want its own size", wide.ViewBox())
+	}
+	if _, ok := set.Icon("absent"); ok {
+		t.Error("absent icon resolved")
+	}
+	if _, ok := set.Icon("loop"); ok {
+		t.Error("alias cycle resolved")
+	}
+}
+
+func TestParseSetDefaultsAndErrors(t *testing.T) {
+	set, err := ParseSet([]byte(`{"prefix": "small", "icons": {"dot": {"body": "<circle r=\"1\"/>"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dot, _ := set.Icon("dot"); dot.Width != defaultSize || dot.Height != defaultSize {
+		t.Errorf("dot size = %gx%g, want the Iconify default of 16", dot.Width, dot.Height)
+	}
+	if _, err := ParseSet([]byte(`{"icons": {}}`)); err == nil {
+		t.Error("set without a prefix parsed")
+	}
+	if _, err := ParseSet([]byte(`not json`)); err == nil {
+		t.Error("invalid JSON parsed")
+	}
+}
+
+func TestAliasTransforms(t *testing.T) {
+	set, err := ParseSet([]byte(testSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	house, _ := set.Icon("house")
+	home, _ := set.Icon("home")
+	if house != home {
+		t.Errorf("plain alias = %+v, want its parent %+v", house, home)
+	}
+	mirror, _ := set.Icon("mirror")
+	if !strings.HasPrefix(mirror.Body, `<g transform="translate(24 0) scale(-1 1)">`) {
+		t.Errorf("flipped body = %q", mirror.Body)
+	}
+	turned, _ := set.Icon("turned")
+	if turned.Width != 16 || turned.Height != 32 || !strings.Contains(turned.Body, "rotate(90 8 8)") {
+		t.Errorf("rotated icon = %+v, want a 16x32 quarter turn", turned)
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Load([]byte(testSet)); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"test:home", "test test-home", "test:house"} {
+		if _, ok := reg.Lookup(ref); !ok {
+			t.Errorf("Lookup(%q) failed", ref)
+		}
+	}
+	for _, ref := range []string{"home", "other:home", "test:", "test-home", ""} {
+		if _, ok := reg.Lookup(ref); ok {
+			t.Errorf("Lookup(%q) resolved", ref)
+		}
+	}
+
+	var none *Registry
+	if _, ok := none.Lookup("test:home"); ok {
+		t.Error("nil registry resolved an icon")
+	}
+}
+
+func TestRegistryLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"packs/test.json":  {Data: []byte(testSet)},
+		"packs/other.json": {Data: []byte(`{"prefix": "other", "icons": {"x": {"body": "<path/>"}}}`)},
+		"packs/readme.txt": {Data: []byte("not an icon set")},
+	}
+	reg := NewRegistry()
+	if err := reg.LoadFS(fsys, "packs/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reg.Lookup("other:x"); !ok {
+		t.Error("other:x not loaded")
+	}
+	if _, ok := reg.Lookup("test:home"); !ok {
+		t.Error("test:home not loaded")
+	}
+	if err := reg.LoadFS(fsys, "missing/*.json"); err == nil {
+		t.Error("LoadFS with no matches succeeded")
+	}
+	if err := reg.LoadFS(fsys, "packs/*.txt"); err == nil {
+		t.Error("LoadFS of a non-JSON file succeeded")
+	}
+	if err := reg.LoadFile("testdata/absent.json"); err == nil {
+		t.Error("LoadFile of a missing file succeeded")
+	}
+}
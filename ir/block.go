@@ -1,10 +1,68 @@
 package ir
 
+// BlockKind distinguishes the cells of a block diagram.
+type BlockKind int
+
+const (
+	// BlockNode is an ordinary block, or a composite one with Children.
+	BlockNode BlockKind = iota
+	// BlockSpace is an empty filler cell from `space` or `space:N`.
+	BlockSpace
+	// BlockArrow is a block arrow such as `id<["label"]>(right)`.
+	BlockArrow
+)
+
+func (k BlockKind) String() string {
+	switch k {
+	case BlockSpace:
+		return "Space"
+	case BlockArrow:
+		return "Arrow"
+	default:
+		return "Node"
+	}
+}
+
+// BlockArrowDirection is where a block arrow points. X and Y point both
+// ways horizontally and vertically.
+type BlockArrowDirection int
+
+const (
+	BlockArrowRight BlockArrowDirection = iota
+	BlockArrowLeft
+	BlockArrowUp
+	BlockArrowDown
+	BlockArrowX
+	BlockArrowY
+)
+
+func (d BlockArrowDirection) String() string {
+	switch d {
+	case BlockArrowLeft:
+		return "left"
+	case BlockArrowUp:
+		return "up"
+	case BlockArrowDown:
+		return "down"
+	case BlockArrowX:
+		return "x"
+	case BlockArrowY:
+		return "y"
+	default:
+		return "right"
+	}
+}
+
 // BlockDef represents a block in a block diagram.
 type BlockDef struct {
 	ID       string
 	Label    string
 	Shape    NodeShape
-	Width    int         // column span (1 = default)
+	Width    int // column span (1 = default)
+	Kind     BlockKind
+	ArrowDir BlockArrowDirection // for BlockArrow
+	// Columns is a composite block's own column count; 0 places all its
+	// children in one row.
+	Columns  int
 	Children []*BlockDef // nested blocks
 }
@@ -123,6 +123,29 @@ func (e C4ElementType) IsQueue() bool {
 	}
 }
 
+// StyleKey returns the element-type name UpdateElementStyle uses to style
+// every element of a kind: "person", "system", "container" or
+// "component", prefixed with "external_" for external types. Database
+// and queue variants share their base kind's key.
+func (e C4ElementType) StyleKey() string {
+	var base string
+	switch e {
+	case C4Person, C4ExternalPerson:
+		base = "person"
+	case C4ContainerPlain, C4ContainerDb, C4ContainerQueue,
+		C4ExternalContainer, C4ExternalContainerDb, C4ExternalContainerQueue:
+		base = "container"
+	case C4ComponentPlain, C4ExternalComponent:
+		base = "component"
+	default:
+		base = "system"
+	}
+	if e.IsExternal() {
+		return "external_" + base
+	}
+	return base
+}
+
 // C4Element represents a single element in a C4 diagram.
 type C4Element struct {
 	ID          string
@@ -133,19 +156,66 @@ type C4Element struct {
 	BoundaryID  string // empty if top-level
 }
 
-// C4Boundary represents a boundary/grouping in a C4 diagram.
+// C4Boundary represents a boundary/grouping in a C4 diagram. Deployment
+// nodes are boundaries too.
 type C4Boundary struct {
-	ID       string
-	Label    string
-	Type     string   // e.g. "Enterprise", "Software System"
-	Children []string // element IDs within this boundary
+	ID          string
+	Label       string
+	Type        string // e.g. "Enterprise", "Software System"
+	Description string
+	Deployment  bool     // Deployment_Node, Node, Node_L or Node_R
+	ParentID    string   // enclosing boundary; empty if top-level
+	Children    []string // element IDs within this boundary
+}
+
+// C4RelDirection is the placement hint of a directional relationship.
+type C4RelDirection int
+
+const (
+	C4RelNone C4RelDirection = iota
+	C4RelUp
+	C4RelDown
+	C4RelLeft
+	C4RelRight
+)
+
+func (d C4RelDirection) String() string {
+	switch d {
+	case C4RelUp:
+		return "Up"
+	case C4RelDown:
+		return "Down"
+	case C4RelLeft:
+		return "Left"
+	case C4RelRight:
+		return "Right"
+	default:
+		return labelNone
+	}
 }
 
-// C4Rel represents a relationship between C4 elements.
+// C4Rel represents a relationship between C4 elements. Direction asks
+// the layout to place To above, below, left or right of From.
 type C4Rel struct {
-	From        string
-	To          string
-	Label       string
-	Technology  string
-	Description string
+	From          string
+	To            string
+	Label         string
+	Technology    string
+	Description   string
+	Direction     C4RelDirection
+	Bidirectional bool
+	TextColor     string // from UpdateRelStyle
+	LineColor     string // from UpdateRelStyle
+	OffsetX       float32
+	OffsetY       float32
+}
+
+// C4Style holds the overrides of an UpdateElementStyle or
+// UpdateBoundaryStyle statement. Empty fields keep the theme's values.
+type C4Style struct {
+	BgColor     string
+	FontColor   string
+	BorderColor string
+	Shape       string // "RoundedBoxShape" or "EightSidedShape"
+	LegendText  string
 }
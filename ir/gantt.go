@@ -6,7 +6,7 @@ type GanttTask struct {
 	Label    string   // Display name
 	StartStr string   // Start: date string, "after t1", or empty (follows previous)
 	EndStr   string   // End: duration string ("5d", "2w") or date string
-	Tags     []string // Status tags: done, active, crit, milestone
+	Tags     []string // Status tags: done, active, crit, milestone, vert
 	AfterIDs []string // Task IDs this depends on (parsed from "after t1 t2")
 	UntilID  string   // Task ID this runs until
 }
@@ -16,3 +16,10 @@ type GanttSection struct {
 	Title string
 	Tasks []*GanttTask
 }
+
+// GanttCallback is a JavaScript function bound to a task by a
+// "click id call fn(args)" line.
+type GanttCallback struct {
+	Name string
+	Args []string
+}
@@ -14,7 +14,21 @@ type Node struct {
 	Label string
 	Shape NodeShape
 	Value *float32
-	Icon  *string
+	Icon  *NodeIcon
+}
+
+// NodeIcon makes a flowchart node an icon node, declared with
+// id@{ icon: "prefix:name", form: "square", label: "Text", pos: "t", h: 48 }.
+type NodeIcon struct {
+	// Name is the icon reference, resolved through the icon registry.
+	Name string
+	// Form is the background drawn behind the icon: "square", "circle",
+	// "rounded", or empty for none.
+	Form string
+	// LabelTop puts the label above the icon instead of below it.
+	LabelTop bool
+	// Size is the icon's height in pixels; zero means the default.
+	Size float32
 }
 
 type Edge struct {
@@ -77,12 +91,17 @@ type Graph struct {
 	Events       []*SeqEvent
 	Boxes        []*SeqBox
 	Autonumber   bool
+	SeqWrap      bool // wrap message and note text unless it says nowrap:
 
 	// Kanban diagram fields
 	Columns []*KanbanColumn
 
 	// Packet diagram fields
 	Fields []*PacketField
+	// Packet settings from a directive; zero or nil values use the config.
+	PacketBitsPerRow int
+	PacketShowBits   *bool
+	PacketBitRuler   *bool
 
 	// Pie diagram fields
 	PieSlices   []*PieSlice
@@ -97,6 +116,8 @@ type Graph struct {
 	XAxisRight     string
 	YAxisBottom    string
 	YAxisTop       string
+	// QuadrantClasses holds quadrant classDef styles by class name.
+	QuadrantClasses map[string]QuadrantPointStyle
 
 	// Timeline diagram fields
 	TimelineSections []*TimelineSection
@@ -111,17 +132,31 @@ type Graph struct {
 	GanttTickInterval string
 	GanttTodayMarker  string
 	GanttWeekday      string
+	// GanttInclusiveEndDates makes explicit task end dates include the
+	// whole end day.
+	GanttInclusiveEndDates bool
+	// GanttTopAxis repeats the date labels above the chart.
+	GanttTopAxis bool
+	// GanttCallbacks holds click callbacks by task ID. URL clicks are
+	// kept in NodeLinks.
+	GanttCallbacks map[string]*GanttCallback
 
 	// GitGraph diagram fields
-	GitActions    []GitAction
-	GitMainBranch string
+	GitActions         []GitAction
+	GitMainBranch      string
+	GitDirection       Direction // LeftRight, TopDown or BottomTop
+	GitParallelCommits bool      // align commits by distance from the branch point
 
 	// XYChart diagram fields
 	XYSeries     []*XYSeries
 	XYTitle      string
 	XYXAxis      *XYAxis
 	XYYAxis      *XYAxis
+	XYY2Axis     *XYAxis // secondary y-axis, drawn on the right
 	XYHorizontal bool
+	XYStacked    bool   // stack bar series instead of grouping them
+	XYDataLabels bool   // label bars and points with their values
+	XYNumberFmt  string // d3-style number format for ticks and labels
 
 	// Radar diagram fields
 	RadarAxes          []*RadarAxis
@@ -135,13 +170,21 @@ type Graph struct {
 
 	// Sankey diagram fields
 	SankeyLinks []*SankeyLink
+	// SankeyNodeAlignment, SankeyLinkColor and SankeyShowValues come from
+	// a directive; empty or nil values use the config.
+	SankeyNodeAlignment string
+	SankeyLinkColor     string
+	SankeyShowValues    *bool
 
 	// Mindmap diagram fields
-	MindmapRoot *MindmapNode
+	MindmapRoot   *MindmapNode
+	MindmapLayout string // "radial" or "tree" from a directive; empty uses the config
 
 	// Treemap diagram fields
-	TreemapRoot  *TreemapNode
-	TreemapTitle string
+	TreemapRoot        *TreemapNode
+	TreemapTitle       string
+	TreemapValueFormat string // d3-style number format from a directive; empty uses the config
+	TreemapMaxDepth    int    // levels drawn, from a directive; zero uses the config
 
 	// Requirement diagram fields
 	Requirements     []*RequirementDef
@@ -157,6 +200,13 @@ type Graph struct {
 	C4Elements   []*C4Element
 	C4Boundaries []*C4Boundary
 	C4Rels       []*C4Rel
+	// C4ElementStyles maps an element ID, or an element type such as
+	// "person" or "external_system", to its UpdateElementStyle overrides.
+	C4ElementStyles  map[string]*C4Style
+	C4BoundaryStyles map[string]*C4Style // by boundary ID
+	C4ShapeInRow     int                 // from UpdateLayoutConfig; 0 uses the config
+	C4BoundaryInRow  int                 // from UpdateLayoutConfig; 0 uses the config
+	C4Layout         string              // "rows" or "graph" from a directive; empty uses the config
 
 	// Journey diagram fields
 	JourneyTitle    string
@@ -189,6 +239,8 @@ func NewGraph() *Graph {
 		CompositeStates:   make(map[string]*CompositeState),
 		StateDescriptions: make(map[string]string),
 		StateAnnotations:  make(map[string]StateAnnotation),
+		QuadrantClasses:   make(map[string]QuadrantPointStyle),
+		GitDirection:      LeftRight,
 	}
 }
 
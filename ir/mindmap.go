@@ -40,6 +40,6 @@ type MindmapNode struct {
 	Label    string
 	Shape    MindmapShape
 	Icon     string // CSS class from ::icon()
-	Class    string // CSS class from :::
+	Class    string // classes from :::, separated by spaces
 	Children []*MindmapNode
 }
@@ -6,4 +6,33 @@ type QuadrantPoint struct {
 	Label string
 	X     float64
 	Y     float64
+	// Class names a quadrant classDef from a ::: suffix on the label.
+	Class string
+	// Style holds the point's own styles, which win over its class.
+	Style QuadrantPointStyle
+}
+
+// QuadrantPointStyle styles a quadrant point. Nil fields are unset.
+type QuadrantPointStyle struct {
+	Radius      *float32
+	Color       *string // fill colour
+	StrokeColor *string
+	StrokeWidth *float32
+}
+
+// Merge returns the style with the fields set in over replacing its own.
+func (s QuadrantPointStyle) Merge(over QuadrantPointStyle) QuadrantPointStyle {
+	if over.Radius != nil {
+		s.Radius = over.Radius
+	}
+	if over.Color != nil {
+		s.Color = over.Color
+	}
+	if over.StrokeColor != nil {
+		s.StrokeColor = over.StrokeColor
+	}
+	if over.StrokeWidth != nil {
+		s.StrokeWidth = over.StrokeWidth
+	}
+	return s
 }
@@ -97,6 +97,30 @@ type SeqMessage struct {
 	Kind             SeqMessageKind
 	ActivateTarget   bool
 	DeactivateSource bool
+	Wrap             SeqWrap
+}
+
+// SeqWrap selects whether message or note text is wrapped to fit a
+// maximum width.
+type SeqWrap int
+
+const (
+	SeqWrapDefault SeqWrap = iota // follow the diagram-wide setting
+	SeqWrapOn                     // wrap: prefix
+	SeqWrapOff                    // nowrap: prefix
+)
+
+// Wraps reports whether text with this setting wraps, given the
+// diagram-wide default.
+func (w SeqWrap) Wraps(diagramDefault bool) bool {
+	switch w {
+	case SeqWrapOn:
+		return true
+	case SeqWrapOff:
+		return false
+	default:
+		return diagramDefault
+	}
 }
 
 // SeqEventKind distinguishes the types of events in a sequence diagram.
@@ -137,6 +161,7 @@ type SeqNote struct {
 	Position     SeqNotePosition
 	Participants []string
 	Text         string
+	Wrap         SeqWrap
 }
 
 // SeqFrameKind distinguishes the types of combined fragments.
@@ -76,6 +76,24 @@ func TestSeqMessageKindIsDotted(t *testing.T) {
 	}
 }
 
+func TestSeqWrapWraps(t *testing.T) {
+	tests := []struct {
+		wrap           SeqWrap
+		diagramDefault bool
+		want           bool
+	}{
+		{SeqWrapDefault, false, false},
+		{SeqWrapDefault, true, true},
+		{SeqWrapOn, false, true},
+		{SeqWrapOff, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.wrap.Wraps(tt.diagramDefault); got != tt.want {
+			t.Errorf("SeqWrap(%d).Wraps(%v) = %v, want %v", int(tt.wrap), tt.diagramDefault, got, tt.want)
+		}
+	}
+}
+
 func TestSeqFrameKindString(t *testing.T) {
 	tests := []struct {
 		kind SeqFrameKind
@@ -53,8 +53,11 @@ type XYAxis struct {
 	Max        float64  // for numeric axis
 }
 
-// XYSeries holds one data series (bar or line).
+// XYSeries holds one data series (bar or line). Name labels the series
+// in the legend; Secondary plots it against the secondary y-axis.
 type XYSeries struct {
-	Type   XYSeriesType
-	Values []float64
+	Type      XYSeriesType
+	Name      string
+	Secondary bool
+	Values    []float64
 }
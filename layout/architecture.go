@@ -10,33 +10,34 @@ import (
 // archLabelPadding is the horizontal padding added around a service label.
 const archLabelPadding float32 = 20
 
+// archEpsilon absorbs floating-point error when comparing positions.
+const archEpsilon float32 = 0.01
+
 func computeArchitectureLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	measurer := textmetrics.New()
 	acfg := cfg.Architecture
 	nodes := sizeArchNodes(graph, measurer, th, cfg)
 
-	// Build grid positions from edge directional hints.
-	gridPos := archBuildGridPositions(graph)
-
-	// Convert grid to pixel coordinates.
-	maxCol, maxRow := archGridMaxExtents(gridPos)
-
-	for id, pos := range gridPos {
-		node := nodes[id]
-		if node == nil {
-			continue
+	// Place services, junctions and groups from their port constraints.
+	placer := newArchPlacer(graph, acfg, nodes)
+	boxes := placer.place("")
+	groups := make([]ArchGroupLayout, 0, len(graph.ArchGroups))
+	placer.assign(boxes, acfg.PaddingX, acfg.PaddingY, &groups)
+	for _, grp := range graph.ArchGroups {
+		if !archHasGroup(groups, grp.ID) {
+			groups = append(groups, ArchGroupLayout{ID: grp.ID, Label: grp.Label, Icon: grp.Icon})
 		}
-		node.X = acfg.PaddingX + float32(pos[0])*(acfg.ServiceWidth+acfg.ColumnGap) + acfg.ServiceWidth/2
-		node.Y = acfg.PaddingY + float32(pos[1])*(acfg.ServiceHeight+acfg.RowGap) + acfg.ServiceHeight/2
 	}
 
 	// Compute junction layouts.
+	junctionSet := make(map[string]bool, len(graph.ArchJunctions))
 	var junctions []ArchJunctionLayout
 	for _, junc := range graph.ArchJunctions {
 		node := nodes[junc.ID]
 		if node == nil {
 			continue
 		}
+		junctionSet[junc.ID] = true
 		junctions = append(junctions, ArchJunctionLayout{
 			ID:   junc.ID,
 			X:    node.X,
@@ -45,14 +46,8 @@ func computeArchitectureLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Lay
 		})
 	}
 
-	// Compute group bounding rectangles.
-	groups := make([]ArchGroupLayout, 0, len(graph.ArchGroups))
-	for _, grp := range graph.ArchGroups {
-		groupLayout := computeArchGroupBounds(grp, nodes, acfg)
-		groups = append(groups, groupLayout)
-	}
-
-	// Build edges with side-based anchor points.
+	// Route edges orthogonally between their ports. Edges meet at the
+	// centre of a junction.
 	var edges []*EdgeLayout
 	for _, archEdge := range graph.ArchEdges {
 		src := nodes[archEdge.FromID]
@@ -60,19 +55,26 @@ func computeArchitectureLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Lay
 		if src == nil || dst == nil {
 			continue
 		}
-		sx, sy := archAnchorPoint(src, archEdge.FromSide)
-		dx, dy := archAnchorPoint(dst, archEdge.ToSide)
+		start := [2]float32{src.X, src.Y}
+		if !junctionSet[archEdge.FromID] {
+			start[0], start[1] = archAnchorPoint(src, archEdge.FromSide)
+		}
+		end := [2]float32{dst.X, dst.Y}
+		if !junctionSet[archEdge.ToID] {
+			end[0], end[1] = archAnchorPoint(dst, archEdge.ToSide)
+		}
 		edges = append(edges, &EdgeLayout{
 			From:       archEdge.FromID,
 			To:         archEdge.ToID,
-			Points:     [][2]float32{{sx, sy}, {dx, dy}},
+			Points:     archRoute(start, end, archEdge.FromSide, archEdge.ToSide, acfg),
 			ArrowStart: archEdge.ArrowLeft,
 			ArrowEnd:   archEdge.ArrowRight,
 		})
 	}
 
-	totalW := acfg.PaddingX*2 + float32(maxCol+1)*acfg.ServiceWidth + float32(maxCol)*acfg.ColumnGap
-	totalH := acfg.PaddingY*2 + float32(maxRow+1)*acfg.ServiceHeight + float32(maxRow)*acfg.RowGap
+	contentW, contentH := archContentSize(boxes)
+	totalW := acfg.PaddingX*2 + contentW
+	totalH := acfg.PaddingY*2 + contentH
 
 	// Build service info map for rendering (icon data).
 	svcInfo := make(map[string]ArchServiceInfo, len(graph.ArchServices))
@@ -94,119 +96,80 @@ func computeArchitectureLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Lay
 	}
 }
 
-// archBuildGridPositions uses BFS to assign grid positions to all architecture
-// services and junctions based on edge directional hints.
-func archBuildGridPositions(graph *ir.Graph) map[string][2]int {
-	gridPos := make(map[string][2]int)
-	placed := make(map[string]bool)
-
-	// Find the first node to place at origin.
-	var firstID string
-	if len(graph.ArchServices) > 0 {
-		firstID = graph.ArchServices[0].ID
-	} else if len(graph.ArchJunctions) > 0 {
-		firstID = graph.ArchJunctions[0].ID
-	}
-
-	if firstID != "" {
-		gridPos[firstID] = [2]int{0, 0}
-		placed[firstID] = true
-
-		// BFS to place connected nodes based on edge sides.
-		queue := []string{firstID}
-		for len(queue) > 0 {
-			cur := queue[0]
-			queue = queue[1:]
-			curPos := gridPos[cur]
-
-			for _, archEdge := range graph.ArchEdges {
-				neighbor, dc, dr := archEdgeNeighborOffset(archEdge, cur, placed)
-				if neighbor != "" && !placed[neighbor] {
-					gridPos[neighbor] = [2]int{curPos[0] + dc, curPos[1] + dr}
-					placed[neighbor] = true
-					queue = append(queue, neighbor)
-				}
-			}
+// archHasGroup reports whether a group with the given ID has been laid out.
+func archHasGroup(groups []ArchGroupLayout, id string) bool {
+	for _, grp := range groups {
+		if grp.ID == id {
+			return true
 		}
 	}
+	return false
+}
 
-	// Place any unplaced nodes in a row below.
-	nextRow := archGridMaxRow(gridPos) + 1
-	nextCol := 0
-	for _, svc := range graph.ArchServices {
-		if !placed[svc.ID] {
-			gridPos[svc.ID] = [2]int{nextCol, nextRow}
-			placed[svc.ID] = true
-			nextCol++
+// archRoute returns an orthogonal path between two ports. Each end leaves
+// along its side's axis: two horizontal or two vertical ports are joined
+// through a midpoint, or around both ends when they face the same way, and
+// a horizontal and a vertical port meet at one corner.
+func archRoute(start, end [2]float32, fromSide, toSide ir.ArchSide, acfg config.ArchitectureConfig) [][2]float32 {
+	fromCol, fromRow := archSideOffset(fromSide)
+	toCol, toRow := archSideOffset(toSide)
+	var points [][2]float32
+	switch {
+	case fromCol != 0 && toCol != 0:
+		midX := (start[0] + end[0]) / 2
+		if fromCol == toCol {
+			// Both ports face the same way; loop around the outer one.
+			midX = max(start[0], end[0]) + acfg.ColumnGap/2
+			if fromCol < 0 {
+				midX = min(start[0], end[0]) - acfg.ColumnGap/2
+			}
 		}
-	}
-	for _, junc := range graph.ArchJunctions {
-		if !placed[junc.ID] {
-			gridPos[junc.ID] = [2]int{nextCol, nextRow}
-			placed[junc.ID] = true
-			nextCol++
+		points = [][2]float32{start, {midX, start[1]}, {midX, end[1]}, end}
+	case fromRow != 0 && toRow != 0:
+		midY := (start[1] + end[1]) / 2
+		if fromRow == toRow {
+			midY = max(start[1], end[1]) + acfg.RowGap/2
+			if fromRow < 0 {
+				midY = min(start[1], end[1]) - acfg.RowGap/2
+			}
 		}
+		points = [][2]float32{start, {start[0], midY}, {end[0], midY}, end}
+	case fromCol != 0:
+		points = [][2]float32{start, {end[0], start[1]}, end}
+	default:
+		points = [][2]float32{start, {start[0], end[1]}, end}
 	}
-
-	// Normalize grid to non-negative.
-	archNormalizeGrid(gridPos)
-
-	return gridPos
-}
-
-// archEdgeNeighborOffset returns the unplaced neighbor and grid offset for an edge,
-// given the current node ID.
-func archEdgeNeighborOffset(archEdge *ir.ArchEdge, cur string, placed map[string]bool) (string, int, int) {
-	if archEdge.FromID == cur && !placed[archEdge.ToID] {
-		dc, dr := archSideOffset(archEdge.FromSide)
-		return archEdge.ToID, dc, dr
-	}
-	if archEdge.ToID == cur && !placed[archEdge.FromID] {
-		dc, dr := archSideOffsetReverse(archEdge.ToSide)
-		return archEdge.FromID, dc, dr
-	}
-	return "", 0, 0
+	return archSimplifyPath(points)
 }
 
-// archGridMaxRow returns the maximum row value in the grid.
-func archGridMaxRow(gridPos map[string][2]int) int {
-	maxRow := 0
-	for _, pos := range gridPos {
-		if pos[1] > maxRow {
-			maxRow = pos[1]
+// archSimplifyPath drops repeated points and the middle of straight runs.
+func archSimplifyPath(points [][2]float32) [][2]float32 {
+	out := make([][2]float32, 0, len(points))
+	for _, pt := range points {
+		if len(out) > 0 && archSamePoint(out[len(out)-1], pt) {
+			continue
+		}
+		if len(out) >= 2 { //nolint:mnd // a run needs two earlier points.
+			prev, last := out[len(out)-2], out[len(out)-1]
+			sameX := archNear(prev[0], last[0]) && archNear(last[0], pt[0])
+			sameY := archNear(prev[1], last[1]) && archNear(last[1], pt[1])
+			if sameX || sameY {
+				out[len(out)-1] = pt
+				continue
+			}
 		}
+		out = append(out, pt)
 	}
-	return maxRow
+	return out
 }
 
-// archNormalizeGrid shifts all positions so that the minimum col and row are 0.
-func archNormalizeGrid(gridPos map[string][2]int) {
-	minCol, minRow := 0, 0
-	for _, pos := range gridPos {
-		if pos[0] < minCol {
-			minCol = pos[0]
-		}
-		if pos[1] < minRow {
-			minRow = pos[1]
-		}
-	}
-	for id, pos := range gridPos {
-		gridPos[id] = [2]int{pos[0] - minCol, pos[1] - minRow}
-	}
+func archSamePoint(first, second [2]float32) bool {
+	return archNear(first[0], second[0]) && archNear(first[1], second[1])
 }
 
-// archGridMaxExtents returns the maximum column and row values.
-func archGridMaxExtents(gridPos map[string][2]int) (int, int) {
-	maxCol, maxRow := 0, 0
-	for _, pos := range gridPos {
-		if pos[0] > maxCol {
-			maxCol = pos[0]
-		}
-		if pos[1] > maxRow {
-			maxRow = pos[1]
-		}
-	}
-	return maxCol, maxRow
+func archNear(first, second float32) bool {
+	diff := first - second
+	return diff < archEpsilon && diff > -archEpsilon
 }
 
 // archSideOffset returns the grid displacement when moving FROM the given side.
@@ -259,56 +222,21 @@ func archAnchorPoint(node *NodeLayout, side ir.ArchSide) (float32, float32) {
 	}
 }
 
-func computeArchGroupBounds(grp *ir.ArchGroup, nodes map[string]*NodeLayout, acfg config.ArchitectureConfig) ArchGroupLayout {
-	var minX, minY float32 = 1e9, 1e9
-	var maxX, maxY float32 = -1e9, -1e9
-	found := false
-
-	for _, childID := range grp.Children {
-		node := nodes[childID]
-		if node == nil {
-			continue
-		}
-		found = true
-		left := node.X - node.Width/2
-		right := node.X + node.Width/2
-		top := node.Y - node.Height/2
-		bottom := node.Y + node.Height/2
-		if left < minX {
-			minX = left
-		}
-		if right > maxX {
-			maxX = right
-		}
-		if top < minY {
-			minY = top
-		}
-		if bottom > maxY {
-			maxY = bottom
-		}
-	}
-
-	if !found {
-		return ArchGroupLayout{ID: grp.ID, Label: grp.Label, Icon: grp.Icon}
-	}
-
-	pad := acfg.GroupPadding
-	return ArchGroupLayout{
-		ID:     grp.ID,
-		Label:  grp.Label,
-		Icon:   grp.Icon,
-		X:      minX - pad,
-		Y:      minY - pad,
-		Width:  (maxX - minX) + 2*pad,
-		Height: (maxY - minY) + 2*pad,
-	}
-}
-
 func sizeArchNodes(graph *ir.Graph, measurer *textmetrics.Measurer, th *theme.Theme, cfg *config.Layout) map[string]*NodeLayout {
 	nodes := make(map[string]*NodeLayout, len(graph.Nodes))
 	fontSize := th.FontSize
 	fontFamily := th.FontFamily
+	junctions := make(map[string]bool, len(graph.ArchJunctions))
+	for _, junc := range graph.ArchJunctions {
+		junctions[junc.ID] = true
+	}
 	for id, node := range graph.Nodes {
+		if junctions[id] {
+			// Junctions are bare connection points, sized to their dot.
+			size := cfg.Architecture.JunctionSize
+			nodes[id] = &NodeLayout{ID: id, Shape: node.Shape, Width: size, Height: size}
+			continue
+		}
 		width := cfg.Architecture.ServiceWidth
 		height := cfg.Architecture.ServiceHeight
 		labelW := measurer.Width(node.Label, fontSize, fontFamily)
@@ -0,0 +1,470 @@
+package layout
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// archBox is a service, junction or group placed as one rectangle inside
+// its enclosing group, or at the top level.
+type archBox struct {
+	id       string
+	group    *ir.ArchGroup // nil for services and junctions
+	width    float32
+	height   float32
+	x, y     float32 // centre, relative to the container's content origin
+	children []*archBox
+}
+
+// archPlacer places architecture elements group by group. Each group is
+// laid out on its own and then placed as a single box in its parent, so
+// groups stay compact and never interleave with outside elements.
+type archPlacer struct {
+	graph    *ir.Graph
+	acfg     config.ArchitectureConfig
+	nodes    map[string]*NodeLayout
+	groups   map[string]*ir.ArchGroup
+	parent   map[string]string   // element ID -> enclosing group ID, "" at the top level
+	children map[string][]string // group ID ("" for the top level) -> element IDs
+}
+
+func newArchPlacer(graph *ir.Graph, acfg config.ArchitectureConfig, nodes map[string]*NodeLayout) *archPlacer {
+	placer := &archPlacer{
+		graph:    graph,
+		acfg:     acfg,
+		nodes:    nodes,
+		groups:   make(map[string]*ir.ArchGroup, len(graph.ArchGroups)),
+		parent:   make(map[string]string),
+		children: make(map[string][]string),
+	}
+	// Membership comes from each element's own group, or else from the
+	// group's child list.
+	groupOf := make(map[string]string)
+	for _, grp := range graph.ArchGroups {
+		placer.groups[grp.ID] = grp
+		groupOf[grp.ID] = grp.ParentID
+	}
+	for _, svc := range graph.ArchServices {
+		groupOf[svc.ID] = svc.GroupID
+	}
+	for _, junc := range graph.ArchJunctions {
+		groupOf[junc.ID] = junc.GroupID
+	}
+	for _, grp := range graph.ArchGroups {
+		for _, child := range grp.Children {
+			if groupOf[child] == "" && child != grp.ID {
+				groupOf[child] = grp.ID
+			}
+		}
+	}
+
+	add := func(id string) {
+		if _, seen := placer.parent[id]; seen {
+			return
+		}
+		groupID := groupOf[id]
+		if placer.groups[groupID] == nil || placer.nestsIn(groupID, id, groupOf) {
+			groupID = "" // unknown group or a nesting cycle; use the top level
+		}
+		placer.parent[id] = groupID
+		placer.children[groupID] = append(placer.children[groupID], id)
+	}
+	for _, svc := range graph.ArchServices {
+		add(svc.ID)
+	}
+	for _, junc := range graph.ArchJunctions {
+		add(junc.ID)
+	}
+	for _, grp := range graph.ArchGroups {
+		add(grp.ID)
+	}
+	return placer
+}
+
+// nestsIn reports whether group id is, or is nested inside, ancestor.
+func (p *archPlacer) nestsIn(id, ancestor string, groupOf map[string]string) bool {
+	for depth := 0; id != "" && depth <= len(p.groups); depth++ {
+		if id == ancestor {
+			return true
+		}
+		id = groupOf[id]
+	}
+	return false
+}
+
+// member returns the element directly inside container that is id or
+// encloses it.
+func (p *archPlacer) member(id, container string) (string, bool) {
+	for {
+		parentID, ok := p.parent[id]
+		if !ok {
+			return "", false
+		}
+		if parentID == container {
+			return id, true
+		}
+		if parentID == "" {
+			return "", false
+		}
+		id = parentID
+	}
+}
+
+// place lays out the elements of a container and returns their boxes,
+// positioned so that the content's top-left corner is at the origin.
+func (p *archPlacer) place(container string) []*archBox {
+	var boxes []*archBox
+	index := make(map[string]int)
+	for _, id := range p.children[container] {
+		if box := p.box(id); box != nil {
+			index[id] = len(boxes)
+			boxes = append(boxes, box)
+		}
+	}
+	if len(boxes) == 0 {
+		return nil
+	}
+
+	cols := newArchAxis(len(boxes))
+	rows := newArchAxis(len(boxes))
+	for _, edge := range p.graph.ArchEdges {
+		fromID, fromOK := p.member(edge.FromID, container)
+		toID, toOK := p.member(edge.ToID, container)
+		if !fromOK || !toOK || fromID == toID {
+			continue // the edge lies inside one element, or outside this container
+		}
+		from, fromPlaced := index[fromID]
+		to, toPlaced := index[toID]
+		if !fromPlaced || !toPlaced {
+			continue
+		}
+		fromX, fromY := p.offsetIn(boxes[from], edge.FromID)
+		toX, toY := p.offsetIn(boxes[to], edge.ToID)
+		dc, dr := archEdgeDirection(edge)
+		cols.constrain(from, to, dc, (boxes[from].width+boxes[to].width)/2+p.acfg.ColumnGap, fromX-toX)
+		rows.constrain(from, to, dr, (boxes[from].height+boxes[to].height)/2+p.acfg.RowGap, fromY-toY)
+	}
+
+	halfW := make([]float32, len(boxes))
+	halfH := make([]float32, len(boxes))
+	for idx, box := range boxes {
+		halfW[idx] = box.width / 2
+		halfH[idx] = box.height / 2
+	}
+	xs := cols.solve(halfW)
+	ys := rows.solve(halfH)
+	archSeparate(boxes, xs, ys, cols, rows, p.acfg)
+
+	// Move the content's top-left corner to the origin.
+	minX, minY := xs[0]-halfW[0], ys[0]-halfH[0]
+	for idx := range boxes {
+		minX = min(minX, xs[idx]-halfW[idx])
+		minY = min(minY, ys[idx]-halfH[idx])
+	}
+	for idx, box := range boxes {
+		box.x = xs[idx] - minX
+		box.y = ys[idx] - minY
+	}
+	return boxes
+}
+
+// offsetIn returns the centre of element id relative to the centre of box,
+// which is id itself or a group enclosing it.
+func (p *archPlacer) offsetIn(box *archBox, id string) (float32, float32) {
+	if box.id == id {
+		return 0, 0
+	}
+	for _, child := range box.children {
+		if child.id == id || (child.group != nil && p.nestsIn(p.parent[id], child.id, p.parent)) {
+			dx, dy := p.offsetIn(child, id)
+			return p.acfg.GroupPadding + child.x - box.width/2 + dx, p.acfg.GroupPadding + child.y - box.height/2 + dy
+		}
+	}
+	return 0, 0
+}
+
+// box returns the box for an element, or nil for groups with nothing in
+// them and IDs without a node.
+func (p *archPlacer) box(id string) *archBox {
+	grp := p.groups[id]
+	if grp == nil {
+		node := p.nodes[id]
+		if node == nil {
+			return nil
+		}
+		return &archBox{id: id, width: node.Width, height: node.Height}
+	}
+	children := p.place(id)
+	if len(children) == 0 {
+		return nil
+	}
+	contentW, contentH := archContentSize(children)
+	pad := p.acfg.GroupPadding
+	return &archBox{
+		id:       id,
+		group:    grp,
+		width:    contentW + 2*pad,
+		height:   contentH + 2*pad,
+		children: children,
+	}
+}
+
+// assign converts placed boxes to absolute coordinates, setting node
+// centres and appending group rectangles, outer groups first.
+func (p *archPlacer) assign(boxes []*archBox, originX, originY float32, groups *[]ArchGroupLayout) {
+	for _, box := range boxes {
+		cx, cy := originX+box.x, originY+box.y
+		if box.group == nil {
+			p.nodes[box.id].X = cx
+			p.nodes[box.id].Y = cy
+			continue
+		}
+		left, top := cx-box.width/2, cy-box.height/2
+		*groups = append(*groups, ArchGroupLayout{
+			ID:     box.group.ID,
+			Label:  box.group.Label,
+			Icon:   box.group.Icon,
+			X:      left,
+			Y:      top,
+			Width:  box.width,
+			Height: box.height,
+		})
+		p.assign(box.children, left+p.acfg.GroupPadding, top+p.acfg.GroupPadding, groups)
+	}
+}
+
+// archContentSize returns the extent of boxes placed from the origin.
+func archContentSize(boxes []*archBox) (float32, float32) {
+	var width, height float32
+	for _, box := range boxes {
+		width = max(width, box.x+box.width/2)
+		height = max(height, box.y+box.height/2)
+	}
+	return width, height
+}
+
+// archEdgeDirection returns where an edge's ports put its target relative
+// to its source, as column and row signs. An edge leaving the right side
+// and entering a left side puts the target in the next column on the same
+// row; mixing a horizontal and a vertical side puts it diagonally. Ports on
+// the same side of both ends place the target beside the source across
+// that side, so the edge can loop around.
+func archEdgeDirection(edge *ir.ArchEdge) (int, int) {
+	fromCol, fromRow := archSideOffset(edge.FromSide)
+	toCol, toRow := archSideOffsetReverse(edge.ToSide)
+	toCol, toRow = -toCol, -toRow
+	switch {
+	case fromCol != 0 && toCol != 0:
+		if fromCol == toCol {
+			return fromCol, 0
+		}
+		return 0, 1
+	case fromRow != 0 && toRow != 0:
+		if fromRow == toRow {
+			return 0, fromRow
+		}
+		return 1, 0
+	default:
+		return fromCol + toCol, fromRow + toRow
+	}
+}
+
+// archSeparation requires item after's centre to lie at least gap past
+// item before's centre along an axis.
+type archSeparation struct {
+	before, after int
+	gap           float32
+}
+
+// archAxis collects the placement constraints of one axis. Aligned items
+// share a class, each at a fixed offset from the class position, and
+// separations order the classes. Constraints that contradict earlier ones
+// are dropped, so the class graph stays acyclic.
+type archAxis struct {
+	class       []int
+	offset      []float32 // position relative to the class parent
+	separations []archSeparation
+}
+
+func newArchAxis(count int) *archAxis {
+	axis := &archAxis{class: make([]int, count), offset: make([]float32, count)}
+	for idx := range axis.class {
+		axis.class[idx] = idx
+	}
+	return axis
+}
+
+// find returns an item's class and its offset from the class position.
+func (a *archAxis) find(item int) (int, float32) {
+	parent := a.class[item]
+	if parent == item {
+		return item, 0
+	}
+	root, parentOffset := a.find(parent)
+	a.class[item] = root
+	a.offset[item] += parentOffset
+	return root, a.offset[item]
+}
+
+func (a *archAxis) root(item int) int {
+	root, _ := a.find(item)
+	return root
+}
+
+// constrain places to after from (sign > 0), before it (sign < 0) or
+// aligned with it (sign == 0). Aligned items sit delta apart, to minus
+// from, so that nested ports line up rather than box centres.
+func (a *archAxis) constrain(from, to, sign int, gap, delta float32) {
+	switch {
+	case sign > 0:
+		a.separate(from, to, gap)
+	case sign < 0:
+		a.separate(to, from, gap)
+	default:
+		a.align(from, to, delta)
+	}
+}
+
+func (a *archAxis) align(first, second int, delta float32) {
+	firstClass, firstOffset := a.find(first)
+	secondClass, secondOffset := a.find(second)
+	if firstClass == secondClass || a.reaches(firstClass, secondClass) || a.reaches(secondClass, firstClass) {
+		return
+	}
+	a.class[secondClass] = firstClass
+	a.offset[secondClass] = delta + firstOffset - secondOffset
+}
+
+func (a *archAxis) separate(before, after int, gap float32) {
+	if a.root(before) == a.root(after) || a.reaches(a.root(after), a.root(before)) {
+		return
+	}
+	a.separations = append(a.separations, archSeparation{before: before, after: after, gap: gap})
+}
+
+// reaches reports whether class to must come after class from.
+func (a *archAxis) reaches(from, to int) bool {
+	visited := make(map[int]bool)
+	stack := []int{from}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur == to {
+			return true
+		}
+		if visited[cur] {
+			continue
+		}
+		visited[cur] = true
+		for _, sep := range a.separations {
+			if a.root(sep.before) == cur {
+				stack = append(stack, a.root(sep.after))
+			}
+		}
+	}
+	return false
+}
+
+// solve returns the smallest item centres satisfying the constraints, with
+// every item starting at least half its size from zero.
+func (a *archAxis) solve(half []float32) []float32 {
+	classPos := make([]float32, len(a.class))
+	for idx := range a.class {
+		root, offset := a.find(idx)
+		classPos[root] = max(classPos[root], half[idx]-offset)
+	}
+	// The class graph is acyclic, so longest paths settle within one
+	// relaxation pass per class.
+	for range a.class {
+		changed := false
+		for _, sep := range a.separations {
+			before, beforeOffset := a.find(sep.before)
+			after, afterOffset := a.find(sep.after)
+			if need := classPos[before] + beforeOffset + sep.gap - afterOffset; need > classPos[after]+archEpsilon {
+				classPos[after] = need
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	pos := make([]float32, len(a.class))
+	for idx := range pos {
+		root, offset := a.find(idx)
+		pos[idx] = classPos[root] + offset
+	}
+	return pos
+}
+
+// archSeparate pushes apart boxes that overlap, or sit closer than the
+// configured gaps. Each push moves one box, its class and everything that
+// lies or must lie beyond it along one axis, which keeps separations and
+// alignments. Columns are preferred, so unrelated elements line up in a
+// row.
+func archSeparate(boxes []*archBox, xs, ys []float32, cols, rows *archAxis, acfg config.ArchitectureConfig) {
+	maxPushes := len(boxes) * len(boxes)
+	for range maxPushes {
+		first, second, found := archFindOverlap(boxes, xs, ys, acfg)
+		if !found {
+			return
+		}
+		pos, axis, size, gap := xs, cols, func(box *archBox) float32 { return box.width }, acfg.ColumnGap
+		if cols.root(first) == cols.root(second) && rows.root(first) != rows.root(second) {
+			pos, axis, size, gap = ys, rows, func(box *archBox) float32 { return box.height }, acfg.RowGap
+		}
+		mover, other := second, first
+		if pos[first] > pos[second] {
+			mover, other = first, second
+		}
+		shift := (size(boxes[mover])+size(boxes[other]))/2 + gap - (pos[mover] - pos[other])
+		for idx, moved := range archPushSet(axis, pos, mover, other) {
+			if moved {
+				pos[idx] += shift
+			}
+		}
+	}
+}
+
+// archPushSet returns the items that move with mover when it is pushed
+// away from other: those beyond it, its class, and everything separations
+// require to follow them. When both share a class, mover leaves it alone.
+func archPushSet(axis *archAxis, pos []float32, mover, other int) []bool {
+	keepClass := axis.root(mover) != axis.root(other)
+	moved := make([]bool, len(pos))
+	for idx := range pos {
+		moved[idx] = idx == mover || pos[idx] > pos[mover] || (keepClass && axis.root(idx) == axis.root(mover))
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, sep := range axis.separations {
+			if !moved[sep.before] || moved[sep.after] {
+				continue
+			}
+			for idx := range pos {
+				if axis.root(idx) == axis.root(sep.after) {
+					moved[idx] = true
+				}
+			}
+			changed = true
+		}
+	}
+	return moved
+}
+
+// archFindOverlap returns the first pair of boxes closer than the gaps on
+// both axes.
+func archFindOverlap(boxes []*archBox, xs, ys []float32, acfg config.ArchitectureConfig) (int, int, bool) {
+	for second := range boxes {
+		for first := range second {
+			dx := xs[second] - xs[first]
+			dy := ys[second] - ys[first]
+			needX := (boxes[first].width+boxes[second].width)/2 + acfg.ColumnGap
+			needY := (boxes[first].height+boxes[second].height)/2 + acfg.RowGap
+			if dx*dx < (needX-archEpsilon)*(needX-archEpsilon) && dy*dy < (needY-archEpsilon)*(needY-archEpsilon) {
+				return first, second, true
+			}
+		}
+	}
+	return 0, 0, false
+}
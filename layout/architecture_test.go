@@ -1,6 +1,8 @@
 package layout
 
 import (
+	"slices"
+	"sort"
 	"testing"
 
 	"github.com/jamesainslie/gomd2svg/config"
@@ -315,3 +317,180 @@ func TestArchAnchorPoint(t *testing.T) {
 		})
 	}
 }
+
+// archTestGraph builds an architecture graph from services, with optional
+// groups as "id in parent" pairs, and edges.
+func archTestGraph(services map[string]string, groups [][2]string, edges []*ir.ArchEdge) *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Architecture
+	for _, grp := range groups {
+		graph.ArchGroups = append(graph.ArchGroups, &ir.ArchGroup{ID: grp[0], Label: grp[0], ParentID: grp[1]})
+	}
+	ids := make([]string, 0, len(services))
+	for id := range services {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		label := id
+		graph.EnsureNode(id, &label, nil)
+		graph.ArchServices = append(graph.ArchServices, &ir.ArchService{ID: id, Label: id, GroupID: services[id]})
+	}
+	graph.ArchEdges = edges
+	return graph
+}
+
+func archEdge(from string, fromSide ir.ArchSide, toSide ir.ArchSide, to string) *ir.ArchEdge {
+	return &ir.ArchEdge{FromID: from, FromSide: fromSide, ToID: to, ToSide: toSide}
+}
+
+func TestArchitectureLayoutHonoursPortSides(t *testing.T) {
+	// A square: a-b on top, d-c below, reached from both a and c.
+	graph := archTestGraph(map[string]string{"a": "", "b": "", "c": "", "d": ""}, nil, []*ir.ArchEdge{
+		archEdge("b", ir.ArchLeft, ir.ArchRight, "a"),
+		archEdge("b", ir.ArchBottom, ir.ArchTop, "c"),
+		archEdge("a", ir.ArchBottom, ir.ArchTop, "d"),
+		archEdge("c", ir.ArchLeft, ir.ArchRight, "d"),
+	})
+	lay := computeArchitectureLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	nodeA, nodeB, nodeC, nodeD := lay.Nodes["a"], lay.Nodes["b"], lay.Nodes["c"], lay.Nodes["d"]
+	if nodeA.X >= nodeB.X || nodeA.Y != nodeB.Y {
+		t.Errorf("a (%v,%v) should be left of b (%v,%v) on its row", nodeA.X, nodeA.Y, nodeB.X, nodeB.Y)
+	}
+	if nodeD.X != nodeA.X || nodeD.Y <= nodeA.Y {
+		t.Errorf("d (%v,%v) should be below a (%v,%v)", nodeD.X, nodeD.Y, nodeA.X, nodeA.Y)
+	}
+	if nodeC.X != nodeB.X || nodeC.Y != nodeD.Y {
+		t.Errorf("c (%v,%v) should be below b and beside d", nodeC.X, nodeC.Y)
+	}
+
+	// Every edge leaves and enters through its declared sides.
+	for idx, archEdge := range graph.ArchEdges {
+		pts := lay.Edges[idx].Points
+		sx, sy := archAnchorPoint(lay.Nodes[archEdge.FromID], archEdge.FromSide)
+		ex, ey := archAnchorPoint(lay.Nodes[archEdge.ToID], archEdge.ToSide)
+		if pts[0] != [2]float32{sx, sy} || pts[len(pts)-1] != [2]float32{ex, ey} {
+			t.Errorf("edge %d runs %v, want %v to %v", idx, pts, [2]float32{sx, sy}, [2]float32{ex, ey})
+		}
+	}
+}
+
+func TestArchitectureLayoutDiagonalPorts(t *testing.T) {
+	graph := archTestGraph(map[string]string{"a": "", "b": ""}, nil, []*ir.ArchEdge{
+		archEdge("a", ir.ArchRight, ir.ArchTop, "b"),
+	})
+	lay := computeArchitectureLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	nodeA, nodeB := lay.Nodes["a"], lay.Nodes["b"]
+	if nodeB.X <= nodeA.X || nodeB.Y <= nodeA.Y {
+		t.Errorf("b (%v,%v) should be right of and below a (%v,%v)", nodeB.X, nodeB.Y, nodeA.X, nodeA.Y)
+	}
+	want := [][2]float32{
+		{nodeA.X + nodeA.Width/2, nodeA.Y},
+		{nodeB.X, nodeA.Y},
+		{nodeB.X, nodeB.Y - nodeB.Height/2},
+	}
+	if got := lay.Edges[0].Points; len(got) != len(want) || got[1] != want[1] {
+		t.Errorf("route = %v, want one corner %v", got, want)
+	}
+}
+
+func TestArchitectureLayoutNestedGroupsStayCompact(t *testing.T) {
+	graph := archTestGraph(
+		map[string]string{"db": "core", "server": "core", "disk": "api", "gateway": "", "log": ""},
+		[][2]string{{"api", ""}, {"core", "api"}},
+		[]*ir.ArchEdge{
+			archEdge("db", ir.ArchLeft, ir.ArchRight, "server"),
+			archEdge("disk", ir.ArchTop, ir.ArchBottom, "server"),
+			archEdge("gateway", ir.ArchRight, ir.ArchLeft, "server"),
+			archEdge("log", ir.ArchTop, ir.ArchBottom, "gateway"),
+		},
+	)
+	lay := computeArchitectureLayout(graph, theme.Modern(), config.DefaultLayout())
+	data, ok := lay.Diagram.(ArchitectureData)
+	if !ok {
+		t.Fatal("Diagram is not ArchitectureData")
+	}
+
+	bounds := make(map[string]ArchGroupLayout)
+	for _, grp := range data.Groups {
+		bounds[grp.ID] = grp
+	}
+	inside := func(node *NodeLayout, grp ArchGroupLayout) bool {
+		return node.X-node.Width/2 >= grp.X && node.X+node.Width/2 <= grp.X+grp.Width &&
+			node.Y-node.Height/2 >= grp.Y && node.Y+node.Height/2 <= grp.Y+grp.Height
+	}
+	api, core := bounds["api"], bounds["core"]
+	if core.X < api.X || core.Y < api.Y || core.X+core.Width > api.X+api.Width || core.Y+core.Height > api.Y+api.Height {
+		t.Errorf("core %+v is not inside api %+v", core, api)
+	}
+	for id, want := range map[string]bool{"db": true, "server": true, "disk": false, "gateway": false, "log": false} {
+		if got := inside(lay.Nodes[id], core); got != want {
+			t.Errorf("%s inside core = %v, want %v", id, got, want)
+		}
+	}
+	for _, id := range []string{"gateway", "log"} {
+		if inside(lay.Nodes[id], api) {
+			t.Errorf("%s should be outside api", id)
+		}
+	}
+
+	// Edges into a group line up with the service they reach.
+	if lay.Nodes["gateway"].Y != lay.Nodes["server"].Y {
+		t.Errorf("gateway.Y = %v, want server.Y %v", lay.Nodes["gateway"].Y, lay.Nodes["server"].Y)
+	}
+	if lay.Nodes["disk"].X != lay.Nodes["server"].X {
+		t.Errorf("disk.X = %v, want server.X %v", lay.Nodes["disk"].X, lay.Nodes["server"].X)
+	}
+}
+
+func TestArchitectureLayoutRoutesThroughJunctions(t *testing.T) {
+	graph := archTestGraph(map[string]string{"a": "", "b": "", "c": ""}, nil, []*ir.ArchEdge{
+		archEdge("a", ir.ArchRight, ir.ArchLeft, "j"),
+		archEdge("j", ir.ArchRight, ir.ArchLeft, "b"),
+		archEdge("j", ir.ArchBottom, ir.ArchTop, "c"),
+	})
+	graph.EnsureNode("j", nil, nil)
+	graph.ArchJunctions = []*ir.ArchJunction{{ID: "j"}}
+	lay := computeArchitectureLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	junc := lay.Nodes["j"]
+	for idx, edge := range lay.Edges {
+		for seg := 1; seg < len(edge.Points); seg++ {
+			prev, cur := edge.Points[seg-1], edge.Points[seg]
+			if prev[0] != cur[0] && prev[1] != cur[1] {
+				t.Errorf("edge %d segment %v-%v is not orthogonal", idx, prev, cur)
+			}
+		}
+		ends := [][2]float32{edge.Points[0], edge.Points[len(edge.Points)-1]}
+		if !slices.Contains(ends, [2]float32{junc.X, junc.Y}) {
+			t.Errorf("edge %d %v does not meet the junction centre (%v,%v)", idx, edge.Points, junc.X, junc.Y)
+		}
+	}
+	if lay.Nodes["c"].X != junc.X || lay.Nodes["c"].Y <= junc.Y {
+		t.Errorf("c should hang below the junction")
+	}
+}
+
+func TestArchEdgeDirection(t *testing.T) {
+	tests := []struct {
+		from, to       ir.ArchSide
+		wantDC, wantDR int
+	}{
+		{ir.ArchRight, ir.ArchLeft, 1, 0},
+		{ir.ArchLeft, ir.ArchRight, -1, 0},
+		{ir.ArchBottom, ir.ArchTop, 0, 1},
+		{ir.ArchTop, ir.ArchBottom, 0, -1},
+		{ir.ArchRight, ir.ArchTop, 1, 1},
+		{ir.ArchTop, ir.ArchLeft, 1, -1},
+		{ir.ArchRight, ir.ArchRight, 0, 1},
+		{ir.ArchBottom, ir.ArchBottom, 1, 0},
+	}
+	for _, tt := range tests {
+		dc, dr := archEdgeDirection(&ir.ArchEdge{FromSide: tt.from, ToSide: tt.to})
+		if dc != tt.wantDC || dr != tt.wantDR {
+			t.Errorf("archEdgeDirection(%v, %v) = (%d, %d), want (%d, %d)", tt.from, tt.to, dc, dr, tt.wantDC, tt.wantDR)
+		}
+	}
+}
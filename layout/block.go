@@ -7,18 +7,31 @@ import (
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
+// blockLabelRatio is the composite block label size relative to the font size.
+const blockLabelRatio = 0.9
+
 func computeBlockLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	measurer := textmetrics.New()
 	nodes := sizeBlockNodes(graph, measurer, th, cfg)
 
 	blockInfos := make(map[string]BlockInfo)
-	for _, blk := range graph.Blocks {
-		blockInfos[blk.ID] = BlockInfo{Span: blk.Width, HasChildren: len(blk.Children) > 0}
-	}
+	composite := false
+	walkBlocks(graph.Blocks, func(blk *ir.BlockDef) {
+		if blk.Kind == ir.BlockSpace {
+			return
+		}
+		blockInfos[blk.ID] = BlockInfo{
+			Span:        blk.Width,
+			HasChildren: len(blk.Children) > 0,
+			Kind:        blk.Kind,
+			ArrowDir:    blk.ArrowDir,
+		}
+		composite = composite || len(blk.Children) > 0
+	})
 
 	// Decide layout strategy
-	if graph.BlockColumns > 0 {
-		return blockGridLayout(graph, nodes, blockInfos, cfg)
+	if graph.BlockColumns > 0 || composite {
+		return blockGridLayout(graph, nodes, blockInfos, measurer, th, cfg)
 	}
 	if len(graph.Edges) > 0 {
 		result := runSugiyama(graph, nodes, cfg)
@@ -31,57 +44,206 @@ func computeBlockLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 			Diagram: BlockData{Columns: 0, BlockInfos: blockInfos},
 		}
 	}
-	return blockGridLayout(graph, nodes, blockInfos, cfg)
+	return blockGridLayout(graph, nodes, blockInfos, measurer, th, cfg)
 }
 
-func blockGridLayout(graph *ir.Graph, nodes map[string]*NodeLayout, blockInfos map[string]BlockInfo, cfg *config.Layout) *Layout {
-	cols := graph.BlockColumns
-	if cols <= 0 {
-		cols = 1
+// walkBlocks calls visit for every block, parents before their children.
+func walkBlocks(blocks []*ir.BlockDef, visit func(*ir.BlockDef)) {
+	for _, blk := range blocks {
+		visit(blk)
+		walkBlocks(blk.Children, visit)
 	}
-	colGap := cfg.Block.ColumnGap
-	rowGap := cfg.Block.RowGap
-	padX := cfg.Block.PaddingX
-	padY := cfg.Block.PaddingY
+}
 
-	var maxCellW, maxCellH float32
-	for _, n := range nodes {
-		if n.Width > maxCellW {
-			maxCellW = n.Width
-		}
-		if n.Height > maxCellH {
-			maxCellH = n.Height
-		}
-	}
+// blockCell is a block placed in a grid.
+type blockCell struct {
+	block    *ir.BlockDef
+	row, col int
+	span     int
+	inner    *blockGrid // for composite blocks
+}
 
-	col := 0
-	row := 0
-	for _, blk := range graph.Blocks {
-		node, ok := nodes[blk.ID]
-		if !ok {
-			continue
-		}
+// blockGrid is the measured grid of the diagram or of a composite block.
+type blockGrid struct {
+	cells  []*blockCell
+	colW   []float32
+	rowH   []float32
+	width  float32
+	height float32
+}
 
-		span := blk.Width
-		if span <= 0 {
-			span = 1
-		}
+// blockGridder measures and places nested block grids.
+type blockGridder struct {
+	nodes  map[string]*NodeLayout
+	cfg    *config.Layout
+	cellW  float32 // width of a single-column block
+	cellH  float32 // least height of a row
+	labelH float32 // height of a composite block's label
+}
+
+// measure fills a grid of cols columns with blocks in order, wrapping to a
+// new row when a block's span does not fit. Columns are cellW wide unless
+// a composite block needs them wider; spaces leave their cells empty.
+func (g *blockGridder) measure(blocks []*ir.BlockDef, cols int) *blockGrid {
+	grid := &blockGrid{}
+	col, row := 0, 0
+	for _, blk := range blocks {
+		span := min(max(blk.Width, 1), cols)
 		if col+span > cols {
 			col = 0
 			row++
 		}
-
-		cellW := maxCellW*float32(span) + colGap*float32(span-1)
-		node.Width = cellW
-		node.X = padX + float32(col)*(maxCellW+colGap) + cellW/2
-		node.Y = padY + float32(row)*(maxCellH+rowGap) + maxCellH/2
-
+		cell := &blockCell{block: blk, row: row, col: col, span: span}
+		if len(blk.Children) > 0 {
+			cell.inner = g.measure(blk.Children, blockInnerColumns(blk))
+		}
+		grid.cells = append(grid.cells, cell)
 		col += span
 		if col >= cols {
 			col = 0
 			row++
 		}
 	}
+	rows := row
+	if col > 0 || rows == 0 {
+		rows++
+	}
+	if len(blocks) == 0 {
+		rows = 0
+	}
+
+	grid.colW = make([]float32, cols)
+	for idx := range grid.colW {
+		grid.colW[idx] = g.cellW
+	}
+	grid.rowH = make([]float32, rows)
+	for idx := range grid.rowH {
+		grid.rowH[idx] = g.cellH
+	}
+	for _, cell := range grid.cells {
+		width, height := g.cellSize(cell)
+		grid.rowH[cell.row] = max(grid.rowH[cell.row], height)
+		// Widen the spanned columns evenly when the block needs more room.
+		if extra := width - g.spanWidth(grid, cell); extra > 0 {
+			for idx := cell.col; idx < cell.col+cell.span; idx++ {
+				grid.colW[idx] += extra / float32(cell.span)
+			}
+		}
+	}
+	grid.width = blockSpan(grid.colW, g.cfg.Block.ColumnGap)
+	grid.height = blockSpan(grid.rowH, g.cfg.Block.RowGap)
+	return grid
+}
+
+// cellSize returns the room a cell's block needs.
+func (g *blockGridder) cellSize(cell *blockCell) (float32, float32) {
+	if cell.inner == nil {
+		return 0, g.cellH
+	}
+	pad := g.cfg.Block.NodePadding
+	return cell.inner.width + 2*pad, cell.inner.height + 2*pad + g.compositeLabelH(cell.block)
+}
+
+// compositeLabelH returns the space above a composite block's children
+// taken by its label.
+func (g *blockGridder) compositeLabelH(blk *ir.BlockDef) float32 {
+	if blk.Label == "" {
+		return 0
+	}
+	return g.labelH
+}
+
+// spanWidth returns the width of the columns a cell spans.
+func (g *blockGridder) spanWidth(grid *blockGrid, cell *blockCell) float32 {
+	return blockSpan(grid.colW[cell.col:cell.col+cell.span], g.cfg.Block.ColumnGap)
+}
+
+// place positions a measured grid with its top-left corner at (originX,
+// originY). Blocks fill the width of their columns and composite blocks
+// the height of their row, with their children centred inside.
+func (g *blockGridder) place(grid *blockGrid, originX, originY float32) {
+	colX := make([]float32, len(grid.colW))
+	pos := originX
+	for idx, width := range grid.colW {
+		colX[idx] = pos
+		pos += width + g.cfg.Block.ColumnGap
+	}
+	rowY := make([]float32, len(grid.rowH))
+	pos = originY
+	for idx, height := range grid.rowH {
+		rowY[idx] = pos
+		pos += height + g.cfg.Block.RowGap
+	}
+
+	for _, cell := range grid.cells {
+		node, ok := g.nodes[cell.block.ID]
+		if cell.block.Kind == ir.BlockSpace || !ok {
+			continue
+		}
+		width := g.spanWidth(grid, cell)
+		node.Width = width
+		node.X = colX[cell.col] + width/2
+		node.Y = rowY[cell.row] + grid.rowH[cell.row]/2
+		if cell.inner == nil {
+			continue
+		}
+		node.Height = grid.rowH[cell.row]
+		pad := g.cfg.Block.NodePadding
+		labelH := g.compositeLabelH(cell.block)
+		innerX := colX[cell.col] + (width-cell.inner.width)/2
+		innerY := rowY[cell.row] + pad + labelH + (node.Height-2*pad-labelH-cell.inner.height)/2
+		g.place(cell.inner, innerX, innerY)
+	}
+}
+
+// blockInnerColumns returns a composite block's column count: its own
+// columns setting, or enough for all its children in one row.
+func blockInnerColumns(blk *ir.BlockDef) int {
+	if blk.Columns > 0 {
+		return blk.Columns
+	}
+	total := 0
+	for _, child := range blk.Children {
+		total += max(child.Width, 1)
+	}
+	return max(total, 1)
+}
+
+// blockSpan returns the total length of sizes separated by gap.
+func blockSpan(sizes []float32, gap float32) float32 {
+	if len(sizes) == 0 {
+		return 0
+	}
+	var total float32
+	for _, size := range sizes {
+		total += size
+	}
+	return total + gap*float32(len(sizes)-1)
+}
+
+func blockGridLayout(graph *ir.Graph, nodes map[string]*NodeLayout, blockInfos map[string]BlockInfo, measurer *textmetrics.Measurer, th *theme.Theme, cfg *config.Layout) *Layout {
+	cols := graph.BlockColumns
+	if cols <= 0 {
+		cols = 1
+	}
+	padX := cfg.Block.PaddingX
+	padY := cfg.Block.PaddingY
+
+	gridder := &blockGridder{
+		nodes:  nodes,
+		cfg:    cfg,
+		labelH: th.FontSize * blockLabelRatio * cfg.LabelLineHeight,
+	}
+	for id, n := range nodes {
+		if blockInfos[id].HasChildren {
+			continue
+		}
+		gridder.cellW = max(gridder.cellW, n.Width)
+		gridder.cellH = max(gridder.cellH, n.Height)
+	}
+
+	grid := gridder.measure(graph.Blocks, cols)
+	gridder.place(grid, padX, padY)
 
 	var edges []*EdgeLayout
 	for _, edge := range graph.Edges {
@@ -90,27 +252,33 @@ func blockGridLayout(graph *ir.Graph, nodes map[string]*NodeLayout, blockInfos m
 		if src == nil || dst == nil {
 			continue
 		}
-		edges = append(edges, &EdgeLayout{
-			From:     edge.From,
-			To:       edge.To,
-			Points:   [][2]float32{{src.X, src.Y}, {dst.X, dst.Y}},
-			ArrowEnd: edge.ArrowEnd,
-		})
-	}
-
-	totalW := padX*2 + float32(cols)*maxCellW + float32(cols-1)*colGap
-	totalRows := row + 1
-	if col == 0 && row > 0 {
-		totalRows = row
+		start := nodeBorderPoint(src, dst.X, dst.Y)
+		end := nodeBorderPoint(dst, src.X, src.Y)
+		edgeLayout := &EdgeLayout{
+			From:        edge.From,
+			To:          edge.To,
+			Points:      [][2]float32{start, end},
+			LabelAnchor: [2]float32{(start[0] + end[0]) / 2, (start[1] + end[1]) / 2}, //nolint:mnd // midpoint.
+			ArrowEnd:    edge.ArrowEnd,
+		}
+		if edge.Label != nil && *edge.Label != "" {
+			fontSize := th.FontSize * blockLabelRatio
+			edgeLayout.Label = &TextBlock{
+				Lines:    []string{*edge.Label},
+				Width:    measurer.Width(*edge.Label, fontSize, th.FontFamily),
+				Height:   fontSize * cfg.LabelLineHeight,
+				FontSize: fontSize,
+			}
+		}
+		edges = append(edges, edgeLayout)
 	}
-	totalH := padY*2 + float32(totalRows)*maxCellH + float32(totalRows-1)*rowGap
 
 	return &Layout{
 		Kind:    graph.Kind,
 		Nodes:   nodes,
 		Edges:   edges,
-		Width:   totalW,
-		Height:  totalH,
+		Width:   padX*2 + grid.width,
+		Height:  padY*2 + grid.height,
 		Diagram: BlockData{Columns: cols, BlockInfos: blockInfos},
 	}
 }
@@ -119,6 +287,7 @@ func sizeBlockNodes(graph *ir.Graph, measurer *textmetrics.Measurer, th *theme.T
 	nodes := make(map[string]*NodeLayout, len(graph.Nodes))
 	for id, node := range graph.Nodes {
 		nl := sizeNode(node, measurer, th, cfg)
+		nl.Style = resolveNodeStyle(graph, id)
 		nodes[id] = nl
 	}
 	return nodes
@@ -104,3 +104,87 @@ func TestBlockLayoutEmpty(t *testing.T) {
 		t.Errorf("Nodes = %d", len(lay.Nodes))
 	}
 }
+
+func TestBlockSpaceLeavesCellEmpty(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Block
+	graph.BlockColumns = 3
+	for _, id := range []string{"a", "b"} {
+		label := id
+		graph.EnsureNode(id, &label, nil)
+	}
+	graph.Blocks = append(graph.Blocks,
+		&ir.BlockDef{ID: "a", Label: "a", Width: 1},
+		&ir.BlockDef{Kind: ir.BlockSpace, Width: 1},
+		&ir.BlockDef{ID: "b", Label: "b", Width: 1},
+	)
+
+	lay := computeBlockLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	a, b := lay.Nodes["a"], lay.Nodes["b"]
+	if a.Y != b.Y {
+		t.Errorf("a.Y = %v, b.Y = %v, want the same row", a.Y, b.Y)
+	}
+	if gap := b.X - a.X; gap < 2*a.Width {
+		t.Errorf("b is %v right of a, want at least two columns (%v)", gap, 2*a.Width)
+	}
+}
+
+func TestBlockCompositeContainsChildren(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Block
+	for _, id := range []string{"group", "a", "b", "c"} {
+		label := id
+		graph.EnsureNode(id, &label, nil)
+	}
+	group := &ir.BlockDef{ID: "group", Label: "group", Width: 1, Columns: 2, Children: []*ir.BlockDef{
+		{ID: "a", Label: "a", Width: 1},
+		{ID: "b", Label: "b", Width: 1},
+		{ID: "c", Label: "c", Width: 2},
+	}}
+	graph.Blocks = append(graph.Blocks, group)
+
+	lay := computeBlockLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	parent := lay.Nodes["group"]
+	for _, id := range []string{"a", "b", "c"} {
+		child := lay.Nodes[id]
+		if child.X-child.Width/2 < parent.X-parent.Width/2 || child.X+child.Width/2 > parent.X+parent.Width/2 ||
+			child.Y-child.Height/2 < parent.Y-parent.Height/2 || child.Y+child.Height/2 > parent.Y+parent.Height/2 {
+			t.Errorf("%s (%v,%v %vx%v) is outside group (%v,%v %vx%v)", id,
+				child.X, child.Y, child.Width, child.Height, parent.X, parent.Y, parent.Width, parent.Height)
+		}
+	}
+	if lay.Nodes["a"].Y != lay.Nodes["b"].Y || lay.Nodes["c"].Y <= lay.Nodes["a"].Y {
+		t.Error("want a and b in the first inner row and c below them")
+	}
+	if lay.Nodes["c"].Width <= lay.Nodes["a"].Width {
+		t.Errorf("c width = %v, want wider than a (%v)", lay.Nodes["c"].Width, lay.Nodes["a"].Width)
+	}
+	bd, ok := lay.Diagram.(BlockData)
+	if !ok || !bd.BlockInfos["group"].HasChildren {
+		t.Error("group should be marked as having children")
+	}
+}
+
+func TestBlockLayoutResolvesStyles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Block
+	label := "a"
+	graph.EnsureNode("a", &label, nil)
+	graph.Blocks = append(graph.Blocks, &ir.BlockDef{ID: "a", Label: "a", Width: 1})
+	classFill, classStroke, styleFill := "#f96", "#333", "#9cf"
+	graph.ClassDefs["hot"] = &ir.NodeStyle{Fill: &classFill, Stroke: &classStroke}
+	graph.NodeClasses["a"] = []string{"hot"}
+	graph.NodeStyles["a"] = &ir.NodeStyle{Fill: &styleFill}
+
+	lay := computeBlockLayout(graph, theme.Modern(), config.DefaultLayout())
+
+	style := lay.Nodes["a"].Style
+	if style.Fill == nil || *style.Fill != styleFill {
+		t.Errorf("fill = %v, want the style statement's %s", style.Fill, styleFill)
+	}
+	if style.Stroke == nil || *style.Stroke != classStroke {
+		t.Errorf("stroke = %v, want the class's %s", style.Stroke, classStroke)
+	}
+}
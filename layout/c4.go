@@ -1,48 +1,585 @@
 package layout
 
 import (
+	"sort"
+
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
 	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
-// c4SmallFontRatio is the ratio of small text size to the base font size.
-const c4SmallFontRatio = 0.85
+// C4 layout constants.
+const (
+	// c4SmallFontRatio is the ratio of small text size to the base font size.
+	c4SmallFontRatio = 0.85
+	// c4BoundaryLabelHeight is the space above a boundary's content taken
+	// by its label.
+	c4BoundaryLabelHeight = 20
+	// c4BoundaryLineHeight is the space taken by each of a boundary's type
+	// and description lines.
+	c4BoundaryLineHeight = 14
+	// c4BoundaryLabelOffset is the label's inset from the boundary's left edge.
+	c4BoundaryLabelOffset = 8
+	// c4LegendSwatch is the size of a legend colour square.
+	c4LegendSwatch = 16
+	// c4LegendSpacing separates legend rows.
+	c4LegendSpacing = 4
+	// c4LegendTextGap separates a legend swatch from its text.
+	c4LegendTextGap = 6
+	// c4EdgeLabelLineHeight is the relationship label line height, as a
+	// multiple of its font size.
+	c4EdgeLabelLineHeight = 1.2
+	// c4EdgeLabelClearance is the least space between a relationship label
+	// and the elements either side of it.
+	c4EdgeLabelClearance = 12
+)
 
+// c4Container is an area of a C4 diagram holding a grid of elements above
+// rows of nested boundaries: either the whole diagram or one boundary.
+// Positions are relative to the container's content origin until placed.
+type c4Container struct {
+	boundary   *ir.C4Boundary // nil for the diagram itself
+	elements   []string
+	children   []*c4Container
+	elemCenter map[string][2]float32
+	childPos   [][2]float32
+	contentX   float32 // content origin relative to the top-left corner
+	contentY   float32
+	width      float32
+	height     float32
+}
+
+// computeC4Layout lays out a C4 diagram by row packing or, when the
+// config or an init directive asks for it, as a layered graph.
 func computeC4Layout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	measurer := textmetrics.New()
 	nodes := sizeC4Nodes(graph, measurer, th, cfg)
 
-	result := runSugiyama(graph, nodes, cfg)
+	mode := cfg.C4.Layout
+	if graph.C4Layout != "" {
+		mode = config.C4LayoutMode(graph.C4Layout)
+	}
+	var edges []*EdgeLayout
+	var boundaries []*C4BoundaryLayout
+	var width, height float32
+	if mode == config.C4LayoutGraph {
+		edges, boundaries, width, height = layoutC4Graph(graph, nodes, measurer, th, cfg)
+	} else {
+		boundaries, width, height = packC4Rows(graph, nodes, measurer, th, cfg)
+		edges = c4Edges(graph, nodes)
+		labelC4Edges(graph, nodes, edges, measurer, th)
+	}
 
 	elemMap := make(map[string]*ir.C4Element)
 	for _, elem := range graph.C4Elements {
 		elemMap[elem.ID] = elem
 	}
 
-	var boundaryLayouts []*C4BoundaryLayout
-	for _, boundary := range graph.C4Boundaries {
-		bl := computeC4BoundaryRect(boundary, nodes, cfg)
-		if bl != nil {
-			boundaryLayouts = append(boundaryLayouts, bl)
+	legend, legendW, legendH := c4Legend(graph, measurer, th)
+	if len(legend) > 0 {
+		originY := height
+		for idx := range legend {
+			legend[idx].X += cfg.C4.PaddingX
+			legend[idx].TextX += cfg.C4.PaddingX
+			legend[idx].Y += originY
 		}
+		width = max(width, legendW+2*cfg.C4.PaddingX)
+		height += legendH + cfg.C4.PaddingY
 	}
 
 	return &Layout{
 		Kind:   graph.Kind,
 		Nodes:  nodes,
-		Edges:  result.Edges,
-		Width:  result.Width,
-		Height: result.Height,
+		Edges:  edges,
+		Width:  width,
+		Height: height,
 		Diagram: C4Data{
-			Elements:   elemMap,
-			Boundaries: boundaryLayouts,
-			SubKind:    graph.C4SubKind,
+			Elements:       elemMap,
+			Boundaries:     boundaries,
+			SubKind:        graph.C4SubKind,
+			Rels:           c4EdgeRels(graph, nodes),
+			ElementStyles:  graph.C4ElementStyles,
+			BoundaryStyles: graph.C4BoundaryStyles,
+			Legend:         legend,
+			LegendSwatch:   c4LegendSwatch,
 		},
 	}
 }
 
+// packC4Rows packs elements into rows of ShapeInRow and boundaries into
+// rows of BoundaryInRow, the way Mermaid lays out C4 diagrams. A
+// directional relationship such as Rel_R places its target in the grid
+// cell beside its source when that cell is free. It returns the boundary
+// rectangles and the diagram's size.
+func packC4Rows(graph *ir.Graph, nodes map[string]*NodeLayout, measurer *textmetrics.Measurer, th *theme.Theme, cfg *config.Layout) ([]*C4BoundaryLayout, float32, float32) {
+	shapeInRow := cfg.C4.ShapeInRow
+	if graph.C4ShapeInRow > 0 {
+		shapeInRow = graph.C4ShapeInRow
+	}
+	boundaryInRow := cfg.C4.BoundaryInRow
+	if graph.C4BoundaryInRow > 0 {
+		boundaryInRow = graph.C4BoundaryInRow
+	}
+	packer := &c4Packer{
+		nodes:         nodes,
+		rels:          graph.C4Rels,
+		measurer:      measurer,
+		th:            th,
+		cfg:           cfg,
+		shapeInRow:    max(1, shapeInRow),
+		boundaryInRow: max(1, boundaryInRow),
+	}
+
+	root := buildC4Containers(graph, nodes)
+	packer.measure(root)
+	packer.place(root, cfg.C4.PaddingX, cfg.C4.PaddingY)
+	return packer.boundaries, root.width + 2*cfg.C4.PaddingX, root.height + 2*cfg.C4.PaddingY
+}
+
+// buildC4Containers returns the diagram's container tree. Elements outside
+// every boundary, and boundaries without a known parent, are top-level.
+func buildC4Containers(graph *ir.Graph, nodes map[string]*NodeLayout) *c4Container {
+	root := &c4Container{}
+	byID := make(map[string]*c4Container, len(graph.C4Boundaries))
+	inBoundary := make(map[string]bool)
+	for _, boundary := range graph.C4Boundaries {
+		container := &c4Container{boundary: boundary}
+		for _, childID := range boundary.Children {
+			if _, ok := nodes[childID]; ok && !inBoundary[childID] {
+				container.elements = append(container.elements, childID)
+				inBoundary[childID] = true
+			}
+		}
+		byID[boundary.ID] = container
+	}
+	for _, boundary := range graph.C4Boundaries {
+		parent, ok := byID[boundary.ParentID]
+		if !ok || boundary.ParentID == boundary.ID {
+			parent = root
+		}
+		parent.children = append(parent.children, byID[boundary.ID])
+	}
+
+	for id := range nodes {
+		if !inBoundary[id] {
+			root.elements = append(root.elements, id)
+		}
+	}
+	sort.Slice(root.elements, func(left, right int) bool {
+		return graph.NodeOrder[root.elements[left]] < graph.NodeOrder[root.elements[right]]
+	})
+	return root
+}
+
+// c4Packer measures and places the containers of one diagram.
+type c4Packer struct {
+	nodes         map[string]*NodeLayout
+	rels          []*ir.C4Rel
+	measurer      *textmetrics.Measurer
+	th            *theme.Theme
+	cfg           *config.Layout
+	shapeInRow    int
+	boundaryInRow int
+	boundaries    []*C4BoundaryLayout
+}
+
+// measure sizes a container and its descendants, laying out its elements
+// and nested boundaries relative to its content origin.
+func (p *c4Packer) measure(container *c4Container) {
+	margin := p.cfg.C4.ShapeMargin
+
+	// Element grid.
+	cells := c4GridCells(container.elements, p.rels, p.shapeInRow)
+	var colW, rowH []float32
+	for _, id := range container.elements {
+		cell := cells[id]
+		for len(rowH) <= cell[0] {
+			rowH = append(rowH, 0)
+		}
+		for len(colW) <= cell[1] {
+			colW = append(colW, 0)
+		}
+		rowH[cell[0]] = max(rowH[cell[0]], p.nodes[id].Height)
+		colW[cell[1]] = max(colW[cell[1]], p.nodes[id].Width)
+	}
+	colGaps, rowGaps := p.gridGaps(cells, len(colW), len(rowH))
+	colX := c4Offsets(colW, colGaps)
+	rowY := c4Offsets(rowH, rowGaps)
+	container.elemCenter = make(map[string][2]float32, len(container.elements))
+	for _, id := range container.elements {
+		cell := cells[id]
+		container.elemCenter[id] = [2]float32{
+			colX[cell[1]] + colW[cell[1]]/2,
+			rowY[cell[0]] + rowH[cell[0]]/2,
+		}
+	}
+	contentW := c4Span(colW, colGaps)
+	contentH := c4Span(rowH, rowGaps)
+
+	// Nested boundaries, in rows below the grid.
+	curY := contentH
+	if contentH > 0 && len(container.children) > 0 {
+		curY += margin
+	}
+	container.childPos = make([][2]float32, len(container.children))
+	for start := 0; start < len(container.children); start += p.boundaryInRow {
+		var curX, rowHeight float32
+		for idx := start; idx < min(start+p.boundaryInRow, len(container.children)); idx++ {
+			child := container.children[idx]
+			p.measure(child)
+			if idx > start {
+				curX += margin
+			}
+			container.childPos[idx] = [2]float32{curX, curY}
+			curX += child.width
+			rowHeight = max(rowHeight, child.height)
+		}
+		contentW = max(contentW, curX)
+		contentH = curY + rowHeight
+		curY = contentH + margin
+	}
+
+	if container.boundary == nil {
+		container.width, container.height = contentW, contentH
+		return
+	}
+	pad := p.cfg.C4.BoundaryPadding
+	labelW := p.measurer.Width(container.boundary.Label, p.th.FontSize, p.th.FontFamily)
+	headerH := float32(c4BoundaryLabelHeight)
+	for _, line := range c4BoundarySubtitles(container.boundary) {
+		labelW = max(labelW, p.measurer.Width(line, p.th.FontSize*c4SmallFontRatio, p.th.FontFamily))
+		headerH += c4BoundaryLineHeight
+	}
+	container.contentX = pad
+	container.contentY = pad + headerH
+	container.width = max(contentW+2*pad, labelW+2*c4BoundaryLabelOffset)
+	container.height = contentH + 2*pad + headerH
+}
+
+// gridGaps returns the gaps between neighbouring columns and rows of an
+// element grid: ShapeMargin, widened where the label of a relationship
+// between neighbouring elements needs more room.
+func (p *c4Packer) gridGaps(cells map[string][2]int, cols, rows int) ([]float32, []float32) {
+	colGaps := make([]float32, max(0, cols-1))
+	rowGaps := make([]float32, max(0, rows-1))
+	for idx := range colGaps {
+		colGaps[idx] = p.cfg.C4.ShapeMargin
+	}
+	for idx := range rowGaps {
+		rowGaps[idx] = p.cfg.C4.ShapeMargin
+	}
+	fontSize := p.th.FontSize * c4SmallFontRatio
+	for _, rel := range p.rels {
+		from, fromOK := cells[rel.From]
+		to, toOK := cells[rel.To]
+		lines := c4RelLabelLines(rel)
+		if !fromOK || !toOK || len(lines) == 0 {
+			continue
+		}
+		switch {
+		case from[0] == to[0] && c4Adjacent(from[1], to[1]):
+			var labelW float32
+			for _, line := range lines {
+				labelW = max(labelW, p.measurer.Width(line, fontSize, p.th.FontFamily))
+			}
+			gap := &colGaps[min(from[1], to[1])]
+			*gap = max(*gap, labelW+2*c4EdgeLabelClearance)
+		case from[1] == to[1] && c4Adjacent(from[0], to[0]):
+			gap := &rowGaps[min(from[0], to[0])]
+			*gap = max(*gap, fontSize*c4EdgeLabelLineHeight*float32(len(lines))+2*c4EdgeLabelClearance)
+		}
+	}
+	return colGaps, rowGaps
+}
+
+// c4Adjacent reports whether two grid indices are neighbours.
+func c4Adjacent(first, second int) bool {
+	return first-second == 1 || second-first == 1
+}
+
+// place positions a measured container with its top-left corner at
+// (posX, posY), recording element centres and boundary rectangles.
+func (p *c4Packer) place(container *c4Container, posX, posY float32) {
+	if container.boundary != nil {
+		p.boundaries = append(p.boundaries, &C4BoundaryLayout{
+			ID:          container.boundary.ID,
+			Label:       container.boundary.Label,
+			Type:        container.boundary.Type,
+			Description: container.boundary.Description,
+			Deployment:  container.boundary.Deployment,
+			X:           posX,
+			Y:           posY,
+			Width:       container.width,
+			Height:      container.height,
+		})
+	}
+	originX := posX + container.contentX
+	originY := posY + container.contentY
+	for id, center := range container.elemCenter {
+		p.nodes[id].X = originX + center[0]
+		p.nodes[id].Y = originY + center[1]
+	}
+	for idx, child := range container.children {
+		p.place(child, originX+container.childPos[idx][0], originY+container.childPos[idx][1])
+	}
+}
+
+// c4BoundarySubtitles returns the lines shown under a boundary's label.
+func c4BoundarySubtitles(boundary *ir.C4Boundary) []string {
+	var lines []string
+	if boundary.Type != "" {
+		lines = append(lines, "["+boundary.Type+"]")
+	}
+	if boundary.Description != "" {
+		lines = append(lines, boundary.Description)
+	}
+	return lines
+}
+
+// c4GridCells assigns each element a (row, column) cell. Elements fill
+// rows of perRow in order, except that an element with a directional
+// relationship to an element already placed takes the neighbouring cell
+// in that direction when it is free. Rows and columns are then renumbered
+// from zero without gaps.
+func c4GridCells(ids []string, rels []*ir.C4Rel, perRow int) map[string][2]int {
+	cells := make(map[string][2]int, len(ids))
+	taken := make(map[[2]int]bool, len(ids))
+	next := 0
+	for _, id := range ids {
+		cell, ok := c4HintedCell(id, rels, cells, taken)
+		if !ok {
+			for taken[[2]int{next / perRow, next % perRow}] {
+				next++
+			}
+			cell = [2]int{next / perRow, next % perRow}
+		}
+		cells[id] = cell
+		taken[cell] = true
+	}
+
+	rowIndex := c4Renumber(cells, 0)
+	colIndex := c4Renumber(cells, 1)
+	for id, cell := range cells {
+		cells[id] = [2]int{rowIndex[cell[0]], colIndex[cell[1]]}
+	}
+	return cells
+}
+
+// c4HintedCell returns the free cell a directional relationship between
+// id and an already placed element asks for.
+func c4HintedCell(id string, rels []*ir.C4Rel, cells map[string][2]int, taken map[[2]int]bool) ([2]int, bool) {
+	for _, rel := range rels {
+		var delta [2]int
+		switch rel.Direction {
+		case ir.C4RelUp:
+			delta = [2]int{-1, 0}
+		case ir.C4RelDown:
+			delta = [2]int{1, 0}
+		case ir.C4RelLeft:
+			delta = [2]int{0, -1}
+		case ir.C4RelRight:
+			delta = [2]int{0, 1}
+		default:
+			continue
+		}
+		var anchor string
+		switch id {
+		case rel.To:
+			anchor = rel.From
+		case rel.From:
+			anchor = rel.To
+			delta = [2]int{-delta[0], -delta[1]}
+		default:
+			continue
+		}
+		anchorCell, ok := cells[anchor]
+		if !ok {
+			continue
+		}
+		cell := [2]int{anchorCell[0] + delta[0], anchorCell[1] + delta[1]}
+		if !taken[cell] {
+			return cell, true
+		}
+	}
+	return [2]int{}, false
+}
+
+// c4Renumber maps the distinct values of one cell coordinate to 0, 1, 2...
+// in ascending order.
+func c4Renumber(cells map[string][2]int, axis int) map[int]int {
+	var values []int
+	seen := make(map[int]bool)
+	for _, cell := range cells {
+		if !seen[cell[axis]] {
+			seen[cell[axis]] = true
+			values = append(values, cell[axis])
+		}
+	}
+	sort.Ints(values)
+	index := make(map[int]int, len(values))
+	for idx, value := range values {
+		index[value] = idx
+	}
+	return index
+}
+
+// c4Offsets returns the start of each of a row of sizes, where gaps[i]
+// separates sizes i and i+1.
+func c4Offsets(sizes, gaps []float32) []float32 {
+	offsets := make([]float32, len(sizes))
+	var pos float32
+	for idx, size := range sizes {
+		offsets[idx] = pos
+		pos += size
+		if idx < len(gaps) {
+			pos += gaps[idx]
+		}
+	}
+	return offsets
+}
+
+// c4Span returns the total length of sizes separated by gaps.
+func c4Span(sizes, gaps []float32) float32 {
+	var total float32
+	for _, size := range sizes {
+		total += size
+	}
+	for _, gap := range gaps {
+		total += gap
+	}
+	return total
+}
+
+// c4EdgeRels returns the relationship behind each edge c4Edges lays out,
+// so the renderer can apply UpdateRelStyle colours.
+func c4EdgeRels(graph *ir.Graph, nodes map[string]*NodeLayout) []*ir.C4Rel {
+	var rels []*ir.C4Rel
+	for idx, edge := range graph.Edges {
+		if nodes[edge.From] == nil || nodes[edge.To] == nil {
+			continue
+		}
+		if idx < len(graph.C4Rels) {
+			rels = append(rels, graph.C4Rels[idx])
+		} else {
+			rels = append(rels, nil)
+		}
+	}
+	return rels
+}
+
+// c4Edges draws each relationship as a straight line between the borders
+// of its elements.
+func c4Edges(graph *ir.Graph, nodes map[string]*NodeLayout) []*EdgeLayout {
+	var edges []*EdgeLayout
+	for _, edge := range graph.Edges {
+		src, dst := nodes[edge.From], nodes[edge.To]
+		if src == nil || dst == nil {
+			continue
+		}
+		start := nodeBorderPoint(src, dst.X, dst.Y)
+		end := nodeBorderPoint(dst, src.X, src.Y)
+		edges = append(edges, &EdgeLayout{
+			From:        edge.From,
+			To:          edge.To,
+			Points:      [][2]float32{start, end},
+			LabelAnchor: [2]float32{(start[0] + end[0]) / 2, (start[1] + end[1]) / 2}, //nolint:mnd // midpoint.
+			Style:       edge.Style,
+			ArrowStart:  edge.ArrowStart,
+			ArrowEnd:    edge.ArrowEnd,
+		})
+	}
+	return edges
+}
+
+// labelC4Edges gives each laid-out edge its relationship's label and
+// [technology] line, measured, and moves the label by any UpdateRelStyle
+// offset. edges must hold one entry per graph edge whose elements exist.
+func labelC4Edges(graph *ir.Graph, nodes map[string]*NodeLayout, edges []*EdgeLayout, measurer *textmetrics.Measurer, th *theme.Theme) {
+	fontSize := th.FontSize * c4SmallFontRatio
+	next := 0
+	for idx, edge := range graph.Edges {
+		if nodes[edge.From] == nil || nodes[edge.To] == nil {
+			continue
+		}
+		if next >= len(edges) {
+			return
+		}
+		edgeLayout := edges[next]
+		next++
+
+		var lines []string
+		if idx < len(graph.C4Rels) {
+			rel := graph.C4Rels[idx]
+			lines = c4RelLabelLines(rel)
+			edgeLayout.LabelAnchor[0] += rel.OffsetX
+			edgeLayout.LabelAnchor[1] += rel.OffsetY
+		} else if edge.Label != nil && *edge.Label != "" {
+			lines = []string{*edge.Label}
+		}
+		if len(lines) == 0 {
+			edgeLayout.Label = nil
+			continue
+		}
+		var width float32
+		for _, line := range lines {
+			width = max(width, measurer.Width(line, fontSize, th.FontFamily))
+		}
+		edgeLayout.Label = &TextBlock{
+			Lines:    lines,
+			Width:    width,
+			Height:   fontSize * c4EdgeLabelLineHeight * float32(len(lines)),
+			FontSize: fontSize,
+		}
+	}
+}
+
+// c4RelLabelLines returns a relationship's label lines: its label and, on
+// a second line, its technology in brackets.
+func c4RelLabelLines(rel *ir.C4Rel) []string {
+	var lines []string
+	if rel.Label != "" {
+		lines = append(lines, rel.Label)
+	}
+	if rel.Technology != "" {
+		lines = append(lines, "["+rel.Technology+"]")
+	}
+	return lines
+}
+
+// c4Legend returns a legend row for every style with legend text,
+// element styles before boundary styles, positioned relative to the
+// legend's top-left corner, and the legend's size.
+func c4Legend(graph *ir.Graph, measurer *textmetrics.Measurer, th *theme.Theme) ([]C4LegendEntry, float32, float32) {
+	var legend []C4LegendEntry
+	var textW float32
+	fontSize := th.FontSize * c4SmallFontRatio
+	for kind, styles := range []map[string]*ir.C4Style{graph.C4ElementStyles, graph.C4BoundaryStyles} {
+		keys := make([]string, 0, len(styles))
+		for key, style := range styles {
+			if style.LegendText != "" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			textW = max(textW, measurer.Width(styles[key].LegendText, fontSize, th.FontFamily))
+			legend = append(legend, C4LegendEntry{
+				Text:     styles[key].LegendText,
+				Key:      key,
+				Boundary: kind == 1,
+				Y:        float32(len(legend)) * (c4LegendSwatch + c4LegendSpacing),
+				TextX:    c4LegendSwatch + c4LegendTextGap,
+			})
+		}
+	}
+	if len(legend) == 0 {
+		return nil, 0, 0
+	}
+	height := float32(len(legend))*(c4LegendSwatch+c4LegendSpacing) - c4LegendSpacing
+	return legend, c4LegendSwatch + c4LegendTextGap + textW, height
+}
+
 func sizeC4Nodes(graph *ir.Graph, measurer *textmetrics.Measurer, th *theme.Theme, cfg *config.Layout) map[string]*NodeLayout {
 	nodes := make(map[string]*NodeLayout, len(graph.Nodes))
 	lineH := th.FontSize * cfg.LabelLineHeight
@@ -111,55 +648,3 @@ func sizeC4Nodes(graph *ir.Graph, measurer *textmetrics.Measurer, th *theme.Them
 
 	return nodes
 }
-
-func computeC4BoundaryRect(boundary *ir.C4Boundary, nodes map[string]*NodeLayout, cfg *config.Layout) *C4BoundaryLayout {
-	const boundaryLabelHeight float32 = 20
-
-	if len(boundary.Children) == 0 {
-		return nil
-	}
-	pad := cfg.C4.BoundaryPadding
-
-	var minX, minY, maxX, maxY float32
-	first := true
-	for _, childID := range boundary.Children {
-		childNode, ok := nodes[childID]
-		if !ok {
-			continue
-		}
-		left := childNode.X - childNode.Width/2
-		top := childNode.Y - childNode.Height/2
-		right := childNode.X + childNode.Width/2
-		bottom := childNode.Y + childNode.Height/2
-		if first {
-			minX, minY, maxX, maxY = left, top, right, bottom
-			first = false
-		} else {
-			if left < minX {
-				minX = left
-			}
-			if top < minY {
-				minY = top
-			}
-			if right > maxX {
-				maxX = right
-			}
-			if bottom > maxY {
-				maxY = bottom
-			}
-		}
-	}
-	if first {
-		return nil
-	}
-
-	return &C4BoundaryLayout{
-		ID:     boundary.ID,
-		Label:  boundary.Label,
-		Type:   boundary.Type,
-		X:      minX - pad,
-		Y:      minY - pad - boundaryLabelHeight,
-		Width:  (maxX - minX) + 2*pad,
-		Height: (maxY - minY) + 2*pad + boundaryLabelHeight,
-	}
-}
@@ -0,0 +1,528 @@
+package layout
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/textmetrics"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// c4Extent is an axis-aligned box in the layered C4 layout.
+type c4Extent struct {
+	minX, minY, maxX, maxY float32
+}
+
+func (e c4Extent) union(other c4Extent) c4Extent {
+	return c4Extent{min(e.minX, other.minX), min(e.minY, other.minY), max(e.maxX, other.maxX), max(e.maxY, other.maxY)}
+}
+
+func (e c4Extent) overlaps(other c4Extent) bool {
+	return e.minX < other.maxX && other.minX < e.maxX && e.minY < other.maxY && other.minY < e.maxY
+}
+
+// c4LabelPositions are the fractions along a relationship, in the order
+// they are tried, at which its label may sit.
+//
+//nolint:gochecknoglobals // read-only lookup table.
+var c4LabelPositions = []float32{0.5, 0.35, 0.65, 0.2, 0.8}
+
+// c4Clusterer keeps the elements of each boundary together in the layered
+// C4 layout. Containers are ordered the same way in every layer, so each
+// one covers a contiguous run of a single global order of the nodes.
+type c4Clusterer struct {
+	nodes   map[string]*NodeLayout
+	cfg     *config.Layout
+	gap     float32
+	order   map[string]int // position of each node in the global order
+	byOrder []string
+	// position is a node's place in its layer after crossing reduction,
+	// centred on zero like the layer itself.
+	position   map[string]float32
+	boundaries []*C4BoundaryLayout
+	starts     []int // first global position of each boundary's nodes
+}
+
+// layoutC4Graph ranks elements by their relationships with the Sugiyama
+// steps, treating boundaries as clusters whose elements stay side by side,
+// then routes relationships straight or orthogonally around the elements,
+// keeping their labels apart. It returns the edges, boundary rectangles
+// and the diagram's size.
+func layoutC4Graph(graph *ir.Graph, nodes map[string]*NodeLayout, measurer *textmetrics.Measurer, th *theme.Theme, cfg *config.Layout) ([]*EdgeLayout, []*C4BoundaryLayout, float32, float32) {
+	root := buildC4Containers(graph, nodes)
+
+	// Leave room between ranks for two-line relationship labels and for
+	// the padding and header of every boundary level.
+	graphCfg := *cfg
+	boundaryH := 2*cfg.C4.BoundaryPadding + c4BoundaryLabelHeight + 2*c4BoundaryLineHeight //nolint:mnd // type and description lines.
+	graphCfg.RankSpacing = max(cfg.RankSpacing, 2*cfg.C4.ShapeMargin) + float32(c4Depth(root))*boundaryH
+	graphCfg.NodeSpacing = max(cfg.NodeSpacing, cfg.C4.ShapeMargin)
+
+	nodeIDs := sortedNodeIDs(graph.Nodes, graph.NodeOrder)
+	ranks := computeRanks(nodeIDs, graph.Edges, graph.NodeOrder)
+	layers := orderRankNodes(ranks, graph.Edges, cfg.Flowchart.OrderPasses)
+
+	clusterer := &c4Clusterer{
+		nodes:    nodes,
+		cfg:      cfg,
+		gap:      graphCfg.NodeSpacing,
+		order:    make(map[string]int, len(nodes)),
+		position: make(map[string]float32, len(nodes)),
+	}
+	for _, layer := range layers {
+		for idx, id := range layer {
+			clusterer.position[id] = float32(idx) - float32(len(layer)-1)/2 //nolint:mnd // centred like the layer.
+		}
+	}
+	clusterer.flatten(root, graph.NodeOrder)
+	for _, layer := range layers {
+		sort.SliceStable(layer, func(left, right int) bool {
+			return clusterer.order[layer[left]] < clusterer.order[layer[right]]
+		})
+	}
+	positionNodes(layers, nodes, ir.TopDown, &graphCfg)
+	clusterer.separate(root)
+
+	edges := c4GraphEdges(graph, nodes)
+	labelC4Edges(graph, nodes, edges, measurer, th)
+	placeC4Labels(edges, nodes)
+	width, height := clusterer.normalize(edges)
+	return edges, clusterer.boundaries, width, height
+}
+
+// c4Depth returns how many boundaries deep a container tree nests.
+func c4Depth(container *c4Container) int {
+	depth := 0
+	for _, child := range container.children {
+		depth = max(depth, c4Depth(child)+1)
+	}
+	return depth
+}
+
+// c4Item is an element or a nested container inside a container.
+type c4Item struct {
+	element string
+	child   *c4Container
+	key     float32
+}
+
+// items returns a container's elements and non-empty nested containers,
+// ordered by their mean position in the layers.
+func (c *c4Clusterer) items(container *c4Container, nodeOrder map[string]int) []c4Item {
+	var items []c4Item
+	for _, id := range container.elements {
+		items = append(items, c4Item{element: id, key: c.position[id]})
+	}
+	for _, child := range container.children {
+		members := c4Members(child)
+		if len(members) == 0 {
+			continue
+		}
+		var sum float32
+		for _, id := range members {
+			sum += c.position[id]
+		}
+		items = append(items, c4Item{child: child, key: sum / float32(len(members))})
+	}
+	sort.SliceStable(items, func(left, right int) bool {
+		if items[left].key != items[right].key {
+			return items[left].key < items[right].key
+		}
+		return c4ItemOrder(items[left], nodeOrder) < c4ItemOrder(items[right], nodeOrder)
+	})
+	return items
+}
+
+// c4ItemOrder returns the declaration order of an item's first element.
+func c4ItemOrder(item c4Item, nodeOrder map[string]int) int {
+	if item.child == nil {
+		return nodeOrder[item.element]
+	}
+	first := -1
+	for _, id := range c4Members(item.child) {
+		if first < 0 || nodeOrder[id] < first {
+			first = nodeOrder[id]
+		}
+	}
+	return first
+}
+
+// c4Members returns every element inside a container, nested ones included.
+func c4Members(container *c4Container) []string {
+	members := append([]string(nil), container.elements...)
+	for _, child := range container.children {
+		members = append(members, c4Members(child)...)
+	}
+	return members
+}
+
+// flatten numbers the nodes in a depth-first walk of the containers, each
+// container's items in order, so every container's nodes are contiguous.
+func (c *c4Clusterer) flatten(container *c4Container, nodeOrder map[string]int) {
+	for _, item := range c.items(container, nodeOrder) {
+		if item.child != nil {
+			c.flatten(item.child, nodeOrder)
+			continue
+		}
+		c.order[item.element] = len(c.byOrder)
+		c.byOrder = append(c.byOrder, item.element)
+	}
+}
+
+// separate moves the items of a container, innermost first, right until
+// no item overlaps an earlier one at the same height, and records the
+// boundary rectangle around each nested container. It returns the extent
+// of the container's items and the global position of its first node.
+func (c *c4Clusterer) separate(container *c4Container) (c4Extent, int) {
+	var placed []c4Extent
+	var bounds c4Extent
+	first := -1
+	for _, item := range c.itemsByOrder(container) {
+		var extent c4Extent
+		var start int
+		if item.child != nil {
+			extent, start = c.separate(item.child)
+			extent = c.boundaryRect(item.child, extent, start)
+		} else {
+			node := c.nodes[item.element]
+			extent = c4Extent{node.X - node.Width/2, node.Y - node.Height/2, node.X + node.Width/2, node.Y + node.Height/2}
+			start = c.order[item.element]
+		}
+
+		need := extent.minX
+		for _, other := range placed {
+			if other.minY < extent.maxY && extent.minY < other.maxY {
+				need = max(need, other.maxX+c.gap)
+			}
+		}
+		if shift := need - extent.minX; shift > 0 {
+			c.shiftFrom(start, shift)
+			extent.minX += shift
+			extent.maxX += shift
+		}
+
+		placed = append(placed, extent)
+		if first < 0 {
+			bounds, first = extent, start
+		} else {
+			bounds = bounds.union(extent)
+		}
+	}
+	return bounds, first
+}
+
+// itemsByOrder returns a container's items in the global node order.
+func (c *c4Clusterer) itemsByOrder(container *c4Container) []c4Item {
+	var items []c4Item
+	for _, id := range container.elements {
+		items = append(items, c4Item{element: id, key: float32(c.order[id])})
+	}
+	for _, child := range container.children {
+		members := c4Members(child)
+		if len(members) == 0 {
+			continue
+		}
+		first := c.order[members[0]]
+		for _, id := range members {
+			first = min(first, c.order[id])
+		}
+		items = append(items, c4Item{child: child, key: float32(first)})
+	}
+	sort.Slice(items, func(left, right int) bool { return items[left].key < items[right].key })
+	return items
+}
+
+// boundaryRect records the rectangle of a boundary around the extent of
+// its items and returns it.
+func (c *c4Clusterer) boundaryRect(container *c4Container, extent c4Extent, start int) c4Extent {
+	pad := c.cfg.C4.BoundaryPadding
+	headerH := float32(c4BoundaryLabelHeight + c4BoundaryLineHeight*len(c4BoundarySubtitles(container.boundary)))
+	rect := c4Extent{extent.minX - pad, extent.minY - pad - headerH, extent.maxX + pad, extent.maxY + pad}
+	c.boundaries = append(c.boundaries, &C4BoundaryLayout{
+		ID:          container.boundary.ID,
+		Label:       container.boundary.Label,
+		Type:        container.boundary.Type,
+		Description: container.boundary.Description,
+		Deployment:  container.boundary.Deployment,
+		X:           rect.minX,
+		Y:           rect.minY,
+		Width:       rect.maxX - rect.minX,
+		Height:      rect.maxY - rect.minY,
+	})
+	c.starts = append(c.starts, start)
+	return rect
+}
+
+// shiftFrom moves every node from global position start onwards, and the
+// boundaries around them, right by shift.
+func (c *c4Clusterer) shiftFrom(start int, shift float32) {
+	for _, id := range c.byOrder[start:] {
+		c.nodes[id].X += shift
+	}
+	for idx, boundary := range c.boundaries {
+		if c.starts[idx] >= start {
+			boundary.X += shift
+		}
+	}
+}
+
+// normalize moves everything so the content starts at the C4 padding and
+// returns the diagram's size.
+func (c *c4Clusterer) normalize(edges []*EdgeLayout) (float32, float32) {
+	if len(c.nodes) == 0 {
+		return 2 * c.cfg.C4.PaddingX, 2 * c.cfg.C4.PaddingY
+	}
+	var bounds c4Extent
+	first := true
+	add := func(extent c4Extent) {
+		if first {
+			bounds, first = extent, false
+		} else {
+			bounds = bounds.union(extent)
+		}
+	}
+	for _, node := range c.nodes {
+		add(c4Extent{node.X - node.Width/2, node.Y - node.Height/2, node.X + node.Width/2, node.Y + node.Height/2})
+	}
+	for _, boundary := range c.boundaries {
+		add(c4Extent{boundary.X, boundary.Y, boundary.X + boundary.Width, boundary.Y + boundary.Height})
+	}
+	for _, edge := range edges {
+		for _, pt := range edge.Points {
+			add(c4Extent{pt[0], pt[1], pt[0], pt[1]})
+		}
+		if edge.Label != nil {
+			halfW, halfH := edge.Label.Width/2, edge.Label.Height/2 //nolint:mnd // labels are centred on the anchor.
+			add(c4Extent{edge.LabelAnchor[0] - halfW, edge.LabelAnchor[1] - halfH, edge.LabelAnchor[0] + halfW, edge.LabelAnchor[1] + halfH})
+		}
+	}
+
+	dx := c.cfg.C4.PaddingX - bounds.minX
+	dy := c.cfg.C4.PaddingY - bounds.minY
+	for _, node := range c.nodes {
+		node.X += dx
+		node.Y += dy
+	}
+	for _, boundary := range c.boundaries {
+		boundary.X += dx
+		boundary.Y += dy
+	}
+	for _, edge := range edges {
+		for idx := range edge.Points {
+			edge.Points[idx][0] += dx
+			edge.Points[idx][1] += dy
+		}
+		edge.LabelAnchor[0] += dx
+		edge.LabelAnchor[1] += dy
+	}
+	return bounds.maxX - bounds.minX + 2*c.cfg.C4.PaddingX, bounds.maxY - bounds.minY + 2*c.cfg.C4.PaddingY
+}
+
+// c4GraphEdges routes each relationship as a straight line between the
+// borders of its elements when that line clears every other element, and
+// otherwise along the shortest clear orthogonal route: out of the source,
+// along a free column and into the target. Relationships with no clear
+// route of either kind fall back to grid routing.
+func c4GraphEdges(graph *ir.Graph, nodes map[string]*NodeLayout) []*EdgeLayout {
+	obstacleGrid := buildGrid(nodes, defaultCellSize, defaultNodePad)
+	// Obstacles go in declaration order so that equally short routes are
+	// chosen the same way every time.
+	nodeIDs := sortedNodeIDs(graph.Nodes, graph.NodeOrder)
+	var edges []*EdgeLayout
+	for _, edge := range graph.Edges {
+		src, dst := nodes[edge.From], nodes[edge.To]
+		if src == nil || dst == nil {
+			continue
+		}
+		var obstacles []c4Extent
+		for _, id := range nodeIDs {
+			if node := nodes[id]; node != nil && id != edge.From && id != edge.To {
+				obstacles = append(obstacles, c4NodeExtent(node, defaultNodePad))
+			}
+		}
+
+		points := c4RouteStraight(src, dst, obstacles)
+		if points == nil {
+			points = c4RouteOrthogonal(src, dst, obstacles)
+		}
+		if points == nil {
+			startX, startY, endX, endY := edgeEndpoints(src, dst, ir.TopDown)
+			points = simplifyPath(obstacleGrid.findPath(startX, startY, endX, endY, edge.From, edge.To))
+		}
+		if points == nil {
+			points, _ = routeTD(src, dst)
+		}
+		edges = append(edges, &EdgeLayout{
+			From:        edge.From,
+			To:          edge.To,
+			Points:      points,
+			LabelAnchor: pathMidpoint(points),
+			Style:       edge.Style,
+			ArrowStart:  edge.ArrowStart,
+			ArrowEnd:    edge.ArrowEnd,
+		})
+	}
+	return edges
+}
+
+// c4NodeExtent returns a node's box grown by pad on every side.
+func c4NodeExtent(node *NodeLayout, pad float32) c4Extent {
+	return c4Extent{node.X - node.Width/2 - pad, node.Y - node.Height/2 - pad, node.X + node.Width/2 + pad, node.Y + node.Height/2 + pad}
+}
+
+// c4RouteStraight returns the straight line between the borders of src
+// and dst, or nil when it crosses an obstacle.
+func c4RouteStraight(src, dst *NodeLayout, obstacles []c4Extent) [][2]float32 {
+	points := [][2]float32{nodeBorderPoint(src, dst.X, dst.Y), nodeBorderPoint(dst, src.X, src.Y)}
+	if c4PathHits(points, obstacles) {
+		return nil
+	}
+	return points
+}
+
+// c4RouteOrthogonal returns the shortest route of vertical and horizontal
+// segments from the side of src facing dst to the side of dst facing src
+// that crosses no obstacle, or nil when there is none. Elements in the
+// same rank have no such sides.
+func c4RouteOrthogonal(src, dst *NodeLayout, obstacles []c4Extent) [][2]float32 {
+	dir := float32(1)
+	switch {
+	case dst.Y-dst.Height/2 >= src.Y+src.Height/2:
+	case dst.Y+dst.Height/2 <= src.Y-src.Height/2:
+		dir = -1
+	default:
+		return nil
+	}
+	startY := src.Y + dir*src.Height/2
+	endY := dst.Y - dir*dst.Height/2
+	// Turn halfway between the elements and their nearest neighbours in
+	// the ranks between, so adjacent ranks get a single bend.
+	turnA, turnB := (startY+endY)/2, (startY+endY)/2 //nolint:mnd // midway.
+	for _, obstacle := range obstacles {
+		if dir > 0 {
+			if obstacle.minY >= startY && obstacle.maxY <= endY {
+				turnA = min(turnA, (startY+obstacle.minY)/2) //nolint:mnd // midway.
+				turnB = max(turnB, (obstacle.maxY+endY)/2)   //nolint:mnd // midway.
+			}
+		} else if obstacle.maxY <= startY && obstacle.minY >= endY {
+			turnA = max(turnA, (startY+obstacle.maxY)/2) //nolint:mnd // midway.
+			turnB = min(turnB, (obstacle.minY+endY)/2)   //nolint:mnd // midway.
+		}
+	}
+
+	columns := []float32{src.X, dst.X}
+	for _, obstacle := range obstacles {
+		columns = append(columns, obstacle.minX-defaultNodePad, obstacle.maxX+defaultNodePad)
+	}
+	var best [][2]float32
+	bestLen := float32(math.Inf(1))
+	for _, column := range columns {
+		points := simplifyPath(c4Dedupe([][2]float32{
+			{src.X, startY}, {src.X, turnA}, {column, turnA},
+			{column, turnB}, {dst.X, turnB}, {dst.X, endY},
+		}))
+		if length := c4PathLength(points); length < bestLen && !c4PathHits(points, obstacles) {
+			best, bestLen = points, length
+		}
+	}
+	return best
+}
+
+// c4Dedupe drops points that repeat the point before them.
+func c4Dedupe(points [][2]float32) [][2]float32 {
+	result := points[:1]
+	for _, point := range points[1:] {
+		if point != result[len(result)-1] {
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// c4PathLength returns the total length of a polyline.
+func c4PathLength(points [][2]float32) float32 {
+	var total float32
+	for idx := 1; idx < len(points); idx++ {
+		total += float32(math.Hypot(float64(points[idx][0]-points[idx-1][0]), float64(points[idx][1]-points[idx-1][1])))
+	}
+	return total
+}
+
+// c4PathHits reports whether any segment of a polyline passes through an
+// obstacle.
+func c4PathHits(points [][2]float32, obstacles []c4Extent) bool {
+	for idx := 1; idx < len(points); idx++ {
+		for _, obstacle := range obstacles {
+			if c4SegmentHits(points[idx-1], points[idx], obstacle) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// c4SegmentHits reports whether the segment from start to end passes
+// through the inside of box, clipping it to each pair of sides in turn.
+func c4SegmentHits(start, end [2]float32, box c4Extent) bool {
+	lo, hi := float32(0), float32(1)
+	clip := func(origin, delta, minV, maxV float32) bool {
+		if delta == 0 {
+			return origin > minV && origin < maxV
+		}
+		near, far := (minV-origin)/delta, (maxV-origin)/delta
+		if near > far {
+			near, far = far, near
+		}
+		lo, hi = max(lo, near), min(hi, far)
+		return lo < hi
+	}
+	return clip(start[0], end[0]-start[0], box.minX, box.maxX) && clip(start[1], end[1]-start[1], box.minY, box.maxY)
+}
+
+// placeC4Labels slides each relationship label along its route, from the
+// middle outwards, to the first spot where it overlaps no element, no
+// earlier label and no other relationship. Any UpdateRelStyle offset is
+// kept. A label with no free spot stays in the middle.
+func placeC4Labels(edges []*EdgeLayout, nodes map[string]*NodeLayout) {
+	var obstacles []c4Extent
+	for _, node := range nodes {
+		obstacles = append(obstacles, c4NodeExtent(node, 0))
+	}
+	for idx, edge := range edges {
+		if edge.Label == nil || len(edge.Points) == 0 {
+			continue
+		}
+		mid := pathMidpoint(edge.Points)
+		offsetX, offsetY := edge.LabelAnchor[0]-mid[0], edge.LabelAnchor[1]-mid[1]
+		halfW, halfH := edge.Label.Width/2, edge.Label.Height/2 //nolint:mnd // labels are centred on the anchor.
+		box := func(anchor [2]float32) c4Extent {
+			return c4Extent{anchor[0] - halfW, anchor[1] - halfH, anchor[0] + halfW, anchor[1] + halfH}
+		}
+
+		chosen := edge.LabelAnchor
+		for _, frac := range c4LabelPositions {
+			point := pathPointAt(edge.Points, frac)
+			anchor := [2]float32{point[0] + offsetX, point[1] + offsetY}
+			if !slices.ContainsFunc(obstacles, box(anchor).overlaps) && !c4CrossesOthers(edges, idx, box(anchor)) {
+				chosen = anchor
+				break
+			}
+		}
+		edge.LabelAnchor = chosen
+		obstacles = append(obstacles, box(chosen))
+	}
+}
+
+// c4CrossesOthers reports whether any relationship but edges[skip] passes
+// through box.
+func c4CrossesOthers(edges []*EdgeLayout, skip int, box c4Extent) bool {
+	for idx, edge := range edges {
+		if idx != skip && c4PathHits(edge.Points, []c4Extent{box}) {
+			return true
+		}
+	}
+	return false
+}
@@ -94,3 +94,274 @@ func TestC4LayoutEmpty(t *testing.T) {
 		t.Errorf("Nodes = %d", len(lay.Nodes))
 	}
 }
+
+// c4RowGraph returns a context diagram of systems with the given
+// relationships, added as both C4 relationships and edges.
+func c4RowGraph(ids []string, rels ...*ir.C4Rel) *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.C4
+	for _, id := range ids {
+		label := id
+		graph.EnsureNode(id, &label, nil)
+		graph.C4Elements = append(graph.C4Elements, &ir.C4Element{ID: id, Label: id, Type: ir.C4System})
+	}
+	for _, rel := range rels {
+		label := rel.Label
+		graph.C4Rels = append(graph.C4Rels, rel)
+		graph.Edges = append(graph.Edges, &ir.Edge{From: rel.From, To: rel.To, Label: &label, Directed: true, ArrowEnd: true})
+	}
+	return graph
+}
+
+func TestC4LayoutRows(t *testing.T) {
+	graph := c4RowGraph([]string{"a", "b", "c"})
+	graph.C4ShapeInRow = 2
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	nodeA, nodeB, nodeC := lay.Nodes["a"], lay.Nodes["b"], lay.Nodes["c"]
+	if nodeA.Y != nodeB.Y || nodeB.X <= nodeA.X {
+		t.Errorf("b at (%v,%v) should be right of a at (%v,%v)", nodeB.X, nodeB.Y, nodeA.X, nodeA.Y)
+	}
+	if nodeC.X != nodeA.X || nodeC.Y <= nodeA.Y {
+		t.Errorf("c at (%v,%v) should start a new row under a at (%v,%v)", nodeC.X, nodeC.Y, nodeA.X, nodeA.Y)
+	}
+}
+
+func TestC4LayoutDirectionalRels(t *testing.T) {
+	graph := c4RowGraph([]string{"a", "b", "c", "d"},
+		&ir.C4Rel{From: "a", To: "b", Label: "below", Direction: ir.C4RelDown},
+		&ir.C4Rel{From: "c", To: "a", Label: "right", Direction: ir.C4RelRight},
+		&ir.C4Rel{From: "d", To: "b", Label: "up", Direction: ir.C4RelUp},
+	)
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	nodeA, nodeB, nodeC, nodeD := lay.Nodes["a"], lay.Nodes["b"], lay.Nodes["c"], lay.Nodes["d"]
+	if nodeB.X != nodeA.X || nodeB.Y <= nodeA.Y {
+		t.Errorf("Rel_D target b at (%v,%v) should be below a at (%v,%v)", nodeB.X, nodeB.Y, nodeA.X, nodeA.Y)
+	}
+	if nodeC.Y != nodeA.Y || nodeC.X >= nodeA.X {
+		t.Errorf("Rel_R source c at (%v,%v) should be left of a at (%v,%v)", nodeC.X, nodeC.Y, nodeA.X, nodeA.Y)
+	}
+	// d's cell below b is free, so Rel_U(d, b) puts d there.
+	if nodeD.X != nodeB.X || nodeD.Y <= nodeB.Y {
+		t.Errorf("Rel_U source d at (%v,%v) should be below b at (%v,%v)", nodeD.X, nodeD.Y, nodeB.X, nodeB.Y)
+	}
+	if len(lay.Edges) != 3 {
+		t.Fatalf("Edges = %d, want 3", len(lay.Edges))
+	}
+	// The a -> b edge leaves a's bottom and enters b's top.
+	edge := lay.Edges[0]
+	if got, want := edge.Points[0][1], nodeA.Y+nodeA.Height/2; got != want {
+		t.Errorf("edge start y = %v, want %v", got, want)
+	}
+	if got, want := edge.Points[1][1], nodeB.Y-nodeB.Height/2; got != want {
+		t.Errorf("edge end y = %v, want %v", got, want)
+	}
+}
+
+func TestC4LayoutNestedBoundaries(t *testing.T) {
+	graph := c4RowGraph([]string{"user", "api", "db"},
+		&ir.C4Rel{From: "api", To: "db", Label: "reads", OffsetX: 5, OffsetY: -10},
+	)
+	graph.C4Boundaries = []*ir.C4Boundary{
+		{ID: "cloud", Label: "Cloud", Type: "AWS", Deployment: true},
+		{ID: "web", Label: "Web", ParentID: "cloud", Deployment: true, Children: []string{"api"}},
+		{ID: "data", Label: "Data", ParentID: "cloud", Children: []string{"db"}},
+	}
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	cd, ok := lay.Diagram.(C4Data)
+	if !ok {
+		t.Fatal("Diagram is not C4Data")
+	}
+	if len(cd.Boundaries) != 3 {
+		t.Fatalf("Boundaries = %d, want 3", len(cd.Boundaries))
+	}
+	rects := make(map[string]*C4BoundaryLayout)
+	for _, boundary := range cd.Boundaries {
+		rects[boundary.ID] = boundary
+	}
+	if !rects["cloud"].Deployment || rects["data"].Deployment {
+		t.Error("Deployment flags not carried into the layout")
+	}
+	contains := func(outer *C4BoundaryLayout, left, top, right, bottom float32) bool {
+		return left >= outer.X && top >= outer.Y && right <= outer.X+outer.Width && bottom <= outer.Y+outer.Height
+	}
+	for _, child := range []string{"web", "data"} {
+		inner := rects[child]
+		if !contains(rects["cloud"], inner.X, inner.Y, inner.X+inner.Width, inner.Y+inner.Height) {
+			t.Errorf("boundary %s %+v is outside cloud %+v", child, inner, rects["cloud"])
+		}
+	}
+	for id, boundary := range map[string]string{"api": "web", "db": "data"} {
+		node := lay.Nodes[id]
+		if !contains(rects[boundary], node.X-node.Width/2, node.Y-node.Height/2, node.X+node.Width/2, node.Y+node.Height/2) {
+			t.Errorf("%s is outside boundary %s", id, boundary)
+		}
+	}
+	// Boundaries in the same row sit side by side.
+	if rects["web"].Y != rects["data"].Y || rects["data"].X <= rects["web"].X+rects["web"].Width {
+		t.Errorf("web %+v and data %+v should share a row", rects["web"], rects["data"])
+	}
+	// Top-level elements come before boundaries.
+	if user := lay.Nodes["user"]; user.Y+user.Height/2 >= rects["cloud"].Y {
+		t.Errorf("user should be above the cloud boundary")
+	}
+
+	edge := lay.Edges[0]
+	midX := (edge.Points[0][0] + edge.Points[1][0]) / 2
+	midY := (edge.Points[0][1] + edge.Points[1][1]) / 2
+	if edge.LabelAnchor[0] != midX+5 || edge.LabelAnchor[1] != midY-10 {
+		t.Errorf("label anchor = %v, want offset from (%v,%v)", edge.LabelAnchor, midX, midY)
+	}
+	if len(cd.Rels) != 1 || cd.Rels[0] != graph.C4Rels[0] {
+		t.Errorf("Rels not aligned with edges: %v", cd.Rels)
+	}
+}
+
+func TestC4LayoutLegend(t *testing.T) {
+	graph := c4RowGraph([]string{"a"})
+	graph.C4ElementStyles = map[string]*ir.C4Style{
+		"a":      {BgColor: "grey", LegendText: "Legacy"},
+		"person": {BgColor: "red"},
+	}
+	graph.C4BoundaryStyles = map[string]*ir.C4Style{"sys": {LegendText: "Owned"}}
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	cd, ok := lay.Diagram.(C4Data)
+	if !ok {
+		t.Fatal("Diagram is not C4Data")
+	}
+	if len(cd.Legend) != 2 {
+		t.Fatalf("Legend = %d entries, want 2", len(cd.Legend))
+	}
+	if cd.Legend[0].Key != "a" || cd.Legend[0].Boundary || cd.Legend[1].Key != "sys" || !cd.Legend[1].Boundary {
+		t.Errorf("legend = %+v", cd.Legend)
+	}
+	node := lay.Nodes["a"]
+	if cd.Legend[0].Y <= node.Y+node.Height/2 || cd.Legend[1].Y+cd.LegendSwatch > lay.Height {
+		t.Errorf("legend rows %+v should sit under the diagram, within height %v", cd.Legend, lay.Height)
+	}
+}
+
+func TestC4LayoutRelTechnologyLine(t *testing.T) {
+	graph := c4RowGraph([]string{"a", "b"}, &ir.C4Rel{From: "a", To: "b", Label: "Calls", Technology: "gRPC"})
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	label := lay.Edges[0].Label
+	if label == nil || len(label.Lines) != 2 || label.Lines[1] != "[gRPC]" {
+		t.Fatalf("label = %+v, want a [gRPC] second line", label)
+	}
+	if label.Width <= 0 || label.Height <= 0 {
+		t.Errorf("label size = %vx%v", label.Width, label.Height)
+	}
+}
+
+func TestC4GraphLayoutClusters(t *testing.T) {
+	// Crossing reduction alone would put ext between api and db.
+	graph := c4RowGraph([]string{"user", "partner", "admin", "api", "ext", "db"},
+		&ir.C4Rel{From: "user", To: "api", Label: "uses", Technology: "HTTPS"},
+		&ir.C4Rel{From: "partner", To: "ext", Label: "calls"},
+		&ir.C4Rel{From: "admin", To: "db", Label: "queries"},
+	)
+	graph.C4Boundaries = []*ir.C4Boundary{
+		{ID: "sys", Label: "System", Children: []string{"api", "db"}},
+	}
+	cfg := config.DefaultLayout()
+	cfg.C4.Layout = config.C4LayoutGraph
+	lay := computeC4Layout(graph, theme.Modern(), cfg)
+
+	cd, ok := lay.Diagram.(C4Data)
+	if !ok {
+		t.Fatal("Diagram is not C4Data")
+	}
+	if len(cd.Boundaries) != 1 {
+		t.Fatalf("Boundaries = %d, want 1", len(cd.Boundaries))
+	}
+	box := cd.Boundaries[0]
+	for id, node := range lay.Nodes {
+		left, top := node.X-node.Width/2, node.Y-node.Height/2
+		right, bottom := node.X+node.Width/2, node.Y+node.Height/2
+		inside := left >= box.X && top >= box.Y && right <= box.X+box.Width && bottom <= box.Y+box.Height
+		overlaps := left < box.X+box.Width && box.X < right && top < box.Y+box.Height && box.Y < bottom
+		member := id == "api" || id == "db"
+		if member && !inside {
+			t.Errorf("%s should be inside the boundary %+v", id, box)
+		}
+		if !member && overlaps {
+			t.Errorf("%s at (%v,%v) overlaps the boundary %+v", id, node.X, node.Y, box)
+		}
+		if left < 0 || top < 0 || right > lay.Width || bottom > lay.Height {
+			t.Errorf("%s is outside the %vx%v diagram", id, lay.Width, lay.Height)
+		}
+	}
+	for id, node := range lay.Nodes {
+		for otherID, other := range lay.Nodes {
+			if id < otherID && abs32(node.X-other.X) < (node.Width+other.Width)/2 && abs32(node.Y-other.Y) < (node.Height+other.Height)/2 {
+				t.Errorf("%s and %s overlap", id, otherID)
+			}
+		}
+	}
+	if len(lay.Edges) != 3 {
+		t.Fatalf("Edges = %d, want 3", len(lay.Edges))
+	}
+	if label := lay.Edges[0].Label; label == nil || len(label.Lines) != 2 {
+		t.Errorf("first edge label = %+v, want label and technology", label)
+	}
+}
+
+func TestC4GraphLayoutDirective(t *testing.T) {
+	graph := c4RowGraph([]string{"a", "b"}, &ir.C4Rel{From: "a", To: "b", Label: "uses"})
+	graph.C4Layout = "graph"
+	lay := computeC4Layout(graph, theme.Modern(), config.DefaultLayout())
+
+	// Rows would put b beside a; the graph layout ranks it below.
+	if lay.Nodes["b"].Y <= lay.Nodes["a"].Y {
+		t.Errorf("b at y=%v should be below a at y=%v", lay.Nodes["b"].Y, lay.Nodes["a"].Y)
+	}
+}
+
+func TestC4GraphLayoutRoutes(t *testing.T) {
+	// a->c would cross b as a straight line.
+	graph := c4RowGraph([]string{"a", "b", "c"},
+		&ir.C4Rel{From: "a", To: "b", Label: "first", Technology: "HTTPS"},
+		&ir.C4Rel{From: "b", To: "c", Label: "second", Technology: "HTTPS"},
+		&ir.C4Rel{From: "a", To: "c", Label: "skips a rank", Technology: "HTTPS"},
+	)
+	cfg := config.DefaultLayout()
+	cfg.C4.Layout = config.C4LayoutGraph
+	lay := computeC4Layout(graph, theme.Modern(), cfg)
+
+	if len(lay.Edges) != 3 {
+		t.Fatalf("Edges = %d, want 3", len(lay.Edges))
+	}
+	skip := lay.Edges[2]
+	if c4PathHits(skip.Points, []c4Extent{c4NodeExtent(lay.Nodes["b"], 0)}) {
+		t.Errorf("a->c route %v crosses b", skip.Points)
+	}
+	for idx := 1; idx < len(skip.Points); idx++ {
+		prev, cur := skip.Points[idx-1], skip.Points[idx]
+		if prev[0] != cur[0] && prev[1] != cur[1] {
+			t.Errorf("a->c segment %v-%v is neither vertical nor horizontal", prev, cur)
+		}
+	}
+	if len(skip.Points) > 6 {
+		t.Errorf("a->c route has %d points, want at most 6", len(skip.Points))
+	}
+
+	for idx, edge := range lay.Edges {
+		box := c4Extent{
+			edge.LabelAnchor[0] - edge.Label.Width/2, edge.LabelAnchor[1] - edge.Label.Height/2,
+			edge.LabelAnchor[0] + edge.Label.Width/2, edge.LabelAnchor[1] + edge.Label.Height/2,
+		}
+		for _, other := range lay.Edges[idx+1:] {
+			otherBox := c4Extent{
+				other.LabelAnchor[0] - other.Label.Width/2, other.LabelAnchor[1] - other.Label.Height/2,
+				other.LabelAnchor[0] + other.Label.Width/2, other.LabelAnchor[1] + other.Label.Height/2,
+			}
+			if box.overlaps(otherBox) {
+				t.Errorf("label %q overlaps %q", edge.Label.Lines[0], other.Label.Lines[0])
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package layout
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,46 +23,106 @@ const (
 	ganttDailyTickLimit   = 14
 	ganttMonthlyTickLimit = 90
 	ganttMonthlyTickDays  = 30
+	ganttMinTickSpacing   = 60
 )
 
-var ganttDurationRe = regexp.MustCompile(`^(\d+)([dwmhDWMH])$`)
+var ganttDurationRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)(ms|[dwmhsDWMHS])$`)
+
+// ganttDateTokens maps dayjs dateFormat tokens to Go layout elements,
+// longest first so "MMM" is not read as "MM" and "M".
+//
+//nolint:gochecknoglobals // read-only lookup table.
+var ganttDateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"DD", "02"}, {"D", "2"},
+	{"dddd", "Monday"}, {"ddd", "Mon"},
+	{"HH", "15"}, {"H", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"}, {"ss", "05"}, {"s", "5"},
+	{"SSS", "000"}, {"A", "PM"}, {"a", "pm"},
+	{"ZZ", "-0700"}, {"Z", "-07:00"},
+}
 
 // mermaidDateToGoLayout converts mermaid dateFormat tokens to Go time layout.
+// Text that is not a token is copied unchanged.
 func mermaidDateToGoLayout(format string) string {
-	replacer := strings.NewReplacer(
-		"YYYY", "2006", "YY", "06",
-		"MM", "01", "DD", "02",
-		"HH", "15", "mm", "04", "ss", "05",
-	)
-	return replacer.Replace(format)
+	var out strings.Builder
+	for idx := 0; idx < len(format); {
+		matched := false
+		for _, tok := range ganttDateTokens {
+			if strings.HasPrefix(format[idx:], tok.token) {
+				out.WriteString(tok.layout)
+				idx += len(tok.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out.WriteByte(format[idx])
+			idx++
+		}
+	}
+	return out.String()
 }
 
-// parseMermaidDuration converts a mermaid duration string to time.Duration.
+// parseGanttDate reads a task date in the chart's dateFormat. The "X" and
+// "x" formats are Unix timestamps in seconds and milliseconds; other
+// formats are read as UTC unless they carry a zone.
+func parseGanttDate(value, dateFormat string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	switch strings.TrimSpace(dateFormat) {
+	case "X":
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		whole := math.Floor(secs)
+		return time.Unix(int64(whole), int64((secs-whole)*float64(time.Second))).UTC(), true
+	case "x":
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(millis).UTC(), true
+	}
+	parsed, err := time.Parse(mermaidDateToGoLayout(dateFormat), value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// parseMermaidDuration converts a mermaid duration string such as "3d",
+// "1.5h", "30m" or "250ms" to time.Duration. Units are case-insensitive.
 func parseMermaidDuration(durStr string) time.Duration {
-	match := ganttDurationRe.FindStringSubmatch(durStr)
+	match := ganttDurationRe.FindStringSubmatch(strings.TrimSpace(durStr))
 	if match == nil {
 		return 0
 	}
-	count, err := strconv.Atoi(match[1]) // regex guarantees digits
+	count, err := strconv.ParseFloat(match[1], 64) // regex guarantees a number
 	if err != nil {
 		return 0
 	}
+	var unit time.Duration
 	switch strings.ToLower(match[2]) {
-	case "d":
-		return time.Duration(count) * ganttHoursPerDay * time.Hour
 	case "w":
-		return time.Duration(count) * ganttDaysPerWeek * ganttHoursPerDay * time.Hour
+		unit = ganttDaysPerWeek * ganttHoursPerDay * time.Hour
+	case "d":
+		unit = ganttHoursPerDay * time.Hour
 	case "h":
-		return time.Duration(count) * time.Hour
+		unit = time.Hour
 	case "m":
-		return time.Duration(count) * time.Minute
-	default:
-		return 0
+		unit = time.Minute
+	case "s":
+		unit = time.Second
+	case "ms":
+		unit = time.Millisecond
 	}
+	return time.Duration(count * float64(unit))
 }
 
 // isExcluded checks if a date should be excluded based on the excludes list.
-func isExcluded(checkTime time.Time, excludes []string, goLayout string) bool {
+func isExcluded(checkTime time.Time, excludes []string, dateFormat string) bool {
 	dayName := strings.ToLower(checkTime.Weekday().String())
 	for _, ex := range excludes {
 		ex = strings.ToLower(strings.TrimSpace(ex))
@@ -72,7 +133,7 @@ func isExcluded(checkTime time.Time, excludes []string, goLayout string) bool {
 			return true
 		}
 		// Try parsing as a date.
-		if exDate, err := time.Parse(goLayout, ex); err == nil {
+		if exDate, ok := parseGanttDate(ex, dateFormat); ok {
 			if checkTime.Year() == exDate.Year() && checkTime.YearDay() == exDate.YearDay() {
 				return true
 			}
@@ -82,7 +143,7 @@ func isExcluded(checkTime time.Time, excludes []string, goLayout string) bool {
 }
 
 // addWorkingDays adds n working days to start, skipping excluded days.
-func addWorkingDays(start time.Time, days int, excludes []string, goLayout string) time.Time {
+func addWorkingDays(start time.Time, days int, excludes []string, dateFormat string) time.Time {
 	if len(excludes) == 0 {
 		return start.Add(time.Duration(days) * ganttHoursPerDay * time.Hour)
 	}
@@ -90,7 +151,7 @@ func addWorkingDays(start time.Time, days int, excludes []string, goLayout strin
 	added := 0
 	for added < days {
 		current = current.Add(ganttHoursPerDay * time.Hour)
-		if !isExcluded(current, excludes, goLayout) {
+		if !isExcluded(current, excludes, dateFormat) {
 			added++
 		}
 	}
@@ -103,7 +164,7 @@ type resolvedTask struct {
 }
 
 func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
-	goLayout := mermaidDateToGoLayout(graph.GanttDateFormat)
+	dateFormat := graph.GanttDateFormat
 	sidePad := cfg.Gantt.SidePadding
 	topPad := cfg.Gantt.TopPadding
 	barH := cfg.Gantt.BarHeight
@@ -130,7 +191,7 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 	var prevEnd time.Time
 
 	for _, task := range allTasks {
-		start, end := resolveGanttTaskDates(task, resolved, &prevEnd, goLayout, graph.GanttExcludes)
+		start, end := resolveGanttTaskDates(task, resolved, &prevEnd, dateFormat, graph.GanttExcludes, graph.GanttInclusiveEndDates)
 		if task.ID != "" {
 			resolved[task.ID] = resolvedTask{Start: start, End: end}
 		}
@@ -138,20 +199,20 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 	}
 
 	// Find global date range.
-	minDate, maxDate := ganttDateRange(allTasks, resolved, goLayout, graph.GanttExcludes)
+	minDate, maxDate := ganttDateRange(allTasks, resolved, dateFormat, graph.GanttExcludes, graph.GanttInclusiveEndDates)
 
+	// ganttDateRange guarantees maxDate is after minDate, so sub-day
+	// charts keep their own scale.
 	totalDays := maxDate.Sub(minDate).Hours() / ganttHoursPerDay
-	if totalDays < 1 {
-		totalDays = 1
-	}
+	tickTimes := ganttAxisTimes(minDate, maxDate, totalDays, graph.GanttTickInterval)
 
-	chartW := float32(totalDays) * ganttPixelsPerDay
-	if chartW < ganttMinChartWidth {
-		chartW = ganttMinChartWidth
-	}
-	if chartW > ganttMaxChartWidth {
-		chartW = ganttMaxChartWidth
+	// Widen the chart until ticks are far enough apart to label.
+	chartW := max(float32(totalDays)*ganttPixelsPerDay, ganttMinChartWidth)
+	for idx := 1; idx < len(tickTimes); idx++ {
+		frac := float32(tickTimes[idx].Sub(tickTimes[idx-1]).Hours() / ganttHoursPerDay / totalDays)
+		chartW = max(chartW, ganttMinTickSpacing/frac)
 	}
+	chartW = min(chartW, ganttMaxChartWidth)
 
 	chartX := sidePad
 	chartY := titleHeight + topPad
@@ -164,6 +225,7 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 
 	// Build sections and tasks.
 	sections := make([]GanttSectionLayout, 0, len(graph.GanttSections))
+	var markers []GanttMarker
 	curY := chartY
 	prevEnd = time.Time{}
 
@@ -172,7 +234,14 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 		secStartY := curY
 
 		for _, task := range sec.Tasks {
-			start, end := resolveGanttTaskDates(task, resolved, &prevEnd, goLayout, graph.GanttExcludes)
+			start, end := resolveGanttTaskDates(task, resolved, &prevEnd, dateFormat, graph.GanttExcludes, graph.GanttInclusiveEndDates)
+			prevEnd = end
+
+			// Vertical markers span every section instead of taking a row.
+			if hasTag(task.Tags, "vert") {
+				markers = append(markers, GanttMarker{Label: task.Label, X: dateToX(start)})
+				continue
+			}
 
 			taskX := dateToX(start)
 			taskW := dateToX(end) - taskX
@@ -191,9 +260,10 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 				IsDone:      hasTag(task.Tags, "done"),
 				IsActive:    hasTag(task.Tags, "active"),
 				IsMilestone: hasTag(task.Tags, "milestone"),
+				Link:        graph.NodeLinks[task.ID],
+				Callback:    graph.GanttCallbacks[task.ID],
 			})
 
-			prevEnd = end
 			curY += barH + barGap
 		}
 
@@ -212,27 +282,33 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 	}
 
 	// Axis ticks.
-	var axisTicks []GanttAxisTick
-	tickDays := ganttDaysPerWeek
-	if totalDays < ganttDailyTickLimit {
-		tickDays = 1
-	} else if totalDays > ganttMonthlyTickLimit {
-		tickDays = ganttMonthlyTickDays
+	axisFormat := graph.GanttAxisFormat
+	if axisFormat == "" {
+		axisFormat = ganttAutoAxisFormat(totalDays)
 	}
-	for dateVal := minDate; !dateVal.After(maxDate); dateVal = dateVal.AddDate(0, 0, tickDays) {
+	axisTicks := make([]GanttAxisTick, 0, len(tickTimes))
+	for _, tick := range tickTimes {
 		axisTicks = append(axisTicks, GanttAxisTick{
-			Label: dateVal.Format("2006-01-02"),
-			X:     dateToX(dateVal),
+			Label: formatD3Time(tick, axisFormat),
+			X:     dateToX(tick),
 		})
 	}
 
-	// Today marker.
-	today := time.Now()
+	// Today marker. "off" hides it; any other value but "on" styles it.
+	now := time.Now
+	if cfg.Gantt.Now != nil {
+		now = cfg.Gantt.Now
+	}
+	today := now()
 	showToday := graph.GanttTodayMarker != "off" && !today.Before(minDate) && !today.After(maxDate)
 	var todayX float32
 	if showToday {
 		todayX = dateToX(today)
 	}
+	var todayStyle string
+	if marker := strings.TrimSpace(graph.GanttTodayMarker); marker != "on" && marker != "off" {
+		todayStyle = marker
+	}
 
 	totalW := sidePad*2 + chartW
 	totalH := curY + topPad
@@ -243,21 +319,25 @@ func computeGanttLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *L
 		Width:  totalW,
 		Height: totalH,
 		Diagram: GanttData{
-			Sections:        sections,
-			Title:           graph.GanttTitle,
-			AxisTicks:       axisTicks,
-			TodayMarkerX:    todayX,
-			ShowTodayMarker: showToday,
-			ChartX:          chartX,
-			ChartY:          chartY,
-			ChartWidth:      chartW,
-			ChartHeight:     curY - chartY,
+			Sections:         sections,
+			Title:            graph.GanttTitle,
+			AxisTicks:        axisTicks,
+			TodayMarkerX:     todayX,
+			ShowTodayMarker:  showToday,
+			TodayMarkerStyle: todayStyle,
+			ChartX:           chartX,
+			ChartY:           chartY,
+			ChartWidth:       chartW,
+			ChartHeight:      curY - chartY,
+			Markers:          markers,
+			TopAxis:          graph.GanttTopAxis || cfg.Gantt.TopAxis,
 		},
 	}
 }
 
 // resolveGanttTaskDates computes start and end times for a single task.
-func resolveGanttTaskDates(task *ir.GanttTask, resolved map[string]resolvedTask, prevEnd *time.Time, goLayout string, excludes []string) (time.Time, time.Time) {
+// With inclusiveEnd, an explicit end date covers the whole end day.
+func resolveGanttTaskDates(task *ir.GanttTask, resolved map[string]resolvedTask, prevEnd *time.Time, dateFormat string, excludes []string, inclusiveEnd bool) (time.Time, time.Time) {
 	var start, end time.Time
 
 	// Check if already resolved by ID.
@@ -277,7 +357,7 @@ func resolveGanttTaskDates(task *ir.GanttTask, resolved map[string]resolvedTask,
 			}
 		}
 	} else if task.StartStr != "" && !strings.HasPrefix(strings.ToLower(task.StartStr), "after ") {
-		if parsed, err := time.Parse(goLayout, task.StartStr); err == nil {
+		if parsed, ok := parseGanttDate(task.StartStr, dateFormat); ok {
 			start = parsed
 		}
 	}
@@ -290,16 +370,18 @@ func resolveGanttTaskDates(task *ir.GanttTask, resolved map[string]resolvedTask,
 	}
 
 	// Resolve end.
+	// Whole days skip excluded days; any remainder is added as is. A zero
+	// duration, as milestones use, ends where it starts.
 	dur := parseMermaidDuration(task.EndStr)
-	if dur > 0 {
-		days := int(dur.Hours() / ganttHoursPerDay)
-		if days > 0 {
-			end = addWorkingDays(start, days, excludes, goLayout)
-		} else {
-			end = start.Add(dur)
-		}
-	} else if parsed, err := time.Parse(goLayout, task.EndStr); err == nil {
+	if dur > 0 || ganttDurationRe.MatchString(strings.TrimSpace(task.EndStr)) {
+		day := ganttHoursPerDay * time.Hour
+		days := int(dur / day)
+		end = addWorkingDays(start, days, excludes, dateFormat).Add(dur - time.Duration(days)*day)
+	} else if parsed, ok := parseGanttDate(task.EndStr, dateFormat); ok {
 		end = parsed
+		if inclusiveEnd {
+			end = end.Add(ganttHoursPerDay * time.Hour)
+		}
 	} else {
 		end = start.Add(ganttHoursPerDay * time.Hour)
 	}
@@ -308,13 +390,13 @@ func resolveGanttTaskDates(task *ir.GanttTask, resolved map[string]resolvedTask,
 }
 
 // ganttDateRange finds the global min and max dates across all tasks.
-func ganttDateRange(allTasks []*ir.GanttTask, resolved map[string]resolvedTask, goLayout string, excludes []string) (time.Time, time.Time) {
+func ganttDateRange(allTasks []*ir.GanttTask, resolved map[string]resolvedTask, dateFormat string, excludes []string, inclusiveEnd bool) (time.Time, time.Time) {
 	var minDate, maxDate time.Time
 	first := true
 	var prevEnd time.Time
 
 	for _, task := range allTasks {
-		start, end := resolveGanttTaskDates(task, resolved, &prevEnd, goLayout, excludes)
+		start, end := resolveGanttTaskDates(task, resolved, &prevEnd, dateFormat, excludes, inclusiveEnd)
 		if first || start.Before(minDate) {
 			minDate = start
 		}
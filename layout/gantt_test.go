@@ -2,6 +2,7 @@ package layout
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -130,3 +131,180 @@ func TestGanttLayoutExcludesWeekends(t *testing.T) {
 		t.Errorf("Width = %f", lay.Width)
 	}
 }
+
+func TestGanttLayoutVertMarker(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "YYYY-MM-DD"
+	graph.GanttSections = []*ir.GanttSection{
+		{Title: "A", Tasks: []*ir.GanttTask{
+			{ID: "t1", Label: "Design", StartStr: "2024-01-01", EndStr: "10d"},
+			{Label: "Freeze", Tags: []string{"vert"}, StartStr: "2024-01-06", EndStr: "0d"},
+		}},
+		{Title: "B", Tasks: []*ir.GanttTask{
+			{ID: "t2", Label: "Code", StartStr: "2024-01-01", EndStr: "10d"},
+		}},
+	}
+
+	gd := ComputeLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(GanttData)
+	if len(gd.Sections[0].Tasks) != 1 {
+		t.Fatalf("section A tasks = %d, want 1 (vert tasks take no row)", len(gd.Sections[0].Tasks))
+	}
+	if len(gd.Markers) != 1 || gd.Markers[0].Label != "Freeze" {
+		t.Fatalf("Markers = %+v", gd.Markers)
+	}
+	design := gd.Sections[0].Tasks[0]
+	if mid := design.X + design.Width/2; gd.Markers[0].X != mid {
+		t.Errorf("marker X = %f, want %f (halfway through Design)", gd.Markers[0].X, mid)
+	}
+}
+
+func TestGanttLayoutInclusiveEndDates(t *testing.T) {
+	widthOf := func(inclusive bool) float32 {
+		graph := ir.NewGraph()
+		graph.Kind = ir.Gantt
+		graph.GanttDateFormat = "YYYY-MM-DD"
+		graph.GanttInclusiveEndDates = inclusive
+		graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+			{Label: "Dated", StartStr: "2024-01-01", EndStr: "2024-01-02"},
+			{Label: "Long", StartStr: "2024-01-01", EndStr: "30d"},
+		}}}
+		gd := ComputeLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(GanttData)
+		return gd.Sections[0].Tasks[0].Width
+	}
+	exclusive, inclusive := widthOf(false), widthOf(true)
+	if inclusive <= exclusive*1.9 || inclusive >= exclusive*2.1 {
+		t.Errorf("inclusive width = %f, want twice the exclusive %f", inclusive, exclusive)
+	}
+}
+
+func TestGanttLayoutClicksAndTopAxis(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "YYYY-MM-DD"
+	graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+		{ID: "t1", Label: "Design", StartStr: "2024-01-01", EndStr: "3d"},
+	}}}
+	graph.NodeLinks["t1"] = &ir.NodeLink{URL: "https://example.com"}
+	graph.GanttCallbacks = map[string]*ir.GanttCallback{"t1": {Name: "show"}}
+
+	cfg := config.DefaultLayout()
+	gd := ComputeLayout(graph, theme.Modern(), cfg).Diagram.(GanttData)
+	task := gd.Sections[0].Tasks[0]
+	if task.Link == nil || task.Link.URL != "https://example.com" || task.Callback == nil {
+		t.Errorf("task Link = %+v, Callback = %+v", task.Link, task.Callback)
+	}
+	if gd.TopAxis {
+		t.Error("TopAxis = true without topAxis")
+	}
+
+	cfg.Gantt.TopAxis = true
+	if gd = ComputeLayout(graph, theme.Modern(), cfg).Diagram.(GanttData); !gd.TopAxis {
+		t.Error("TopAxis = false with config TopAxis")
+	}
+}
+
+func TestGanttLayoutTodayMarkerClock(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "YYYY-MM-DD"
+	graph.GanttAxisFormat = "%b %-d"
+	graph.GanttTickInterval = "1week"
+	graph.GanttTodayMarker = "stroke-width:5px,stroke:#0f0"
+	graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+		{Label: "Design", StartStr: "2024-01-01", EndStr: "20d"},
+	}}}
+
+	cfg := config.DefaultLayout()
+	cfg.Gantt.Now = func() time.Time { return time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC) }
+	gd := ComputeLayout(graph, theme.Modern(), cfg).Diagram.(GanttData)
+
+	task := gd.Sections[0].Tasks[0]
+	if !gd.ShowTodayMarker || gd.TodayMarkerX != task.X+task.Width/2 {
+		t.Errorf("today marker = %v at %f, want shown halfway through the task", gd.ShowTodayMarker, gd.TodayMarkerX)
+	}
+	if gd.TodayMarkerStyle != "stroke-width:5px,stroke:#0f0" {
+		t.Errorf("TodayMarkerStyle = %q", gd.TodayMarkerStyle)
+	}
+	// Weekly ticks fall on Sundays and use the axis format.
+	if len(gd.AxisTicks) == 0 || gd.AxisTicks[0].Label != "Jan 7" {
+		t.Errorf("AxisTicks = %+v, want the first on Jan 7", gd.AxisTicks)
+	}
+
+	cfg.Gantt.Now = func() time.Time { return time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	if gd = ComputeLayout(graph, theme.Modern(), cfg).Diagram.(GanttData); gd.ShowTodayMarker {
+		t.Error("today marker shown outside the chart's dates")
+	}
+}
+
+func TestParseGanttDate(t *testing.T) {
+	tests := []struct {
+		value, format string
+		want          time.Time
+	}{
+		{"2024-03-05", "YYYY-MM-DD", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"9:05", "HH:mm", time.Date(0, time.January, 1, 9, 5, 0, 0, time.UTC)},
+		{"2024-03-05 14:30:15.250", "YYYY-MM-DD HH:mm:ss.SSS", time.Date(2024, time.March, 5, 14, 30, 15, 250*int(time.Millisecond), time.UTC)},
+		{"5 Mar 2024", "D MMM YYYY", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"1700000000", "X", time.Unix(1700000000, 0).UTC()},
+		{"1700000000.5", "X", time.Unix(1700000000, int64(time.Second/2)).UTC()},
+		{"1700000000123", "x", time.UnixMilli(1700000000123).UTC()},
+	}
+	for _, tt := range tests {
+		got, ok := parseGanttDate(tt.value, tt.format)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parseGanttDate(%q, %q) = %v, %v, want %v", tt.value, tt.format, got, ok, tt.want)
+		}
+	}
+	if _, ok := parseGanttDate("noon", "HH:mm"); ok {
+		t.Error("parseGanttDate accepted text that does not fit the format")
+	}
+}
+
+func TestParseMermaidDuration(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Duration
+	}{
+		{"3d", 3 * ganttHoursPerDay * time.Hour},
+		{"2w", 2 * ganttDaysPerWeek * ganttHoursPerDay * time.Hour},
+		{"1.5h", 90 * time.Minute},
+		{"30m", 30 * time.Minute},
+		{"45s", 45 * time.Second},
+		{"250ms", 250 * time.Millisecond},
+		{"0.5D", 12 * time.Hour},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseMermaidDuration(tt.text); got != tt.want {
+			t.Errorf("parseMermaidDuration(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGanttLayoutMinutes(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "HH:mm"
+	graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+		{ID: "a", Label: "Alert", StartStr: "10:00", EndStr: "15m"},
+		{ID: "b", Label: "Mitigate", StartStr: "after a", AfterIDs: []string{"a"}, EndStr: "1.5h"},
+	}}}
+
+	gd := ComputeLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(GanttData)
+	alert, mitigate := gd.Sections[0].Tasks[0], gd.Sections[0].Tasks[1]
+	if ratio := mitigate.Width / alert.Width; ratio < 5.9 || ratio > 6.1 {
+		t.Errorf("Mitigate/Alert width ratio = %f, want 6 (90m / 15m)", ratio)
+	}
+	if mitigate.X+mitigate.Width > gd.ChartX+gd.ChartWidth+0.01 {
+		t.Errorf("Mitigate ends at %f, past the chart end %f", mitigate.X+mitigate.Width, gd.ChartX+gd.ChartWidth)
+	}
+	if len(gd.AxisTicks) < 3 || gd.AxisTicks[0].Label != "10:00" || gd.AxisTicks[1].Label != "10:15" {
+		t.Errorf("AxisTicks = %+v, want quarter-hour clock labels", gd.AxisTicks)
+	}
+	for idx := 1; idx < len(gd.AxisTicks); idx++ {
+		if gap := gd.AxisTicks[idx].X - gd.AxisTicks[idx-1].X; gap < ganttMinTickSpacing-0.01 {
+			t.Errorf("ticks %d and %d are %f apart, want at least %d", idx-1, idx, gap, ganttMinTickSpacing)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package layout
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Gantt axis time constants.
+const (
+	// ganttDefaultAxisFormat is the axisFormat Mermaid uses when the chart
+	// does not declare one.
+	ganttDefaultAxisFormat = "%Y-%m-%d"
+	// ganttTimeAxisFormat and ganttSecondsAxisFormat label sub-day charts
+	// that do not declare an axisFormat.
+	ganttTimeAxisFormat    = "%H:%M"
+	ganttSecondsAxisFormat = "%H:%M:%S"
+	// ganttSubDayTickTarget is the most automatic ticks a sub-day chart
+	// gets.
+	ganttSubDayTickTarget = 12
+	ganttMinutesPerDay    = 24 * 60
+	// ganttMaxTicks caps the ticks a tickInterval may produce; finer
+	// intervals fall back to automatic ticks.
+	ganttMaxTicks     = 200
+	ganttHoursPerHalf = 12
+	ganttMonthsPerQtr = 3
+)
+
+// ganttSubDaySteps are the automatic tick steps for charts shorter than a
+// day, finest first.
+//
+//nolint:gochecknoglobals // read-only lookup table.
+var ganttSubDaySteps = []ganttTickStep{
+	{1, "second"}, {5, "second"}, {15, "second"}, {30, "second"},
+	{1, "minute"}, {5, "minute"}, {15, "minute"}, {30, "minute"},
+	{1, "hour"}, {3, "hour"}, {6, "hour"}, {12, "hour"},
+}
+
+var ganttTickIntervalRe = regexp.MustCompile(`^([1-9]\d*)(millisecond|second|minute|hour|day|week|month)$`)
+
+// formatD3Time formats a time with a d3 time-format string such as
+// "%Y-%m-%d" or "%b %d". Directives are expanded one at a time, so the
+// literal text between them is never mistaken for a Go layout token. The
+// "-", "_" and "0" padding modifiers are honoured; unknown directives are
+// kept as written.
+func formatD3Time(tm time.Time, format string) string {
+	var out strings.Builder
+	for idx := 0; idx < len(format); idx++ {
+		if format[idx] != '%' || idx+1 == len(format) {
+			out.WriteByte(format[idx])
+			continue
+		}
+		idx++
+		var modifier byte
+		if mod := format[idx]; (mod == '-' || mod == '_' || mod == '0') && idx+1 < len(format) {
+			modifier = mod
+			idx++
+		}
+		out.WriteString(d3Directive(tm, format[idx], modifier))
+	}
+	return out.String()
+}
+
+// d3Directive expands a single d3 time-format directive.
+//
+//nolint:gocyclo,cyclop,funlen // one case per directive reads best as a flat switch.
+func d3Directive(tm time.Time, verb, modifier byte) string {
+	switch verb {
+	case 'a':
+		return tm.Format("Mon")
+	case 'A':
+		return tm.Format("Monday")
+	case 'b', 'h':
+		return tm.Format("Jan")
+	case 'B':
+		return tm.Format("January")
+	case 'c':
+		return formatD3Time(tm, "%x, %X")
+	case 'x':
+		return formatD3Time(tm, "%-m/%-d/%Y")
+	case 'X':
+		return formatD3Time(tm, "%-I:%M:%S %p")
+	case 'd':
+		return d3Pad(tm.Day(), 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'e':
+		return d3Pad(tm.Day(), 2, ' ', modifier) //nolint:mnd // two-digit field.
+	case 'f':
+		return d3Pad(tm.Nanosecond()/int(time.Microsecond), 6, '0', modifier) //nolint:mnd // microseconds.
+	case 'G':
+		year, _ := tm.ISOWeek()
+		return d3Pad(year, 4, '0', modifier) //nolint:mnd // four-digit year.
+	case 'H':
+		return d3Pad(tm.Hour(), 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'I':
+		hour := tm.Hour() % ganttHoursPerHalf
+		if hour == 0 {
+			hour = ganttHoursPerHalf
+		}
+		return d3Pad(hour, 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'j':
+		return d3Pad(tm.YearDay(), 3, '0', modifier) //nolint:mnd // three-digit field.
+	case 'L':
+		return d3Pad(tm.Nanosecond()/int(time.Millisecond), 3, '0', modifier) //nolint:mnd // milliseconds.
+	case 'm':
+		return d3Pad(int(tm.Month()), 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'M':
+		return d3Pad(tm.Minute(), 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'p':
+		return tm.Format("PM")
+	case 'q':
+		return strconv.Itoa((int(tm.Month())-1)/ganttMonthsPerQtr + 1)
+	case 'Q':
+		return strconv.FormatInt(tm.UnixMilli(), 10)
+	case 's':
+		return strconv.FormatInt(tm.Unix(), 10)
+	case 'S':
+		return d3Pad(tm.Second(), 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'u':
+		weekday := int(tm.Weekday())
+		if weekday == 0 {
+			weekday = ganttDaysPerWeek
+		}
+		return strconv.Itoa(weekday)
+	case 'U':
+		// Weeks start on Sunday; days before the first Sunday are week 0.
+		week := (tm.YearDay() - 1 + ganttDaysPerWeek - int(tm.Weekday())) / ganttDaysPerWeek
+		return d3Pad(week, 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'V':
+		_, week := tm.ISOWeek()
+		return d3Pad(week, 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'w':
+		return strconv.Itoa(int(tm.Weekday()))
+	case 'W':
+		// Weeks start on Monday; days before the first Monday are week 0.
+		mondayBased := (int(tm.Weekday()) + ganttDaysPerWeek - 1) % ganttDaysPerWeek
+		week := (tm.YearDay() - 1 + ganttDaysPerWeek - mondayBased) / ganttDaysPerWeek
+		return d3Pad(week, 2, '0', modifier) //nolint:mnd // two-digit field.
+	case 'y':
+		return d3Pad(tm.Year()%100, 2, '0', modifier) //nolint:mnd // two-digit year.
+	case 'Y':
+		return d3Pad(tm.Year(), 4, '0', modifier) //nolint:mnd // four-digit year.
+	case 'Z':
+		return tm.Format("-0700")
+	case '%':
+		return "%"
+	default:
+		return "%" + string(verb)
+	}
+}
+
+// d3Pad formats a number at the given width with the directive's default
+// fill, unless a modifier overrides it: "-" for none, "_" for spaces and
+// "0" for zeros.
+func d3Pad(value, width int, fill, modifier byte) string {
+	switch modifier {
+	case '-':
+		return strconv.Itoa(value)
+	case '_':
+		fill = ' '
+	case '0':
+		fill = '0'
+	}
+	text := strconv.Itoa(value)
+	if len(text) < width {
+		text = strings.Repeat(string(fill), width-len(text)) + text
+	}
+	return text
+}
+
+// ganttTickStep is a calendar-aware tick interval such as "1week" or
+// "2month". Months step by calendar month, and ticks fall on the start of
+// their unit: midnight, Sunday or the first of the month.
+type ganttTickStep struct {
+	count int
+	unit  string
+}
+
+// parseGanttTickInterval parses a tickInterval value.
+func parseGanttTickInterval(text string) (ganttTickStep, bool) {
+	match := ganttTickIntervalRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if match == nil {
+		return ganttTickStep{}, false
+	}
+	count, err := strconv.Atoi(match[1])
+	if err != nil {
+		return ganttTickStep{}, false
+	}
+	return ganttTickStep{count: count, unit: match[2]}, true
+}
+
+// floor returns the start of the unit containing tm. Clock units round
+// down to a multiple of the step, so five-minute ticks fall on :00, :05
+// and so on.
+func (s ganttTickStep) floor(tm time.Time) time.Time {
+	year, month, day := tm.Date()
+	switch s.unit {
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, tm.Location())
+	case "week":
+		return time.Date(year, month, day-int(tm.Weekday()), 0, 0, 0, 0, tm.Location())
+	case "day":
+		return time.Date(year, month, day, 0, 0, 0, 0, tm.Location())
+	case "hour":
+		return tm.Truncate(time.Duration(s.count) * time.Hour)
+	case "minute":
+		return tm.Truncate(time.Duration(s.count) * time.Minute)
+	case "second":
+		return tm.Truncate(time.Duration(s.count) * time.Second)
+	default:
+		return tm.Truncate(time.Duration(s.count) * time.Millisecond)
+	}
+}
+
+// next returns the tick after tm.
+func (s ganttTickStep) next(tm time.Time) time.Time {
+	switch s.unit {
+	case "month":
+		return tm.AddDate(0, s.count, 0)
+	case "week":
+		return tm.AddDate(0, 0, ganttDaysPerWeek*s.count)
+	case "day":
+		return tm.AddDate(0, 0, s.count)
+	case "hour":
+		return tm.Add(time.Duration(s.count) * time.Hour)
+	case "minute":
+		return tm.Add(time.Duration(s.count) * time.Minute)
+	case "second":
+		return tm.Add(time.Duration(s.count) * time.Second)
+	default:
+		return tm.Add(time.Duration(s.count) * time.Millisecond)
+	}
+}
+
+// ticks returns the tick times within [minDate, maxDate], or false when
+// there would be more than ganttMaxTicks of them.
+func (s ganttTickStep) ticks(minDate, maxDate time.Time) ([]time.Time, bool) {
+	var out []time.Time
+	tick := s.floor(minDate)
+	for tick.Before(minDate) {
+		tick = s.next(tick)
+	}
+	for ; !tick.After(maxDate); tick = s.next(tick) {
+		if len(out) == ganttMaxTicks {
+			return nil, false
+		}
+		out = append(out, tick)
+	}
+	return out, true
+}
+
+// ganttAxisTimes picks the axis tick times for a chart spanning totalDays.
+// A valid tickInterval is used as given; otherwise charts shorter than a
+// day get the finest clock step with at most ganttSubDayTickTarget ticks,
+// and longer ones are daily for short charts, weekly from the first day,
+// or on the first of each month for long charts.
+func ganttAxisTimes(minDate, maxDate time.Time, totalDays float64, tickInterval string) []time.Time {
+	if step, ok := parseGanttTickInterval(tickInterval); ok {
+		if ticks, ok := step.ticks(minDate, maxDate); ok {
+			return ticks
+		}
+	}
+	if totalDays < 1 {
+		for _, step := range ganttSubDaySteps {
+			if ticks, ok := step.ticks(minDate, maxDate); ok && len(ticks) <= ganttSubDayTickTarget {
+				return ticks
+			}
+		}
+	}
+	if totalDays > ganttMonthlyTickLimit {
+		ticks, _ := ganttTickStep{count: 1, unit: "month"}.ticks(minDate, maxDate)
+		return ticks
+	}
+	tickDays := ganttDaysPerWeek
+	if totalDays < ganttDailyTickLimit {
+		tickDays = 1
+	}
+	var ticks []time.Time
+	for dateVal := minDate; !dateVal.After(maxDate); dateVal = dateVal.AddDate(0, 0, tickDays) {
+		ticks = append(ticks, dateVal)
+	}
+	return ticks
+}
+
+// ganttAutoAxisFormat picks the axis label format for a chart without an
+// axisFormat: dates for charts of a day or more, and the time of day for
+// shorter ones, with seconds when they span only a few minutes.
+func ganttAutoAxisFormat(totalDays float64) string {
+	switch {
+	case totalDays >= 1:
+		return ganttDefaultAxisFormat
+	case totalDays*ganttMinutesPerDay < ganttSubDayTickTarget:
+		return ganttSecondsAxisFormat
+	default:
+		return ganttTimeAxisFormat
+	}
+}
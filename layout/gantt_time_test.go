@@ -0,0 +1,77 @@
+package layout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatD3Time(t *testing.T) {
+	tm := time.Date(2024, time.March, 5, 14, 7, 9, 250*int(time.Millisecond), time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d", "2024-03-05"},
+		{"%b %d", "Mar 05"},
+		{"%B %-d, %Y", "March 5, 2024"},
+		{"%e|%_m", " 5| 3"},
+		{"%a %A", "Tue Tuesday"},
+		{"%H:%M:%S.%L", "14:07:09.250"},
+		{"%I %p", "02 PM"},
+		{"%j", "065"},
+		{"%y Q%q", "24 Q1"},
+		{"week %U/%W/%V", "week 09/10/10"},
+		{"%x", "3/5/2024"},
+		{"100%%", "100%"},
+		// Literal text that happens to be a Go layout token is kept.
+		{"Jan 2 %k", "Jan 2 %k"},
+	}
+	for _, tt := range tests {
+		if got := formatD3Time(tm, tt.format); got != tt.want {
+			t.Errorf("formatD3Time(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestParseGanttTickInterval(t *testing.T) {
+	if step, ok := parseGanttTickInterval("2week"); !ok || step.count != 2 || step.unit != "week" {
+		t.Errorf("2week = %+v, %v", step, ok)
+	}
+	for _, bad := range []string{"", "week", "0day", "1year", "1 day"} {
+		if _, ok := parseGanttTickInterval(bad); ok {
+			t.Errorf("parseGanttTickInterval(%q) accepted", bad)
+		}
+	}
+}
+
+func TestGanttAxisTimes(t *testing.T) {
+	// Wednesday 2024-01-03 to Friday 2024-03-15.
+	minDate := time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC)
+	maxDate := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	days := maxDate.Sub(minDate).Hours() / ganttHoursPerDay
+
+	weekly := ganttAxisTimes(minDate, maxDate, days, "1week")
+	if len(weekly) == 0 || weekly[0] != time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("weekly ticks start at %v, want Sunday 2024-01-07", weekly)
+	}
+	for _, tick := range weekly {
+		if tick.Weekday() != time.Sunday {
+			t.Errorf("weekly tick %v is not a Sunday", tick)
+		}
+	}
+
+	monthly := ganttAxisTimes(minDate, maxDate, days, "1month")
+	want := []time.Time{
+		time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if len(monthly) != len(want) || monthly[0] != want[0] || monthly[1] != want[1] {
+		t.Errorf("monthly ticks = %v, want %v", monthly, want)
+	}
+
+	// Too fine an interval falls back to the automatic weekly ticks.
+	auto := ganttAxisTimes(minDate, maxDate, days, "1minute")
+	if len(auto) == 0 || auto[0] != minDate || auto[1].Sub(auto[0]) != ganttDaysPerWeek*ganttHoursPerDay*time.Hour {
+		t.Errorf("fallback ticks = %v, want weekly from the first day", auto)
+	}
+}
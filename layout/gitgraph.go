@@ -6,21 +6,24 @@ import (
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
 type gitBranchInfo struct {
-	name  string
-	order int
-	head  string // latest commit ID on this branch
+	name    string
+	order   int
+	created int    // creation sequence, breaking ties between equal orders
+	head    string // latest commit ID on this branch
 }
 
 type gitCommitInfo struct {
-	id     string
-	tag    string
-	ctype  ir.GitCommitType
-	branch string
-	seq    int // sequential order
+	id      string
+	tag     string
+	ctype   ir.GitCommitType
+	branch  string
+	seq     int   // sequential order
+	parents []int // indices of parent commits
 }
 
 type gitPendingConnection struct {
@@ -29,6 +32,15 @@ type gitPendingConnection struct {
 	isCherryPick bool
 }
 
+// GitGraph layout constants. The tag sizes match the tag boxes drawn by
+// the renderer, which sit beside commits on vertical lanes.
+const (
+	gitLabelFontDelta float32 = 2
+	gitLabelGap       float32 = 10
+	gitTagWidth       float32 = 40
+	gitTagGap         float32 = 4
+)
+
 func computeGitGraphLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	padX := cfg.GitGraph.PaddingX
 	padY := cfg.GitGraph.PaddingY
@@ -46,9 +58,50 @@ func computeGitGraphLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 	// Sort branches by order for lane assignment.
 	sortedBranches := gitGraphSortBranches(branches)
 
-	branchY := make(map[string]float32, len(sortedBranches))
-	for idx, bl := range sortedBranches {
-		branchY[bl.name] = padY + float32(idx)*branchSpacing
+	positions, slots := gitGraphPositions(commits, graph.GitParallelCommits || cfg.GitGraph.ParallelCommits)
+
+	direction := graph.GitDirection
+	vertical := direction == ir.TopDown || direction == ir.BottomTop
+	if !vertical {
+		direction = ir.LeftRight
+	}
+
+	// lane maps a branch to its cross-axis coordinate; along maps a commit
+	// position to its coordinate in the direction history runs.
+	lane := make(map[string]float32, len(sortedBranches))
+	var along func(pos int) float32
+	var totalW, totalH float32
+	if vertical {
+		// Tags sit left of their commit, so lanes make room for one
+		// between neighbouring commits.
+		laneStart := padX
+		for _, ci := range commits {
+			if ci.tag != "" {
+				tagRoom := cfg.GitGraph.CommitRadius*2 + gitTagGap*2 + gitTagWidth //nolint:mnd // commits on both sides.
+				laneStart = max(padX, tagRoom)
+				branchSpacing = max(branchSpacing, tagRoom)
+				break
+			}
+		}
+		for idx, bl := range sortedBranches {
+			lane[bl.name] = laneStart + float32(idx)*branchSpacing
+		}
+		labelRoom := gitGraphLabelRoom(sortedBranches, th)
+		along = func(pos int) float32 {
+			if direction == ir.BottomTop {
+				return padY + float32(slots-1-pos)*commitSpacing
+			}
+			return padY + labelRoom + float32(pos)*commitSpacing
+		}
+		totalW = laneStart + padX + float32(len(sortedBranches))*branchSpacing
+		totalH = padY*2 + labelRoom + float32(slots)*commitSpacing
+	} else {
+		for idx, bl := range sortedBranches {
+			lane[bl.name] = padY + float32(idx)*branchSpacing
+		}
+		along = func(pos int) float32 { return padX + float32(pos)*commitSpacing }
+		totalW = padX*2 + float32(slots)*commitSpacing
+		totalH = padY*2 + float32(len(sortedBranches))*branchSpacing
 	}
 
 	// Position commits.
@@ -59,8 +112,11 @@ func computeGitGraphLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 			Tag:    ci.tag,
 			Type:   ci.ctype,
 			Branch: ci.branch,
-			X:      padX + float32(ci.seq)*commitSpacing,
-			Y:      branchY[ci.branch],
+			X:      along(positions[idx]),
+			Y:      lane[ci.branch],
+		}
+		if vertical {
+			commitLayouts[idx].X, commitLayouts[idx].Y = lane[ci.branch], along(positions[idx])
 		}
 	}
 
@@ -79,10 +135,7 @@ func computeGitGraphLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 	}
 
 	// Build branch layouts.
-	branchLayouts := gitGraphBuildBranchLayouts(sortedBranches, commitLayouts, branchY, th)
-
-	totalW := padX*2 + float32(len(commits))*commitSpacing
-	totalH := padY*2 + float32(len(sortedBranches))*branchSpacing
+	branchLayouts := gitGraphBuildBranchLayouts(sortedBranches, commitLayouts, lane, vertical, th)
 
 	return &Layout{
 		Kind:   graph.Kind,
@@ -93,10 +146,42 @@ func computeGitGraphLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 			Commits:     commitLayouts,
 			Branches:    branchLayouts,
 			Connections: connLayouts,
+			Direction:   direction,
 		},
 	}
 }
 
+// gitGraphPositions returns each commit's slot along the history axis and
+// the number of slots. Commits normally take one slot each in statement
+// order; with parallel commits a commit sits one slot after its latest
+// parent, so commits the same distance from a branch point line up.
+func gitGraphPositions(commits []gitCommitInfo, parallel bool) ([]int, int) {
+	positions := make([]int, len(commits))
+	slots := 0
+	for idx, ci := range commits {
+		positions[idx] = ci.seq
+		if parallel {
+			positions[idx] = 0
+			for _, parent := range ci.parents {
+				positions[idx] = max(positions[idx], positions[parent]+1)
+			}
+		}
+		slots = max(slots, positions[idx]+1)
+	}
+	return positions, slots
+}
+
+// gitGraphLabelRoom returns the space needed above vertical lanes for the
+// rotated branch labels.
+func gitGraphLabelRoom(lanes []gitBranchLane, th *theme.Theme) float32 {
+	measurer := textmetrics.New()
+	var widest float32
+	for _, bl := range lanes {
+		widest = max(widest, measurer.Width(bl.name, th.FontSize-gitLabelFontDelta, th.FontFamily))
+	}
+	return widest + gitLabelGap
+}
+
 // gitGraphSimulate processes git actions and builds the commit graph, connections,
 // and branch map.
 func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gitPendingConnection, map[string]*gitBranchInfo) {
@@ -110,6 +195,16 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 	var connections []gitPendingConnection
 	autoID := 0
 
+	// headIndex returns the index of a branch's latest commit, if any.
+	headIndex := func(name string) []int {
+		if branch, ok := branches[name]; ok {
+			if idx, ok := commitMap[branch.head]; ok {
+				return []int{idx}
+			}
+		}
+		return nil
+	}
+
 	for _, action := range graph.GitActions {
 		switch gitAction := action.(type) {
 		case *ir.GitCommit:
@@ -119,11 +214,12 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 				autoID++
 			}
 			ci := gitCommitInfo{
-				id:     id,
-				tag:    gitAction.Tag,
-				ctype:  gitAction.Type,
-				branch: currentBranch,
-				seq:    len(commits),
+				id:      id,
+				tag:     gitAction.Tag,
+				ctype:   gitAction.Type,
+				branch:  currentBranch,
+				seq:     len(commits),
+				parents: headIndex(currentBranch),
 			}
 			commitMap[id] = len(commits)
 			commits = append(commits, ci)
@@ -135,9 +231,10 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 				order = len(branches)
 			}
 			branches[gitAction.Name] = &gitBranchInfo{
-				name:  gitAction.Name,
-				order: order,
-				head:  branches[currentBranch].head,
+				name:    gitAction.Name,
+				order:   order,
+				created: len(branches),
+				head:    branches[currentBranch].head,
 			}
 			currentBranch = gitAction.Name
 
@@ -151,11 +248,12 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 				autoID++
 			}
 			ci := gitCommitInfo{
-				id:     id,
-				tag:    gitAction.Tag,
-				ctype:  gitAction.Type,
-				branch: currentBranch,
-				seq:    len(commits),
+				id:      id,
+				tag:     gitAction.Tag,
+				ctype:   gitAction.Type,
+				branch:  currentBranch,
+				seq:     len(commits),
+				parents: append(headIndex(currentBranch), headIndex(gitAction.Branch)...),
 			}
 			commitMap[id] = len(commits)
 			commits = append(commits, ci)
@@ -175,11 +273,12 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 			id := fmt.Sprintf("cp_%d", autoID)
 			autoID++
 			ci := gitCommitInfo{
-				id:     id,
-				tag:    gitAction.ID, // show source as tag
-				ctype:  ir.GitCommitNormal,
-				branch: currentBranch,
-				seq:    len(commits),
+				id:      id,
+				tag:     gitAction.ID, // show source as tag
+				ctype:   ir.GitCommitNormal,
+				branch:  currentBranch,
+				seq:     len(commits),
+				parents: headIndex(currentBranch),
 			}
 			commitMap[id] = len(commits)
 			commits = append(commits, ci)
@@ -199,52 +298,61 @@ func gitGraphSimulate(graph *ir.Graph, mainBranch string) ([]gitCommitInfo, []gi
 }
 
 type gitBranchLane struct {
-	name  string
-	order int
+	name    string
+	order   int
+	created int
 }
 
-// gitGraphSortBranches collects and sorts branches by order.
+// gitGraphSortBranches collects and sorts branches by order, then by
+// creation so branches with equal orders keep a stable lane.
 func gitGraphSortBranches(branches map[string]*gitBranchInfo) []gitBranchLane {
 	sorted := make([]gitBranchLane, 0, len(branches))
 	for name, bi := range branches {
-		sorted = append(sorted, gitBranchLane{name, bi.order})
+		sorted = append(sorted, gitBranchLane{name, bi.order, bi.created})
 	}
 	sort.Slice(sorted, func(idxA, idxB int) bool {
-		return sorted[idxA].order < sorted[idxB].order
+		if sorted[idxA].order != sorted[idxB].order {
+			return sorted[idxA].order < sorted[idxB].order
+		}
+		return sorted[idxA].created < sorted[idxB].created
 	})
 	return sorted
 }
 
-// gitGraphBuildBranchLayouts builds the branch layout objects with colors and
-// start/end X positions.
-func gitGraphBuildBranchLayouts(sortedBranches []gitBranchLane, commitLayouts []GitGraphCommitLayout, branchY map[string]float32, th *theme.Theme) []GitGraphBranchLayout {
+// gitGraphBuildBranchLayouts builds the branch layout objects with colors
+// and the extent of each lane along the history axis.
+func gitGraphBuildBranchLayouts(sortedBranches []gitBranchLane, commitLayouts []GitGraphCommitLayout, lane map[string]float32, vertical bool, th *theme.Theme) []GitGraphBranchLayout {
 	branchLayouts := make([]GitGraphBranchLayout, 0, len(sortedBranches))
 	for idx, bl := range sortedBranches {
 		color := "#4A90D9" // fallback
 		if len(th.GitBranchColors) > 0 {
 			color = th.GitBranchColors[idx%len(th.GitBranchColors)]
 		}
-		// Find start and end X for this branch.
-		var startX, endX float32
+		// Find the first and last commit coordinates on this branch.
+		var start, end float32
 		first := true
 		for _, cl := range commitLayouts {
 			if cl.Branch == bl.name {
-				if first || cl.X < startX {
-					startX = cl.X
+				pos := cl.X
+				if vertical {
+					pos = cl.Y
+				}
+				if first || pos < start {
+					start = pos
 				}
-				if first || cl.X > endX {
-					endX = cl.X
+				if first || pos > end {
+					end = pos
 				}
 				first = false
 			}
 		}
-		branchLayouts = append(branchLayouts, GitGraphBranchLayout{
-			Name:   bl.name,
-			Y:      branchY[bl.name],
-			Color:  color,
-			StartX: startX,
-			EndX:   endX,
-		})
+		branch := GitGraphBranchLayout{Name: bl.name, Color: color}
+		if vertical {
+			branch.X, branch.StartY, branch.EndY = lane[bl.name], start, end
+		} else {
+			branch.Y, branch.StartX, branch.EndX = lane[bl.name], start, end
+		}
+		branchLayouts = append(branchLayouts, branch)
 	}
 	return branchLayouts
 }
@@ -107,3 +107,110 @@ func TestGitGraphLayoutCommitOrder(t *testing.T) {
 			ggd.Commits[0].X, ggd.Commits[1].X, ggd.Commits[2].X)
 	}
 }
+
+// parallelGraph returns a graph whose develop and main branches each
+// gain one commit after branching.
+func parallelGraph() *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.GitGraph
+	graph.GitMainBranch = "main"
+	graph.GitActions = []ir.GitAction{
+		&ir.GitCommit{ID: "c1"},
+		&ir.GitBranch{Name: "develop"},
+		&ir.GitCommit{ID: "d1"},
+		&ir.GitCheckout{Branch: "main"},
+		&ir.GitCommit{ID: "m1"},
+		&ir.GitMerge{Branch: "develop", ID: "merge"},
+	}
+	return graph
+}
+
+// commitByID returns the layout of the commit with the given ID.
+func commitByID(t *testing.T, data GitGraphData, id string) GitGraphCommitLayout {
+	t.Helper()
+	for _, commit := range data.Commits {
+		if commit.ID == id {
+			return commit
+		}
+	}
+	t.Fatalf("commit %q not found", id)
+	return GitGraphCommitLayout{}
+}
+
+func TestGitGraphLayoutParallelCommits(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+
+	sequential := ComputeLayout(parallelGraph(), th, cfg).Diagram.(GitGraphData)
+	if commitByID(t, sequential, "d1").X == commitByID(t, sequential, "m1").X {
+		t.Error("without parallelCommits, d1 and m1 should take separate columns")
+	}
+
+	graph := parallelGraph()
+	graph.GitParallelCommits = true
+	parallel := ComputeLayout(graph, th, cfg).Diagram.(GitGraphData)
+	if commitByID(t, parallel, "d1").X != commitByID(t, parallel, "m1").X {
+		t.Error("with parallelCommits, d1 and m1 should share a column")
+	}
+	if commitByID(t, parallel, "merge").X <= commitByID(t, parallel, "m1").X {
+		t.Error("merge commit should follow both parents")
+	}
+
+	cfg.GitGraph.ParallelCommits = true
+	fromConfig := ComputeLayout(parallelGraph(), th, cfg).Diagram.(GitGraphData)
+	if commitByID(t, fromConfig, "d1").X != commitByID(t, fromConfig, "m1").X {
+		t.Error("config ParallelCommits should align d1 and m1")
+	}
+}
+
+func TestGitGraphLayoutVertical(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+
+	graph := parallelGraph()
+	graph.GitDirection = ir.TopDown
+	lay := ComputeLayout(graph, th, cfg)
+	down := lay.Diagram.(GitGraphData)
+	if down.Direction != ir.TopDown {
+		t.Errorf("Direction = %v, want TopDown", down.Direction)
+	}
+	first, last := commitByID(t, down, "c1"), commitByID(t, down, "merge")
+	if first.X != last.X || first.Y >= last.Y {
+		t.Errorf("TB main commits = (%v,%v) then (%v,%v), want one lane running down",
+			first.X, first.Y, last.X, last.Y)
+	}
+	if dev := commitByID(t, down, "d1"); dev.X <= first.X {
+		t.Errorf("develop lane X = %v, want right of main at %v", dev.X, first.X)
+	}
+	for _, br := range down.Branches {
+		if br.Name == "main" && (br.X != first.X || br.StartY != first.Y || br.EndY != last.Y) {
+			t.Errorf("main lane = %+v, want X %v from %v to %v", br, first.X, first.Y, last.Y)
+		}
+	}
+	if lay.Height <= lay.Width {
+		t.Errorf("TB size = %vx%v, want a portrait layout", lay.Width, lay.Height)
+	}
+
+	graph = parallelGraph()
+	graph.GitDirection = ir.BottomTop
+	up := ComputeLayout(graph, th, cfg).Diagram.(GitGraphData)
+	if commitByID(t, up, "c1").Y <= commitByID(t, up, "merge").Y {
+		t.Error("BT history should run upward")
+	}
+}
+
+func TestGitGraphSortBranchesTies(t *testing.T) {
+	branches := map[string]*gitBranchInfo{
+		"main":    {name: "main", order: 0, created: 0},
+		"develop": {name: "develop", order: 0, created: 1},
+		"feature": {name: "feature", order: 0, created: 2},
+		"hotfix":  {name: "hotfix", order: -1, created: 3},
+	}
+	for range 20 {
+		sorted := gitGraphSortBranches(branches)
+		got := []string{sorted[0].name, sorted[1].name, sorted[2].name, sorted[3].name}
+		if got[0] != "hotfix" || got[1] != "main" || got[2] != "develop" || got[3] != "feature" {
+			t.Fatalf("lanes = %v, want [hotfix main develop feature]", got)
+		}
+	}
+}
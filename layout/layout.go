@@ -12,6 +12,7 @@ import (
 func ComputeLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	lay := computeLayout(graph, th, cfg)
 	lay.Title = diagramTitle(graph)
+	lay.NodeLinks = graph.NodeLinks
 	return lay
 }
 
@@ -198,3 +198,29 @@ func TestComputeLayoutEmptyGraph(t *testing.T) {
 		t.Errorf("Edges = %d, want 0", len(lay.Edges))
 	}
 }
+
+func TestComputeLayoutIconNode(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Flowchart
+	graph.Direction = ir.LeftRight
+	label := "User"
+	graph.EnsureNode("U", &label, nil)
+	graph.Nodes["U"].Icon = &ir.NodeIcon{Name: "fa:user", Form: "square", Size: 40}
+	graph.EnsureNode("A", nil, nil)
+	graph.Edges = []*ir.Edge{edge("U", "A")}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, th, cfg)
+
+	node := lay.Nodes["U"]
+	if node.Icon == nil || node.IconSize != 40 || node.IconBox != 40+2*iconFormPadding {
+		t.Fatalf("icon = %+v, size %v, box %v; want a 40px icon in its form", node.Icon, node.IconSize, node.IconBox)
+	}
+	if want := node.IconBox + iconLabelGap + node.Label.Height; node.Height != want {
+		t.Errorf("Height = %v, want icon box plus label (%v)", node.Height, want)
+	}
+	if node.Width != max(node.IconBox, node.Label.Width) {
+		t.Errorf("Width = %v, want the wider of icon box and label", node.Width)
+	}
+}
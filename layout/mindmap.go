@@ -2,6 +2,7 @@ package layout
 
 import (
 	"math"
+	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -10,19 +11,20 @@ import (
 )
 
 // mindmapLayoutNode is an internal type that wraps MindmapNodeLayout with
-// tree-traversal fields used during the radial layout computation.
+// tree-traversal fields used during the layout computation.
 type mindmapLayoutNode struct {
 	MindmapNodeLayout
 	children    []*mindmapLayoutNode
 	subtreeSpan float32
+	offsetY     float32 // tree layout: centre relative to the parent's centre
 }
 
-// computeMindmapLayout builds a radial tree layout for a mindmap diagram.
-// The root node is placed at the center and children are distributed radially
-// in concentric rings at increasing distances.
 // mindmapEmptySize is the default width/height for an empty mindmap layout.
 const mindmapEmptySize float32 = 100
 
+// computeMindmapLayout lays out a mindmap with the root at the centre,
+// either radially, with branches in concentric rings at increasing
+// distances, or as a tree growing to both sides of the root.
 func computeMindmapLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	if graph.MindmapRoot == nil {
 		return &Layout{
@@ -38,16 +40,45 @@ func computeMindmapLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 	padX := cfg.Mindmap.PaddingX
 	padY := cfg.Mindmap.PaddingY
 	nodePad := cfg.Mindmap.NodePadding
-	levelSpacing := cfg.Mindmap.LevelSpacing
-	branchSpacing := cfg.Mindmap.BranchSpacing
 
 	// Phase 1: Build layout tree with measured node sizes.
-	root := mindmapBuildLayoutTree(graph.MindmapRoot, measurer, th, cfg, nodePad, 0, 0)
+	root := mindmapBuildLayoutTree(graph, graph.MindmapRoot, measurer, th, cfg, nodePad, 0, 0)
 
-	// Phase 2: Compute subtree angular spans bottom-up.
-	mindmapComputeSubtreeSize(root, branchSpacing)
+	// Phase 2: Position nodes around the root at the origin.
+	mode := cfg.Mindmap.Layout
+	if graph.MindmapLayout != "" {
+		mode = config.MindmapLayoutMode(graph.MindmapLayout)
+	}
+	if mode == config.MindmapLayoutTree {
+		mindmapTreeLayout(root, cfg.Mindmap)
+	} else {
+		mindmapRadialLayout(root, cfg.Mindmap)
+	}
+
+	// Phase 3: Normalize to positive coordinates.
+	minX, minY, maxX, maxY := mindmapBounds(root)
+	shiftX := padX - minX
+	shiftY := padY - minY
+	mindmapShift(root, shiftX, shiftY)
+
+	totalW := (maxX - minX) + padX*2
+	totalH := (maxY - minY) + padY*2
+
+	return &Layout{
+		Kind:    graph.Kind,
+		Nodes:   map[string]*NodeLayout{},
+		Width:   totalW,
+		Height:  totalH,
+		Diagram: MindmapData{Root: &root.MindmapNodeLayout},
+	}
+}
+
+// mindmapRadialLayout places the root's children around it by angle, each
+// getting a share of the circle proportional to its subtree.
+func mindmapRadialLayout(root *mindmapLayoutNode, mcfg config.MindmapConfig) {
+	levelSpacing := mcfg.LevelSpacing
+	mindmapComputeSubtreeSize(root, mcfg.BranchSpacing)
 
-	// Phase 3: Position nodes radially from center.
 	root.X = 0
 	root.Y = 0
 	if len(root.children) > 0 {
@@ -66,23 +97,6 @@ func computeMindmapLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 			startAngle += fraction * 2 * math.Pi
 		}
 	}
-
-	// Phase 4: Normalize to positive coordinates.
-	minX, minY, maxX, maxY := mindmapBounds(root)
-	shiftX := padX - minX
-	shiftY := padY - minY
-	mindmapShift(root, shiftX, shiftY)
-
-	totalW := (maxX - minX) + padX*2
-	totalH := (maxY - minY) + padY*2
-
-	return &Layout{
-		Kind:    graph.Kind,
-		Nodes:   map[string]*NodeLayout{},
-		Width:   totalW,
-		Height:  totalH,
-		Diagram: MindmapData{Root: &root.MindmapNodeLayout},
-	}
 }
 
 // mindmapBuildLayoutTree recursively constructs the internal layout tree from
@@ -90,6 +104,7 @@ func computeMindmapLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 // both in the internal tree (for traversal) and in the exported
 // MindmapNodeLayout.Children slice (for the renderer).
 func mindmapBuildLayoutTree(
+	graph *ir.Graph,
 	node *ir.MindmapNode,
 	measurer *textmetrics.Measurer,
 	th *theme.Theme,
@@ -107,14 +122,21 @@ func mindmapBuildLayoutTree(
 	ln.Icon = node.Icon
 	ln.Width = textW + nodePad*2
 	ln.Height = textH + nodePad*2
+	if _, ok := cfg.Icons.Lookup(node.Icon); ok && node.Icon != "" {
+		// Stack the icon, as tall as a line of text, above the label.
+		ln.IconSize = textH
+		ln.Width = max(textW, textH) + nodePad*2
+		ln.Height += textH
+	}
 	ln.ColorIndex = branchIdx
+	ln.Style = resolveClassStyle(graph, strings.Fields(node.Class))
 
 	for i, child := range node.Children {
 		childBranch := branchIdx
 		if depth == 0 {
 			childBranch = i
 		}
-		childNode := mindmapBuildLayoutTree(child, measurer, th, cfg, nodePad, depth+1, childBranch)
+		childNode := mindmapBuildLayoutTree(graph, child, measurer, th, cfg, nodePad, depth+1, childBranch)
 		ln.children = append(ln.children, childNode)
 		ln.Children = append(ln.Children, &childNode.MindmapNodeLayout)
 	}
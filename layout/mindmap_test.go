@@ -1,9 +1,11 @@
 package layout
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/icons"
 	"github.com/jamesainslie/gomd2svg/ir"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
@@ -219,3 +221,155 @@ func TestMindmapLayoutPositiveCoordinates(t *testing.T) {
 	}
 	checkPositive(md.Root, "root")
 }
+
+func TestMindmapLayoutIconSize(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Mindmap
+	graph.MindmapRoot = &ir.MindmapNode{
+		ID: "root", Label: "Root",
+		Children: []*ir.MindmapNode{
+			{ID: "a", Label: "Known", Icon: "test:dot"},
+			{ID: "b", Label: "Known", Icon: "test:missing"},
+		},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	cfg.Icons = icons.NewRegistry()
+	if err := cfg.Icons.Load([]byte(`{"prefix": "test", "icons": {"dot": {"body": "<circle r=\"8\"/>"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	md, ok := ComputeLayout(graph, th, cfg).Diagram.(MindmapData)
+	if !ok {
+		t.Fatal("Diagram is not MindmapData")
+	}
+
+	known, missing := md.Root.Children[0], md.Root.Children[1]
+	if known.IconSize <= 0 || known.Height != missing.Height+known.IconSize {
+		t.Errorf("resolved icon size %v, height %v; want the icon stacked on a %v node", known.IconSize, known.Height, missing.Height)
+	}
+	if missing.IconSize != 0 {
+		t.Errorf("unresolved icon size = %v, want 0", missing.IconSize)
+	}
+}
+
+// deepMindmap returns a mindmap with branches of uneven depth and
+// breadth, which the radial layout cannot place without overlaps.
+func deepMindmap() *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Mindmap
+	count := 0
+	var grow func(depth, fanout int) *ir.MindmapNode
+	grow = func(depth, fanout int) *ir.MindmapNode {
+		count++
+		node := &ir.MindmapNode{ID: fmt.Sprintf("n%d", count), Label: fmt.Sprintf("Topic %d", count)}
+		if depth > 0 {
+			for range fanout {
+				node.Children = append(node.Children, grow(depth-1, fanout))
+			}
+		}
+		return node
+	}
+	root := &ir.MindmapNode{ID: "root", Label: "Root", Shape: ir.MindmapCircle}
+	for idx := range 5 {
+		root.Children = append(root.Children, grow(idx%3+1, 3-idx%2))
+	}
+	graph.MindmapRoot = root
+	return graph
+}
+
+func flattenMindmap(node *MindmapNodeLayout, depth int, visit func(*MindmapNodeLayout, int)) {
+	visit(node, depth)
+	for _, child := range node.Children {
+		flattenMindmap(child, depth+1, visit)
+	}
+}
+
+func TestMindmapTreeLayout(t *testing.T) {
+	graph := deepMindmap()
+	cfg := config.DefaultLayout()
+	cfg.Mindmap.Layout = config.MindmapLayoutTree
+	md, ok := computeMindmapLayout(graph, theme.Modern(), cfg).Diagram.(MindmapData)
+	if !ok {
+		t.Fatal("Diagram is not MindmapData")
+	}
+	root := md.Root
+
+	var nodes []*MindmapNodeLayout
+	flattenMindmap(root, 0, func(node *MindmapNodeLayout, _ int) { nodes = append(nodes, node) })
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			first, second := nodes[i], nodes[j]
+			if abs32(first.X-second.X) < (first.Width+second.Width)/2 && abs32(first.Y-second.Y) < (first.Height+second.Height)/2 {
+				t.Errorf("%q and %q overlap", first.Label, second.Label)
+			}
+		}
+	}
+
+	// Branches grow away from the root on both sides, each deeper level
+	// further out than its parent.
+	var right, left int
+	for _, branch := range root.Children {
+		side := float32(1)
+		if branch.X < root.X {
+			side = -1
+			left++
+		} else {
+			right++
+		}
+		flattenMindmap(branch, 1, func(node *MindmapNodeLayout, _ int) {
+			for _, child := range node.Children {
+				if (child.X-node.X)*side <= 0 {
+					t.Errorf("%q does not grow outward from %q", child.Label, node.Label)
+				}
+			}
+		})
+	}
+	if right == 0 || left == 0 {
+		t.Errorf("branches right = %d, left = %d; want both sides used", right, left)
+	}
+}
+
+func TestMindmapTreeLayoutCentresParents(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Mindmap
+	graph.MindmapRoot = &ir.MindmapNode{ID: "root", Label: "Root", Children: []*ir.MindmapNode{
+		{ID: "a", Label: "A", Children: []*ir.MindmapNode{{ID: "a1", Label: "A1"}, {ID: "a2", Label: "A2"}, {ID: "a3", Label: "A3"}}},
+	}}
+	graph.MindmapLayout = "tree"
+	md, ok := computeMindmapLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(MindmapData)
+	if !ok {
+		t.Fatal("Diagram is not MindmapData")
+	}
+	branch := md.Root.Children[0]
+	first, last := branch.Children[0], branch.Children[2]
+	if abs32(branch.Y-(first.Y+last.Y)/2) > 0.01 || branch.Y != md.Root.Y {
+		t.Errorf("branch.Y = %v, want centred on its children (%v..%v) and the root %v", branch.Y, first.Y, last.Y, md.Root.Y)
+	}
+	if branch.Children[1].X != first.X {
+		t.Errorf("siblings should share a column")
+	}
+}
+
+func TestMindmapLayoutClassStyles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Mindmap
+	fill, stroke := "#fdd", "#c00"
+	graph.ClassDefs["urgent"] = &ir.NodeStyle{Fill: &fill}
+	graph.ClassDefs["outlined"] = &ir.NodeStyle{Stroke: &stroke}
+	graph.MindmapRoot = &ir.MindmapNode{ID: "root", Label: "Root", Children: []*ir.MindmapNode{
+		{ID: "a", Label: "A", Class: "urgent outlined"},
+		{ID: "b", Label: "B", Class: "unknown"},
+	}}
+	md, ok := computeMindmapLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(MindmapData)
+	if !ok {
+		t.Fatal("Diagram is not MindmapData")
+	}
+	styled, plain := md.Root.Children[0].Style, md.Root.Children[1].Style
+	if styled.Fill == nil || *styled.Fill != fill || styled.Stroke == nil || *styled.Stroke != stroke {
+		t.Errorf("styled node = %+v, want both classes merged", styled)
+	}
+	if plain.Fill != nil || plain.Stroke != nil {
+		t.Errorf("unknown class resolved to %+v", plain)
+	}
+}
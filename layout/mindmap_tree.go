@@ -0,0 +1,140 @@
+package layout
+
+import "github.com/jamesainslie/gomd2svg/config"
+
+// mindmapContour is the vertical extent of a subtree at each depth, the
+// subtree's root being depth 0, relative to the root's centre.
+type mindmapContour struct {
+	top, bottom []float32
+}
+
+// merge widens the contour to cover other moved down by offset.
+func (c mindmapContour) merge(other mindmapContour, offset float32) mindmapContour {
+	for depth := range other.top {
+		top, bottom := other.top[depth]+offset, other.bottom[depth]+offset
+		if depth < len(c.top) {
+			c.top[depth] = min(c.top[depth], top)
+			c.bottom[depth] = max(c.bottom[depth], bottom)
+			continue
+		}
+		c.top = append(c.top, top)
+		c.bottom = append(c.bottom, bottom)
+	}
+	return c
+}
+
+// mindmapTreeLayout grows the root's branches to its right and left, with
+// each depth in its own column. The branches are shared between the sides
+// so that both are about as tall, and subtrees are stacked as closely as
+// their contours allow, in the manner of Reingold and Tilford.
+func mindmapTreeLayout(root *mindmapLayoutNode, mcfg config.MindmapConfig) {
+	root.X = 0
+	root.Y = 0
+	right, left := mindmapSplitSides(root.children, mcfg.BranchSpacing)
+	for _, side := range []struct {
+		children  []*mindmapLayoutNode
+		direction float32
+	}{{right, 1}, {left, -1}} {
+		if len(side.children) == 0 {
+			continue
+		}
+		mindmapStackSubtrees(side.children, mcfg.SiblingSpacing)
+
+		// Each column starts a level's spacing past the previous one and
+		// is as wide as its widest node.
+		widths := mindmapColumnWidths(side.children, 0, nil)
+		starts := make([]float32, len(widths))
+		edge := root.Width/2 + mcfg.LevelSpacing
+		for depth, width := range widths {
+			starts[depth] = edge
+			edge += width + mcfg.LevelSpacing
+		}
+		for _, child := range side.children {
+			mindmapPlaceTree(child, root.Y, 0, starts, side.direction)
+		}
+	}
+}
+
+// mindmapSplitSides shares the root's branches between the right and left
+// sides, in order, giving each branch to the side with less in it so far.
+func mindmapSplitSides(children []*mindmapLayoutNode, branchSpacing float32) ([]*mindmapLayoutNode, []*mindmapLayoutNode) {
+	var right, left []*mindmapLayoutNode
+	var rightSpan, leftSpan float32
+	for _, child := range children {
+		mindmapComputeSubtreeSize(child, branchSpacing)
+		if rightSpan <= leftSpan {
+			right = append(right, child)
+			rightSpan += child.subtreeSpan
+		} else {
+			left = append(left, child)
+			leftSpan += child.subtreeSpan
+		}
+	}
+	return right, left
+}
+
+// mindmapTidy sets the offsets of a node's descendants and returns the
+// node's subtree contour.
+func mindmapTidy(node *mindmapLayoutNode, spacing float32) mindmapContour {
+	contour := mindmapContour{top: []float32{-node.Height / 2}, bottom: []float32{node.Height / 2}}
+	if len(node.children) == 0 {
+		return contour
+	}
+	below := mindmapStackSubtrees(node.children, spacing)
+	contour.top = append(contour.top, below.top...)
+	contour.bottom = append(contour.bottom, below.bottom...)
+	return contour
+}
+
+// mindmapStackSubtrees stacks sibling subtrees top to bottom, each moved
+// up against the ones above it until some depth is spacing apart. The
+// siblings are centred on their parent: their offsets are set relative
+// to the midpoint of the first and last, and so is the returned contour.
+func mindmapStackSubtrees(children []*mindmapLayoutNode, spacing float32) mindmapContour {
+	var merged mindmapContour
+	positions := make([]float32, len(children))
+	for idx, child := range children {
+		contour := mindmapTidy(child, spacing)
+		if idx > 0 {
+			positions[idx] = merged.bottom[0] - contour.top[0] + spacing
+			for depth := 1; depth < min(len(merged.bottom), len(contour.top)); depth++ {
+				positions[idx] = max(positions[idx], merged.bottom[depth]-contour.top[depth]+spacing)
+			}
+		}
+		merged = merged.merge(contour, positions[idx])
+	}
+
+	mid := (positions[0] + positions[len(positions)-1]) / 2 //nolint:mnd // midpoint.
+	for idx, child := range children {
+		child.offsetY = positions[idx] - mid
+	}
+	for depth := range merged.top {
+		merged.top[depth] -= mid
+		merged.bottom[depth] -= mid
+	}
+	return merged
+}
+
+// mindmapColumnWidths returns the widest node at each depth below a side
+// of the root, depth 0 being the root's children.
+func mindmapColumnWidths(nodes []*mindmapLayoutNode, depth int, widths []float32) []float32 {
+	for _, node := range nodes {
+		if depth == len(widths) {
+			widths = append(widths, 0)
+		}
+		widths[depth] = max(widths[depth], node.Width)
+		widths = mindmapColumnWidths(node.children, depth+1, widths)
+	}
+	return widths
+}
+
+// mindmapPlaceTree positions a subtree from its offsets. Nodes sit at the
+// inner edge of their column, the one nearer the root, and direction is
+// 1 for the right side and -1 for the left.
+func mindmapPlaceTree(node *mindmapLayoutNode, parentY float32, depth int, starts []float32, direction float32) {
+	node.Y = parentY + node.offsetY
+	node.X = direction * (starts[depth] + node.Width/2)
+	for _, child := range node.children {
+		mindmapPlaceTree(child, node.Y, depth+1, starts, direction)
+	}
+}
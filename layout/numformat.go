@@ -0,0 +1,222 @@
+package layout
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Number format constants.
+const (
+	// numberDefaultSignificant is the precision of "s" formats without one.
+	numberDefaultSignificant = 3
+	// numberShortestDigits limits unformatted numbers to the precision a
+	// chart can show, hiding floating-point noise such as 0.30000000000000004.
+	numberShortestDigits = 12
+	numberPercentScale   = 100
+	numberGroupSize      = 3
+	// numberSIStep is the exponent step between SI prefixes.
+	numberSIStep = 3
+	// numberSIUnit is the index of the empty prefix in numberSIPrefixes.
+	numberSIUnit = 4
+	numberBase   = 10
+	// numberFixedPrecision is d3's default precision for "f".
+	numberFixedPrecision = 6
+	// numberGeneralPrecision is d3's default precision for formats without
+	// a type, which it treats as ".12~g".
+	numberGeneralPrecision = 12
+	// numberExactDigits is enough significant digits to write any float64
+	// exactly.
+	numberExactDigits = 767
+	// numberExponentFloor is the smallest exponent toPrecision writes
+	// without exponent notation.
+	numberExponentFloor = -6
+)
+
+// numberFormatRe matches the supported subset of d3-format specifiers:
+// an optional "$" currency symbol, an optional "," for thousands grouping,
+// an optional ".N" precision, an optional "~" to trim trailing zeros and a
+// type of f (fixed), d (integer), % (percentage) or s (SI prefix). Without
+// a type, as in d3, the number has N significant digits, 12 by default,
+// with trailing zeros trimmed.
+var numberFormatRe = regexp.MustCompile(`^(\$)?(,)?(?:\.(\d+))?(~)?([fds%]?)$`)
+
+// numberSIPrefixes are the SI prefixes from 10^-12 to 10^12, indexed by
+// exponent/3 + numberSIUnit.
+//
+//nolint:gochecknoglobals // read-only lookup table.
+var numberSIPrefixes = []string{"p", "n", "µ", "m", "", "k", "M", "G", "T"}
+
+// formatNumber formats a chart value with a d3-style format specifier:
+//
+//	".2f"  fixed decimals           1234.5 -> "1234.50"
+//	",d"   grouped integer          1234.5 -> "1,235"
+//	".1%"  percentage               0.256  -> "25.6%"
+//	".2s"  SI prefix, significant   1234.5 -> "1.2k"
+//	"$,.2f" currency                1234.5 -> "$1,234.50"
+//	","    grouped, trimmed         1234.5 -> "1,234.5"
+//	".2"   significant, trimmed     1234.5 -> "1.2e+3"
+//	".2~f" fixed, trimmed           1234.5 -> "1234.5"
+//
+// SI values drop trailing zeros, so 1000 is "1k". An empty or unsupported
+// format gives the shortest plain representation.
+func formatNumber(value float64, format string) string {
+	match := numberFormatRe.FindStringSubmatch(strings.TrimSpace(format))
+	if match == nil {
+		return formatShortest(value)
+	}
+	currency := match[1] != ""
+	grouped := match[2] != ""
+	precision := -1
+	if match[3] != "" {
+		precision, _ = strconv.Atoi(match[3]) //nolint:errcheck // regex guarantees digits.
+	}
+
+	trim := match[4] != ""
+
+	var text string
+	switch match[5] {
+	case "d":
+		text = groupThousands(strconv.FormatFloat(math.Round(value), 'f', 0, 64), grouped)
+	case "%":
+		if precision < 0 {
+			precision = 0
+		}
+		text = groupThousands(trimIf(strconv.FormatFloat(value*numberPercentScale, 'f', precision, 64), trim), grouped) + "%"
+	case "s":
+		text = formatSI(value, precision)
+	case "f":
+		if precision < 0 {
+			precision = numberFixedPrecision
+		}
+		text = groupThousands(trimIf(strconv.FormatFloat(value, 'f', precision, 64), trim), grouped)
+	default:
+		if precision < 0 {
+			precision = numberGeneralPrecision
+		}
+		text = groupThousands(formatSignificant(value, precision), grouped)
+	}
+	if !currency {
+		return text
+	}
+	if rest, negative := strings.CutPrefix(text, "-"); negative {
+		return "-$" + rest
+	}
+	return "$" + text
+}
+
+// formatSignificant formats a value to the given number of significant
+// digits as JavaScript's toPrecision does, in exponent notation such as
+// "1.2e+3" when the exponent is below -6 or not below the precision, then
+// trims trailing zeros as d3's "~" option does.
+func formatSignificant(value float64, significant int) string {
+	significant = max(1, significant)
+	if value == 0 {
+		return "0"
+	}
+	// toPrecision rounds halves away from zero where Go rounds them to
+	// even, so an exact tie is nudged off it.
+	if decimalTie(value, significant) {
+		value = math.Nextafter(value, math.Copysign(math.Inf(1), value))
+	}
+	// The exponent is the rounded value's, so 999.96 to three digits is
+	// "1e+3" rather than "1000".
+	mantissa, exponentText, _ := strings.Cut(strconv.FormatFloat(value, 'e', significant-1, 64), "e")
+	exponent, _ := strconv.Atoi(exponentText) //nolint:errcheck // FormatFloat writes a valid exponent.
+	if exponent < numberExponentFloor || exponent >= significant {
+		sign := "+"
+		if exponent < 0 {
+			sign = "-"
+		}
+		return trimZeros(mantissa) + "e" + sign + strconv.Itoa(max(exponent, -exponent))
+	}
+	return trimZeros(strconv.FormatFloat(value, 'f', significant-1-exponent, 64))
+}
+
+// decimalTie reports whether a value lies exactly halfway between the two
+// nearest numbers with the given count of significant digits.
+func decimalTie(value float64, significant int) bool {
+	mantissa, _, _ := strings.Cut(strconv.FormatFloat(math.Abs(value), 'e', numberExactDigits, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	if len(digits) <= significant {
+		return false
+	}
+	rest := digits[significant:]
+	return rest[0] == '5' && strings.Trim(rest[1:], "0") == ""
+}
+
+// trimZeros drops trailing zeros after a decimal point, and the point
+// itself when nothing follows it.
+func trimZeros(text string) string {
+	if !strings.Contains(text, ".") {
+		return text
+	}
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
+
+// trimIf trims trailing zeros as trimZeros does when trim is set.
+func trimIf(text string, trim bool) string {
+	if !trim {
+		return text
+	}
+	return trimZeros(text)
+}
+
+// formatShortest formats a value with no more digits than it needs.
+func formatShortest(value float64) string {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', numberShortestDigits, 64), 64)
+	if err != nil {
+		rounded = value
+	}
+	if rounded == 0 {
+		rounded = 0 // drop the sign of negative zero
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// formatSI formats a value with an SI prefix and the given number of
+// significant digits, dropping trailing zeros.
+func formatSI(value float64, significant int) string {
+	if significant <= 0 {
+		significant = numberDefaultSignificant
+	}
+	if value == 0 {
+		return "0"
+	}
+	// Round first so 999.96 with three digits becomes "1k", not "1000".
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', significant, 64), 64)
+	if err != nil {
+		rounded = value
+	}
+	exponent := int(math.Floor(math.Log10(math.Abs(rounded))/numberSIStep)) * numberSIStep
+	index := max(0, min(exponent/numberSIStep+numberSIUnit, len(numberSIPrefixes)-1))
+	exponent = (index - numberSIUnit) * numberSIStep
+	scaled := rounded / math.Pow(numberBase, float64(exponent))
+	return formatShortest(scaled) + numberSIPrefixes[index]
+}
+
+// groupThousands inserts commas between groups of three in the digits
+// before the first non-digit when grouped is set, as d3 does.
+func groupThousands(text string, grouped bool) string {
+	if !grouped {
+		return text
+	}
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	end := strings.IndexFunc(text, func(char rune) bool { return char < '0' || char > '9' })
+	if end < 0 {
+		end = len(text)
+	}
+	intPart, rest := text[:end], text[end:]
+	var out strings.Builder
+	for idx, digit := range intPart {
+		if idx > 0 && (len(intPart)-idx)%numberGroupSize == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(digit)
+	}
+	return sign + out.String() + rest
+}
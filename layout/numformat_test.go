@@ -0,0 +1,51 @@
+package layout
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value  float64
+		format string
+		want   string
+	}{
+		{1234.5, "", "1234.5"},
+		{0.1 + 0.2, "", "0.3"},
+		{1234.5, ".2f", "1234.50"},
+		{1234.5, ",.1f", "1,234.5"},
+		{1234567, ",d", "1,234,567"},
+		{-1234.5, ",d", "-1,235"},
+		{0.256, ".1%", "25.6%"},
+		{0.5, "%", "50%"},
+		{1234.5, ".2s", "1.2k"},
+		{1000, "s", "1k"},
+		{999.96, ".3s", "1k"},
+		{2500000, ".2s", "2.5M"},
+		{0.0042, ".2s", "4.2m"},
+		{0, ".2s", "0"},
+		{-1500, ".2s", "-1.5k"},
+		{42, "bogus", "42"},
+		{1234.5, "$,.2f", "$1,234.50"},
+		{-1234.6, "$,.0f", "-$1,235"},
+		{1234567.5, ",", "1,234,567.5"},
+		{1234.5, "$", "$1234.5"},
+		{1234.5, ".2", "1.2e+3"},
+		{1234.5, ".4", "1235"},
+		{-1234.5, ".4", "-1235"},
+		{0.15, ".1", "0.1"},
+		{0.000012345, ".3", "0.0000123"},
+		{0.00000012345, ".3", "1.23e-7"},
+		{120, ".5", "120"},
+		{1e15, ",", "1e+15"},
+		{0.1 + 0.2, ",", "0.3"},
+		{1234.5, ".2~f", "1234.5"},
+		{1234, ",.2~f", "1,234"},
+		{0.25, ".1~%", "25%"},
+		{1500, "~s", "1.5k"},
+		{1234.5, "~", "1234.5"},
+	}
+	for _, tt := range tests {
+		if got := formatNumber(tt.value, tt.format); got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.format, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,43 @@
 package layout
 
 import (
+	"strings"
+
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
 	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
+// Packet layout constants.
+const (
+	// packetRulerFontScale sizes bit ruler numbers relative to the theme
+	// font size, as the renderer's small bit numbers are.
+	packetRulerFontScale float32 = 0.7
+	// packetContinuedSuffix marks the label of a field's cells after the
+	// first when it spans rows and the cell is wide enough.
+	packetContinuedSuffix = " (cont.)"
+	// packetEllipsis ends a label shortened to fit its cell.
+	packetEllipsis = "…"
+)
+
 func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	measurer := textmetrics.New()
 	pc := cfg.Packet
+	if graph.PacketBitsPerRow > 0 {
+		pc.BitsPerRow = graph.PacketBitsPerRow
+	}
+	if graph.PacketShowBits != nil {
+		pc.ShowBits = *graph.PacketShowBits
+	}
+	if graph.PacketBitRuler != nil {
+		pc.BitRuler = *graph.PacketBitRuler
+	}
+	// Each row's ruler numbers its bits, so the header would repeat the
+	// first one.
+	if pc.BitRuler {
+		pc.ShowBits = false
+	}
 	bitsPerRow := pc.BitsPerRow
 	bitW := pc.BitWidth
 	rowH := pc.RowHeight
@@ -18,6 +46,13 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 
 	totalRowW := float32(bitsPerRow) * bitW
 
+	// A bit ruler sits above each row, inside the row's stride.
+	var rulerH float32
+	if pc.BitRuler {
+		rulerH = th.FontSize * packetRulerFontScale * cfg.LabelLineHeight
+	}
+	rowStride := rulerH + rowH + padY
+
 	// Group fields into rows based on bit positions.
 	type rowBuilder struct {
 		fields []PacketFieldLayout
@@ -32,6 +67,10 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 	}
 
 	for _, field := range graph.Fields {
+		// Reversed ranges are reported by lint and have nothing to draw.
+		if field.End < field.Start {
+			continue
+		}
 		startRow := field.Start / bitsPerRow
 		endRow := field.End / bitsPerRow
 
@@ -39,7 +78,7 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 			// Ensure we have enough rows
 			for len(rows) <= row {
 				rows = append(rows, rowBuilder{
-					y: cursorY + float32(len(rows))*(rowH+padY),
+					y: cursorY + float32(len(rows))*rowStride + rulerH,
 				})
 			}
 
@@ -60,22 +99,31 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 			fieldX := padX + float32(offsetInRow)*bitW
 			fieldW := float32(bitsInRow) * bitW
 
-			tw := measurer.Width(field.Description, th.FontSize, th.FontFamily)
+			// Continuation cells are marked in the label when it still fits.
+			// Labels wider than their cell are shortened to fit.
+			label := field.Description
+			if marked := label + packetContinuedSuffix; row > startRow &&
+				measurer.Width(marked, th.FontSize, th.FontFamily) <= fieldW {
+				label = marked
+			}
+			label, tw := packetFitLabel(label, fieldW, measurer, th)
 			tb := TextBlock{
-				Lines:    []string{field.Description},
+				Lines:    []string{label},
 				Width:    tw,
 				Height:   th.FontSize * cfg.LabelLineHeight,
 				FontSize: th.FontSize,
 			}
 
 			rows[row].fields = append(rows[row].fields, PacketFieldLayout{
-				Label:    tb,
-				X:        fieldX,
-				Y:        rows[row].y,
-				Width:    fieldW,
-				Height:   rowH,
-				StartBit: fieldStartInRow,
-				EndBit:   fieldEndInRow,
+				Label:         tb,
+				X:             fieldX,
+				Y:             rows[row].y,
+				Width:         fieldW,
+				Height:        rowH,
+				StartBit:      fieldStartInRow,
+				EndBit:        fieldEndInRow,
+				ContinuesFrom: row > startRow,
+				ContinuesTo:   row < endRow,
 			})
 		}
 	}
@@ -84,13 +132,14 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 	resultRows := make([]PacketRowLayout, len(rows))
 	for i, rb := range rows {
 		resultRows[i] = PacketRowLayout{
-			Y:      rb.y,
-			Height: rowH,
-			Fields: rb.fields,
+			Y:        rb.y,
+			Height:   rowH,
+			StartBit: i * bitsPerRow,
+			Fields:   rb.fields,
 		}
 	}
 
-	totalH := cursorY + float32(len(rows))*(rowH+padY) + padY
+	totalH := cursorY + float32(len(rows))*rowStride + padY
 	totalW := totalRowW + 2*padX
 
 	return &Layout{
@@ -101,6 +150,25 @@ func computePacketLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *
 			Rows:       resultRows,
 			BitsPerRow: bitsPerRow,
 			ShowBits:   pc.ShowBits,
+			BitRuler:   pc.BitRuler,
 		},
 	}
 }
+
+// packetFitLabel shortens label with an ellipsis until it fits within
+// width, returning it with its width. A label that cannot fit even one
+// character is dropped.
+func packetFitLabel(label string, width float32, measurer *textmetrics.Measurer, th *theme.Theme) (string, float32) {
+	labelW := measurer.Width(label, th.FontSize, th.FontFamily)
+	if labelW <= width {
+		return label, labelW
+	}
+	runes := []rune(label)
+	for end := len(runes) - 1; end > 0; end-- {
+		short := strings.TrimRight(string(runes[:end]), " ") + packetEllipsis
+		if shortW := measurer.Width(short, th.FontSize, th.FontFamily); shortW <= width {
+			return short, shortW
+		}
+	}
+	return "", 0
+}
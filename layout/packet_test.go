@@ -1,6 +1,8 @@
 package layout
 
 import (
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/jamesainslie/gomd2svg/config"
@@ -79,3 +81,104 @@ func TestPacketLayoutSingleBit(t *testing.T) {
 		t.Errorf("1-bit field width = %v, want %v", pd.Rows[0].Fields[0].Width, cfg.Packet.BitWidth)
 	}
 }
+
+func TestPacketLayoutContinuation(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Packet
+	graph.Fields = []*ir.PacketField{
+		{Start: 0, End: 7, Description: "Head"},
+		{Start: 8, End: 71, Description: "Payload"},
+		{Start: 72, End: 70, Description: "Reversed"},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	pd, ok := ComputeLayout(graph, th, cfg).Diagram.(PacketData)
+	if !ok {
+		t.Fatal("expected PacketData")
+	}
+	if len(pd.Rows) != 3 {
+		t.Fatalf("len(Rows) = %d, want 3", len(pd.Rows))
+	}
+
+	first := pd.Rows[0].Fields[1]
+	if first.ContinuesFrom || !first.ContinuesTo || first.Label.Lines[0] != "Payload" {
+		t.Errorf("first cell = %+v, want an unmarked cell continuing to the next row", first)
+	}
+	middle := pd.Rows[1].Fields[0]
+	if !middle.ContinuesFrom || !middle.ContinuesTo || middle.Label.Lines[0] != "Payload (cont.)" {
+		t.Errorf("middle cell = %+v, want a marked cell continuing both ways", middle)
+	}
+	last := pd.Rows[2].Fields[0]
+	if !last.ContinuesFrom || last.ContinuesTo || last.StartBit != 64 || last.EndBit != 71 {
+		t.Errorf("last cell = %+v, want bits 64-71 continuing from the row above", last)
+	}
+	if len(pd.Rows[2].Fields) != 1 {
+		t.Errorf("len(Rows[2].Fields) = %d, want the reversed field skipped", len(pd.Rows[2].Fields))
+	}
+	if pd.Rows[2].StartBit != 64 {
+		t.Errorf("Rows[2].StartBit = %d, want 64", pd.Rows[2].StartBit)
+	}
+}
+
+func TestPacketLayoutBitRuler(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Packet
+	graph.Fields = []*ir.PacketField{{Start: 0, End: 31, Description: "Data"}}
+	bitRuler := true
+	graph.PacketBitRuler = &bitRuler
+	graph.PacketBitsPerRow = 16
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	pd, ok := ComputeLayout(graph, th, cfg).Diagram.(PacketData)
+	if !ok {
+		t.Fatal("expected PacketData")
+	}
+	if !pd.BitRuler || pd.ShowBits {
+		t.Errorf("BitRuler = %v, ShowBits = %v, want the ruler replacing the header", pd.BitRuler, pd.ShowBits)
+	}
+	if pd.BitsPerRow != 16 || len(pd.Rows) != 2 {
+		t.Fatalf("BitsPerRow = %d, rows = %d, want 16 bits in 2 rows", pd.BitsPerRow, len(pd.Rows))
+	}
+	// Every row, the first included, leaves room for its ruler above it.
+	rulerH := th.FontSize * packetRulerFontScale * cfg.LabelLineHeight
+	if got := pd.Rows[0].Y; got != cfg.Packet.PaddingY+rulerH {
+		t.Errorf("Rows[0].Y = %v, want %v", got, cfg.Packet.PaddingY+rulerH)
+	}
+	if gap := pd.Rows[1].Y - pd.Rows[0].Y - pd.Rows[0].Height; math.Abs(float64(gap-cfg.Packet.PaddingY-rulerH)) > 0.01 {
+		t.Errorf("gap between rows = %v, want %v", gap, cfg.Packet.PaddingY+rulerH)
+	}
+}
+
+func TestPacketLayoutLabelsFitCells(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Packet
+	graph.PacketBitsPerRow = 16
+	graph.Fields = []*ir.PacketField{
+		{Start: 0, End: 15, Description: "Data"},
+		{Start: 16, End: 16, Description: "CRC delimiter"},
+		{Start: 17, End: 17, Description: "WWWWWWWW"},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	cfg.Packet.BitWidth = 16
+	pd, ok := ComputeLayout(graph, th, cfg).Diagram.(PacketData)
+	if !ok {
+		t.Fatal("expected PacketData")
+	}
+	for _, row := range pd.Rows {
+		for _, field := range row.Fields {
+			if field.Label.Width > field.Width {
+				t.Errorf("label %q is %v wide, beyond its %v cell", field.Label.Lines[0], field.Label.Width, field.Width)
+			}
+		}
+	}
+	if got := pd.Rows[0].Fields[0].Label.Lines[0]; got != "Data" {
+		t.Errorf("fitting label = %q, want it unchanged", got)
+	}
+	if got := pd.Rows[1].Fields[0].Label.Lines[0]; !strings.HasSuffix(got, packetEllipsis) && got != "" {
+		t.Errorf("overlong label = %q, want it shortened or dropped", got)
+	}
+}
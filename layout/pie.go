@@ -1,21 +1,79 @@
 package layout
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"strconv"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
-// piePercentScale is the multiplier for converting a fraction to a percentage.
-const piePercentScale = 100
+// Pie chart layout constants.
+const (
+	// piePercentScale is the multiplier for converting a fraction to a percentage.
+	piePercentScale = 100
+	// pieLegendSwatch is the size of a legend colour square.
+	pieLegendSwatch = 18
+	// pieLegendSpacing separates legend rows.
+	pieLegendSpacing = 4
+	// pieLegendTextGap separates a legend swatch from its text.
+	pieLegendTextGap = 6
+	// pieLegendGap separates the legend from the pie and its labels.
+	pieLegendGap = 30
+	// pieLeaderElbow is how far past the rim a leader line bends.
+	pieLeaderElbow = 12
+	// pieLeaderReach is how far past the rim a leader line ends.
+	pieLeaderReach = 24
+	// pieLeaderTextGap separates the end of a leader line from its label.
+	pieLeaderTextGap = 4
+	// pieInnerTextPosition is the label radius, as a fraction of the pie
+	// radius, tried when a label does not fit at the configured position.
+	pieInnerTextPosition = 0.5
+	// pieOutsideLineHeight spaces outside labels, as a multiple of the font size.
+	pieOutsideLineHeight = 1.2
+)
+
+// pieBox is an axis-aligned label box.
+type pieBox struct {
+	minX, minY, maxX, maxY float32
+}
+
+func (b pieBox) overlaps(other pieBox) bool {
+	return b.minX < other.maxX && other.minX < b.maxX && b.minY < other.maxY && other.minY < b.maxY
+}
+
+// inWedge reports whether every corner of the box, relative to the
+// centre, lies inside the pie between the start and end angles.
+func (b pieBox) inWedge(startAngle, endAngle, radius float32) bool {
+	if endAngle-startAngle >= 2*math.Pi {
+		startAngle, endAngle = -math.Pi, math.Pi
+	}
+	for _, corner := range [][2]float32{{b.minX, b.minY}, {b.maxX, b.minY}, {b.minX, b.maxY}, {b.maxX, b.maxY}} {
+		if math.Hypot(float64(corner[0]), float64(corner[1])) > float64(radius) {
+			return false
+		}
+		angle := float32(math.Atan2(float64(corner[1]), float64(corner[0])))
+		for angle < startAngle {
+			angle += 2 * math.Pi
+		}
+		if angle > endAngle {
+			return false
+		}
+	}
+	return true
+}
 
 func computePieLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
+	measurer := textmetrics.New()
 	radius := cfg.Pie.Radius
 	padX := cfg.Pie.PaddingX
 	padY := cfg.Pie.PaddingY
 	textPos := cfg.Pie.TextPosition
+	fontSize := th.PieSectionTextSize
 
 	// Compute total value.
 	var total float64
@@ -32,38 +90,90 @@ func computePieLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Lay
 		titleHeight = th.PieTitleTextSize + padY
 	}
 
-	centerX := padX + radius
-	centerY := titleHeight + padY + radius
-
-	// Compute slice angles (clockwise from top = -pi/2).
+	// Compute slice angles (clockwise from top = -pi/2). Positions are
+	// relative to the centre until the chart's extent is known.
 	slices := make([]PieSliceLayout, len(graph.PieSlices))
+	widths := make([]float32, len(graph.PieSlices))
 	var angle float32 = -math.Pi / 2 // start at top
 
 	for idx, slice := range graph.PieSlices {
 		frac := float32(slice.Value / total)
 		span := frac * 2 * math.Pi
 
+		text := slice.Label
+		if graph.PieShowData {
+			text = fmt.Sprintf("%s (%.0f)", slice.Label, slice.Value)
+		}
+		widths[idx] = measurer.Width(text, fontSize, th.FontFamily)
+
 		midAngle := angle + span/2
 		labelR := radius * textPos
-		labelX := centerX + labelR*float32(math.Cos(float64(midAngle)))
-		labelY := centerY + labelR*float32(math.Sin(float64(midAngle)))
-
 		slices[idx] = PieSliceLayout{
 			Label:      slice.Label,
+			Text:       text,
 			Value:      slice.Value,
 			Percentage: frac * piePercentScale,
 			StartAngle: angle,
 			EndAngle:   angle + span,
-			LabelX:     labelX,
-			LabelY:     labelY,
+			LabelX:     labelR * float32(math.Cos(float64(midAngle))),
+			LabelY:     labelR * float32(math.Sin(float64(midAngle))),
 			ColorIndex: idx,
 		}
 
 		angle += span
 	}
+	pieMoveCollidingLabels(slices, widths, radius, fontSize)
 
-	width := 2*padX + 2*radius
-	height := titleHeight + 2*padY + 2*radius
+	// Extent of the pie and its outside labels around the centre.
+	left, right, top, bottom := radius, radius, radius, radius
+	for idx, slice := range slices {
+		if !slice.Outside {
+			continue
+		}
+		if slice.LabelX >= 0 {
+			right = max(right, slice.LabelX+widths[idx])
+		} else {
+			left = max(left, widths[idx]-slice.LabelX)
+		}
+		top = max(top, fontSize/2-slice.LabelY)       //nolint:mnd // labels are centred vertically.
+		bottom = max(bottom, slice.LabelY+fontSize/2) //nolint:mnd // see above.
+	}
+
+	centerX := padX + left
+	centerY := titleHeight + padY + top
+	for idx := range slices {
+		slice := &slices[idx]
+		slice.LabelX += centerX
+		slice.LabelY += centerY
+		for pt := range slice.Leader {
+			slice.Leader[pt][0] += centerX
+			slice.Leader[pt][1] += centerY
+		}
+	}
+
+	width := centerX + right + padX
+	height := centerY + bottom + padY
+
+	var legend []PieLegendEntry
+	if cfg.Pie.LegendPosition != config.LegendNone && len(slices) > 0 {
+		var legendW, legendH float32
+		legend, legendW, legendH = pieLegend(graph, slices, measurer, th)
+		var originX, originY float32
+		if cfg.Pie.LegendPosition == config.LegendBottom {
+			originX = max(padX, centerX-legendW/2) //nolint:mnd // centred under the pie.
+			originY = centerY + bottom + pieLegendGap
+		} else {
+			originX = centerX + right + pieLegendGap
+			originY = max(titleHeight+padY, centerY-legendH/2) //nolint:mnd // centred beside the pie.
+		}
+		for idx := range legend {
+			legend[idx].X += originX
+			legend[idx].TextX += originX
+			legend[idx].Y += originY
+		}
+		width = max(width, originX+legendW+padX)
+		height = max(height, originY+legendH+padY)
+	}
 
 	return &Layout{
 		Kind:   graph.Kind,
@@ -71,12 +181,139 @@ func computePieLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Lay
 		Width:  width,
 		Height: height,
 		Diagram: PieData{
-			Slices:   slices,
-			CenterX:  centerX,
-			CenterY:  centerY,
-			Radius:   radius,
-			Title:    graph.PieTitle,
-			ShowData: graph.PieShowData,
+			Slices:       slices,
+			CenterX:      centerX,
+			CenterY:      centerY,
+			Radius:       radius,
+			Title:        graph.PieTitle,
+			ShowData:     graph.PieShowData,
+			Legend:       legend,
+			LegendSwatch: pieLegendSwatch,
 		},
 	}
 }
+
+// pieMoveCollidingLabels moves labels that would overlap another label or
+// spill out of their own slice outside the pie. Larger slices keep their
+// inside labels first. Outside
+// labels sit beside the pie on the side of their slice, spread apart
+// vertically, and get a leader line from the rim. Positions are relative
+// to the centre.
+func pieMoveCollidingLabels(slices []PieSliceLayout, widths []float32, radius, fontSize float32) {
+	order := make([]int, len(slices))
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(left, right int) bool {
+		spanL := slices[order[left]].EndAngle - slices[order[left]].StartAngle
+		spanR := slices[order[right]].EndAngle - slices[order[right]].StartAngle
+		return spanL > spanR
+	})
+
+	var placed []pieBox
+	var leftSide, rightSide []int
+	for _, idx := range order {
+		slice := &slices[idx]
+		if box, ok := pieFitLabel(slice, widths[idx], fontSize, radius, placed); ok {
+			placed = append(placed, box)
+			continue
+		}
+		slice.Outside = true
+		midAngle := float64(slice.StartAngle+slice.EndAngle) / 2 //nolint:mnd // bisector.
+		cos, sin := float32(math.Cos(midAngle)), float32(math.Sin(midAngle))
+		slice.Leader = [][2]float32{
+			{radius * cos, radius * sin},
+			{(radius + pieLeaderElbow) * cos, (radius + pieLeaderElbow) * sin},
+		}
+		slice.LabelY = (radius + pieLeaderElbow) * sin
+		if cos >= 0 {
+			rightSide = append(rightSide, idx)
+		} else {
+			leftSide = append(leftSide, idx)
+		}
+	}
+
+	lineH := fontSize * pieOutsideLineHeight
+	for side, indices := range [][]int{leftSide, rightSide} {
+		sign := float32(side*2 - 1) //nolint:mnd // -1 for the left side, 1 for the right.
+		pieSpreadLabels(slices, indices, lineH, radius+lineH)
+		for _, idx := range indices {
+			slice := &slices[idx]
+			slice.Leader = append(slice.Leader, [2]float32{sign * (radius + pieLeaderReach), slice.LabelY})
+			slice.LabelX = sign * (radius + pieLeaderReach + pieLeaderTextGap)
+		}
+	}
+}
+
+// pieFitLabel tries the slice's label at its configured position and then
+// nearer the centre, returning the label box of the first position that
+// stays inside the slice without overlapping a placed label. A label that
+// fits further in is moved there.
+func pieFitLabel(slice *PieSliceLayout, width, fontSize, radius float32, placed []pieBox) (pieBox, bool) {
+	halfW, halfH := width/2, fontSize/2 //nolint:mnd // boxes are centred on the label.
+	labelR := float32(math.Hypot(float64(slice.LabelX), float64(slice.LabelY)))
+	candidates := [][2]float32{{slice.LabelX, slice.LabelY}}
+	if inner := radius * pieInnerTextPosition; labelR > inner {
+		scale := inner / labelR
+		candidates = append(candidates, [2]float32{slice.LabelX * scale, slice.LabelY * scale})
+	}
+	for _, pos := range candidates {
+		box := pieBox{pos[0] - halfW, pos[1] - halfH, pos[0] + halfW, pos[1] + halfH}
+		fits := box.inWedge(slice.StartAngle, slice.EndAngle, radius)
+		for _, other := range placed {
+			if fits && box.overlaps(other) {
+				fits = false
+			}
+		}
+		if fits {
+			slice.LabelX, slice.LabelY = pos[0], pos[1]
+			return box, true
+		}
+	}
+	return pieBox{}, false
+}
+
+// pieSpreadLabels moves the outside labels of one side apart so they are
+// at least lineH apart, keeping the lowest no further down than maxY
+// where possible.
+func pieSpreadLabels(slices []PieSliceLayout, indices []int, lineH, maxY float32) {
+	sort.Slice(indices, func(left, right int) bool {
+		return slices[indices[left]].LabelY < slices[indices[right]].LabelY
+	})
+	for pos := 1; pos < len(indices); pos++ {
+		prev := slices[indices[pos-1]].LabelY
+		slices[indices[pos]].LabelY = max(slices[indices[pos]].LabelY, prev+lineH)
+	}
+	if last := len(indices) - 1; last >= 0 && slices[indices[last]].LabelY > maxY {
+		slices[indices[last]].LabelY = maxY
+		for pos := last - 1; pos >= 0; pos-- {
+			next := slices[indices[pos+1]].LabelY
+			slices[indices[pos]].LabelY = min(slices[indices[pos]].LabelY, next-lineH)
+		}
+	}
+}
+
+// pieLegend returns one legend row per slice, positioned relative to the
+// legend's top-left corner, and the legend's size. Rows show the value
+// and percentage when the chart has showData.
+func pieLegend(graph *ir.Graph, slices []PieSliceLayout, measurer *textmetrics.Measurer, th *theme.Theme) ([]PieLegendEntry, float32, float32) {
+	legend := make([]PieLegendEntry, len(slices))
+	var textW float32
+	for idx, slice := range slices {
+		text := slice.Label
+		if graph.PieShowData {
+			text = fmt.Sprintf("%s [%s, %s%%]", slice.Label,
+				strconv.FormatFloat(slice.Value, 'f', -1, 64),
+				strconv.FormatFloat(float64(slice.Percentage), 'f', 1, 32))
+		}
+		textW = max(textW, measurer.Width(text, th.PieSectionTextSize, th.FontFamily))
+		legend[idx] = PieLegendEntry{
+			Text:       text,
+			Y:          float32(idx) * (pieLegendSwatch + pieLegendSpacing),
+			TextX:      pieLegendSwatch + pieLegendTextGap,
+			ColorIndex: slice.ColorIndex,
+		}
+	}
+	height := float32(len(slices))*(pieLegendSwatch+pieLegendSpacing) - pieLegendSpacing
+	return legend, pieLegendSwatch + pieLegendTextGap + textW, height
+}
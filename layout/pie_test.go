@@ -73,3 +73,111 @@ func TestPieLayoutSingleSlice(t *testing.T) {
 		t.Errorf("span = %f, want ~2*pi", span)
 	}
 }
+
+func TestPieLayoutLegend(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Pie
+	graph.PieShowData = true
+	graph.PieSlices = []*ir.PieSlice{
+		{Label: "Dogs", Value: 386},
+		{Label: "Cats", Value: 85},
+		{Label: "Rats", Value: 15},
+	}
+	th := theme.Modern()
+
+	tests := []struct {
+		name     string
+		position config.LegendPosition
+	}{
+		{"right", config.LegendRight},
+		{"bottom", config.LegendBottom},
+		{"none", config.LegendNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultLayout()
+			cfg.Pie.LegendPosition = tt.position
+			lay := ComputeLayout(graph, th, cfg)
+			pd, ok := lay.Diagram.(PieData)
+			if !ok {
+				t.Fatalf("Diagram type = %T, want PieData", lay.Diagram)
+			}
+			if tt.position == config.LegendNone {
+				if len(pd.Legend) != 0 {
+					t.Errorf("Legend = %d entries, want none", len(pd.Legend))
+				}
+				return
+			}
+			if len(pd.Legend) != 3 {
+				t.Fatalf("Legend = %d entries, want 3", len(pd.Legend))
+			}
+			if got, want := pd.Legend[0].Text, "Dogs [386, 79.4%]"; got != want {
+				t.Errorf("Legend[0].Text = %q, want %q", got, want)
+			}
+			for _, entry := range pd.Legend {
+				if tt.position == config.LegendRight && entry.X <= pd.CenterX+pd.Radius {
+					t.Errorf("entry %q at x=%f, want right of the pie", entry.Text, entry.X)
+				}
+				if tt.position == config.LegendBottom && entry.Y <= pd.CenterY+pd.Radius {
+					t.Errorf("entry %q at y=%f, want below the pie", entry.Text, entry.Y)
+				}
+				if entry.TextX+1 > lay.Width || entry.Y+pd.LegendSwatch > lay.Height {
+					t.Errorf("entry %q outside the %fx%f chart", entry.Text, lay.Width, lay.Height)
+				}
+			}
+			if pd.Legend[1].Y <= pd.Legend[0].Y {
+				t.Errorf("legend rows not stacked: %f then %f", pd.Legend[0].Y, pd.Legend[1].Y)
+			}
+		})
+	}
+}
+
+func TestPieLayoutOutsideLabels(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Pie
+	graph.PieSlices = []*ir.PieSlice{
+		{Label: "Large", Value: 900},
+		{Label: "Tiny one", Value: 10},
+		{Label: "Tiny two", Value: 8},
+		{Label: "Tiny three", Value: 6},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, th, cfg)
+	pd, ok := lay.Diagram.(PieData)
+	if !ok {
+		t.Fatalf("Diagram type = %T, want PieData", lay.Diagram)
+	}
+
+	if pd.Slices[0].Outside {
+		t.Error("large slice label moved outside, want inside")
+	}
+	var outsideY []float32
+	for _, slice := range pd.Slices[1:] {
+		if !slice.Outside {
+			t.Errorf("%q label inside, want outside", slice.Label)
+			continue
+		}
+		if len(slice.Leader) != 3 {
+			t.Errorf("%q leader has %d points, want 3", slice.Label, len(slice.Leader))
+			continue
+		}
+		rim := slice.Leader[0]
+		dist := math.Hypot(float64(rim[0]-pd.CenterX), float64(rim[1]-pd.CenterY))
+		if math.Abs(dist-float64(pd.Radius)) > 0.5 {
+			t.Errorf("%q leader starts %f from the centre, want the rim at %f", slice.Label, dist, pd.Radius)
+		}
+		if end := slice.Leader[2]; end[1] != slice.LabelY {
+			t.Errorf("%q leader ends at y=%f, want label y=%f", slice.Label, end[1], slice.LabelY)
+		}
+		outsideY = append(outsideY, slice.LabelY)
+	}
+	for idx := range outsideY {
+		for other := idx + 1; other < len(outsideY); other++ {
+			if gap := math.Abs(float64(outsideY[idx] - outsideY[other])); gap < float64(th.PieSectionTextSize) {
+				t.Errorf("outside labels %d and %d are %f apart, want >= %f", idx, other, gap, th.PieSectionTextSize)
+			}
+		}
+	}
+}
@@ -41,15 +41,25 @@ func computeQuadrantLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 		px := chartX + float32(pt.X)*chartW
 		// Y is inverted: 0 = bottom (high Y), 1 = top (low Y).
 		py := chartY + (1-float32(pt.Y))*chartH
+		// A point's own styles win over its class.
+		style := graph.QuadrantClasses[pt.Class].Merge(pt.Style)
+		radius := cfg.Quadrant.PointRadius
+		if style.Radius != nil {
+			radius = *style.Radius
+		}
 		points[idx] = QuadrantPointLayout{
-			Label: pt.Label,
-			X:     px,
-			Y:     py,
+			Label:  pt.Label,
+			X:      px,
+			Y:      py,
+			Radius: radius,
+			Style:  style,
 		}
 	}
 
 	totalW := padX + yAxisLabelWidth + chartW + padX
 	totalH := titleHeight + padY + chartH + xAxisLabelHeight + padY
+	titles := quadrantTitleBoxes(graph.QuadrantLabels, chartX, chartY, chartW, chartH, cfg.Quadrant.QuadrantLabelFontSize, th.FontFamily)
+	quadrantPlaceLabels(points, titles, cfg.Quadrant.AxisLabelFontSize, th.FontFamily, totalW, totalH)
 
 	return &Layout{
 		Kind:   graph.Kind,
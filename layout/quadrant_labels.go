@@ -0,0 +1,138 @@
+package layout
+
+import "github.com/jamesainslie/gomd2svg/textmetrics"
+
+// Quadrant label placement constants.
+const (
+	// quadrantLabelGap is the space between a point and its label.
+	quadrantLabelGap float32 = 3
+	// quadrantLabelDiagonal places diagonal labels at 45 degrees around
+	// the point, about cos(45°) of the radius out on each axis.
+	quadrantLabelDiagonal float32 = 0.7
+)
+
+// quadrantBox is an axis-aligned box.
+type quadrantBox struct {
+	left, top, right, bottom float32
+}
+
+// overlap returns the area shared by two boxes.
+func (b quadrantBox) overlap(other quadrantBox) float32 {
+	width := min(b.right, other.right) - max(b.left, other.left)
+	height := min(b.bottom, other.bottom) - max(b.top, other.top)
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	return width * height
+}
+
+// quadrantLabelSpot is a candidate label position: the anchor x, the
+// label's vertical centre and the SVG text-anchor.
+type quadrantLabelSpot struct {
+	x, centerY float32
+	anchor     string
+}
+
+// quadrantPlaceLabels places each point's label at the first of several
+// spots around it, right, left, above, below then diagonally, that does
+// not cover another point, an earlier label, a fixed box such as a
+// quadrant's title or the diagram's edge. When every spot collides the one
+// with the least overlap wins.
+func quadrantPlaceLabels(points []QuadrantPointLayout, fixed []quadrantBox, fontSize float32, fontFamily string, width, height float32) {
+	measurer := textmetrics.New()
+	obstacles := make([]quadrantBox, 0, len(points)*2+len(fixed)) //nolint:mnd // a point and its label each.
+	for _, point := range points {
+		obstacles = append(obstacles, quadrantBox{
+			left: point.X - point.Radius, top: point.Y - point.Radius,
+			right: point.X + point.Radius, bottom: point.Y + point.Radius,
+		})
+	}
+	obstacles = append(obstacles, fixed...)
+	bounds := quadrantBox{right: width, bottom: height}
+
+	for idx := range points {
+		point := &points[idx]
+		labelW := measurer.Width(point.Label, fontSize, fontFamily)
+		var best quadrantBox
+		var bestSpot quadrantLabelSpot
+		bestCost := float32(-1)
+		for _, spot := range quadrantLabelSpots(point.X, point.Y, point.Radius, fontSize) {
+			box := quadrantLabelBox(spot, labelW, fontSize)
+			// Whatever falls outside the diagram counts as overlap.
+			cost := (box.right-box.left)*(box.bottom-box.top) - box.overlap(bounds)
+			for obstacle, other := range obstacles {
+				if obstacle != idx {
+					cost += box.overlap(other)
+				}
+			}
+			if bestCost < 0 || cost < bestCost {
+				best, bestSpot, bestCost = box, spot, cost
+			}
+			if cost == 0 {
+				break
+			}
+		}
+		point.LabelX = bestSpot.x
+		point.LabelY = bestSpot.centerY + fontSize/3 //nolint:mnd // baseline sits a third of the size below the centre.
+		point.LabelAnchor = bestSpot.anchor
+		obstacles = append(obstacles, best)
+	}
+}
+
+// quadrantLabelSpots returns the candidate label spots around a point in
+// order of preference.
+func quadrantLabelSpots(x, y, radius, fontSize float32) []quadrantLabelSpot {
+	side := radius + quadrantLabelGap
+	vertical := radius + quadrantLabelGap + fontSize/2 //nolint:mnd // half the label height.
+	diagonalX := radius*quadrantLabelDiagonal + quadrantLabelGap
+	diagonalY := radius*quadrantLabelDiagonal + fontSize/2 //nolint:mnd // half the label height.
+	return []quadrantLabelSpot{
+		{x + side, y, "start"},
+		{x - side, y, "end"},
+		{x, y - vertical, "middle"},
+		{x, y + vertical, "middle"},
+		{x + diagonalX, y - diagonalY, "start"},
+		{x + diagonalX, y + diagonalY, "start"},
+		{x - diagonalX, y - diagonalY, "end"},
+		{x - diagonalX, y + diagonalY, "end"},
+	}
+}
+
+// quadrantTitleBoxes returns the boxes covered by the quadrant titles,
+// which are centred in their quadrants.
+func quadrantTitleBoxes(labels [4]string, chartX, chartY, chartW, chartH, fontSize float32, fontFamily string) []quadrantBox {
+	measurer := textmetrics.New()
+	// Centres of Q1 (top-right), Q2 (top-left), Q3 (bottom-left) and Q4
+	// (bottom-right) as fractions of the chart.
+	centres := [4][2]float32{{0.75, 0.25}, {0.25, 0.25}, {0.25, 0.75}, {0.75, 0.75}} //nolint:mnd // quadrant centres.
+	var boxes []quadrantBox
+	for idx, label := range labels {
+		if label == "" {
+			continue
+		}
+		spot := quadrantLabelSpot{
+			x:       chartX + centres[idx][0]*chartW,
+			centerY: chartY + centres[idx][1]*chartH,
+			anchor:  "middle",
+		}
+		boxes = append(boxes, quadrantLabelBox(spot, measurer.Width(label, fontSize, fontFamily), fontSize))
+	}
+	return boxes
+}
+
+// quadrantLabelBox returns the box a label covers at a spot.
+func quadrantLabelBox(spot quadrantLabelSpot, labelW, fontSize float32) quadrantBox {
+	left := spot.x
+	switch spot.anchor {
+	case "end":
+		left -= labelW
+	case "middle":
+		left -= labelW / 2 //nolint:mnd // centred.
+	}
+	return quadrantBox{
+		left:   left,
+		top:    spot.centerY - fontSize/2, //nolint:mnd // centred.
+		right:  left + labelW,
+		bottom: spot.centerY + fontSize/2, //nolint:mnd // centred.
+	}
+}
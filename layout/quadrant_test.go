@@ -1,10 +1,12 @@
 package layout
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
@@ -89,3 +91,80 @@ func abs32(x float32) float32 {
 	}
 	return x
 }
+
+func TestQuadrantLayoutPointStyles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Quadrant
+	classRadius, ownRadius := float32(10), float32(14)
+	classColor, ownStroke := "#109060", "#900"
+	graph.QuadrantClasses["hot"] = ir.QuadrantPointStyle{Radius: &classRadius, Color: &classColor}
+	graph.QuadrantPoints = []*ir.QuadrantPoint{
+		{Label: "Plain", X: 0.2, Y: 0.2},
+		{Label: "Class", X: 0.5, Y: 0.5, Class: "hot"},
+		{Label: "Both", X: 0.8, Y: 0.8, Class: "hot", Style: ir.QuadrantPointStyle{Radius: &ownRadius, StrokeColor: &ownStroke}},
+	}
+
+	cfg := config.DefaultLayout()
+	qd, ok := ComputeLayout(graph, theme.Modern(), cfg).Diagram.(QuadrantData)
+	if !ok {
+		t.Fatal("Diagram is not QuadrantData")
+	}
+	if got := qd.Points[0]; got.Radius != cfg.Quadrant.PointRadius || got.Style.Color != nil {
+		t.Errorf("plain point = %+v, want the default radius and no style", got)
+	}
+	if got := qd.Points[1]; got.Radius != classRadius || got.Style.Color == nil || *got.Style.Color != classColor {
+		t.Errorf("class point = %+v, want the class radius and colour", got)
+	}
+	both := qd.Points[2]
+	if both.Radius != ownRadius || both.Style.Color == nil || *both.Style.Color != classColor ||
+		both.Style.StrokeColor == nil || *both.Style.StrokeColor != ownStroke {
+		t.Errorf("styled point = %+v, want its own radius over the class's and both colours", both)
+	}
+}
+
+func TestQuadrantLayoutLabelsAvoidCollisions(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Quadrant
+	// A column of points 16px apart: labels all placed to the right
+	// would overlap, as would a label on the right of the point beside it.
+	for idx := range 6 {
+		graph.QuadrantPoints = append(graph.QuadrantPoints, &ir.QuadrantPoint{
+			Label: fmt.Sprintf("Point %d", idx+1),
+			X:     0.5,
+			Y:     0.5 + float64(idx)*0.02,
+		})
+	}
+	graph.QuadrantPoints = append(graph.QuadrantPoints, &ir.QuadrantPoint{Label: "Neighbour", X: 0.58, Y: 0.54})
+
+	cfg := config.DefaultLayout()
+	th := theme.Modern()
+	lay := ComputeLayout(graph, th, cfg)
+	qd, ok := lay.Diagram.(QuadrantData)
+	if !ok {
+		t.Fatal("Diagram is not QuadrantData")
+	}
+
+	measurer := textmetrics.New()
+	fontSize := cfg.Quadrant.AxisLabelFontSize
+	boxes := make([]quadrantBox, len(qd.Points))
+	for idx, point := range qd.Points {
+		spot := quadrantLabelSpot{x: point.LabelX, centerY: point.LabelY - fontSize/3, anchor: point.LabelAnchor}
+		boxes[idx] = quadrantLabelBox(spot, measurer.Width(point.Label, fontSize, th.FontFamily), fontSize)
+	}
+	for first := range boxes {
+		for second := first + 1; second < len(boxes); second++ {
+			if area := boxes[first].overlap(boxes[second]); area > 0 {
+				t.Errorf("labels %q and %q overlap by %v", qd.Points[first].Label, qd.Points[second].Label, area)
+			}
+		}
+		for other, point := range qd.Points {
+			circle := quadrantBox{point.X - point.Radius, point.Y - point.Radius, point.X + point.Radius, point.Y + point.Radius}
+			if other != first && boxes[first].overlap(circle) > 0 {
+				t.Errorf("label %q covers point %q", qd.Points[first].Label, point.Label)
+			}
+		}
+	}
+	if qd.Points[0].LabelAnchor != "start" {
+		t.Errorf("first label anchor = %q, want the preferred right-hand spot", qd.Points[0].LabelAnchor)
+	}
+}
@@ -34,12 +34,23 @@ func computeRadarLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *La
 	centerX := padX + radius + labelOffset
 	centerY := titleHeight + padY + radius + labelOffset
 
-	// Determine max value.
+	ticks := graph.RadarTicks
+	if ticks <= 0 {
+		ticks = cfg.Radar.DefaultTicks
+	}
+
+	// Determine max value. An automatic maximum is rounded up to a nice
+	// tick. Without an explicit tick count the rings then fall on the nice
+	// tick values; with one, exactly that many rings are drawn.
+	minVal := graph.RadarMin
 	maxVal := graph.RadarMax
+	var tickStep float64
 	if maxVal <= 0 {
-		maxVal = radarAutoMax(graph)
+		_, maxVal, tickStep = niceScale(minVal, radarDataMax(graph), ticks)
+		if graph.RadarTicks > 0 {
+			tickStep = 0
+		}
 	}
-	minVal := graph.RadarMin
 
 	valRange := maxVal - minVal
 	if valRange <= 0 {
@@ -93,13 +104,18 @@ func computeRadarLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *La
 	}
 
 	// Graticule radii (concentric rings).
-	ticks := graph.RadarTicks
-	if ticks <= 0 {
-		ticks = cfg.Radar.DefaultTicks
-	}
-	graticuleRadii := make([]float32, ticks)
-	for i := range ticks {
-		graticuleRadii[i] = radius * float32(i+1) / float32(ticks)
+	var graticuleRadii []float32
+	if tickStep > 0 {
+		for _, value := range niceTickValues(minVal, maxVal, tickStep) {
+			if value > minVal {
+				graticuleRadii = append(graticuleRadii, radius*float32((value-minVal)/valRange))
+			}
+		}
+	} else {
+		graticuleRadii = make([]float32, ticks)
+		for i := range ticks {
+			graticuleRadii[i] = radius * float32(i+1) / float32(ticks)
+		}
 	}
 
 	totalW := (padX + labelOffset + radius) * 2
@@ -126,7 +142,9 @@ func computeRadarLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *La
 	}
 }
 
-func radarAutoMax(graph *ir.Graph) float64 {
+// radarDataMax returns the largest curve value, or radarDefaultValue for
+// a chart without positive values.
+func radarDataMax(graph *ir.Graph) float64 {
 	maxValue := 0.0
 	for _, c := range graph.RadarCurves {
 		for _, v := range c.Values {
@@ -138,5 +156,5 @@ func radarAutoMax(graph *ir.Graph) float64 {
 	if maxValue <= 0 {
 		return radarDefaultValue
 	}
-	return niceMax(maxValue)
+	return maxValue
 }
@@ -74,3 +74,62 @@ func TestRadarAutoMax(t *testing.T) {
 		t.Error("MaxValue should be auto-computed > 0")
 	}
 }
+
+func TestRadarNiceRings(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Radar
+	graph.RadarTicks = 4
+	graph.RadarAxes = []*ir.RadarAxis{
+		{ID: "a", Label: "A"},
+		{ID: "b", Label: "B"},
+		{ID: "c", Label: "C"},
+	}
+	graph.RadarCurves = []*ir.RadarCurve{
+		{ID: "x", Label: "X", Values: []float64{30, 87, 55}},
+	}
+
+	lay := ComputeLayout(graph, theme.Modern(), config.DefaultLayout())
+	rd, ok := lay.Diagram.(RadarData)
+	if !ok {
+		t.Fatal("Diagram is not RadarData")
+	}
+	if rd.MaxValue != 100 {
+		t.Errorf("MaxValue = %v, want 100", rd.MaxValue)
+	}
+	// Four rings at 25, 50, 75 and 100.
+	if len(rd.GraticuleRadii) != 4 {
+		t.Fatalf("GraticuleRadii = %v, want 4 rings", rd.GraticuleRadii)
+	}
+	if got, want := rd.GraticuleRadii[0], rd.Radius/4; got != want {
+		t.Errorf("first ring = %v, want %v", got, want)
+	}
+}
+
+func TestRadarExplicitTicks(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Radar
+	graph.RadarTicks = 7
+	graph.RadarAxes = []*ir.RadarAxis{
+		{ID: "a", Label: "A"},
+		{ID: "b", Label: "B"},
+		{ID: "c", Label: "C"},
+	}
+	graph.RadarCurves = []*ir.RadarCurve{
+		{ID: "x", Label: "X", Values: []float64{30, 87, 55}},
+	}
+
+	lay := ComputeLayout(graph, theme.Modern(), config.DefaultLayout())
+	rd, ok := lay.Diagram.(RadarData)
+	if !ok {
+		t.Fatal("Diagram is not RadarData")
+	}
+	if len(rd.GraticuleRadii) != 7 {
+		t.Fatalf("GraticuleRadii = %v, want 7 rings", rd.GraticuleRadii)
+	}
+	if got := rd.GraticuleRadii[6]; got != rd.Radius {
+		t.Errorf("outer ring = %v, want the radius %v", got, rd.Radius)
+	}
+	if rd.MaxValue < 87 {
+		t.Errorf("MaxValue = %v, want at least the data maximum", rd.MaxValue)
+	}
+}
@@ -95,6 +95,23 @@ func edgeEndpoints(src, dst *NodeLayout, direction ir.Direction) (float32, float
 	}
 }
 
+// nodeBorderPoint returns where the line from a node's centre towards
+// (towardX, towardY) leaves the node's rectangle.
+func nodeBorderPoint(node *NodeLayout, towardX, towardY float32) [2]float32 {
+	deltaX, deltaY := towardX-node.X, towardY-node.Y
+	if deltaX == 0 && deltaY == 0 {
+		return [2]float32{node.X, node.Y}
+	}
+	scale := float32(math.Inf(1))
+	if deltaX != 0 {
+		scale = min(scale, node.Width/2/float32(math.Abs(float64(deltaX)))) //nolint:mnd // half-width.
+	}
+	if deltaY != 0 {
+		scale = min(scale, node.Height/2/float32(math.Abs(float64(deltaY)))) //nolint:mnd // half-height.
+	}
+	return [2]float32{node.X + deltaX*scale, node.Y + deltaY*scale}
+}
+
 // grid represents a 2D obstacle grid for A* pathfinding.
 type grid struct {
 	blocked  [][]bool
@@ -383,6 +400,12 @@ func simplifyPath(pts [][2]float32) [][2]float32 {
 
 // pathMidpoint returns the point at the middle of the path's total length.
 func pathMidpoint(pts [][2]float32) [2]float32 {
+	return pathPointAt(pts, 0.5) //nolint:mnd // halfway.
+}
+
+// pathPointAt returns the point at the given fraction of the path's total
+// length.
+func pathPointAt(pts [][2]float32, frac float32) [2]float32 {
 	if len(pts) == 0 {
 		return [2]float32{}
 	}
@@ -398,18 +421,18 @@ func pathMidpoint(pts [][2]float32) [2]float32 {
 		totalLen += float32(math.Sqrt(float64(deltaX*deltaX + deltaY*deltaY)))
 	}
 
-	// Walk to the halfway point.
-	halfLen := totalLen / 2
+	// Walk to the requested point.
+	wantLen := totalLen * frac
 	var walked float32
 	for idx := 1; idx < len(pts); idx++ {
 		deltaX := pts[idx][0] - pts[idx-1][0]
 		deltaY := pts[idx][1] - pts[idx-1][1]
 		segLen := float32(math.Sqrt(float64(deltaX*deltaX + deltaY*deltaY)))
-		if walked+segLen >= halfLen && segLen > 0 {
-			frac := (halfLen - walked) / segLen
+		if walked+segLen >= wantLen && segLen > 0 {
+			segFrac := (wantLen - walked) / segLen
 			return [2]float32{
-				pts[idx-1][0] + deltaX*frac,
-				pts[idx-1][1] + deltaY*frac,
+				pts[idx-1][0] + deltaX*segFrac,
+				pts[idx-1][1] + deltaY*segFrac,
 			}
 		}
 		walked += segLen
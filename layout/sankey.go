@@ -1,7 +1,7 @@
 package layout
 
 import (
-	"sort"
+	"math"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -9,129 +9,69 @@ import (
 )
 
 func computeSankeyLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *Layout {
+	scfg := cfg.Sankey
+	linkColor := scfg.LinkColor
+	if graph.SankeyLinkColor != "" {
+		linkColor = graph.SankeyLinkColor
+	}
+	showValues := scfg.ShowValues
+	if graph.SankeyShowValues != nil {
+		showValues = *graph.SankeyShowValues
+	}
 	if len(graph.SankeyLinks) == 0 {
 		return &Layout{
 			Kind:    graph.Kind,
 			Nodes:   map[string]*NodeLayout{},
-			Width:   cfg.Sankey.ChartWidth + cfg.Sankey.PaddingX*2,
-			Height:  cfg.Sankey.ChartHeight + cfg.Sankey.PaddingY*2,
-			Diagram: SankeyData{},
+			Width:   scfg.ChartWidth + scfg.PaddingX*2,
+			Height:  scfg.ChartHeight + scfg.PaddingY*2,
+			Diagram: SankeyData{LinkColor: linkColor, ShowValues: showValues},
 		}
 	}
+	alignment := scfg.NodeAlignment
+	if graph.SankeyNodeAlignment != "" {
+		alignment = config.SankeyAlignment(graph.SankeyNodeAlignment)
+	}
 
 	// Step 1: Collect unique node names preserving order of first appearance.
 	nodeNames, nodeIndex := sankeyCollectNodes(graph.SankeyLinks)
 
-	// Step 2: Assign columns via longest path from sources.
-	columns := sankeyAssignColumns(nodeNames, nodeIndex, graph.SankeyLinks)
-	maxCol := 0
-	for _, c := range columns {
-		if c > maxCol {
-			maxCol = c
-		}
-	}
-
-	// Step 3: Compute total flow per node (max of inflow vs outflow).
-	totalFlow := sankeyComputeFlow(nodeNames, nodeIndex, graph.SankeyLinks)
-
-	// Step 4: Position nodes in columns.
-	chartW := cfg.Sankey.ChartWidth
-	chartH := cfg.Sankey.ChartHeight
-	padX := cfg.Sankey.PaddingX
-	padY := cfg.Sankey.PaddingY
-	nodeWidth := cfg.Sankey.NodeWidth
-	nodePad := cfg.Sankey.NodePadding
-
-	// Group nodes by column.
-	colNodes := make([][]int, maxCol+1)
-	for i, c := range columns {
-		colNodes[c] = append(colNodes[c], i)
-	}
-
-	// Compute horizontal spacing between columns.
-	colWidth := float32(0)
-	if maxCol > 0 {
-		colWidth = (chartW - nodeWidth) / float32(maxCol)
-	}
-
-	// Position nodes within each column.
-	nodes := make([]SankeyNodeLayout, len(nodeNames))
-	for colIdx, col := range colNodes {
-		// Sort nodes in column by their original appearance order.
-		sort.Slice(col, func(ci, cj int) bool { return col[ci] < col[cj] })
-
-		nodeX := padX + float32(colIdx)*colWidth
-
-		// Compute total flow for this column.
-		colTotal := float64(0)
-		for _, ni := range col {
-			colTotal += totalFlow[ni]
-		}
-
-		// Scale heights so nodes fit in chartH minus inter-node padding.
-		availH := chartH - nodePad*float32(len(col)-1)
-		scale := float64(availH) / colTotal
-		if colTotal == 0 {
-			scale = 0
-		}
-
-		nodeY := padY
-		for _, ni := range col {
-			nodeH := float32(totalFlow[ni] * scale)
-			if nodeH < 2 {
-				nodeH = 2
-			}
-			nodes[ni] = SankeyNodeLayout{
-				Label:      nodeNames[ni],
-				X:          nodeX,
-				Y:          nodeY,
-				Width:      nodeWidth,
-				Height:     nodeH,
-				ColorIndex: ni,
-			}
-			nodeY += nodeH + nodePad
-		}
+	// Links that close a cycle cannot be ranked into columns; lint reports
+	// them and the layout leaves them out.
+	flows := sankeyBreakCycles(nodeNames, nodeIndex, graph.SankeyLinks)
+
+	// Step 2: Compute total flow per node (max of inflow vs outflow).
+	totalFlow := sankeyComputeFlow(nodeNames, nodeIndex, flows)
+
+	// Step 3: Build the node and link graph and pick columns.
+	solver := newSankeySolver(nodeNames, nodeIndex, totalFlow, flows, scfg)
+	solver.assignColumns(alignment, sankeyAssignColumns(nodeNames, nodeIndex, flows))
+
+	// Step 4: Stack nodes in their columns, then relax them toward the
+	// nodes they link to.
+	solver.initializeBreadths()
+	for iteration := range scfg.Iterations {
+		alpha := float32(math.Pow(sankeyRelaxDecay, float64(iteration)))
+		beta := max(1-alpha, float32(iteration+1)/float32(scfg.Iterations))
+		solver.relaxRightToLeft(alpha, beta)
+		solver.relaxLeftToRight(alpha, beta)
 	}
 
-	// Step 5: Compute link positions with running Y offsets for stacking.
-	sourceOffsets := make([]float32, len(nodeNames))
-	targetOffsets := make([]float32, len(nodeNames))
-
-	links := make([]SankeyLinkLayout, len(graph.SankeyLinks))
-	for linkIdx, link := range graph.SankeyLinks {
-		si := nodeIndex[link.Source]
-		ti := nodeIndex[link.Target]
-
-		// Link width proportional to value relative to source node total flow.
-		linkH := float32(0)
-		if totalFlow[si] > 0 {
-			linkH = nodes[si].Height * float32(link.Value/totalFlow[si])
-		}
-		if linkH < 1 {
-			linkH = 1
-		}
-
-		links[linkIdx] = SankeyLinkLayout{
-			SourceIdx: si,
-			TargetIdx: ti,
-			Value:     link.Value,
-			SourceY:   nodes[si].Y + sourceOffsets[si],
-			TargetY:   nodes[ti].Y + targetOffsets[ti],
-			Width:     linkH,
-		}
-		sourceOffsets[si] += linkH
-		targetOffsets[ti] += linkH
-	}
-
-	totalW := chartW + padX*2
-	totalH := chartH + padY*2
+	// Step 5: Stack links at each node in the order of the nodes at their
+	// other ends, so they do not cross there.
+	solver.reorderLinks()
+	nodes, links := solver.result(nodeNames)
 
 	return &Layout{
-		Kind:    graph.Kind,
-		Nodes:   map[string]*NodeLayout{},
-		Width:   totalW,
-		Height:  totalH,
-		Diagram: SankeyData{Nodes: nodes, Links: links},
+		Kind:   graph.Kind,
+		Nodes:  map[string]*NodeLayout{},
+		Width:  scfg.ChartWidth + scfg.PaddingX*2,
+		Height: scfg.ChartHeight + scfg.PaddingY*2,
+		Diagram: SankeyData{
+			Nodes:      nodes,
+			Links:      links,
+			LinkColor:  linkColor,
+			ShowValues: showValues,
+		},
 	}
 }
 
@@ -153,6 +93,53 @@ func sankeyCollectNodes(links []*ir.SankeyLink) ([]string, map[string]int) {
 	return names, index
 }
 
+// sankeyBreakCycles returns the links in input order without those that
+// close a cycle: walking depth first from each node in order of first
+// appearance, a link back to a node still being walked, including a link
+// from a node to itself, is dropped.
+func sankeyBreakCycles(names []string, index map[string]int, links []*ir.SankeyLink) []*ir.SankeyLink {
+	outgoing := make([][]int, len(names))
+	for idx, link := range links {
+		source := index[link.Source]
+		outgoing[source] = append(outgoing[source], idx)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(names))
+	dropped := make([]bool, len(links))
+	var visit func(node int)
+	visit = func(node int) {
+		state[node] = visiting
+		for _, idx := range outgoing[node] {
+			target := index[links[idx].Target]
+			switch state[target] {
+			case visiting:
+				dropped[idx] = true
+			case unvisited:
+				visit(target)
+			}
+		}
+		state[node] = done
+	}
+	for node := range names {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+
+	kept := make([]*ir.SankeyLink, 0, len(links))
+	for idx, link := range links {
+		if !dropped[idx] {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
+
 // sankeyAssignColumns assigns each node to a column using the longest-path
 // algorithm: source nodes (no incoming links) get column 0, others get
 // max(source column) + 1.
@@ -168,9 +155,10 @@ func sankeyAssignColumns(names []string, index map[string]int, links []*ir.Sanke
 		incoming[ti] = append(incoming[ti], si)
 	}
 
-	// Iterative longest-path relaxation.
+	// Iterative longest-path relaxation. The links are acyclic, so it
+	// settles within one pass per node.
 	changed := true
-	for changed {
+	for pass := 0; changed && pass <= nodeCount; pass++ {
 		changed = false
 		for idx := range nodeCount {
 			for _, src := range incoming[idx] {
@@ -0,0 +1,410 @@
+package layout
+
+import (
+	"slices"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// sankeyRelaxDecay shrinks each relaxation pass's step, as d3-sankey does.
+const sankeyRelaxDecay = 0.99
+
+// sankeyMinShift is the smallest move collision resolution bothers with.
+const sankeyMinShift float32 = 1e-6
+
+// sankeyNode is a Sankey node while it is being placed. Y0 and Y1 are its
+// top and bottom edges.
+type sankeyNode struct {
+	index       int
+	value       float64
+	column      int
+	y0, y1      float32
+	sourceLinks []*sankeyLink
+	targetLinks []*sankeyLink
+}
+
+// sankeyLink is a Sankey link while it is being placed. Y0 and Y1 are its
+// top edges at the source and target nodes.
+type sankeyLink struct {
+	index          int
+	source, target *sankeyNode
+	value          float64
+	width          float32
+	y0, y1         float32
+}
+
+// sankeySolver places Sankey nodes in columns and relaxes them toward the
+// nodes they link to, following d3-sankey.
+type sankeySolver struct {
+	scfg    config.SankeyConfig
+	nodes   []*sankeyNode
+	links   []*sankeyLink
+	columns [][]*sankeyNode
+	// top and bottom bound the chart area; padding is the gap between
+	// nodes in a column, shrunk when a column would not otherwise fit.
+	top, bottom float32
+	padding     float32
+}
+
+func newSankeySolver(names []string, index map[string]int, flow []float64, links []*ir.SankeyLink, scfg config.SankeyConfig) *sankeySolver {
+	solver := &sankeySolver{
+		scfg:    scfg,
+		nodes:   make([]*sankeyNode, len(names)),
+		links:   make([]*sankeyLink, len(links)),
+		top:     scfg.PaddingY,
+		bottom:  scfg.PaddingY + scfg.ChartHeight,
+		padding: scfg.NodePadding,
+	}
+	for idx := range names {
+		solver.nodes[idx] = &sankeyNode{index: idx, value: flow[idx]}
+	}
+	for idx, link := range links {
+		source := solver.nodes[index[link.Source]]
+		target := solver.nodes[index[link.Target]]
+		sl := &sankeyLink{index: idx, source: source, target: target, value: link.Value}
+		solver.links[idx] = sl
+		source.sourceLinks = append(source.sourceLinks, sl)
+		target.targetLinks = append(target.targetLinks, sl)
+	}
+	return solver
+}
+
+// assignColumns puts each node in a column according to the alignment,
+// depths being each node's distance from a source.
+func (s *sankeySolver) assignColumns(alignment config.SankeyAlignment, depths []int) {
+	heights := s.sinkDistances()
+	columnCount := slices.Max(depths) + 1
+	for _, node := range s.nodes {
+		var column int
+		switch alignment {
+		case config.SankeyAlignLeft:
+			column = depths[node.index]
+		case config.SankeyAlignRight:
+			column = columnCount - 1 - heights[node.index]
+		case config.SankeyAlignCenter:
+			switch {
+			case len(node.targetLinks) > 0:
+				column = depths[node.index]
+			case len(node.sourceLinks) > 0:
+				column = columnCount
+				for _, link := range node.sourceLinks {
+					column = min(column, depths[link.target.index]-1)
+				}
+			}
+		default:
+			column = columnCount - 1
+			if len(node.sourceLinks) > 0 {
+				column = depths[node.index]
+			}
+		}
+		node.column = max(0, min(columnCount-1, column))
+	}
+
+	s.columns = make([][]*sankeyNode, columnCount)
+	for _, node := range s.nodes {
+		s.columns[node.column] = append(s.columns[node.column], node)
+	}
+}
+
+// sinkDistances returns each node's longest distance to a node without
+// outgoing links. The links are acyclic, so it settles within one pass per
+// node.
+func (s *sankeySolver) sinkDistances() []int {
+	heights := make([]int, len(s.nodes))
+	changed := true
+	for pass := 0; changed && pass <= len(s.nodes); pass++ {
+		changed = false
+		for _, link := range s.links {
+			if heights[link.target.index]+1 > heights[link.source.index] {
+				heights[link.source.index] = heights[link.target.index] + 1
+				changed = true
+			}
+		}
+	}
+	return heights
+}
+
+// initializeBreadths sizes nodes and links on one value scale, the largest
+// that fits every column, and stacks each column's nodes with the spare
+// height shared out between them.
+func (s *sankeySolver) initializeBreadths() {
+	longest := 0
+	for _, column := range s.columns {
+		longest = max(longest, len(column))
+	}
+	if longest > 1 {
+		s.padding = min(s.padding, s.scfg.ChartHeight/float32(longest-1))
+	}
+
+	var scale float32
+	scaled := false
+	for _, column := range s.columns {
+		var total float64
+		for _, node := range column {
+			total += node.value
+		}
+		if total <= 0 {
+			continue
+		}
+		columnScale := (s.scfg.ChartHeight - float32(len(column)-1)*s.padding) / float32(total)
+		if !scaled || columnScale < scale {
+			scale = columnScale
+			scaled = true
+		}
+	}
+
+	for _, link := range s.links {
+		link.width = float32(link.value) * scale
+	}
+	for _, column := range s.columns {
+		y := s.top
+		for _, node := range column {
+			node.y0 = y
+			node.y1 = y + float32(node.value)*scale
+			y = node.y1 + s.padding
+		}
+		spare := (s.bottom - y + s.padding) / float32(len(column)+1)
+		for idx, node := range column {
+			node.y0 += spare * float32(idx+1)
+			node.y1 += spare * float32(idx+1)
+		}
+	}
+	s.reorderLinks()
+}
+
+// relaxLeftToRight moves each node toward the weighted centre of the links
+// arriving at it, then resolves collisions in its column.
+func (s *sankeySolver) relaxLeftToRight(alpha, beta float32) {
+	for _, column := range s.columns[1:] {
+		if len(column) == 0 {
+			continue
+		}
+		for _, target := range column {
+			var sum, weight float32
+			for _, link := range target.targetLinks {
+				span := float32(link.value) * float32(target.column-link.source.column)
+				if span <= 0 {
+					continue
+				}
+				sum += s.targetTop(link.source, target) * span
+				weight += span
+			}
+			if weight <= 0 {
+				continue
+			}
+			s.shift(target, (sum/weight-target.y0)*alpha)
+		}
+		sortSankeyColumn(column)
+		s.resolveCollisions(column, beta)
+	}
+}
+
+// relaxRightToLeft moves each node toward the weighted centre of the links
+// leaving it, then resolves collisions in its column.
+func (s *sankeySolver) relaxRightToLeft(alpha, beta float32) {
+	for idx := len(s.columns) - 2; idx >= 0; idx-- { //nolint:mnd // the last column has no outgoing links.
+		column := s.columns[idx]
+		if len(column) == 0 {
+			continue
+		}
+		for _, source := range column {
+			var sum, weight float32
+			for _, link := range source.sourceLinks {
+				span := float32(link.value) * float32(link.target.column-source.column)
+				if span <= 0 {
+					continue
+				}
+				sum += s.sourceTop(source, link.target) * span
+				weight += span
+			}
+			if weight <= 0 {
+				continue
+			}
+			s.shift(source, (sum/weight-source.y0)*alpha)
+		}
+		sortSankeyColumn(column)
+		s.resolveCollisions(column, beta)
+	}
+}
+
+// shift moves a node and re-sorts the links at both of its ends.
+func (s *sankeySolver) shift(node *sankeyNode, dy float32) {
+	node.y0 += dy
+	node.y1 += dy
+	s.reorderNodeLinks(node)
+}
+
+// targetTop returns where target's top would be for the link from source
+// to run level, given the links stacked above it at each end.
+func (s *sankeySolver) targetTop(source, target *sankeyNode) float32 {
+	y := source.y0 - float32(len(source.sourceLinks)-1)*s.padding/2 //nolint:mnd // centre the spread.
+	for _, link := range source.sourceLinks {
+		if link.target == target {
+			break
+		}
+		y += link.width + s.padding
+	}
+	for _, link := range target.targetLinks {
+		if link.source == source {
+			break
+		}
+		y -= link.width
+	}
+	return y
+}
+
+// sourceTop returns where source's top would be for the link to target to
+// run level, given the links stacked above it at each end.
+func (s *sankeySolver) sourceTop(source, target *sankeyNode) float32 {
+	y := target.y0 - float32(len(target.targetLinks)-1)*s.padding/2 //nolint:mnd // centre the spread.
+	for _, link := range target.targetLinks {
+		if link.source == source {
+			break
+		}
+		y += link.width + s.padding
+	}
+	for _, link := range source.sourceLinks {
+		if link.target == target {
+			break
+		}
+		y -= link.width
+	}
+	return y
+}
+
+// sortSankeyColumn orders a column's nodes top to bottom.
+func sortSankeyColumn(column []*sankeyNode) {
+	slices.SortStableFunc(column, func(first, second *sankeyNode) int {
+		return compareFloat32(first.y0, second.y0)
+	})
+}
+
+// resolveCollisions pushes a column's nodes apart from its middle node
+// outward, then pulls them back inside the chart, each step by the share
+// alpha of the overlap.
+func (s *sankeySolver) resolveCollisions(column []*sankeyNode, alpha float32) {
+	if len(column) == 0 {
+		return
+	}
+	middle := len(column) / 2 //nolint:mnd // the middle node.
+	subject := column[middle]
+	s.collideUp(column, subject.y0-s.padding, middle-1, alpha)
+	s.collideDown(column, subject.y1+s.padding, middle+1, alpha)
+	s.collideUp(column, s.bottom, len(column)-1, alpha)
+	s.collideDown(column, s.top, 0, alpha)
+}
+
+// collideDown pushes nodes from start down below limit and each other.
+func (s *sankeySolver) collideDown(column []*sankeyNode, limit float32, start int, alpha float32) {
+	for idx := start; idx < len(column); idx++ {
+		node := column[idx]
+		if dy := (limit - node.y0) * alpha; dy > sankeyMinShift {
+			node.y0 += dy
+			node.y1 += dy
+		}
+		limit = node.y1 + s.padding
+	}
+}
+
+// collideUp pushes nodes from start up above limit and each other.
+func (s *sankeySolver) collideUp(column []*sankeyNode, limit float32, start int, alpha float32) {
+	for idx := start; idx >= 0; idx-- {
+		node := column[idx]
+		if dy := (node.y1 - limit) * alpha; dy > sankeyMinShift {
+			node.y0 -= dy
+			node.y1 -= dy
+		}
+		limit = node.y0 - s.padding
+	}
+}
+
+// reorderLinks sorts the links at every node by the position of the node
+// at their other end.
+func (s *sankeySolver) reorderLinks() {
+	for _, node := range s.nodes {
+		sortSankeyLinks(node)
+	}
+}
+
+// reorderNodeLinks sorts the links at a node and at its neighbours.
+func (s *sankeySolver) reorderNodeLinks(node *sankeyNode) {
+	sortSankeyLinks(node)
+	for _, link := range node.targetLinks {
+		sortSankeyLinks(link.source)
+	}
+	for _, link := range node.sourceLinks {
+		sortSankeyLinks(link.target)
+	}
+}
+
+func sortSankeyLinks(node *sankeyNode) {
+	slices.SortStableFunc(node.sourceLinks, func(first, second *sankeyLink) int {
+		if order := compareFloat32(first.target.y0, second.target.y0); order != 0 {
+			return order
+		}
+		return first.index - second.index
+	})
+	slices.SortStableFunc(node.targetLinks, func(first, second *sankeyLink) int {
+		if order := compareFloat32(first.source.y0, second.source.y0); order != 0 {
+			return order
+		}
+		return first.index - second.index
+	})
+}
+
+func compareFloat32(first, second float32) int {
+	switch {
+	case first < second:
+		return -1
+	case first > second:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// result stacks the links at each node and returns the placed nodes and
+// links in their input order.
+func (s *sankeySolver) result(names []string) ([]SankeyNodeLayout, []SankeyLinkLayout) {
+	columnCount := len(s.columns)
+	var columnStep float32
+	if columnCount > 1 {
+		columnStep = (s.scfg.ChartWidth - s.scfg.NodeWidth) / float32(columnCount-1)
+	}
+
+	nodes := make([]SankeyNodeLayout, len(s.nodes))
+	for _, node := range s.nodes {
+		sourceY, targetY := node.y0, node.y0
+		for _, link := range node.sourceLinks {
+			link.y0 = sourceY
+			sourceY += link.width
+		}
+		for _, link := range node.targetLinks {
+			link.y1 = targetY
+			targetY += link.width
+		}
+		nodes[node.index] = SankeyNodeLayout{
+			Label:      names[node.index],
+			Value:      node.value,
+			X:          s.scfg.PaddingX + float32(node.column)*columnStep,
+			Y:          node.y0,
+			Width:      s.scfg.NodeWidth,
+			Height:     node.y1 - node.y0,
+			ColorIndex: node.index,
+		}
+	}
+
+	links := make([]SankeyLinkLayout, len(s.links))
+	for idx, link := range s.links {
+		links[idx] = SankeyLinkLayout{
+			SourceIdx: link.source.index,
+			TargetIdx: link.target.index,
+			Value:     link.value,
+			SourceY:   link.y0,
+			TargetY:   link.y1,
+			Width:     link.width,
+		}
+	}
+	return nodes, links
+}
@@ -99,6 +99,72 @@ func TestSankeyAssignColumns(t *testing.T) {
 	}
 }
 
+func TestSankeyBreakCycles(t *testing.T) {
+	links := []*ir.SankeyLink{
+		{Source: "A", Target: "B", Value: 10},
+		{Source: "B", Target: "C", Value: 5},
+		{Source: "C", Target: "A", Value: 2},
+		{Source: "C", Target: "C", Value: 1},
+		{Source: "A", Target: "C", Value: 3},
+	}
+
+	names, index := sankeyCollectNodes(links)
+	kept := sankeyBreakCycles(names, index, links)
+
+	// C->A and the C->C self-loop close cycles; the rest stay in order.
+	want := []*ir.SankeyLink{links[0], links[1], links[4]}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %d links, want %d", len(kept), len(want))
+	}
+	for idx := range want {
+		if kept[idx] != want[idx] {
+			t.Errorf("kept[%d] = %+v, want %+v", idx, kept[idx], want[idx])
+		}
+	}
+}
+
+func TestSankeyLayoutCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		links     []*ir.SankeyLink
+		wantNodes int
+		wantLinks int
+	}{
+		{
+			name:      "self-loop",
+			links:     []*ir.SankeyLink{{Source: "a", Target: "a", Value: 3}},
+			wantNodes: 1,
+			wantLinks: 0,
+		},
+		{
+			name: "two-node cycle",
+			links: []*ir.SankeyLink{
+				{Source: "a", Target: "b", Value: 10},
+				{Source: "b", Target: "a", Value: 5},
+			},
+			wantNodes: 2,
+			wantLinks: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := ir.NewGraph()
+			graph.Kind = ir.Sankey
+			graph.SankeyLinks = tt.links
+
+			lay := ComputeLayout(graph, theme.Modern(), config.DefaultLayout())
+			data, ok := lay.Diagram.(SankeyData)
+			if !ok {
+				t.Fatalf("Diagram = %T, want SankeyData", lay.Diagram)
+			}
+			if len(data.Nodes) != tt.wantNodes || len(data.Links) != tt.wantLinks {
+				t.Errorf("got %d nodes and %d links, want %d and %d",
+					len(data.Nodes), len(data.Links), tt.wantNodes, tt.wantLinks)
+			}
+		})
+	}
+}
+
 func TestSankeyComputeFlow(t *testing.T) {
 	links := []*ir.SankeyLink{
 		{Source: "A", Target: "X", Value: 100},
@@ -156,3 +222,118 @@ func TestSankeyLinkPositions(t *testing.T) {
 		}
 	}
 }
+
+// sankeyChain links A to B to C, with a second source D feeding C.
+func sankeyChain() *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sankey
+	graph.SankeyLinks = []*ir.SankeyLink{
+		{Source: "A", Target: "B", Value: 10},
+		{Source: "B", Target: "C", Value: 10},
+		{Source: "D", Target: "C", Value: 5},
+		{Source: "A", Target: "E", Value: 5},
+	}
+	return graph
+}
+
+func TestSankeyNodeAlignment(t *testing.T) {
+	// Nodes in order of appearance: A, B, C, D, E.
+	tests := []struct {
+		alignment config.SankeyAlignment
+		columns   []int
+	}{
+		{config.SankeyAlignJustify, []int{0, 1, 2, 0, 2}},
+		{config.SankeyAlignLeft, []int{0, 1, 2, 0, 1}},
+		{config.SankeyAlignRight, []int{0, 1, 2, 1, 2}},
+		{config.SankeyAlignCenter, []int{0, 1, 2, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alignment), func(t *testing.T) {
+			cfg := config.DefaultLayout()
+			cfg.Sankey.NodeAlignment = tt.alignment
+			sd, ok := computeSankeyLayout(sankeyChain(), theme.Modern(), cfg).Diagram.(SankeyData)
+			if !ok {
+				t.Fatal("expected SankeyData")
+			}
+			step := (cfg.Sankey.ChartWidth - cfg.Sankey.NodeWidth) / 2
+			for idx, column := range tt.columns {
+				want := cfg.Sankey.PaddingX + float32(column)*step
+				if sd.Nodes[idx].X != want {
+					t.Errorf("%s.X = %v, want column %d at %v", sd.Nodes[idx].Label, sd.Nodes[idx].X, column, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSankeyNodeAlignmentDirective(t *testing.T) {
+	graph := sankeyChain()
+	graph.SankeyNodeAlignment = "left"
+	cfg := config.DefaultLayout()
+	sd, ok := computeSankeyLayout(graph, theme.Modern(), cfg).Diagram.(SankeyData)
+	if !ok {
+		t.Fatal("expected SankeyData")
+	}
+	// Left alignment keeps E next to its source, not in the last column.
+	if sd.Nodes[4].X >= sd.Nodes[2].X {
+		t.Errorf("E.X = %v, want left of C at %v", sd.Nodes[4].X, sd.Nodes[2].X)
+	}
+}
+
+func TestSankeyUniformScale(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sankey
+	graph.SankeyLinks = []*ir.SankeyLink{
+		{Source: "A", Target: "X", Value: 30},
+		{Source: "B", Target: "X", Value: 10},
+		{Source: "X", Target: "Y", Value: 40},
+	}
+	cfg := config.DefaultLayout()
+	sd, ok := computeSankeyLayout(graph, theme.Modern(), cfg).Diagram.(SankeyData)
+	if !ok {
+		t.Fatal("expected SankeyData")
+	}
+	// One scale across columns: link widths match at both ends and node
+	// heights are proportional to their values.
+	// Nodes in order of appearance: A, X, B, Y.
+	scale := sd.Nodes[1].Height / 40
+	for _, link := range sd.Links {
+		if diff := link.Width - float32(link.Value)*scale; diff > 0.01 || diff < -0.01 {
+			t.Errorf("link %d width = %v, want %v", link.SourceIdx, link.Width, float32(link.Value)*scale)
+		}
+	}
+	if diff := sd.Nodes[0].Height - 3*sd.Nodes[2].Height; diff > 0.01 || diff < -0.01 {
+		t.Errorf("A height = %v, want three times B's %v", sd.Nodes[0].Height, sd.Nodes[2].Height)
+	}
+	for _, node := range sd.Nodes {
+		if node.Y < cfg.Sankey.PaddingY-0.01 || node.Y+node.Height > cfg.Sankey.PaddingY+cfg.Sankey.ChartHeight+0.01 {
+			t.Errorf("%s spans %v..%v, outside the chart", node.Label, node.Y, node.Y+node.Height)
+		}
+	}
+}
+
+func TestSankeyRelaxationUncrosses(t *testing.T) {
+	// In appearance order P is above Q and Low above High, but P feeds
+	// High and Q feeds Low, so relaxation has to swap one pair.
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sankey
+	graph.SankeyLinks = []*ir.SankeyLink{
+		{Source: "P", Target: "Low", Value: 1},
+		{Source: "P", Target: "High", Value: 20},
+		{Source: "Q", Target: "Low", Value: 20},
+	}
+	cfg := config.DefaultLayout()
+	sd, ok := computeSankeyLayout(graph, theme.Modern(), cfg).Diagram.(SankeyData)
+	if !ok {
+		t.Fatal("expected SankeyData")
+	}
+	// Nodes in order of appearance: P, Low, High, Q.
+	pY, qY := sd.Nodes[0].Y, sd.Nodes[3].Y
+	lowY, highY := sd.Nodes[1].Y, sd.Nodes[2].Y
+	if (pY < qY) != (highY < lowY) {
+		t.Errorf("P/Q at %v/%v and High/Low at %v/%v cross", pY, qY, highY, lowY)
+	}
+	if !sd.ShowValues {
+		t.Error("ShowValues = false, want the default true")
+	}
+}
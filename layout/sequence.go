@@ -2,6 +2,7 @@ package layout
 
 import (
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
@@ -22,11 +23,13 @@ func computeSequenceLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 
 	// Phase 1: Measure participants and assign horizontal positions.
 	pInfos, pIndex := seqMeasureParticipants(graph, measurer, th, sc, lineH, padH)
+	texts := seqEventTexts(graph, measurer, th, sc, lineH, padH)
+	rightReach := seqPlaceParticipants(graph, texts, pInfos, pIndex, sc, padH)
 
 	// Phase 2: Walk events top-to-bottom.
 	eventY := sc.HeaderHeight + padV
 	messages, notes, activations, frames, eventY := seqProcessEvents(
-		graph, measurer, th, sc, lineH, padH, padV, pInfos, pIndex, eventY,
+		graph, texts, sc, lineH, padH, padV, pInfos, pIndex, eventY,
 	)
 
 	// Phase 3: Finalize.
@@ -46,6 +49,10 @@ func computeSequenceLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 			Y:      pi.y,
 			Width:  pi.w,
 			Height: pi.h,
+
+			Links:      pi.links,
+			Properties: pi.properties,
+			MenuWidth:  pi.menuW,
 		}
 		lifelines[idx] = SeqLifeline{
 			ParticipantID: pi.id,
@@ -60,7 +67,7 @@ func computeSequenceLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout)
 	rightEdge := float32(0)
 	if len(pInfos) > 0 {
 		last := pInfos[len(pInfos)-1]
-		rightEdge = last.x + last.w/2 + padH
+		rightEdge = last.x + rightReach + padH
 	}
 
 	return &Layout{
@@ -91,6 +98,10 @@ type seqParticipantInfo struct {
 	y     float32 // top Y (0 for normal, set later for created)
 	w     float32
 	h     float32
+
+	links      []ir.SeqLink
+	properties map[string]string
+	menuW      float32 // link menu width
 }
 
 func seqMeasureParticipants(
@@ -103,34 +114,247 @@ func seqMeasureParticipants(
 	pInfos := make([]seqParticipantInfo, len(graph.Participants))
 	pIndex := make(map[string]int, len(graph.Participants))
 
-	cursorX := padH
 	for idx, participant := range graph.Participants {
 		name := participant.DisplayName()
 		tw := measurer.Width(name, th.FontSize, th.FontFamily)
 		partW := tw + 2*padH
 		partH := sc.HeaderHeight
 
+		menuW := partW
+		for _, link := range participant.Links {
+			menuW = max(menuW, measurer.Width(link.Label, th.FontSize, th.FontFamily)+2*padH)
+		}
+
 		pInfos[idx] = seqParticipantInfo{
-			id:    participant.ID,
-			label: TextBlock{Lines: []string{name}, Width: tw, Height: lineH, FontSize: th.FontSize},
-			kind:  participant.Kind,
-			x:     cursorX + partW/2,
-			y:     0,
-			w:     partW,
-			h:     partH,
+			id:         participant.ID,
+			label:      TextBlock{Lines: []string{name}, Width: tw, Height: lineH, FontSize: th.FontSize},
+			kind:       participant.Kind,
+			y:          0,
+			w:          partW,
+			h:          partH,
+			links:      participant.Links,
+			properties: participant.Properties,
+			menuW:      menuW,
 		}
 		pIndex[participant.ID] = idx
-		cursorX += partW + sc.ParticipantSpacing
 	}
 	return pInfos, pIndex
 }
 
+// seqEventTexts measures the text of every message and note, wrapping it
+// where the event or the diagram asks for it. The result is indexed like
+// graph.Events; other events get an empty block.
+func seqEventTexts(
+	graph *ir.Graph,
+	measurer *textmetrics.Measurer,
+	th *theme.Theme,
+	sc config.SequenceConfig,
+	lineH, padH float32,
+) []TextBlock {
+	wrapDefault := graph.SeqWrap || sc.Wrap
+	texts := make([]TextBlock, len(graph.Events))
+	for idx, ev := range graph.Events {
+		switch {
+		case ev.Kind == ir.EvMessage && ev.Message != nil:
+			texts[idx] = seqTextBlock(ev.Message.Text, ev.Message.Wrap.Wraps(wrapDefault), sc.WrapWidth,
+				measurer, th, lineH)
+		case ev.Kind == ir.EvNote && ev.Note != nil:
+			texts[idx] = seqTextBlock(ev.Note.Text, ev.Note.Wrap.Wraps(wrapDefault), sc.NoteMaxWidth-2*padH,
+				measurer, th, lineH)
+		}
+	}
+	return texts
+}
+
+// seqTextBlock measures text split at line breaks and, when wrap is set,
+// at word boundaries so that no line is wider than maxW.
+func seqTextBlock(
+	text string,
+	wrap bool,
+	maxW float32,
+	measurer *textmetrics.Measurer,
+	th *theme.Theme,
+	lineH float32,
+) TextBlock {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		if wrap {
+			lines = append(lines, seqWrapWords(para, maxW, measurer, th)...)
+		} else {
+			lines = append(lines, para)
+		}
+	}
+	maxLineW := float32(0)
+	for _, line := range lines {
+		maxLineW = max(maxLineW, measurer.Width(line, th.FontSize, th.FontFamily))
+	}
+	return TextBlock{Lines: lines, Width: maxLineW, Height: float32(len(lines)) * lineH, FontSize: th.FontSize}
+}
+
+// seqWrapWords breaks a line greedily at spaces so that each piece fits
+// within maxW. Words wider than maxW on their own are split between
+// characters.
+func seqWrapWords(text string, maxW float32, measurer *textmetrics.Measurer, th *theme.Theme) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	fits := func(candidate string) bool {
+		return measurer.Width(candidate, th.FontSize, th.FontFamily) <= maxW
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		if current != "" && fits(current+" "+word) {
+			current += " " + word
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		current = ""
+		for _, ch := range word {
+			if current != "" && !fits(current+string(ch)) {
+				lines = append(lines, current)
+				current = ""
+			}
+			current += string(ch)
+		}
+	}
+	return append(lines, current)
+}
+
+// seqNoteWidth returns the box width of a note holding text. Wrapped
+// notes already fit NoteMaxWidth; unwrapped ones grow with their text so
+// that it never spills out of the box.
+func seqNoteWidth(text TextBlock, padH float32) float32 {
+	return text.Width + 2*padH
+}
+
+// seqSpan asks for at least need between the centres of participants lo
+// and hi.
+type seqSpan struct {
+	lo, hi int
+	need   float32
+}
+
+// seqPlaceParticipants assigns participant centres. Each gap starts at
+// ParticipantSpacing and grows until the messages and notes drawn between
+// two participants fit. It returns how far the diagram reaches beyond the
+// centre of the last participant.
+func seqPlaceParticipants(
+	graph *ir.Graph,
+	texts []TextBlock,
+	pInfos []seqParticipantInfo,
+	pIndex map[string]int,
+	sc config.SequenceConfig,
+	padH float32,
+) float32 {
+	count := len(pInfos)
+	if count == 0 {
+		return 0
+	}
+	gaps := make([]float32, count-1)
+	for idx := range gaps {
+		gaps[idx] = sc.ParticipantSpacing
+	}
+	leftReach, rightReach := pInfos[0].w/2, pInfos[count-1].w/2
+
+	var spans []seqSpan
+	// beside asks for room of extent to one side of participant idx; at
+	// either end of the diagram the outer margin grows instead.
+	beside := func(idx int, right bool, extent float32) {
+		switch {
+		case right && idx < count-1:
+			spans = append(spans, seqSpan{lo: idx, hi: idx + 1, need: extent + padH})
+		case right:
+			rightReach = max(rightReach, extent)
+		case idx > 0:
+			spans = append(spans, seqSpan{lo: idx - 1, hi: idx, need: extent + padH})
+		default:
+			leftReach = max(leftReach, extent)
+		}
+	}
+
+	for evIdx, ev := range graph.Events {
+		switch {
+		case ev.Kind == ir.EvMessage && ev.Message != nil:
+			fromIdx, fromOK := pIndex[ev.Message.From]
+			toIdx, toOK := pIndex[ev.Message.To]
+			if !fromOK || !toOK {
+				continue
+			}
+			textW := texts[evIdx].Width
+			if fromIdx == toIdx {
+				// Self-message labels are centred on the loop, so a wide
+				// label reaches past the lifeline on both sides.
+				beside(fromIdx, true, max(sc.SelfMessageWidth, sc.SelfMessageWidth/2+textW/2)) //nolint:mnd // half the loop and half the label.
+				if left := textW/2 - sc.SelfMessageWidth/2; left > 0 {                         //nolint:mnd // half the label and half the loop.
+					beside(fromIdx, false, left)
+				}
+				continue
+			}
+			spans = append(spans, seqSpan{lo: min(fromIdx, toIdx), hi: max(fromIdx, toIdx), need: textW + 2*padH})
+
+		case ev.Kind == ir.EvNote && ev.Note != nil && len(ev.Note.Participants) > 0:
+			note := ev.Note
+			noteW := seqNoteWidth(texts[evIdx], padH)
+			first := pIndex[note.Participants[0]]
+			switch {
+			case note.Position == ir.NoteRight:
+				beside(first, true, pInfos[first].w/2+padH+noteW)
+			case note.Position == ir.NoteLeft:
+				beside(first, false, pInfos[first].w/2+padH+noteW)
+			case len(note.Participants) >= 2:
+				second := pIndex[note.Participants[1]]
+				lo, hi := min(first, second), max(first, second)
+				if lo == hi {
+					beside(lo, true, noteW/2)  //nolint:mnd // notes are centred.
+					beside(lo, false, noteW/2) //nolint:mnd // notes are centred.
+					continue
+				}
+				spans = append(spans, seqSpan{lo: lo, hi: hi, need: noteW - pInfos[lo].w/2 - pInfos[hi].w/2})
+			default:
+				beside(first, true, noteW/2)  //nolint:mnd // notes are centred.
+				beside(first, false, noteW/2) //nolint:mnd // notes are centred.
+			}
+		}
+	}
+
+	// Fit neighbouring pairs first so that wide spans only add what the
+	// narrower ones inside them have not already provided.
+	sort.SliceStable(spans, func(left, right int) bool {
+		return spans[left].hi-spans[left].lo < spans[right].hi-spans[right].lo
+	})
+	centreDistance := func(lo, hi int) float32 {
+		dist := float32(0)
+		for idx := lo; idx < hi; idx++ {
+			dist += pInfos[idx].w/2 + gaps[idx] + pInfos[idx+1].w/2
+		}
+		return dist
+	}
+	for _, span := range spans {
+		if short := span.need - centreDistance(span.lo, span.hi); short > 0 {
+			gaps[span.hi-1] += short
+		}
+	}
+
+	cursorX := padH + leftReach - pInfos[0].w/2
+	for idx := range pInfos {
+		pInfos[idx].x = cursorX + pInfos[idx].w/2
+		if idx < len(gaps) {
+			cursorX += pInfos[idx].w + gaps[idx]
+		}
+	}
+	return rightReach
+}
+
 // seqProcessEvents walks events top-to-bottom, building messages, notes,
 // activations, and frames. Returns the updated Y cursor.
 func seqProcessEvents( //nolint:revive,funlen // event processing switch is inherently complex; 5 return values needed for distinct event types
 	graph *ir.Graph,
-	measurer *textmetrics.Measurer,
-	th *theme.Theme,
+	texts []TextBlock,
 	sc config.SequenceConfig,
 	lineH, padH, padV float32,
 	pInfos []seqParticipantInfo,
@@ -152,11 +376,13 @@ func seqProcessEvents( //nolint:revive,funlen // event processing switch is inhe
 	var frames []SeqFrameLayout
 	msgNumber := 0
 
-	for _, ev := range graph.Events {
+	for evIdx, ev := range graph.Events {
 		switch ev.Kind {
 		case ir.EvMessage:
 			msg := ev.Message
-			eventY += sc.MessageSpacing
+			text := texts[evIdx]
+			// Extra label lines stack upwards from the arrow.
+			eventY += sc.MessageSpacing + max(text.Height-lineH, 0)
 
 			fromIdx, fromOK := pIndex[msg.From]
 			toIdx, toOK := pIndex[msg.To]
@@ -170,7 +396,6 @@ func seqProcessEvents( //nolint:revive,funlen // event processing switch is inhe
 				toX = fromX + sc.SelfMessageWidth
 			}
 
-			tw := measurer.Width(msg.Text, th.FontSize, th.FontFamily)
 			msgNumber++
 			num := 0
 			if graph.Autonumber {
@@ -180,7 +405,7 @@ func seqProcessEvents( //nolint:revive,funlen // event processing switch is inhe
 			messages = append(messages, SeqMessageLayout{
 				From:   msg.From,
 				To:     msg.To,
-				Text:   TextBlock{Lines: []string{msg.Text}, Width: tw, Height: lineH, FontSize: th.FontSize},
+				Text:   text,
 				Kind:   msg.Kind,
 				Y:      eventY,
 				FromX:  fromX,
@@ -190,22 +415,12 @@ func seqProcessEvents( //nolint:revive,funlen // event processing switch is inhe
 
 		case ir.EvNote:
 			note := ev.Note
-			noteLines := strings.Split(note.Text, "\n")
-			maxLineW := float32(0)
-			for _, ln := range noteLines {
-				lw := measurer.Width(ln, th.FontSize, th.FontFamily)
-				if lw > maxLineW {
-					maxLineW = lw
-				}
-			}
-			noteW := maxLineW + 2*padH
-			if noteW > sc.NoteMaxWidth {
-				noteW = sc.NoteMaxWidth
-			}
-			noteH := float32(len(noteLines))*lineH + 2*padV
+			text := texts[evIdx]
+			noteW := seqNoteWidth(text, padH)
+			noteH := text.Height + 2*padV
 			noteX := seqNoteX(note, pInfos, pIndex, padH, noteW)
 			notes = append(notes, SeqNoteLayout{
-				Text:   TextBlock{Lines: noteLines, Width: maxLineW, Height: float32(len(noteLines)) * lineH, FontSize: th.FontSize},
+				Text:   text,
 				X:      noteX,
 				Y:      eventY,
 				Width:  noteW,
@@ -0,0 +1,237 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// seqMarkerBaseline places a page marker baseline within its text line.
+const seqMarkerBaseline = 0.75
+
+// SplitSequence splits a sequence diagram layout into pages no taller than
+// maxHeight. Pages break between events, away from the inside of frames
+// where possible. Every page repeats the participant headers and footers;
+// activations and frames cut by a break continue on the next page, and
+// page markers say where the diagram continues. Other layouts, and
+// sequence diagrams that already fit, are returned as a single page.
+func SplitSequence(computed *Layout, th *theme.Theme, cfg *config.Layout, maxHeight float32) []*Layout {
+	sd, ok := computed.Diagram.(SequenceData)
+	if !ok || maxHeight <= 0 || computed.Height <= maxHeight || len(sd.Lifelines) == 0 {
+		return []*Layout{computed}
+	}
+
+	sc := cfg.Sequence
+	padV := cfg.Padding.NodeVertical
+	lineH := th.FontSize * cfg.LabelLineHeight
+	contentTop := sc.HeaderHeight + padV
+	contentEnd := sd.Lifelines[0].BottomY - padV
+	// Room for headers, footers and a marker line above and below.
+	avail := maxHeight - contentTop - 2*padV - sc.HeaderHeight - 2*(lineH+sc.FramePadding)
+
+	bounds := seqPageBounds(seqEventTops(&sd, sc), sd.Frames, sc, contentTop, contentEnd, avail)
+	if len(bounds) <= 2 { //nolint:mnd // one page is a single top and bottom.
+		return []*Layout{computed}
+	}
+
+	pages := make([]*Layout, 0, len(bounds)-1)
+	for idx := range len(bounds) - 1 {
+		pages = append(pages, seqPage(computed, &sd, cfg, lineH, bounds[idx], bounds[idx+1], idx+1, len(bounds)-1))
+	}
+	return pages
+}
+
+// seqEventTops returns the sorted Y positions at which messages and notes
+// begin. These are the places where a page may break.
+func seqEventTops(sd *SequenceData, sc config.SequenceConfig) []float32 {
+	tops := make([]float32, 0, len(sd.Messages)+len(sd.Notes))
+	for _, msg := range sd.Messages {
+		tops = append(tops, seqMessageTop(msg, sc))
+	}
+	for _, note := range sd.Notes {
+		tops = append(tops, note.Y)
+	}
+	sort.Slice(tops, func(left, right int) bool { return tops[left] < tops[right] })
+	return tops
+}
+
+// seqMessageTop returns the Y position where a message's slot begins: its
+// arrow sits MessageSpacing below, plus one line for each extra label line.
+func seqMessageTop(msg SeqMessageLayout, sc config.SequenceConfig) float32 {
+	extra := float32(0)
+	if lines := len(msg.Text.Lines); lines > 1 {
+		extra = msg.Text.Height / float32(lines) * float32(lines-1)
+	}
+	return msg.Y - sc.MessageSpacing - extra
+}
+
+// seqPageBounds chooses page breaks from the event tops. It returns the
+// content range of every page as consecutive bounds, starting at
+// contentTop and ending at contentEnd. Each page is filled to at most
+// avail, preferring breaks inside as few frames as possible among those
+// in the lower half of the page.
+func seqPageBounds(
+	tops []float32,
+	frames []SeqFrameLayout,
+	sc config.SequenceConfig,
+	contentTop, contentEnd, avail float32,
+) []float32 {
+	depth := func(breakY float32) int {
+		count := 0
+		for _, frame := range frames {
+			if frame.Y < breakY && breakY < frame.Y+frame.Height-sc.FramePadding {
+				count++
+			}
+		}
+		return count
+	}
+
+	bounds := []float32{contentTop}
+	start := contentTop
+	for contentEnd-start > avail {
+		best, bestDepth := float32(-1), 0
+		for _, top := range tops {
+			switch {
+			case top <= start:
+				continue
+			case top-start > avail:
+				if best < 0 {
+					// A single event taller than a page gets one to itself.
+					best = top
+				}
+			case top-start < avail/2: //nolint:mnd // frames only matter in the lower half of the page.
+				best, bestDepth = top, depth(top)
+			default:
+				level := depth(top)
+				if best-start < avail/2 || level <= bestDepth { //nolint:mnd // see above.
+					best, bestDepth = top, level
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds, best)
+		start = best
+	}
+	return append(bounds, contentEnd)
+}
+
+// seqPage builds page number page of count, showing the events whose slots
+// begin in [top, bottom).
+func seqPage(
+	computed *Layout,
+	sd *SequenceData,
+	cfg *config.Layout,
+	lineH, top, bottom float32,
+	page, count int,
+) *Layout {
+	sc := cfg.Sequence
+	padV := cfg.Padding.NodeVertical
+	contentTop := sc.HeaderHeight + padV
+
+	// Marker lines keep clear of frames cut by the break.
+	markerRoom := lineH + sc.FramePadding
+	var markers []SeqPageMarker
+	shift := contentTop - top
+	if page > 1 {
+		markers = append(markers, SeqPageMarker{
+			Text: fmt.Sprintf("continued from page %d", page-1),
+			X:    computed.Width / 2, //nolint:mnd // centred.
+			Y:    contentTop + lineH*seqMarkerBaseline,
+		})
+		shift += markerRoom
+	}
+	footerY := bottom + shift + padV
+	if page < count {
+		markers = append(markers, SeqPageMarker{
+			Text: fmt.Sprintf("continued on page %d", page+1),
+			X:    computed.Width / 2, //nolint:mnd // centred.
+			Y:    bottom + shift + sc.FramePadding + lineH*seqMarkerBaseline,
+		})
+		footerY += markerRoom
+	}
+	diagramH := footerY + sc.HeaderHeight + padV
+	inPage := func(y float32) bool { return y >= top && y < bottom }
+
+	out := SequenceData{
+		Autonumber:    sd.Autonumber,
+		DiagramHeight: diagramH,
+		PageMarkers:   markers,
+	}
+
+	for _, participant := range sd.Participants {
+		switch {
+		case participant.Y >= bottom:
+			// Created further down the diagram.
+			continue
+		case participant.Y > 0 && participant.Y >= top:
+			participant.Y += shift
+		default:
+			participant.Y = 0
+		}
+		out.Participants = append(out.Participants, participant)
+		out.Lifelines = append(out.Lifelines, SeqLifeline{
+			ParticipantID: participant.ID,
+			X:             participant.X,
+			TopY:          participant.Y + participant.Height,
+			BottomY:       footerY,
+		})
+	}
+
+	for _, msg := range sd.Messages {
+		if inPage(seqMessageTop(msg, sc)) {
+			msg.Y += shift
+			out.Messages = append(out.Messages, msg)
+		}
+	}
+	for _, note := range sd.Notes {
+		if inPage(note.Y) {
+			note.Y += shift
+			out.Notes = append(out.Notes, note)
+		}
+	}
+
+	for _, act := range sd.Activations {
+		open := act.TopY < bottom && act.BottomY > top
+		if !open && !(act.TopY == act.BottomY && inPage(act.TopY)) {
+			continue
+		}
+		act.Continued = page > 1 && act.TopY <= top
+		act.TopY = max(act.TopY, top) + shift
+		act.BottomY = min(act.BottomY, bottom) + shift
+		out.Activations = append(out.Activations, act)
+	}
+
+	for _, frame := range sd.Frames {
+		frameBottom := frame.Y + frame.Height
+		if frame.Y >= bottom || frameBottom-sc.FramePadding <= top {
+			continue
+		}
+		frame.Continued = frame.Y < top
+		frame.Y = max(frame.Y, top)
+		frameBottom = min(frameBottom, bottom+sc.FramePadding)
+		var dividers []float32
+		for _, divY := range frame.Dividers {
+			if divY > frame.Y && divY < frameBottom {
+				dividers = append(dividers, divY+shift)
+			}
+		}
+		frame.Dividers = dividers
+		frame.Height = frameBottom - frame.Y
+		frame.Y += shift
+		out.Frames = append(out.Frames, frame)
+	}
+
+	for _, box := range sd.Boxes {
+		box.Height = diagramH
+		out.Boxes = append(out.Boxes, box)
+	}
+
+	pageLayout := *computed
+	pageLayout.Height = diagramH
+	pageLayout.Diagram = out
+	return &pageLayout
+}
@@ -0,0 +1,114 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// longSequence returns a sequence diagram with count messages between A
+// and B. B is activated for the whole diagram and the messages from
+// frameFrom up to frameTo sit inside a loop.
+func longSequence(count, frameFrom, frameTo int) *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{{ID: "A"}, {ID: "B"}}
+	graph.Events = append(graph.Events, &ir.SeqEvent{Kind: ir.EvActivate, Target: "B"})
+	for idx := range count {
+		if idx == frameFrom {
+			graph.Events = append(graph.Events, &ir.SeqEvent{Kind: ir.EvFrameStart, Frame: &ir.SeqFrame{Kind: ir.FrameLoop, Label: "retry"}})
+		}
+		graph.Events = append(graph.Events, &ir.SeqEvent{
+			Kind:    ir.EvMessage,
+			Message: &ir.SeqMessage{From: "A", To: "B", Text: "ping", Kind: ir.MsgSolidArrow},
+		})
+		if idx == frameTo-1 {
+			graph.Events = append(graph.Events, &ir.SeqEvent{Kind: ir.EvFrameEnd})
+		}
+	}
+	graph.Events = append(graph.Events, &ir.SeqEvent{Kind: ir.EvDeactivate, Target: "B"})
+	return graph
+}
+
+func TestSplitSequence(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	computed := ComputeLayout(longSequence(30, 100, 100), th, cfg)
+
+	if pages := SplitSequence(computed, th, cfg, 0); len(pages) != 1 || pages[0] != computed {
+		t.Errorf("SplitSequence() with no limit = %d pages, want the layout itself", len(pages))
+	}
+
+	const maxHeight = 400
+	pages := SplitSequence(computed, th, cfg, maxHeight)
+	if len(pages) < 3 {
+		t.Fatalf("pages = %d, want at least 3", len(pages))
+	}
+	messages := 0
+	for idx, page := range pages {
+		sd := page.Diagram.(SequenceData)
+		if page.Height > maxHeight {
+			t.Errorf("page %d height = %v, want at most %v", idx+1, page.Height, maxHeight)
+		}
+		if len(sd.Participants) != 2 || sd.DiagramHeight != page.Height {
+			t.Errorf("page %d: %d participants, diagram height %v", idx+1, len(sd.Participants), sd.DiagramHeight)
+		}
+		for _, msg := range sd.Messages {
+			if msg.Y <= cfg.Sequence.HeaderHeight || msg.Y >= sd.Lifelines[0].BottomY {
+				t.Errorf("page %d: message at %v outside lifelines", idx+1, msg.Y)
+			}
+		}
+		messages += len(sd.Messages)
+
+		if len(sd.Activations) != 1 || sd.Activations[0].Continued != (idx > 0) {
+			t.Errorf("page %d activations = %+v, want one, continued after the first page", idx+1, sd.Activations)
+		}
+		wantMarkers := 2
+		if idx == 0 || idx == len(pages)-1 {
+			wantMarkers = 1
+		}
+		if len(sd.PageMarkers) != wantMarkers {
+			t.Errorf("page %d markers = %+v, want %d", idx+1, sd.PageMarkers, wantMarkers)
+		}
+	}
+	if messages != 30 {
+		t.Errorf("messages across pages = %d, want 30", messages)
+	}
+}
+
+func TestSplitSequenceAvoidsFrames(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	// Without the frame the first page would end part-way through messages
+	// 4 to 8; the split should come before the loop instead.
+	computed := ComputeLayout(longSequence(12, 4, 8), th, cfg)
+	pages := SplitSequence(computed, th, cfg, 400)
+	if len(pages) < 2 {
+		t.Fatalf("pages = %d, want at least 2", len(pages))
+	}
+	for idx, page := range pages {
+		for _, frame := range page.Diagram.(SequenceData).Frames {
+			if frame.Continued {
+				t.Errorf("page %d: loop continued from an earlier page; want it kept whole", idx+1)
+			}
+		}
+	}
+}
+
+func TestSplitSequenceContinuesLongFrames(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	computed := ComputeLayout(longSequence(20, 0, 20), th, cfg)
+	pages := SplitSequence(computed, th, cfg, 400)
+	if len(pages) < 2 {
+		t.Fatalf("pages = %d, want at least 2", len(pages))
+	}
+	for idx, page := range pages {
+		frames := page.Diagram.(SequenceData).Frames
+		if len(frames) != 1 || frames[0].Continued != (idx > 0) {
+			t.Errorf("page %d frames = %+v, want the loop, continued after the first page", idx+1, frames)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package layout
 
 import (
+	"math"
 	"testing"
 
 	"github.com/jamesainslie/gomd2svg/config"
@@ -206,3 +207,169 @@ func TestSequenceLayoutCreatedParticipant(t *testing.T) {
 		t.Errorf("created participant B Y (%f) should be > 0", bLayout.Y)
 	}
 }
+
+func TestSequenceLayoutParticipantLinks(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{
+		{
+			ID:         "A",
+			Links:      []ir.SeqLink{{Label: "A very long dashboard label", URL: "https://example.com"}},
+			Properties: map[string]string{"team": "platform"},
+		},
+		{ID: "B"},
+	}
+
+	lay := ComputeLayout(graph, theme.Modern(), config.DefaultLayout())
+	sd, ok := lay.Diagram.(SequenceData)
+	if !ok {
+		t.Fatalf("Diagram type = %T, want SequenceData", lay.Diagram)
+	}
+	linked, plain := sd.Participants[0], sd.Participants[1]
+	if len(linked.Links) != 1 || linked.Properties["team"] != "platform" {
+		t.Errorf("participant links/properties not carried into layout: %+v", linked)
+	}
+	if linked.MenuWidth <= linked.Width {
+		t.Errorf("MenuWidth = %v, want wider than the participant (%v) for a long label", linked.MenuWidth, linked.Width)
+	}
+	if plain.MenuWidth != plain.Width {
+		t.Errorf("MenuWidth without links = %v, want participant width %v", plain.MenuWidth, plain.Width)
+	}
+}
+
+// seqLayout lays out a sequence diagram and returns its diagram data.
+func seqLayout(t *testing.T, graph *ir.Graph, cfg *config.Layout) SequenceData {
+	t.Helper()
+	lay := ComputeLayout(graph, theme.Modern(), cfg)
+	sd, ok := lay.Diagram.(SequenceData)
+	if !ok {
+		t.Fatalf("Diagram type = %T, want SequenceData", lay.Diagram)
+	}
+	return sd
+}
+
+func TestSequenceLayoutWrap(t *testing.T) {
+	long := "this message is far too long to fit on a single line between two participants"
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{{ID: "A"}, {ID: "B"}}
+	graph.Events = []*ir.SeqEvent{
+		{Kind: ir.EvMessage, Message: &ir.SeqMessage{From: "A", To: "B", Text: long, Wrap: ir.SeqWrapOn}},
+		{Kind: ir.EvMessage, Message: &ir.SeqMessage{From: "B", To: "A", Text: "short"}},
+		{Kind: ir.EvNote, Note: &ir.SeqNote{Position: ir.NoteOver, Participants: []string{"A"}, Text: long, Wrap: ir.SeqWrapOn}},
+	}
+	cfg := config.DefaultLayout()
+	sd := seqLayout(t, graph, cfg)
+
+	wrapped := sd.Messages[0].Text
+	if len(wrapped.Lines) < 2 {
+		t.Fatalf("wrapped lines = %q, want several", wrapped.Lines)
+	}
+	if wrapped.Width > cfg.Sequence.WrapWidth {
+		t.Errorf("wrapped width = %v, want at most %v", wrapped.Width, cfg.Sequence.WrapWidth)
+	}
+	// The arrow sits below every label line.
+	if sd.Messages[0].Y < cfg.Sequence.HeaderHeight+wrapped.Height {
+		t.Errorf("message Y = %v leaves no room for %d label lines", sd.Messages[0].Y, len(wrapped.Lines))
+	}
+	if len(sd.Messages[1].Text.Lines) != 1 {
+		t.Errorf("short message lines = %q, want one", sd.Messages[1].Text.Lines)
+	}
+	if note := sd.Notes[0]; len(note.Text.Lines) < 2 || note.Width > cfg.Sequence.NoteMaxWidth {
+		t.Errorf("note = %d lines, width %v; want wrapped within %v", len(note.Text.Lines), note.Width, cfg.Sequence.NoteMaxWidth)
+	}
+
+	// Without wrapping, the diagram-wide setting decides.
+	graph.Events[0].Message.Wrap = ir.SeqWrapDefault
+	if lines := seqLayout(t, graph, cfg).Messages[0].Text.Lines; len(lines) != 1 {
+		t.Errorf("unwrapped lines = %q, want one", lines)
+	}
+	graph.SeqWrap = true
+	if lines := seqLayout(t, graph, cfg).Messages[0].Text.Lines; len(lines) < 2 {
+		t.Errorf("lines with diagram-wide wrap = %q, want several", lines)
+	}
+}
+
+func TestSequenceLayoutGapsFitMessages(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{{ID: "A"}, {ID: "B"}, {ID: "C"}}
+	cfg := config.DefaultLayout()
+	base := seqLayout(t, graph, cfg)
+	baseGap := base.Participants[1].X - base.Participants[0].X
+
+	graph.Events = []*ir.SeqEvent{
+		{Kind: ir.EvMessage, Message: &ir.SeqMessage{From: "A", To: "B", Text: "a rather long message label between A and B"}},
+		{Kind: ir.EvMessage, Message: &ir.SeqMessage{From: "C", To: "C", Text: "a long self-message label"}},
+		{Kind: ir.EvNote, Note: &ir.SeqNote{Position: ir.NoteLeft, Participants: []string{"A"}, Text: "left note"}},
+	}
+	sd := seqLayout(t, graph, cfg)
+	parts := sd.Participants
+
+	msg := sd.Messages[0]
+	if gap := parts[1].X - parts[0].X; gap <= baseGap || gap < msg.Text.Width {
+		t.Errorf("A-B distance = %v, want wider than %v and the label (%v)", gap, baseGap, msg.Text.Width)
+	}
+	if gap := parts[2].X - parts[1].X; math.Abs(float64(gap-baseGap)) > 0.01 {
+		t.Errorf("B-C distance = %v, want unchanged %v", gap, baseGap)
+	}
+	// The note left of the first participant and the self-message on the
+	// last one stay inside the diagram.
+	if note := sd.Notes[0]; note.X < 0 {
+		t.Errorf("note X = %v, want inside the diagram", note.X)
+	}
+	self := sd.Messages[1]
+	lay := ComputeLayout(graph, theme.Modern(), cfg)
+	if right := self.FromX + cfg.Sequence.SelfMessageWidth/2 + self.Text.Width/2; right > lay.Width {
+		t.Errorf("self-message label reaches %v, beyond diagram width %v", right, lay.Width)
+	}
+}
+
+func TestSequenceLayoutNoteSpansParticipants(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{{ID: "A"}, {ID: "B"}, {ID: "C"}}
+	graph.Events = []*ir.SeqEvent{
+		{Kind: ir.EvNote, Note: &ir.SeqNote{
+			Position:     ir.NoteOver,
+			Participants: []string{"A", "C"},
+			Text:         "a note that is wider than the three participants together",
+		}},
+	}
+	cfg := config.DefaultLayout()
+	cfg.Sequence.ParticipantSpacing = 10
+	sd := seqLayout(t, graph, cfg)
+	first, last := sd.Participants[0], sd.Participants[2]
+	note := sd.Notes[0]
+	if note.X < first.X-first.Width/2-0.01 || note.X+note.Width > last.X+last.Width/2+0.01 {
+		t.Errorf("note spans %v..%v, want within participants %v..%v",
+			note.X, note.X+note.Width, first.X-first.Width/2, last.X+last.Width/2)
+	}
+}
+
+func TestSequenceLayoutWideLabelsStayInside(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sequence
+	graph.Participants = []*ir.SeqParticipant{{ID: "A"}, {ID: "B"}, {ID: "C"}}
+	long := "a label that is far wider than the note limit and the participant boxes together"
+	graph.Events = []*ir.SeqEvent{
+		{Kind: ir.EvMessage, Message: &ir.SeqMessage{From: "A", To: "A", Text: long}},
+		{Kind: ir.EvNote, Note: &ir.SeqNote{Position: ir.NoteOver, Participants: []string{"A", "C"}, Text: long}},
+	}
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, theme.Modern(), cfg)
+	sd := seqLayout(t, graph, cfg)
+
+	self := sd.Messages[0]
+	centre := self.FromX + cfg.Sequence.SelfMessageWidth/2
+	if left := centre - self.Text.Width/2; left < 0 {
+		t.Errorf("self-message label starts at %v, left of the diagram", left)
+	}
+	note := sd.Notes[0]
+	if note.Width < note.Text.Width {
+		t.Errorf("note width = %v, narrower than its text (%v)", note.Width, note.Text.Width)
+	}
+	if note.X < 0 || note.X+note.Width > lay.Width {
+		t.Errorf("note spans %v..%v, want inside diagram width %v", note.X, note.X+note.Width, lay.Width)
+	}
+}
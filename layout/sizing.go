@@ -58,6 +58,10 @@ func sizeNode(
 		FontSize: fontSize,
 	}
 
+	if node.Icon != nil {
+		return sizeIconNode(node, textBlock)
+	}
+
 	// Apply padding.
 	padH := cfg.Padding.NodeHorizontal
 	padV := cfg.Padding.NodeVertical
@@ -105,6 +109,41 @@ func sizeNode(
 	}
 }
 
+// sizeIconNode sizes a flowchart icon node: the icon, in its background
+// form if any, stacked with the label.
+func sizeIconNode(node *ir.Node, label TextBlock) *NodeLayout {
+	size := node.Icon.Size
+	if size <= 0 {
+		size = defaultIconSize
+	}
+	box := size
+	if node.Icon.Form != "" {
+		box += 2 * iconFormPadding
+	}
+	height := box
+	if label.Width > 0 {
+		height += iconLabelGap + label.Height
+	}
+	return &NodeLayout{
+		ID:       node.ID,
+		Label:    label,
+		Shape:    node.Shape,
+		Width:    max(box, label.Width),
+		Height:   height,
+		Icon:     node.Icon,
+		IconSize: size,
+		IconBox:  box,
+	}
+}
+
+// Flowchart icon node constants.
+const (
+	defaultIconSize float32 = 48
+	// iconFormPadding separates an icon from the edge of its background.
+	iconFormPadding float32 = 8
+	iconLabelGap    float32 = 4
+)
+
 // Layout constants matching the Rust reference implementation.
 const (
 	flowchartPadMain        = 40.0 // main-axis padding
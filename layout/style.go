@@ -0,0 +1,54 @@
+package layout
+
+import "github.com/jamesainslie/gomd2svg/ir"
+
+// resolveNodeStyle merges the classDef styles of a node's classes, in
+// order, and then its own style statement; later values win.
+func resolveNodeStyle(graph *ir.Graph, id string) ir.NodeStyle {
+	styles := make([]*ir.NodeStyle, 0, len(graph.NodeClasses[id])+1)
+	for _, class := range graph.NodeClasses[id] {
+		styles = append(styles, graph.ClassDefs[class])
+	}
+	styles = append(styles, graph.NodeStyles[id])
+	return mergeNodeStyles(styles)
+}
+
+// resolveClassStyle merges the classDef styles of the named classes, in
+// order; later values win.
+func resolveClassStyle(graph *ir.Graph, classes []string) ir.NodeStyle {
+	styles := make([]*ir.NodeStyle, 0, len(classes))
+	for _, class := range classes {
+		styles = append(styles, graph.ClassDefs[class])
+	}
+	return mergeNodeStyles(styles)
+}
+
+// mergeNodeStyles merges styles in order, skipping nil ones; later values
+// win.
+func mergeNodeStyles(styles []*ir.NodeStyle) ir.NodeStyle {
+	var merged ir.NodeStyle
+	for _, style := range styles {
+		if style == nil {
+			continue
+		}
+		if style.Fill != nil {
+			merged.Fill = style.Fill
+		}
+		if style.Stroke != nil {
+			merged.Stroke = style.Stroke
+		}
+		if style.TextColor != nil {
+			merged.TextColor = style.TextColor
+		}
+		if style.StrokeWidth != nil {
+			merged.StrokeWidth = style.StrokeWidth
+		}
+		if style.StrokeDasharray != nil {
+			merged.StrokeDasharray = style.StrokeDasharray
+		}
+		if style.LineColor != nil {
+			merged.LineColor = style.LineColor
+		}
+	}
+	return merged
+}
@@ -0,0 +1,110 @@
+package layout
+
+import "math"
+
+// Nice-number tick constants.
+const (
+	// niceTickEpsilon absorbs floating-point error when stepping ticks.
+	niceTickEpsilon = 1e-9
+	niceStepTwo     = 2
+	niceStepTwoHalf = 2.5
+	niceStepFive    = 5
+	niceStepTen     = 10
+)
+
+// niceScale extends [lo, hi] outwards to bounds that are whole multiples
+// of a "nice" step of 1, 2, 2.5 or 5 times a power of ten. Of the nice
+// steps near the span divided by count, it picks the one giving the
+// interval count closest to count, then the one that wastes the least
+// space. It returns the new bounds and the step; see niceTickValues for
+// the ticks themselves.
+func niceScale(lo, hi float64, count int) (float64, float64, float64) {
+	if count < 1 {
+		count = 1
+	}
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	if hi == lo {
+		if hi == 0 {
+			hi = 1
+		} else {
+			lo, hi = lo-math.Abs(lo)/2, hi+math.Abs(hi)/2 //nolint:mnd // widen a single value by half its size.
+		}
+	}
+	bestLo, bestHi, bestStep := lo, hi, 0.0
+	bestScore, bestSpan := math.Inf(1), math.Inf(1)
+	for _, step := range niceCandidates((hi - lo) / float64(count)) {
+		niceLo := math.Floor(lo/step+niceTickEpsilon) * step
+		niceHi := math.Ceil(hi/step-niceTickEpsilon) * step
+		score := math.Abs(math.Round((niceHi-niceLo)/step) - float64(count))
+		if span := niceHi - niceLo; score < bestScore || (score == bestScore && span < bestSpan) {
+			bestLo, bestHi, bestStep, bestScore, bestSpan = niceLo, niceHi, step, score, span
+		}
+	}
+	return bestLo, bestHi, bestStep
+}
+
+// niceStep returns a nice step for about count intervals across the fixed
+// range [lo, hi]. Steps that divide the range evenly, so both bounds get
+// a tick, are preferred.
+func niceStep(lo, hi float64, count int) float64 {
+	if count < 1 {
+		count = 1
+	}
+	span := math.Abs(hi - lo)
+	if span == 0 {
+		span = 1
+	}
+	best, bestScore := 1.0, math.Inf(1)
+	for _, step := range niceCandidates(span / float64(count)) {
+		intervals := span / step
+		score := math.Abs(intervals - float64(count))
+		if math.Abs(intervals-math.Round(intervals)) > niceTickEpsilon*intervals {
+			score++
+		}
+		if score < bestScore {
+			best, bestScore = step, score
+		}
+	}
+	return best
+}
+
+// niceCandidates returns the numbers of the form 1, 2, 2.5 or 5 times a
+// power of ten within a factor of ten of raw, smallest first.
+func niceCandidates(raw float64) []float64 {
+	if raw <= 0 || math.IsInf(raw, 0) || math.IsNaN(raw) {
+		return []float64{1}
+	}
+	exponent := math.Floor(math.Log10(raw))
+	var candidates []float64
+	for power := exponent - 1; power <= exponent+1; power++ {
+		magnitude := math.Pow(niceStepTen, power)
+		for _, mantissa := range []float64{1, niceStepTwo, niceStepTwoHalf, niceStepFive} {
+			candidates = append(candidates, mantissa*magnitude)
+		}
+	}
+	return candidates
+}
+
+// niceTickValues returns the multiples of step within [lo, hi], snapped
+// so accumulated floating-point error does not show in labels.
+func niceTickValues(lo, hi, step float64) []float64 {
+	if step <= 0 || hi < lo {
+		return nil
+	}
+	first := math.Ceil(lo/step - niceTickEpsilon)
+	last := math.Floor(hi/step + niceTickEpsilon)
+	values := make([]float64, 0, int(last-first)+1)
+	for idx := first; idx <= last; idx++ {
+		value := idx * step
+		// Round to the step's precision, with a digit to spare for steps
+		// of 2.5, so 0.1*3 prints as 0.3.
+		if decimals := 1 - math.Floor(math.Log10(step)); decimals > 0 {
+			scale := math.Pow(niceStepTen, decimals)
+			value = math.Round(value*scale) / scale
+		}
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,59 @@
+package layout
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNiceScale(t *testing.T) {
+	tests := []struct {
+		name           string
+		lo, hi         float64
+		count          int
+		wantLo, wantHi float64
+		wantStep       float64
+	}{
+		{"hundreds", 0, 720, 5, 0, 800, 200},
+		{"quarters", 0, 980, 4, 0, 1000, 250},
+		{"thousands", 0, 12000, 5, 0, 12500, 2500},
+		{"exact", 0, 100, 5, 0, 100, 20},
+		{"negative", -35, 80, 5, -40, 80, 20},
+		{"fractions", 0, 0.87, 4, 0, 1, 0.25},
+		{"single value", 50, 50, 5, 20, 80, 10},
+		{"empty", 0, 0, 5, 0, 1, 0.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi, step := niceScale(tt.lo, tt.hi, tt.count)
+			if lo != tt.wantLo || hi != tt.wantHi || step != tt.wantStep {
+				t.Errorf("niceScale(%v, %v, %d) = %v, %v, %v; want %v, %v, %v",
+					tt.lo, tt.hi, tt.count, lo, hi, step, tt.wantLo, tt.wantHi, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	// Fixed ranges prefer steps that land on both bounds.
+	if got := niceStep(0, 12000, 5); got != 2000 {
+		t.Errorf("niceStep(0, 12000, 5) = %v, want 2000", got)
+	}
+	if got := niceStep(0, 100, 5); got != 20 {
+		t.Errorf("niceStep(0, 100, 5) = %v, want 20", got)
+	}
+}
+
+func TestNiceTickValues(t *testing.T) {
+	if got, want := niceTickValues(0, 750, 250), []float64{0, 250, 500, 750}; !slices.Equal(got, want) {
+		t.Errorf("niceTickValues(0, 750, 250) = %v, want %v", got, want)
+	}
+	// Fixed bounds that are not multiples of the step keep only the
+	// multiples inside them.
+	if got, want := niceTickValues(5, 47, 10), []float64{10, 20, 30, 40}; !slices.Equal(got, want) {
+		t.Errorf("niceTickValues(5, 47, 10) = %v, want %v", got, want)
+	}
+	// Accumulated error is snapped away.
+	if got, want := niceTickValues(0, 0.3, 0.1), []float64{0, 0.1, 0.2, 0.3}; !slices.Equal(got, want) {
+		t.Errorf("niceTickValues(0, 0.3, 0.1) = %v, want %v", got, want)
+	}
+}
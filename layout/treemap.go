@@ -3,6 +3,7 @@ package layout
 import (
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -28,6 +29,15 @@ type treemapSquarifiedRect struct {
 	w, h float32
 }
 
+// treemapPlacer lays out treemap sections and leaves with the settings
+// shared by every level.
+type treemapPlacer struct {
+	graph       *ir.Graph
+	tcfg        config.TreemapConfig
+	valueFormat string
+	maxDepth    int
+}
+
 // computeTreemapLayout builds a squarified treemap layout from the IR graph.
 func computeTreemapLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *Layout {
 	padX := cfg.Treemap.PaddingX
@@ -45,27 +55,26 @@ func computeTreemapLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 		}
 	}
 
+	placer := treemapPlacer{
+		graph:       graph,
+		tcfg:        cfg.Treemap,
+		valueFormat: cfg.Treemap.ValueFormat,
+		maxDepth:    cfg.Treemap.MaxDepth,
+	}
+	if graph.TreemapValueFormat != "" {
+		placer.valueFormat = graph.TreemapValueFormat
+	}
+	if graph.TreemapMaxDepth > 0 {
+		placer.maxDepth = graph.TreemapMaxDepth
+	}
+
 	var rects []TreemapRectLayout
 
 	// If root has children, lay them out; otherwise treat root as single rect.
 	if len(graph.TreemapRoot.Children) > 0 {
-		rects = treemapLayoutChildren(
-			graph.TreemapRoot.Children,
-			padX, padY, chartW, chartH,
-			cfg.Treemap.Padding, cfg.Treemap.HeaderHeight,
-			0, 0,
-		)
+		rects = placer.layoutChildren(graph.TreemapRoot.Children, padX, padY, chartW, chartH, 0, 0)
 	} else {
-		rects = []TreemapRectLayout{{
-			Label:      graph.TreemapRoot.Label,
-			Value:      graph.TreemapRoot.Value,
-			X:          padX,
-			Y:          padY,
-			Width:      chartW,
-			Height:     chartH,
-			Depth:      0,
-			ColorIndex: 0,
-		}}
+		rects = []TreemapRectLayout{placer.rect(graph.TreemapRoot, graph.TreemapRoot.Value, padX, padY, chartW, chartH, 0, 0)}
 	}
 
 	return &Layout{
@@ -77,16 +86,31 @@ func computeTreemapLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 	}
 }
 
-// treemapLayoutChildren lays out children within a given rectangle using the
+// rect returns the layout of a node with its formatted value and the
+// colours of its ::: classes.
+func (p treemapPlacer) rect(node *ir.TreemapNode, value float64, rectX, rectY, width, height float32, depth, colorIdx int) TreemapRectLayout {
+	return TreemapRectLayout{
+		Label:      node.Label,
+		Value:      value,
+		ValueLabel: formatNumber(value, p.valueFormat),
+		X:          rectX,
+		Y:          rectY,
+		Width:      width,
+		Height:     height,
+		Depth:      depth,
+		ColorIndex: colorIdx,
+		Style:      resolveClassStyle(p.graph, strings.Fields(node.Class)),
+	}
+}
+
+// layoutChildren lays out children within a given rectangle using the
 // squarified treemap algorithm (Bruls-Huizing-van Wijk). Section nodes get a
 // header band and their children are recursively laid out beneath it.
-func treemapLayoutChildren( //nolint:revive // argument-limit: recursive layout requires rectangle + styling + depth params
-	children []*ir.TreemapNode,
-	rectX, rectY, width, height float32,
-	padding, headerH float32,
-	depth, colorStart int,
-) []TreemapRectLayout {
+// Sections at the last level drawn are drawn whole, like leaves.
+func (p treemapPlacer) layoutChildren(children []*ir.TreemapNode, rectX, rectY, width, height float32, depth, colorStart int) []TreemapRectLayout {
 	var rects []TreemapRectLayout
+	padding := p.tcfg.Padding
+	headerH := p.tcfg.HeaderHeight
 
 	// Build items with positive values, sorted descending by value.
 	var items []treemapItem
@@ -108,47 +132,27 @@ func treemapLayoutChildren( //nolint:revive // argument-limit: recursive layout
 	}
 
 	squarifiedRects := treemapSquarify(items, rectX, rectY, width, height, totalValue)
+	lastLevel := p.maxDepth > 0 && depth+1 >= p.maxDepth
 
 	for _, sr := range squarifiedRects {
 		it := sr.item
 		colorIdx := (colorStart + it.idx) % treemapColorCount
+		rect := p.rect(it.node, it.value, sr.x+padding/2, sr.y+padding/2, sr.w-padding, sr.h-padding, depth, colorIdx)
 
-		if it.node.IsLeaf() {
-			rects = append(rects, TreemapRectLayout{
-				Label:      it.node.Label,
-				Value:      it.node.Value,
-				X:          sr.x + padding/2,
-				Y:          sr.y + padding/2,
-				Width:      sr.w - padding,
-				Height:     sr.h - padding,
-				Depth:      depth,
-				ColorIndex: colorIdx,
-			})
-		} else {
-			// Section node: header rect, then recurse into children below it.
-			rects = append(rects, TreemapRectLayout{
-				Label:      it.node.Label,
-				X:          sr.x + padding/2,
-				Y:          sr.y + padding/2,
-				Width:      sr.w - padding,
-				Height:     sr.h - padding,
-				Depth:      depth,
-				IsSection:  true,
-				ColorIndex: colorIdx,
-			})
-			innerX := sr.x + padding
-			innerY := sr.y + padding + headerH
-			innerW := sr.w - padding*2
-			innerH := sr.h - padding*2 - headerH
-			if innerW > 0 && innerH > 0 {
-				childRects := treemapLayoutChildren(
-					it.node.Children,
-					innerX, innerY, innerW, innerH,
-					padding, headerH,
-					depth+1, colorIdx,
-				)
-				rects = append(rects, childRects...)
-			}
+		if it.node.IsLeaf() || lastLevel {
+			rects = append(rects, rect)
+			continue
+		}
+
+		// Section node: header rect, then recurse into children below it.
+		rect.IsSection = true
+		rects = append(rects, rect)
+		innerX := sr.x + padding
+		innerY := sr.y + padding + headerH
+		innerW := sr.w - padding*2
+		innerH := sr.h - padding*2 - headerH
+		if innerW > 0 && innerH > 0 {
+			rects = append(rects, p.layoutChildren(it.node.Children, innerX, innerY, innerW, innerH, depth+1, colorIdx)...)
 		}
 	}
 
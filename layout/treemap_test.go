@@ -140,6 +140,78 @@ func TestTreemapLayoutNested(t *testing.T) {
 	}
 }
 
+// treemapBudget has a section with two leaves beside a leaf.
+func treemapBudget() *ir.Graph {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Treemap
+	graph.TreemapRoot = &ir.TreemapNode{
+		Label: "Root",
+		Children: []*ir.TreemapNode{
+			{Label: "Section", Class: "hot", Children: []*ir.TreemapNode{
+				{Label: "X", Value: 1200},
+				{Label: "Y", Value: 3000.5},
+			}},
+			{Label: "Z", Value: 5000},
+		},
+	}
+	return graph
+}
+
+func treemapRects(t *testing.T, graph *ir.Graph, cfg *config.Layout) map[string]TreemapRectLayout {
+	t.Helper()
+	td, ok := ComputeLayout(graph, theme.Modern(), cfg).Diagram.(TreemapData)
+	if !ok {
+		t.Fatal("Diagram is not TreemapData")
+	}
+	rects := make(map[string]TreemapRectLayout, len(td.Rects))
+	for _, rect := range td.Rects {
+		rects[rect.Label] = rect
+	}
+	return rects
+}
+
+func TestTreemapLayoutValueFormat(t *testing.T) {
+	cfg := config.DefaultLayout()
+	rects := treemapRects(t, treemapBudget(), cfg)
+	if got := rects["Section"]; got.Value != 4200.5 || got.ValueLabel != "4,200.5" {
+		t.Errorf("Section total = %v %q, want 4200.5 \"4,200.5\"", got.Value, got.ValueLabel)
+	}
+
+	cfg.Treemap.ValueFormat = ",.0f"
+	graph := treemapBudget()
+	graph.TreemapValueFormat = "$,.2f"
+	rects = treemapRects(t, graph, cfg)
+	if got := rects["X"].ValueLabel; got != "$1,200.00" {
+		t.Errorf("X label = %q, want the directive's format", got)
+	}
+}
+
+func TestTreemapLayoutMaxDepth(t *testing.T) {
+	cfg := config.DefaultLayout()
+	cfg.Treemap.MaxDepth = 1
+	rects := treemapRects(t, treemapBudget(), cfg)
+	if len(rects) != 2 {
+		t.Fatalf("rects = %d, want the two top-level nodes", len(rects))
+	}
+	section := rects["Section"]
+	if section.IsSection || section.Value != 4200.5 {
+		t.Errorf("Section = %+v, want a leaf holding its total", section)
+	}
+}
+
+func TestTreemapLayoutClassStyles(t *testing.T) {
+	graph := treemapBudget()
+	fill := "#f96"
+	graph.ClassDefs["hot"] = &ir.NodeStyle{Fill: &fill}
+	rects := treemapRects(t, graph, config.DefaultLayout())
+	if got := rects["Section"].Style.Fill; got == nil || *got != fill {
+		t.Errorf("Section fill = %v, want %q", got, fill)
+	}
+	if rects["Z"].Style.Fill != nil {
+		t.Error("Z has no class and should keep the palette fill")
+	}
+}
+
 func TestTreemapLayoutEmpty(t *testing.T) {
 	graph := ir.NewGraph()
 	graph.Kind = ir.Treemap
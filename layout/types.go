@@ -17,6 +17,9 @@ type Layout struct {
 	Height    float32
 	Title     string
 	Diagram   DiagramData
+	// NodeLinks maps node IDs to the URLs attached by click and link
+	// statements.
+	NodeLinks map[string]*ir.NodeLink
 }
 
 // NodeLayout holds the position, size, and style of a single node.
@@ -28,6 +31,11 @@ type NodeLayout struct {
 	Width  float32
 	Height float32
 	Style  ir.NodeStyle
+	// Icon is set for flowchart icon nodes, whose icon is IconSize wide
+	// and sits centred in an IconBox square with its background form.
+	Icon     *ir.NodeIcon
+	IconSize float32
+	IconBox  float32
 }
 
 // EdgeLayout holds the route, label, and style of a single edge.
@@ -125,10 +133,22 @@ type SequenceData struct {
 	Boxes         []SeqBoxLayout
 	Autonumber    bool
 	DiagramHeight float32
+	// PageMarkers are the "continued" notes on a page produced by
+	// SplitSequence. They are empty for an unsplit diagram.
+	PageMarkers []SeqPageMarker
 }
 
 func (SequenceData) diagramData() {}
 
+// SeqPageMarker is a note at the top or bottom of a page saying where the
+// diagram continues from or on. X is the centre of the text and Y its
+// baseline.
+type SeqPageMarker struct {
+	Text string
+	X    float32
+	Y    float32
+}
+
 // SeqParticipantLayout holds the position of a participant header.
 type SeqParticipantLayout struct {
 	ID     string
@@ -138,6 +158,12 @@ type SeqParticipantLayout struct {
 	Y      float32 // top Y (0 for normal, mid-diagram for created)
 	Width  float32
 	Height float32
+	// Links and Properties come from link, links and properties
+	// statements. MenuWidth is the width of a link menu that fits the
+	// longest link label.
+	Links      []ir.SeqLink
+	Properties map[string]string
+	MenuWidth  float32
 }
 
 // SeqLifeline is a vertical dashed line from participant to diagram bottom.
@@ -167,6 +193,7 @@ type SeqActivationLayout struct {
 	TopY          float32
 	BottomY       float32
 	Width         float32
+	Continued     bool // started on an earlier page
 }
 
 // SeqNoteLayout holds the position and content of a note.
@@ -180,14 +207,15 @@ type SeqNoteLayout struct {
 
 // SeqFrameLayout holds the bounds and label of a frame (combined fragment).
 type SeqFrameLayout struct {
-	Kind     ir.SeqFrameKind
-	Label    string
-	Color    string
-	X        float32
-	Y        float32
-	Width    float32
-	Height   float32
-	Dividers []float32 // Y positions of else/and/option divider lines
+	Kind      ir.SeqFrameKind
+	Label     string
+	Color     string
+	X         float32
+	Y         float32
+	Width     float32
+	Height    float32
+	Dividers  []float32 // Y positions of else/and/option divider lines
+	Continued bool      // started on an earlier page
 }
 
 // SeqBoxLayout holds the bounds and label of a participant box group.
@@ -233,6 +261,8 @@ type PacketData struct {
 	Rows       []PacketRowLayout
 	BitsPerRow int
 	ShowBits   bool
+	// BitRuler is set when each row has a ruler of absolute bit numbers.
+	BitRuler bool
 }
 
 func (PacketData) diagramData() {}
@@ -241,10 +271,13 @@ func (PacketData) diagramData() {}
 type PacketRowLayout struct {
 	Y      float32
 	Height float32
-	Fields []PacketFieldLayout
+	// StartBit is the absolute number of the row's first bit.
+	StartBit int
+	Fields   []PacketFieldLayout
 }
 
-// PacketFieldLayout holds the position of a single packet field cell.
+// PacketFieldLayout holds the position of a single packet field cell. A
+// field crossing a row boundary has a cell in each row it spans.
 type PacketFieldLayout struct {
 	Label    TextBlock
 	X, Y     float32
@@ -252,6 +285,10 @@ type PacketFieldLayout struct {
 	Height   float32
 	StartBit int
 	EndBit   int
+	// ContinuesFrom is set when the field started on an earlier row, and
+	// ContinuesTo when it carries on in the next one.
+	ContinuesFrom bool
+	ContinuesTo   bool
 }
 
 // PieData holds pie-chart-specific layout data.
@@ -262,13 +299,19 @@ type PieData struct {
 	Radius   float32
 	Title    string
 	ShowData bool
+	// Legend has one entry per slice; it is empty when the legend is off.
+	Legend       []PieLegendEntry
+	LegendSwatch float32
 }
 
 func (PieData) diagramData() {}
 
 // PieSliceLayout holds computed angles and label position for one slice.
+// Outside labels sit beside the pie, anchored at LabelX on the side of
+// their slice, and Leader holds the polyline from the rim to the label.
 type PieSliceLayout struct {
 	Label      string
+	Text       string
 	Value      float64
 	Percentage float32
 	StartAngle float32
@@ -276,6 +319,18 @@ type PieSliceLayout struct {
 	LabelX     float32
 	LabelY     float32
 	ColorIndex int
+	Outside    bool
+	Leader     [][2]float32
+}
+
+// PieLegendEntry is one legend row: a colour swatch with its top-left
+// corner at X, Y and the text starting at TextX, centred on the swatch.
+type PieLegendEntry struct {
+	Text       string
+	X          float32
+	Y          float32
+	TextX      float32
+	ColorIndex int
 }
 
 // QuadrantData holds quadrant-chart-specific layout data.
@@ -297,9 +352,17 @@ func (QuadrantData) diagramData() {}
 
 // QuadrantPointLayout holds the pixel position of a data point.
 type QuadrantPointLayout struct {
-	Label string
-	X     float32
-	Y     float32
+	Label  string
+	X      float32
+	Y      float32
+	Radius float32
+	// Style holds the point's class styles merged with its own.
+	Style ir.QuadrantPointStyle
+	// LabelX and LabelY are the label's anchor and baseline, placed clear
+	// of other points and labels where possible; LabelAnchor is its SVG
+	// text-anchor.
+	LabelX, LabelY float32
+	LabelAnchor    string
 }
 
 // TimelineData holds timeline-diagram-specific layout data.
@@ -344,10 +407,17 @@ type GanttData struct {
 	AxisTicks       []GanttAxisTick
 	TodayMarkerX    float32
 	ShowTodayMarker bool
-	ChartX          float32
-	ChartY          float32
-	ChartWidth      float32
-	ChartHeight     float32
+	// TodayMarkerStyle holds the CSS declarations of a styled
+	// todayMarker, separated by commas as Mermaid writes them.
+	TodayMarkerStyle string
+	ChartX           float32
+	ChartY           float32
+	ChartWidth       float32
+	ChartHeight      float32
+	// Markers are vertical lines for vert tasks, spanning all sections.
+	Markers []GanttMarker
+	// TopAxis repeats the axis labels above the chart.
+	TopAxis bool
 }
 
 func (GanttData) diagramData() {}
@@ -372,6 +442,16 @@ type GanttTaskLayout struct {
 	IsDone      bool
 	IsActive    bool
 	IsMilestone bool
+	// Link is set for tasks with a click href.
+	Link *ir.NodeLink
+	// Callback is set for tasks with a click call.
+	Callback *ir.GanttCallback
+}
+
+// GanttMarker is a labelled vertical line across the whole chart.
+type GanttMarker struct {
+	Label string
+	X     float32
 }
 
 // GanttAxisTick holds a tick mark on the date axis.
@@ -385,6 +465,9 @@ type GitGraphData struct {
 	Commits     []GitGraphCommitLayout
 	Branches    []GitGraphBranchLayout
 	Connections []GitGraphConnection
+	// Direction is LeftRight for horizontal lanes, or TopDown or BottomTop
+	// for vertical lanes with history running down or up.
+	Direction ir.Direction
 }
 
 func (GitGraphData) diagramData() {}
@@ -398,13 +481,17 @@ type GitGraphCommitLayout struct {
 	X, Y   float32
 }
 
-// GitGraphBranchLayout holds branch lane data.
+// GitGraphBranchLayout holds branch lane data. Horizontal lanes use Y,
+// StartX and EndX; vertical lanes use X, StartY and EndY.
 type GitGraphBranchLayout struct {
 	Name   string
 	Y      float32
 	Color  string
 	StartX float32
 	EndX   float32
+	X      float32
+	StartY float32
+	EndY   float32
 }
 
 // GitGraphConnection holds a line connecting two commits (merge/cherry-pick).
@@ -419,6 +506,9 @@ type XYChartData struct {
 	Series      []XYSeriesLayout
 	XLabels     []XYAxisLabel
 	YTicks      []XYAxisTick
+	Y2Ticks     []XYAxisTick // secondary y-axis ticks, drawn on the right
+	Legend      []XYLegendEntry
+	LegendKey   float32 // size of a legend colour key
 	Title       string
 	ChartX      float32
 	ChartY      float32
@@ -426,7 +516,11 @@ type XYChartData struct {
 	ChartHeight float32
 	YMin        float64
 	YMax        float64
+	Y2Min       float64
+	Y2Max       float64
+	HasY2       bool
 	Horizontal  bool
+	Stacked     bool
 }
 
 func (XYChartData) diagramData() {}
@@ -434,10 +528,24 @@ func (XYChartData) diagramData() {}
 // XYSeriesLayout holds one positioned data series.
 type XYSeriesLayout struct {
 	Type       ir.XYSeriesType
+	Name       string
+	Secondary  bool
 	Points     []XYPointLayout
 	ColorIndex int
 }
 
+// XYLegendEntry is one legend entry: a colour key with its top-left corner
+// at X, Y and the text starting at TextX, centred on the key. The key is
+// drawn as a bar swatch or a line sample depending on Type.
+type XYLegendEntry struct {
+	Text       string
+	Type       ir.XYSeriesType
+	X          float32
+	Y          float32
+	TextX      float32
+	ColorIndex int
+}
+
 // XYPointLayout holds the pixel position and value of one data point.
 type XYPointLayout struct {
 	X      float32
@@ -445,6 +553,11 @@ type XYPointLayout struct {
 	Width  float32 // bar width (0 for line points)
 	Height float32 // bar height (0 for line points)
 	Value  float64
+	// Label is the formatted value, centred on LabelX, LabelY; it is
+	// empty unless the chart shows data labels.
+	Label  string
+	LabelX float32
+	LabelY float32
 }
 
 // XYAxisLabel holds a label on the x-axis.
@@ -501,20 +614,30 @@ func (MindmapData) diagramData() {}
 
 // MindmapNodeLayout holds the positioned data for one mindmap node.
 type MindmapNodeLayout struct {
-	Label      string
-	Shape      ir.MindmapShape
-	Icon       string
+	Label string
+	Shape ir.MindmapShape
+	Icon  string
+	// IconSize is the size of the icon drawn above the label, or zero
+	// when the icon registry does not resolve Icon.
+	IconSize   float32
 	X, Y       float32
 	Width      float32
 	Height     float32
 	ColorIndex int
-	Children   []*MindmapNodeLayout
+	// Style holds the merged classDef colours of the node's ::: classes.
+	Style    ir.NodeStyle
+	Children []*MindmapNodeLayout
 }
 
 // SankeyData holds Sankey diagram layout data.
 type SankeyData struct {
 	Nodes []SankeyNodeLayout
 	Links []SankeyLinkLayout
+	// LinkColor is the resolved link colouring: "source", "target",
+	// "gradient", a colour, or empty for the theme's link colour.
+	LinkColor string
+	// ShowValues adds each node's value to its label.
+	ShowValues bool
 }
 
 func (SankeyData) diagramData() {}
@@ -522,6 +645,7 @@ func (SankeyData) diagramData() {}
 // SankeyNodeLayout holds a positioned Sankey node.
 type SankeyNodeLayout struct {
 	Label      string
+	Value      float64
 	X, Y       float32
 	Width      float32
 	Height     float32
@@ -548,14 +672,19 @@ func (TreemapData) diagramData() {}
 
 // TreemapRectLayout holds a positioned treemap rectangle.
 type TreemapRectLayout struct {
-	Label      string
+	Label string
+	// Value is a leaf's value or a section's total; ValueLabel is it
+	// formatted with the configured value format.
 	Value      float64
+	ValueLabel string
 	X, Y       float32
 	Width      float32
 	Height     float32
 	Depth      int
 	IsSection  bool
 	ColorIndex int
+	// Style holds the merged classDef colours of the node's ::: classes.
+	Style ir.NodeStyle
 }
 
 // RequirementData holds requirement-diagram-specific layout data.
@@ -579,6 +708,8 @@ func (BlockData) diagramData() {}
 type BlockInfo struct {
 	Span        int
 	HasChildren bool
+	Kind        ir.BlockKind
+	ArrowDir    ir.BlockArrowDirection
 }
 
 // C4Data holds C4-diagram-specific layout data.
@@ -586,18 +717,37 @@ type C4Data struct {
 	Elements   map[string]*ir.C4Element
 	Boundaries []*C4BoundaryLayout
 	SubKind    ir.C4Kind
+	// Rels holds the relationship behind each of the layout's edges, or
+	// nil for an edge without one.
+	Rels           []*ir.C4Rel
+	ElementStyles  map[string]*ir.C4Style
+	BoundaryStyles map[string]*ir.C4Style
+	Legend         []C4LegendEntry
+	LegendSwatch   float32
 }
 
 func (C4Data) diagramData() {}
 
 // C4BoundaryLayout stores positioned boundary rectangles.
 type C4BoundaryLayout struct {
-	ID     string
-	Label  string
-	Type   string
-	X, Y   float32
-	Width  float32
-	Height float32
+	ID          string
+	Label       string
+	Type        string
+	Description string
+	Deployment  bool
+	X, Y        float32
+	Width       float32
+	Height      float32
+}
+
+// C4LegendEntry is one row of a C4 legend: a swatch styled like the
+// element type, element or boundary named by Key, and its legend text.
+type C4LegendEntry struct {
+	Text     string
+	Key      string
+	Boundary bool
+	X, Y     float32
+	TextX    float32
 }
 
 // JourneyData holds journey-diagram-specific layout data.
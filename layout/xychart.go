@@ -6,17 +6,38 @@ import (
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/textmetrics"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
 // XY chart layout constants.
 const (
 	xyChartDefaultTickCount = 5
-	xyChartTickBase         = 10
-	xyChartNiceStepFive     = 5
+	// xyDataLabelGap separates a data label from its bar end or point.
+	xyDataLabelGap = 4
+	// xyLegendSwatch is the size of a legend colour key.
+	xyLegendSwatch = 12
+	// xyLegendTextGap separates a legend key from its text.
+	xyLegendTextGap = 6
+	// xyLegendSpacing separates legend entries.
+	xyLegendSpacing = 8
+	// xyLegendGap separates the legend from the chart.
+	xyLegendGap = 20
 )
 
-func computeXYChartLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *Layout {
+// xyScale maps values on one y-axis to pixel positions.
+type xyScale struct {
+	min, max float64
+	top      float32
+	height   float32
+}
+
+func (s xyScale) y(value float64) float32 {
+	frac := (value - s.min) / (s.max - s.min)
+	return s.top + s.height - float32(frac)*s.height
+}
+
+func computeXYChartLayout(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) *Layout {
 	padX := cfg.XYChart.PaddingX
 	padY := cfg.XYChart.PaddingY
 	chartW := cfg.XYChart.ChartWidth
@@ -28,28 +49,38 @@ func computeXYChartLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 		titleHeight = cfg.XYChart.TitleFontSize + padY
 	}
 
-	// Determine Y range.
-	yMin, yMax := xyDataRange(graph)
-	if graph.XYYAxis != nil && (graph.XYYAxis.Min != 0 || graph.XYYAxis.Max != 0) {
-		yMin = graph.XYYAxis.Min
-		yMax = graph.XYYAxis.Max
-	}
-	if yMax <= yMin {
-		yMax = yMin + 1
-	}
-	yRange := yMax - yMin
-
 	chartX := padX
 	chartY := titleHeight + padY
 
-	// valueToY maps a data value to a pixel Y position.
-	valueToY := func(v float64) float32 {
-		frac := (v - yMin) / yRange
-		return chartY + chartH - float32(frac)*chartH
+	// Split the series between the two y-axes.
+	var primary, secondary []*ir.XYSeries
+	for _, series := range graph.XYSeries {
+		if series.Secondary {
+			secondary = append(secondary, series)
+		} else {
+			primary = append(primary, series)
+		}
+	}
+	hasY2 := graph.XYY2Axis != nil || len(secondary) > 0
+
+	numberFormat := cfg.XYChart.NumberFormat
+	if graph.XYNumberFmt != "" {
+		numberFormat = graph.XYNumberFmt
 	}
+	showLabels := graph.XYDataLabels || cfg.XYChart.ShowDataLabels
+	labelFont := cfg.XYChart.AxisFontSize
 
-	// Generate Y-axis ticks.
-	yTicks := generateYTicks(yMin, yMax, xyChartDefaultTickCount, chartY, chartH)
+	yMin, yMax, yStep := xyAxisRange(graph.XYYAxis, primary, graph.XYStacked)
+	scale := xyScale{min: yMin, max: yMax, top: chartY, height: chartH}
+	yTicks := generateYTicks(scale, yStep, numberFormat)
+
+	scale2 := scale
+	var y2Ticks []XYAxisTick
+	if hasY2 {
+		y2Min, y2Max, y2Step := xyAxisRange(graph.XYY2Axis, secondary, graph.XYStacked)
+		scale2 = xyScale{min: y2Min, max: y2Max, top: chartY, height: chartH}
+		y2Ticks = generateYTicks(scale2, y2Step, numberFormat)
+	}
 
 	// Generate X-axis labels and series points.
 	var xLabels []XYAxisLabel
@@ -77,60 +108,142 @@ func computeXYChartLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 		}
 	}
 
-	// Count bar series for grouping.
-	barCount := 0
-	for _, series := range graph.XYSeries {
-		if series.Type == ir.XYSeriesBar {
-			barCount++
+	// Bars share each band in slots: one per bar series when grouped, or
+	// one per y-axis stack when stacked.
+	slotOf := make(map[*ir.XYSeries]int)
+	slotCount := 0
+	if graph.XYStacked {
+		for _, axisSeries := range [][]*ir.XYSeries{primary, secondary} {
+			used := false
+			for _, series := range axisSeries {
+				if series.Type == ir.XYSeriesBar {
+					slotOf[series] = slotCount
+					used = true
+				}
+			}
+			if used {
+				slotCount++
+			}
+		}
+	} else {
+		for _, series := range graph.XYSeries {
+			if series.Type == ir.XYSeriesBar {
+				slotOf[series] = slotCount
+				slotCount++
+			}
 		}
 	}
 
 	barGroupWidth := bandW * cfg.XYChart.BarWidth
 	var singleBarW float32
-	if barCount > 0 {
-		singleBarW = barGroupWidth / float32(barCount)
+	if slotCount > 0 {
+		singleBarW = barGroupWidth / float32(slotCount)
 	}
 
+	// Running stack heights per axis and category, split by sign so
+	// negative values stack downwards from the baseline.
+	posStack := map[bool][]float64{false: make([]float64, numPoints), true: make([]float64, numPoints)}
+	negStack := map[bool][]float64{false: make([]float64, numPoints), true: make([]float64, numPoints)}
+
 	// Build series layouts.
-	barIdx := 0
 	seriesLayouts := make([]XYSeriesLayout, 0, len(graph.XYSeries))
 	for si, xySeries := range graph.XYSeries {
+		axisScale := scale
+		if xySeries.Secondary {
+			axisScale = scale2
+		}
 		var points []XYPointLayout
 		for i, val := range xySeries.Values {
 			cx := chartX + float32(i)*bandW + bandW/2
-			py := valueToY(val)
 
 			switch xySeries.Type {
 			case ir.XYSeriesBar:
-				barX := cx - barGroupWidth/2 + float32(barIdx)*singleBarW
-				baseY := valueToY(math.Max(yMin, 0))
+				barX := cx - barGroupWidth/2 + float32(slotOf[xySeries])*singleBarW
+				base, top := math.Max(axisScale.min, 0), val
+				if graph.XYStacked {
+					stack := posStack[xySeries.Secondary]
+					if val < 0 {
+						stack = negStack[xySeries.Secondary]
+					}
+					base = stack[i]
+					stack[i] += val
+					top = stack[i]
+				}
+				baseY := axisScale.y(base)
+				py := axisScale.y(top)
 				barHeight := baseY - py
 				if barHeight < 0 {
 					barHeight = -barHeight
 					py = baseY
 				}
-				points = append(points, XYPointLayout{
+				point := XYPointLayout{
 					X: barX, Y: py, Width: singleBarW, Height: barHeight, Value: val,
-				})
+				}
+				if showLabels {
+					// Stacked segments are labelled in their middle, other
+					// bars just past their end.
+					point.LabelX = barX + singleBarW/2 //nolint:mnd // centred on the bar.
+					switch {
+					case graph.XYStacked:
+						point.LabelY = py + barHeight/2 //nolint:mnd // centred in the segment.
+					case val < 0:
+						point.LabelY = py + barHeight + xyDataLabelGap + labelFont/2 //nolint:mnd // half the text height.
+					default:
+						point.LabelY = py - xyDataLabelGap - labelFont/2 //nolint:mnd // see above.
+					}
+				}
+				points = append(points, point)
 			case ir.XYSeriesLine:
-				points = append(points, XYPointLayout{
-					X: cx, Y: py, Value: val,
-				})
+				point := XYPointLayout{X: cx, Y: axisScale.y(val), Value: val}
+				if showLabels {
+					point.LabelX = cx
+					point.LabelY = point.Y - xyDataLabelGap*2 - labelFont/2 //nolint:mnd // clear of the point marker.
+				}
+				points = append(points, point)
 			}
 		}
-		if xySeries.Type == ir.XYSeriesBar {
-			barIdx++
+		if showLabels {
+			for idx := range points {
+				points[idx].Label = formatNumber(points[idx].Value, numberFormat)
+			}
 		}
 		seriesLayouts = append(seriesLayouts, XYSeriesLayout{
 			Type:       xySeries.Type,
+			Name:       xySeries.Name,
+			Secondary:  xySeries.Secondary,
 			Points:     points,
 			ColorIndex: si,
 		})
 	}
 
-	totalW := padX*2 + chartW
+	// The secondary axis labels take the same room on the right as the
+	// primary ones do on the left.
+	chartRight := chartX + chartW
+	if hasY2 {
+		chartRight += padX
+	}
+	totalW := chartRight + padX
 	totalH := titleHeight + padY*2 + chartH + cfg.XYChart.AxisFontSize + padY
 
+	legend, legendW, legendH := xyLegend(graph, th, cfg)
+	if len(legend) > 0 {
+		var originX, originY float32
+		if cfg.XYChart.LegendPosition == config.LegendBottom {
+			originX = max(chartX, chartX+chartW/2-legendW/2) //nolint:mnd // centred under the chart.
+			originY = totalH - padY + xyLegendGap
+			totalH = originY + legendH + padY
+		} else {
+			originX = chartRight + xyLegendGap
+			originY = chartY
+			totalW = max(totalW, originX+legendW+padX)
+		}
+		for idx := range legend {
+			legend[idx].X += originX
+			legend[idx].TextX += originX
+			legend[idx].Y += originY
+		}
+	}
+
 	return &Layout{
 		Kind:   graph.Kind,
 		Nodes:  map[string]*NodeLayout{},
@@ -140,6 +253,9 @@ func computeXYChartLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 			Series:      seriesLayouts,
 			XLabels:     xLabels,
 			YTicks:      yTicks,
+			Y2Ticks:     y2Ticks,
+			Legend:      legend,
+			LegendKey:   xyLegendSwatch,
 			Title:       graph.XYTitle,
 			ChartX:      chartX,
 			ChartY:      chartY,
@@ -147,22 +263,95 @@ func computeXYChartLayout(graph *ir.Graph, _ *theme.Theme, cfg *config.Layout) *
 			ChartHeight: chartH,
 			YMin:        yMin,
 			YMax:        yMax,
+			Y2Min:       scale2.min,
+			Y2Max:       scale2.max,
+			HasY2:       hasY2,
 			Horizontal:  graph.XYHorizontal,
+			Stacked:     graph.XYStacked,
 		},
 	}
 }
 
-func xyDataRange(graph *ir.Graph) (float64, float64) {
+// xyLegend returns the legend entries relative to the legend's top-left
+// corner, a column for a legend on the right and a row for one below, and
+// the legend's size. Charts without named series, or with the legend
+// turned off, get none. Unnamed series in a chart with a legend are
+// called "Series N".
+func xyLegend(graph *ir.Graph, th *theme.Theme, cfg *config.Layout) ([]XYLegendEntry, float32, float32) {
+	named := false
+	for _, series := range graph.XYSeries {
+		named = named || series.Name != ""
+	}
+	if !named || cfg.XYChart.LegendPosition == config.LegendNone {
+		return nil, 0, 0
+	}
+	measurer := textmetrics.New()
+	bottom := cfg.XYChart.LegendPosition == config.LegendBottom
+	legend := make([]XYLegendEntry, 0, len(graph.XYSeries))
+	var width, height float32
+	for idx, series := range graph.XYSeries {
+		text := series.Name
+		if text == "" {
+			text = "Series " + strconv.Itoa(idx+1)
+		}
+		textW := measurer.Width(text, cfg.XYChart.AxisFontSize, th.FontFamily)
+		entry := XYLegendEntry{Text: text, Type: series.Type, ColorIndex: idx}
+		if bottom {
+			entry.X = width
+			if idx > 0 {
+				entry.X += xyLegendGap
+			}
+		} else {
+			entry.Y = float32(idx) * (xyLegendSwatch + xyLegendSpacing)
+		}
+		entry.TextX = entry.X + xyLegendSwatch + xyLegendTextGap
+		width = max(width, entry.TextX+textW)
+		height = entry.Y + xyLegendSwatch
+		legend = append(legend, entry)
+	}
+	return legend, width, height
+}
+
+// xyAxisRange returns the value range of a y-axis and its tick step. An
+// axis that declares its bounds keeps them and gets a nice step within
+// them; otherwise the range covers its series from zero and is widened to
+// nice bounds. Stacked bars count with their stack totals.
+func xyAxisRange(axis *ir.XYAxis, series []*ir.XYSeries, stacked bool) (float64, float64, float64) {
+	if axis != nil && (axis.Min != 0 || axis.Max != 0) && axis.Max > axis.Min {
+		return axis.Min, axis.Max, niceStep(axis.Min, axis.Max, xyChartDefaultTickCount)
+	}
+	yMin, yMax := xyDataRange(series, stacked)
+	return niceScale(yMin, yMax, xyChartDefaultTickCount)
+}
+
+// xyDataRange returns the smallest and largest values of the series,
+// with the smallest no higher than zero so bars grow from the baseline.
+func xyDataRange(series []*ir.XYSeries, stacked bool) (float64, float64) {
 	minVal, maxVal := math.Inf(1), math.Inf(-1)
-	for _, xySeries := range graph.XYSeries {
-		for _, val := range xySeries.Values {
-			if val < minVal {
-				minVal = val
+	var posSums, negSums []float64
+	for _, xySeries := range series {
+		if stacked && xySeries.Type == ir.XYSeriesBar {
+			for len(posSums) < len(xySeries.Values) {
+				posSums = append(posSums, 0)
+				negSums = append(negSums, 0)
 			}
-			if val > maxVal {
-				maxVal = val
+			for i, val := range xySeries.Values {
+				if val < 0 {
+					negSums[i] += val
+				} else {
+					posSums[i] += val
+				}
 			}
+			continue
 		}
+		for _, val := range xySeries.Values {
+			minVal = math.Min(minVal, val)
+			maxVal = math.Max(maxVal, val)
+		}
+	}
+	for i := range posSums {
+		minVal = math.Min(minVal, negSums[i])
+		maxVal = math.Max(maxVal, posSums[i])
 	}
 	if math.IsInf(minVal, 1) {
 		minVal = 0
@@ -173,40 +362,18 @@ func xyDataRange(graph *ir.Graph) (float64, float64) {
 	if minVal > 0 {
 		minVal = 0
 	}
-	// Round max up to a nice number.
-	maxVal = niceMax(maxVal)
 	return minVal, maxVal
 }
 
-func niceMax(v float64) float64 {
-	if v <= 0 {
-		return 1
-	}
-	magnitude := math.Pow(xyChartTickBase, math.Floor(math.Log10(v)))
-	normalized := v / magnitude
-	switch {
-	case normalized <= 1:
-		return magnitude
-	case normalized <= 2:
-		return 2 * magnitude
-	case normalized <= xyChartNiceStepFive:
-		return xyChartNiceStepFive * magnitude
-	default:
-		return xyChartTickBase * magnitude
-	}
-}
-
-func generateYTicks(yMin, yMax float64, count int, chartY, chartH float32) []XYAxisTick {
-	yRange := yMax - yMin
-	step := yRange / float64(count)
-	ticks := make([]XYAxisTick, 0, count+1)
-	for i := range count + 1 {
-		v := yMin + float64(i)*step
-		frac := (v - yMin) / yRange
-		y := chartY + chartH - float32(frac)*chartH
+// generateYTicks places a labelled tick at each multiple of step on the
+// axis.
+func generateYTicks(scale xyScale, step float64, numberFormat string) []XYAxisTick {
+	values := niceTickValues(scale.min, scale.max, step)
+	ticks := make([]XYAxisTick, 0, len(values))
+	for _, value := range values {
 		ticks = append(ticks, XYAxisTick{
-			Label: strconv.FormatFloat(v, 'g', 4, 64),
-			Y:     y,
+			Label: formatNumber(value, numberFormat),
+			Y:     scale.y(value),
 		})
 	}
 	return ticks
@@ -77,3 +77,204 @@ func TestXYChartAutoRange(t *testing.T) {
 		t.Error("YMax should be auto-computed > 0")
 	}
 }
+
+func TestXYChartStackedBars(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYStacked = true
+	graph.XYYAxis = &ir.XYAxis{Mode: ir.XYAxisNumeric, Min: 0, Max: 100}
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{30, 40}},
+		{Type: ir.XYSeriesBar, Values: []float64{20, 10}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, th, cfg)
+	xyd, ok := lay.Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+
+	lower := xyd.Series[0].Points[0]
+	upper := xyd.Series[1].Points[0]
+	if lower.X != upper.X || lower.Width != upper.Width {
+		t.Errorf("stacked bars at x=%v w=%v and x=%v w=%v, want the same slot",
+			lower.X, lower.Width, upper.X, upper.Width)
+	}
+	if diff := upper.Y + upper.Height - lower.Y; diff > 0.01 || diff < -0.01 {
+		t.Errorf("upper bar ends at %v, want on top of the lower bar at %v", upper.Y+upper.Height, lower.Y)
+	}
+	wantTop := xyd.ChartY + xyd.ChartHeight*0.5 // 50 of 100
+	if diff := upper.Y - wantTop; diff > 0.01 || diff < -0.01 {
+		t.Errorf("stack top = %v, want %v", upper.Y, wantTop)
+	}
+}
+
+func TestXYChartStackedAutoRange(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYStacked = true
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{60, 10}},
+		{Type: ir.XYSeriesBar, Values: []float64{60, 10}},
+	}
+
+	lay := ComputeLayout(graph, theme.Modern(), config.DefaultLayout())
+	xyd, ok := lay.Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+	if xyd.YMax < 120 {
+		t.Errorf("YMax = %v, want at least the stack total 120", xyd.YMax)
+	}
+}
+
+func TestXYChartSecondaryAxis(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYYAxis = &ir.XYAxis{Mode: ir.XYAxisNumeric, Min: 0, Max: 1000}
+	graph.XYY2Axis = &ir.XYAxis{Mode: ir.XYAxisNumeric, Min: 0, Max: 10}
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Name: "Requests", Values: []float64{500, 1000}},
+		{Type: ir.XYSeriesLine, Name: "Latency", Secondary: true, Values: []float64{5, 10}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, th, cfg)
+	xyd, ok := lay.Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+	if !xyd.HasY2 || xyd.Y2Max != 10 || len(xyd.Y2Ticks) == 0 {
+		t.Fatalf("secondary axis = %v max %v with %d ticks, want 0 --> 10", xyd.HasY2, xyd.Y2Max, len(xyd.Y2Ticks))
+	}
+	// Both series peak at their axis maximum and sit halfway at index 0.
+	bar, point := xyd.Series[0].Points[0], xyd.Series[1].Points[0]
+	if diff := bar.Y - point.Y; diff > 0.01 || diff < -0.01 {
+		t.Errorf("bar top %v and line point %v differ, want both at half height", bar.Y, point.Y)
+	}
+
+	if len(xyd.Legend) != 2 {
+		t.Fatalf("Legend len = %d, want 2", len(xyd.Legend))
+	}
+	if xyd.Legend[0].Text != "Requests" || xyd.Legend[1].Type != ir.XYSeriesLine {
+		t.Errorf("Legend = %+v, want Requests then a line entry", xyd.Legend)
+	}
+	for _, entry := range xyd.Legend {
+		if entry.X <= xyd.ChartX+xyd.ChartWidth {
+			t.Errorf("legend entry %q at x=%v, want right of the chart", entry.Text, entry.X)
+		}
+	}
+
+	cfg.XYChart.LegendPosition = config.LegendBottom
+	xyd, _ = ComputeLayout(graph, th, cfg).Diagram.(XYChartData)
+	if len(xyd.Legend) != 2 || xyd.Legend[0].Y <= xyd.ChartY+xyd.ChartHeight || xyd.Legend[1].X <= xyd.Legend[0].TextX {
+		t.Errorf("bottom Legend = %+v, want one row below the chart", xyd.Legend)
+	}
+
+	cfg.XYChart.LegendPosition = config.LegendNone
+	xyd, _ = ComputeLayout(graph, th, cfg).Diagram.(XYChartData)
+	if len(xyd.Legend) != 0 {
+		t.Errorf("Legend len = %d with LegendNone, want 0", len(xyd.Legend))
+	}
+}
+
+func TestXYChartNoLegendWithoutNames(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{1, 2}},
+	}
+	xyd, ok := ComputeLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+	if len(xyd.Legend) != 0 || xyd.HasY2 {
+		t.Errorf("Legend len = %d, HasY2 = %v, want no legend or secondary axis", len(xyd.Legend), xyd.HasY2)
+	}
+}
+
+func TestXYChartNiceTicksAndDataLabels(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYDataLabels = true
+	graph.XYNumberFmt = ".2s"
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{1230, 720, -300}},
+		{Type: ir.XYSeriesLine, Values: []float64{400, 500, 600}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	lay := ComputeLayout(graph, th, cfg)
+	xyd, ok := lay.Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+
+	if xyd.YMin != -500 || xyd.YMax != 1500 {
+		t.Errorf("Y range = %v..%v, want -500..1500", xyd.YMin, xyd.YMax)
+	}
+	var labels []string
+	for _, tick := range xyd.YTicks {
+		labels = append(labels, tick.Label)
+	}
+	want := []string{"-500", "0", "500", "1k", "1.5k"}
+	if len(labels) != len(want) {
+		t.Fatalf("tick labels = %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("tick labels = %v, want %v", labels, want)
+			break
+		}
+	}
+
+	bar := xyd.Series[0].Points[0]
+	if bar.Label != "1.2k" || bar.LabelY >= bar.Y {
+		t.Errorf("bar label %q at y=%v, want \"1.2k\" above the bar top %v", bar.Label, bar.LabelY, bar.Y)
+	}
+	negative := xyd.Series[0].Points[2]
+	if negative.LabelY <= negative.Y+negative.Height {
+		t.Errorf("negative bar label at y=%v, want below the bar end %v", negative.LabelY, negative.Y+negative.Height)
+	}
+	point := xyd.Series[1].Points[1]
+	if point.Label != "500" || point.LabelY >= point.Y {
+		t.Errorf("point label %q at y=%v, want \"500\" above the point %v", point.Label, point.LabelY, point.Y)
+	}
+
+	// Labels are off unless asked for.
+	graph.XYDataLabels = false
+	xyd, _ = ComputeLayout(graph, th, cfg).Diagram.(XYChartData)
+	if xyd.Series[0].Points[0].Label != "" {
+		t.Errorf("Label = %q without data labels, want empty", xyd.Series[0].Points[0].Label)
+	}
+	cfg.XYChart.ShowDataLabels = true
+	cfg.XYChart.NumberFormat = ",d"
+	graph.XYNumberFmt = ""
+	xyd, _ = ComputeLayout(graph, th, cfg).Diagram.(XYChartData)
+	if got := xyd.Series[0].Points[0].Label; got != "1,230" {
+		t.Errorf("Label = %q from config, want \"1,230\"", got)
+	}
+}
+
+func TestXYChartFixedRangeTicks(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYYAxis = &ir.XYAxis{Mode: ir.XYAxisNumeric, Min: 0, Max: 12000}
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{5000, 6000}},
+	}
+	xyd, ok := ComputeLayout(graph, theme.Modern(), config.DefaultLayout()).Diagram.(XYChartData)
+	if !ok {
+		t.Fatal("Diagram is not XYChartData")
+	}
+	if xyd.YMin != 0 || xyd.YMax != 12000 {
+		t.Errorf("Y range = %v..%v, want the declared 0..12000", xyd.YMin, xyd.YMax)
+	}
+	if n := len(xyd.YTicks); n != 7 || xyd.YTicks[1].Label != "2000" || xyd.YTicks[n-1].Label != "12000" {
+		t.Errorf("YTicks = %+v, want 0 to 12000 in steps of 2000", xyd.YTicks)
+	}
+}
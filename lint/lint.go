@@ -0,0 +1,192 @@
+// Package lint reports likely mistakes in Mermaid diagrams that parse
+// without error, such as references to undefined nodes or dependency
+// cycles.
+//
+// Most rules inspect the parsed ir.Graph. Rules about statements the
+// parser does not model, such as flowchart class and style lines, also
+// scan the source text and report line numbers.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// Severity ranks how serious a diagnostic is.
+type Severity int
+
+const (
+	// Warning flags something that renders but is probably unintended.
+	Warning Severity = iota
+	// Error flags something that renders incorrectly or is silently ignored.
+	Error
+)
+
+// String returns the lowercase name of the severity.
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// MarshalText encodes the severity by name, for JSON output.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic is a single lint finding.
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Line is the 1-based source line, or 0 when the finding comes from
+	// the graph alone.
+	Line int `json:"line,omitempty"`
+}
+
+// String formats the diagnostic as "severity: message (rule)".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", d.Severity, d.Message, d.Rule)
+}
+
+// Rule is a named check.
+type Rule struct {
+	Name        string
+	Severity    Severity
+	Description string
+	check       func(graph *ir.Graph, src []sourceLine) []finding
+}
+
+// finding is a rule result before the rule name and severity are attached.
+type finding struct {
+	line    int
+	message string
+}
+
+// sourceLine is one significant line of the input with its line number.
+type sourceLine struct {
+	num  int
+	text string
+}
+
+// Rules returns every rule in the order they run.
+func Rules() []Rule {
+	return []Rule{
+		{
+			Name:        "undefined-reference",
+			Severity:    Error,
+			Description: "class, style and cssClass statements that name nodes not defined elsewhere",
+			check:       checkUndefinedReferences,
+		},
+		{
+			Name:        "orphan-node",
+			Severity:    Warning,
+			Description: "nodes with no edges in a diagram that has edges",
+			check:       checkOrphanNodes,
+		},
+		{
+			Name:        "duplicate-label",
+			Severity:    Warning,
+			Description: "distinct nodes or participants that display the same label",
+			check:       checkDuplicateLabels,
+		},
+		{
+			Name:        "gantt-unknown-dependency",
+			Severity:    Error,
+			Description: "gantt after/until references to task IDs that do not exist",
+			check:       checkGanttDependencies,
+		},
+		{
+			Name:        "sequence-unmatched-deactivate",
+			Severity:    Error,
+			Description: "sequence deactivations without a matching activation",
+			check:       checkSequenceActivations,
+		},
+		{
+			Name:        "sankey-cycle",
+			Severity:    Error,
+			Description: "sankey flows that loop back to an earlier node",
+			check:       checkSankeyCycles,
+		},
+		{
+			Name:        "gitgraph-unknown-branch",
+			Severity:    Error,
+			Description: "gitgraph checkouts and merges of branches that were never created",
+			check:       checkGitBranches,
+		},
+		{
+			Name:        "packet-syntax",
+			Severity:    Error,
+			Description: "packet lines that are not bit range, single bit or bit count fields",
+			check:       checkPacketSyntax,
+		},
+		{
+			Name:        "packet-range",
+			Severity:    Error,
+			Description: "packet fields that end before they start or overlap another field",
+			check:       checkPacketRanges,
+		},
+		{
+			Name:        "packet-gap",
+			Severity:    Warning,
+			Description: "packet bits before the last field that no field covers",
+			check:       checkPacketGaps,
+		},
+	}
+}
+
+// Lint parses source and runs every rule against it. A parse failure is
+// returned as an error rather than a diagnostic.
+func Lint(source string) ([]Diagnostic, error) {
+	parsed, err := parser.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return run(parsed.Graph, scanSource(source)), nil
+}
+
+// Check runs the graph-only rules against an already-built graph.
+func Check(graph *ir.Graph) []Diagnostic {
+	return run(graph, nil)
+}
+
+// run applies every rule and orders the results by line, keeping rule
+// order for findings on the same line.
+func run(graph *ir.Graph, src []sourceLine) []Diagnostic {
+	var diags []Diagnostic
+	for _, rule := range Rules() {
+		for _, found := range rule.check(graph, src) {
+			diags = append(diags, Diagnostic{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Message:  found.message,
+				Line:     found.line,
+			})
+		}
+	}
+	sort.SliceStable(diags, func(idxA, idxB int) bool {
+		return diags[idxA].Line < diags[idxB].Line
+	})
+	return diags
+}
+
+// scanSource returns the non-blank, non-comment lines of source with
+// trailing comments removed.
+func scanSource(source string) []sourceLine {
+	var lines []sourceLine
+	for idx, raw := range strings.Split(source, "\n") {
+		text := strings.TrimSpace(raw)
+		if cut := strings.Index(text, "%%"); cut >= 0 {
+			text = strings.TrimSpace(text[:cut])
+		}
+		if text != "" {
+			lines = append(lines, sourceLine{num: idx + 1, text: text})
+		}
+	}
+	return lines
+}
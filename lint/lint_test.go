@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+func TestLintClean(t *testing.T) {
+	diags, err := Lint("flowchart LR\n    A[Start] --> B[End]")
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("Lint() = %v, want none", diags)
+	}
+}
+
+func TestLintParseError(t *testing.T) {
+	if _, err := Lint("flowchart LR\n    subgraph one\n    A --> B"); err == nil {
+		t.Error("Lint() expected parse error for unclosed subgraph")
+	}
+}
+
+func TestLintOrdersByLine(t *testing.T) {
+	diags, err := Lint("flowchart LR\n    A --> B\n    C\n    style Y fill:#f00\n    class X hot")
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if len(diags) != 3 {
+		t.Fatalf("Lint() = %v, want 3 diagnostics", diags)
+	}
+	if diags[0].Rule != "orphan-node" || diags[1].Line != 4 || diags[2].Line != 5 {
+		t.Errorf("unexpected order: %v", diags)
+	}
+}
+
+func TestCheckGraph(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sankey
+	graph.SankeyLinks = []*ir.SankeyLink{
+		{Source: "A", Target: "B", Value: 1},
+		{Source: "B", Target: "A", Value: 1},
+	}
+	diags := Check(graph)
+	if len(diags) != 1 || diags[0].Rule != "sankey-cycle" {
+		t.Errorf("Check() = %v, want one sankey-cycle", diags)
+	}
+}
+
+func TestDiagnosticFormatting(t *testing.T) {
+	diag := Diagnostic{Rule: "orphan-node", Severity: Warning, Message: `node "C" has no edges`, Line: 3}
+	if got, want := diag.String(), `warning: node "C" has no edges (orphan-node)`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	data, err := json.Marshal(diag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"severity":"warning"`) || !strings.Contains(string(data), `"line":3`) {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestRulesHaveNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, rule := range Rules() {
+		if rule.Name == "" || rule.Description == "" || rule.check == nil {
+			t.Errorf("incomplete rule %+v", rule)
+		}
+		if seen[rule.Name] {
+			t.Errorf("duplicate rule %q", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+}
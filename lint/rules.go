@@ -0,0 +1,449 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// checkUndefinedReferences reports IDs named by statements the parser
+// skips (class, style, click and friends) that no other statement defines.
+func checkUndefinedReferences(graph *ir.Graph, src []sourceLine) []finding {
+	var refs func(string) (string, []string)
+	switch graph.Kind {
+	case ir.Flowchart:
+		refs = flowchartReferences
+	case ir.State:
+		refs = stateReferences
+	case ir.Class:
+		refs = classReferences
+	default:
+		return nil
+	}
+
+	known := make(map[string]bool)
+	collectStateIDs(graph, known)
+	for _, sg := range graph.Subgraphs {
+		if sg.ID != nil {
+			known[*sg.ID] = true
+		}
+	}
+
+	var found []finding
+	for _, line := range src {
+		for _, stmt := range strings.Split(line.text, ";") {
+			keyword, ids := refs(strings.TrimSpace(stmt))
+			for _, id := range ids {
+				if id != "" && !known[id] {
+					found = append(found, finding{
+						line:    line.num,
+						message: fmt.Sprintf("%s statement references undefined node %q", keyword, id),
+					})
+				}
+			}
+		}
+	}
+	return found
+}
+
+// flowchartReferences returns the node IDs named by a flowchart class,
+// style or click statement.
+func flowchartReferences(stmt string) (string, []string) {
+	fields := strings.Fields(stmt)
+	if len(fields) < 2 { //nolint:mnd // keyword plus target.
+		return "", nil
+	}
+	switch keyword := strings.ToLower(fields[0]); keyword {
+	case "class":
+		return keyword, strings.Split(fields[1], ",")
+	case "style", "click":
+		return keyword, fields[1:2]
+	default:
+		return "", nil
+	}
+}
+
+// stateReferences returns the state IDs named by a class or style statement.
+func stateReferences(stmt string) (string, []string) {
+	keyword, ids := flowchartReferences(stmt)
+	if keyword == "click" {
+		return "", nil
+	}
+	return keyword, ids
+}
+
+// classReferences returns the class IDs named by a class diagram style,
+// cssClass, click, link or callback statement.
+func classReferences(stmt string) (string, []string) {
+	fields := strings.Fields(stmt)
+	if len(fields) < 2 { //nolint:mnd // keyword plus target.
+		return "", nil
+	}
+	switch keyword := strings.ToLower(fields[0]); keyword {
+	case "style", "click", "link", "callback":
+		return keyword, fields[1:2]
+	case "cssclass":
+		quoted := strings.TrimSpace(stmt[len(fields[0]):])
+		if !strings.HasPrefix(quoted, `"`) {
+			return "", nil
+		}
+		end := strings.Index(quoted[1:], `"`)
+		if end < 0 {
+			return "", nil
+		}
+		var ids []string
+		for _, id := range strings.Split(quoted[1:end+1], ",") {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+		return "cssClass", ids
+	default:
+		return "", nil
+	}
+}
+
+// collectStateIDs adds every node ID in graph and its composite states.
+func collectStateIDs(graph *ir.Graph, known map[string]bool) {
+	for id := range graph.Nodes {
+		known[id] = true
+	}
+	for _, cs := range graph.CompositeStates {
+		if cs.Inner != nil {
+			collectStateIDs(cs.Inner, known)
+		}
+		for _, region := range cs.Regions {
+			collectStateIDs(region, known)
+		}
+	}
+}
+
+// checkOrphanNodes reports nodes without edges in node-and-edge diagrams
+// that have at least one edge.
+func checkOrphanNodes(graph *ir.Graph, _ []sourceLine) []finding {
+	switch graph.Kind {
+	case ir.Flowchart, ir.Class, ir.State, ir.Er:
+	default:
+		return nil
+	}
+	if len(graph.Edges) == 0 {
+		return nil
+	}
+
+	connected := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		connected[edge.From] = true
+		connected[edge.To] = true
+	}
+	// Nodes inside a subgraph that is itself an edge endpoint are connected
+	// through it.
+	for _, sg := range graph.Subgraphs {
+		if sg.ID != nil && connected[*sg.ID] {
+			for _, id := range sg.Nodes {
+				connected[id] = true
+			}
+		}
+	}
+
+	var found []finding
+	for _, id := range nodeIDs(graph) {
+		if connected[id] {
+			continue
+		}
+		if _, composite := graph.CompositeStates[id]; composite {
+			continue
+		}
+		found = append(found, finding{message: fmt.Sprintf("node %q has no edges", id)})
+	}
+	return found
+}
+
+// checkDuplicateLabels reports distinct nodes, states or participants
+// that would be indistinguishable in the rendered diagram.
+func checkDuplicateLabels(graph *ir.Graph, _ []sourceLine) []finding {
+	labels := make(map[string][]string)
+	var order []string
+	add := func(label, id string) {
+		if label == "" {
+			return
+		}
+		if _, seen := labels[label]; !seen {
+			order = append(order, label)
+		}
+		labels[label] = append(labels[label], id)
+	}
+
+	switch graph.Kind {
+	case ir.Flowchart:
+		for _, id := range nodeIDs(graph) {
+			add(graph.Nodes[id].Label, id)
+		}
+	case ir.State:
+		for _, id := range nodeIDs(graph) {
+			if desc, ok := graph.StateDescriptions[id]; ok {
+				add(desc, id)
+			} else if id != "__start__" && id != "__end__" {
+				add(id, id)
+			}
+		}
+	case ir.Sequence:
+		for _, participant := range graph.Participants {
+			add(participant.DisplayName(), participant.ID)
+		}
+	default:
+		return nil
+	}
+
+	var found []finding
+	for _, label := range order {
+		if ids := labels[label]; len(ids) > 1 {
+			found = append(found, finding{
+				message: fmt.Sprintf("label %q is used by %s", label, quoteList(ids)),
+			})
+		}
+	}
+	return found
+}
+
+// checkGanttDependencies reports after and until references to unknown
+// task IDs, which the layout silently ignores.
+func checkGanttDependencies(graph *ir.Graph, _ []sourceLine) []finding {
+	if graph.Kind != ir.Gantt {
+		return nil
+	}
+	ids := make(map[string]bool)
+	for _, sec := range graph.GanttSections {
+		for _, task := range sec.Tasks {
+			if task.ID != "" {
+				ids[task.ID] = true
+			}
+		}
+	}
+
+	var found []finding
+	for _, sec := range graph.GanttSections {
+		for _, task := range sec.Tasks {
+			for _, dep := range task.AfterIDs {
+				if !ids[dep] {
+					found = append(found, finding{
+						message: fmt.Sprintf("task %q starts after unknown task %q", task.Label, dep),
+					})
+				}
+			}
+			if task.UntilID != "" && !ids[task.UntilID] {
+				found = append(found, finding{
+					message: fmt.Sprintf("task %q runs until unknown task %q", task.Label, task.UntilID),
+				})
+			}
+		}
+	}
+	return found
+}
+
+// checkSequenceActivations reports deactivations of participants that
+// have no open activation, including the "-" message shorthand.
+func checkSequenceActivations(graph *ir.Graph, _ []sourceLine) []finding {
+	if graph.Kind != ir.Sequence {
+		return nil
+	}
+	open := make(map[string]int)
+	var found []finding
+	for _, ev := range graph.Events {
+		switch ev.Kind {
+		case ir.EvActivate:
+			open[ev.Target]++
+		case ir.EvDeactivate:
+			if open[ev.Target] == 0 {
+				found = append(found, finding{
+					message: fmt.Sprintf("deactivate %q without a matching activate", ev.Target),
+				})
+				continue
+			}
+			open[ev.Target]--
+		default:
+		}
+	}
+	return found
+}
+
+// checkSankeyCycles reports flows that form a loop, which sankey layouts
+// cannot rank.
+func checkSankeyCycles(graph *ir.Graph, _ []sourceLine) []finding {
+	if graph.Kind != ir.Sankey {
+		return nil
+	}
+	adjacency := make(map[string][]string)
+	var nodes []string
+	seen := make(map[string]bool)
+	for _, link := range graph.SankeyLinks {
+		adjacency[link.Source] = append(adjacency[link.Source], link.Target)
+		for _, name := range []string{link.Source, link.Target} {
+			if !seen[name] {
+				seen[name] = true
+				nodes = append(nodes, name)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var found []finding
+	var visit func(string)
+	visit = func(node string) {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, next := range adjacency[node] {
+			switch state[next] {
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), next)
+				found = append(found, finding{
+					message: "flow cycle " + strings.Join(cycle, " -> "),
+				})
+			case unvisited:
+				visit(next)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = done
+	}
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+	return found
+}
+
+// checkGitBranches reports checkouts and merges of branches that do not
+// exist yet, and merges of a branch into itself.
+func checkGitBranches(graph *ir.Graph, _ []sourceLine) []finding {
+	if graph.Kind != ir.GitGraph {
+		return nil
+	}
+	mainBranch := graph.GitMainBranch
+	if mainBranch == "" {
+		mainBranch = "main"
+	}
+	branches := map[string]bool{mainBranch: true}
+	current := mainBranch
+
+	var found []finding
+	for _, action := range graph.GitActions {
+		switch act := action.(type) {
+		case *ir.GitBranch:
+			branches[act.Name] = true
+			current = act.Name
+		case *ir.GitCheckout:
+			if !branches[act.Branch] {
+				found = append(found, finding{
+					message: fmt.Sprintf("checkout of unknown branch %q", act.Branch),
+				})
+				continue
+			}
+			current = act.Branch
+		case *ir.GitMerge:
+			switch {
+			case !branches[act.Branch]:
+				found = append(found, finding{
+					message: fmt.Sprintf("merge of unknown branch %q into %q", act.Branch, current),
+				})
+			case act.Branch == current:
+				found = append(found, finding{
+					message: fmt.Sprintf("branch %q merged into itself", act.Branch),
+				})
+			}
+		}
+	}
+	return found
+}
+
+// checkPacketSyntax reports packet lines the parser skips because they are
+// not field statements.
+func checkPacketSyntax(graph *ir.Graph, src []sourceLine) []finding {
+	if graph.Kind != ir.Packet {
+		return nil
+	}
+	var found []finding
+	for idx, line := range src {
+		lower := strings.ToLower(line.text)
+		if idx == 0 && strings.HasPrefix(lower, "packet") {
+			continue
+		}
+		if strings.HasPrefix(lower, "title") || strings.HasPrefix(lower, "acctitle") || strings.HasPrefix(lower, "accdescr") {
+			continue
+		}
+		if _, ok := parser.ParsePacketField(line.text, 0); !ok {
+			found = append(found, finding{
+				line:    line.num,
+				message: fmt.Sprintf("%q is not a packet field; use start-end, bit or +count followed by a quoted label", line.text),
+			})
+		}
+	}
+	return found
+}
+
+// checkPacketRanges reports packet fields whose range is reversed and
+// fields that share bits with an earlier field.
+func checkPacketRanges(graph *ir.Graph, _ []sourceLine) []finding {
+	return packetFindings(graph, parser.PacketReversed, parser.PacketOverlap)
+}
+
+// checkPacketGaps reports bits, from bit 0 up to the last field, that no
+// field covers.
+func checkPacketGaps(graph *ir.Graph, _ []sourceLine) []finding {
+	return packetFindings(graph, parser.PacketGap)
+}
+
+// packetFindings returns the packet field problems of the given kinds, as
+// the renderer reports them.
+func packetFindings(graph *ir.Graph, kinds ...parser.PacketProblem) []finding {
+	if graph.Kind != ir.Packet {
+		return nil
+	}
+	var found []finding
+	for _, problem := range parser.CheckPacketFields(graph.Fields) {
+		if slices.Contains(kinds, problem.Problem) {
+			found = append(found, finding{message: problem.Message})
+		}
+	}
+	return found
+}
+
+// nodeIDs returns node IDs in declaration order.
+func nodeIDs(graph *ir.Graph) []string {
+	ids := make([]string, 0, len(graph.Nodes))
+	for id := range graph.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(idxA, idxB int) bool {
+		orderA, orderB := graph.NodeOrder[ids[idxA]], graph.NodeOrder[ids[idxB]]
+		if orderA != orderB {
+			return orderA < orderB
+		}
+		return ids[idxA] < ids[idxB]
+	})
+	return ids
+}
+
+// quoteList formats IDs as "a", "b" and "c".
+func quoteList(ids []string) string {
+	quoted := make([]string, len(ids))
+	for idx, id := range ids {
+		quoted[idx] = fmt.Sprintf("%q", id)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
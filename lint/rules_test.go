@@ -0,0 +1,184 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+// ruleMessages lints input and returns the messages reported by rule.
+func ruleMessages(t *testing.T, input, rule string) []string {
+	t.Helper()
+	diags, err := Lint(input)
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	var msgs []string
+	for _, diag := range diags {
+		if diag.Rule == rule {
+			msgs = append(msgs, diag.Message)
+		}
+	}
+	return msgs
+}
+
+func TestUndefinedReferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "flowchart",
+			input: "flowchart LR\n    A --> B\n    class A,Z hot\n    style Q fill:#f00\n    click B call cb()",
+			want:  []string{`class statement references undefined node "Z"`, `style statement references undefined node "Q"`},
+		},
+		{
+			name:  "flowchart subgraph",
+			input: "flowchart LR\n    subgraph grp\n    A --> B\n    end\n    style grp fill:#eee",
+		},
+		{
+			name:  "state nested",
+			input: "stateDiagram-v2\n    state Outer {\n        [*] --> Inner\n    }\n    class Inner,Missing hot",
+			want:  []string{`class statement references undefined node "Missing"`},
+		},
+		{
+			name:  "class diagram",
+			input: "classDiagram\n    Animal <|-- Dog\n    cssClass \"Dog,Cat\" pet\n    style Animal fill:#f00",
+			want:  []string{`cssClass statement references undefined node "Cat"`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ruleMessages(t, tc.input, "undefined-reference")
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOrphanNodes(t *testing.T) {
+	got := ruleMessages(t, "flowchart LR\n    A --> B\n    C\n    subgraph grp\n    D\n    end\n    B --> grp", "orphan-node")
+	if len(got) != 1 || !strings.Contains(got[0], `"C"`) {
+		t.Errorf("got %q, want only C", got)
+	}
+	if got := ruleMessages(t, "flowchart LR\n    A\n    B", "orphan-node"); len(got) != 0 {
+		t.Errorf("edge-less diagram reported %q", got)
+	}
+}
+
+func TestDuplicateLabels(t *testing.T) {
+	got := ruleMessages(t, "flowchart LR\n    A[Save] --> B[Save]\n    B --> C[Save]", "duplicate-label")
+	if len(got) != 1 || got[0] != `label "Save" is used by "A", "B" and "C"` {
+		t.Errorf("got %q", got)
+	}
+	got = ruleMessages(t, "sequenceDiagram\n    participant A as Server\n    participant B as Server\n    A->>B: hi", "duplicate-label")
+	if len(got) != 1 {
+		t.Errorf("sequence got %q", got)
+	}
+}
+
+func TestGanttDependencies(t *testing.T) {
+	input := `gantt
+    dateFormat YYYY-MM-DD
+    section S
+    One : a1, 2024-01-01, 3d
+    Two : after a1 zz, 2d
+    Three : 2024-01-02, until nope`
+	got := ruleMessages(t, input, "gantt-unknown-dependency")
+	want := []string{`task "Two" starts after unknown task "zz"`, `task "Three" runs until unknown task "nope"`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSequenceActivations(t *testing.T) {
+	input := `sequenceDiagram
+    A->>+B: call
+    B-->>-A: reply
+    deactivate B
+    activate A
+    deactivate A`
+	got := ruleMessages(t, input, "sequence-unmatched-deactivate")
+	if len(got) != 1 || got[0] != `deactivate "B" without a matching activate` {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSankeyCycles(t *testing.T) {
+	got := ruleMessages(t, "sankey-beta\nA,B,1\nB,C,1\nC,A,1\nC,D,1", "sankey-cycle")
+	if len(got) != 1 || got[0] != "flow cycle A -> B -> C -> A" {
+		t.Errorf("got %q", got)
+	}
+	if got := ruleMessages(t, "sankey-beta\nA,B,1\nA,C,1\nB,C,1", "sankey-cycle"); len(got) != 0 {
+		t.Errorf("acyclic got %q", got)
+	}
+}
+
+func TestGitBranches(t *testing.T) {
+	input := `gitGraph
+    commit
+    branch dev
+    commit
+    checkout main
+    merge dev
+    merge feature
+    checkout nowhere
+    merge main`
+	got := ruleMessages(t, input, "gitgraph-unknown-branch")
+	want := []string{
+		`merge of unknown branch "feature" into "main"`,
+		`checkout of unknown branch "nowhere"`,
+		`branch "main" merged into itself`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPacketSyntax(t *testing.T) {
+	input := `packet-beta
+title TCP
+0-15: "Source Port"
+16-31 "Destination Port"
++32: "Sequence Number"`
+	got := ruleMessages(t, input, "packet-syntax")
+	want := `"16-31 \"Destination Port\"" is not a packet field; use start-end, bit or +count followed by a quoted label`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPacketRanges(t *testing.T) {
+	input := `packet-beta
+0-15: "Source Port"
+8-23: "Overlap"
+31-24: "Backwards"
+24: "Flag"`
+	got := ruleMessages(t, input, "packet-range")
+	want := []string{
+		`field "Overlap" (bits 8-23) overlaps field "Source Port" (bits 0-15)`,
+		`field "Backwards" ends at bit 24 before it starts at bit 31`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPacketGaps(t *testing.T) {
+	input := `packet-beta
+4-15: "Late Start"
+20: "Flag"
+21-31: "Rest"`
+	got := ruleMessages(t, input, "packet-gap")
+	want := []string{
+		`bits 0-3 before field "Late Start" are not covered by any field`,
+		`bits 16-19 before field "Flag" are not covered by any field`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := ruleMessages(t, "packet-beta\n+16: \"A\"\n+16: \"B\"", "packet-gap"); len(got) != 0 {
+		t.Errorf("contiguous got %q", got)
+	}
+}
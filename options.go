@@ -1,6 +1,8 @@
 package gomd2svg
 
 import (
+	"time"
+
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/parser"
 	"github.com/jamesainslie/gomd2svg/theme"
@@ -14,6 +16,25 @@ type Options struct {
 	// Theme provides a custom theme. Ignored if ThemeName is set.
 	Theme  *theme.Theme
 	Layout *config.Layout
+	// Scale multiplies the pixel dimensions of raster output such as
+	// RenderPNG, and the page size of RenderPDF output sized to the
+	// diagram. Zero means 1.
+	Scale float64
+	// Paper selects a fixed RenderPDF page size such as "a4" or "letter",
+	// with the diagram scaled to fit. Empty sizes the page to the diagram.
+	Paper string
+	// Landscape turns a fixed RenderPDF paper size sideways.
+	Landscape bool
+	// PageHeight splits long sequence diagrams rendered by RenderPages
+	// into pages no taller than this many pixels. Zero keeps one page.
+	PageHeight float32
+	// Now is the clock for gantt today markers. Nil means time.Now; set a
+	// fixed clock for reproducible output.
+	Now func() time.Time
+	// Icons resolves icon names such as "logos:aws-lambda" in architecture
+	// services and groups, flowchart icon nodes and mindmap ::icon()
+	// decorations. Nil uses only the built-in architecture glyphs.
+	Icons *IconRegistry
 }
 
 func (o Options) resolveTheme(dir parser.Directive) *theme.Theme {
@@ -60,10 +81,22 @@ func (o Options) resolveTheme(dir parser.Directive) *theme.Theme {
 }
 
 func (o Options) layoutOrDefault() *config.Layout {
-	if o.Layout != nil {
-		return o.Layout
+	cfg := o.Layout
+	if cfg == nil {
+		cfg = config.DefaultLayout()
+	}
+	if o.Now != nil || o.Icons != nil {
+		// Copy so the caller's layout is not changed.
+		withOptions := *cfg
+		if o.Now != nil {
+			withOptions.Gantt.Now = o.Now
+		}
+		if o.Icons != nil {
+			withOptions.Icons = o.Icons
+		}
+		cfg = &withOptions
 	}
-	return config.DefaultLayout()
+	return cfg
 }
 
 // Result holds the rendered SVG and per-stage timing information.
@@ -0,0 +1,46 @@
+package gomd2svg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// PacketField is one row of a packet diagram's field table.
+type PacketField struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Bits        int    `json:"bits"`
+	Description string `json:"description"`
+}
+
+// PacketFields parses a packet diagram and returns its fields in source
+// order, with bit-count fields resolved to absolute ranges. It returns an
+// error when the input is not a packet diagram.
+func PacketFields(input string) ([]PacketField, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("mermaid: empty input")
+	}
+
+	parsed, err := parser.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	if parsed.Graph.Kind != ir.Packet {
+		return nil, errors.New("mermaid: not a packet diagram")
+	}
+
+	fields := make([]PacketField, len(parsed.Graph.Fields))
+	for idx, field := range parsed.Graph.Fields {
+		fields[idx] = PacketField{
+			Start:       field.Start,
+			End:         field.End,
+			Bits:        max(field.End-field.Start+1, 0),
+			Description: field.Description,
+		}
+	}
+	return fields, nil
+}
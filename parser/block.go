@@ -11,9 +11,12 @@ import (
 const directedArrow = "-->"
 
 var (
-	blockColumnsRe = regexp.MustCompile(`^columns\s+(\d+)$`)
-	blockEdgeRe    = regexp.MustCompile(`^(\w+)\s*(-->|---)\s*(?:\|"?([^"|]*)"?\|\s*)?(\w+)$`)
-	blockDefRe     = regexp.MustCompile(`^(\w+)(?:\["([^"]*)"\]|\("([^"]*)"\)|\(\("([^"]*)"\)\)|\{"([^"]*)"\}|>\["([^"]*)"\])?(?::(\d+))?\s*$`)
+	blockColumnsRe   = regexp.MustCompile(`^columns\s+(\d+)$`)
+	blockEdgeRe      = regexp.MustCompile(`^(\w+)\s*(?:--\s*"([^"]*)"\s*)?(-->|---)\s*(?:\|"?([^"|]*)"?\|\s*)?(\w+)$`)
+	blockDefRe       = regexp.MustCompile(`^(\w+)(?:\[\("([^"]*)"\)\]|\["([^"]*)"\]|\("([^"]*)"\)|\(\("([^"]*)"\)\)|\{"([^"]*)"\}|>\["([^"]*)"\])?(?::(\d+))?\s*$`)
+	blockCompositeRe = regexp.MustCompile(`^block(?::(\w+))?(?:\["([^"]*)"\])?(?::(\d+))?$`)
+	blockSpaceRe     = regexp.MustCompile(`^space(?::(\d+))?$`)
+	blockArrowRe     = regexp.MustCompile(`^(\w+)<\["([^"]*)"\]>\((right|left|up|down|x|y)\)(?::(\d+))?$`)
 )
 
 //nolint:unparam // error return is part of the parser interface contract used by Parse().
@@ -29,17 +32,52 @@ func parseBlock(input string) (*ParseOutput, error) {
 		}
 	}
 
+	// stack holds the open composite blocks; definitions go to the
+	// innermost one.
+	var stack []*ir.BlockDef
+	anonymous := 0
 	for _, line := range lines {
 		if match := blockColumnsRe.FindStringSubmatch(line); match != nil {
 			cols, errConv := strconv.Atoi(match[1])
 			if errConv == nil {
-				graph.BlockColumns = cols
+				if len(stack) > 0 {
+					stack[len(stack)-1].Columns = cols
+				} else {
+					graph.BlockColumns = cols
+				}
 			}
 			continue
 		}
 
+		if match := blockCompositeRe.FindStringSubmatch(line); match != nil {
+			id := match[1]
+			if id == "" {
+				anonymous++
+				id = "block" + strconv.Itoa(anonymous)
+			}
+			block := &ir.BlockDef{ID: id, Label: match[2], Shape: ir.Rectangle, Width: parseBlockSpan(match[3])}
+			addBlock(graph, stack, block)
+			stack = append(stack, block)
+			continue
+		}
+
+		if line == "end" {
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		if parseStyleStatement(graph, line) {
+			continue
+		}
+
 		if match := blockEdgeRe.FindStringSubmatch(line); match != nil {
-			from, arrow, label, to := match[1], match[2], match[3], match[4]
+			from, arrow, to := match[1], match[3], match[5]
+			label := match[2]
+			if label == "" {
+				label = match[4]
+			}
 			edge := &ir.Edge{
 				From:     from,
 				To:       to,
@@ -55,15 +93,54 @@ func parseBlock(input string) (*ParseOutput, error) {
 			continue
 		}
 
-		parseBlockDefs(line, graph)
+		parseBlockDefs(line, graph, stack)
 	}
 
 	return &ParseOutput{Graph: graph}, nil
 }
 
-func parseBlockDefs(line string, graph *ir.Graph) {
-	tokens := strings.Fields(line)
-	for _, token := range tokens {
+// addBlock adds a block to the innermost open composite block, or to the
+// top level. Blocks other than spaces become graph nodes.
+func addBlock(graph *ir.Graph, stack []*ir.BlockDef, block *ir.BlockDef) {
+	if len(stack) > 0 {
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, block)
+	} else {
+		graph.Blocks = append(graph.Blocks, block)
+	}
+	if block.Kind != ir.BlockSpace {
+		label := block.Label
+		shape := block.Shape
+		graph.EnsureNode(block.ID, &label, &shape)
+	}
+}
+
+// parseBlockSpan parses a ":N" column span, defaulting to 1.
+func parseBlockSpan(text string) int {
+	if span, err := strconv.Atoi(text); err == nil && span > 0 {
+		return span
+	}
+	return 1
+}
+
+func parseBlockDefs(line string, graph *ir.Graph, stack []*ir.BlockDef) {
+	for _, token := range splitBlockTokens(line) {
+		if match := blockSpaceRe.FindStringSubmatch(token); match != nil {
+			addBlock(graph, stack, &ir.BlockDef{Kind: ir.BlockSpace, Width: parseBlockSpan(match[1])})
+			continue
+		}
+		if match := blockArrowRe.FindStringSubmatch(token); match != nil {
+			addBlock(graph, stack, &ir.BlockDef{
+				ID:       match[1],
+				Label:    match[2],
+				Shape:    ir.Rectangle,
+				Width:    parseBlockSpan(match[4]),
+				Kind:     ir.BlockArrow,
+				ArrowDir: parseBlockArrowDirection(match[3]),
+			})
+			continue
+		}
+
 		match := blockDefRe.FindStringSubmatch(token)
 		if match == nil {
 			continue
@@ -74,36 +151,73 @@ func parseBlockDefs(line string, graph *ir.Graph) {
 		switch {
 		case match[2] != "":
 			label = match[2]
-			shape = ir.Rectangle
+			shape = ir.Cylinder
 		case match[3] != "":
 			label = match[3]
-			shape = ir.RoundRect
+			shape = ir.Rectangle
 		case match[4] != "":
 			label = match[4]
-			shape = ir.Circle
+			shape = ir.RoundRect
 		case match[5] != "":
 			label = match[5]
-			shape = ir.Diamond
+			shape = ir.Circle
 		case match[6] != "":
 			label = match[6]
+			shape = ir.Diamond
+		case match[7] != "":
+			label = match[7]
 			shape = ir.Asymmetric
 		}
 
-		width := 1
-		if match[7] != "" {
-			parsed, errConv := strconv.Atoi(match[7])
-			if errConv == nil {
-				width = parsed
-			}
-		}
-
-		block := &ir.BlockDef{
+		addBlock(graph, stack, &ir.BlockDef{
 			ID:    id,
 			Label: label,
 			Shape: shape,
-			Width: width,
+			Width: parseBlockSpan(match[8]),
+		})
+	}
+}
+
+// splitBlockTokens splits a line of block definitions on whitespace
+// outside quotes, so labels may contain spaces.
+func splitBlockTokens(line string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, char := range line {
+		switch {
+		case char == '"':
+			quoted = !quoted
+			current.WriteRune(char)
+		case !quoted && (char == ' ' || char == '\t'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(char)
 		}
-		graph.Blocks = append(graph.Blocks, block)
-		graph.EnsureNode(id, &label, &shape)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseBlockArrowDirection maps a block arrow's direction keyword.
+func parseBlockArrowDirection(keyword string) ir.BlockArrowDirection {
+	switch keyword {
+	case "left":
+		return ir.BlockArrowLeft
+	case "up":
+		return ir.BlockArrowUp
+	case "down":
+		return ir.BlockArrowDown
+	case "x":
+		return ir.BlockArrowX
+	case "y":
+		return ir.BlockArrowY
+	default:
+		return ir.BlockArrowRight
 	}
 }
@@ -78,3 +78,103 @@ func TestParseBlockEmpty(t *testing.T) {
 		t.Errorf("Blocks = %d, want 0", len(out.Graph.Blocks))
 	}
 }
+
+func TestParseBlockSpacesAndArrows(t *testing.T) {
+	input := `block-beta
+columns 3
+a["Start here"] space:1 b
+arrow<["go"]>(down):2 space`
+
+	out, err := parseBlock(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	blocks := out.Graph.Blocks
+	if len(blocks) != 5 {
+		t.Fatalf("Blocks = %d, want 5", len(blocks))
+	}
+	if blocks[0].Label != "Start here" {
+		t.Errorf("a label = %q, want %q", blocks[0].Label, "Start here")
+	}
+	if blocks[1].Kind != ir.BlockSpace || blocks[4].Kind != ir.BlockSpace {
+		t.Errorf("kinds = %v, %v, want space", blocks[1].Kind, blocks[4].Kind)
+	}
+	if _, ok := out.Graph.Nodes[""]; ok {
+		t.Error("spaces should not become nodes")
+	}
+	arrow := blocks[3]
+	if arrow.Kind != ir.BlockArrow || arrow.ArrowDir != ir.BlockArrowDown || arrow.Width != 2 || arrow.Label != "go" {
+		t.Errorf("arrow = %+v", *arrow)
+	}
+}
+
+func TestParseBlockComposite(t *testing.T) {
+	input := `block-beta
+columns 2
+block:group["Group"]:2
+  columns 1
+  a b
+  block
+    c
+  end
+end
+d`
+
+	out, err := parseBlock(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if graph.BlockColumns != 2 {
+		t.Errorf("BlockColumns = %d, want 2", graph.BlockColumns)
+	}
+	if len(graph.Blocks) != 2 {
+		t.Fatalf("Blocks = %d, want 2", len(graph.Blocks))
+	}
+	group := graph.Blocks[0]
+	if group.ID != "group" || group.Label != "Group" || group.Width != 2 || group.Columns != 1 {
+		t.Errorf("group = %+v", *group)
+	}
+	if len(group.Children) != 3 {
+		t.Fatalf("group children = %d, want 3", len(group.Children))
+	}
+	inner := group.Children[2]
+	if inner.ID != "block1" || len(inner.Children) != 1 || inner.Children[0].ID != "c" {
+		t.Errorf("anonymous block = %+v", *inner)
+	}
+	if graph.Blocks[1].ID != "d" {
+		t.Errorf("Blocks[1] = %q, want d", graph.Blocks[1].ID)
+	}
+}
+
+func TestParseBlockStylesAndLabeledEdge(t *testing.T) {
+	input := `block-beta
+a b db[("Store")]
+a -- "writes" --> db
+classDef hot fill:#f96,stroke:#333,stroke-width:2px
+class a,b hot
+style db fill:#9cf,stroke-dasharray: 5 5`
+
+	out, err := parseBlock(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if graph.Blocks[2].Shape != ir.Cylinder {
+		t.Errorf("db shape = %v, want Cylinder", graph.Blocks[2].Shape)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0].Label == nil || *graph.Edges[0].Label != "writes" {
+		t.Fatalf("Edges = %+v, want a labeled edge", graph.Edges)
+	}
+	hot := graph.ClassDefs["hot"]
+	if hot == nil || hot.Fill == nil || *hot.Fill != "#f96" || hot.StrokeWidth == nil || *hot.StrokeWidth != 2 {
+		t.Errorf("classDef hot = %+v", hot)
+	}
+	if got := graph.NodeClasses["b"]; len(got) != 1 || got[0] != "hot" {
+		t.Errorf("b classes = %v, want [hot]", got)
+	}
+	style := graph.NodeStyles["db"]
+	if style == nil || style.Fill == nil || *style.Fill != "#9cf" || style.StrokeDasharray == nil || *style.StrokeDasharray != "5 5" {
+		t.Errorf("db style = %+v", style)
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -14,12 +15,25 @@ var (
 			`|Component|Component_Ext)\s*\((.+)\)\s*$`,
 	)
 	c4BoundaryRe = regexp.MustCompile(
-		`^(Enterprise_Boundary|System_Boundary|Container_Boundary|Boundary)` +
-			`\s*\(([^,]+),\s*"([^"]*)"(?:\s*,\s*"([^"]*)")?\)\s*\{?\s*$`,
+		`^(Enterprise_Boundary|System_Boundary|Container_Boundary|Boundary` +
+			`|Deployment_Node|Node|Node_L|Node_R)\s*\((.+)\)\s*\{?\s*$`,
 	)
 	c4RelRe = regexp.MustCompile(
-		`^(Rel|Rel_Back|Rel_Neighbor|Rel_Back_Neighbor|BiRel|BiRel_Neighbor)\s*\((.+)\)\s*$`,
+		`^(Rel|Rel_Back|Rel_Neighbor|Rel_Back_Neighbor|BiRel|BiRel_Neighbor` +
+			`|Rel_U|Rel_Up|Rel_D|Rel_Down|Rel_L|Rel_Left|Rel_R|Rel_Right)\s*\((.+)\)\s*$`,
 	)
+	c4UpdateRe = regexp.MustCompile(
+		`^(UpdateElementStyle|UpdateRelStyle|UpdateBoundaryStyle|UpdateLayoutConfig)\s*\((.*)\)\s*$`,
+	)
+)
+
+// Positional parameters of the Update* statements after their subject.
+//
+//nolint:gochecknoglobals // read-only lookup tables.
+var (
+	c4StyleParams    = []string{"bgcolor", "fontcolor", "bordercolor", "shadowing", "shape", "sprite", "techn", "legendtext", "legendsprite"}
+	c4RelStyleParams = []string{"textcolor", "linecolor", "offsetx", "offsety"}
+	c4LayoutParams   = []string{"c4shapeinrow", "c4boundaryinrow"}
 )
 
 //nolint:unparam // error return is part of the parser interface contract used by Parse().
@@ -34,6 +48,7 @@ func parseC4(input string) (*ParseOutput, error) {
 	}
 
 	var boundaryStack []*ir.C4Boundary
+	var relStyles []map[string]string
 
 	for _, line := range lines {
 		if strings.TrimSpace(line) == "}" {
@@ -44,31 +59,41 @@ func parseC4(input string) (*ParseOutput, error) {
 		}
 
 		if match := c4BoundaryRe.FindStringSubmatch(line); match != nil {
-			boundaryType := match[1]
-			id := strings.TrimSpace(match[2])
-			label := match[3]
-			var bType string
-			switch boundaryType {
+			args := parseC4Args(match[2])
+			if len(args) < 2 {
+				continue
+			}
+			boundary := &ir.C4Boundary{
+				ID:    args[0],
+				Label: args[1],
+			}
+			switch match[1] {
 			case "Enterprise_Boundary":
-				bType = "Enterprise"
+				boundary.Type = "Enterprise"
 			case "System_Boundary":
-				bType = "Software System"
+				boundary.Type = "Software System"
 			case "Container_Boundary":
-				bType = "Container"
+				boundary.Type = "Container"
+			case "Boundary":
+				boundary.Type = c4PositionalArg(args, 2)
 			default:
-				bType = match[4]
+				boundary.Deployment = true
+				boundary.Type = c4PositionalArg(args, 2)
+				boundary.Description = c4PositionalArg(args, 3)
 			}
-
-			boundary := &ir.C4Boundary{
-				ID:    id,
-				Label: label,
-				Type:  bType,
+			if len(boundaryStack) > 0 {
+				boundary.ParentID = boundaryStack[len(boundaryStack)-1].ID
 			}
 			graph.C4Boundaries = append(graph.C4Boundaries, boundary)
 			boundaryStack = append(boundaryStack, boundary)
 			continue
 		}
 
+		if match := c4UpdateRe.FindStringSubmatch(line); match != nil {
+			relStyles = parseC4Update(graph, match[1], parseC4Args(match[2]), relStyles)
+			continue
+		}
+
 		if match := c4ElementRe.FindStringSubmatch(line); match != nil {
 			elemType := parseC4ElementType(match[1])
 			args := parseC4Args(match[2])
@@ -80,15 +105,11 @@ func parseC4(input string) (*ParseOutput, error) {
 				Label: args[1],
 				Type:  elemType,
 			}
-			if len(args) > 2 {
-				if elemType.IsPerson() {
-					elem.Description = args[2]
-				} else {
-					elem.Technology = args[2]
-					if len(args) > 3 {
-						elem.Description = args[3]
-					}
-				}
+			if elemType.IsPerson() {
+				elem.Description = c4PositionalArg(args, 2)
+			} else {
+				elem.Technology = c4PositionalArg(args, 2)
+				elem.Description = c4PositionalArg(args, 3)
 			}
 
 			if len(boundaryStack) > 0 {
@@ -109,29 +130,147 @@ func parseC4(input string) (*ParseOutput, error) {
 				continue
 			}
 			rel := &ir.C4Rel{
-				From:  args[0],
-				To:    args[1],
-				Label: args[2],
-			}
-			if len(args) > 3 {
-				rel.Technology = args[3]
+				From:          args[0],
+				To:            args[1],
+				Label:         args[2],
+				Technology:    c4PositionalArg(args, 3),
+				Description:   c4PositionalArg(args, 4),
+				Direction:     parseC4RelDirection(match[1]),
+				Bidirectional: strings.HasPrefix(match[1], "BiRel"),
 			}
 			graph.C4Rels = append(graph.C4Rels, rel)
 			relLabel := rel.Label
 			graph.Edges = append(graph.Edges, &ir.Edge{
-				From:     rel.From,
-				To:       rel.To,
-				Label:    &relLabel,
-				Directed: true,
-				ArrowEnd: true,
+				From:       rel.From,
+				To:         rel.To,
+				Label:      &relLabel,
+				Directed:   true,
+				ArrowEnd:   true,
+				ArrowStart: rel.Bidirectional,
 			})
 			continue
 		}
 	}
 
+	// UpdateRelStyle may come before or after the relationships it styles.
+	for _, style := range relStyles {
+		for _, rel := range graph.C4Rels {
+			if rel.From == style["from"] && rel.To == style["to"] {
+				applyC4RelStyle(rel, style)
+			}
+		}
+	}
+
 	return &ParseOutput{Graph: graph}, nil
 }
 
+// parseC4Update applies an UpdateElementStyle, UpdateBoundaryStyle or
+// UpdateLayoutConfig statement to the graph. UpdateRelStyle arguments are
+// returned appended to relStyles, to be applied once every relationship
+// is known.
+func parseC4Update(graph *ir.Graph, statement string, args []string, relStyles []map[string]string) []map[string]string {
+	switch statement {
+	case "UpdateElementStyle", "UpdateBoundaryStyle":
+		if len(args) == 0 {
+			return relStyles
+		}
+		named := c4NamedArgs(args[1:], c4StyleParams)
+		style := &ir.C4Style{
+			BgColor:     named["bgcolor"],
+			FontColor:   named["fontcolor"],
+			BorderColor: named["bordercolor"],
+			Shape:       named["shape"],
+			LegendText:  named["legendtext"],
+		}
+		if statement == "UpdateBoundaryStyle" {
+			if graph.C4BoundaryStyles == nil {
+				graph.C4BoundaryStyles = make(map[string]*ir.C4Style)
+			}
+			graph.C4BoundaryStyles[args[0]] = style
+		} else {
+			if graph.C4ElementStyles == nil {
+				graph.C4ElementStyles = make(map[string]*ir.C4Style)
+			}
+			graph.C4ElementStyles[args[0]] = style
+		}
+	case "UpdateRelStyle":
+		if len(args) < 2 {
+			return relStyles
+		}
+		named := c4NamedArgs(args[2:], c4RelStyleParams)
+		named["from"], named["to"] = args[0], args[1]
+		relStyles = append(relStyles, named)
+	case "UpdateLayoutConfig":
+		named := c4NamedArgs(args, c4LayoutParams)
+		if count, err := strconv.Atoi(named["c4shapeinrow"]); err == nil && count > 0 {
+			graph.C4ShapeInRow = count
+		}
+		if count, err := strconv.Atoi(named["c4boundaryinrow"]); err == nil && count > 0 {
+			graph.C4BoundaryInRow = count
+		}
+	}
+	return relStyles
+}
+
+// applyC4RelStyle copies UpdateRelStyle values onto a relationship.
+func applyC4RelStyle(rel *ir.C4Rel, style map[string]string) {
+	if color := style["textcolor"]; color != "" {
+		rel.TextColor = color
+	}
+	if color := style["linecolor"]; color != "" {
+		rel.LineColor = color
+	}
+	if offset, err := strconv.ParseFloat(style["offsetx"], 32); err == nil {
+		rel.OffsetX = float32(offset)
+	}
+	if offset, err := strconv.ParseFloat(style["offsety"], 32); err == nil {
+		rel.OffsetY = float32(offset)
+	}
+}
+
+// c4NamedArgs maps macro arguments to parameter names. Arguments written
+// as $name=value are matched by name, case-insensitively; the others take
+// the positional names in order. Empty positional arguments are skipped
+// over, so `UpdateRelStyle(a, b, , "red")` sets only the line colour.
+func c4NamedArgs(args, positional []string) map[string]string {
+	named := make(map[string]string)
+	for idx, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "$") {
+			named[strings.ToLower(strings.TrimSpace(name[1:]))] = strings.Trim(strings.TrimSpace(value), `"`)
+			continue
+		}
+		if idx < len(positional) && arg != "" {
+			named[positional[idx]] = arg
+		}
+	}
+	return named
+}
+
+// c4PositionalArg returns the idx-th macro argument, or "" when it is
+// missing or a $name=value argument.
+func c4PositionalArg(args []string, idx int) string {
+	if idx >= len(args) || strings.HasPrefix(args[idx], "$") {
+		return ""
+	}
+	return args[idx]
+}
+
+// parseC4RelDirection returns the placement hint of a Rel_* macro.
+func parseC4RelDirection(macro string) ir.C4RelDirection {
+	switch macro {
+	case "Rel_U", "Rel_Up":
+		return ir.C4RelUp
+	case "Rel_D", "Rel_Down":
+		return ir.C4RelDown
+	case "Rel_L", "Rel_Left":
+		return ir.C4RelLeft
+	case "Rel_R", "Rel_Right":
+		return ir.C4RelRight
+	default:
+		return ir.C4RelNone
+	}
+}
+
 func parseC4Kind(line string) ir.C4Kind {
 	lower := strings.ToLower(strings.TrimSpace(line))
 	switch {
@@ -112,3 +112,95 @@ func TestParseC4Args(t *testing.T) {
 		}
 	}
 }
+
+func TestParseC4DeploymentNodes(t *testing.T) {
+	input := `C4Deployment
+Deployment_Node(cloud, "Cloud", "AWS", "eu-west-1") {
+  Node_L(web, "Web Tier", "ECS") {
+    Container(api, "API", "Go")
+  }
+}`
+	out, err := parseC4(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	boundaries := out.Graph.C4Boundaries
+	if len(boundaries) != 2 {
+		t.Fatalf("Boundaries = %d, want 2", len(boundaries))
+	}
+	cloud, web := boundaries[0], boundaries[1]
+	if !cloud.Deployment || cloud.Type != "AWS" || cloud.Description != "eu-west-1" {
+		t.Errorf("cloud = %+v", cloud)
+	}
+	if cloud.ParentID != "" || web.ParentID != "cloud" {
+		t.Errorf("parents = %q, %q; want \"\", \"cloud\"", cloud.ParentID, web.ParentID)
+	}
+	if len(web.Children) != 1 || web.Children[0] != "api" {
+		t.Errorf("web children = %v, want [api]", web.Children)
+	}
+}
+
+func TestParseC4DirectionalRels(t *testing.T) {
+	input := `C4Context
+System(a, "A")
+System(b, "B")
+Rel_U(a, b, "up")
+Rel_Down(a, b, "down")
+Rel_L(a, b, "left")
+Rel_Right(a, b, "right")
+Rel(a, b, "plain")
+BiRel(a, b, "both")`
+	out, err := parseC4(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := []ir.C4RelDirection{ir.C4RelUp, ir.C4RelDown, ir.C4RelLeft, ir.C4RelRight, ir.C4RelNone, ir.C4RelNone}
+	if len(out.Graph.C4Rels) != len(want) {
+		t.Fatalf("Rels = %d, want %d", len(out.Graph.C4Rels), len(want))
+	}
+	for idx, rel := range out.Graph.C4Rels {
+		if rel.Direction != want[idx] {
+			t.Errorf("rel %q direction = %v, want %v", rel.Label, rel.Direction, want[idx])
+		}
+	}
+	if !out.Graph.C4Rels[5].Bidirectional || !out.Graph.Edges[5].ArrowStart {
+		t.Error("BiRel should be bidirectional with a start arrow")
+	}
+}
+
+func TestParseC4UpdateStatements(t *testing.T) {
+	input := `C4Context
+System(a, "A")
+System(b, "B")
+UpdateRelStyle(a, b, $textColor="red", $offsetY="-10")
+Rel(a, b, "uses")
+UpdateElementStyle(a, $bgColor="grey", $shape="EightSidedShape", $legendText="Legacy")
+UpdateElementStyle(external_system, "#999999", "white")
+UpdateBoundaryStyle(sys, $borderColor="green")
+UpdateLayoutConfig($c4ShapeInRow="2", $c4BoundaryInRow="1")`
+	out, err := parseC4(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+
+	styleA := graph.C4ElementStyles["a"]
+	if styleA == nil || styleA.BgColor != "grey" || styleA.Shape != "EightSidedShape" || styleA.LegendText != "Legacy" {
+		t.Errorf("style a = %+v", styleA)
+	}
+	external := graph.C4ElementStyles["external_system"]
+	if external == nil || external.BgColor != "#999999" || external.FontColor != "white" {
+		t.Errorf("style external_system = %+v", external)
+	}
+	if style := graph.C4BoundaryStyles["sys"]; style == nil || style.BorderColor != "green" {
+		t.Errorf("boundary style = %+v", style)
+	}
+
+	rel := graph.C4Rels[0]
+	if rel.TextColor != "red" || rel.OffsetY != -10 || rel.LineColor != "" {
+		t.Errorf("rel style = %q %q %v", rel.TextColor, rel.LineColor, rel.OffsetY)
+	}
+	if graph.C4ShapeInRow != 2 || graph.C4BoundaryInRow != 1 {
+		t.Errorf("layout config = %d, %d; want 2, 1", graph.C4ShapeInRow, graph.C4BoundaryInRow)
+	}
+}
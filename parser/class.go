@@ -68,8 +68,9 @@ func parseClass(input string) (*ParseOutput, error) { //nolint:unparam // error
 			continue
 		}
 
-		// Skip directives.
+		// Skip directives, keeping click and link URLs as node links.
 		if classDirectiveSkipRe.MatchString(line) {
+			addClickLink(graph, line)
 			continue
 		}
 
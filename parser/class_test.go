@@ -258,3 +258,16 @@ class Foo {
 		t.Error("expected staticMethod with ClassifierStatic")
 	}
 }
+
+func TestParseClassLinks(t *testing.T) {
+	out, err := Parse("classDiagram\nclass Shape\nclass Circle\nlink Shape \"https://example.com/shape\" \"Docs\"\nclick Circle href \"https://example.com/circle\"")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if link := out.Graph.NodeLinks["Shape"]; link == nil || link.URL != "https://example.com/shape" {
+		t.Errorf("Shape link = %+v", link)
+	}
+	if link := out.Graph.NodeLinks["Circle"]; link == nil || link.URL != "https://example.com/circle" {
+		t.Errorf("Circle link = %+v", link)
+	}
+}
@@ -4,12 +4,72 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
 )
 
 // Directive holds parsed %%{init: ...}%% values.
 type Directive struct {
-	Theme          string         `json:"theme"`
-	ThemeVariables ThemeVariables `json:"themeVariables"`
+	Theme          string            `json:"theme"`
+	ThemeVariables ThemeVariables    `json:"themeVariables"`
+	GitGraph       GitGraphDirective `json:"gitGraph"`
+	Sequence       SequenceDirective `json:"sequence"`
+	Gantt          GanttDirective    `json:"gantt"`
+	XYChart        XYChartDirective  `json:"xyChart"`
+	C4             C4Directive       `json:"c4"`
+	Sankey         SankeyDirective   `json:"sankey"`
+	Treemap        TreemapDirective  `json:"treemap"`
+	Packet         PacketDirective   `json:"packet"`
+	// Layout names a layout algorithm; mindmaps accept "tidy-tree".
+	Layout string `json:"layout"`
+	// Wrap is set by a %%{wrap}%% directive.
+	Wrap bool `json:"-"`
+}
+
+// GitGraphDirective holds gitGraph settings from directives.
+type GitGraphDirective struct {
+	ParallelCommits bool `json:"parallelCommits"`
+}
+
+// GanttDirective holds gantt chart settings from directives.
+type GanttDirective struct {
+	TopAxis bool `json:"topAxis"`
+}
+
+// XYChartDirective holds XY chart settings from directives.
+type XYChartDirective struct {
+	ShowDataLabel bool   `json:"showDataLabel"`
+	NumberFormat  string `json:"numberFormat"`
+}
+
+// C4Directive holds C4 diagram settings from directives.
+type C4Directive struct {
+	Layout string `json:"layout"`
+}
+
+// SankeyDirective holds Sankey diagram settings from directives.
+type SankeyDirective struct {
+	NodeAlignment string `json:"nodeAlignment"`
+	LinkColor     string `json:"linkColor"`
+	ShowValues    *bool  `json:"showValues"`
+}
+
+// TreemapDirective holds treemap settings from directives.
+type TreemapDirective struct {
+	ValueFormat string `json:"valueFormat"`
+	MaxDepth    int    `json:"maxDepth"`
+}
+
+// PacketDirective holds packet diagram settings from directives.
+type PacketDirective struct {
+	BitsPerRow int   `json:"bitsPerRow"`
+	ShowBits   *bool `json:"showBits"`
+	BitRuler   *bool `json:"bitRuler"`
+}
+
+// SequenceDirective holds sequence diagram settings from directives.
+type SequenceDirective struct {
+	Wrap bool `json:"wrap"`
 }
 
 // ThemeVariables holds theme field overrides from directives.
@@ -23,10 +83,17 @@ type ThemeVariables struct {
 
 var directiveRe = regexp.MustCompile(`(?m)^\s*%%\{init:\s*(.*?)\}%%\s*$`)
 
+var wrapDirectiveRe = regexp.MustCompile(`(?m)^\s*%%\{\s*wrap\s*\}%%\s*\n?`)
+
 // extractDirective finds and removes a %%{init: ...}%% directive from input.
-// Returns the parsed directive and the input with the directive line removed.
+// A %%{wrap}%% directive is removed too and recorded in Directive.Wrap.
+// Returns the parsed directive and the input with the directive lines removed.
 func extractDirective(input string) (Directive, string) {
 	var dir Directive
+	if wrapDirectiveRe.MatchString(input) {
+		dir.Wrap = true
+		input = wrapDirectiveRe.ReplaceAllString(input, "")
+	}
 	loc := directiveRe.FindStringSubmatchIndex(input)
 	if loc == nil {
 		return dir, input
@@ -48,3 +115,47 @@ func extractDirective(input string) (Directive, string) {
 	rest = strings.TrimLeft(rest, "\n")
 	return dir, rest
 }
+
+// applyDirective copies diagram settings from a directive onto the graph.
+func applyDirective(graph *ir.Graph, dir Directive) {
+	if graph.Kind == ir.GitGraph && dir.GitGraph.ParallelCommits {
+		graph.GitParallelCommits = true
+	}
+	if graph.Kind == ir.Sequence && (dir.Wrap || dir.Sequence.Wrap) {
+		graph.SeqWrap = true
+	}
+	if graph.Kind == ir.Gantt && dir.Gantt.TopAxis {
+		graph.GanttTopAxis = true
+	}
+	if graph.Kind == ir.XYChart {
+		graph.XYDataLabels = graph.XYDataLabels || dir.XYChart.ShowDataLabel
+		if dir.XYChart.NumberFormat != "" {
+			graph.XYNumberFmt = dir.XYChart.NumberFormat
+		}
+	}
+	if graph.Kind == ir.C4 && dir.C4.Layout != "" {
+		graph.C4Layout = strings.ToLower(strings.TrimSpace(dir.C4.Layout))
+	}
+	if graph.Kind == ir.Sankey {
+		graph.SankeyNodeAlignment = strings.ToLower(strings.TrimSpace(dir.Sankey.NodeAlignment))
+		graph.SankeyLinkColor = strings.TrimSpace(dir.Sankey.LinkColor)
+		graph.SankeyShowValues = dir.Sankey.ShowValues
+	}
+	if graph.Kind == ir.Treemap {
+		graph.TreemapValueFormat = strings.TrimSpace(dir.Treemap.ValueFormat)
+		graph.TreemapMaxDepth = max(0, dir.Treemap.MaxDepth)
+	}
+	if graph.Kind == ir.Packet {
+		graph.PacketBitsPerRow = max(0, dir.Packet.BitsPerRow)
+		graph.PacketShowBits = dir.Packet.ShowBits
+		graph.PacketBitRuler = dir.Packet.BitRuler
+	}
+	if graph.Kind == ir.Mindmap {
+		switch strings.ToLower(strings.TrimSpace(dir.Layout)) {
+		case "tidy-tree", "tree":
+			graph.MindmapLayout = "tree"
+		case "radial", "cose-bilkent":
+			graph.MindmapLayout = "radial"
+		}
+	}
+}
@@ -53,3 +53,106 @@ func TestExtractDirective_PreservesRest(t *testing.T) {
 		t.Errorf("rest should start with flowchart, got %q", rest[:20])
 	}
 }
+
+func TestExtractDirective_Wrap(t *testing.T) {
+	input := "%%{wrap}%%\n%%{init: {\"theme\": \"dark\"}}%%\nsequenceDiagram\n  A->>B: Hi"
+	dir, rest := extractDirective(input)
+	if !dir.Wrap {
+		t.Error("Wrap = false, want true")
+	}
+	if dir.Theme != "dark" {
+		t.Errorf("Theme = %q, want dark", dir.Theme)
+	}
+	if !strings.HasPrefix(rest, "sequenceDiagram") {
+		t.Errorf("rest = %q, want directives stripped", rest)
+	}
+}
+
+func TestGanttTopAxisDirective(t *testing.T) {
+	out, err := Parse("%%{init: {\"gantt\": {\"topAxis\": true}}}%%\ngantt\n  Design :2024-01-01, 3d")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !out.Graph.GanttTopAxis {
+		t.Error("GanttTopAxis = false, want true from directive")
+	}
+}
+
+func TestXYChartDirective(t *testing.T) {
+	out, err := Parse("%%{init: {\"xyChart\": {\"showDataLabel\": true, \"numberFormat\": \".1%\"}}}%%\nxychart-beta\n  bar [0.2, 0.5]")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !out.Graph.XYDataLabels {
+		t.Error("XYDataLabels = false, want true from directive")
+	}
+	if out.Graph.XYNumberFmt != ".1%" {
+		t.Errorf("XYNumberFmt = %q, want %q", out.Graph.XYNumberFmt, ".1%")
+	}
+}
+
+func TestC4Directive(t *testing.T) {
+	out, err := Parse("%%{init: {\"c4\": {\"layout\": \"Graph\"}}}%%\nC4Container\nSystem(a, \"A\")")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if out.Graph.C4Layout != "graph" {
+		t.Errorf("C4Layout = %q, want %q", out.Graph.C4Layout, "graph")
+	}
+}
+
+func TestMindmapLayoutDirective(t *testing.T) {
+	out, err := Parse("%%{init: {\"layout\": \"tidy-tree\"}}%%\nmindmap\n  root\n    A")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if out.Graph.MindmapLayout != "tree" {
+		t.Errorf("MindmapLayout = %q, want %q", out.Graph.MindmapLayout, "tree")
+	}
+}
+
+func TestSankeyDirective(t *testing.T) {
+	input := "%%{init: {\"sankey\": {\"nodeAlignment\": \"left\", \"linkColor\": \"gradient\", \"showValues\": false}}}%%\nsankey-beta\n\nA,B,10"
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if out.Graph.SankeyNodeAlignment != "left" {
+		t.Errorf("SankeyNodeAlignment = %q, want %q", out.Graph.SankeyNodeAlignment, "left")
+	}
+	if out.Graph.SankeyLinkColor != "gradient" {
+		t.Errorf("SankeyLinkColor = %q, want %q", out.Graph.SankeyLinkColor, "gradient")
+	}
+	if out.Graph.SankeyShowValues == nil || *out.Graph.SankeyShowValues {
+		t.Errorf("SankeyShowValues = %v, want false", out.Graph.SankeyShowValues)
+	}
+}
+
+func TestTreemapDirective(t *testing.T) {
+	out, err := Parse("%%{init: {\"treemap\": {\"valueFormat\": \"$,.2f\", \"maxDepth\": 2}}}%%\ntreemap-beta\n\"A\"\n    \"B\": 1")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if out.Graph.TreemapValueFormat != "$,.2f" {
+		t.Errorf("TreemapValueFormat = %q, want %q", out.Graph.TreemapValueFormat, "$,.2f")
+	}
+	if out.Graph.TreemapMaxDepth != 2 {
+		t.Errorf("TreemapMaxDepth = %d, want 2", out.Graph.TreemapMaxDepth)
+	}
+}
+
+func TestPacketDirective(t *testing.T) {
+	out, err := Parse("%%{init: {\"packet\": {\"bitsPerRow\": 16, \"showBits\": false, \"bitRuler\": true}}}%%\npacket-beta\n0-15: \"A\"")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if out.Graph.PacketBitsPerRow != 16 {
+		t.Errorf("PacketBitsPerRow = %d, want 16", out.Graph.PacketBitsPerRow)
+	}
+	if out.Graph.PacketShowBits == nil || *out.Graph.PacketShowBits {
+		t.Errorf("PacketShowBits = %v, want false", out.Graph.PacketShowBits)
+	}
+	if out.Graph.PacketBitRuler == nil || !*out.Graph.PacketBitRuler {
+		t.Errorf("PacketBitRuler = %v, want true", out.Graph.PacketBitRuler)
+	}
+}
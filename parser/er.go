@@ -182,7 +182,7 @@ func parseER(input string) (*ParseOutput, error) { //nolint:unparam // error ret
 			lineStyle := match[3]
 			rightCard := match[4]
 			rightEntity := match[5]
-			label := strings.TrimSpace(match[6])
+			label := stripQuotes(match[6])
 
 			// Ensure nodes exist.
 			graph.EnsureNode(leftEntity, nil, nil)
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -64,8 +66,13 @@ func parseFlowchart(input string) (*ParseOutput, error) {
 				continue
 			}
 
-			// Skip classDef, class, style, linkStyle, click, accTitle, accDescr, title
+			// Skip classDef, class, style, linkStyle, click, accTitle, accDescr,
+			// title. Click statements with a URL are kept as node links.
 			lowerLine := strings.ToLower(line)
+			if strings.HasPrefix(lowerLine, "click ") {
+				addClickLink(graph, line)
+				continue
+			}
 			if strings.HasPrefix(lowerLine, "classdef") ||
 				strings.HasPrefix(lowerLine, "class ") ||
 				strings.HasPrefix(lowerLine, "style ") ||
@@ -77,27 +84,41 @@ func parseFlowchart(input string) (*ParseOutput, error) {
 				continue
 			}
 
+			// Try node data (A@{ icon: "fa:user", label: "User" }).
+			if caps := nodeDataRe.FindStringSubmatch(line); caps != nil && !strings.Contains(caps[1], "--") {
+				addNodeData(graph, caps[1], caps[2])
+				addNodeToSubgraphs(graph, subgraphStack, caps[1])
+				continue
+			}
+
+			// Node data inside an edge statement (A@{ icon: "..." } --> B) is
+			// applied once the edge has introduced its nodes.
+			edgeLine, nodeData := splitNodeData(line)
+
 			// Try edge chain (A-->B-->C).
-			if chainLines := splitEdgeChain(line); chainLines != nil {
+			if chainLines := splitEdgeChain(edgeLine); chainLines != nil {
 				added := false
-				for _, edgeLine := range chainLines {
-					if addFlowchartEdge(edgeLine, graph, subgraphStack) {
+				for _, chainLine := range chainLines {
+					if addFlowchartEdge(chainLine, graph, subgraphStack) {
 						added = true
 					}
 				}
 				if added {
+					applyNodeData(graph, nodeData)
 					continue
 				}
 			}
 
 			// Try single edge.
-			if addFlowchartEdge(line, graph, subgraphStack) {
+			if addFlowchartEdge(edgeLine, graph, subgraphStack) {
+				applyNodeData(graph, nodeData)
 				continue
 			}
 
 			// Fallback: standalone node.
-			if nodeID, nodeLabel, nodeShape, _, ok := parseNodeOnly(line); ok {
+			if nodeID, nodeLabel, nodeShape, nodeClasses, ok := parseNodeOnly(line); ok {
 				graph.EnsureNode(nodeID, nodeLabel, nodeShape)
+				addNodeClasses(graph, nodeID, nodeClasses)
 				addNodeToSubgraphs(graph, subgraphStack, nodeID)
 			}
 		}
@@ -126,16 +147,18 @@ func addFlowchartEdge(line string, graph *ir.Graph, subgraphStack []int) bool {
 
 	var sourceIDs []string
 	for _, source := range sources {
-		id, lbl, shape, _ := parseNodeToken(source)
+		id, lbl, shape, classes := parseNodeToken(source)
 		graph.EnsureNode(id, lbl, shape)
+		addNodeClasses(graph, id, classes)
 		addNodeToSubgraphs(graph, subgraphStack, id)
 		sourceIDs = append(sourceIDs, id)
 	}
 
 	var targetIDs []string
 	for _, target := range targets {
-		id, lbl, shape, _ := parseNodeToken(target)
+		id, lbl, shape, classes := parseNodeToken(target)
 		graph.EnsureNode(id, lbl, shape)
+		addNodeClasses(graph, id, classes)
 		addNodeToSubgraphs(graph, subgraphStack, id)
 		targetIDs = append(targetIDs, id)
 	}
@@ -162,6 +185,136 @@ func addFlowchartEdge(line string, graph *ir.Graph, subgraphStack []int) bool {
 	return true
 }
 
+// addNodeData applies the key: value pairs of a node@{ ... } statement.
+// An icon key makes the node an icon node; label sets its label.
+func addNodeData(graph *ir.Graph, nodeID, raw string) {
+	data := parseNodeData(raw)
+	var label *string
+	if text, ok := data["label"]; ok {
+		label = &text
+	}
+	graph.EnsureNode(nodeID, label, nil)
+
+	name := data["icon"]
+	if name == "" {
+		return
+	}
+	icon := &ir.NodeIcon{
+		Name:     name,
+		Form:     strings.ToLower(data["form"]),
+		LabelTop: strings.EqualFold(data["pos"], "t"),
+	}
+	if size, err := strconv.ParseFloat(data["h"], 32); err == nil && size > 0 {
+		icon.Size = float32(size)
+	}
+	graph.Nodes[nodeID].Icon = icon
+}
+
+// nodeDataRef is one id@{ ... } node data block found in an edge statement.
+type nodeDataRef struct {
+	id  string
+	raw string
+}
+
+// splitNodeData removes the @{ ... } node data blocks that follow node IDs
+// in an edge statement, leaving the bare IDs, and returns them in order.
+// Blocks inside double-quoted text are left alone.
+func splitNodeData(line string) (string, []nodeDataRef) {
+	var refs []nodeDataRef
+	var out strings.Builder
+	quoted := false
+	for idx := 0; idx < len(line); idx++ {
+		char := line[idx]
+		if char == '"' {
+			quoted = !quoted
+		}
+		if quoted || char != '@' || idx+1 >= len(line) || line[idx+1] != '{' {
+			out.WriteByte(char)
+			continue
+		}
+		id := nodeDataID(out.String())
+		end := nodeDataEnd(line, idx+2)
+		if id == "" || end < 0 {
+			out.WriteByte(char)
+			continue
+		}
+		refs = append(refs, nodeDataRef{id: id, raw: line[idx+2 : end]})
+		idx = end
+	}
+	return out.String(), refs
+}
+
+// nodeDataID returns the node ID at the end of text when it directly
+// precedes an @{ block, or "". An arrow written against the ID, as in
+// A---B@{ ... }, is not part of it.
+func nodeDataID(text string) string {
+	match := nodeDataIDRe.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	id := match[1]
+	if idx := strings.LastIndex(id, "--"); idx >= 0 {
+		id = id[idx+2:]
+	}
+	return strings.TrimLeft(id, "-")
+}
+
+// nodeDataEnd returns the index of the brace closing a node data block
+// that starts at from, skipping quoted values, or -1.
+func nodeDataEnd(line string, from int) int {
+	var quote byte
+	for idx := from; idx < len(line); idx++ {
+		switch char := line[idx]; {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '}':
+			return idx
+		}
+	}
+	return -1
+}
+
+// applyNodeData applies node data blocks split from an edge statement.
+func applyNodeData(graph *ir.Graph, refs []nodeDataRef) {
+	for _, ref := range refs {
+		addNodeData(graph, ref.id, ref.raw)
+	}
+}
+
+// parseNodeData parses comma-separated key: value pairs. Values may be
+// single- or double-quoted, and quoted values may contain commas.
+func parseNodeData(raw string) map[string]string {
+	data := make(map[string]string)
+	rest := raw
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		key, after, ok := strings.Cut(rest, ":")
+		if !ok {
+			return data
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		after = strings.TrimLeft(after, " \t")
+
+		var value string
+		if after != "" && (after[0] == '"' || after[0] == '\'') {
+			end := strings.IndexByte(after[1:], after[0])
+			if end < 0 {
+				value, rest = after[1:], ""
+			} else {
+				value, rest = after[1:end+1], after[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(after, ",")
+			value = strings.TrimSpace(value)
+		}
+		data[key] = value
+	}
+}
+
 // splitAndTrim splits on & and trims whitespace, filtering empty parts.
 func splitAndTrim(s string) []string {
 	parts := strings.Split(s, "&")
@@ -175,6 +328,16 @@ func splitAndTrim(s string) []string {
 	return result
 }
 
+// addNodeClasses records inline :::class suffixes for a node, avoiding
+// duplicates.
+func addNodeClasses(graph *ir.Graph, nodeID string, classes []string) {
+	for _, cls := range classes {
+		if !slices.Contains(graph.NodeClasses[nodeID], cls) {
+			graph.NodeClasses[nodeID] = append(graph.NodeClasses[nodeID], cls)
+		}
+	}
+}
+
 // addNodeToSubgraphs adds a node to all subgraphs in the current stack.
 func addNodeToSubgraphs(graph *ir.Graph, subgraphStack []int, nodeID string) {
 	for _, idx := range subgraphStack {
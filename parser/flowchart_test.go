@@ -131,3 +131,104 @@ func TestParseFlowchartShapes(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlowchartInlineClasses(t *testing.T) {
+	out, err := Parse("flowchart LR\nA:::hot --> B\nC[label]:::cold:::hot\nA:::hot --> C")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	classes := out.Graph.NodeClasses
+	if got := classes["A"]; len(got) != 1 || got[0] != "hot" {
+		t.Errorf("A classes = %v, want [hot]", got)
+	}
+	if got := classes["C"]; len(got) != 2 || got[0] != "cold" || got[1] != "hot" {
+		t.Errorf("C classes = %v, want [cold hot]", got)
+	}
+	if _, ok := classes["B"]; ok {
+		t.Error("B should have no classes")
+	}
+}
+
+func TestParseFlowchartClickLinks(t *testing.T) {
+	src := "flowchart LR\nA --> B --> C\n" +
+		"click A \"https://example.com/a\" \"Open A\" _blank\n" +
+		"click B href \"https://example.com/b\"\n" +
+		"click C call notify()"
+	out, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	links := out.Graph.NodeLinks
+	linkA := links["A"]
+	if linkA == nil || linkA.URL != "https://example.com/a" {
+		t.Fatalf("A link = %+v, want https://example.com/a", linkA)
+	}
+	if linkA.Title == nil || *linkA.Title != "Open A" || linkA.Target == nil || *linkA.Target != "_blank" {
+		t.Errorf("A link title/target = %v/%v, want Open A/_blank", linkA.Title, linkA.Target)
+	}
+	if linkB := links["B"]; linkB == nil || linkB.URL != "https://example.com/b" || linkB.Title != nil {
+		t.Errorf("B link = %+v, want href URL without title", linkB)
+	}
+	if _, ok := links["C"]; ok {
+		t.Error("callback click should not create a link")
+	}
+}
+
+func TestParseFlowchartIconNodes(t *testing.T) {
+	src := "flowchart LR\n" +
+		"A@{ icon: \"fa:user\", form: \"circle\", label: \"User, admin\", pos: \"t\", h: 60 }\n" +
+		"B@{ icon: 'logos:aws-lambda' }\n" +
+		"C@{ label: \"Plain\" }\n" +
+		"A --> B --> C"
+	out, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	nodes := out.Graph.Nodes
+	nodeA := nodes["A"]
+	if nodeA.Label != "User, admin" || nodeA.Icon == nil {
+		t.Fatalf("A = %+v, want an icon node labelled %q", nodeA, "User, admin")
+	}
+	if want := (ir.NodeIcon{Name: "fa:user", Form: "circle", LabelTop: true, Size: 60}); *nodeA.Icon != want {
+		t.Errorf("A icon = %+v, want %+v", *nodeA.Icon, want)
+	}
+	if nodeB := nodes["B"]; nodeB.Icon == nil || nodeB.Icon.Name != "logos:aws-lambda" || nodeB.Label != "B" {
+		t.Errorf("B = %+v, want icon logos:aws-lambda with its ID as label", nodeB)
+	}
+	if nodeC := nodes["C"]; nodeC.Icon != nil || nodeC.Label != "Plain" {
+		t.Errorf("C = %+v, want a plain node labelled Plain", nodeC)
+	}
+	if len(out.Graph.Edges) != 2 {
+		t.Errorf("edges = %d, want 2", len(out.Graph.Edges))
+	}
+}
+
+func TestParseFlowchartNodeDataInEdges(t *testing.T) {
+	src := "flowchart LR\n" +
+		"A@{ icon: \"logos:aws-lambda\", label: \"Fn, main\" } --> B\n" +
+		"B --> C@{ icon: \"fa:user\" } & D\n" +
+		"D---E@{ label: \"a --> b\" }"
+	out, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	nodes := out.Graph.Nodes
+	if len(nodes) != 5 {
+		t.Fatalf("nodes = %d, want 5: %v", len(nodes), out.Graph.NodeOrder)
+	}
+	if nodeA := nodes["A"]; nodeA.Icon == nil || nodeA.Icon.Name != "logos:aws-lambda" || nodeA.Label != "Fn, main" {
+		t.Errorf("A = %+v, want icon logos:aws-lambda labelled %q", nodeA, "Fn, main")
+	}
+	if nodeC := nodes["C"]; nodeC.Icon == nil || nodeC.Icon.Name != "fa:user" {
+		t.Errorf("C = %+v, want icon fa:user", nodeC)
+	}
+	if nodeE := nodes["E"]; nodeE.Label != "a --> b" {
+		t.Errorf("E label = %q, want %q", nodeE.Label, "a --> b")
+	}
+	if len(out.Graph.Edges) != 4 {
+		t.Errorf("edges = %d, want 4", len(out.Graph.Edges))
+	}
+	if order := out.Graph.NodeOrder; order["A"] != 0 || order["B"] != 1 || order["C"] != 2 {
+		t.Errorf("node order = %v, want A, B, C first", order)
+	}
+}
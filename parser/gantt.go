@@ -3,6 +3,7 @@ package parser
 import (
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/jamesainslie/gomd2svg/ir"
 )
@@ -10,9 +11,26 @@ import (
 var (
 	ganttTaskRe = regexp.MustCompile(`^(.+?)\s*:\s*(.+)$`)
 	//nolint:gochecknoglobals // package-level lookup table is idiomatic for constant sets.
-	ganttTagSet = map[string]bool{"done": true, "active": true, "crit": true, "milestone": true}
-	ganttDateRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
-	ganttDurRe  = regexp.MustCompile(`^\d+[dwmhDWMH]$`)
+	ganttTagSet = map[string]bool{"done": true, "active": true, "crit": true, "milestone": true, "vert": true}
+	ganttDurRe  = regexp.MustCompile(`^\d+(?:\.\d+)?(?:ms|[dwmhsDWMHS])$`)
+	// ganttDateTokens maps dayjs dateFormat tokens to the text they match,
+	// longest first so "MMM" is not read as "MM" and "M".
+	//nolint:gochecknoglobals // read-only lookup table.
+	ganttDateTokens = []struct{ token, pattern string }{
+		{"YYYY", `\d{4}`}, {"YY", `\d{2}`},
+		{"MMMM", `[A-Za-z]+`}, {"MMM", `[A-Za-z]{3}`}, {"MM", `\d{2}`}, {"M", `\d{1,2}`},
+		{"DD", `\d{2}`}, {"D", `\d{1,2}`},
+		{"dddd", `[A-Za-z]+`}, {"ddd", `[A-Za-z]{3}`},
+		{"HH", `\d{1,2}`}, {"H", `\d{1,2}`}, {"hh", `\d{1,2}`}, {"h", `\d{1,2}`},
+		{"mm", `\d{2}`}, {"m", `\d{1,2}`}, {"ss", `\d{2}`}, {"s", `\d{1,2}`},
+		{"SSS", `\d{3}`}, {"A", `[AaPp][Mm]`}, {"a", `[AaPp][Mm]`},
+		{"ZZ", `(?:Z|[+-]\d{4})`}, {"Z", `(?:Z|[+-]\d{2}:\d{2})`},
+		{"X", `-?\d+(?:\.\d+)?`}, {"x", `-?\d+`},
+	}
+	// ganttHrefRe and ganttCallRe match the two actions of a click line,
+	// which may appear in either order.
+	ganttHrefRe = regexp.MustCompile(`(?i)\bhref\s+"([^"]*)"`)
+	ganttCallRe = regexp.MustCompile(`(?i)\bcall\s+([\w$.]+)\s*(?:\(([^)]*)\))?`)
 )
 
 //nolint:unparam // error return is part of the parser interface contract used by Parse().
@@ -24,6 +42,7 @@ func parseGantt(input string) (*ParseOutput, error) {
 	lines := preprocessInput(input)
 
 	var currentSection *ir.GanttSection
+	dateRe := ganttDateRegexp(graph.GanttDateFormat)
 
 	for _, line := range lines {
 		lower := strings.ToLower(line)
@@ -39,6 +58,7 @@ func parseGantt(input string) (*ParseOutput, error) {
 		}
 		if strings.HasPrefix(lower, "dateformat ") {
 			graph.GanttDateFormat = strings.TrimSpace(line[len("dateformat "):])
+			dateRe = ganttDateRegexp(graph.GanttDateFormat)
 			continue
 		}
 		if strings.HasPrefix(lower, "axisformat ") {
@@ -64,6 +84,19 @@ func parseGantt(input string) (*ParseOutput, error) {
 			graph.GanttWeekday = strings.TrimSpace(line[len("weekend "):])
 			continue
 		}
+		if lower == "inclusiveenddates" {
+			graph.GanttInclusiveEndDates = true
+			continue
+		}
+		if lower == "topaxis" {
+			graph.GanttTopAxis = true
+			continue
+		}
+		// Click lines come before task lines: URLs contain colons.
+		if strings.HasPrefix(lower, "click ") {
+			parseGanttClick(graph, line)
+			continue
+		}
 
 		// Section.
 		if strings.HasPrefix(lower, "section ") {
@@ -83,7 +116,7 @@ func parseGantt(input string) (*ParseOutput, error) {
 
 			label := strings.TrimSpace(match[1])
 			metadata := strings.TrimSpace(match[2])
-			task := parseGanttTask(label, metadata)
+			task := parseGanttTask(label, metadata, dateRe)
 			currentSection.Tasks = append(currentSection.Tasks, task)
 		}
 	}
@@ -91,7 +124,75 @@ func parseGantt(input string) (*ParseOutput, error) {
 	return &ParseOutput{Graph: graph}, nil
 }
 
-func parseGanttTask(label, metadata string) *ir.GanttTask {
+// parseGanttClick records the actions of a "click id[,id...] [call
+// fn(args)] [href "url"]" line for each listed task.
+func parseGanttClick(graph *ir.Graph, line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 { //nolint:mnd // keyword, task IDs and an action.
+		return
+	}
+	rest := strings.TrimSpace(line[strings.Index(line, fields[1])+len(fields[1]):])
+
+	var link *ir.NodeLink
+	if match := ganttHrefRe.FindStringSubmatch(rest); match != nil {
+		link = &ir.NodeLink{URL: match[1]}
+	}
+	var callback *ir.GanttCallback
+	if match := ganttCallRe.FindStringSubmatch(rest); match != nil {
+		callback = &ir.GanttCallback{Name: match[1]}
+		for _, arg := range strings.Split(match[2], ",") {
+			if arg = strings.Trim(strings.TrimSpace(arg), `"`); arg != "" {
+				callback.Args = append(callback.Args, arg)
+			}
+		}
+	}
+
+	for _, id := range strings.Split(fields[1], ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if link != nil {
+			graph.NodeLinks[id] = link
+		}
+		if callback != nil {
+			if graph.GanttCallbacks == nil {
+				graph.GanttCallbacks = make(map[string]*ir.GanttCallback)
+			}
+			graph.GanttCallbacks[id] = callback
+		}
+	}
+}
+
+// ganttDateRegexp returns a pattern matching dates written in a dayjs
+// dateFormat such as "YYYY-MM-DD", "HH:mm" or "X". Text between tokens
+// must match literally.
+func ganttDateRegexp(format string) *regexp.Regexp {
+	var out strings.Builder
+	out.WriteString("^")
+	for idx := 0; idx < len(format); {
+		matched := false
+		for _, tok := range ganttDateTokens {
+			if strings.HasPrefix(format[idx:], tok.token) {
+				out.WriteString(tok.pattern)
+				idx += len(tok.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			_, size := utf8.DecodeRuneInString(format[idx:])
+			out.WriteString(regexp.QuoteMeta(format[idx : idx+size]))
+			idx += size
+		}
+	}
+	out.WriteString("$")
+	return regexp.MustCompile(out.String())
+}
+
+// parseGanttTask parses the metadata of a task line. Dates are recognised
+// by dateRe, which follows the chart's dateFormat.
+func parseGanttTask(label, metadata string, dateRe *regexp.Regexp) *ir.GanttTask {
 	task := &ir.GanttTask{Label: label}
 
 	parts := strings.Split(metadata, ",")
@@ -120,14 +221,14 @@ func parseGanttTask(label, metadata string) *ir.GanttTask {
 			task.StartStr = part
 		case strings.HasPrefix(lp, "until "):
 			task.UntilID = strings.TrimSpace(part[len("until "):])
-		case ganttDateRe.MatchString(part):
+		case ganttDurRe.MatchString(part):
+			task.EndStr = part
+		case dateRe.MatchString(part):
 			if task.StartStr == "" {
 				task.StartStr = part
 			} else {
 				task.EndStr = part
 			}
-		case ganttDurRe.MatchString(part):
-			task.EndStr = part
 		default:
 			// Must be a task ID -- only if it's the first non-tag item
 			// and we haven't set start yet.
@@ -150,3 +150,104 @@ func TestParseGanttNoSection(t *testing.T) {
 		t.Errorf("Tasks = %d, want 2", len(out.Graph.GanttSections[0].Tasks))
 	}
 }
+
+func TestParseGanttClicks(t *testing.T) {
+	input := `gantt
+    section Build
+        Design :d1, 2024-01-01, 3d
+        Coding :d2, after d1, 5d
+    click d1 href "https://example.com/design"
+    click d2 call showTask("d2", 5) href "https://example.com/code"
+    click d1,d2 call track()`
+
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if tasks := graph.GanttSections[0].Tasks; len(tasks) != 2 {
+		t.Fatalf("Tasks = %d, want 2 (click lines are not tasks)", len(tasks))
+	}
+	if link := graph.NodeLinks["d1"]; link == nil || link.URL != "https://example.com/design" {
+		t.Errorf("d1 link = %+v", link)
+	}
+	if link := graph.NodeLinks["d2"]; link == nil || link.URL != "https://example.com/code" {
+		t.Errorf("d2 link = %+v", link)
+	}
+	callback := graph.GanttCallbacks["d1"]
+	if callback == nil || callback.Name != "track" || len(callback.Args) != 0 {
+		t.Errorf("d1 callback = %+v, want track()", callback)
+	}
+	// The later click line replaces the earlier callback for d2.
+	if callback := graph.GanttCallbacks["d2"]; callback == nil || callback.Name != "track" {
+		t.Errorf("d2 callback = %+v, want track()", callback)
+	}
+}
+
+func TestParseGanttCallArgs(t *testing.T) {
+	out, err := Parse("gantt\n  Design :d1, 2024-01-01, 3d\n  click d1 call showTask(\"d1\", 5)")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	callback := out.Graph.GanttCallbacks["d1"]
+	if callback == nil || callback.Name != "showTask" {
+		t.Fatalf("callback = %+v", callback)
+	}
+	if len(callback.Args) != 2 || callback.Args[0] != "d1" || callback.Args[1] != "5" {
+		t.Errorf("Args = %q, want [d1 5]", callback.Args)
+	}
+}
+
+func TestParseGanttVertAndOptions(t *testing.T) {
+	input := `gantt
+    inclusiveEndDates
+    topAxis
+    Design :d1, 2024-01-01, 2024-01-03
+    Freeze : vert, f1, 2024-01-02, 0d`
+
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if !graph.GanttInclusiveEndDates || !graph.GanttTopAxis {
+		t.Errorf("InclusiveEndDates = %v, TopAxis = %v, want both set",
+			graph.GanttInclusiveEndDates, graph.GanttTopAxis)
+	}
+	freeze := graph.GanttSections[0].Tasks[1]
+	if len(freeze.Tags) != 1 || freeze.Tags[0] != "vert" || freeze.ID != "f1" || freeze.StartStr != "2024-01-02" {
+		t.Errorf("vert task = %+v", freeze)
+	}
+}
+
+func TestParseGanttDateFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		metadata  string
+		wantID    string
+		wantStart string
+		wantEnd   string
+	}{
+		{"clock", "HH:mm", "a1, 10:00, 30m", "a1", "10:00", "30m"},
+		{"clock end", "HH:mm", "10:00, 10:45", "", "10:00", "10:45"},
+		{"unix seconds", "X", "a1, 1700000000, 1.5h", "a1", "1700000000", "1.5h"},
+		{"unix millis", "x", "1700000000000, 45s", "", "1700000000000", "45s"},
+		{"date and time", "YYYY-MM-DD HH:mm", "2024-01-01 09:30, 250ms", "", "2024-01-01 09:30", "250ms"},
+		{"month name", "D MMM YYYY", "5 Mar 2024, 2d", "", "5 Mar 2024", "2d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "gantt\n  dateFormat " + tt.format + "\n  Task :" + tt.metadata
+			out, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			task := out.Graph.GanttSections[0].Tasks[0]
+			if task.ID != tt.wantID || task.StartStr != tt.wantStart || task.EndStr != tt.wantEnd {
+				t.Errorf("task = {ID %q, Start %q, End %q}, want {%q, %q, %q}",
+					task.ID, task.StartStr, task.EndStr, tt.wantID, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
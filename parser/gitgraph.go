@@ -24,6 +24,7 @@ func parseGitGraph(input string) (*ParseOutput, error) {
 		lower := strings.ToLower(strings.TrimSpace(line))
 
 		if strings.HasPrefix(lower, "gitgraph") {
+			graph.GitDirection = parseGitDirection(lower[len("gitgraph"):])
 			continue
 		}
 
@@ -62,6 +63,19 @@ func parseGitGraph(input string) (*ParseOutput, error) {
 	return &ParseOutput{Graph: graph}, nil
 }
 
+// parseGitDirection reads the orientation after the gitGraph keyword, as
+// in "gitGraph TB:". Mermaid only supports LR, TB and BT.
+func parseGitDirection(rest string) ir.Direction {
+	switch strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(rest), ":")) {
+	case "TB", "TD":
+		return ir.TopDown
+	case "BT":
+		return ir.BottomTop
+	default:
+		return ir.LeftRight
+	}
+}
+
 func parseGitCommit(line string) *ir.GitCommit {
 	commit := &ir.GitCommit{}
 	opts := gitKeyValRe.FindAllStringSubmatch(line, -1)
@@ -171,3 +171,38 @@ func TestParseGitGraphSwitch(t *testing.T) {
 		t.Errorf("switch.Branch = %q", co.Branch)
 	}
 }
+
+func TestParseGitGraphOrientation(t *testing.T) {
+	tests := []struct {
+		header string
+		want   ir.Direction
+	}{
+		{"gitGraph", ir.LeftRight},
+		{"gitGraph LR:", ir.LeftRight},
+		{"gitGraph TB:", ir.TopDown},
+		{"gitGraph BT:", ir.BottomTop},
+	}
+	for _, tc := range tests {
+		out, err := Parse(tc.header + "\n    commit")
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tc.header, err)
+		}
+		if out.Graph.GitDirection != tc.want {
+			t.Errorf("%q: GitDirection = %v, want %v", tc.header, out.Graph.GitDirection, tc.want)
+		}
+	}
+}
+
+func TestParseGitGraphParallelCommitsDirective(t *testing.T) {
+	input := `%%{init: {'gitGraph': {'parallelCommits': true}}}%%
+gitGraph
+    commit`
+
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !out.Graph.GitParallelCommits {
+		t.Error("GitParallelCommits = false, want true")
+	}
+}
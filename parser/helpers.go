@@ -10,6 +10,7 @@ import (
 var (
 	headerRe    = regexp.MustCompile(`(?i)^(flowchart|graph)\s+(\w+)`)
 	subgraphRe  = regexp.MustCompile(`(?i)^subgraph\s+(.*)$`)
+	nodeDataRe  = regexp.MustCompile(`^([\w-]+)\s*@\{(.*)\}$`)
 	pipeLabelRe = regexp.MustCompile(
 		`^(?P<left>.+?)\s*(?P<arrow><[-.=ox]*[-=]+[-.=ox]*>|<[-.=ox]*[-=]+|[-.=ox]*[-=]+>|[-.=ox]*[-=]+)\|(?P<label>.+?)\|\s*(?P<right>.+)$`,
 	)
@@ -28,6 +29,15 @@ var (
 	arrowTokenRe = regexp.MustCompile(
 		`<[-.=ox]*[-=]+[-.=ox]*>|<[-.=ox]*[-=]+|[-.=ox]*[-=]+>|[-.=ox]*[-=]+`,
 	)
+	// clickLinkRe matches URL click statements: click id "url" ["tooltip"]
+	// [target], the same with href before the URL, and the class diagram
+	// form link id "url" ["tooltip"] [target].
+	clickLinkRe = regexp.MustCompile(
+		`(?i)^(?:click|link)\s+(\S+)\s+(?:href\s+)?"([^"]*)"(?:\s+"([^"]*)")?(?:\s+(_\w+))?\s*;?$`,
+	)
+	// nodeDataIDRe matches the node ID before an @{ block inside an edge
+	// statement.
+	nodeDataIDRe = regexp.MustCompile(`(?:^|[\s&>=.|])([\w-]+)$`)
 )
 
 // edgeMeta holds parsed metadata about an edge arrow.
@@ -300,7 +310,7 @@ func parseEdgeLine(line string) (left string, label *string, right string, meta
 
 		leftNode := strings.TrimSpace(line[leftRange[0]:leftRange[1]])
 		rightNode := strings.TrimSpace(line[rightRange[0]:rightRange[1]])
-		lbl := strings.TrimSpace(line[labelRange[0]:labelRange[1]])
+		lbl := stripQuotes(line[labelRange[0]:labelRange[1]])
 		arrow := strings.TrimSpace(line[arrowRange[0]:arrowRange[1]])
 
 		if lbl != "" && leftNode != "" && rightNode != "" {
@@ -422,7 +432,7 @@ func parseEdgeLine(line string) (left string, label *string, right string, meta
 	rightToken := rightNode
 	if strings.HasPrefix(rightNode, "|") {
 		if endIdx := strings.Index(rightNode[1:], "|"); endIdx >= 0 {
-			labelStr := strings.TrimSpace(rightNode[1 : endIdx+1])
+			labelStr := stripQuotes(rightNode[1 : endIdx+1])
 			rest := strings.TrimSpace(rightNode[endIdx+2:])
 			if rest != "" {
 				lbl = &labelStr
@@ -679,17 +689,38 @@ func parseShapeFromBraces(raw string) (string, ir.NodeShape) {
 }
 
 // stripQuotes removes surrounding double or single quotes from a string.
+// Quoted strings may carry the #quot; entity for an embedded double quote.
 func stripQuotes(input string) string {
 	trimmed := strings.TrimSpace(input)
 	if len(trimmed) >= 2 {
 		if (trimmed[0] == '"' && trimmed[len(trimmed)-1] == '"') ||
 			(trimmed[0] == '\'' && trimmed[len(trimmed)-1] == '\'') {
-			return trimmed[1 : len(trimmed)-1]
+			return strings.ReplaceAll(trimmed[1:len(trimmed)-1], "#quot;", "\"")
 		}
 	}
 	return trimmed
 }
 
+// addClickLink records the URL of a click or link statement in
+// graph.NodeLinks. Callback forms such as "click A call fn()" carry no URL
+// and are ignored.
+func addClickLink(graph *ir.Graph, line string) {
+	match := clickLinkRe.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	link := &ir.NodeLink{URL: match[2]}
+	if match[3] != "" {
+		title := match[3]
+		link.Title = &title
+	}
+	if match[4] != "" {
+		target := match[4]
+		link.Target = &target
+	}
+	graph.NodeLinks[match[1]] = link
+}
+
 // parseSubgraphHeader parses the "subgraph rest..." part after the keyword.
 func parseSubgraphHeader(input string) (id *string, label string, classes []string) { //nolint:nonamedreturns // named returns clarify the multi-value return.
 	base, classes := splitInlineClasses(input)
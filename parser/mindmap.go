@@ -10,7 +10,7 @@ import (
 
 var (
 	mindmapIconRe  = regexp.MustCompile(`::icon\(([^)]+)\)`)
-	mindmapClassRe = regexp.MustCompile(`:::(\S+)`)
+	mindmapClassRe = regexp.MustCompile(`:::\s*([\w-]+(?:\s+[\w-]+)*)`)
 )
 
 func parseMindmap(input string) (*ParseOutput, error) { //nolint:unparam // error return is part of the parser interface contract used by Parse().
@@ -37,6 +37,27 @@ func parseMindmap(input string) (*ParseOutput, error) { //nolint:unparam // erro
 			continue
 		}
 
+		// classDef statements give ::: classes their colours. The keyword
+		// matches in any case.
+		if trimmed := strings.TrimSpace(text); strings.HasPrefix(strings.ToLower(trimmed), "classdef ") &&
+			parseStyleStatement(graph, "classDef "+trimmed[len("classdef "):]) {
+			continue
+		}
+
+		// A line holding only ::icon() or ::: decorations applies them to
+		// the node above it.
+		if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "::") && len(stack) > 0 {
+			decorated := parseMindmapNodeText(trimmed, 0)
+			last := stack[len(stack)-1].node
+			if decorated.Icon != "" {
+				last.Icon = decorated.Icon
+			}
+			if decorated.Class != "" {
+				last.Class = decorated.Class
+			}
+			continue
+		}
+
 		node := parseMindmapNodeText(text, nodeCount)
 		nodeCount++
 
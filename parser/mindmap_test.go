@@ -95,3 +95,61 @@ func TestParseMindmapIconAndClass(t *testing.T) {
 		t.Errorf("class = %q, want %q", graph.MindmapRoot.Children[1].Class, "urgent")
 	}
 }
+
+func TestParseMindmapDecorationLines(t *testing.T) {
+	input := `mindmap
+    root
+        A
+        ::icon(mdi mdi-book)
+        :::urgent
+        B`
+
+	out, err := parseMindmap(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	children := out.Graph.MindmapRoot.Children
+	if len(children) != 2 {
+		t.Fatalf("children = %d, want 2", len(children))
+	}
+	if children[0].Icon != "mdi mdi-book" || children[0].Class != "urgent" {
+		t.Errorf("A icon = %q, class = %q; want the decorations on the line below", children[0].Icon, children[0].Class)
+	}
+}
+
+func TestParseMindmapClassDefs(t *testing.T) {
+	input := `mindmap
+    root
+        A[Alpha]:::urgent large
+    classDef urgent fill:#fdd,stroke:#c00`
+
+	out, err := parseMindmap(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	node := graph.MindmapRoot.Children[0]
+	if node.Class != "urgent large" || node.Label != "Alpha" {
+		t.Errorf("node = %q with class %q, want Alpha with %q", node.Label, node.Class, "urgent large")
+	}
+	if len(graph.MindmapRoot.Children) != 1 {
+		t.Errorf("classDef parsed as a node")
+	}
+	if style := graph.ClassDefs["urgent"]; style == nil || style.Fill == nil || *style.Fill != "#fdd" {
+		t.Errorf("classDef urgent = %+v", style)
+	}
+}
+
+func TestParseMindmapClassDefAnyCase(t *testing.T) {
+	out, err := parseMindmap("mindmap\n    root\n        A\n    ClassDef foo fill:#f00")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if len(graph.MindmapRoot.Children) != 1 {
+		t.Errorf("ClassDef parsed as a node")
+	}
+	if style := graph.ClassDefs["foo"]; style == nil || style.Fill == nil || *style.Fill != "#f00" {
+		t.Errorf("classDef foo = %+v", style)
+	}
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -10,6 +12,7 @@ import (
 
 var (
 	packetRangeRe    = regexp.MustCompile(`^(\d+)-(\d+)\s*:\s*"([^"]*)"$`)
+	packetBitRe      = regexp.MustCompile(`^(\d+)\s*:\s*"([^"]*)"$`)
 	packetBitCountRe = regexp.MustCompile(`^\+(\d+)\s*:\s*"([^"]*)"$`)
 )
 
@@ -26,31 +29,116 @@ func parsePacket(input string) (*ParseOutput, error) { //nolint:unparam // error
 			continue
 		}
 
-		// Try range notation: 0-15: "Source Port".
-		if match := packetRangeRe.FindStringSubmatch(line); match != nil {
-			start, _ := strconv.Atoi(match[1]) //nolint:errcheck // regex guarantees digits.
-			end, _ := strconv.Atoi(match[2])   //nolint:errcheck // regex guarantees digits.
-			desc := match[3]
-			graph.Fields = append(graph.Fields, &ir.PacketField{
-				Start: start, End: end, Description: desc,
+		if field, ok := ParsePacketField(line, nextBit); ok {
+			graph.Fields = append(graph.Fields, field)
+			nextBit = field.End + 1
+		}
+	}
+
+	return &ParseOutput{Graph: graph}, nil
+}
+
+// ParsePacketField parses one packet field statement: a bit range such as
+// `0-15: "Source Port"`, a single bit such as `16: "Flag"`, or a bit count
+// such as `+16: "Source Port"`, which starts at nextBit. It reports false
+// for anything else. Ranges are not checked; a range whose end comes
+// before its start, or a zero count, gives a field with End < Start.
+func ParsePacketField(line string, nextBit int) (*ir.PacketField, bool) {
+	line = strings.TrimSpace(line)
+
+	// Range notation: 0-15: "Source Port".
+	if match := packetRangeRe.FindStringSubmatch(line); match != nil {
+		start, _ := strconv.Atoi(match[1]) //nolint:errcheck // regex guarantees digits.
+		end, _ := strconv.Atoi(match[2])   //nolint:errcheck // regex guarantees digits.
+		return &ir.PacketField{Start: start, End: end, Description: match[3]}, true
+	}
+
+	// Single bit notation: 16: "Flag".
+	if match := packetBitRe.FindStringSubmatch(line); match != nil {
+		bit, _ := strconv.Atoi(match[1]) //nolint:errcheck // regex guarantees digits.
+		return &ir.PacketField{Start: bit, End: bit, Description: match[2]}, true
+	}
+
+	// Bit count notation: +16: "Source Port".
+	if match := packetBitCountRe.FindStringSubmatch(line); match != nil {
+		count, _ := strconv.Atoi(match[1]) //nolint:errcheck // regex guarantees digits.
+		return &ir.PacketField{Start: nextBit, End: nextBit + count - 1, Description: match[2]}, true
+	}
+
+	return nil, false
+}
+
+// PacketProblem classifies a PacketFieldError.
+type PacketProblem int
+
+const (
+	// PacketReversed is a field whose range ends before it starts.
+	PacketReversed PacketProblem = iota
+	// PacketOverlap is a field that shares bits with an earlier field.
+	PacketOverlap
+	// PacketGap is a run of bits before a field that no field covers.
+	PacketGap
+)
+
+// PacketFieldError describes packet fields that cannot be drawn as
+// written: reversed ranges, overlapping fields and uncovered bits.
+type PacketFieldError struct {
+	Problem PacketProblem
+	Message string
+}
+
+func (e *PacketFieldError) Error() string {
+	return e.Message
+}
+
+// CheckPacketFields reports reversed and overlapping fields in source
+// order, then the bits from bit 0 up to the last field that no field
+// covers.
+func CheckPacketFields(fields []*ir.PacketField) []*PacketFieldError {
+	var problems []*PacketFieldError
+	var valid []*ir.PacketField
+	for _, field := range fields {
+		if field.End < field.Start {
+			problems = append(problems, &PacketFieldError{
+				Problem: PacketReversed,
+				Message: fmt.Sprintf("field %q ends at bit %d before it starts at bit %d", field.Description, field.End, field.Start),
 			})
-			nextBit = end + 1
 			continue
 		}
+		for _, earlier := range valid {
+			if field.Start <= earlier.End && earlier.Start <= field.End {
+				problems = append(problems, &PacketFieldError{
+					Problem: PacketOverlap,
+					Message: fmt.Sprintf("field %q (bits %s) overlaps field %q (bits %s)",
+						field.Description, packetBits(field.Start, field.End), earlier.Description, packetBits(earlier.Start, earlier.End)),
+				})
+			}
+		}
+		valid = append(valid, field)
+	}
 
-		// Try bit count notation: +16: "Source Port".
-		if match := packetBitCountRe.FindStringSubmatch(line); match != nil {
-			count, _ := strconv.Atoi(match[1]) //nolint:errcheck // regex guarantees digits.
-			desc := match[2]
-			start := nextBit
-			end := start + count - 1
-			graph.Fields = append(graph.Fields, &ir.PacketField{
-				Start: start, End: end, Description: desc,
+	sorted := append([]*ir.PacketField(nil), valid...)
+	sort.SliceStable(sorted, func(idxA, idxB int) bool {
+		return sorted[idxA].Start < sorted[idxB].Start
+	})
+	covered := 0
+	for _, field := range sorted {
+		if field.Start > covered {
+			problems = append(problems, &PacketFieldError{
+				Problem: PacketGap,
+				Message: fmt.Sprintf("bits %s before field %q are not covered by any field",
+					packetBits(covered, field.Start-1), field.Description),
 			})
-			nextBit = end + 1
-			continue
 		}
+		covered = max(covered, field.End+1)
 	}
+	return problems
+}
 
-	return &ParseOutput{Graph: graph}, nil
+// packetBits formats a bit range as "4-7", or "4" for a single bit.
+func packetBits(start, end int) string {
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
 }
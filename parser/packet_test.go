@@ -97,3 +97,32 @@ func TestParsePacketSingleBit(t *testing.T) {
 		t.Errorf("Fields[1] = %d-%d, want 4-4", out.Graph.Fields[1].Start, out.Graph.Fields[1].End)
 	}
 }
+
+func TestParsePacketField(t *testing.T) {
+	tests := []struct {
+		line       string
+		nextBit    int
+		ok         bool
+		start, end int
+		desc       string
+	}{
+		{`0-15: "Source Port"`, 0, true, 0, 15, "Source Port"},
+		{`106: "URG"`, 106, true, 106, 106, "URG"},
+		{`+8: "Data Offset"`, 96, true, 96, 103, "Data Offset"},
+		{`+0: "Empty"`, 8, true, 8, 7, "Empty"},
+		{`15-8: "Backwards"`, 0, true, 15, 8, "Backwards"},
+		{`0-15 "Missing colon"`, 0, false, 0, 0, ""},
+		{`a-b: "Letters"`, 0, false, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		field, ok := ParsePacketField(tt.line, tt.nextBit)
+		if ok != tt.ok {
+			t.Errorf("ParsePacketField(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && (field.Start != tt.start || field.End != tt.end || field.Description != tt.desc) {
+			t.Errorf("ParsePacketField(%q) = %d-%d %q, want %d-%d %q",
+				tt.line, field.Start, field.End, field.Description, tt.start, tt.end, tt.desc)
+		}
+	}
+}
@@ -72,6 +72,7 @@ func Parse(input string) (*ParseOutput, error) {
 		return nil, err
 	}
 	po.Directive = dir
+	applyDirective(po.Graph, dir)
 	return po, nil
 }
 
@@ -8,7 +8,9 @@ import (
 	"github.com/jamesainslie/gomd2svg/ir"
 )
 
-var quadrantPointRe = regexp.MustCompile(`^\s*(.+?):\s*\[([0-9.]+),\s*([0-9.]+)\]\s*$`)
+// quadrantPointRe matches "Label: [x, y]", with an optional ":::class"
+// after the label and optional styles after the coordinates.
+var quadrantPointRe = regexp.MustCompile(`^\s*(.+?)(?::::([\w-]+))?\s*:\s*\[\s*([0-9.]+)\s*,\s*([0-9.]+)\s*\]\s*(.*)$`)
 
 func parseQuadrant(input string) (*ParseOutput, error) { //nolint:unparam // error return is part of the parser interface contract used by Parse().
 	graph := ir.NewGraph()
@@ -71,10 +73,20 @@ func parseQuadrant(input string) (*ParseOutput, error) { //nolint:unparam // err
 			continue
 		}
 
-		// Data point: "Label: [x, y]".
+		// classDef name radius: 10, color: #109060, ...
+		if strings.HasPrefix(lower, "classdef ") {
+			name, styles, _ := strings.Cut(strings.TrimSpace(line[len("classdef "):]), " ")
+			if name != "" {
+				graph.QuadrantClasses[name] = parseQuadrantStyle(styles)
+			}
+			continue
+		}
+
+		// Data point: "Label: [x, y]", optionally "Label:::class: [x, y]"
+		// and followed by styles such as "radius: 12, color: #f30".
 		if match := quadrantPointRe.FindStringSubmatch(line); match != nil {
-			xCoord, _ := strconv.ParseFloat(match[2], 64) //nolint:errcheck // regex guarantees digits.
-			yCoord, _ := strconv.ParseFloat(match[3], 64) //nolint:errcheck // regex guarantees digits.
+			xCoord, _ := strconv.ParseFloat(match[3], 64) //nolint:errcheck // regex guarantees digits.
+			yCoord, _ := strconv.ParseFloat(match[4], 64) //nolint:errcheck // regex guarantees digits.
 			// Clamp to valid [0, 1] range.
 			if xCoord < 0 {
 				xCoord = 0
@@ -90,6 +102,8 @@ func parseQuadrant(input string) (*ParseOutput, error) { //nolint:unparam // err
 				Label: strings.TrimSpace(match[1]),
 				X:     xCoord,
 				Y:     yCoord,
+				Class: match[2],
+				Style: parseQuadrantStyle(match[5]),
 			})
 			continue
 		}
@@ -97,3 +111,37 @@ func parseQuadrant(input string) (*ParseOutput, error) { //nolint:unparam // err
 
 	return &ParseOutput{Graph: graph}, nil
 }
+
+// parseQuadrantStyle parses comma-separated point styles: radius, color,
+// stroke-color and stroke-width. Unknown or malformed entries are ignored.
+func parseQuadrantStyle(text string) ir.QuadrantPointStyle {
+	var style ir.QuadrantPointStyle
+	for _, entry := range strings.Split(text, ",") {
+		key, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch key {
+		case "radius":
+			if radius, err := strconv.ParseFloat(value, 32); err == nil && radius > 0 {
+				size := float32(radius)
+				style.Radius = &size
+			}
+		case "color":
+			style.Color = &value
+		case "stroke-color":
+			style.StrokeColor = &value
+		case "stroke-width":
+			if width, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32); err == nil && width >= 0 {
+				size := float32(width)
+				style.StrokeWidth = &size
+			}
+		}
+	}
+	return style
+}
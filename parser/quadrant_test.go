@@ -82,3 +82,41 @@ func TestParseQuadrantMinimal(t *testing.T) {
 		t.Fatalf("Points = %d, want 1", len(out.Graph.QuadrantPoints))
 	}
 }
+
+func TestParseQuadrantPointStyles(t *testing.T) {
+	input := `quadrantChart
+    Point A: [0.3, 0.6] radius: 12, color: #ff3300, stroke-color: #10f0f0, stroke-width: 5px
+    Point B:::urgent: [0.45, 0.23]
+    Point C: [0.5, 0.5]
+    classDef urgent color: #109060, radius : 10, stroke-color: #310085, stroke-width: 10px`
+
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	points := out.Graph.QuadrantPoints
+	if len(points) != 3 {
+		t.Fatalf("points = %d, want 3", len(points))
+	}
+
+	styled := points[0].Style
+	if points[0].Label != "Point A" || points[0].X != 0.3 || points[0].Y != 0.6 {
+		t.Errorf("point A = %+v", points[0])
+	}
+	if styled.Radius == nil || *styled.Radius != 12 || styled.Color == nil || *styled.Color != "#ff3300" ||
+		styled.StrokeColor == nil || *styled.StrokeColor != "#10f0f0" || styled.StrokeWidth == nil || *styled.StrokeWidth != 5 {
+		t.Errorf("point A style = %+v", styled)
+	}
+
+	if points[1].Label != "Point B" || points[1].Class != "urgent" {
+		t.Errorf("point B = %q class %q, want Point B class urgent", points[1].Label, points[1].Class)
+	}
+	urgent, ok := out.Graph.QuadrantClasses["urgent"]
+	if !ok || urgent.Radius == nil || *urgent.Radius != 10 || urgent.Color == nil || *urgent.Color != "#109060" {
+		t.Errorf("classDef urgent = %+v", urgent)
+	}
+
+	if plain := points[2].Style; plain.Radius != nil || plain.Color != nil {
+		t.Errorf("point C style = %+v, want unset", plain)
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"encoding/json"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -257,7 +258,8 @@ func parseSequence(input string) (*ParseOutput, error) {
 		if match := noteRe.FindStringSubmatch(line); match != nil {
 			posToken := strings.ToLower(strings.TrimSpace(match[1]))
 			participantsPart := strings.TrimSpace(match[2])
-			text := replaceBR(strings.TrimSpace(match[3]))
+			text, wrap := splitSeqWrap(strings.TrimSpace(match[3]))
+			text = replaceBR(text)
 
 			var pos ir.SeqNotePosition
 			var participants []string
@@ -289,6 +291,7 @@ func parseSequence(input string) (*ParseOutput, error) {
 					Position:     pos,
 					Participants: participants,
 					Text:         text,
+					Wrap:         wrap,
 				},
 			})
 			continue
@@ -352,8 +355,14 @@ func parseSequence(input string) (*ParseOutput, error) {
 					Message: "invalid JSON in links: " + err.Error(),
 				}
 			}
-			for lbl, url := range parsed {
-				participant.Links = append(participant.Links, ir.SeqLink{Label: lbl, URL: url})
+			// JSON objects are unordered, so links are added by label.
+			labels := make([]string, 0, len(parsed))
+			for lbl := range parsed {
+				labels = append(labels, lbl)
+			}
+			sort.Strings(labels)
+			for _, lbl := range labels {
+				participant.Links = append(participant.Links, ir.SeqLink{Label: lbl, URL: parsed[lbl]})
 			}
 			continue
 		}
@@ -386,6 +395,7 @@ func parseSequence(input string) (*ParseOutput, error) {
 			ensureParticipant(from)
 			ensureParticipant(to)
 
+			text, wrap := splitSeqWrap(text)
 			msg := &ir.SeqMessage{
 				From:             from,
 				To:               to,
@@ -393,6 +403,7 @@ func parseSequence(input string) (*ParseOutput, error) {
 				Kind:             kind,
 				ActivateTarget:   activateTarget,
 				DeactivateSource: deactivateSource,
+				Wrap:             wrap,
 			}
 
 			graph.Events = append(graph.Events, &ir.SeqEvent{
@@ -658,6 +669,20 @@ func seqKindFromString(typeStr string) ir.SeqParticipantKind {
 	}
 }
 
+// splitSeqWrap removes a leading wrap: or nowrap: from message or note
+// text and returns the wrap setting it selects.
+func splitSeqWrap(text string) (string, ir.SeqWrap) {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.HasPrefix(lower, "wrap:"):
+		return strings.TrimSpace(text[len("wrap:"):]), ir.SeqWrapOn
+	case strings.HasPrefix(lower, "nowrap:"):
+		return strings.TrimSpace(text[len("nowrap:"):]), ir.SeqWrapOff
+	default:
+		return text, ir.SeqWrapDefault
+	}
+}
+
 // replaceBR replaces <br/> and <br> tags with newlines.
 func replaceBR(text string) string {
 	text = strings.ReplaceAll(text, "<br/>", "\n")
@@ -450,6 +450,27 @@ func TestSequenceLinks(t *testing.T) {
 	}
 }
 
+func TestSequenceLinksJSONOrder(t *testing.T) {
+	input := `sequenceDiagram
+    participant API
+    links API: {"Repo": "https://git.example.com", "Dashboard": "https://dash.example.com", "Alerts": "https://alerts.example.com"}
+`
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	links := out.Graph.Participants[0].Links
+	want := []string{"Alerts", "Dashboard", "Repo"}
+	if len(links) != len(want) {
+		t.Fatalf("expected %d links, got %d", len(want), len(links))
+	}
+	for idx, label := range want {
+		if links[idx].Label != label {
+			t.Errorf("link %d = %q, want %q", idx, links[idx].Label, label)
+		}
+	}
+}
+
 func TestSequenceLineBreaks(t *testing.T) {
 	input := `sequenceDiagram
     participant A
@@ -488,3 +509,56 @@ func TestSequenceLineBreaks(t *testing.T) {
 		t.Errorf("expected 'Line1\\nLine2', got %q", noteEvents[0].Note.Text)
 	}
 }
+
+func TestSequenceWrapPrefix(t *testing.T) {
+	input := `sequenceDiagram
+    A->>B: wrap: A long message that should wrap
+    B-->>A: nowrap:Short reply
+    A->>B: Plain
+    Note over A,B: Wrap: A long note
+`
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events := out.Graph.Events
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	tests := []struct {
+		text string
+		wrap ir.SeqWrap
+	}{
+		{"A long message that should wrap", ir.SeqWrapOn},
+		{"Short reply", ir.SeqWrapOff},
+		{"Plain", ir.SeqWrapDefault},
+	}
+	for idx, tt := range tests {
+		msg := events[idx].Message
+		if msg.Text != tt.text || msg.Wrap != tt.wrap {
+			t.Errorf("message %d = %q (wrap %d), want %q (wrap %d)", idx, msg.Text, msg.Wrap, tt.text, tt.wrap)
+		}
+	}
+	if note := events[3].Note; note.Text != "A long note" || note.Wrap != ir.SeqWrapOn {
+		t.Errorf("note = %q (wrap %d), want wrapped %q", note.Text, note.Wrap, "A long note")
+	}
+	if out.Graph.SeqWrap {
+		t.Error("SeqWrap should be false without a directive")
+	}
+}
+
+func TestSequenceWrapDirective(t *testing.T) {
+	for _, directive := range []string{
+		"%%{wrap}%%",
+		`%%{init: {"sequence": {"wrap": true}}}%%`,
+	} {
+		out, err := Parse(directive + "\nsequenceDiagram\n    A->>B: Hi")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", directive, err)
+		}
+		if out.Graph.Kind != ir.Sequence || !out.Graph.SeqWrap {
+			t.Errorf("%s: kind = %v, SeqWrap = %v, want wrapped sequence", directive, out.Graph.Kind, out.Graph.SeqWrap)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+var (
+	styleStatementRe    = regexp.MustCompile(`^style\s+([\w,-]+)\s+(.+)$`)
+	classDefStatementRe = regexp.MustCompile(`^classDef\s+([\w,-]+)\s+(.+)$`)
+	classStatementRe    = regexp.MustCompile(`^class\s+([\w,-]+)\s+([\w-]+)\s*;?$`)
+)
+
+// parseStyleStatement records a style, classDef or class statement on the
+// graph and reports whether the line was one:
+//
+//	style id fill:#f9f,stroke:#333
+//	classDef name,other fill:#f9f
+//	class id,other name
+func parseStyleStatement(graph *ir.Graph, line string) bool {
+	if match := styleStatementRe.FindStringSubmatch(line); match != nil {
+		for _, id := range strings.Split(match[1], ",") {
+			graph.NodeStyles[id] = parseStyleProps(match[2])
+		}
+		return true
+	}
+	if match := classDefStatementRe.FindStringSubmatch(line); match != nil {
+		for _, name := range strings.Split(match[1], ",") {
+			graph.ClassDefs[name] = parseStyleProps(match[2])
+		}
+		return true
+	}
+	if match := classStatementRe.FindStringSubmatch(line); match != nil {
+		for _, id := range strings.Split(match[1], ",") {
+			addNodeClasses(graph, id, []string{match[2]})
+		}
+		return true
+	}
+	return false
+}
+
+// parseStyleProps parses a CSS-like property list such as
+// "fill:#f9f,stroke:#333,stroke-width:4px". Commas inside parentheses, as
+// in rgb(1,2,3), do not split properties. Unknown properties are ignored.
+func parseStyleProps(props string) *ir.NodeStyle {
+	style := &ir.NodeStyle{}
+	for _, prop := range splitStyleProps(strings.TrimSuffix(strings.TrimSpace(props), ";")) {
+		name, value, ok := strings.Cut(prop, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "fill":
+			style.Fill = &value
+		case "stroke":
+			style.Stroke = &value
+		case "color":
+			style.TextColor = &value
+		case "stroke-width":
+			if width, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32); err == nil {
+				strokeWidth := float32(width)
+				style.StrokeWidth = &strokeWidth
+			}
+		case "stroke-dasharray":
+			style.StrokeDasharray = &value
+		}
+	}
+	return style
+}
+
+// splitStyleProps splits a property list on commas and semicolons outside
+// parentheses.
+func splitStyleProps(props string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for idx, char := range props {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth = max(0, depth-1)
+		case ',', ';':
+			if depth == 0 {
+				parts = append(parts, props[start:idx])
+				start = idx + 1
+			}
+		}
+	}
+	return append(parts, props[start:])
+}
@@ -32,6 +32,13 @@ func parseTreemap(input string) (*ParseOutput, error) { //nolint:unparam // erro
 			continue
 		}
 
+		// classDef statements give ::: classes their colours. The keyword
+		// matches in any case.
+		if strings.HasPrefix(lower, "classdef ") &&
+			parseStyleStatement(graph, "classDef "+strings.TrimSpace(text)[len("classdef "):]) {
+			continue
+		}
+
 		// Handle title directive.
 		if strings.HasPrefix(lower, "title ") || strings.HasPrefix(lower, "title\t") {
 			graph.TreemapTitle = strings.TrimSpace(text[6:])
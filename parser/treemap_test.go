@@ -111,6 +111,35 @@ func TestParseTreemapClass(t *testing.T) {
 	}
 }
 
+func TestParseTreemapClassDef(t *testing.T) {
+	input := `treemap
+"Root"
+    "Leaf": 42 :::hot
+classDef hot fill:#f96,stroke:#333,color:#fff`
+
+	out, err := parseTreemap(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got := len(out.Graph.TreemapRoot.Children); got != 1 {
+		t.Fatalf("children = %d, want 1", got)
+	}
+	hot := out.Graph.ClassDefs["hot"]
+	if hot == nil || hot.Fill == nil || *hot.Fill != "#f96" || hot.TextColor == nil || *hot.TextColor != "#fff" {
+		t.Errorf("classDef hot = %+v", hot)
+	}
+}
+
+func TestParseTreemapClassDefAnyCase(t *testing.T) {
+	out, err := parseTreemap("treemap\n\"Root\"\n    \"Leaf\": 42 :::hot\nCLASSDEF hot fill:#f96")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if hot := out.Graph.ClassDefs["hot"]; hot == nil || hot.Fill == nil || *hot.Fill != "#f96" {
+		t.Errorf("classDef hot = %+v", hot)
+	}
+}
+
 func TestParseTreemapNodeLine(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -12,6 +12,9 @@ var (
 	xyValuesRe   = regexp.MustCompile(`\[([^\]]+)\]`)
 	xyNumAxisRe  = regexp.MustCompile(`^(?:"([^"]*)"?\s+)?(-?[\d.]+)\s*-->\s*(-?[\d.]+)$`)
 	xyBandAxisRe = regexp.MustCompile(`^(?:"([^"]*)"?\s+)?\[([^\]]+)\]$`)
+	// xySeriesRe matches a series head: the type, an optional quoted name
+	// and an optional y2 marker on either side of it.
+	xySeriesRe = regexp.MustCompile(`(?i)^(bar|line)(\s+y2)?(?:\s+"([^"]*)")?(\s+y2)?\s*\[`)
 )
 
 func parseXYChart(input string) (*ParseOutput, error) { //nolint:unparam // error return is part of the parser interface contract used by Parse().
@@ -43,20 +46,15 @@ func parseXYChart(input string) (*ParseOutput, error) { //nolint:unparam // erro
 		case strings.HasPrefix(lower, "y-axis"):
 			graph.XYYAxis = parseXYAxis(strings.TrimSpace(trimmed[6:]))
 
-		case strings.HasPrefix(lower, "bar"):
-			if vals := parseXYValues(trimmed); vals != nil {
-				graph.XYSeries = append(graph.XYSeries, &ir.XYSeries{
-					Type:   ir.XYSeriesBar,
-					Values: vals,
-				})
-			}
+		case strings.HasPrefix(lower, "y2-axis"):
+			graph.XYY2Axis = parseXYAxis(strings.TrimSpace(trimmed[7:]))
+
+		case lower == "stacked":
+			graph.XYStacked = true
 
-		case strings.HasPrefix(lower, "line"):
-			if vals := parseXYValues(trimmed); vals != nil {
-				graph.XYSeries = append(graph.XYSeries, &ir.XYSeries{
-					Type:   ir.XYSeriesLine,
-					Values: vals,
-				})
+		default:
+			if series := parseXYSeries(trimmed); series != nil {
+				graph.XYSeries = append(graph.XYSeries, series)
 			}
 		}
 	}
@@ -85,6 +83,29 @@ func parseXYAxis(str string) *ir.XYAxis {
 	return &ir.XYAxis{Mode: ir.XYAxisNumeric, Title: title}
 }
 
+// parseXYSeries parses a series line such as `bar "Requests" [1, 2]` or
+// `line y2 "Latency" [3, 4]`. It returns nil for anything else.
+func parseXYSeries(line string) *ir.XYSeries {
+	match := xySeriesRe.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	vals := parseXYValues(line)
+	if vals == nil {
+		return nil
+	}
+	series := &ir.XYSeries{
+		Type:      ir.XYSeriesBar,
+		Name:      match[3],
+		Secondary: match[2] != "" || match[4] != "",
+		Values:    vals,
+	}
+	if strings.EqualFold(match[1], "line") {
+		series.Type = ir.XYSeriesLine
+	}
+	return series
+}
+
 func parseXYValues(line string) []float64 {
 	match := xyValuesRe.FindStringSubmatch(line)
 	if match == nil {
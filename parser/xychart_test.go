@@ -99,3 +99,49 @@ func TestParseXYChartMinimal(t *testing.T) {
 		t.Errorf("value[0] = %v, want 1.5", out.Graph.XYSeries[0].Values[0])
 	}
 }
+
+func TestParseXYChartNamedSeries(t *testing.T) {
+	input := `xychart-beta
+    x-axis [Q1, Q2]
+    y2-axis "Latency" 0 --> 500
+    stacked
+    bar "EU requests" [10, 20]
+    bar[5, 6]
+    line y2 "p99" [120, 140]
+    line "p50" y2 [60, 70]`
+
+	out, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	graph := out.Graph
+	if !graph.XYStacked {
+		t.Error("XYStacked = false, want true")
+	}
+	if graph.XYY2Axis == nil || graph.XYY2Axis.Title != "Latency" || graph.XYY2Axis.Max != 500 {
+		t.Errorf("XYY2Axis = %+v, want Latency 0 --> 500", graph.XYY2Axis)
+	}
+	want := []struct {
+		typ       ir.XYSeriesType
+		name      string
+		secondary bool
+	}{
+		{ir.XYSeriesBar, "EU requests", false},
+		{ir.XYSeriesBar, "", false},
+		{ir.XYSeriesLine, "p99", true},
+		{ir.XYSeriesLine, "p50", true},
+	}
+	if len(graph.XYSeries) != len(want) {
+		t.Fatalf("series len = %d, want %d", len(graph.XYSeries), len(want))
+	}
+	for i, w := range want {
+		got := graph.XYSeries[i]
+		if got.Type != w.typ || got.Name != w.name || got.Secondary != w.secondary {
+			t.Errorf("series[%d] = {%v %q %v}, want {%v %q %v}",
+				i, got.Type, got.Name, got.Secondary, w.typ, w.name, w.secondary)
+		}
+	}
+	if graph.XYSeries[0].Values[1] != 20 {
+		t.Errorf("series[0] value[1] = %v, want 20", graph.XYSeries[0].Values[1])
+	}
+}
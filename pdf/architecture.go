@@ -0,0 +1,139 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Architecture diagram drawing constants, matching the SVG renderer.
+const (
+	archGroupBorderRadius   float32 = 8
+	archGroupLabelOffsetX   float32 = 10
+	archGroupLabelOffsetY   float32 = 18
+	archGroupFontScale      float32 = 0.9
+	archGroupIconOffsetX    float32 = 20
+	archGroupIconOffsetY    float32 = 14
+	archGroupIconSize       float32 = 10
+	archGroupDash                   = "6,3"
+	archServiceBorderRadius float32 = 6
+	archServiceBorderWidth  float32 = 1.5
+	archServiceIconOffsetY  float32 = 10
+	archServiceIconSize     float32 = 12
+	archLabelOffsetY        float32 = 8
+	archLabelIconOffsetY    float32 = 14
+	archEllipseYScale       float32 = 0.6
+	archCloudXScale         float32 = 1.2
+	archCloudYScale         float32 = 0.7
+	archServerRadius        float32 = 2
+	archServerLineInset     float32 = 2
+	archServerLineColor             = "#fff"
+	archGlobeWidth          float32 = 1.5
+	archGlobeBulge          float32 = 0.5
+	archIconColor                   = "#78909C"
+)
+
+// drawArchitecture draws groups, then edges, then services and junctions.
+func drawArchitecture(pen *painter, computed *layout.Layout, data layout.ArchitectureData, th *theme.Theme, cfg *config.Layout) {
+	for _, grp := range data.Groups {
+		if grp.Width <= 0 || grp.Height <= 0 {
+			continue
+		}
+		pen.rect(grp.X, grp.Y, grp.Width, grp.Height, archGroupBorderRadius,
+			shapeStyle{fill: th.ArchGroupFill, stroke: th.ArchGroupBorder, dash: archGroupDash})
+		pen.label(grp.X+archGroupLabelOffsetX, grp.Y+archGroupLabelOffsetY, grp.Label,
+			textStyle{size: th.FontSize * archGroupFontScale, color: th.ArchGroupText, bold: true})
+		if grp.Icon != "" {
+			drawArchIcon(pen, cfg, grp.Icon, grp.X+grp.Width-archGroupIconOffsetX, grp.Y+archGroupIconOffsetY, archGroupIconSize)
+		}
+	}
+
+	// Edges take the architecture edge colour; their markers stay in the
+	// theme's line colour, as in the SVG.
+	for _, edge := range computed.Edges {
+		if len(edge.Points) < 2 {
+			continue
+		}
+		style := shapeStyle{stroke: th.ArchEdgeColor, width: edgeWidth, round: true}
+		switch edge.Style {
+		case ir.Dotted:
+			style.dash = dottedEdgeDash
+		case ir.Thick:
+			style.width = thickEdgeWidth
+		}
+		start, end := markerNone, markerNone
+		if edge.ArrowStart {
+			start = markerArrow
+		}
+		if edge.ArrowEnd {
+			end = markerArrow
+		}
+		pen.edge(edge.Points, style, start, end, th)
+		if edge.Label != nil && len(edge.Label.Lines) > 0 {
+			drawEdgeLabel(pen, edge, th, th.LabelTextColor)
+		}
+	}
+
+	junctions := make(map[string]bool, len(data.Junctions))
+	for _, junc := range data.Junctions {
+		junctions[junc.ID] = true
+	}
+	for _, id := range sortedNodeIDs(computed) {
+		if junctions[id] {
+			continue
+		}
+		node := computed.Nodes[id]
+		pen.rect(node.X-node.Width/2, node.Y-node.Height/2, node.Width, node.Height, archServiceBorderRadius,
+			shapeStyle{fill: th.ArchServiceFill, stroke: th.ArchServiceBorder, width: archServiceBorderWidth})
+
+		// An icon sits above the label, which moves down to make room.
+		labelY := node.Y + archLabelOffsetY
+		if svc, ok := data.Services[id]; ok && svc.Icon != "" {
+			drawArchIcon(pen, cfg, svc.Icon, node.X, node.Y-archServiceIconOffsetY, archServiceIconSize)
+			labelY = node.Y + archLabelIconOffsetY
+		}
+		pen.label(node.X, labelY, firstLine(node.Label),
+			textStyle{size: th.FontSize, color: th.ArchServiceText, anchor: "middle"})
+	}
+
+	for _, junc := range data.Junctions {
+		pen.circle(junc.X, junc.Y, junc.Size/2, shapeStyle{fill: th.ArchJunctionFill})
+	}
+}
+
+// drawArchIcon draws an icon centred on (cx, cy): one from the configured
+// icon registry, or else a simple built-in glyph.
+func drawArchIcon(pen *painter, cfg *config.Layout, icon string, cx, cy, size float32) {
+	if pen.icon(cfg, icon, cx, cy, size, archIconColor) {
+		return
+	}
+	half := size / 2
+	solid := shapeStyle{fill: archIconColor}
+	switch icon {
+	case "database":
+		pen.ellipse(cx, cy, half, half*archEllipseYScale, solid)
+	case "server":
+		pen.rect(cx-half, cy-half, size, size, archServerRadius, solid)
+		third := size / 3 //nolint:mnd // two lines split the box in thirds.
+		rule := shapeStyle{stroke: archServerLineColor}
+		pen.line(cx-half+archServerLineInset, cy-half+third, cx+half-archServerLineInset, cy-half+third, rule)
+		pen.line(cx-half+archServerLineInset, cy-half+2*third, cx+half-archServerLineInset, cy-half+2*third, rule)
+	case "cloud":
+		pen.ellipse(cx, cy, half*archCloudXScale, half*archCloudYScale, solid)
+	case "internet":
+		// A globe: a circle with a cross and a meridian.
+		pen.circle(cx, cy, half, shapeStyle{stroke: archIconColor, width: archGlobeWidth})
+		rule := shapeStyle{stroke: archIconColor}
+		pen.line(cx-half, cy, cx+half, cy, rule)
+		pen.line(cx, cy-half, cx, cy+half, rule)
+		var meridian canvas.Path
+		top := pt(cx, cy-half)
+		meridian.MoveTo(top)
+		meridian.QuadTo(top, pt(cx+half*archGlobeBulge, cy), pt(cx, cy+half))
+		pen.shape(meridian, rule)
+	case "disk":
+		pen.circle(cx, cy, half, solid)
+	}
+}
@@ -0,0 +1,159 @@
+package pdf
+
+import (
+	"sort"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Block diagram drawing constants, matching the SVG renderer.
+const (
+	blockCompositeRadius   float32 = 4
+	blockCompositeLabelPad float32 = 4
+	blockLabelFontScale    float32 = 0.9
+	// blockArrowShaft is a block arrow's shaft thickness as a fraction of
+	// its cross-axis size.
+	blockArrowShaft float32 = 0.5
+	// blockArrowHeadRatio limits a block arrow's head to this fraction of
+	// its length.
+	blockArrowHeadRatio float32 = 0.4
+	blockBorderFallback         = "#3B6492"
+)
+
+// blockFallbackColors are the palette when the theme has none.
+var blockFallbackColors = []string{"#D4E6F1", "#D5F5E3", "#FCF3CF", "#FADBD8"}
+
+// drawBlock draws composite blocks, outermost first, then the other
+// blocks in the theme's palette, then the edges on top.
+func drawBlock(pen *painter, computed *layout.Layout, data layout.BlockData, th *theme.Theme) {
+	colors := th.BlockColors
+	if len(colors) == 0 {
+		colors = blockFallbackColors
+	}
+	borderColor := th.BlockNodeBorder
+	if borderColor == "" {
+		borderColor = blockBorderFallback
+	}
+	ids := sortedNodeIDs(computed)
+	colorsOf := func(idx int, node *layout.NodeLayout) (string, string, string) {
+		fill := colors[idx%len(colors)]
+		if node.Style.Fill != nil {
+			fill = *node.Style.Fill
+		}
+		stroke := borderColor
+		if node.Style.Stroke != nil {
+			stroke = *node.Style.Stroke
+		}
+		textColor := th.PrimaryTextColor
+		if node.Style.TextColor != nil {
+			textColor = *node.Style.TextColor
+		}
+		return fill, stroke, textColor
+	}
+
+	// Composite blocks go first, largest first, so their children are
+	// drawn on top.
+	var composites []int
+	for idx, id := range ids {
+		if data.BlockInfos[id].HasChildren {
+			composites = append(composites, idx)
+		}
+	}
+	sort.SliceStable(composites, func(left, right int) bool {
+		nodeL, nodeR := computed.Nodes[ids[composites[left]]], computed.Nodes[ids[composites[right]]]
+		return nodeL.Width*nodeL.Height > nodeR.Width*nodeR.Height
+	})
+	for _, idx := range composites {
+		node := computed.Nodes[ids[idx]]
+		fill, stroke, textColor := colorsOf(idx, node)
+		drawBlockComposite(pen, node, fill, stroke, textColor, th)
+	}
+
+	for idx, id := range ids {
+		node := computed.Nodes[id]
+		info := data.BlockInfos[id]
+		if info.HasChildren {
+			continue
+		}
+		fill, stroke, textColor := colorsOf(idx, node)
+		if info.Kind == ir.BlockArrow {
+			style := shapeStyle{fill: fill, stroke: stroke}
+			if node.Style.StrokeWidth != nil {
+				style.width = *node.Style.StrokeWidth
+			}
+			pen.polygon(blockArrowPoints(node, info.ArrowDir), style)
+			drawNodeLabel(pen, node, textColor)
+			continue
+		}
+		drawNodeShape(pen, node, fill, stroke, textColor)
+	}
+
+	drawEdges(pen, computed, th, func(*layout.EdgeLayout, bool) markerKind { return markerArrow })
+}
+
+// drawBlockComposite draws a composite block's rectangle with its label,
+// if any, at the top.
+func drawBlockComposite(pen *painter, node *layout.NodeLayout, fill, stroke, textColor string, th *theme.Theme) {
+	style := shapeStyle{fill: fill, stroke: stroke}
+	if node.Style.StrokeDasharray != nil {
+		style.dash = *node.Style.StrokeDasharray
+	}
+	pen.rect(node.X-node.Width/2, node.Y-node.Height/2, node.Width, node.Height, blockCompositeRadius, style)
+
+	fontSize := th.FontSize * blockLabelFontScale
+	pen.label(node.X, node.Y-node.Height/2+blockCompositeLabelPad+fontSize, firstLine(node.Label), textStyle{
+		size:   fontSize,
+		color:  textColor,
+		anchor: "middle",
+		bold:   true,
+	})
+}
+
+// blockArrowPoints returns the outline of a block arrow filling a node's
+// box and pointing in dir; x and y arrows have a head at both ends.
+func blockArrowPoints(node *layout.NodeLayout, dir ir.BlockArrowDirection) [][2]float32 {
+	left, top := node.X-node.Width/2, node.Y-node.Height/2
+	right, bottom := node.X+node.Width/2, node.Y+node.Height/2
+
+	// Build the outline along a "length" axis u and a "cross" axis v,
+	// centred on v = 0, then map it onto the box.
+	vertical := dir == ir.BlockArrowUp || dir == ir.BlockArrowDown || dir == ir.BlockArrowY
+	length, cross := node.Width, node.Height
+	if vertical {
+		length, cross = node.Height, node.Width
+	}
+	head := min(cross/2, length*blockArrowHeadRatio) //nolint:mnd // head as deep as half the cross size.
+	shaft := cross * blockArrowShaft / 2             //nolint:mnd // half-thickness.
+	half := cross / 2                                //nolint:mnd // half the cross size.
+
+	var outline [][2]float32
+	if dir == ir.BlockArrowX || dir == ir.BlockArrowY {
+		outline = [][2]float32{
+			{0, 0}, {head, -half}, {head, -shaft}, {length - head, -shaft}, {length - head, -half},
+			{length, 0}, {length - head, half}, {length - head, shaft}, {head, shaft}, {head, half},
+		}
+	} else {
+		outline = [][2]float32{
+			{0, -shaft}, {length - head, -shaft}, {length - head, -half},
+			{length, 0}, {length - head, half}, {length - head, shaft}, {0, shaft},
+		}
+	}
+
+	points := make([][2]float32, len(outline))
+	for idx, point := range outline {
+		along, across := point[0], point[1]
+		switch dir {
+		case ir.BlockArrowLeft:
+			points[idx] = [2]float32{right - along, node.Y + across}
+		case ir.BlockArrowDown, ir.BlockArrowY:
+			points[idx] = [2]float32{node.X + across, top + along}
+		case ir.BlockArrowUp:
+			points[idx] = [2]float32{node.X + across, bottom - along}
+		default:
+			points[idx] = [2]float32{left + along, node.Y + across}
+		}
+	}
+	return points
+}
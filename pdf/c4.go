@@ -0,0 +1,272 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// C4 diagram drawing constants, matching the SVG renderer.
+const (
+	c4SmallFontScale      float32 = 0.85
+	c4BoundaryRadius      float32 = 4
+	c4BoundaryLabelOffset float32 = 8
+	c4BoundaryLabelY      float32 = 16
+	c4BoundaryTypeY       float32 = 30
+	c4BoundaryLineStep    float32 = 14
+	c4BoundaryFontScale   float32 = 0.9
+	c4BoundaryTypeFScale  float32 = 0.8
+	c4BoundaryDash                = "5,5"
+	c4NodeBorderRadius    float32 = 6
+	c4TextBaselineShift   float32 = 0.25
+	c4PersonTextOffsetY   float32 = 50
+	c4PersonNodeRadius    float32 = 6
+	c4PersonHeadRadius    float32 = 12
+	c4PersonHeadCenterY   float32 = 18
+	c4PersonBodyWidth     float32 = 20
+	c4PersonBodyArcHeight float32 = 24
+	c4PersonBodyGap       float32 = 2
+	c4PersonBodyLineWidth float32 = 2
+	c4LegendRadius        float32 = 2
+	c4LegendBoundaryDash          = "3,3"
+	// c4OctagonCut is the corner cut of an EightSidedShape, as a fraction
+	// of the element's smaller side.
+	c4OctagonCut float32 = 0.2
+)
+
+// c4EightSidedShape is the UpdateElementStyle shape drawn as an octagon.
+const c4EightSidedShape = "EightSidedShape"
+
+// drawC4 draws boundaries, then relationships, then elements with their
+// type colours and person icons, then the legend.
+func drawC4(pen *painter, computed *layout.Layout, data layout.C4Data, th *theme.Theme, cfg *config.Layout) {
+	smallFontSize := th.FontSize * c4SmallFontScale
+	lineH := th.FontSize * cfg.LabelLineHeight
+	smallLineH := smallFontSize * cfg.LabelLineHeight
+
+	for _, boundary := range data.Boundaries {
+		drawC4Boundary(pen, boundary, data.BoundaryStyles[boundary.ID], th)
+	}
+	drawC4Edges(pen, computed, data, th)
+
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		elem := data.Elements[id]
+		person := elem != nil && elem.Type.IsPerson()
+
+		style := c4ElementStyle(data, id, elem)
+		color := c4StyleValue(style.BgColor, c4ElementColor(elem, th))
+		textColor := c4StyleValue(style.FontColor, th.C4TextColor)
+		shape := shapeStyle{fill: color, stroke: style.BorderColor}
+
+		posX := node.X - node.Width/2
+		posY := node.Y - node.Height/2
+		switch {
+		case person:
+			drawC4Person(pen, posX, posY, node.Width, node.Height, shape, textColor)
+		case style.Shape == c4EightSidedShape:
+			pen.polygon(c4Octagon(posX, posY, node.Width, node.Height), shape)
+		default:
+			pen.rect(posX, posY, node.Width, node.Height, c4NodeBorderRadius, shape)
+		}
+
+		// Person text sits below the icon.
+		curY := posY + node.Height/2 - lineH*c4TextBaselineShift
+		if person {
+			curY = posY + c4PersonTextOffsetY
+		}
+		if len(node.Label.Lines) > 0 {
+			pen.label(node.X, curY, node.Label.Lines[0],
+				textStyle{size: th.FontSize, color: textColor, anchor: "middle", bold: true})
+			curY += lineH
+		}
+		if elem == nil {
+			continue
+		}
+		small := textStyle{size: smallFontSize, color: textColor, anchor: "middle"}
+		if elem.Technology != "" {
+			pen.label(node.X, curY, "["+elem.Technology+"]", small)
+			curY += smallLineH
+		}
+		pen.label(node.X, curY, elem.Description, small)
+	}
+
+	drawC4Legend(pen, data, th)
+}
+
+// drawC4Boundary draws a boundary's rectangle, label and subtitle lines,
+// applying any UpdateBoundaryStyle overrides. Deployment nodes have solid
+// borders.
+func drawC4Boundary(pen *painter, boundary *layout.C4BoundaryLayout, style *ir.C4Style, th *theme.Theme) {
+	if style == nil {
+		style = &ir.C4Style{}
+	}
+	textColor := c4StyleValue(style.FontColor, th.C4BoundaryColor)
+	frame := shapeStyle{fill: style.BgColor, stroke: c4StyleValue(style.BorderColor, th.C4BoundaryColor)}
+	if !boundary.Deployment {
+		frame.dash = c4BoundaryDash
+	}
+	pen.rect(boundary.X, boundary.Y, boundary.Width, boundary.Height, c4BoundaryRadius, frame)
+
+	pen.label(boundary.X+c4BoundaryLabelOffset, boundary.Y+c4BoundaryLabelY, boundary.Label,
+		textStyle{size: th.FontSize * c4BoundaryFontScale, color: textColor, bold: true})
+	var lines []string
+	if boundary.Type != "" {
+		lines = append(lines, "["+boundary.Type+"]")
+	}
+	if boundary.Description != "" {
+		lines = append(lines, boundary.Description)
+	}
+	curY := boundary.Y + c4BoundaryTypeY
+	for _, line := range lines {
+		pen.label(boundary.X+c4BoundaryLabelOffset, curY, line,
+			textStyle{size: th.FontSize * c4BoundaryTypeFScale, color: textColor})
+		curY += c4BoundaryLineStep
+	}
+}
+
+// drawC4Edges draws the relationships like drawEdges, using the line and
+// text colours of their UpdateRelStyle statements.
+func drawC4Edges(pen *painter, computed *layout.Layout, data layout.C4Data, th *theme.Theme) {
+	for edgeIdx, edge := range computed.Edges {
+		if len(edge.Points) < 2 {
+			continue
+		}
+		lineColor, textColor := th.LineColor, th.LabelTextColor
+		if edgeIdx < len(data.Rels) && data.Rels[edgeIdx] != nil {
+			lineColor = c4StyleValue(data.Rels[edgeIdx].LineColor, lineColor)
+			textColor = c4StyleValue(data.Rels[edgeIdx].TextColor, textColor)
+		}
+		start, end := markerNone, markerNone
+		if edge.ArrowStart {
+			start = markerArrow
+		}
+		if edge.ArrowEnd {
+			end = markerArrow
+		}
+		pen.edge(edge.Points, shapeStyle{stroke: lineColor, width: edgeWidth, round: true}, start, end, th)
+		if edge.Label != nil && len(edge.Label.Lines) > 0 {
+			drawEdgeLabel(pen, edge, th, textColor)
+		}
+	}
+}
+
+// drawC4Legend draws a swatch and text for each legend entry. Element
+// swatches take the element's fill; boundary swatches its border.
+func drawC4Legend(pen *painter, data layout.C4Data, th *theme.Theme) {
+	for _, entry := range data.Legend {
+		var swatch shapeStyle
+		if entry.Boundary {
+			style := data.BoundaryStyles[entry.Key]
+			swatch = shapeStyle{
+				fill:   style.BgColor,
+				stroke: c4StyleValue(style.BorderColor, th.C4BoundaryColor),
+				dash:   c4LegendBoundaryDash,
+			}
+		} else {
+			style := data.ElementStyles[entry.Key]
+			fill := c4ElementColor(data.Elements[entry.Key], th)
+			if data.Elements[entry.Key] == nil {
+				fill = c4ElementColor(&ir.C4Element{Type: c4TypeForStyleKey(entry.Key)}, th)
+			}
+			swatch = shapeStyle{fill: c4StyleValue(style.BgColor, fill), stroke: style.BorderColor}
+		}
+		pen.rect(entry.X, entry.Y, data.LegendSwatch, data.LegendSwatch, c4LegendRadius, swatch)
+		pen.label(entry.TextX, entry.Y+data.LegendSwatch/2, entry.Text, textStyle{
+			size:     th.FontSize * c4SmallFontScale,
+			color:    th.TextColor,
+			baseline: "middle",
+		})
+	}
+}
+
+// c4ElementStyle merges the UpdateElementStyle overrides for an element:
+// those naming its ID win over those naming its type.
+func c4ElementStyle(data layout.C4Data, id string, elem *ir.C4Element) ir.C4Style {
+	var merged ir.C4Style
+	var styles []*ir.C4Style
+	if elem != nil {
+		styles = append(styles, data.ElementStyles[elem.Type.StyleKey()])
+	}
+	styles = append(styles, data.ElementStyles[id])
+	for _, style := range styles {
+		if style == nil {
+			continue
+		}
+		merged.BgColor = c4StyleValue(style.BgColor, merged.BgColor)
+		merged.FontColor = c4StyleValue(style.FontColor, merged.FontColor)
+		merged.BorderColor = c4StyleValue(style.BorderColor, merged.BorderColor)
+		merged.Shape = c4StyleValue(style.Shape, merged.Shape)
+	}
+	return merged
+}
+
+// c4TypeForStyleKey returns an element type with the given style key.
+func c4TypeForStyleKey(key string) ir.C4ElementType {
+	for elemType := ir.C4Person; elemType <= ir.C4ExternalComponent; elemType++ {
+		if elemType.StyleKey() == key {
+			return elemType
+		}
+	}
+	return ir.C4System
+}
+
+// c4StyleValue returns value, or fallback when value is empty.
+func c4StyleValue(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// c4Octagon returns the corners of an eight-sided element shape.
+func c4Octagon(posX, posY, width, height float32) [][2]float32 {
+	cut := min(width, height) * c4OctagonCut
+	return [][2]float32{
+		{posX + cut, posY}, {posX + width - cut, posY},
+		{posX + width, posY + cut}, {posX + width, posY + height - cut},
+		{posX + width - cut, posY + height}, {posX + cut, posY + height},
+		{posX, posY + height - cut}, {posX, posY + cut},
+	}
+}
+
+// drawC4Person draws a C4 person: a rounded rectangle with a head and
+// shoulders icon centred at the top.
+func drawC4Person(pen *painter, posX, posY, width, height float32, style shapeStyle, iconColor string) {
+	pen.rect(posX, posY, width, height, c4PersonNodeRadius, style)
+
+	cx := posX + width/2
+	headCY := posY + c4PersonHeadCenterY
+	pen.circle(cx, headCY, c4PersonHeadRadius, shapeStyle{fill: iconColor})
+
+	bodyTop := headCY + c4PersonHeadRadius + c4PersonBodyGap
+	var body canvas.Path
+	start := pt(cx-c4PersonBodyWidth, bodyTop)
+	body.MoveTo(start)
+	body.QuadTo(start, pt(cx, bodyTop+c4PersonBodyArcHeight), pt(cx+c4PersonBodyWidth, bodyTop))
+	pen.shape(body, shapeStyle{stroke: iconColor, width: c4PersonBodyLineWidth, round: true})
+}
+
+// c4ElementColor returns the fill colour for a C4 element based on its
+// type.
+func c4ElementColor(elem *ir.C4Element, th *theme.Theme) string {
+	if elem == nil {
+		return th.C4SystemColor
+	}
+	if elem.Type.IsExternal() {
+		return th.C4ExternalColor
+	}
+	if elem.Type.IsPerson() {
+		return th.C4PersonColor
+	}
+	switch elem.Type {
+	case ir.C4ContainerPlain, ir.C4ContainerDb, ir.C4ContainerQueue:
+		return th.C4ContainerColor
+	case ir.C4ComponentPlain:
+		return th.C4ComponentColor
+	default:
+		return th.C4SystemColor
+	}
+}
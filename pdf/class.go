@@ -0,0 +1,111 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Class diagram drawing constants, matching the SVG renderer.
+const (
+	classMemberFontScale     float32 = 0.85
+	classLineHeightScale     float32 = 1.2
+	classHeaderBaselineShift float32 = 0.75
+	classDefaultPadX         float32 = 12
+)
+
+// drawClass draws class diagram edges with UML markers, then the classes
+// as compartment boxes.
+func drawClass(pen *painter, computed *layout.Layout, data layout.ClassData, th *theme.Theme, cfg *config.Layout) {
+	drawEdges(pen, computed, th, func(edge *layout.EdgeLayout, start bool) markerKind {
+		if start {
+			return classMarker(edge.ArrowStartKind)
+		}
+		return classMarker(edge.ArrowEndKind)
+	})
+
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		comp, hasComp := data.Compartments[id]
+		members := data.Members[id]
+		if !hasComp || members == nil {
+			fill, stroke, textColor := nodeColors(node, th)
+			drawNodeShape(pen, node, fill, stroke, textColor)
+			continue
+		}
+		drawClassNode(pen, node, members, comp, data.Annotations[id], th, cfg)
+	}
+}
+
+// drawClassNode draws a class box with header, attribute and method
+// compartments.
+func drawClassNode(pen *painter, node *layout.NodeLayout, members *ir.ClassMembers, comp layout.ClassCompartment, annotation string, th *theme.Theme, cfg *config.Layout) {
+	posX := node.X - node.Width/2
+	posY := node.Y - node.Height/2
+	width := node.Width
+
+	fontSize := node.Label.FontSize
+	if fontSize <= 0 {
+		fontSize = th.FontSize
+	}
+	memberFontSize := cfg.Class.MemberFontSize
+	if memberFontSize <= 0 {
+		memberFontSize = fontSize * classMemberFontScale
+	}
+	lineH := fontSize * classLineHeightScale
+	memberLineH := memberFontSize * classLineHeightScale
+	padX := cfg.Class.CompartmentPadX
+	if padX <= 0 {
+		padX = classDefaultPadX
+	}
+
+	border := shapeStyle{stroke: th.ClassBorder}
+	body := border
+	body.fill = th.ClassBodyBg
+	pen.rect(posX, posY, width, node.Height, defaultRectRadius, body)
+	header := border
+	header.fill = th.ClassHeaderBg
+	pen.rect(posX, posY, width, comp.HeaderHeight, defaultRectRadius, header)
+
+	textLines := 1
+	if annotation != "" {
+		textLines = 2
+	}
+	startY := posY + comp.HeaderHeight/2 - lineH*float32(textLines)/2 + lineH*classHeaderBaselineShift
+	if annotation != "" {
+		pen.label(posX+width/2, startY, "«"+annotation+"»", textStyle{
+			size:   fontSize * classMemberFontScale,
+			color:  th.PrimaryTextColor,
+			anchor: "middle",
+			italic: true,
+		})
+		startY += lineH
+	}
+	if len(node.Label.Lines) > 0 {
+		pen.label(posX+width/2, startY, node.Label.Lines[0], textStyle{
+			size:   fontSize,
+			color:  th.PrimaryTextColor,
+			anchor: "middle",
+			bold:   true,
+		})
+	}
+
+	member := textStyle{size: memberFontSize, color: th.TextColor}
+	dividerY := posY + comp.HeaderHeight
+	pen.line(posX, dividerY, posX+width, dividerY, border)
+	for idx, attr := range members.Attributes {
+		pen.label(posX+padX, dividerY+memberLineH*float32(idx+1),
+			attr.Visibility.Symbol()+attr.Type+" "+attr.Name, member)
+	}
+
+	dividerY += comp.AttributeHeight
+	pen.line(posX, dividerY, posX+width, dividerY, border)
+	for idx, meth := range members.Methods {
+		text := meth.Visibility.Symbol() + meth.Name + "(" + meth.Params + ")"
+		if meth.Type != "" {
+			text += " : " + meth.Type
+		}
+		pen.label(posX+padX, dividerY+memberLineH*float32(idx+1), text, member)
+	}
+}
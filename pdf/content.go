@@ -0,0 +1,212 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+)
+
+// channelMax is the largest 8-bit color channel value.
+const channelMax = 255.0
+
+// content is the canvas.Backend that builds a page content stream. It
+// draws in page space with the Y axis pointing down; the page writer flips
+// the coordinate system once at the start of the stream.
+type content struct {
+	buf    bytes.Buffer
+	fonts  []*font // in order of first use
+	byFace map[*canvas.Face]*font
+	alphas []uint8 // ExtGState alpha levels in order of first use
+}
+
+// newContent returns an empty content stream.
+func newContent() *content {
+	return &content{byFace: make(map[*canvas.Face]*font)}
+}
+
+// Fill implements canvas.Backend.
+func (c *content) Fill(shape canvas.Path, paint color.NRGBA) {
+	if len(shape) == 0 {
+		return
+	}
+	c.buf.WriteString("q\n")
+	c.setAlpha(paint.A)
+	fmt.Fprintf(&c.buf, "%s %s %s rg\n", channel(paint.R), channel(paint.G), channel(paint.B))
+	c.path(shape)
+	c.buf.WriteString("f\nQ\n")
+}
+
+// Stroke implements canvas.Backend.
+func (c *content) Stroke(shape canvas.Path, stroke canvas.Stroke, paint color.NRGBA) {
+	if len(shape) == 0 || stroke.Width <= 0 {
+		return
+	}
+	c.buf.WriteString("q\n")
+	c.setAlpha(paint.A)
+	fmt.Fprintf(&c.buf, "%s %s %s RG\n", channel(paint.R), channel(paint.G), channel(paint.B))
+	fmt.Fprintf(&c.buf, "%s w %d J %d j\n", num(stroke.Width), capStyle(stroke.Cap), joinStyle(stroke.Join))
+	if len(stroke.Dash) > 0 {
+		c.buf.WriteByte('[')
+		for idx, length := range stroke.Dash {
+			if idx > 0 {
+				c.buf.WriteByte(' ')
+			}
+			c.buf.WriteString(num(length))
+		}
+		c.buf.WriteString("] 0 d\n")
+	}
+	c.path(shape)
+	c.buf.WriteString("S\nQ\n")
+}
+
+// Text implements canvas.Backend. Each stretch of glyphs from one face is
+// shown with a single TJ operator whose adjustments place every glyph at
+// its laid-out position.
+func (c *content) Text(run canvas.TextRun) {
+	if len(run.Glyphs) == 0 || run.Size <= 0 {
+		return
+	}
+	for _, glyph := range run.Glyphs {
+		if glyph.Face.Data == nil {
+			// Fonts that cannot be embedded are drawn as outlines.
+			c.Fill(run.Outlines(), run.Paint)
+			return
+		}
+	}
+	c.buf.WriteString("q\n")
+	c.setAlpha(run.Paint.A)
+	fmt.Fprintf(&c.buf, "%s %s %s rg\nBT\n", channel(run.Paint.R), channel(run.Paint.G), channel(run.Paint.B))
+	for start := 0; start < len(run.Glyphs); {
+		end := start + 1
+		for end < len(run.Glyphs) && run.Glyphs[end].Face == run.Glyphs[start].Face {
+			end++
+		}
+		c.showGlyphs(run, run.Glyphs[start:end])
+		start = end
+	}
+	c.buf.WriteString("ET\nQ\n")
+}
+
+// showGlyphs writes the operators for glyphs that share a face.
+func (c *content) showGlyphs(run canvas.TextRun, glyphs []canvas.Glyph) {
+	fnt := c.font(glyphs[0].Face)
+	// Glyph space has the Y axis up, so the text matrix flips it back. It
+	// also stretches the glyphs as the typesetter fitted them.
+	stretch := glyphs[0].Stretch()
+	matrix := run.Transform.
+		Multiply(canvas.Translate(glyphs[0].X, 0)).
+		Multiply(canvas.Scaling(stretch, -1))
+	fmt.Fprintf(&c.buf, "/%s %s Tf\n", fnt.name, num(run.Size))
+	fmt.Fprintf(&c.buf, "%s %s %s %s %s %s Tm\n[",
+		num(matrix[0]), num(matrix[1]), num(matrix[2]), num(matrix[3]), num(matrix[4]), num(matrix[5]))
+	for idx, glyph := range glyphs {
+		width := fnt.use(glyph.Index, glyph.Rune)
+		fmt.Fprintf(&c.buf, "<%04X>", uint16(glyph.Index))
+		if idx+1 < len(glyphs) {
+			// TJ adjustments are in thousandths of the font size, before
+			// the stretch, and subtract from the natural advance.
+			advance := (glyphs[idx+1].X - glyph.X) / stretch / run.Size * glyphUnits
+			if adjust := width - advance; adjust > 1e-3 || adjust < -1e-3 {
+				c.buf.WriteString(num(adjust))
+			}
+		}
+	}
+	c.buf.WriteString("] TJ\n")
+}
+
+// font returns the embedded font for a face, registering it on first use.
+func (c *content) font(face *canvas.Face) *font {
+	fnt, ok := c.byFace[face]
+	if !ok {
+		fnt = newFont(face, "F"+strconv.Itoa(len(c.fonts)+1))
+		c.byFace[face] = fnt
+		c.fonts = append(c.fonts, fnt)
+	}
+	return fnt
+}
+
+// setAlpha selects a graphics state with the given constant alpha.
+func (c *content) setAlpha(alpha uint8) {
+	if alpha == uint8(channelMax) {
+		return
+	}
+	idx := -1
+	for known, level := range c.alphas {
+		if level == alpha {
+			idx = known
+		}
+	}
+	if idx < 0 {
+		idx = len(c.alphas)
+		c.alphas = append(c.alphas, alpha)
+	}
+	fmt.Fprintf(&c.buf, "/%s gs\n", alphaName(idx))
+}
+
+// path writes path construction operators.
+func (c *content) path(shape canvas.Path) {
+	for _, seg := range shape {
+		switch seg.Op {
+		case canvas.OpMoveTo:
+			fmt.Fprintf(&c.buf, "%s %s m\n", num(seg.Pts[0].X), num(seg.Pts[0].Y))
+		case canvas.OpLineTo:
+			fmt.Fprintf(&c.buf, "%s %s l\n", num(seg.Pts[0].X), num(seg.Pts[0].Y))
+		case canvas.OpCubicTo:
+			fmt.Fprintf(&c.buf, "%s %s %s %s %s %s c\n",
+				num(seg.Pts[0].X), num(seg.Pts[0].Y),
+				num(seg.Pts[1].X), num(seg.Pts[1].Y),
+				num(seg.Pts[2].X), num(seg.Pts[2].Y))
+		case canvas.OpClose:
+			c.buf.WriteString("h\n")
+		}
+	}
+}
+
+// alphaName is the resource name of the idx-th alpha graphics state.
+func alphaName(idx int) string {
+	return "GA" + strconv.Itoa(idx+1)
+}
+
+// channel formats an 8-bit color channel as a PDF color component.
+func channel(value uint8) string {
+	return num(float64(value) / channelMax)
+}
+
+// capStyle maps an SVG line cap to the PDF J operand.
+func capStyle(lineCap string) int {
+	switch lineCap {
+	case "round":
+		return 1
+	case "square":
+		return 2 //nolint:mnd // PDF projecting square cap.
+	default:
+		return 0
+	}
+}
+
+// joinStyle maps an SVG line join to the PDF j operand.
+func joinStyle(join string) int {
+	switch join {
+	case "round":
+		return 1
+	case "bevel":
+		return 2 //nolint:mnd // PDF bevel join.
+	default:
+		return 0
+	}
+}
+
+// num formats a number with at most three decimals, as PDF readers do not
+// accept exponents.
+func num(value float64) string {
+	out := strconv.FormatFloat(value, 'f', 3, 64) //nolint:mnd // thousandth-point precision.
+	out = strings.TrimSuffix(strings.TrimRight(out, "0"), ".")
+	if out == "-0" {
+		return "0"
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+)
+
+func TestContentFillAndStroke(t *testing.T) {
+	var shape canvas.Path
+	shape.MoveTo(canvas.Point{X: 1, Y: 2})
+	shape.LineTo(canvas.Point{X: 3.5, Y: 2})
+	shape.CubicTo(canvas.Point{X: 4, Y: 3}, canvas.Point{X: 5, Y: 4}, canvas.Point{X: 6, Y: 5})
+	shape.Close()
+
+	out := newContent()
+	out.Fill(shape, color.NRGBA{255, 0, 0, 255})
+	out.Stroke(shape, canvas.Stroke{Width: 1.5, Dash: []float64{3, 1}, Cap: "round", Join: "bevel"}, color.NRGBA{0, 0, 255, 128})
+	got := out.buf.String()
+	for _, want := range []string{
+		"1 0 0 rg\n1 2 m\n3.5 2 l\n4 3 5 4 6 5 c\nh\nf\n",
+		"/GA1 gs\n0 0 1 RG\n1.5 w 1 J 2 j\n[3 1] 0 d\n",
+		"S\nQ\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("content lacks %q:\n%s", want, got)
+		}
+	}
+	if len(out.alphas) != 1 || out.alphas[0] != 128 {
+		t.Errorf("alphas = %v, want [128]", out.alphas)
+	}
+}
+
+func TestNum(t *testing.T) {
+	for value, want := range map[float64]string{
+		0: "0", 1: "1", 1.5: "1.5", -0.0001: "0", 2.3456: "2.346", 1e-7: "0", 123456789: "123456789",
+	} {
+		if got := num(value); got != want {
+			t.Errorf("num(%g) = %q, want %q", value, got, want)
+		}
+	}
+}
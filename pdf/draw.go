@@ -0,0 +1,302 @@
+package pdf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+)
+
+// Drawing defaults, matching the SVG initial values.
+const (
+	defaultStrokeWidth float32 = 1
+	defaultFontSize    float32 = 16
+)
+
+// shapeStyle is how a shape is painted. Colours are CSS colour values as
+// the theme and diagram styles give them; an empty colour or "none" is
+// not painted.
+type shapeStyle struct {
+	fill   string
+	stroke string
+	// width is the stroke width. Zero means 1.
+	width float32
+	// dash is a dash array such as "5,5".
+	dash string
+	// round selects round line caps and joins instead of butt and miter.
+	round bool
+	// opacity scales both paints, fillOpacity and strokeOpacity one
+	// each. Zero means 1.
+	opacity       float32
+	fillOpacity   float32
+	strokeOpacity float32
+}
+
+// textStyle is how a line of text is set.
+type textStyle struct {
+	size  float32
+	color string
+	// anchor is start, middle or end. Empty means start.
+	anchor string
+	// baseline is a dominant-baseline value. Empty means alphabetic.
+	baseline string
+	bold     bool
+	italic   bool
+	// opacity scales the text colour. Zero means 1.
+	opacity float32
+	// rotate turns the text about its anchor, in degrees clockwise.
+	rotate float32
+}
+
+// painter draws diagram elements, given in diagram pixels, onto a page
+// content stream.
+type painter struct {
+	out    *content
+	device canvas.Matrix
+	text   *canvas.Typesetter
+	family string
+	// links collects the page's link regions, shared by translated
+	// painters.
+	links *[]link
+}
+
+// newPainter returns a painter that maps diagram pixels to page space
+// with device and sets text in the theme's font family.
+func newPainter(out *content, device canvas.Matrix, family string) *painter {
+	return &painter{
+		out:    out,
+		device: device,
+		text:   canvas.NewTypesetter(canvas.Options{Embeddable: true}),
+		family: family,
+		links:  new([]link),
+	}
+}
+
+// translate returns a painter that draws offset by (dx, dy).
+func (p *painter) translate(dx, dy float32) *painter {
+	moved := *p
+	moved.device = p.device.Multiply(canvas.Translate(float64(dx), float64(dy)))
+	return &moved
+}
+
+// shape fills and strokes a path given in diagram space.
+func (p *painter) shape(shape canvas.Path, style shapeStyle) {
+	if len(shape) == 0 {
+		return
+	}
+	device := shape.Transform(p.device)
+	if fill, ok := canvas.ParseColor(style.fill); ok {
+		fill.A = alpha(fill.A, style.opacity, style.fillOpacity)
+		if fill.A > 0 {
+			p.out.Fill(device, fill)
+		}
+	}
+	stroke, ok := canvas.ParseColor(style.stroke)
+	if !ok {
+		return
+	}
+	stroke.A = alpha(stroke.A, style.opacity, style.strokeOpacity)
+	if stroke.A == 0 {
+		return
+	}
+	width := style.width
+	if width == 0 {
+		width = defaultStrokeWidth
+	}
+	factor := p.device.ScaleFactor()
+	outline := canvas.Stroke{Width: float64(width) * factor, Cap: "butt", Join: "miter"}
+	if style.round {
+		outline.Cap, outline.Join = "round", "round"
+	}
+	for _, length := range dashLengths(style.dash) {
+		outline.Dash = append(outline.Dash, length*factor)
+	}
+	p.out.Stroke(device, outline, stroke)
+}
+
+// rect draws a rectangle with corner radius, which may be zero.
+func (p *painter) rect(posX, posY, width, height, radius float32, style shapeStyle) {
+	p.shape(canvas.RectPath(float64(posX), float64(posY), float64(width), float64(height),
+		float64(radius), float64(radius)), style)
+}
+
+// circle draws a circle.
+func (p *painter) circle(cx, cy, radius float32, style shapeStyle) {
+	p.ellipse(cx, cy, radius, radius, style)
+}
+
+// ellipse draws an axis-aligned ellipse.
+func (p *painter) ellipse(cx, cy, rx, ry float32, style shapeStyle) {
+	p.shape(canvas.EllipsePath(pt(cx, cy), float64(rx), float64(ry)), style)
+}
+
+// line strokes a straight line. Lines have no interior, so the style's
+// fill is ignored.
+func (p *painter) line(x1, y1, x2, y2 float32, style shapeStyle) {
+	style.fill = ""
+	var shape canvas.Path
+	shape.MoveTo(pt(x1, y1))
+	shape.LineTo(pt(x2, y2))
+	p.shape(shape, style)
+}
+
+// polyline draws an open path through points.
+func (p *painter) polyline(points [][2]float32, style shapeStyle) {
+	p.shape(polylinePath(points, false), style)
+}
+
+// polygon draws a closed path through points.
+func (p *painter) polygon(points [][2]float32, style shapeStyle) {
+	p.shape(polylinePath(points, true), style)
+}
+
+// label draws one line of text anchored at (x, y).
+func (p *painter) label(posX, posY float32, text string, style textStyle) {
+	text = strings.Join(strings.Fields(text), " ")
+	fill, ok := canvas.ParseColor(style.color)
+	if text == "" || !ok {
+		return
+	}
+	fill.A = alpha(fill.A, style.opacity, 0)
+	size := style.size
+	if size <= 0 {
+		size = defaultFontSize
+	}
+	anchor := style.anchor
+	if anchor == "" {
+		anchor = "start"
+	}
+	glyphs, offset := p.text.Layout(text, canvas.Font{
+		Family:   p.family,
+		Size:     float64(size),
+		Bold:     style.bold,
+		Italic:   style.italic,
+		Anchor:   anchor,
+		Baseline: style.baseline,
+	})
+	if len(glyphs) == 0 || fill.A == 0 {
+		return
+	}
+	place := p.device.Multiply(canvas.Translate(float64(posX), float64(posY)))
+	if style.rotate != 0 {
+		place = place.Multiply(canvas.Rotation(float64(style.rotate) * math.Pi / 180)) //nolint:mnd // degrees to radians.
+	}
+	p.out.Text(canvas.TextRun{
+		Glyphs:    glyphs,
+		Size:      float64(size),
+		Transform: place.Multiply(canvas.Translate(offset.X, offset.Y)),
+		Paint:     fill,
+	})
+}
+
+// icon draws a registered icon fitted in a size x size box centred on
+// (cx, cy). Icons painting with currentColor take color. It reports
+// false, drawing nothing, when the configured registry does not resolve
+// ref.
+//
+// Icon bodies are SVG markup from the registered icon sets, so they are
+// the one part of a page drawn through the canvas SVG interpreter.
+func (p *painter) icon(cfg *config.Layout, ref string, cx, cy, size float32, color string) bool {
+	if cfg == nil {
+		return false
+	}
+	icon, ok := cfg.Icons.Lookup(ref)
+	if !ok {
+		return false
+	}
+	doc, err := canvas.Parse(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="` + icon.ViewBox() +
+		`" width="` + fmtNum(size) + `" height="` + fmtNum(size) + `" color="` + color + `">` +
+		icon.Body + `</svg>`)
+	if err != nil {
+		return false
+	}
+	place := p.device.Multiply(canvas.Translate(float64(cx-size/2), float64(cy-size/2))) //nolint:mnd // centred on (cx, cy).
+	doc.Draw(p.out, place, canvas.Options{Embeddable: true})
+	return true
+}
+
+// link makes a diagram-space box a link to url. Boxes without a URL are
+// ignored.
+func (p *painter) link(posX, posY, width, height float32, url, title string) {
+	if url == "" {
+		return
+	}
+	corner1 := p.device.Apply(pt(posX, posY))
+	corner2 := p.device.Apply(pt(posX+width, posY+height))
+	*p.links = append(*p.links, link{
+		minX:  math.Min(corner1.X, corner2.X),
+		minY:  math.Min(corner1.Y, corner2.Y),
+		maxX:  math.Max(corner1.X, corner2.X),
+		maxY:  math.Max(corner1.Y, corner2.Y),
+		url:   url,
+		title: title,
+	})
+}
+
+// alpha applies opacities, where zero means 1, to a colour's alpha.
+func alpha(level uint8, opacities ...float32) uint8 {
+	scale := 1.0
+	for _, opacity := range opacities {
+		if opacity > 0 {
+			scale *= math.Min(float64(opacity), 1)
+		}
+	}
+	return uint8(math.Round(float64(level) * scale)) //nolint:gosec // scale is within [0, 1].
+}
+
+// dashLengths parses a comma or space separated dash array. It returns
+// nil for "none" and for arrays with no positive length.
+func dashLengths(value string) []float64 {
+	var lengths []float64
+	total := 0.0
+	for _, field := range strings.FieldsFunc(value, func(char rune) bool { return char == ',' || char == ' ' }) {
+		length, err := strconv.ParseFloat(field, 64)
+		if err != nil || length < 0 {
+			return nil
+		}
+		lengths = append(lengths, length)
+		total += length
+	}
+	if total == 0 {
+		return nil
+	}
+	return lengths
+}
+
+// polylinePath returns the path through points, closed for polygons.
+func polylinePath(points [][2]float32, closed bool) canvas.Path {
+	var out canvas.Path
+	for idx, point := range points {
+		if idx == 0 {
+			out.MoveTo(pt(point[0], point[1]))
+		} else {
+			out.LineTo(pt(point[0], point[1]))
+		}
+	}
+	if closed && len(out) > 0 {
+		out.Close()
+	}
+	return out
+}
+
+// pt converts diagram coordinates to a canvas point.
+func pt(posX, posY float32) canvas.Point {
+	return canvas.Point{X: float64(posX), Y: float64(posY)}
+}
+
+// fmtNum formats a diagram length for the icon wrapper document.
+func fmtNum(value float32) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
+
+// firstLine returns the first line of a text block, or "" when it has
+// none.
+func firstLine(block layout.TextBlock) string {
+	if len(block.Lines) == 0 {
+		return ""
+	}
+	return block.Lines[0]
+}
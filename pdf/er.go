@@ -0,0 +1,102 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// ER diagram drawing constants, matching the SVG renderer.
+const (
+	erBorderRadius   float32 = 4
+	erTextBaseline   float32 = 0.35
+	erAttrFontScale  float32 = 0.85
+	erStripeOpacity  float32 = 0.5
+	erRowLineWidth   float32 = 0.5
+	erRowLineOpacity float32 = 0.4
+	erStripeInset    float32 = 1
+)
+
+// drawER draws relationship lines with their labels, then entity boxes.
+// Relationships are plain lines; crow's foot decorations are not drawn.
+func drawER(pen *painter, computed *layout.Layout, data layout.ERData, th *theme.Theme, cfg *config.Layout) {
+	for _, edge := range computed.Edges {
+		if len(edge.Points) < 2 {
+			continue
+		}
+		pen.polyline(edge.Points, shapeStyle{stroke: th.LineColor, width: edgeWidth, round: true})
+		if edge.Label != nil && len(edge.Label.Lines) > 0 {
+			drawEdgeLabel(pen, edge, th, th.LabelTextColor)
+		}
+	}
+
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		entity := data.Entities[id]
+		if entity == nil {
+			drawNodeShape(pen, node, th.PrimaryColor, th.PrimaryBorderColor, th.PrimaryTextColor)
+			continue
+		}
+		drawEntity(pen, node, entity, data.EntityDims[id], th, cfg)
+	}
+}
+
+// drawEntity draws an entity as a table with a header and a row per
+// attribute: keys, type and name.
+func drawEntity(pen *painter, node *layout.NodeLayout, entity *ir.Entity, dims layout.EntityDimensions, th *theme.Theme, cfg *config.Layout) {
+	posX := node.X - node.Width/2
+	posY := node.Y - node.Height/2
+	width := node.Width
+
+	rowH := cfg.ER.AttributeRowHeight
+	if rowH <= 0 {
+		rowH = th.FontSize * cfg.LabelLineHeight
+	}
+	colPad := cfg.ER.ColumnPadding
+	if colPad <= 0 {
+		colPad = cfg.Padding.NodeHorizontal
+	}
+
+	border := shapeStyle{stroke: th.EntityBorder}
+	body := border
+	body.fill = th.EntityBodyBg
+	pen.rect(posX, posY, width, node.Height, erBorderRadius, body)
+	header := border
+	header.fill = th.EntityHeaderBg
+	headerH := dims.HeaderHeight
+	pen.rect(posX, posY, width, headerH, 0, header)
+	pen.label(posX+width/2, posY+headerH/2+th.FontSize*erTextBaseline, entity.DisplayName(), textStyle{
+		size:   th.FontSize,
+		color:  th.PrimaryTextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+	pen.line(posX, posY+headerH, posX+width, posY+headerH, border)
+
+	attrSize := th.FontSize * erAttrFontScale
+	for attrIdx, attr := range entity.Attributes {
+		rowY := posY + headerH + float32(attrIdx)*rowH
+		if attrIdx%2 == 1 {
+			pen.rect(posX+erStripeInset, rowY, width-2*erStripeInset, rowH, 0,
+				shapeStyle{fill: th.EntityBodyBg, opacity: erStripeOpacity})
+		}
+		if attrIdx > 0 {
+			pen.line(posX, rowY, posX+width, rowY,
+				shapeStyle{stroke: th.EntityBorder, width: erRowLineWidth, opacity: erRowLineOpacity})
+		}
+
+		textY := rowY + rowH/2 + th.FontSize*erTextBaseline
+		keyX := posX + colPad
+		keys := make([]string, 0, len(attr.Keys))
+		for _, key := range attr.Keys {
+			keys = append(keys, key.String())
+		}
+		pen.label(keyX, textY, strings.Join(keys, ","), textStyle{size: attrSize, color: th.TextColor, bold: true})
+		typeX := keyX + dims.KeyColWidth + colPad
+		pen.label(typeX, textY, attr.Type, textStyle{size: attrSize, color: th.TextColor, italic: true})
+		pen.label(typeX+dims.TypeColWidth+colPad, textY, attr.Name, textStyle{size: attrSize, color: th.TextColor})
+	}
+}
@@ -0,0 +1,153 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+)
+
+// Font constants.
+const (
+	// glyphUnits is the size of the PDF glyph space em.
+	glyphUnits = 1000.0
+	// fixedScale converts fixed.Int26_6 values to floats.
+	fixedScale = 64.0
+	// symbolicFlag marks a font whose glyphs are outside the standard
+	// Latin character set, which is required for Identity-H encodings.
+	symbolicFlag = 1 << 2
+	// italicFlag marks an italic font.
+	italicFlag = 1 << 6
+	// italicAngle is the slant of the Go italic fonts, in degrees.
+	italicAngle = -12
+	// stemV is a typical vertical stem width, which PDF requires but
+	// viewers only use when substituting fonts.
+	stemV = 80
+	// bfcharLimit is the most entries one beginbfchar block may hold.
+	bfcharLimit = 100
+)
+
+// font is a TrueType font embedded as a CID-keyed Type0 font whose CIDs
+// are glyph indices.
+type font struct {
+	name   string // resource name, such as F1
+	face   *canvas.Face
+	buf    sfnt.Buffer
+	widths map[sfnt.GlyphIndex]float64 // glyph advances in glyph units
+	runes  map[sfnt.GlyphIndex]rune    // text each glyph stands for
+}
+
+// newFont returns a font resource for a face.
+func newFont(face *canvas.Face, name string) *font {
+	return &font{
+		name:   name,
+		face:   face,
+		widths: make(map[sfnt.GlyphIndex]float64),
+		runes:  make(map[sfnt.GlyphIndex]rune),
+	}
+}
+
+// use records that a glyph is shown and returns its advance in glyph
+// units.
+func (f *font) use(index sfnt.GlyphIndex, ch rune) float64 {
+	if width, ok := f.widths[index]; ok {
+		return width
+	}
+	advance, err := f.face.Font.GlyphAdvance(&f.buf, index, fixed.I(glyphUnits), xfont.HintingNone)
+	width := 0.0
+	if err == nil {
+		width = float64(advance) / fixedScale
+	}
+	f.widths[index] = width
+	f.runes[index] = ch
+	return width
+}
+
+// glyphs returns the used glyph indices in ascending order.
+func (f *font) glyphs() []sfnt.GlyphIndex {
+	indices := make([]sfnt.GlyphIndex, 0, len(f.widths))
+	for index := range f.widths {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(left, right int) bool { return indices[left] < indices[right] })
+	return indices
+}
+
+// baseFont is the PostScript name of the font.
+func (f *font) baseFont() string {
+	return strings.Map(func(ch rune) rune {
+		if ch <= ' ' || ch > '~' || strings.ContainsRune("()<>[]{}/%#", ch) {
+			return -1
+		}
+		return ch
+	}, f.face.Name)
+}
+
+// widthArray returns the CIDFont W array for the used glyphs.
+func (f *font) widthArray() string {
+	var out strings.Builder
+	out.WriteByte('[')
+	for _, index := range f.glyphs() {
+		fmt.Fprintf(&out, "%d [%s] ", index, num(f.widths[index]))
+	}
+	return strings.TrimSpace(out.String()) + "]"
+}
+
+// toUnicode returns a CMap that maps glyph indices back to text, so PDF
+// viewers can search and copy it.
+func (f *font) toUnicode() string {
+	var out strings.Builder
+	out.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	indices := f.glyphs()
+	for start := 0; start < len(indices); start += bfcharLimit {
+		block := indices[start:min(start+bfcharLimit, len(indices))]
+		fmt.Fprintf(&out, "%d beginbfchar\n", len(block))
+		for _, index := range block {
+			fmt.Fprintf(&out, "<%04X> <", uint16(index))
+			for _, unit := range utf16.Encode([]rune{f.runes[index]}) {
+				fmt.Fprintf(&out, "%04X", unit)
+			}
+			out.WriteString(">\n")
+		}
+		out.WriteString("endbfchar\n")
+	}
+	out.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return out.String()
+}
+
+// descriptor returns the FontDescriptor entries other than the font file.
+func (f *font) descriptor() string {
+	ppem := fixed.I(glyphUnits)
+	var ascent, descent, capHeight float64
+	if metrics, err := f.face.Font.Metrics(&f.buf, ppem, xfont.HintingNone); err == nil {
+		ascent = float64(metrics.Ascent) / fixedScale
+		descent = float64(metrics.Descent) / fixedScale
+		capHeight = float64(metrics.CapHeight) / fixedScale
+	}
+	// sfnt bounds have the Y axis down; PDF bounding boxes have it up.
+	var bbox [4]float64
+	if bounds, err := f.face.Font.Bounds(&f.buf, ppem, xfont.HintingNone); err == nil {
+		bbox = [4]float64{
+			float64(bounds.Min.X) / fixedScale, -float64(bounds.Max.Y) / fixedScale,
+			float64(bounds.Max.X) / fixedScale, -float64(bounds.Min.Y) / fixedScale,
+		}
+	}
+	flags, angle := symbolicFlag, 0
+	if f.face.Italic {
+		flags |= italicFlag
+		angle = italicAngle
+	}
+	return fmt.Sprintf("/Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%s %s %s %s] "+
+		"/ItalicAngle %d /Ascent %s /Descent %s /CapHeight %s /StemV %d",
+		f.baseFont(), flags, num(bbox[0]), num(bbox[1]), num(bbox[2]), num(bbox[3]),
+		angle, num(ascent), num(-descent), num(capHeight), stemV)
+}
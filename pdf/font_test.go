@@ -0,0 +1,33 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+)
+
+func TestFontResources(t *testing.T) {
+	doc, err := canvas.Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="20"><text x="0" y="10">Aé</text></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := newContent()
+	doc.Draw(out, canvas.Identity(), canvas.Options{Embeddable: true})
+	if len(out.fonts) != 1 {
+		t.Fatalf("fonts = %d, want 1", len(out.fonts))
+	}
+	fnt := out.fonts[0]
+	if fnt.baseFont() != "GoRegular" {
+		t.Errorf("baseFont = %q", fnt.baseFont())
+	}
+	if widths := fnt.widthArray(); strings.Count(widths, "[") != 3 {
+		t.Errorf("W array = %s, want two glyph entries", widths)
+	}
+	if cmap := fnt.toUnicode(); !strings.Contains(cmap, "2 beginbfchar") || !strings.Contains(cmap, "<00E9>") {
+		t.Errorf("ToUnicode lacks é:\n%s", cmap)
+	}
+	if desc := fnt.descriptor(); !strings.Contains(desc, "/FontName /GoRegular") || !strings.Contains(desc, "/Ascent ") {
+		t.Errorf("descriptor = %s", desc)
+	}
+}
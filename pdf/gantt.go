@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Gantt chart drawing constants, matching the SVG renderer.
+const (
+	ganttTitlePadding    float32 = 5
+	ganttTitleGrow       float32 = 2
+	ganttSectionLabelGap float32 = 5
+	ganttSectionShrink   float32 = 2
+	ganttGridWidth       float32 = 0.5
+	ganttAxisLabelOffset float32 = 12
+	ganttAxisFontSize    float32 = 9
+	ganttTaskRadius      float32 = 2
+	ganttTaskLabelGap    float32 = 4
+	ganttTaskLabelShift  float32 = 1
+	ganttTaskShrink      float32 = 3
+	ganttTopAxisOffset   float32 = 6
+	ganttMarkerLabelGap  float32 = 4
+	ganttMarkerWidth     float32 = 2
+	ganttTodayDash               = "4,4"
+)
+
+// drawGantt draws the title, section bands, grid and axis labels, task
+// bars with their labels, vert markers and the today marker. Tasks that
+// link to a URL become links; callbacks cannot run in a page and are left
+// out.
+func drawGantt(pen *painter, computed *layout.Layout, data layout.GanttData, th *theme.Theme) {
+	pen.label(computed.Width/2, th.FontSize+ganttTitlePadding, data.Title, textStyle{
+		size:   th.FontSize + ganttTitleGrow,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	for _, sec := range data.Sections {
+		pen.rect(data.ChartX, sec.Y, data.ChartWidth, sec.Height, 0, shapeStyle{fill: sec.Color})
+		pen.label(data.ChartX-ganttSectionLabelGap, sec.Y+sec.Height/2, sec.Title, textStyle{
+			size:     th.FontSize - ganttSectionShrink,
+			color:    th.TextColor,
+			anchor:   "end",
+			baseline: "middle",
+		})
+	}
+
+	chartBottom := data.ChartY + data.ChartHeight
+	axis := textStyle{size: ganttAxisFontSize, color: th.TextColor, anchor: "middle"}
+	for _, tick := range data.AxisTicks {
+		pen.line(tick.X, data.ChartY, tick.X, chartBottom, shapeStyle{stroke: th.GanttGridColor, width: ganttGridWidth})
+		pen.label(tick.X, chartBottom+ganttAxisLabelOffset, tick.Label, axis)
+		if data.TopAxis {
+			pen.label(tick.X, data.ChartY-ganttTopAxisOffset, tick.Label, axis)
+		}
+	}
+
+	for _, sec := range data.Sections {
+		for _, task := range sec.Tasks {
+			drawGanttTask(pen, &task, th)
+		}
+	}
+
+	labelY := data.ChartY - ganttMarkerLabelGap
+	if data.TopAxis {
+		labelY -= ganttAxisLabelOffset
+	}
+	for _, marker := range data.Markers {
+		pen.line(marker.X, data.ChartY, marker.X, chartBottom, shapeStyle{stroke: th.GanttMilestoneFill, width: ganttMarkerWidth})
+		pen.label(marker.X, labelY, marker.Label, textStyle{
+			size:   th.FontSize - ganttTaskShrink,
+			color:  th.TextColor,
+			anchor: "middle",
+		})
+	}
+
+	if data.ShowTodayMarker {
+		today := shapeStyle{stroke: th.GanttTodayMarkerColor, width: ganttMarkerWidth, dash: ganttTodayDash}
+		// An inline style overrides the defaults, as in Mermaid.
+		applyStrokeStyle(&today, data.TodayMarkerStyle)
+		pen.line(data.TodayMarkerX, data.ChartY, data.TodayMarkerX, chartBottom, today)
+	}
+}
+
+// drawGanttTask draws a task bar, or a diamond for a milestone, with its
+// label to the right.
+func drawGanttTask(pen *painter, task *layout.GanttTaskLayout, th *theme.Theme) {
+	style := shapeStyle{fill: th.GanttTaskFill, stroke: th.GanttTaskBorder}
+	if task.IsCrit {
+		style.fill, style.stroke = th.GanttCritFill, th.GanttCritBorder
+	}
+	if task.IsDone {
+		style.fill = th.GanttDoneFill
+	}
+	if task.IsActive {
+		style.fill = th.GanttActiveFill
+	}
+
+	left, width := task.X, task.Width
+	if task.IsMilestone {
+		cx, cy := task.X, task.Y+task.Height/2
+		size := task.Height / 2
+		style.fill = th.GanttMilestoneFill
+		pen.polygon([][2]float32{{cx, cy - size}, {cx + size, cy}, {cx, cy + size}, {cx - size, cy}}, style)
+		left, width = cx-size, task.Height
+	} else {
+		pen.rect(task.X, task.Y, task.Width, task.Height, ganttTaskRadius, style)
+	}
+	pen.label(task.X+task.Width+ganttTaskLabelGap, task.Y+task.Height/2+ganttTaskLabelShift, task.Label, textStyle{
+		size:     th.FontSize - ganttTaskShrink,
+		color:    th.TextColor,
+		baseline: "middle",
+	})
+
+	if task.Link != nil {
+		title := ""
+		if task.Link.Title != nil {
+			title = *task.Link.Title
+		}
+		pen.link(left, task.Y, width, task.Height, task.Link.URL, title)
+	}
+}
+
+// applyStrokeStyle applies the stroke declarations of a CSS style list,
+// separated by commas or semicolons, to a line style. Other declarations
+// are ignored.
+func applyStrokeStyle(style *shapeStyle, css string) {
+	for _, decl := range strings.FieldsFunc(css, func(char rune) bool { return char == ',' || char == ';' }) {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "stroke":
+			style.stroke = value
+		case "stroke-width":
+			if width, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32); err == nil {
+				style.width = float32(width)
+			}
+		case "stroke-dasharray":
+			style.dash = value
+		}
+	}
+}
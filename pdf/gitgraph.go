@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Git graph drawing constants, matching the SVG renderer.
+const (
+	gitBranchWidth       float32 = 2
+	gitBranchLabelOffset float32 = 10
+	gitBranchShrink      float32 = 2
+	gitConnectionWidth   float32 = 1.5
+	gitCherryPickDash            = "4,4"
+	gitCommitWidth       float32 = 2
+	gitReverseCrossScale float32 = 0.6
+	gitTagPadding        float32 = 4
+	gitTagHalfWidth      float32 = 20
+	gitTagRectWidth      float32 = 40
+	gitTagRectHeight     float32 = 14
+	gitTagBorderRadius   float32 = 3
+	gitTagOffsetY        float32 = 10
+	gitVerticalTurns     float32 = -90
+)
+
+// drawGitGraph draws branch lanes with their names, merge and cherry-pick
+// connections, then the commits with their tags.
+func drawGitGraph(pen *painter, data layout.GitGraphData, th *theme.Theme, cfg *config.Layout) {
+	commitRadius := cfg.GitGraph.CommitRadius
+	vertical := data.Direction == ir.TopDown || data.Direction == ir.BottomTop
+	branchText := textStyle{size: th.FontSize - gitBranchShrink, color: th.TextColor, baseline: "middle"}
+
+	branchColor := make(map[string]string, len(data.Branches))
+	for _, br := range data.Branches {
+		branchColor[br.Name] = br.Color
+		lane := shapeStyle{stroke: br.Color, width: gitBranchWidth}
+		if vertical {
+			if br.StartY < br.EndY {
+				pen.line(br.X, br.StartY, br.X, br.EndY, lane)
+			}
+			// History runs down in TB, so the name reads up from above the
+			// first commit; in BT it ends below it.
+			text := branchText
+			text.rotate = gitVerticalTurns
+			labelY := br.StartY - gitBranchLabelOffset
+			text.anchor = "start"
+			if data.Direction == ir.BottomTop {
+				labelY, text.anchor = br.EndY+gitBranchLabelOffset, "end"
+			}
+			pen.label(br.X, labelY, br.Name, text)
+			continue
+		}
+		if br.StartX < br.EndX {
+			pen.line(br.StartX, br.Y, br.EndX, br.Y, lane)
+		}
+		text := branchText
+		text.anchor = "end"
+		pen.label(br.StartX-gitBranchLabelOffset, br.Y, br.Name, text)
+	}
+
+	for _, conn := range data.Connections {
+		style := shapeStyle{stroke: th.LineColor, width: gitConnectionWidth}
+		if conn.IsCherryPick {
+			style.dash = gitCherryPickDash
+		}
+		pen.line(conn.FromX, conn.FromY, conn.ToX, conn.ToY, style)
+	}
+
+	for _, commit := range data.Commits {
+		color := branchColor[commit.Branch]
+		if color == "" {
+			color = th.GitCommitFill
+		}
+		switch commit.Type {
+		case ir.GitCommitHighlight:
+			pen.circle(commit.X, commit.Y, commitRadius, shapeStyle{fill: th.GitHighlightFill, stroke: color, width: gitCommitWidth})
+		case ir.GitCommitReverse:
+			// A reverse commit is crossed out.
+			pen.circle(commit.X, commit.Y, commitRadius, shapeStyle{fill: color, stroke: th.GitCommitStroke, width: gitCommitWidth})
+			half := commitRadius * gitReverseCrossScale
+			cross := shapeStyle{stroke: th.Background, width: gitCommitWidth}
+			pen.line(commit.X-half, commit.Y-half, commit.X+half, commit.Y+half, cross)
+			pen.line(commit.X-half, commit.Y+half, commit.X+half, commit.Y-half, cross)
+		default:
+			pen.circle(commit.X, commit.Y, commitRadius, shapeStyle{fill: color, stroke: th.GitCommitStroke, width: gitCommitWidth})
+		}
+
+		// Tags sit above the commit on horizontal lanes and to its left on
+		// vertical ones.
+		if commit.Tag == "" {
+			continue
+		}
+		tagX, tagY := commit.X, commit.Y-commitRadius-gitTagPadding
+		if vertical {
+			tagX = commit.X - commitRadius - gitTagPadding - gitTagHalfWidth
+			tagY = commit.Y + gitTagOffsetY - gitTagRectHeight/2
+		}
+		pen.rect(tagX-gitTagHalfWidth, tagY-gitTagOffsetY, gitTagRectWidth, gitTagRectHeight, gitTagBorderRadius,
+			shapeStyle{fill: th.GitTagFill, stroke: th.GitTagBorder})
+		pen.label(tagX, tagY-1, commit.Tag, textStyle{
+			size:     cfg.GitGraph.TagFontSize,
+			color:    th.TextColor,
+			anchor:   "middle",
+			baseline: "middle",
+		})
+	}
+}
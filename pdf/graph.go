@@ -0,0 +1,180 @@
+package pdf
+
+import (
+	"sort"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Graph drawing constants, matching the SVG renderer.
+const (
+	subgraphBorderRadius   float32 = 4
+	subgraphLabelOffsetX   float32 = 8
+	subgraphLabelOffsetY   float32 = 16
+	subgraphFontScale      float32 = 0.9
+	subgraphDash                   = "5,5"
+	edgeWidth              float32 = 1.5
+	thickEdgeWidth         float32 = 3
+	dottedEdgeDash                 = "5,5"
+	edgeLabelPadX          float32 = 4
+	edgeLabelPadY          float32 = 2
+	edgeLabelRadius        float32 = 2
+	edgeLabelFontScale     float32 = 0.85
+	edgeLabelLineHeight    float32 = 1.2
+	edgeLabelBaselineShift float32 = 0.75
+	iconFormRadius         float32 = 8
+	iconPlaceholderDash            = "4,3"
+)
+
+// drawGraph draws flowchart elements: subgraphs, then edges, then nodes.
+func drawGraph(pen *painter, computed *layout.Layout, th *theme.Theme, cfg *config.Layout) {
+	drawSubgraphs(pen, computed, th)
+	drawEdges(pen, computed, th, func(*layout.EdgeLayout, bool) markerKind { return markerArrow })
+	drawNodes(pen, computed, th, cfg)
+}
+
+// drawSubgraphs draws subgraph containers with their labels at the top
+// left.
+func drawSubgraphs(pen *painter, computed *layout.Layout, th *theme.Theme) {
+	for _, sg := range computed.Subgraphs {
+		pen.rect(sg.X, sg.Y, sg.Width, sg.Height, subgraphBorderRadius, shapeStyle{
+			fill:   th.ClusterBackground,
+			stroke: th.ClusterBorder,
+			dash:   subgraphDash,
+		})
+		pen.label(sg.X+subgraphLabelOffsetX, sg.Y+subgraphLabelOffsetY, sg.Label, textStyle{
+			size:  th.FontSize * subgraphFontScale,
+			color: th.TextColor,
+			bold:  true,
+		})
+	}
+}
+
+// drawEdges draws every edge with the markers its arrows call for, and its
+// label. markers picks the marker for an edge's start or end.
+func drawEdges(pen *painter, computed *layout.Layout, th *theme.Theme, markers func(edge *layout.EdgeLayout, start bool) markerKind) {
+	for _, edge := range computed.Edges {
+		style := shapeStyle{stroke: th.LineColor, width: edgeWidth, round: true}
+		switch edge.Style {
+		case ir.Dotted:
+			style.dash = dottedEdgeDash
+		case ir.Thick:
+			style.width = thickEdgeWidth
+		}
+		start, end := markerNone, markerNone
+		if edge.ArrowStart {
+			start = markers(edge, true)
+		}
+		if edge.ArrowEnd {
+			end = markers(edge, false)
+		}
+		pen.edge(edge.Points, style, start, end, th)
+		if len(edge.Points) >= 2 && edge.Label != nil && len(edge.Label.Lines) > 0 {
+			drawEdgeLabel(pen, edge, th, th.LabelTextColor)
+		}
+	}
+}
+
+// drawEdgeLabel draws an edge's label on a background at its anchor, in
+// textColor.
+func drawEdgeLabel(pen *painter, edge *layout.EdgeLayout, th *theme.Theme, textColor string) {
+	label := edge.Label
+	anchorX, anchorY := edge.LabelAnchor[0], edge.LabelAnchor[1]
+	bgW := label.Width + edgeLabelPadX*2
+	bgH := label.Height + edgeLabelPadY*2
+	pen.rect(anchorX-bgW/2, anchorY-bgH/2, bgW, bgH, edgeLabelRadius, shapeStyle{fill: th.EdgeLabelBackground})
+
+	fontSize := label.FontSize
+	if fontSize <= 0 {
+		fontSize = th.FontSize * edgeLabelFontScale
+	}
+	lineHeight := fontSize * edgeLabelLineHeight
+	startY := anchorY - lineHeight*float32(len(label.Lines))/2 + lineHeight*edgeLabelBaselineShift
+	for idx, line := range label.Lines {
+		pen.label(anchorX, startY+float32(idx)*lineHeight, line, textStyle{
+			size:   fontSize,
+			color:  th.LabelTextColor,
+			anchor: "middle",
+		})
+	}
+}
+
+// drawNodes draws every node in ID order.
+func drawNodes(pen *painter, computed *layout.Layout, th *theme.Theme, cfg *config.Layout) {
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		fill, stroke, textColor := nodeColors(node, th)
+		if node.Icon != nil {
+			drawIconNode(pen, node, fill, stroke, textColor, cfg)
+			continue
+		}
+		drawNodeShape(pen, node, fill, stroke, textColor)
+	}
+}
+
+// drawIconNode draws a flowchart icon node: the icon in its background
+// form, if any, with the label below it, or above it for pos: t. An icon
+// the registry does not resolve without a form shows a dashed placeholder.
+func drawIconNode(pen *painter, node *layout.NodeLayout, fill, stroke, textColor string, cfg *config.Layout) {
+	top := node.Y - node.Height/2
+	bottom := node.Y + node.Height/2
+	boxTop := top
+	if node.Icon.LabelTop {
+		boxTop = bottom - node.IconBox
+	}
+	boxCenterY := boxTop + node.IconBox/2
+	half := node.IconBox / 2
+
+	form := shapeStyle{fill: fill, stroke: stroke}
+	switch node.Icon.Form {
+	case "circle":
+		pen.circle(node.X, boxCenterY, half, form)
+	case "square":
+		pen.rect(node.X-half, boxTop, node.IconBox, node.IconBox, 0, form)
+	case "rounded":
+		pen.rect(node.X-half, boxTop, node.IconBox, node.IconBox, iconFormRadius, form)
+	}
+	if !pen.icon(cfg, node.Icon.Name, node.X, boxCenterY, node.IconSize, textColor) && node.Icon.Form == "" {
+		pen.rect(node.X-node.IconSize/2, boxCenterY-node.IconSize/2, node.IconSize, node.IconSize, 0,
+			shapeStyle{stroke: stroke, dash: iconPlaceholderDash})
+	}
+
+	// Centre the label in the space the icon leaves.
+	label := *node
+	if node.Icon.LabelTop {
+		label.Y = top + node.Label.Height/2
+	} else {
+		label.Y = bottom - node.Label.Height/2
+	}
+	drawNodeLabel(pen, &label, textColor)
+}
+
+// nodeColors returns a node's fill, stroke and text colours: its style
+// overrides where set, otherwise the theme's primary colours.
+func nodeColors(node *layout.NodeLayout, th *theme.Theme) (string, string, string) {
+	fill, stroke, textColor := th.PrimaryColor, th.PrimaryBorderColor, th.PrimaryTextColor
+	if node.Style.Fill != nil {
+		fill = *node.Style.Fill
+	}
+	if node.Style.Stroke != nil {
+		stroke = *node.Style.Stroke
+	}
+	if node.Style.TextColor != nil {
+		textColor = *node.Style.TextColor
+	}
+	return fill, stroke, textColor
+}
+
+// sortedNodeIDs returns the layout's node IDs in order, so pages draw
+// deterministically.
+func sortedNodeIDs(computed *layout.Layout) []string {
+	ids := make([]string, 0, len(computed.Nodes))
+	for id := range computed.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Journey diagram drawing constants, matching the SVG renderer.
+const (
+	journeyScoreMaxIdx        int     = 4
+	journeyScoreRange         float32 = 4.0
+	journeyScoreLabelGap      float32 = 15
+	journeySectionRadius      float32 = 4
+	journeySectionLabelGap    float32 = 5
+	journeyTaskRadius         float32 = 6
+	journeyIndicatorOffset    float32 = 10
+	journeyIndicatorRadius    float32 = 5
+	journeyTaskTextOffset     float32 = 5
+	journeyLegendGap          float32 = 20
+	journeyLegendDotOffset    float32 = 5
+	journeyLegendDotR         float32 = 4
+	journeyLegendTextOff      float32 = 15
+	journeyLegendStep         float32 = 80
+	journeyTextBaselineOff    float32 = 4
+	journeyTitleGrowth        float32 = 2
+	journeyTaskFontShrink     float32 = 2
+	journeyScoreFontSize      float32 = 10
+	journeyLegendFontSize     float32 = 11
+	journeyGuideColor                 = "#ddd"
+	journeyGuideDash                  = "4,4"
+	journeyScoreTextColor             = "#999"
+	journeySectionFallback            = "#f5f5f5"
+	journeyActorFallbackColor         = "#666"
+)
+
+// drawJourney draws the title, score guidelines, sections with their
+// tasks and the actor legend.
+func drawJourney(pen *painter, computed *layout.Layout, data layout.JourneyData, th *theme.Theme, cfg *config.Layout) {
+	pen.label(computed.Width/2, cfg.Journey.PaddingY, data.Title, textStyle{
+		size:   th.FontSize + journeyTitleGrowth,
+		color:  th.JourneyTaskText,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	// Dashed score guidelines, 1 at the bottom to 5 at the top.
+	for score := 1; score <= journeyScoreMaxIdx+1; score++ {
+		scoreRatio := float32(score-1) / journeyScoreRange
+		posY := data.TrackY + data.TrackH*(1-scoreRatio)
+		pen.line(cfg.Journey.PaddingX, posY, computed.Width-cfg.Journey.PaddingX, posY,
+			shapeStyle{stroke: journeyGuideColor, dash: journeyGuideDash})
+		pen.label(cfg.Journey.PaddingX-journeyScoreLabelGap, posY+journeyTextBaselineOff, strconv.Itoa(score),
+			textStyle{size: journeyScoreFontSize, color: journeyScoreTextColor, anchor: "middle"})
+	}
+
+	taskText := textStyle{size: th.FontSize - journeyTaskFontShrink, color: th.JourneyTaskText, anchor: "middle"}
+	for _, sec := range data.Sections {
+		fill := sec.Color
+		if fill == "" {
+			fill = journeySectionFallback
+		}
+		pen.rect(sec.X, sec.Y, sec.Width, sec.Height, journeySectionRadius, shapeStyle{fill: fill})
+		pen.label(sec.X+sec.Width/2, sec.Y-journeySectionLabelGap, sec.Label, taskText)
+
+		for _, task := range sec.Tasks {
+			scoreColor := th.JourneyScoreColors[min(max(task.Score-1, 0), journeyScoreMaxIdx)]
+			posX := task.X - task.Width/2
+			posY := task.Y - task.Height/2
+			pen.rect(posX, posY, task.Width, task.Height, journeyTaskRadius,
+				shapeStyle{fill: th.JourneyTaskFill, stroke: th.JourneyTaskBorder})
+			pen.circle(posX+journeyIndicatorOffset, task.Y, journeyIndicatorRadius,
+				shapeStyle{fill: scoreColor, stroke: scoreColor})
+			label := taskText
+			label.baseline = "middle"
+			pen.label(task.X+journeyTaskTextOffset, task.Y+journeyTextBaselineOff, task.Label, label)
+		}
+	}
+
+	legendY := data.TrackY + data.TrackH + journeyLegendGap
+	legendX := cfg.Journey.PaddingX
+	for _, actor := range data.Actors {
+		color := journeyActorFallbackColor
+		if len(th.JourneySectionColors) > 0 {
+			color = th.JourneySectionColors[actor.ColorIndex%len(th.JourneySectionColors)]
+		}
+		pen.circle(legendX+journeyLegendDotOffset, legendY, journeyLegendDotR, shapeStyle{fill: color, stroke: color})
+		pen.label(legendX+journeyLegendTextOff, legendY+journeyTextBaselineOff, actor.Name,
+			textStyle{size: journeyLegendFontSize, color: th.JourneyTaskText})
+		legendX += journeyLegendStep
+	}
+}
@@ -0,0 +1,43 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Kanban drawing constants, matching the SVG renderer.
+const (
+	kanbanColumnRadius float32 = 4
+	kanbanCardRadius   float32 = 3
+	// kanbanBaselineDiv centres a line of text on a point: the baseline
+	// sits a third of the font size below it.
+	kanbanBaselineDiv float32 = 3
+)
+
+// drawKanban draws each column with its header and cards.
+func drawKanban(pen *painter, data layout.KanbanData, th *theme.Theme, cfg *config.Layout) {
+	border := shapeStyle{stroke: th.NodeBorderColor}
+	for _, col := range data.Columns {
+		column := border
+		column.fill = th.ClusterBackground
+		pen.rect(col.X, col.Y, col.Width, col.Height, kanbanColumnRadius, column)
+		pen.label(col.X+col.Width/2, col.Y+cfg.Kanban.HeaderHeight/2+col.Label.FontSize/kanbanBaselineDiv,
+			firstLine(col.Label), textStyle{
+				size:   col.Label.FontSize,
+				color:  th.PrimaryTextColor,
+				anchor: "middle",
+				bold:   true,
+			})
+		divY := col.Y + cfg.Kanban.HeaderHeight
+		pen.line(col.X, divY, col.X+col.Width, divY, border)
+
+		for _, card := range col.Cards {
+			box := border
+			box.fill = th.Background
+			pen.rect(card.X, card.Y, card.Width, card.Height, kanbanCardRadius, box)
+			pen.label(card.X+cfg.Kanban.Padding, card.Y+card.Height/2+card.Label.FontSize/kanbanBaselineDiv,
+				firstLine(card.Label), textStyle{size: card.Label.FontSize, color: th.PrimaryTextColor})
+		}
+	}
+}
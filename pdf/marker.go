@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// markerKind selects the shape drawn at the end of an edge.
+type markerKind int
+
+// Edge end markers, matching the marker definitions of the SVG renderer.
+const (
+	markerNone markerKind = iota
+	// markerArrow is a filled arrowhead.
+	markerArrow
+	// markerClosedTriangle is a hollow triangle, for inheritance.
+	markerClosedTriangle
+	// markerFilledDiamond is a filled diamond, for composition.
+	markerFilledDiamond
+	// markerOpenDiamond is a hollow diamond, for aggregation.
+	markerOpenDiamond
+	// markerOpenArrow is an unfilled chevron, for async messages.
+	markerOpenArrow
+	// markerCross is an X, for termination messages.
+	markerCross
+)
+
+// markerShape is a marker's geometry in its own units: the tip points
+// along +X and ref is the point placed on the edge's end.
+type markerShape struct {
+	outline canvas.Path
+	ref     canvas.Point
+	// scale converts marker units to diagram pixels.
+	scale float64
+	// width is the outline's stroke width in marker units.
+	width float32
+	// filled fills the outline; hollow markers fill with the background
+	// and open markers not at all.
+	filled, hollow bool
+}
+
+// markerShapeFor returns the geometry of a marker kind.
+//
+//nolint:mnd // marker geometry in marker units.
+func markerShapeFor(kind markerKind) markerShape {
+	var outline canvas.Path
+	switch kind {
+	case markerClosedTriangle:
+		outline = polylinePath([][2]float32{{0, 0}, {20, 10}, {0, 20}}, true)
+		return markerShape{outline: outline, ref: canvas.Point{X: 18, Y: 10}, scale: 0.6, width: 1, hollow: true}
+	case markerFilledDiamond, markerOpenDiamond:
+		outline = polylinePath([][2]float32{{0, 10}, {10, 0}, {20, 10}, {10, 20}}, true)
+		return markerShape{
+			outline: outline, ref: canvas.Point{X: 18, Y: 10}, scale: 0.6, width: 1,
+			filled: kind == markerFilledDiamond, hollow: kind == markerOpenDiamond,
+		}
+	case markerOpenArrow:
+		outline = polylinePath([][2]float32{{0, 0}, {10, 5}, {0, 10}}, false)
+		return markerShape{outline: outline, ref: canvas.Point{X: 9, Y: 5}, scale: 0.8, width: 1.5}
+	case markerCross:
+		outline.MoveTo(canvas.Point{X: 2, Y: 2})
+		outline.LineTo(canvas.Point{X: 8, Y: 8})
+		outline.MoveTo(canvas.Point{X: 8, Y: 2})
+		outline.LineTo(canvas.Point{X: 2, Y: 8})
+		return markerShape{outline: outline, ref: canvas.Point{X: 9, Y: 5}, scale: 1, width: 1.5}
+	default:
+		outline = polylinePath([][2]float32{{0, 0}, {10, 5}, {0, 10}}, true)
+		return markerShape{outline: outline, ref: canvas.Point{X: 9, Y: 5}, scale: 0.8, width: 1, filled: true}
+	}
+}
+
+// classMarker returns the marker for a class diagram arrowhead kind.
+func classMarker(kind *ir.EdgeArrowhead) markerKind {
+	if kind == nil {
+		return markerArrow
+	}
+	switch *kind {
+	case ir.ClosedTriangle:
+		return markerClosedTriangle
+	case ir.FilledDiamond:
+		return markerFilledDiamond
+	case ir.OpenDiamond:
+		return markerOpenDiamond
+	default:
+		return markerArrow
+	}
+}
+
+// edge strokes the polyline through points and draws markers at its
+// start and end, oriented along the first and last segments.
+func (p *painter) edge(points [][2]float32, style shapeStyle, start, end markerKind, th *theme.Theme) {
+	if len(points) < 2 { //nolint:mnd // an edge needs two points.
+		return
+	}
+	p.polyline(points, style)
+	if start != markerNone {
+		if at, toward, ok := endpoint(points, true); ok {
+			// Start markers point away from the edge.
+			p.marker(start, at, at.Sub(toward), th)
+		}
+	}
+	if end != markerNone {
+		if at, from, ok := endpoint(points, false); ok {
+			p.marker(end, at, at.Sub(from), th)
+		}
+	}
+}
+
+// marker draws a marker whose ref point sits at at, pointing along
+// direction.
+func (p *painter) marker(kind markerKind, at, direction canvas.Point, th *theme.Theme) {
+	shape := markerShapeFor(kind)
+	place := canvas.Translate(at.X, at.Y).
+		Multiply(canvas.Rotation(math.Atan2(direction.Y, direction.X))).
+		Multiply(canvas.Scaling(shape.scale, shape.scale)).
+		Multiply(canvas.Translate(-shape.ref.X, -shape.ref.Y))
+	style := shapeStyle{stroke: th.LineColor, width: shape.width * float32(shape.scale)}
+	switch {
+	case shape.filled:
+		style.fill = th.LineColor
+	case shape.hollow:
+		style.fill = th.Background
+	}
+	p.shape(shape.outline.Transform(place), style)
+}
+
+// endpoint returns the first (or last) point of a polyline and the
+// nearest distinct neighbour that gives its direction.
+func endpoint(points [][2]float32, first bool) (canvas.Point, canvas.Point, bool) {
+	if first {
+		for _, point := range points[1:] {
+			if point != points[0] {
+				return pt(points[0][0], points[0][1]), pt(point[0], point[1]), true
+			}
+		}
+		return canvas.Point{}, canvas.Point{}, false
+	}
+	last := points[len(points)-1]
+	for idx := len(points) - 2; idx >= 0; idx-- {
+		if points[idx] != last {
+			return pt(last[0], last[1]), pt(points[idx][0], points[idx][1]), true
+		}
+	}
+	return canvas.Point{}, canvas.Point{}, false
+}
@@ -0,0 +1,132 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Mindmap drawing constants, matching the SVG renderer.
+const (
+	mindmapRoundedRadius    float32 = 8
+	mindmapDefaultRadius    float32 = 4
+	mindmapHexagonSides     int     = 6
+	mindmapHexAngleDelta    float64 = math.Pi / 6
+	mindmapCloudScale       float32 = 1.1
+	mindmapBorderWidth      float32 = 2
+	mindmapBangBorderWidth  float32 = 4
+	mindmapCloudDash                = "4 2"
+	mindmapConnectionWidth  float32 = 2
+	mindmapConnectionAlpha  float32 = 0.6
+	mindmapLabelBaselineDiv float32 = 3
+	mindmapBangTextColor            = "#FFFFFF"
+)
+
+// mindmapFallbackColors are the branch colours when the theme has none.
+var mindmapFallbackColors = []string{"#4C78A8", "#72B7B2", "#EECA3B", "#F58518"}
+
+// drawMindmap draws the connections, then the nodes on top of them.
+func drawMindmap(pen *painter, data layout.MindmapData, th *theme.Theme, cfg *config.Layout) {
+	if data.Root == nil {
+		return
+	}
+	colors := th.MindmapBranchColors
+	if len(colors) == 0 {
+		colors = mindmapFallbackColors
+	}
+	drawMindmapConnections(pen, data.Root, colors)
+	drawMindmapNode(pen, data.Root, colors, th, cfg)
+}
+
+// drawMindmapConnections draws a curve from each node to its children, in
+// the child's branch colour.
+func drawMindmapConnections(pen *painter, node *layout.MindmapNodeLayout, colors []string) {
+	for _, child := range node.Children {
+		midX := (node.X + child.X) / 2
+		var curve canvas.Path
+		curve.MoveTo(pt(node.X, node.Y))
+		curve.CubicTo(pt(midX, node.Y), pt(midX, child.Y), pt(child.X, child.Y))
+		pen.shape(curve, shapeStyle{
+			stroke:  colors[child.ColorIndex%len(colors)],
+			width:   mindmapConnectionWidth,
+			opacity: mindmapConnectionAlpha,
+		})
+		drawMindmapConnections(pen, child, colors)
+	}
+}
+
+// drawMindmapNode draws a node in its shape with its icon and label, then
+// its children.
+func drawMindmapNode(pen *painter, node *layout.MindmapNodeLayout, colors []string, th *theme.Theme, cfg *config.Layout) {
+	color := colors[node.ColorIndex%len(colors)]
+	// A node's ::: classes override the theme fill and the branch colour.
+	fill := th.MindmapNodeFill
+	if node.Style.Fill != nil {
+		fill = *node.Style.Fill
+	}
+	if node.Style.Stroke != nil {
+		color = *node.Style.Stroke
+	}
+	cx, cy := node.X, node.Y
+	hw, hh := node.Width/2, node.Height/2
+	style := shapeStyle{fill: fill, stroke: color, width: mindmapBorderWidth}
+
+	switch node.Shape {
+	case ir.MindmapSquare:
+		pen.rect(cx-hw, cy-hh, node.Width, node.Height, 0, style)
+	case ir.MindmapRounded:
+		pen.rect(cx-hw, cy-hh, node.Width, node.Height, mindmapRoundedRadius, style)
+	case ir.MindmapCircle:
+		pen.circle(cx, cy, max(hw, hh), style)
+	case ir.MindmapHexagon:
+		points := make([][2]float32, mindmapHexagonSides)
+		for idx := range mindmapHexagonSides {
+			angle := float64(idx)*math.Pi/3 - mindmapHexAngleDelta
+			points[idx] = [2]float32{cx + hw*float32(math.Cos(angle)), cy + hh*float32(math.Sin(angle))}
+		}
+		pen.polygon(points, style)
+	case ir.MindmapBang:
+		if node.Style.Fill == nil {
+			style.fill = color
+		}
+		style.width = mindmapBangBorderWidth
+		pen.circle(cx, cy, max(hw, hh), style)
+	case ir.MindmapCloud:
+		style.dash = mindmapCloudDash
+		pen.ellipse(cx, cy, hw*mindmapCloudScale, hh*mindmapCloudScale, style)
+	default:
+		// The default shape has no border unless a class gives one.
+		style = shapeStyle{fill: fill}
+		if node.Style.Stroke != nil {
+			style.stroke = color
+		}
+		pen.rect(cx-hw, cy-hh, node.Width, node.Height, mindmapDefaultRadius, style)
+	}
+
+	textColor := th.TextColor
+	if node.Shape == ir.MindmapBang {
+		textColor = mindmapBangTextColor
+	}
+	if node.Style.TextColor != nil {
+		textColor = *node.Style.TextColor
+	}
+	// An icon sits above the label, which moves down to make room.
+	labelY := cy
+	if node.IconSize > 0 {
+		pen.icon(cfg, node.Icon, cx, cy-node.IconSize/2, node.IconSize, textColor)
+		labelY += node.IconSize / 2
+	}
+	pen.label(cx, labelY+th.FontSize/mindmapLabelBaselineDiv, node.Label, textStyle{
+		size:   th.FontSize,
+		color:  textColor,
+		anchor: "middle",
+	})
+
+	for _, child := range node.Children {
+		drawMindmapNode(pen, child, colors, th, cfg)
+	}
+}
@@ -0,0 +1,99 @@
+package pdf
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Packet diagram drawing constants, matching the SVG renderer.
+const (
+	packetSmallFontScale  float32 = 0.7
+	packetRulerTick       float32 = 3
+	packetRulerWidth      float32 = 0.5
+	packetRulerMinSpacing float32 = 16
+	packetBitInset        float32 = 2
+	packetBaselineDiv     float32 = 3
+	packetContinuedDash           = "3,3"
+)
+
+// drawPacket draws the bit numbers or per-row rulers, then each field
+// with its label and bit range.
+func drawPacket(pen *painter, data layout.PacketData, th *theme.Theme, cfg *config.Layout) {
+	pc := cfg.Packet
+	small := textStyle{size: th.FontSize * packetSmallFontScale, color: th.SecondaryTextColor}
+	if data.ShowBits {
+		centered := small
+		centered.anchor = "middle"
+		for bit := range data.BitsPerRow {
+			pen.label(pc.PaddingX+float32(bit)*pc.BitWidth+pc.BitWidth/2, th.FontSize*cfg.LabelLineHeight,
+				strconv.Itoa(bit), centered)
+		}
+	}
+
+	for _, row := range data.Rows {
+		if data.BitRuler {
+			drawPacketRuler(pen, row, data.BitsPerRow, th, pc.PaddingX, pc.BitWidth, small)
+		}
+		for _, field := range row.Fields {
+			if field.ContinuesFrom || field.ContinuesTo {
+				drawPacketSplitField(pen, field, th)
+			} else {
+				pen.rect(field.X, field.Y, field.Width, field.Height, 0,
+					shapeStyle{fill: th.PrimaryColor, stroke: th.NodeBorderColor})
+			}
+			if label := firstLine(field.Label); label != "" {
+				pen.label(field.X+field.Width/2, field.Y+field.Height/2+field.Label.FontSize/packetBaselineDiv,
+					label, textStyle{size: field.Label.FontSize, color: th.PrimaryTextColor, anchor: "middle"})
+			}
+
+			bitY := field.Y + field.Height - packetBitInset
+			pen.label(field.X+packetBitInset, bitY, strconv.Itoa(field.StartBit), small)
+			if field.EndBit != field.StartBit {
+				end := small
+				end.anchor = "end"
+				pen.label(field.X+field.Width-packetBitInset, bitY, strconv.Itoa(field.EndBit), end)
+			}
+		}
+	}
+}
+
+// drawPacketRuler draws a row's absolute bit numbers above it, with a
+// tick at the start of each bit.
+func drawPacketRuler(pen *painter, row layout.PacketRowLayout, bitsPerRow int, th *theme.Theme, padX, bitW float32, text textStyle) {
+	step := 1
+	for float32(step)*bitW < packetRulerMinSpacing {
+		step *= 2
+	}
+	text.anchor = "middle"
+	for bit := range bitsPerRow {
+		left := padX + float32(bit)*bitW
+		pen.line(left, row.Y-packetRulerTick, left, row.Y, shapeStyle{stroke: th.NodeBorderColor, width: packetRulerWidth})
+		if bit%step == 0 {
+			pen.label(left+bitW/2, row.Y-packetRulerTick-1, strconv.Itoa(row.StartBit+bit), text)
+		}
+	}
+}
+
+// drawPacketSplitField draws the cell of a field that crosses a row
+// boundary, dashing the edge on each side where it continues.
+func drawPacketSplitField(pen *painter, field layout.PacketFieldLayout, th *theme.Theme) {
+	right := field.X + field.Width
+	bottom := field.Y + field.Height
+	border := shapeStyle{stroke: th.NodeBorderColor}
+	pen.rect(field.X, field.Y, field.Width, field.Height, 0, shapeStyle{fill: th.PrimaryColor})
+	pen.line(field.X, field.Y, right, field.Y, border)
+	pen.line(field.X, bottom, right, bottom, border)
+	for _, side := range []struct {
+		x         float32
+		continued bool
+	}{{field.X, field.ContinuesFrom}, {right, field.ContinuesTo}} {
+		edge := border
+		if side.continued {
+			edge.dash = packetContinuedDash
+		}
+		pen.line(side.x, field.Y, side.x, bottom, edge)
+	}
+}
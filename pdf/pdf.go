@@ -0,0 +1,209 @@
+// Package pdf writes laid-out diagrams as vector PDF documents.
+//
+// It is a second renderer beside the SVG one: it walks the computed
+// layout and its diagram data and draws each element as native PDF
+// paths and text set in embedded fonts, following the SVG renderer's
+// geometry and colours. Nodes with click or link URLs, linked sequence
+// participants and linked Gantt tasks become link annotations.
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Page geometry constants.
+const (
+	// pointsPerPixel converts CSS pixels (1/96 inch) to PDF points (1/72 inch).
+	pointsPerPixel = 0.75
+	// defaultMargin is the space kept around the diagram on fixed paper, in points.
+	defaultMargin = 36.0
+)
+
+// ErrUnknownPaper is returned for a paper name not in the built-in list.
+var ErrUnknownPaper = errors.New("pdf: unknown paper size")
+
+// paperSizes are portrait page sizes in points.
+//
+//nolint:gochecknoglobals // read-only lookup table.
+var paperSizes = map[string][2]float64{
+	"a3":      {841.89, 1190.55},
+	"a4":      {595.28, 841.89},
+	"a5":      {419.53, 595.28},
+	"letter":  {612, 792},
+	"legal":   {612, 1008},
+	"tabloid": {792, 1224},
+}
+
+// Options configures PDF output. The zero value produces a page sized to
+// the diagram.
+type Options struct {
+	// Paper selects a fixed page size ("a3", "a4", "a5", "letter",
+	// "legal", "tabloid"). Empty sizes the page to the diagram.
+	Paper string
+	// Landscape swaps the width and height of a fixed paper size.
+	Landscape bool
+	// Scale multiplies the diagram size on pages sized to the diagram.
+	// On fixed paper the diagram is scaled to fit instead. Zero means 1.
+	Scale float64
+	// Margin is the space kept around the diagram on fixed paper, in
+	// points. Zero means half an inch.
+	Margin float64
+}
+
+// PaperSizes returns the names accepted by Options.Paper, sorted.
+func PaperSizes() []string {
+	names := make([]string, 0, len(paperSizes))
+	for name := range paperSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render draws a computed layout as a single-page PDF document.
+func Render(computed *layout.Layout, th *theme.Theme, cfg *config.Layout, opts Options) ([]byte, error) {
+	width := max(computed.Width, 1)
+	height := max(computed.Height, 1)
+	pageWidth, pageHeight, device, err := pageGeometry(float64(width), float64(height), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	content := newContent()
+	pen := newPainter(content, device, th.FontFamily)
+	pen.rect(0, 0, width, height, 0, shapeStyle{fill: th.Background})
+	drawDiagram(pen, computed, th, cfg)
+	nodeLinks(pen, computed)
+
+	page := &page{
+		width:   pageWidth,
+		height:  pageHeight,
+		content: content,
+		links:   *pen.links,
+		title:   computed.Title,
+	}
+	return page.bytes(), nil
+}
+
+// drawDiagram draws the elements of a layout's diagram type. Layouts
+// without diagram-specific data are drawn as graphs.
+func drawDiagram(pen *painter, computed *layout.Layout, th *theme.Theme, cfg *config.Layout) {
+	switch data := computed.Diagram.(type) {
+	case layout.ClassData:
+		drawClass(pen, computed, data, th, cfg)
+	case layout.ERData:
+		drawER(pen, computed, data, th, cfg)
+	case layout.StateData:
+		drawState(pen, computed, data, th, cfg)
+	case layout.SequenceData:
+		drawSequence(pen, data, th)
+	case layout.KanbanData:
+		drawKanban(pen, data, th, cfg)
+	case layout.PacketData:
+		drawPacket(pen, data, th, cfg)
+	case layout.PieData:
+		drawPie(pen, computed, data, th)
+	case layout.QuadrantData:
+		drawQuadrant(pen, computed, data, th, cfg)
+	case layout.TimelineData:
+		drawTimeline(pen, computed, data, th)
+	case layout.GanttData:
+		drawGantt(pen, computed, data, th)
+	case layout.GitGraphData:
+		drawGitGraph(pen, data, th, cfg)
+	case layout.XYChartData:
+		drawXYChart(pen, computed, data, th, cfg)
+	case layout.RadarData:
+		drawRadar(pen, computed, data, th, cfg)
+	case layout.MindmapData:
+		drawMindmap(pen, data, th, cfg)
+	case layout.SankeyData:
+		drawSankey(pen, computed, data, th)
+	case layout.TreemapData:
+		drawTreemap(pen, computed, data, th, cfg)
+	case layout.RequirementData:
+		drawRequirement(pen, computed, data, th, cfg)
+	case layout.BlockData:
+		drawBlock(pen, computed, data, th)
+	case layout.C4Data:
+		drawC4(pen, computed, data, th, cfg)
+	case layout.JourneyData:
+		drawJourney(pen, computed, data, th, cfg)
+	case layout.ArchitectureData:
+		drawArchitecture(pen, computed, data, th, cfg)
+	default:
+		drawGraph(pen, computed, th, cfg)
+	}
+}
+
+// pageGeometry returns the page size in points and the transform from
+// diagram pixels to page space, whose origin is the top-left corner and
+// whose Y axis points down.
+func pageGeometry(width, height float64, opts Options) (float64, float64, canvas.Matrix, error) {
+	if opts.Paper == "" {
+		scale := opts.Scale
+		if scale <= 0 {
+			scale = 1
+		}
+		factor := pointsPerPixel * scale
+		return width * factor, height * factor, canvas.Scaling(factor, factor), nil
+	}
+
+	size, ok := paperSizes[strings.ToLower(opts.Paper)]
+	if !ok {
+		return 0, 0, canvas.Matrix{}, fmt.Errorf("%w: %s", ErrUnknownPaper, opts.Paper)
+	}
+	pageWidth, pageHeight := size[0], size[1]
+	if opts.Landscape {
+		pageWidth, pageHeight = pageHeight, pageWidth
+	}
+	margin := opts.Margin
+	if margin <= 0 {
+		margin = defaultMargin
+	}
+	availWidth := math.Max(pageWidth-2*margin, 1)
+	availHeight := math.Max(pageHeight-2*margin, 1)
+	fit := math.Min(availWidth/math.Max(width, 1), availHeight/math.Max(height, 1))
+	offsetX := (pageWidth - width*fit) / 2   //nolint:mnd // center horizontally.
+	offsetY := (pageHeight - height*fit) / 2 //nolint:mnd // center vertically.
+	return pageWidth, pageHeight, canvas.Translate(offsetX, offsetY).Multiply(canvas.Scaling(fit, fit)), nil
+}
+
+// link is a clickable page region.
+type link struct {
+	minX, minY, maxX, maxY float64 // page space, Y down
+	url                    string
+	title                  string
+}
+
+// nodeLinks adds link regions for the nodes that carry a URL, in node ID
+// order.
+func nodeLinks(pen *painter, computed *layout.Layout) {
+	ids := make([]string, 0, len(computed.NodeLinks))
+	for id := range computed.NodeLinks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		target := computed.NodeLinks[id]
+		node := computed.Nodes[id]
+		if target == nil || node == nil {
+			continue
+		}
+		title := ""
+		if target.Title != nil {
+			title = *target.Title
+		}
+		pen.link(node.X-node.Width/2, node.Y-node.Height/2, node.Width, node.Height, target.URL, title)
+	}
+}
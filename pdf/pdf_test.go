@@ -0,0 +1,221 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/parser"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// renderInput parses, lays out and renders a diagram as PDF.
+func renderInput(t *testing.T, input string, opts Options) []byte {
+	t.Helper()
+	parsed, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	data, err := Render(layout.ComputeLayout(parsed.Graph, th, cfg), th, cfg, opts)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	return data
+}
+
+// mediaBox returns the page width and height.
+func mediaBox(t *testing.T, data []byte) (float64, float64) {
+	t.Helper()
+	match := regexp.MustCompile(`/MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("no MediaBox")
+	}
+	width, _ := strconv.ParseFloat(string(match[1]), 64)
+	height, _ := strconv.ParseFloat(string(match[2]), 64)
+	return width, height
+}
+
+// streams returns the decompressed contents of every stream.
+func streams(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var out [][]byte
+	re := regexp.MustCompile(`/Length (\d+)[^>]*>>\nstream\n`)
+	for _, loc := range re.FindAllSubmatchIndex(data, -1) {
+		size, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		reader, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+size]))
+		if err != nil {
+			t.Fatalf("stream at %d: %v", loc[1], err)
+		}
+		plain, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("stream at %d: %v", loc[1], err)
+		}
+		out = append(out, plain)
+	}
+	return out
+}
+
+func TestRenderStructure(t *testing.T) {
+	data := renderInput(t, "flowchart LR\n  A[Start]-->B[End]", Options{})
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+
+	// Every cross-reference entry must point at its object.
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	offset, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(data[offset:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", offset)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[offset:], -1)
+	for idx, entry := range entries {
+		pos, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(idx+1) + " 0 obj"; !bytes.HasPrefix(data[pos:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", idx+1, data[pos:pos+10])
+		}
+	}
+
+	if !bytes.Contains(data, []byte("/FontFile2")) || !bytes.Contains(data, []byte("/Identity-H")) {
+		t.Error("text font is not embedded")
+	}
+	var found bool
+	for _, stream := range streams(t, data) {
+		if bytes.Contains(stream, []byte("] TJ")) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("no content stream shows text")
+	}
+}
+
+func TestRenderToUnicode(t *testing.T) {
+	data := renderInput(t, "flowchart LR\n  A[Hi]", Options{})
+	for _, stream := range streams(t, data) {
+		// H is U+0048 and i is U+0069.
+		if bytes.Contains(stream, []byte("beginbfchar")) {
+			if !bytes.Contains(stream, []byte("<0048>")) || !bytes.Contains(stream, []byte("<0069>")) {
+				t.Errorf("ToUnicode CMap lacks the label text:\n%s", stream)
+			}
+			return
+		}
+	}
+	t.Error("no ToUnicode CMap")
+}
+
+func TestPageSizedToDiagram(t *testing.T) {
+	input := "flowchart LR\n  A-->B"
+	width, height := mediaBox(t, renderInput(t, input, Options{}))
+	doubleWidth, doubleHeight := mediaBox(t, renderInput(t, input, Options{Scale: 2}))
+	if math.Abs(doubleWidth-2*width) > 0.01 || math.Abs(doubleHeight-2*height) > 0.01 {
+		t.Errorf("scaled page = %gx%g, want twice %gx%g", doubleWidth, doubleHeight, width, height)
+	}
+}
+
+func TestPaperSize(t *testing.T) {
+	width, height := mediaBox(t, renderInput(t, "flowchart LR\n  A-->B", Options{Paper: "A4"}))
+	if width != 595.28 || height != 841.89 {
+		t.Errorf("A4 page = %gx%g, want 595.28x841.89", width, height)
+	}
+	width, height = mediaBox(t, renderInput(t, "flowchart LR\n  A-->B", Options{Paper: "letter", Landscape: true}))
+	if width != 792 || height != 612 {
+		t.Errorf("landscape letter page = %gx%g, want 792x612", width, height)
+	}
+}
+
+func TestPageGeometryFitsPaper(t *testing.T) {
+	pageWidth, pageHeight, device, err := pageGeometry(2000, 100, Options{Paper: "a4", Margin: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pageWidth != 595.28 || pageHeight != 841.89 {
+		t.Errorf("page = %gx%g", pageWidth, pageHeight)
+	}
+	// The wide diagram is limited by the page width and centered vertically.
+	if scale := device[0]; math.Abs(2000*scale-(pageWidth-20)) > 1e-9 {
+		t.Errorf("scaled width = %g, want %g", 2000*scale, pageWidth-20)
+	}
+	if top := device[5]; math.Abs(top-(pageHeight-100*device[3])/2) > 1e-9 {
+		t.Errorf("top offset = %g, want diagram centered", top)
+	}
+	if _, _, _, err := pageGeometry(10, 10, Options{Paper: "napkin"}); !errors.Is(err, ErrUnknownPaper) {
+		t.Errorf("unknown paper error = %v, want ErrUnknownPaper", err)
+	}
+}
+
+func TestNodeLinkAnnotation(t *testing.T) {
+	input := "flowchart LR\n  A-->B\n  click B \"https://example.com/b\" \"Go to B\""
+	data := renderInput(t, input, Options{})
+	if !bytes.Contains(data, []byte("/Subtype /Link")) ||
+		!bytes.Contains(data, []byte("/URI (https://example.com/b)")) ||
+		!bytes.Contains(data, []byte("/Contents (Go to B)")) {
+		t.Fatalf("missing link annotation in:\n%s", data[:min(len(data), 2000)])
+	}
+	_, height := mediaBox(t, data)
+	match := regexp.MustCompile(`/Rect \[([\d.]+) ([\d.]+) ([\d.]+) ([\d.]+)\]`).FindSubmatch(data)
+	var rect [4]float64
+	for idx := range rect {
+		rect[idx], _ = strconv.ParseFloat(string(match[idx+1]), 64)
+	}
+	if rect[0] >= rect[2] || rect[1] >= rect[3] || rect[1] < 0 || rect[3] > height {
+		t.Errorf("link rect = %v, want a box inside the page", rect)
+	}
+}
+
+func TestRenderFixtures(t *testing.T) {
+	files, err := filepath.Glob("../testdata/fixtures/*.mmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Past the background, every diagram paints shapes.
+		var painted int
+		for _, stream := range streams(t, renderInput(t, string(src), Options{})) {
+			painted += bytes.Count(stream, []byte("f\nQ\n")) + bytes.Count(stream, []byte("S\nQ\n"))
+		}
+		if painted < 2 {
+			t.Errorf("%s: %d painted shapes, want the diagram drawn", filepath.Base(file), painted)
+		}
+	}
+}
+
+func TestSequenceLinkAnnotation(t *testing.T) {
+	input := "sequenceDiagram\n  participant Alice\n  participant Bob\n" +
+		"  link Alice: Dashboard @ https://example.com/dashboard\n  Alice->>Bob: Hi"
+	data := renderInput(t, input, Options{})
+	// The header and the footer both link.
+	if got := bytes.Count(data, []byte("/URI (https://example.com/dashboard)")); got != 2 {
+		t.Errorf("participant link annotations = %d, want 2", got)
+	}
+}
+
+func TestGanttLinkAnnotation(t *testing.T) {
+	input := "gantt\n  dateFormat YYYY-MM-DD\n  section Build\n" +
+		"    Design :d1, 2024-01-01, 3d\n  click d1 href \"https://example.com/design\""
+	data := renderInput(t, input, Options{})
+	if !bytes.Contains(data, []byte("/URI (https://example.com/design)")) {
+		t.Error("missing task link annotation")
+	}
+}
+
+func TestPaperSizes(t *testing.T) {
+	names := PaperSizes()
+	if len(names) != len(paperSizes) || names[0] != "a3" {
+		t.Errorf("PaperSizes() = %v", names)
+	}
+}
@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Pie chart drawing constants, matching the SVG renderer.
+const (
+	pieFallbackColor = "#888888"
+	// pieFullTurnSlack treats slices this close to a full turn as whole
+	// circles.
+	pieFullTurnSlack = 0.01
+)
+
+// drawPie draws the title, slices, outer rim, slice labels and legend.
+func drawPie(pen *painter, computed *layout.Layout, data layout.PieData, th *theme.Theme) {
+	pen.label(computed.Width/2, th.PieTitleTextSize, data.Title, textStyle{
+		size:   th.PieTitleTextSize,
+		color:  th.PieTitleTextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	cx, cy, radius := data.CenterX, data.CenterY, data.Radius
+	for _, slice := range data.Slices {
+		style := shapeStyle{
+			fill:        pieColor(th, slice.ColorIndex),
+			fillOpacity: th.PieOpacity,
+			stroke:      th.PieStrokeColor,
+			width:       th.PieStrokeWidth,
+		}
+		span := float64(slice.EndAngle - slice.StartAngle)
+		if span >= 2*math.Pi-pieFullTurnSlack {
+			pen.circle(cx, cy, radius, style)
+			continue
+		}
+		center := pt(cx, cy)
+		start := pt(cx+radius*float32(math.Cos(float64(slice.StartAngle))), cy+radius*float32(math.Sin(float64(slice.StartAngle))))
+		end := pt(cx+radius*float32(math.Cos(float64(slice.EndAngle))), cy+radius*float32(math.Sin(float64(slice.EndAngle))))
+		var wedge canvas.Path
+		wedge.MoveTo(center)
+		wedge.LineTo(start)
+		wedge.ArcTo(start, float64(radius), float64(radius), 0, span > math.Pi, true, end)
+		wedge.Close()
+		pen.shape(wedge, style)
+	}
+	if th.PieOuterStrokeWidth > 0 {
+		pen.circle(cx, cy, radius, shapeStyle{stroke: th.PieOuterStrokeColor, width: th.PieOuterStrokeWidth})
+	}
+
+	// Outside labels get a leader line from the rim and are anchored
+	// towards the pie.
+	for _, slice := range data.Slices {
+		text := textStyle{size: th.PieSectionTextSize, color: th.PieSectionTextColor, anchor: "middle", baseline: "middle"}
+		if slice.Outside {
+			pen.polyline(slice.Leader, shapeStyle{stroke: th.LineColor})
+			text.color, text.anchor = th.TextColor, "start"
+			if slice.LabelX < data.CenterX {
+				text.anchor = "end"
+			}
+		}
+		pen.label(slice.LabelX, slice.LabelY, slice.Text, text)
+	}
+
+	for _, entry := range data.Legend {
+		pen.rect(entry.X, entry.Y, data.LegendSwatch, data.LegendSwatch, 0,
+			shapeStyle{fill: pieColor(th, entry.ColorIndex), stroke: th.PieStrokeColor})
+		pen.label(entry.TextX, entry.Y+data.LegendSwatch/2, entry.Text, textStyle{ //nolint:mnd // centred on the swatch.
+			size:     th.PieSectionTextSize,
+			color:    th.TextColor,
+			baseline: "middle",
+		})
+	}
+}
+
+// pieColor returns the theme colour for a slice's colour index.
+func pieColor(th *theme.Theme, idx int) string {
+	if len(th.PieColors) == 0 {
+		return pieFallbackColor
+	}
+	return th.PieColors[idx%len(th.PieColors)]
+}
@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Quadrant chart drawing constants, matching the SVG renderer.
+const (
+	quadrantAxisLabelPad  float32 = 4
+	quadrantLabelOpacity  float32 = 0.6
+	quadrantCrossWidth    float32 = 0.5
+	quadrantCrossDash             = "4,4"
+	quadrantVerticalTurns float32 = -90
+)
+
+// drawQuadrant draws the title, the four quadrants with their labels, the
+// axis labels, and the data points.
+func drawQuadrant(pen *painter, computed *layout.Layout, data layout.QuadrantData, th *theme.Theme, cfg *config.Layout) {
+	cx, cy := data.ChartX, data.ChartY
+	width, height := data.ChartWidth, data.ChartHeight
+	halfW, halfH := width/2, height/2
+	axisLabelSize := cfg.Quadrant.AxisLabelFontSize
+
+	pen.label(computed.Width/2, th.FontSize+cfg.Quadrant.PaddingY/2, data.Title, textStyle{
+		size:   th.FontSize,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	// Quadrants are numbered counter-clockwise from the top right.
+	origins := [4][2]float32{{cx + halfW, cy}, {cx, cy}, {cx, cy + halfH}, {cx + halfW, cy + halfH}}
+	fills := [4]string{th.QuadrantFill1, th.QuadrantFill2, th.QuadrantFill3, th.QuadrantFill4}
+	for idx, origin := range origins {
+		pen.rect(origin[0], origin[1], halfW, halfH, 0, shapeStyle{fill: fills[idx]})
+	}
+	pen.rect(cx, cy, width, height, 0, shapeStyle{stroke: th.LineColor})
+	cross := shapeStyle{stroke: th.LineColor, width: quadrantCrossWidth, dash: quadrantCrossDash}
+	pen.line(cx+halfW, cy, cx+halfW, cy+height, cross)
+	pen.line(cx, cy+halfH, cx+width, cy+halfH, cross)
+	for idx, origin := range origins {
+		pen.label(origin[0]+halfW/2, origin[1]+halfH/2, data.Labels[idx], textStyle{
+			size:     cfg.Quadrant.QuadrantLabelFontSize,
+			color:    th.TextColor,
+			anchor:   "middle",
+			baseline: "middle",
+			opacity:  quadrantLabelOpacity,
+		})
+	}
+
+	axis := textStyle{size: axisLabelSize, color: th.TextColor}
+	pen.label(cx, cy+height+axisLabelSize+quadrantAxisLabelPad, data.XAxisLeft, axis)
+	axis.anchor = "end"
+	pen.label(cx+width, cy+height+axisLabelSize+quadrantAxisLabelPad, data.XAxisRight, axis)
+	axis.rotate = quadrantVerticalTurns
+	pen.label(cx-quadrantAxisLabelPad, cy+height, data.YAxisBottom, axis)
+	axis.anchor = "start"
+	pen.label(cx-quadrantAxisLabelPad, cy, data.YAxisTop, axis)
+
+	// Points are styled by their classes and own styles, with labels where
+	// the layout placed them.
+	for _, point := range data.Points {
+		style := shapeStyle{fill: th.QuadrantPointFill, stroke: th.LineColor}
+		if point.Style.Color != nil {
+			style.fill = *point.Style.Color
+		}
+		if point.Style.StrokeColor != nil {
+			style.stroke = *point.Style.StrokeColor
+		}
+		if point.Style.StrokeWidth != nil {
+			style.width = *point.Style.StrokeWidth
+		}
+		pen.circle(point.X, point.Y, point.Radius, style)
+		pen.label(point.LabelX, point.LabelY, point.Label, textStyle{
+			size:   axisLabelSize,
+			color:  th.TextColor,
+			anchor: point.LabelAnchor,
+		})
+	}
+}
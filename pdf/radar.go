@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"math"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Radar chart drawing constants, matching the SVG renderer.
+const (
+	radarTitleOffsetY      float32 = 20
+	radarTitleFontSize     float32 = 16
+	radarLabelFontSize     float32 = 12
+	radarLabelSlack        float32 = 5
+	radarGraticuleWidth    float32 = 0.5
+	radarCurveWidth        float32 = 2
+	radarLegendWidth       float32 = 100
+	radarLegendRowHeight   float32 = 20
+	radarLegendSwatchW     float32 = 12
+	radarLegendSwatchH     float32 = 12
+	radarLegendTextOff     float32 = 16
+	radarLegendBaselineOff float32 = 10
+	radarFallbackColor             = "#4C78A8"
+	radarGraticuleFallback         = "#E0E0E0"
+	radarAxisFallback              = "#333"
+	radarMinPolygonAxes            = 3
+)
+
+// drawRadar draws the title, graticule, axes with labels, the curves and
+// the legend.
+func drawRadar(pen *painter, computed *layout.Layout, data layout.RadarData, th *theme.Theme, cfg *config.Layout) {
+	cx, cy := data.CenterX, data.CenterY
+	numAxes := len(data.Axes)
+
+	pen.label(computed.Width/2, radarTitleOffsetY, data.Title, textStyle{
+		size:   radarTitleFontSize,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	graticule := shapeStyle{stroke: th.RadarGraticuleColor, width: radarGraticuleWidth}
+	if graticule.stroke == "" {
+		graticule.stroke = radarGraticuleFallback
+	}
+	for _, radius := range data.GraticuleRadii {
+		if data.GraticuleType != ir.RadarGraticulePolygon || numAxes < radarMinPolygonAxes {
+			pen.circle(cx, cy, radius, graticule)
+			continue
+		}
+		ring := make([][2]float32, numAxes)
+		angleStep := 2 * math.Pi / float64(numAxes)
+		for idx := range ring {
+			angle := -math.Pi/2 + float64(idx)*angleStep
+			ring[idx] = [2]float32{cx + radius*float32(math.Cos(angle)), cy + radius*float32(math.Sin(angle))}
+		}
+		pen.polygon(ring, graticule)
+	}
+
+	axisColor := th.RadarAxisColor
+	if axisColor == "" {
+		axisColor = radarAxisFallback
+	}
+	for _, ax := range data.Axes {
+		pen.line(cx, cy, ax.EndX, ax.EndY, shapeStyle{stroke: axisColor})
+		text := textStyle{size: radarLabelFontSize, color: th.TextColor, anchor: "middle", baseline: "middle"}
+		if ax.LabelX > cx+radarLabelSlack {
+			text.anchor = "start"
+		} else if ax.LabelX < cx-radarLabelSlack {
+			text.anchor = "end"
+		}
+		pen.label(ax.LabelX, ax.LabelY, ax.Label, text)
+	}
+
+	opacity := th.RadarCurveOpacity
+	if opacity <= 0 {
+		opacity = cfg.Radar.CurveOpacity
+	}
+	for _, curve := range data.Curves {
+		color := radarColor(th, curve.ColorIndex)
+		pen.polygon(curve.Points, shapeStyle{fill: color, fillOpacity: opacity, stroke: color, width: radarCurveWidth})
+	}
+
+	if !data.ShowLegend {
+		return
+	}
+	legendX := computed.Width - cfg.Radar.PaddingX - radarLegendWidth
+	for idx, curve := range data.Curves {
+		posY := cfg.Radar.PaddingY + float32(idx)*radarLegendRowHeight
+		pen.rect(legendX, posY, radarLegendSwatchW, radarLegendSwatchH, 0, shapeStyle{fill: radarColor(th, curve.ColorIndex)})
+		pen.label(legendX+radarLegendTextOff, posY+radarLegendBaselineOff, curve.Label,
+			textStyle{size: radarLabelFontSize, color: th.TextColor})
+	}
+}
+
+// radarColor returns the theme colour for a curve's colour index.
+func radarColor(th *theme.Theme, idx int) string {
+	if len(th.RadarCurveColors) == 0 {
+		return radarFallbackColor
+	}
+	return th.RadarCurveColors[idx%len(th.RadarCurveColors)]
+}
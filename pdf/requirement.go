@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Requirement diagram drawing constants, matching the SVG renderer.
+const (
+	reqNodeBorderRadius    float32 = 4
+	reqStereotypeLineScale float32 = 0.75
+	reqNameDividerScale    float32 = 0.25
+	reqDividerWidth        float32 = 0.5
+	reqDefaultMetaFontSize float32 = 11
+	reqDefaultPadX         float32 = 12
+)
+
+// drawRequirement draws edges, then requirement and element nodes with a
+// stereotype header, their name and metadata lines.
+func drawRequirement(pen *painter, computed *layout.Layout, data layout.RequirementData, th *theme.Theme, cfg *config.Layout) {
+	drawEdges(pen, computed, th, func(*layout.EdgeLayout, bool) markerKind { return markerArrow })
+
+	metaFontSize := cfg.Requirement.MetadataFontSize
+	if metaFontSize <= 0 {
+		metaFontSize = reqDefaultMetaFontSize
+	}
+	lineH := th.FontSize * cfg.LabelLineHeight
+	padX := cfg.Requirement.NodePadding
+	if padX <= 0 {
+		padX = reqDefaultPadX
+	}
+	divider := shapeStyle{stroke: th.RequirementBorder, width: reqDividerWidth}
+
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		posX := node.X - node.Width/2
+		posY := node.Y - node.Height/2
+		pen.rect(posX, posY, node.Width, node.Height, reqNodeBorderRadius,
+			shapeStyle{fill: th.RequirementFill, stroke: th.RequirementBorder})
+
+		kind := data.NodeKinds[id]
+		req := data.Requirements[id]
+		stereotype := "«element»"
+		if kind == "requirement" && req != nil {
+			stereotype = "«" + req.Type.Stereotype() + "»"
+		}
+		curY := posY + cfg.Padding.NodeVertical + lineH*reqStereotypeLineScale
+		pen.label(posX+node.Width/2, curY, stereotype, textStyle{
+			size:   metaFontSize,
+			color:  th.TextColor,
+			anchor: "middle",
+			italic: true,
+		})
+		curY += lineH * reqNameDividerScale
+		pen.line(posX, curY, posX+node.Width, curY, divider)
+
+		curY += lineH * reqStereotypeLineScale
+		pen.label(posX+node.Width/2, curY, firstLine(node.Label), textStyle{
+			size:   th.FontSize,
+			color:  th.TextColor,
+			anchor: "middle",
+			bold:   true,
+		})
+		curY += lineH * reqNameDividerScale
+		pen.line(posX, curY, posX+node.Width, curY, divider)
+
+		var meta []string
+		if kind == "requirement" {
+			if req != nil {
+				meta = requirementMeta(req)
+			}
+		} else if elem := data.Elements[id]; elem != nil {
+			meta = elementMeta(elem)
+		}
+		metaLineH := metaFontSize * cfg.LabelLineHeight
+		for _, line := range meta {
+			curY += metaLineH
+			pen.label(posX+padX, curY, line, textStyle{size: metaFontSize, color: th.TextColor})
+		}
+	}
+}
+
+// requirementMeta returns the metadata lines of a requirement node.
+func requirementMeta(req *ir.RequirementDef) []string {
+	var lines []string
+	if req.ID != "" {
+		lines = append(lines, "Id: "+req.ID)
+	}
+	if req.Text != "" {
+		lines = append(lines, "Text: "+req.Text)
+	}
+	if req.Risk != ir.RiskNone {
+		lines = append(lines, "Risk: "+req.Risk.String())
+	}
+	if req.VerifyMethod != ir.VerifyNone {
+		lines = append(lines, "Verify: "+req.VerifyMethod.String())
+	}
+	return lines
+}
+
+// elementMeta returns the metadata lines of an element node.
+func elementMeta(elem *ir.ElementDef) []string {
+	var lines []string
+	if elem.Type != "" {
+		lines = append(lines, "Type: "+elem.Type)
+	}
+	if elem.DocRef != "" {
+		lines = append(lines, "Doc: "+elem.DocRef)
+	}
+	return lines
+}
@@ -0,0 +1,119 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Sankey diagram drawing constants, matching the SVG renderer.
+const (
+	sankeyLabelGap         float32 = 4
+	sankeyLabelBaselineDiv float32 = 3
+	sankeyValueScale               = 100
+	sankeyDefaultLinkColor         = "#888"
+	sankeyDefaultLinkAlpha float32 = 0.4
+	sankeyOpacityPrecision         = 100
+)
+
+// Sankey link colourings other than a plain colour.
+const (
+	sankeyLinkSource   = "source"
+	sankeyLinkTarget   = "target"
+	sankeyLinkGradient = "gradient"
+)
+
+// sankeyFallbackColors are the node colours when the theme has none.
+var sankeyFallbackColors = []string{"#4C78A8", "#72B7B2", "#EECA3B", "#F58518"}
+
+// drawSankey draws the links as bands, then the nodes with their labels.
+// Gradient links are drawn in the mean of their end colours.
+func drawSankey(pen *painter, computed *layout.Layout, data layout.SankeyData, th *theme.Theme) {
+	nodeColors := th.SankeyNodeColors
+	if len(nodeColors) == 0 {
+		nodeColors = sankeyFallbackColors
+	}
+	linkColor := th.SankeyLinkColor
+	if linkColor == "" {
+		linkColor = sankeyDefaultLinkColor
+	}
+	linkOpacity := th.SankeyLinkOpacity
+	if linkOpacity == 0 {
+		linkOpacity = sankeyDefaultLinkAlpha
+	}
+	// The SVG writes the opacity to two places.
+	linkOpacity = float32(math.Round(float64(linkOpacity)*sankeyOpacityPrecision) / sankeyOpacityPrecision)
+	nodeColor := func(idx int) string {
+		return nodeColors[data.Nodes[idx].ColorIndex%len(nodeColors)]
+	}
+
+	for _, link := range data.Links {
+		if link.SourceIdx >= len(data.Nodes) || link.TargetIdx >= len(data.Nodes) {
+			continue
+		}
+		src := data.Nodes[link.SourceIdx]
+		tgt := data.Nodes[link.TargetIdx]
+		sx, sy := src.X+src.Width, link.SourceY+link.Width/2
+		tx, ty := tgt.X, link.TargetY+link.Width/2
+		midX := (sx + tx) / 2
+		var band canvas.Path
+		band.MoveTo(pt(sx, sy))
+		band.CubicTo(pt(midX, sy), pt(midX, ty), pt(tx, ty))
+
+		stroke := linkColor
+		switch data.LinkColor {
+		case "":
+		case sankeyLinkSource:
+			stroke = nodeColor(link.SourceIdx)
+		case sankeyLinkTarget:
+			stroke = nodeColor(link.TargetIdx)
+		case sankeyLinkGradient:
+			stroke = mixColors(nodeColor(link.SourceIdx), nodeColor(link.TargetIdx))
+		default:
+			stroke = data.LinkColor
+		}
+		pen.shape(band, shapeStyle{stroke: stroke, width: link.Width, strokeOpacity: linkOpacity})
+	}
+
+	for _, node := range data.Nodes {
+		pen.rect(node.X, node.Y, node.Width, node.Height, 0,
+			shapeStyle{fill: nodeColors[node.ColorIndex%len(nodeColors)]})
+
+		// Label beside the node, outside the chart's middle: to the right
+		// of nodes in the left half and to the left of the others.
+		label := node.Label
+		if data.ShowValues {
+			label += " " + sankeyFormatValue(node.Value)
+		}
+		text := textStyle{size: th.FontSize, color: th.TextColor}
+		labelX := node.X + node.Width + sankeyLabelGap
+		if node.X+node.Width/2 > computed.Width/2 {
+			labelX = node.X - sankeyLabelGap
+			text.anchor = "end"
+		}
+		pen.label(labelX, node.Y+node.Height/2+th.FontSize/sankeyLabelBaselineDiv, label, text)
+	}
+}
+
+// mixColors returns the mean of two CSS colours, or the first when
+// either does not parse.
+func mixColors(first, second string) string {
+	col1, ok1 := canvas.ParseColor(first)
+	col2, ok2 := canvas.ParseColor(second)
+	if !ok1 || !ok2 {
+		return first
+	}
+	mean := func(level1, level2 uint8) int { return (int(level1) + int(level2) + 1) / 2 } //nolint:mnd // mean of two.
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", mean(col1.R, col2.R), mean(col1.G, col2.G), mean(col1.B, col2.B),
+		strconv.FormatFloat(float64(mean(col1.A, col2.A))/math.MaxUint8, 'f', -1, 64))
+}
+
+// sankeyFormatValue rounds a node value to two decimal places and drops
+// trailing zeros.
+func sankeyFormatValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*sankeyValueScale)/sankeyValueScale, 'f', -1, 64)
+}
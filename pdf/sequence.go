@@ -0,0 +1,317 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Sequence diagram drawing constants, matching the SVG renderer.
+const (
+	seqBoxBorderRadius    float32 = 4
+	seqBoxLabelOffsetX    float32 = 8
+	seqBoxLabelOffsetY    float32 = 16
+	seqBoxFontScale       float32 = 0.9
+	seqBoxFill                    = "rgba(0,0,0,0.05)"
+	seqBoxColorOpacity    float32 = 0.15
+	seqFrameFill                  = "rgba(0,0,0,0.03)"
+	seqFrameBorderRadius  float32 = 4
+	seqFrameTabCharWidth  float32 = 0.6
+	seqFrameTabPadding    float32 = 16
+	seqFrameTabPadY       float32 = 8
+	seqFrameLabelOffsetX  float32 = 6
+	seqFrameFontScale     float32 = 0.85
+	seqDash                       = "5,5"
+	seqContinuedDash              = "3,3"
+	seqActivationRadius   float32 = 2
+	seqMessageWidth       float32 = 1.5
+	seqSelfBumpWidth      float32 = 40
+	seqSelfBumpHeight     float32 = 30
+	seqSelfTextOffsetX    float32 = 24
+	seqTextAboveOffset    float32 = 6
+	seqAutoNumFontScale   float32 = 0.6
+	seqAutoNumTextScale   float32 = 0.7
+	seqAutoNumBaselineAdj float32 = 0.3
+	seqNoteBorderRadius   float32 = 4
+	seqNoteLineHeight     float32 = 1.2
+	seqNoteTextPadY       float32 = 4
+	seqNoteTextPadX       float32 = 8
+	seqPartBorderRadius   float32 = 4
+	seqPartBorderWidth    float32 = 1.5
+	seqKindFontScale      float32 = 0.65
+	seqTextBaselineAdj    float32 = 0.35
+	seqHeadScale          float32 = 0.15
+	seqBodyScale          float32 = 0.3
+	seqArmYFraction       float32 = 0.3
+	seqArmSpanScale       float32 = 0.25
+	seqLegLenScale        float32 = 0.25
+	seqFigureInset        float32 = 2
+	seqDbEllipseScale     float32 = 0.12
+	seqMarkerFontScale    float32 = 0.8
+)
+
+// drawSequence draws a sequence diagram back to front: boxes, frames,
+// lifelines, activations, messages, notes, participants, then the page
+// markers of a split diagram.
+func drawSequence(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	drawSeqBoxes(pen, data, th)
+	drawSeqFrames(pen, data, th)
+	for _, lifeline := range data.Lifelines {
+		pen.line(lifeline.X, lifeline.TopY, lifeline.X, lifeline.BottomY,
+			shapeStyle{stroke: th.ActorLineColor, dash: seqDash})
+	}
+	for _, act := range data.Activations {
+		style := shapeStyle{fill: th.ActivationBackground, stroke: th.ActivationBorderColor}
+		// Activations carried over from an earlier page have a dashed
+		// outline.
+		if act.Continued {
+			style.dash = seqContinuedDash
+		}
+		pen.rect(act.X, act.TopY, act.Width, act.BottomY-act.TopY, seqActivationRadius, style)
+	}
+	drawSeqMessages(pen, data, th)
+	drawSeqNotes(pen, data, th)
+	drawSeqParticipants(pen, data, th)
+	for _, marker := range data.PageMarkers {
+		pen.label(marker.X, marker.Y, marker.Text, textStyle{
+			size:   th.FontSize * seqMarkerFontScale,
+			color:  th.SignalTextColor,
+			anchor: "middle",
+			italic: true,
+		})
+	}
+}
+
+// drawSeqBoxes draws participant groups as rounded rectangles.
+func drawSeqBoxes(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	for _, box := range data.Boxes {
+		style := shapeStyle{fill: box.Color, stroke: th.ClusterBorder}
+		if box.Color == "" {
+			style.fill = seqBoxFill
+		} else if !isTransparentColor(box.Color) {
+			style.fillOpacity = seqBoxColorOpacity
+		}
+		pen.rect(box.X, box.Y, box.Width, box.Height, seqBoxBorderRadius, style)
+		pen.label(box.X+seqBoxLabelOffsetX, box.Y+seqBoxLabelOffsetY, box.Label, textStyle{
+			size:  th.FontSize * seqBoxFontScale,
+			color: th.TextColor,
+			bold:  true,
+		})
+	}
+}
+
+// drawSeqFrames draws combined fragments (loop, alt, opt and so on) with
+// their kind tab, condition and dividers.
+func drawSeqFrames(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	for _, frame := range data.Frames {
+		fill := seqFrameFill
+		if frame.Kind == ir.FrameRect && frame.Color != "" {
+			fill = frame.Color
+		}
+		pen.rect(frame.X, frame.Y, frame.Width, frame.Height, seqFrameBorderRadius,
+			shapeStyle{fill: fill, stroke: th.ClusterBorder})
+
+		kindLabel := frame.Kind.String()
+		tabW := float32(len(kindLabel))*th.FontSize*seqFrameTabCharWidth + seqFrameTabPadding
+		pen.rect(frame.X, frame.Y, tabW, th.FontSize+seqFrameTabPadY, seqFrameBorderRadius,
+			shapeStyle{fill: th.ClusterBorder, stroke: th.ClusterBorder})
+		textY := frame.Y + th.FontSize + 1
+		pen.label(frame.X+seqFrameLabelOffsetX, textY, kindLabel, textStyle{
+			size:  th.FontSize * seqFrameFontScale,
+			color: th.LoopTextColor,
+			bold:  true,
+		})
+
+		label := frame.Label
+		if frame.Continued {
+			label = strings.TrimSpace(label + " (continued)")
+		}
+		pen.label(frame.X+tabW+seqFrameLabelOffsetX, textY, label, textStyle{
+			size:  th.FontSize * seqFrameFontScale,
+			color: th.LoopTextColor,
+		})
+
+		switch frame.Kind {
+		case ir.FrameAlt, ir.FramePar, ir.FrameCritical:
+			for _, divY := range frame.Dividers {
+				pen.line(frame.X, divY, frame.X+frame.Width, divY, shapeStyle{stroke: th.ClusterBorder, dash: seqDash})
+			}
+		}
+	}
+}
+
+// drawSeqMessages draws message arrows with their text above the line
+// and any autonumber badge at the start.
+func drawSeqMessages(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	for _, msg := range data.Messages {
+		style := shapeStyle{stroke: th.SignalColor, width: seqMessageWidth}
+		if msg.Kind.IsDotted() {
+			style.dash = seqDash
+		}
+		start, end := markerNone, markerNone
+		switch msg.Kind {
+		case ir.MsgSolidArrow, ir.MsgDottedArrow:
+			end = markerArrow
+		case ir.MsgSolidOpen, ir.MsgDottedOpen:
+			end = markerOpenArrow
+		case ir.MsgSolidCross, ir.MsgDottedCross:
+			end = markerCross
+		case ir.MsgBiSolid, ir.MsgBiDotted:
+			start, end = markerArrow, markerArrow
+		}
+
+		isSelf := msg.From == msg.To
+		textX := (msg.FromX + msg.ToX) / 2
+		if isSelf {
+			// A self-message loops out to the right and back.
+			pen.edge([][2]float32{
+				{msg.FromX, msg.Y},
+				{msg.FromX + seqSelfBumpWidth, msg.Y},
+				{msg.FromX + seqSelfBumpWidth, msg.Y + seqSelfBumpHeight},
+				{msg.FromX, msg.Y + seqSelfBumpHeight},
+			}, style, start, end, th)
+			textX = msg.FromX + seqSelfTextOffsetX
+		} else {
+			pen.edge([][2]float32{{msg.FromX, msg.Y}, {msg.ToX, msg.Y}}, style, start, end, th)
+		}
+
+		// The last line sits just above the arrow and earlier lines stack
+		// upwards.
+		if len(msg.Text.Lines) > 0 {
+			lineH := msg.Text.Height / float32(len(msg.Text.Lines))
+			last := len(msg.Text.Lines) - 1
+			for idx, line := range msg.Text.Lines {
+				pen.label(textX, msg.Y-seqTextAboveOffset-float32(last-idx)*lineH, line, textStyle{
+					size:   th.FontSize,
+					color:  th.SignalTextColor,
+					anchor: "middle",
+				})
+			}
+		}
+
+		if msg.Number > 0 {
+			pen.circle(msg.FromX, msg.Y, th.FontSize*seqAutoNumFontScale, shapeStyle{fill: th.SignalColor})
+			pen.label(msg.FromX, msg.Y+th.FontSize*seqAutoNumBaselineAdj, strconv.Itoa(msg.Number), textStyle{
+				size:   th.FontSize * seqAutoNumTextScale,
+				color:  th.SequenceNumberColor,
+				anchor: "middle",
+				bold:   true,
+			})
+		}
+	}
+}
+
+// drawSeqNotes draws note boxes and their text.
+func drawSeqNotes(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	for _, note := range data.Notes {
+		pen.rect(note.X, note.Y, note.Width, note.Height, seqNoteBorderRadius,
+			shapeStyle{fill: th.NoteBackground, stroke: th.NoteBorderColor})
+		fontSize := note.Text.FontSize
+		if fontSize <= 0 {
+			fontSize = th.FontSize
+		}
+		lineH := fontSize * seqNoteLineHeight
+		startY := note.Y + lineH + seqNoteTextPadY
+		for idx, line := range note.Text.Lines {
+			pen.label(note.X+seqNoteTextPadX, startY+float32(idx)*lineH, line,
+				textStyle{size: fontSize, color: th.NoteTextColor})
+		}
+	}
+}
+
+// drawSeqParticipants draws each participant's header at the top and its
+// mirrored footer at the bottom. A participant with a single link links
+// both; link menus need interactivity a page does not have, so
+// participants with several links are drawn without one.
+func drawSeqParticipants(pen *painter, data layout.SequenceData, th *theme.Theme) {
+	for idx := range data.Participants {
+		participant := &data.Participants[idx]
+		for _, topY := range []float32{participant.Y, data.DiagramHeight - participant.Height} {
+			drawSeqParticipant(pen, participant, topY, th)
+			if len(participant.Links) == 1 {
+				target := participant.Links[0]
+				pen.link(participant.X-participant.Width/2, topY, participant.Width, participant.Height,
+					target.URL, target.Label)
+			}
+		}
+	}
+}
+
+// drawSeqParticipant draws one participant shape with its top at topY.
+func drawSeqParticipant(pen *painter, participant *layout.SeqParticipantLayout, topY float32, th *theme.Theme) {
+	label := ""
+	if len(participant.Label.Lines) > 0 {
+		label = participant.Label.Lines[0]
+	}
+	cx := participant.X
+	text := textStyle{size: th.FontSize, color: th.ActorTextColor, anchor: "middle"}
+	centerY := topY + participant.Height/2 + th.FontSize*seqTextBaselineAdj
+
+	switch participant.Kind {
+	case ir.ActorStickFigure:
+		drawStickFigure(pen, cx, topY, participant.Height, th)
+		pen.label(cx, topY+participant.Height-seqFigureInset, label, text)
+	case ir.ParticipantDatabase:
+		drawDatabase(pen, cx, topY, participant.Width, participant.Height, th)
+		pen.label(cx, centerY, label, text)
+	default:
+		pen.rect(cx-participant.Width/2, topY, participant.Width, participant.Height, seqPartBorderRadius,
+			shapeStyle{fill: th.ActorBackground, stroke: th.ActorBorder, width: seqPartBorderWidth})
+		// Kinds other than a plain box name themselves above the label.
+		if participant.Kind != ir.ParticipantBox {
+			pen.label(cx, topY+th.FontSize*seqBoxFontScale, "<<"+participant.Kind.String()+">>", textStyle{
+				size:   th.FontSize * seqKindFontScale,
+				color:  th.ActorTextColor,
+				anchor: "middle",
+				italic: true,
+			})
+		}
+		pen.label(cx, centerY, label, text)
+	}
+}
+
+// drawStickFigure draws an actor as a stick figure: head, body, arms and
+// legs.
+func drawStickFigure(pen *painter, cx, topY, height float32, th *theme.Theme) {
+	headR := height * seqHeadScale
+	headCY := topY + headR + seqFigureInset
+	bodyTop := headCY + headR
+	bodyLen := height * seqBodyScale
+	bodyEnd := bodyTop + bodyLen
+	armY := bodyTop + bodyLen*seqArmYFraction
+	armSpan := height * seqArmSpanScale
+	legLen := height * seqLegLenScale
+
+	style := shapeStyle{stroke: th.ActorBorder, width: seqPartBorderWidth}
+	pen.circle(cx, headCY, headR, style)
+	pen.line(cx, bodyTop, cx, bodyEnd, style)
+	pen.line(cx-armSpan, armY, cx+armSpan, armY, style)
+	pen.line(cx, bodyEnd, cx-armSpan, bodyEnd+legLen, style)
+	pen.line(cx, bodyEnd, cx+armSpan, bodyEnd+legLen, style)
+}
+
+// drawDatabase draws a database participant as a cylinder.
+func drawDatabase(pen *painter, cx, topY, width, height float32, th *theme.Theme) {
+	posX := cx - width/2
+	ellipseRY := height * seqDbEllipseScale
+	bodyTop := topY + ellipseRY
+	bodyH := height - 2*ellipseRY
+	style := shapeStyle{fill: th.ActorBackground, stroke: th.ActorBorder, width: seqPartBorderWidth}
+	pen.rect(posX, bodyTop, width, bodyH, 0, style)
+	pen.ellipse(cx, bodyTop, width/2, ellipseRY, style)
+	pen.ellipse(cx, bodyTop+bodyH, width/2, ellipseRY, style)
+	// Cover where the body's top edge crosses the top cap.
+	pen.rect(posX+1, bodyTop, width-2, ellipseRY, 0, shapeStyle{fill: th.ActorBackground})
+}
+
+// isTransparentColor reports whether a CSS colour already carries
+// transparency (rgba, hsla, or the "transparent" keyword).
+func isTransparentColor(value string) bool {
+	lower := strings.ToLower(value)
+	return strings.HasPrefix(lower, "rgba") ||
+		strings.HasPrefix(lower, "hsla") ||
+		lower == "transparent"
+}
@@ -0,0 +1,142 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+)
+
+// Shape drawing constants, matching the SVG renderer.
+const (
+	roundRectRadius     float32 = 10
+	defaultRectRadius   float32 = 3
+	fallbackRectRadius  float32 = 6
+	subroutineRadius    float32 = 6
+	doubleCircleInset   float32 = 4
+	cylinderMinRY       float32 = 6
+	cylinderMaxRY       float32 = 14
+	cylinderEllipseRate float32 = 0.12
+	subroutineInset     float32 = 6
+	asymmetricSlant     float32 = 0.22
+	parallelogramSkew   float32 = 0.18
+	lineHeightScale     float32 = 1.2
+	textBaselineOffset  float32 = 0.75
+	hexagonLeft         float32 = 0.25
+	hexagonRight        float32 = 0.75
+	nodeFontSize        float32 = 14
+)
+
+// drawNodeShape draws a node's shape and its centred label. The node's X
+// and Y are its centre.
+func drawNodeShape(pen *painter, node *layout.NodeLayout, fill, stroke, textColor string) {
+	style := shapeStyle{fill: fill, stroke: stroke, width: 1, round: true}
+	if node.Style.StrokeWidth != nil {
+		style.width = *node.Style.StrokeWidth
+	}
+	if node.Style.StrokeDasharray != nil {
+		style.dash = *node.Style.StrokeDasharray
+	}
+
+	posX := node.X - node.Width/2
+	posY := node.Y - node.Height/2
+	width := node.Width
+	height := node.Height
+
+	switch node.Shape {
+	case ir.Rectangle, ir.ForkJoin, ir.ActorBox:
+		pen.rect(posX, posY, width, height, defaultRectRadius, style)
+	case ir.RoundRect:
+		pen.rect(posX, posY, width, height, roundRectRadius, style)
+	case ir.Stadium:
+		pen.rect(posX, posY, width, height, height/2, style)
+	case ir.Diamond:
+		cx, cy := posX+width/2, posY+height/2
+		pen.polygon([][2]float32{{cx, posY}, {posX + width, cy}, {cx, posY + height}, {posX, cy}}, style)
+	case ir.Hexagon:
+		leftX := posX + width*hexagonLeft
+		rightX := posX + width*hexagonRight
+		midY := posY + height/2
+		pen.polygon([][2]float32{
+			{leftX, posY}, {rightX, posY}, {posX + width, midY},
+			{rightX, posY + height}, {leftX, posY + height}, {posX, midY},
+		}, style)
+	case ir.Circle, ir.DoubleCircle:
+		radius := min(width, height) / 2
+		pen.circle(node.X, node.Y, radius, style)
+		if inner := radius - doubleCircleInset; node.Shape == ir.DoubleCircle && inner > 0 {
+			pen.circle(node.X, node.Y, inner, shapeStyle{stroke: stroke, width: 1, round: true})
+		}
+	case ir.Cylinder:
+		drawCylinder(pen, posX, posY, width, height, style)
+	case ir.Subroutine:
+		pen.rect(posX, posY, width, height, subroutineRadius, style)
+		lines := shapeStyle{stroke: stroke, width: style.width, round: true}
+		for _, lineX := range []float32{posX + subroutineInset, posX + width - subroutineInset} {
+			pen.line(lineX, posY+2, lineX, posY+height-2, lines)
+		}
+	case ir.Asymmetric:
+		slant := width * asymmetricSlant
+		pen.polygon([][2]float32{
+			{posX, posY}, {posX + width - slant, posY}, {posX + width, posY + height/2},
+			{posX + width - slant, posY + height}, {posX, posY + height},
+		}, style)
+	case ir.Parallelogram, ir.ParallelogramAlt:
+		offset := width * parallelogramSkew
+		if node.Shape == ir.Parallelogram {
+			pen.polygon([][2]float32{
+				{posX + offset, posY}, {posX + width, posY}, {posX + width - offset, posY + height}, {posX, posY + height},
+			}, style)
+		} else {
+			pen.polygon([][2]float32{
+				{posX, posY}, {posX + width - offset, posY}, {posX + width, posY + height}, {posX + offset, posY + height},
+			}, style)
+		}
+	case ir.Trapezoid, ir.TrapezoidAlt:
+		offset := width * parallelogramSkew
+		if node.Shape == ir.Trapezoid {
+			pen.polygon([][2]float32{
+				{posX + offset, posY}, {posX + width - offset, posY}, {posX + width, posY + height}, {posX, posY + height},
+			}, style)
+		} else {
+			pen.polygon([][2]float32{
+				{posX, posY}, {posX + width, posY}, {posX + width - offset, posY + height}, {posX + offset, posY + height},
+			}, style)
+		}
+	default:
+		pen.rect(posX, posY, width, height, fallbackRectRadius, style)
+	}
+
+	drawNodeLabel(pen, node, textColor)
+}
+
+// drawCylinder draws a cylinder as a filled top ellipse, a body and the
+// outline of the bottom ellipse.
+func drawCylinder(pen *painter, posX, posY, width, height float32, style shapeStyle) {
+	ry := min(max(height*cylinderEllipseRate, cylinderMinRY), cylinderMaxRY)
+	rx := width / 2
+	cx := posX + rx
+	pen.ellipse(cx, posY+ry, rx, ry, style)
+	pen.rect(posX, posY+ry, width, max(height-2*ry, 0), 0, style)
+	bottom := style
+	bottom.fill = ""
+	pen.ellipse(cx, posY+height-ry, rx, ry, bottom)
+}
+
+// drawNodeLabel draws a node's label lines centred in the node.
+func drawNodeLabel(pen *painter, node *layout.NodeLayout, textColor string) {
+	if len(node.Label.Lines) == 0 {
+		return
+	}
+	fontSize := node.Label.FontSize
+	if fontSize <= 0 {
+		fontSize = nodeFontSize
+	}
+	lineHeight := fontSize * lineHeightScale
+	startY := node.Y - lineHeight*float32(len(node.Label.Lines))/2 + lineHeight*textBaselineOffset
+	for idx, line := range node.Label.Lines {
+		pen.label(node.X, startY+float32(idx)*lineHeight, line, textStyle{
+			size:   fontSize,
+			color:  textColor,
+			anchor: "middle",
+		})
+	}
+}
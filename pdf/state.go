@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// State diagram drawing constants, matching the SVG renderer.
+const (
+	stateEndInnerScale   float32 = 0.6
+	stateCompositeRadius float32 = 8
+	stateForkJoinRadius  float32 = 2
+	stateLabelOffsetX    float32 = 10
+	stateLabelPadY       float32 = 4
+	stateRegularRadius   float32 = 10
+	stateLineHeightScale float32 = 1.2
+	stateNamePadY        float32 = 4
+	stateDividerPadY     float32 = 6
+	stateDividerInsetX   float32 = 4
+	stateDividerWidth    float32 = 0.5
+	stateDescFontScale   float32 = 0.9
+	stateBorderWidth     float32 = 1.5
+	stateCompositeDash           = "5,5"
+)
+
+// drawState draws transitions, then states.
+func drawState(pen *painter, computed *layout.Layout, data layout.StateData, th *theme.Theme, cfg *config.Layout) {
+	drawEdges(pen, computed, th, func(*layout.EdgeLayout, bool) markerKind { return markerArrow })
+	drawStateNodes(pen, computed, data, th, cfg)
+}
+
+// drawStateNodes draws the states of a layout in ID order: pseudo-states,
+// composites with their inner layouts, and regular states.
+func drawStateNodes(pen *painter, computed *layout.Layout, data layout.StateData, th *theme.Theme, cfg *config.Layout) {
+	for _, id := range sortedNodeIDs(computed) {
+		node := computed.Nodes[id]
+		switch {
+		case strings.HasPrefix(id, "__start__"):
+			pen.circle(node.X, node.Y, node.Width/2, shapeStyle{fill: th.StateStartEnd, stroke: th.StateStartEnd})
+			continue
+		case strings.HasPrefix(id, "__end__"):
+			outer := node.Width / 2
+			pen.circle(node.X, node.Y, outer, shapeStyle{stroke: th.StateStartEnd, width: stateBorderWidth})
+			pen.circle(node.X, node.Y, outer*stateEndInnerScale, shapeStyle{fill: th.StateStartEnd, stroke: th.StateStartEnd})
+			continue
+		}
+
+		if annotation, ok := data.Annotations[id]; ok {
+			switch annotation {
+			case ir.StateFork, ir.StateJoin:
+				pen.rect(node.X-node.Width/2, node.Y-node.Height/2, node.Width, node.Height, stateForkJoinRadius,
+					shapeStyle{fill: th.StateStartEnd, stroke: th.StateStartEnd})
+				continue
+			case ir.StateChoice:
+				halfW, halfH := node.Width/2, node.Height/2
+				pen.polygon([][2]float32{
+					{node.X, node.Y - halfH}, {node.X + halfW, node.Y}, {node.X, node.Y + halfH}, {node.X - halfW, node.Y},
+				}, shapeStyle{fill: th.StateFill, stroke: th.StateBorder, width: stateBorderWidth})
+				continue
+			}
+		}
+
+		if composite, ok := data.CompositeStates[id]; ok {
+			if inner, hasInner := data.InnerLayouts[id]; hasInner {
+				drawCompositeState(pen, node, inner, composite.Label, th, cfg)
+				continue
+			}
+		}
+		drawRegularState(pen, node, data.Descriptions[id], th)
+	}
+}
+
+// drawCompositeState draws a composite state's dashed container and label,
+// then its inner layout below the label.
+func drawCompositeState(pen *painter, node *layout.NodeLayout, inner *layout.Layout, label string, th *theme.Theme, cfg *config.Layout) {
+	posX := node.X - node.Width/2
+	posY := node.Y - node.Height/2
+	pen.rect(posX, posY, node.Width, node.Height, stateCompositeRadius, shapeStyle{
+		fill:   th.ClusterBackground,
+		stroke: th.StateBorder,
+		width:  stateBorderWidth,
+		dash:   stateCompositeDash,
+	})
+	pen.label(posX+stateLabelOffsetX, posY+th.FontSize+stateLabelPadY, label, textStyle{
+		size:  th.FontSize,
+		color: th.TextColor,
+		bold:  true,
+	})
+
+	labelAreaH := th.FontSize*cfg.LabelLineHeight + cfg.Padding.NodeVertical
+	moved := pen.translate(posX+cfg.Padding.NodeHorizontal, posY+labelAreaH)
+	drawEdges(moved, inner, th, func(*layout.EdgeLayout, bool) markerKind { return markerArrow })
+	if data, ok := inner.Diagram.(layout.StateData); ok {
+		drawStateNodes(moved, inner, data, th, cfg)
+	} else {
+		drawNodes(moved, inner, th, cfg)
+	}
+}
+
+// drawRegularState draws a state as a rounded box with its name, and any
+// description below a divider.
+func drawRegularState(pen *painter, node *layout.NodeLayout, description string, th *theme.Theme) {
+	posX := node.X - node.Width/2
+	posY := node.Y - node.Height/2
+	pen.rect(posX, posY, node.Width, node.Height, stateRegularRadius,
+		shapeStyle{fill: th.StateFill, stroke: th.StateBorder, width: stateBorderWidth})
+
+	if description == "" || len(node.Label.Lines) == 0 {
+		drawNodeLabel(pen, node, th.TextColor)
+		return
+	}
+	fontSize := node.Label.FontSize
+	if fontSize <= 0 {
+		fontSize = th.FontSize
+	}
+	lineHeight := fontSize * stateLineHeightScale
+	nameY := posY + lineHeight + stateNamePadY
+	pen.label(node.X, nameY, node.Label.Lines[0], textStyle{
+		size:   fontSize,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+	dividerY := nameY + stateDividerPadY
+	pen.line(posX+stateDividerInsetX, dividerY, posX+node.Width-stateDividerInsetX, dividerY,
+		shapeStyle{stroke: th.StateBorder, width: stateDividerWidth})
+	pen.label(node.X, dividerY+lineHeight, description, textStyle{
+		size:   fontSize * stateDescFontScale,
+		color:  th.TextColor,
+		anchor: "middle",
+	})
+}
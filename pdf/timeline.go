@@ -0,0 +1,68 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Timeline drawing constants, matching the SVG renderer.
+const (
+	timelineTitlePadding    float32 = 5
+	timelineTitleGrow       float32 = 2
+	timelineSectionRadius   float32 = 4
+	timelineSectionLabelOff float32 = 10
+	timelinePeriodLabelGap  float32 = 4
+	timelinePeriodWidth     float32 = 0.5
+	timelinePeriodOpacity   float32 = 0.3
+	timelinePeriodShrink    float32 = 1
+	timelineEventPadding    float32 = 4
+	timelineEventInset      float32 = 2
+	timelineEventRadius     float32 = 12
+	timelineEventShrink     float32 = 2
+	timelineEventTextColor          = "#FFFFFF"
+)
+
+// drawTimeline draws the title, then each section with its periods and
+// their events.
+func drawTimeline(pen *painter, computed *layout.Layout, data layout.TimelineData, th *theme.Theme) {
+	pen.label(computed.Width/2, th.FontSize+timelineTitlePadding, data.Title, textStyle{
+		size:   th.FontSize + timelineTitleGrow,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	for _, sec := range data.Sections {
+		pen.rect(sec.X, sec.Y, sec.Width, sec.Height, timelineSectionRadius, shapeStyle{fill: sec.Color})
+		pen.label(sec.X+timelineSectionLabelOff, sec.Y+sec.Height/2, sec.Title, textStyle{
+			size:     th.FontSize,
+			color:    th.TextColor,
+			baseline: "middle",
+			bold:     true,
+		})
+
+		for _, period := range sec.Periods {
+			pen.rect(period.X, period.Y, period.Width, period.Height, 0, shapeStyle{
+				stroke:        th.TimelineEventBorder,
+				width:         timelinePeriodWidth,
+				strokeOpacity: timelinePeriodOpacity,
+			})
+			pen.label(period.X+period.Width/2, period.Y-timelinePeriodLabelGap, period.Title, textStyle{
+				size:   th.FontSize - timelinePeriodShrink,
+				color:  th.TextColor,
+				anchor: "middle",
+				bold:   true,
+			})
+			for _, event := range period.Events {
+				pen.rect(event.X, event.Y+timelineEventInset, event.Width, event.Height-timelineEventPadding, timelineEventRadius,
+					shapeStyle{fill: th.TimelineEventFill, stroke: th.TimelineEventBorder})
+				pen.label(event.X+event.Width/2, event.Y+event.Height/2, event.Text, textStyle{
+					size:     th.FontSize - timelineEventShrink,
+					color:    timelineEventTextColor,
+					anchor:   "middle",
+					baseline: "middle",
+				})
+			}
+		}
+	}
+}
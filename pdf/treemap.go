@@ -0,0 +1,106 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// Treemap drawing constants, matching the SVG renderer.
+const (
+	treemapTitleOffsetY     float32 = 20
+	treemapTitleFontSize    float32 = 16
+	treemapSectionLabelPadX float32 = 4
+	treemapSectionLabelPadY float32 = 6
+	// treemapSectionTotalMinWidth is the narrowest section header that
+	// shows the section's total beside its name.
+	treemapSectionTotalMinWidth float32 = 60
+	treemapBorderRadius         float32 = 2
+	treemapHeaderOpacity        float32 = 0.3
+	treemapValueOpacity         float32 = 0.7
+	treemapLabelMinWidth        float32 = 20
+	treemapLabelMinHeight       float32 = 14
+	treemapValueMinHeight       float32 = 30
+	treemapValueGap             float32 = 2
+)
+
+// treemapFallbackColors are the palette when the theme has none.
+var treemapFallbackColors = []string{"#4C78A8", "#72B7B2", "#EECA3B", "#F58518"}
+
+// drawTreemap draws the title, then sections as framed boxes with a
+// header bar and leaves as filled boxes with their label and value.
+func drawTreemap(pen *painter, computed *layout.Layout, data layout.TreemapData, th *theme.Theme, cfg *config.Layout) {
+	colors := th.TreemapColors
+	if len(colors) == 0 {
+		colors = treemapFallbackColors
+	}
+	pen.label(computed.Width/2, treemapTitleOffsetY, data.Title, textStyle{
+		size:   treemapTitleFontSize,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	// Classes of a node override its palette colours.
+	for _, rect := range data.Rects {
+		color := colors[rect.ColorIndex%len(colors)]
+		stroke := th.TreemapBorder
+		if rect.Style.Stroke != nil {
+			stroke = *rect.Style.Stroke
+		}
+
+		if rect.IsSection {
+			drawTreemapSection(pen, rect, color, stroke, th, cfg)
+			continue
+		}
+
+		if rect.Style.Fill != nil {
+			color = *rect.Style.Fill
+		}
+		textColor := th.TreemapTextColor
+		if rect.Style.TextColor != nil {
+			textColor = *rect.Style.TextColor
+		}
+		pen.rect(rect.X, rect.Y, rect.Width, rect.Height, treemapBorderRadius, shapeStyle{fill: color, stroke: stroke})
+
+		// Only label leaves large enough to hold it.
+		if rect.Width <= treemapLabelMinWidth || rect.Height <= treemapLabelMinHeight {
+			continue
+		}
+		cx := rect.X + rect.Width/2
+		cy := rect.Y + rect.Height/2
+		pen.label(cx, cy, rect.Label, textStyle{size: cfg.Treemap.LabelFontSize, color: textColor, anchor: "middle"})
+		if rect.Height > treemapValueMinHeight && rect.Value > 0 {
+			pen.label(cx, cy+cfg.Treemap.ValueFontSize+treemapValueGap, rect.ValueLabel, textStyle{
+				size:    cfg.Treemap.ValueFontSize,
+				color:   textColor,
+				anchor:  "middle",
+				opacity: treemapValueOpacity,
+			})
+		}
+	}
+}
+
+// drawTreemapSection draws a section's frame and its header bar holding
+// the section's name and total.
+func drawTreemapSection(pen *painter, rect layout.TreemapRectLayout, color, stroke string, th *theme.Theme, cfg *config.Layout) {
+	pen.rect(rect.X, rect.Y, rect.Width, rect.Height, treemapBorderRadius, shapeStyle{stroke: stroke})
+	headerH := min(cfg.Treemap.HeaderHeight, rect.Height)
+	header := shapeStyle{fill: color, opacity: treemapHeaderOpacity}
+	if rect.Style.Fill != nil {
+		header = shapeStyle{fill: *rect.Style.Fill}
+	}
+	pen.rect(rect.X, rect.Y, rect.Width, headerH, 0, header)
+
+	textColor := th.TextColor
+	if rect.Style.TextColor != nil {
+		textColor = *rect.Style.TextColor
+	}
+	labelY := rect.Y + headerH - treemapSectionLabelPadY
+	pen.label(rect.X+treemapSectionLabelPadX, labelY, rect.Label,
+		textStyle{size: cfg.Treemap.LabelFontSize, color: textColor, bold: true})
+	if rect.Width > treemapSectionTotalMinWidth {
+		pen.label(rect.X+rect.Width-treemapSectionLabelPadX, labelY, rect.ValueLabel,
+			textStyle{size: cfg.Treemap.ValueFontSize, color: textColor, anchor: "end"})
+	}
+}
@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// page is a single-page document ready to serialize.
+type page struct {
+	width, height float64 // points
+	content       *content
+	links         []link
+	title         string
+}
+
+// writer serializes numbered PDF objects and tracks their offsets for the
+// cross-reference table.
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int // offsets[n-1] is the position of object n
+}
+
+// reserve allocates an object number to be written later.
+func (w *writer) reserve() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+// object writes a dictionary or other value as object num.
+func (w *writer) object(num int, body string) {
+	w.offsets[num-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", num, body)
+}
+
+// stream writes a Flate-compressed stream as object num, with extra
+// dictionary entries.
+func (w *writer) stream(num int, extra string, data []byte) {
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	// Writes to a bytes.Buffer cannot fail.
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	w.offsets[num-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode%s >>\nstream\n", num, packed.Len(), extra)
+	w.buf.Write(packed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// ref formats an indirect reference.
+func ref(num int) string {
+	return strconv.Itoa(num) + " 0 R"
+}
+
+// bytes serializes the page as a complete PDF file.
+func (p *page) bytes() []byte {
+	var out writer
+	// The binary comment marks the file as containing 8-bit data.
+	out.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	catalog := out.reserve()
+	pages := out.reserve()
+	pageObj := out.reserve()
+	info := out.reserve()
+
+	// Flip to a top-left origin with the Y axis down, matching SVG.
+	stream := "1 0 0 -1 0 " + num(p.height) + " cm\n" + p.content.buf.String()
+	contentObj := out.reserve()
+	out.stream(contentObj, "", []byte(stream))
+
+	var resources strings.Builder
+	resources.WriteString("<< /ProcSet [/PDF /Text]")
+	if len(p.content.fonts) > 0 {
+		resources.WriteString(" /Font <<")
+		for _, fnt := range p.content.fonts {
+			fmt.Fprintf(&resources, " /%s %s", fnt.name, ref(p.writeFont(&out, fnt)))
+		}
+		resources.WriteString(" >>")
+	}
+	if len(p.content.alphas) > 0 {
+		resources.WriteString(" /ExtGState <<")
+		for idx, alpha := range p.content.alphas {
+			level := num(float64(alpha) / channelMax)
+			fmt.Fprintf(&resources, " /%s << /Type /ExtGState /ca %s /CA %s >>", alphaName(idx), level, level)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	annots := ""
+	if len(p.links) > 0 {
+		refs := make([]string, 0, len(p.links))
+		for _, lnk := range p.links {
+			annot := out.reserve()
+			out.object(annot, p.annotation(lnk))
+			refs = append(refs, ref(annot))
+		}
+		annots = " /Annots [" + strings.Join(refs, " ") + "]"
+	}
+
+	out.object(catalog, "<< /Type /Catalog /Pages "+ref(pages)+" >>")
+	out.object(pages, "<< /Type /Pages /Kids ["+ref(pageObj)+"] /Count 1 >>")
+	out.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [0 0 %s %s] /Resources %s /Contents %s%s >>",
+		ref(pages), num(p.width), num(p.height), resources.String(), ref(contentObj), annots))
+	infoDict := "<< /Producer (gomd2svg)"
+	if p.title != "" {
+		infoDict += " /Title " + textString(p.title)
+	}
+	out.object(info, infoDict+" >>")
+
+	xref := out.buf.Len()
+	fmt.Fprintf(&out.buf, "xref\n0 %d\n0000000000 65535 f \n", len(out.offsets)+1)
+	for _, offset := range out.offsets {
+		fmt.Fprintf(&out.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out.buf, "trailer\n<< /Size %d /Root %s /Info %s >>\nstartxref\n%d\n%%%%EOF\n",
+		len(out.offsets)+1, ref(catalog), ref(info), xref)
+	return out.buf.Bytes()
+}
+
+// writeFont writes the objects of an embedded font and returns the
+// number of its Type0 font dictionary.
+func (p *page) writeFont(out *writer, fnt *font) int {
+	file := out.reserve()
+	out.stream(file, " /Length1 "+strconv.Itoa(len(fnt.face.Data)), fnt.face.Data)
+	descriptor := out.reserve()
+	out.object(descriptor, "<< "+fnt.descriptor()+" /FontFile2 "+ref(file)+" >>")
+	cidFont := out.reserve()
+	out.object(cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %s /CIDToGIDMap /Identity /W %s >>",
+		fnt.baseFont(), ref(descriptor), fnt.widthArray()))
+	cmap := out.reserve()
+	out.stream(cmap, "", []byte(fnt.toUnicode()))
+	type0 := out.reserve()
+	out.object(type0, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+		"/DescendantFonts [%s] /ToUnicode %s >>", fnt.baseFont(), ref(cidFont), ref(cmap)))
+	return type0
+}
+
+// annotation returns the link annotation dictionary for a link region.
+// Annotation rectangles are in default user space, with the Y axis up.
+func (p *page) annotation(lnk link) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI %s >>",
+		num(lnk.minX), num(p.height-lnk.maxY), num(lnk.maxX), num(p.height-lnk.minY), literal(lnk.url))
+	if lnk.title != "" {
+		out.WriteString(" /Contents " + textString(lnk.title))
+	}
+	out.WriteString(" >>")
+	return out.String()
+}
+
+// literal formats an ASCII string as a PDF literal string.
+func literal(value string) string {
+	return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`).Replace(value) + ")"
+}
+
+// textString formats a text string, using UTF-16 when it is not ASCII.
+func textString(value string) string {
+	for _, ch := range value {
+		if ch > '~' {
+			var out strings.Builder
+			out.WriteString("<FEFF")
+			for _, unit := range utf16.Encode([]rune(value)) {
+				fmt.Fprintf(&out, "%04X", unit)
+			}
+			out.WriteString(">")
+			return out.String()
+		}
+	}
+	return literal(value)
+}
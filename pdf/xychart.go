@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+// XY chart drawing constants, matching the SVG renderer.
+const (
+	xyTickLabelPad    float32 = 4
+	xyGridWidth       float32 = 0.5
+	xySeriesLineWidth float32 = 2
+	xyPointRadius     float32 = 3
+	xyFallbackColor           = "#4C78A8"
+	xyGridFallback            = "#E0E0E0"
+	xyAxisFallback            = "#333"
+)
+
+// drawXYChart draws the title, grid and axes with their labels, the
+// series, data labels and the legend.
+func drawXYChart(pen *painter, computed *layout.Layout, data layout.XYChartData, th *theme.Theme, cfg *config.Layout) {
+	axisText := textStyle{size: cfg.XYChart.AxisFontSize, color: th.TextColor}
+	pen.label(computed.Width/2, cfg.XYChart.TitleFontSize, data.Title, textStyle{
+		size:   cfg.XYChart.TitleFontSize,
+		color:  th.TextColor,
+		anchor: "middle",
+		bold:   true,
+	})
+
+	cx, cy := data.ChartX, data.ChartY
+	cw, ch := data.ChartWidth, data.ChartHeight
+	gridColor := th.XYChartGridColor
+	if gridColor == "" {
+		gridColor = xyGridFallback
+	}
+	axisColor := th.XYChartAxisColor
+	if axisColor == "" {
+		axisColor = xyAxisFallback
+	}
+
+	tickText := axisText
+	tickText.anchor = "end"
+	for _, tick := range data.YTicks {
+		pen.line(cx, tick.Y, cx+cw, tick.Y, shapeStyle{stroke: gridColor, width: xyGridWidth})
+		pen.label(cx-xyTickLabelPad, tick.Y+xyTickLabelPad, tick.Label, tickText)
+	}
+	for _, tick := range data.Y2Ticks {
+		pen.label(cx+cw+xyTickLabelPad, tick.Y+xyTickLabelPad, tick.Label, axisText)
+	}
+
+	axis := shapeStyle{stroke: axisColor}
+	pen.line(cx, cy, cx, cy+ch, axis)
+	pen.line(cx, cy+ch, cx+cw, cy+ch, axis)
+	if data.HasY2 {
+		pen.line(cx+cw, cy, cx+cw, cy+ch, axis)
+	}
+
+	xText := axisText
+	xText.anchor = "middle"
+	for _, label := range data.XLabels {
+		pen.label(label.X, cy+ch+cfg.XYChart.AxisFontSize+xyTickLabelPad, label.Text, xText)
+	}
+
+	for _, series := range data.Series {
+		color := xySeriesColor(th, series.ColorIndex)
+		switch series.Type {
+		case ir.XYSeriesBar:
+			for _, point := range series.Points {
+				pen.rect(point.X, point.Y, point.Width, point.Height, 0, shapeStyle{fill: color})
+			}
+		case ir.XYSeriesLine:
+			points := make([][2]float32, 0, len(series.Points))
+			for _, point := range series.Points {
+				points = append(points, [2]float32{point.X, point.Y})
+			}
+			pen.polyline(points, shapeStyle{stroke: color, width: xySeriesLineWidth})
+			for _, point := range series.Points {
+				pen.circle(point.X, point.Y, xyPointRadius, shapeStyle{fill: color, stroke: th.Background})
+			}
+		}
+	}
+
+	// Data labels come after every series so lines never cover them.
+	dataText := axisText
+	dataText.anchor, dataText.baseline = "middle", "middle"
+	for _, series := range data.Series {
+		for _, point := range series.Points {
+			pen.label(point.LabelX, point.LabelY, point.Label, dataText)
+		}
+	}
+
+	drawXYLegend(pen, data, th, axisText)
+}
+
+// drawXYLegend draws a key and name for each legend entry: a filled
+// square for bar series and a short line with a point for line series.
+func drawXYLegend(pen *painter, data layout.XYChartData, th *theme.Theme, text textStyle) {
+	text.baseline = "middle"
+	for _, entry := range data.Legend {
+		color := xySeriesColor(th, entry.ColorIndex)
+		midY := entry.Y + data.LegendKey/2
+		if entry.Type == ir.XYSeriesLine {
+			pen.line(entry.X, midY, entry.X+data.LegendKey, midY, shapeStyle{stroke: color, width: xySeriesLineWidth})
+			pen.circle(entry.X+data.LegendKey/2, midY, xyPointRadius, shapeStyle{fill: color})
+		} else {
+			pen.rect(entry.X, entry.Y, data.LegendKey, data.LegendKey, 0, shapeStyle{fill: color})
+		}
+		pen.label(entry.TextX, midY, entry.Text, text)
+	}
+}
+
+// xySeriesColor returns the theme colour for a series.
+func xySeriesColor(th *theme.Theme, colorIndex int) string {
+	if len(th.XYChartColors) == 0 {
+		return xyFallbackColor
+	}
+	return th.XYChartColors[colorIndex%len(th.XYChartColors)]
+}
@@ -0,0 +1,59 @@
+package printer
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printArchitecture writes an architecture diagram: groups, then services
+// and junctions in declaration order, then edges.
+func printArchitecture(out *writer, graph *ir.Graph) {
+	out.line("architecture-beta")
+	out.indent()
+	for _, grp := range graph.ArchGroups {
+		out.line("group ", grp.ID, archIcon(grp.Icon), "[", grp.Label, "]", archIn(grp.ParentID))
+	}
+
+	services := make(map[string]*ir.ArchService, len(graph.ArchServices))
+	for _, svc := range graph.ArchServices {
+		services[svc.ID] = svc
+	}
+	junctions := make(map[string]*ir.ArchJunction, len(graph.ArchJunctions))
+	for _, junc := range graph.ArchJunctions {
+		junctions[junc.ID] = junc
+	}
+	for _, id := range sortedNodeIDs(graph) {
+		if svc, ok := services[id]; ok {
+			out.line("service ", svc.ID, archIcon(svc.Icon), "[", svc.Label, "]", archIn(svc.GroupID))
+		} else if junc, ok := junctions[id]; ok {
+			out.line("junction ", junc.ID, archIn(junc.GroupID))
+		}
+	}
+
+	for _, edge := range graph.ArchEdges {
+		arrow := "--"
+		if edge.ArrowLeft {
+			arrow = "<" + arrow
+		}
+		if edge.ArrowRight {
+			arrow += ">"
+		}
+		out.line(edge.FromID, ":", edge.FromSide.String(), " ", arrow, " ", edge.ToSide.String(), ":", edge.ToID)
+	}
+	out.dedent()
+}
+
+// archIcon writes an icon in parentheses, or nothing.
+func archIcon(icon string) string {
+	if icon == "" {
+		return ""
+	}
+	return "(" + icon + ")"
+}
+
+// archIn writes the " in group" suffix, or nothing.
+func archIn(groupID string) string {
+	if groupID == "" {
+		return ""
+	}
+	return " in " + groupID
+}
@@ -0,0 +1,28 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintArchitectureRoundTrip(t *testing.T) {
+	out := roundTrip(t, `architecture-beta
+  group api(cloud)[API]
+  service db(database)[Database] in api
+  junction mid in api
+  service web[Web]
+  db:R -- L:mid
+  mid:R <--> L:web`)
+	for _, want := range []string{
+		"group api(cloud)[API]",
+		"service db(database)[Database] in api",
+		"junction mid in api",
+		"service web[Web]",
+		"db:R -- L:mid",
+		"mid:R <--> L:web",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package printer
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printBlock writes a block diagram: column count, blocks one per line
+// with composite blocks nested, then edges.
+func printBlock(out *writer, graph *ir.Graph) {
+	out.line("block-beta")
+	out.indent()
+	if graph.BlockColumns > 0 {
+		out.line("columns ", strconv.Itoa(graph.BlockColumns))
+	}
+	anonymous := 0
+	printBlockDefs(out, graph.Blocks, &anonymous)
+	for _, edge := range graph.Edges {
+		arrow := "---"
+		if edge.Directed {
+			arrow = "-->"
+		}
+		if edge.Label != nil {
+			out.line(edge.From, ` -- "`, *edge.Label, `" `, arrow, " ", edge.To)
+		} else {
+			out.line(edge.From, " ", arrow, " ", edge.To)
+		}
+	}
+	out.dedent()
+}
+
+// printBlockDefs writes blocks in order. Composite blocks whose ID the
+// parser generated are written without one, so it generates the same ID.
+func printBlockDefs(out *writer, blocks []*ir.BlockDef, anonymous *int) {
+	for _, block := range blocks {
+		span := ""
+		if block.Width > 1 {
+			span = ":" + strconv.Itoa(block.Width)
+		}
+		switch {
+		case block.Kind == ir.BlockSpace:
+			out.line("space", span)
+		case block.Kind == ir.BlockArrow:
+			out.line(block.ID, `<["`, block.Label, `"]>(`, block.ArrowDir.String(), ")", span)
+		case len(block.Children) > 0 || block.Columns > 0:
+			header := "block"
+			if block.ID == "block"+strconv.Itoa(*anonymous+1) {
+				*anonymous++
+			} else {
+				header += ":" + block.ID
+			}
+			if block.Label != "" {
+				header += `["` + block.Label + `"]`
+			}
+			out.line(header, span)
+			out.indent()
+			if block.Columns > 0 {
+				out.line("columns ", strconv.Itoa(block.Columns))
+			}
+			printBlockDefs(out, block.Children, anonymous)
+			out.dedent()
+			out.line("end")
+		default:
+			out.line(blockShapeText(block), span)
+		}
+	}
+}
+
+// blockShapeText writes a block ID with its shape and label, the inverse
+// of blockDefRe.
+func blockShapeText(block *ir.BlockDef) string {
+	label := `"` + block.Label + `"`
+	switch block.Shape {
+	case ir.Cylinder:
+		return block.ID + "[(" + label + ")]"
+	case ir.RoundRect:
+		return block.ID + "(" + label + ")"
+	case ir.Circle:
+		return block.ID + "((" + label + "))"
+	case ir.Diamond:
+		return block.ID + "{" + label + "}"
+	case ir.Asymmetric:
+		return block.ID + ">[" + label + "]"
+	default:
+		if block.Label == block.ID {
+			return block.ID
+		}
+		return block.ID + "[" + label + "]"
+	}
+}
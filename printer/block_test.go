@@ -0,0 +1,35 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintBlockRoundTrip(t *testing.T) {
+	out := roundTrip(t, `block-beta
+columns 3
+a b["Label"]:2
+block
+  c(("Round")) space:2
+end
+block:named["Named"]
+  d arrow<["go"]>(right)
+end
+a -- "calls" --> c
+a --- d`)
+	for _, want := range []string{
+		"    a\n",
+		`b["Label"]:2`,
+		"    block\n",
+		`c(("Round"))`,
+		"space:2",
+		`block:named["Named"]`,
+		`arrow<["go"]>(right)`,
+		`a -- "calls" --> c`,
+		"a --- d",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package printer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printC4 writes a C4 diagram: elements nested in their boundaries,
+// relationships, then style and layout updates.
+func printC4(out *writer, graph *ir.Graph) {
+	out.line(graph.C4SubKind.String())
+	out.indent()
+	printC4Scope(out, graph, "")
+
+	for _, rel := range graph.C4Rels {
+		out.line(c4RelMacro(rel), "(", rel.From, ", ", c4Args(rel.To, rel.Label, rel.Technology, rel.Description), ")")
+	}
+
+	printC4Styles(out, "UpdateElementStyle", graph.C4ElementStyles)
+	styled := make(map[[2]string]bool)
+	for _, rel := range graph.C4Rels {
+		key := [2]string{rel.From, rel.To}
+		if styled[key] || (rel.TextColor == "" && rel.LineColor == "" && rel.OffsetX == 0 && rel.OffsetY == 0) {
+			continue
+		}
+		styled[key] = true
+		args := []string{rel.From, rel.To}
+		args = appendC4Named(args, "textColor", rel.TextColor)
+		args = appendC4Named(args, "lineColor", rel.LineColor)
+		if rel.OffsetX != 0 {
+			args = appendC4Named(args, "offsetX", strconv.FormatFloat(float64(rel.OffsetX), 'f', -1, 32))
+		}
+		if rel.OffsetY != 0 {
+			args = appendC4Named(args, "offsetY", strconv.FormatFloat(float64(rel.OffsetY), 'f', -1, 32))
+		}
+		out.line("UpdateRelStyle(", strings.Join(args, ", "), ")")
+	}
+	printC4Styles(out, "UpdateBoundaryStyle", graph.C4BoundaryStyles)
+
+	if graph.C4ShapeInRow > 0 || graph.C4BoundaryInRow > 0 {
+		var args []string
+		if graph.C4ShapeInRow > 0 {
+			args = appendC4Named(args, "c4ShapeInRow", strconv.Itoa(graph.C4ShapeInRow))
+		}
+		if graph.C4BoundaryInRow > 0 {
+			args = appendC4Named(args, "c4BoundaryInRow", strconv.Itoa(graph.C4BoundaryInRow))
+		}
+		out.line("UpdateLayoutConfig(", strings.Join(args, ", "), ")")
+	}
+	out.dedent()
+}
+
+// c4Item is an element or a boundary inside one boundary scope.
+type c4Item struct {
+	element  *ir.C4Element
+	boundary *ir.C4Boundary
+	order    int
+}
+
+// printC4Scope writes the elements and boundaries directly inside the
+// boundary parentID ("" for the top level). A boundary is written where
+// its first element was declared, so elements keep their original order.
+func printC4Scope(out *writer, graph *ir.Graph, parentID string) {
+	elementOrder := make(map[string]int, len(graph.C4Elements))
+	for idx, elem := range graph.C4Elements {
+		elementOrder[elem.ID] = idx
+	}
+
+	var items []c4Item
+	for _, elem := range graph.C4Elements {
+		if elem.BoundaryID == parentID {
+			items = append(items, c4Item{element: elem, order: elementOrder[elem.ID]})
+		}
+	}
+	for _, boundary := range graph.C4Boundaries {
+		if boundary.ParentID == parentID {
+			items = append(items, c4Item{boundary: boundary, order: c4FirstElement(graph, boundary.ID, elementOrder)})
+		}
+	}
+	sort.SliceStable(items, func(idxA, idxB int) bool {
+		return items[idxA].order < items[idxB].order
+	})
+
+	for _, item := range items {
+		if item.element != nil {
+			elem := item.element
+			if elem.Type.IsPerson() {
+				out.line(elem.Type.String(), "(", c4Args(elem.ID, elem.Label, elem.Description), ")")
+			} else {
+				out.line(elem.Type.String(), "(", c4Args(elem.ID, elem.Label, elem.Technology, elem.Description), ")")
+			}
+			continue
+		}
+		boundary := item.boundary
+		macro, args := c4BoundaryMacro(boundary)
+		out.line(macro, "(", args, ") {")
+		out.indent()
+		printC4Scope(out, graph, boundary.ID)
+		out.dedent()
+		out.line("}")
+	}
+}
+
+// c4FirstElement returns the declaration index of the first element in a
+// boundary or its nested boundaries; empty boundaries sort last.
+func c4FirstElement(graph *ir.Graph, boundaryID string, elementOrder map[string]int) int {
+	first := len(graph.C4Elements)
+	for _, elem := range graph.C4Elements {
+		if elem.BoundaryID == boundaryID {
+			first = min(first, elementOrder[elem.ID])
+		}
+	}
+	for _, boundary := range graph.C4Boundaries {
+		if boundary.ParentID == boundaryID {
+			first = min(first, c4FirstElement(graph, boundary.ID, elementOrder))
+		}
+	}
+	return first
+}
+
+// c4BoundaryMacro returns the macro name and arguments declaring a boundary.
+func c4BoundaryMacro(boundary *ir.C4Boundary) (string, string) {
+	if boundary.Deployment {
+		return "Deployment_Node", c4Args(boundary.ID, boundary.Label, boundary.Type, boundary.Description)
+	}
+	switch boundary.Type {
+	case "Enterprise":
+		return "Enterprise_Boundary", c4Args(boundary.ID, boundary.Label)
+	case "Software System":
+		return "System_Boundary", c4Args(boundary.ID, boundary.Label)
+	case "Container":
+		return "Container_Boundary", c4Args(boundary.ID, boundary.Label)
+	}
+	return "Boundary", c4Args(boundary.ID, boundary.Label, boundary.Type)
+}
+
+// c4RelMacro returns the macro name for a relationship, the inverse of
+// parseC4RelDirection.
+func c4RelMacro(rel *ir.C4Rel) string {
+	if rel.Bidirectional {
+		return "BiRel"
+	}
+	switch rel.Direction {
+	case ir.C4RelUp:
+		return "Rel_U"
+	case ir.C4RelDown:
+		return "Rel_D"
+	case ir.C4RelLeft:
+		return "Rel_L"
+	case ir.C4RelRight:
+		return "Rel_R"
+	case ir.C4RelNone:
+	}
+	return "Rel"
+}
+
+// c4Args joins macro arguments: the ID bare and the rest quoted, dropping
+// trailing empty arguments.
+func c4Args(id string, rest ...string) string {
+	for len(rest) > 0 && rest[len(rest)-1] == "" {
+		rest = rest[:len(rest)-1]
+	}
+	args := []string{id}
+	for _, arg := range rest {
+		args = append(args, `"`+arg+`"`)
+	}
+	return strings.Join(args, ", ")
+}
+
+// appendC4Named appends a $name="value" argument when value is set.
+func appendC4Named(args []string, name, value string) []string {
+	if value == "" {
+		return args
+	}
+	return append(args, "$"+name+`="`+value+`"`)
+}
+
+// printC4Styles writes one Update*Style statement per styled subject,
+// sorted by subject.
+func printC4Styles(out *writer, statement string, styles map[string]*ir.C4Style) {
+	subjects := make([]string, 0, len(styles))
+	for subject := range styles {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		style := styles[subject]
+		args := []string{subject}
+		args = appendC4Named(args, "bgColor", style.BgColor)
+		args = appendC4Named(args, "fontColor", style.FontColor)
+		args = appendC4Named(args, "borderColor", style.BorderColor)
+		args = appendC4Named(args, "shape", style.Shape)
+		args = appendC4Named(args, "legendText", style.LegendText)
+		out.line(statement, "(", strings.Join(args, ", "), ")")
+	}
+}
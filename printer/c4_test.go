@@ -0,0 +1,31 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintC4RoundTrip(t *testing.T) {
+	out := roundTrip(t, `C4Container
+Person(user, "User", "End user")
+Container_Boundary(shop, "Shop") {
+  Container(api, "API", "Go", "REST API")
+  ContainerDb(db, "Database")
+}
+BiRel(api, db, "Reads/Writes", "SQL")
+Rel_U(user, api, "Uses")
+UpdateRelStyle(api, db, $lineColor="red")
+UpdateLayoutConfig($c4ShapeInRow="2")`)
+	for _, want := range []string{
+		`    Person(user, "User", "End user")` + "\n    Container_Boundary(shop",
+		`        ContainerDb(db, "Database")`,
+		`BiRel(api, db, "Reads/Writes", "SQL")`,
+		`Rel_U(user, api, "Uses")`,
+		`UpdateRelStyle(api, db, $lineColor="red")`,
+		`UpdateLayoutConfig($c4ShapeInRow="2")`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
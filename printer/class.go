@@ -0,0 +1,166 @@
+package printer
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printClass writes a class diagram: namespaces, class bodies,
+// annotations, relationships and notes.
+func printClass(out *writer, graph *ir.Graph) {
+	out.line("classDiagram")
+	out.indent()
+	if graph.Direction != ir.TopDown {
+		out.line("direction ", directionToken(graph.Direction))
+	}
+
+	namespaceOf := make(map[string]int)
+	for nsIdx, ns := range graph.Namespaces {
+		for _, id := range ns.Classes {
+			namespaceOf[id] = nsIdx
+		}
+	}
+
+	// Namespaces, class bodies and relationships are interleaved so that
+	// classes are introduced in their original order, which the layout
+	// follows.
+	introduced := make(map[string]bool)
+	nextNamespace, nextEdge := 0, 0
+	for _, id := range sortedNodeIDs(graph) {
+		if nsIdx, ok := namespaceOf[id]; ok {
+			for ; nextNamespace <= nsIdx; nextNamespace++ {
+				printClassNamespace(out, graph, graph.Namespaces[nextNamespace], introduced)
+			}
+		}
+		if members, ok := graph.Members[id]; ok && !introduced[id] {
+			printClassBody(out, id, members)
+			introduced[id] = true
+		}
+		if annotation, ok := graph.Annotations[id]; ok {
+			out.line("<<", annotation, ">> ", id)
+			introduced[id] = true
+		}
+		for ; !introduced[id] && nextEdge < len(graph.Edges); nextEdge++ {
+			edge := graph.Edges[nextEdge]
+			printClassEdge(out, edge)
+			introduced[edge.From] = true
+			introduced[edge.To] = true
+		}
+	}
+	for _, ns := range graph.Namespaces[nextNamespace:] {
+		printClassNamespace(out, graph, ns, introduced)
+	}
+	for _, edge := range graph.Edges[nextEdge:] {
+		printClassEdge(out, edge)
+	}
+
+	for _, note := range graph.Notes {
+		if note.Target != "" {
+			out.line("note for ", note.Target, " ", forceQuote(note.Text))
+		} else {
+			out.line("note ", forceQuote(note.Text))
+		}
+	}
+	out.dedent()
+}
+
+// printClassNamespace writes a namespace block with its class bodies and
+// marks those classes as introduced.
+func printClassNamespace(out *writer, graph *ir.Graph, ns *ir.Namespace, introduced map[string]bool) {
+	out.line("namespace ", ns.Name, " {")
+	out.indent()
+	for _, id := range ns.Classes {
+		introduced[id] = true
+		printClassBody(out, id, graph.Members[id])
+	}
+	out.dedent()
+	out.line("}")
+}
+
+// printClassEdge writes one relationship.
+func printClassEdge(out *writer, edge *ir.Edge) {
+	rel := edge.From + " "
+	if edge.StartLabel != nil {
+		rel += forceQuote(*edge.StartLabel) + " "
+	}
+	rel += classArrow(edge)
+	if edge.EndLabel != nil {
+		rel += " " + forceQuote(*edge.EndLabel)
+	}
+	rel += " " + edge.To
+	if edge.Label != nil && *edge.Label != "" {
+		rel += " : " + *edge.Label
+	}
+	out.line(rel)
+}
+
+// printClassBody writes "class Name" or a braced body with members.
+func printClassBody(out *writer, id string, members *ir.ClassMembers) {
+	if members == nil || (len(members.Attributes) == 0 && len(members.Methods) == 0) {
+		out.line("class ", id)
+		return
+	}
+	out.line("class ", id, " {")
+	out.indent()
+	for _, attr := range members.Attributes {
+		out.line(classMember(attr))
+	}
+	for _, method := range members.Methods {
+		out.line(classMember(method))
+	}
+	out.dedent()
+	out.line("}")
+}
+
+// classMember formats a member the way parseClassMember reads it.
+func classMember(member ir.ClassMember) string {
+	classifier := ""
+	switch member.Classifier {
+	case ir.ClassifierAbstract:
+		classifier = "*"
+	case ir.ClassifierStatic:
+		classifier = "$"
+	case ir.ClassifierNone:
+	}
+
+	text := member.Visibility.Symbol()
+	if member.IsMethod {
+		text += member.Name + "(" + member.Params + ")" + classifier
+		if member.Type != "" {
+			text += " " + member.Type
+		}
+		return text
+	}
+	if member.Type != "" {
+		text += member.Type + " "
+	}
+	return text + member.Name + classifier
+}
+
+// classArrow returns the relationship token for a class edge, the inverse
+// of buildClassEdge.
+func classArrow(edge *ir.Edge) string {
+	if edge.ArrowStart && edge.ArrowStartKind != nil {
+		switch *edge.ArrowStartKind {
+		case ir.FilledDiamond:
+			return "*--"
+		case ir.OpenDiamond:
+			return "o--"
+		default:
+			return "<|--"
+		}
+	}
+	if edge.ArrowEnd && edge.ArrowEndKind != nil {
+		switch *edge.ArrowEndKind {
+		case ir.ClassDependency:
+			return "..>"
+		case ir.ClosedTriangle:
+			return "..|>"
+		default:
+			return "-->"
+		}
+	}
+	if edge.Style == ir.Dotted {
+		return ".."
+	}
+	return "--"
+}
@@ -0,0 +1,32 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintClassRoundTrip(t *testing.T) {
+	input := `classDiagram
+    class Animal {
+        +String name
+        +eat(food) bool
+    }
+    <<interface>> Animal
+    Animal <|-- Dog
+    Dog "1" *-- "many" Leg : has
+    Dog ..> Bone
+    note for Dog "good boy"`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"class Animal {",
+		"<<interface>> Animal",
+		"Animal <|-- Dog",
+		`Dog "1" *-- "many" Leg : has`,
+		"Dog ..> Bone",
+		`note for Dog "good boy"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printER writes an ER diagram: entity blocks, then relationships.
+func printER(out *writer, graph *ir.Graph) {
+	out.line("erDiagram")
+	out.indent()
+	if graph.Direction != ir.TopDown {
+		out.line("direction ", directionToken(graph.Direction))
+	}
+
+	related := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		related[edge.From] = true
+		related[edge.To] = true
+	}
+
+	for _, id := range sortedNodeIDs(graph) {
+		entity := graph.Entities[id]
+		if entity == nil {
+			if !related[id] {
+				out.line(id)
+			}
+			continue
+		}
+		if len(entity.Attributes) == 0 && entity.Label == "" {
+			if !related[id] {
+				out.line(id)
+			}
+			continue
+		}
+		header := id
+		if entity.Label != "" {
+			header += "[" + forceQuote(entity.Label) + "]"
+		}
+		out.line(header, " {")
+		out.indent()
+		for _, attr := range entity.Attributes {
+			out.line(erAttribute(attr))
+		}
+		out.dedent()
+		out.line("}")
+	}
+
+	for _, edge := range graph.Edges {
+		label := ""
+		if edge.Label != nil {
+			label = *edge.Label
+		}
+		line := "--"
+		if edge.Style == ir.Dotted {
+			line = ".."
+		}
+		out.line(edge.From, " ",
+			erCardinality(edge.StartDecoration, true), line, erCardinality(edge.EndDecoration, false),
+			" ", edge.To, " : ", quoteLabel(label))
+	}
+	out.dedent()
+}
+
+// erAttribute formats an entity attribute as "type name keys "comment"".
+func erAttribute(attr ir.EntityAttribute) string {
+	parts := []string{attr.Type, attr.Name}
+	if len(attr.Keys) > 0 {
+		keys := make([]string, len(attr.Keys))
+		for idx, key := range attr.Keys {
+			keys[idx] = key.String()
+		}
+		parts = append(parts, strings.Join(keys, ","))
+	}
+	if attr.Comment != "" {
+		parts = append(parts, `"`+attr.Comment+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// erCardinality returns the crow's-foot token for one end of a
+// relationship, the inverse of mapCardinality.
+func erCardinality(dec *ir.EdgeDecoration, left bool) string {
+	kind := ir.DecCrowsFootOne
+	if dec != nil {
+		kind = *dec
+	}
+	switch kind {
+	case ir.DecCrowsFootZeroOne:
+		if left {
+			return "|o"
+		}
+		return "o|"
+	case ir.DecCrowsFootMany:
+		if left {
+			return "}|"
+		}
+		return "|{"
+	case ir.DecCrowsFootZeroMany:
+		if left {
+			return "}o"
+		}
+		return "o{"
+	default:
+		return "||"
+	}
+}
@@ -0,0 +1,27 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintERRoundTrip(t *testing.T) {
+	input := `erDiagram
+    CUSTOMER ||--o{ ORDER : places
+    ORDER }|..|{ LINE_ITEM : "contains items"
+    CUSTOMER {
+        string name PK
+        string email UK "login"
+    }`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"CUSTOMER {",
+		`string email UK "login"`,
+		"CUSTOMER ||--o{ ORDER : places",
+		"ORDER }|..|{ LINE_ITEM : contains items",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
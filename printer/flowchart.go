@@ -0,0 +1,287 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printFlowchart writes a flowchart: header, then subgraphs with their
+// member nodes, node declarations and edges.
+func printFlowchart(out *writer, graph *ir.Graph) {
+	out.line("flowchart ", directionToken(graph.Direction))
+	out.indent()
+
+	parents := subgraphParents(graph.Subgraphs)
+	topLevel := make(map[string]int)
+	for idx, sg := range graph.Subgraphs {
+		if parents[idx] >= 0 {
+			continue
+		}
+		for _, id := range sg.Nodes {
+			if _, ok := topLevel[id]; !ok {
+				topLevel[id] = idx
+			}
+		}
+	}
+	edgeNodes := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		edgeNodes[edge.From] = true
+		edgeNodes[edge.To] = true
+	}
+
+	// Subgraphs, declarations and edges are interleaved so that nodes are
+	// introduced in their original order, which the layout follows.
+	declared := make(map[string]bool)
+	introduced := make(map[string]bool)
+	nextSubgraph, nextEdge := 0, 0
+	for _, id := range sortedNodeIDs(graph) {
+		if introduced[id] || declared[id] {
+			continue
+		}
+		if sgIdx, ok := topLevel[id]; ok {
+			for ; nextSubgraph <= sgIdx; nextSubgraph++ {
+				if parents[nextSubgraph] < 0 {
+					printSubgraph(out, graph, nextSubgraph, parents, declared)
+				}
+			}
+			continue
+		}
+		node := graph.Nodes[id]
+		if !edgeNodes[id] || needsDeclaration(graph, node) {
+			printNodeDeclaration(out, graph, node)
+			introduced[id] = true
+			continue
+		}
+		for ; !introduced[id] && !declared[id] && nextEdge < len(graph.Edges); nextEdge++ {
+			printFlowchartEdge(out, graph.Edges[nextEdge])
+			introduced[graph.Edges[nextEdge].From] = true
+			introduced[graph.Edges[nextEdge].To] = true
+		}
+	}
+	for ; nextSubgraph < len(graph.Subgraphs); nextSubgraph++ {
+		if parents[nextSubgraph] < 0 {
+			printSubgraph(out, graph, nextSubgraph, parents, declared)
+		}
+	}
+	for _, edge := range graph.Edges[nextEdge:] {
+		printFlowchartEdge(out, edge)
+	}
+	out.dedent()
+}
+
+// printFlowchartEdge writes one edge with its arrow and label.
+func printFlowchartEdge(out *writer, edge *ir.Edge) {
+	label := ""
+	if edge.Label != nil && *edge.Label != "" {
+		label = "|" + quoteLabel(*edge.Label) + "|"
+	}
+	out.line(edge.From, " ", edgeArrow(edge), label, " ", edge.To)
+}
+
+// printSubgraph writes one subgraph block, recursing into nested subgraphs.
+func printSubgraph(out *writer, graph *ir.Graph, idx int, parents []int, declared map[string]bool) {
+	sg := graph.Subgraphs[idx]
+	out.line("subgraph ", subgraphHeader(sg))
+	out.indent()
+	if sg.Direction != nil {
+		out.line("direction ", directionToken(*sg.Direction))
+	}
+	for childIdx := range graph.Subgraphs {
+		if parents[childIdx] == idx {
+			printSubgraph(out, graph, childIdx, parents, declared)
+		}
+	}
+	for _, id := range sg.Nodes {
+		if declared[id] {
+			continue
+		}
+		declared[id] = true
+		if node, ok := graph.Nodes[id]; ok {
+			printNodeDeclaration(out, graph, node)
+		}
+	}
+	out.dedent()
+	out.line("end")
+}
+
+// subgraphParents infers subgraph nesting. The parser adds each node to
+// every enclosing subgraph, so the parent of a subgraph is the closest
+// earlier subgraph whose node set contains all of its nodes.
+func subgraphParents(subgraphs []*ir.Subgraph) []int {
+	parents := make([]int, len(subgraphs))
+	for idx, sg := range subgraphs {
+		parents[idx] = -1
+		if len(sg.Nodes) == 0 {
+			continue
+		}
+		for cand := idx - 1; cand >= 0; cand-- {
+			if containsAll(subgraphs[cand].Nodes, sg.Nodes) && len(subgraphs[cand].Nodes) > len(sg.Nodes) {
+				parents[idx] = cand
+				break
+			}
+		}
+	}
+	return parents
+}
+
+// containsAll reports whether every element of subset is in set.
+func containsAll(set, subset []string) bool {
+	members := make(map[string]bool, len(set))
+	for _, id := range set {
+		members[id] = true
+	}
+	for _, id := range subset {
+		if !members[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// subgraphHeader returns the text after the "subgraph" keyword.
+func subgraphHeader(sg *ir.Subgraph) string {
+	if sg.ID == nil {
+		return forceQuote(sg.Label)
+	}
+	if *sg.ID == sg.Label {
+		return *sg.ID
+	}
+	return *sg.ID + "[" + quoteLabel(sg.Label) + "]"
+}
+
+// needsDeclaration reports whether a node differs from the bare-ID default.
+func needsDeclaration(graph *ir.Graph, node *ir.Node) bool {
+	return node.Label != node.ID || node.Shape != ir.Rectangle || len(graph.NodeClasses[node.ID]) > 0 || node.Icon != nil
+}
+
+// printNodeDeclaration declares a node. Icon nodes are written in the
+// id@{ ... } node data form, after a shape declaration if they also have a
+// shape or classes.
+func printNodeDeclaration(out *writer, graph *ir.Graph, node *ir.Node) {
+	if node.Icon == nil {
+		out.line(nodeToken(graph, node))
+		return
+	}
+	label := node.Label != node.ID
+	if node.Shape != ir.Rectangle || len(graph.NodeClasses[node.ID]) > 0 {
+		out.line(nodeToken(graph, node))
+		label = false
+	}
+	data := []string{"icon: " + nodeDataValue(node.Icon.Name)}
+	if node.Icon.Form != "" {
+		data = append(data, "form: "+nodeDataValue(node.Icon.Form))
+	}
+	if label {
+		data = append(data, "label: "+nodeDataValue(node.Label))
+	}
+	if node.Icon.LabelTop {
+		data = append(data, `pos: "t"`)
+	}
+	if node.Icon.Size > 0 {
+		data = append(data, "h: "+strconv.FormatFloat(float64(node.Icon.Size), 'f', -1, 32))
+	}
+	out.line(node.ID, "@{ ", strings.Join(data, ", "), " }")
+}
+
+// nodeDataValue quotes a node data value, with single quotes when it
+// contains a double quote.
+func nodeDataValue(value string) string {
+	if strings.Contains(value, `"`) {
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// nodeToken returns the node ID with its shape brackets, label and
+// inline :::class suffixes.
+func nodeToken(graph *ir.Graph, node *ir.Node) string {
+	token := node.ID
+	if node.Label != node.ID || node.Shape != ir.Rectangle {
+		open, closing := shapeBrackets(node.Shape)
+		token += open + quoteLabel(node.Label) + closing
+	}
+	for _, cls := range graph.NodeClasses[node.ID] {
+		token += ":::" + cls
+	}
+	return token
+}
+
+// shapeBrackets returns the opening and closing delimiters for a shape,
+// mirroring parseShapeFromBrackets, parseShapeFromParens and
+// parseShapeFromBraces.
+func shapeBrackets(shape ir.NodeShape) (string, string) {
+	switch shape {
+	case ir.RoundRect:
+		return "(", ")"
+	case ir.Stadium:
+		return "([", "])"
+	case ir.Subroutine:
+		return "[[", "]]"
+	case ir.Cylinder:
+		return "[(", ")]"
+	case ir.Circle:
+		return "((", "))"
+	case ir.DoubleCircle:
+		return "(((", ")))"
+	case ir.Diamond:
+		return "{", "}"
+	case ir.Hexagon:
+		return "{{", "}}"
+	case ir.Parallelogram:
+		return "[/", "/]"
+	case ir.ParallelogramAlt:
+		return "[\\", "\\]"
+	case ir.Trapezoid:
+		return "[/", "\\]"
+	case ir.TrapezoidAlt:
+		return "[\\", "/]"
+	case ir.Asymmetric:
+		return ">", "]"
+	default:
+		return "[", "]"
+	}
+}
+
+// edgeArrow builds the arrow token for a flowchart edge, the inverse of
+// parseEdgeMeta.
+func edgeArrow(edge *ir.Edge) string {
+	start := ""
+	switch {
+	case edge.StartDecoration != nil && *edge.StartDecoration == ir.DecCircle:
+		start = "o"
+	case edge.StartDecoration != nil && *edge.StartDecoration == ir.DecCross:
+		start = "x"
+	case edge.ArrowStart:
+		start = "<"
+	}
+
+	end := ""
+	switch {
+	case edge.EndDecoration != nil && *edge.EndDecoration == ir.DecCircle:
+		end = "o"
+	case edge.EndDecoration != nil && *edge.EndDecoration == ir.DecCross:
+		end = "x"
+	case edge.ArrowEnd:
+		end = ">"
+	}
+
+	capped := end != ""
+	var body string
+	switch edge.Style {
+	case ir.Dotted:
+		body = "-.-"
+	case ir.Thick:
+		body = "=="
+		if !capped {
+			body = "==="
+		}
+	default:
+		body = "--"
+		if !capped {
+			body = "---"
+		}
+	}
+	return start + body + end
+}
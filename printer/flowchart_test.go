@@ -0,0 +1,61 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintFlowchartRoundTrip(t *testing.T) {
+	input := `flowchart LR
+    A[Start] --> B{Is it ok?}
+    B -->|yes| C(Done)
+    B -.->|"no (retry)"| A
+    C ==> D((End))
+    subgraph sg1 [Group]
+        direction TB
+        C
+        D
+    end`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"flowchart LR",
+		"B{Is it ok?}",
+		"B -->|yes| C",
+		`B -.->|"no (retry)"| A`,
+		"C ==> D",
+		"subgraph sg1[Group]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintFlowchartDoubleCircle(t *testing.T) {
+	out := roundTrip(t, "flowchart LR\n    A(((Stop))) --> B")
+	if !strings.Contains(out, "A(((Stop)))") {
+		t.Errorf("double circle should keep its triple parentheses:\n%s", out)
+	}
+}
+
+func TestPrintFlowchartBareNodesNotDeclared(t *testing.T) {
+	out := roundTrip(t, "flowchart TD\n    A --> B")
+	if strings.Contains(out, "    A\n") {
+		t.Errorf("bare edge node should not be declared separately:\n%s", out)
+	}
+}
+
+func TestPrintFlowchartIconNodes(t *testing.T) {
+	out := roundTrip(t, `flowchart LR
+    A@{ icon: "logos:aws-lambda", form: "square", label: "Fn" } --> B
+    C(Round):::hot
+    C@{ icon: "fa:user", pos: "t", h: 48 }`)
+	for _, want := range []string{
+		`A@{ icon: "logos:aws-lambda", form: "square", label: "Fn" }`,
+		"C(Round):::hot\n    C@{ icon: \"fa:user\", pos: \"t\", h: 48 }",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// ErrFormatChanged is returned when the formatted source would not parse
+// back to the same diagram as the input.
+var ErrFormatChanged = errors.New("printer: formatting would change the diagram")
+
+var (
+	// leadingIdentRe extracts the first identifier of a statement, used to
+	// re-attach comments when a statement's printed form differs.
+	leadingIdentRe = regexp.MustCompile(`^[A-Za-z0-9_]+`)
+
+	// classSuffixRe matches inline :::class suffixes.
+	classSuffixRe = regexp.MustCompile(`:::[A-Za-z0-9_-]+`)
+
+	// passthroughRe matches, per diagram kind, the statements the printer
+	// does not write. Format keeps them verbatim after the printed body.
+	//
+	//nolint:gochecknoglobals // package-level lookup table is idiomatic for constant sets.
+	passthroughRe = map[ir.DiagramKind]*regexp.Regexp{
+		ir.Flowchart: regexp.MustCompile(`(?i)^(classdef|class |style |linkstyle|click |acctitle|accdescr|title )`),
+		ir.Class:     regexp.MustCompile(`(?i)^(classdef|style|cssclass|click|callback|link)\b`),
+		ir.Mindmap:   regexp.MustCompile(`(?i)^classDef `),
+		ir.Block:     regexp.MustCompile(`^(classDef|class|style) `),
+		ir.Treemap:   regexp.MustCompile(`(?i)^classDef `),
+	}
+)
+
+// statement is one significant source line together with its trivia.
+type statement struct {
+	code     string   // statement text with any trailing comment removed
+	comments []string // full-line comments directly above the statement
+	trailing string   // comment on the same line, including the %% marker
+	blank    bool     // preceded by at least one blank line
+}
+
+// hasTrivia reports whether the statement carries anything to re-attach.
+func (s statement) hasTrivia() bool {
+	return len(s.comments) > 0 || s.trailing != "" || s.blank
+}
+
+// document is Mermaid source split into statements and trivia.
+type document struct {
+	directives []string
+	statements []statement
+	footer     []string // comments after the last statement
+}
+
+// Format parses Mermaid source and returns it in canonical form.
+//
+// Comments, blank-line grouping and init directives are carried over as
+// trivia: each comment is attached to the statement that follows it and
+// re-emitted next to that statement's printed form. Statements the
+// printer does not write, such as flowchart classDef and style lines, and
+// statements the parser ignores are kept verbatim at the end of the
+// diagram body.
+//
+// Format returns ErrFormatChanged rather than output that parses to a
+// different diagram than the input.
+func Format(source string) (string, error) {
+	parsed, err := parser.Parse(source)
+	if err != nil {
+		return "", err
+	}
+	printed, err := Print(parsed.Graph)
+	if err != nil {
+		return "", err
+	}
+
+	doc := scanDocument(source)
+	lines := strings.Split(strings.TrimSuffix(printed, "\n"), "\n")
+	keep := passthroughRe[parsed.Graph.Kind]
+	ignored := ignoredStatements(source, parsed.Graph)
+	for stmtIdx, stmt := range doc.statements {
+		if (keep == nil || !keep.MatchString(stmt.code)) && !ignored[stmtIdx] {
+			continue
+		}
+		if matchLine(lines, make([]bool, len(lines)), 0, compactCode(stmt.code), compactCode) < 0 {
+			lines = append(lines, indentUnit+stmt.code)
+		}
+	}
+	formatted := doc.attach(lines)
+
+	// Guard against printer gaps: the result must describe the same diagram.
+	reparsed, err := parser.Parse(formatted)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFormatChanged, err)
+	}
+	if !reflect.DeepEqual(reparsed.Graph, parsed.Graph) {
+		return "", ErrFormatChanged
+	}
+	return formatted, nil
+}
+
+// ignoredStatements reports, by statement index, the statements the
+// parser ignores: those whose removal leaves the parsed diagram unchanged.
+// The header is never ignored.
+func ignoredStatements(source string, graph *ir.Graph) map[int]bool {
+	lines := strings.Split(source, "\n")
+	ignored := make(map[int]bool)
+	stmtIdx := -1
+	for lineIdx, rawLine := range lines {
+		trimmed := strings.TrimSpace(rawLine)
+		if trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+		stmtIdx++
+		if stmtIdx == 0 {
+			continue
+		}
+		rest := append(append([]string{}, lines[:lineIdx]...), lines[lineIdx+1:]...)
+		without, err := parser.Parse(strings.Join(rest, "\n"))
+		if err == nil && reflect.DeepEqual(without.Graph, graph) {
+			ignored[stmtIdx] = true
+		}
+	}
+	return ignored
+}
+
+// scanDocument splits source into directives, statements and comments.
+// Runs of blank lines collapse to one and are kept as empty comments.
+func scanDocument(source string) document {
+	var doc document
+	var pending []string
+	for _, rawLine := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(rawLine)
+		switch {
+		case trimmed == "":
+			started := len(doc.statements) > 0 || len(pending) > 0
+			if started && (len(pending) == 0 || pending[len(pending)-1] != "") {
+				pending = append(pending, "")
+			}
+		case strings.HasPrefix(trimmed, "%%{"):
+			doc.directives = append(doc.directives, trimmed)
+		case strings.HasPrefix(trimmed, "%%"):
+			pending = append(pending, trimmed)
+		default:
+			code, comment := splitComment(trimmed)
+			stmt := statement{code: code, trailing: comment}
+			if len(pending) > 0 && pending[0] == "" {
+				stmt.blank = true
+				pending = pending[1:]
+			}
+			stmt.comments = pending
+			doc.statements = append(doc.statements, stmt)
+			pending = nil
+		}
+	}
+	for len(pending) > 0 && pending[len(pending)-1] == "" {
+		pending = pending[:len(pending)-1]
+	}
+	doc.footer = pending
+	return doc
+}
+
+// splitComment separates a trailing %% comment from a statement, ignoring
+// %% inside quoted strings.
+func splitComment(line string) (string, string) {
+	var quote rune
+	for idx, ch := range line {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '%' && strings.HasPrefix(line[idx:], "%%"):
+			return strings.TrimSpace(line[:idx]), line[idx:]
+		}
+	}
+	return line, ""
+}
+
+// slot collects the trivia re-attached to one printed line.
+type slot struct {
+	blank    bool
+	comments []string
+	trailing string
+}
+
+// attach interleaves the document's trivia with the printed lines.
+// Statements are matched to printed lines in order, first by their text
+// with whitespace and quotes removed, then with labels also removed, and
+// finally by leading identifier. The
+// first statement is always the diagram header.
+func (d document) attach(lines []string) string {
+	slots := make([]slot, len(lines))
+	used := make([]bool, len(lines))
+	var footer []string
+	cursor := 0
+	for stmtIdx, stmt := range d.statements {
+		target := 0
+		if stmtIdx > 0 {
+			target = matchLine(lines, used, cursor, compactCode(stmt.code), compactCode)
+			if target < 0 && stmt.hasTrivia() {
+				target = matchLine(lines, used, cursor, skeleton(stmt.code), skeleton)
+			}
+			if target < 0 && stmt.hasTrivia() {
+				target = matchLine(lines, used, cursor, leadingIdentRe.FindString(stmt.code), leadingIdent)
+			}
+		}
+		if target < 0 {
+			if stmt.hasTrivia() {
+				footer = append(footer, stmt.comments...)
+				if stmt.trailing != "" {
+					footer = append(footer, stmt.trailing)
+				}
+			}
+			continue
+		}
+		used[target] = true
+		cursor = target + 1
+		dest := &slots[target]
+		dest.blank = dest.blank || (stmt.blank && target > 0)
+		dest.comments = append(dest.comments, stmt.comments...)
+		switch {
+		case stmt.trailing == "":
+		case dest.trailing == "":
+			dest.trailing = stmt.trailing
+		default:
+			dest.comments = append(dest.comments, stmt.trailing)
+		}
+	}
+
+	var out strings.Builder
+	for _, directive := range d.directives {
+		out.WriteString(directive + "\n")
+	}
+	for idx, line := range lines {
+		dest := slots[idx]
+		if dest.blank {
+			out.WriteString("\n")
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		for _, comment := range dest.comments {
+			if comment == "" {
+				out.WriteString("\n")
+				continue
+			}
+			out.WriteString(indent + comment + "\n")
+		}
+		out.WriteString(line)
+		if dest.trailing != "" {
+			out.WriteString(" " + dest.trailing)
+		}
+		out.WriteString("\n")
+	}
+	for _, comment := range append(footer, d.footer...) {
+		out.WriteString(comment + "\n")
+	}
+	return out.String()
+}
+
+// matchLine returns the first unused line at or after cursor whose key
+// equals want, wrapping around to the start, or -1.
+func matchLine(lines []string, used []bool, cursor int, want string, key func(string) string) int {
+	if want == "" {
+		return -1
+	}
+	for offset := range lines {
+		idx := (cursor + offset) % len(lines)
+		if !used[idx] && key(lines[idx]) == want {
+			return idx
+		}
+	}
+	return -1
+}
+
+// compactCode strips whitespace and double quotes so that statements
+// differing only in spacing and quoting compare equal.
+func compactCode(line string) string {
+	return strings.Map(func(ch rune) rune {
+		if ch == ' ' || ch == '\t' || ch == '"' {
+			return -1
+		}
+		return ch
+	}, line)
+}
+
+// skeleton compacts a line and drops bracketed, piped and quoted labels
+// and :::class suffixes, leaving the identifiers and arrows.
+func skeleton(line string) string {
+	var out strings.Builder
+	depth := 0
+	var closer rune
+	for _, ch := range classSuffixRe.ReplaceAllString(line, "") {
+		switch {
+		case closer != 0:
+			if ch == closer {
+				closer = 0
+			}
+		case ch == '"' || (ch == '|' && depth == 0):
+			closer = ch
+		case strings.ContainsRune("([{", ch):
+			depth++
+		case strings.ContainsRune(")]}", ch):
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0 && ch != ' ' && ch != '\t':
+			out.WriteRune(ch)
+		}
+	}
+	return out.String()
+}
+
+// leadingIdent returns the first identifier of a printed line.
+func leadingIdent(line string) string {
+	return leadingIdentRe.FindString(strings.TrimSpace(line))
+}
@@ -0,0 +1,152 @@
+package printer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatKeepsTrivia(t *testing.T) {
+	input := `%%{init: {"theme": "dark"}}%%
+%% top comment
+graph LR
+    %% nodes
+    A[Start]-->B{ok?}   %% first edge
+
+
+    B-- yes -->C
+    classDef hot fill:#f00
+    class C hot
+%% trailing
+`
+	want := `%%{init: {"theme": "dark"}}%%
+%% top comment
+flowchart LR
+    A[Start]
+    B{ok?}
+    %% nodes
+    A --> B %% first edge
+
+    B -->|yes| C
+    classDef hot fill:#f00
+    class C hot
+%% trailing
+`
+	got, err := Format(input)
+	if err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatNestedComments(t *testing.T) {
+	input := `sequenceDiagram
+  participant A
+  A->>B: hi %% greet
+  alt ok
+     %% happy path
+     B-->>A: yes
+  end
+`
+	got, err := Format(input)
+	if err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	for _, want := range []string{
+		"    A->>B: hi %% greet\n",
+		"        %% happy path\n        B-->>A: yes\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatGanttOptionsIdempotent(t *testing.T) {
+	input := `gantt
+    dateFormat YYYY-MM-DD
+    inclusiveEndDates
+    topAxis
+    section A
+        Task :t1, 2024-01-01, 3d
+`
+	first, err := Format(input)
+	if err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	for _, keyword := range []string{"inclusiveEndDates", "topAxis"} {
+		if got := strings.Count(first, keyword); got != 1 {
+			t.Errorf("%s printed %d times:\n%s", keyword, got, first)
+		}
+	}
+	second, err := Format(first)
+	if err != nil {
+		t.Fatalf("second Format() error: %v", err)
+	}
+	if second != first {
+		t.Errorf("Format is not idempotent:\n%s\n---\n%s", first, second)
+	}
+}
+
+func TestFormatKeepsUnprintedStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"state classDef", "stateDiagram-v2\n  classDef bad fill:#f00\n  A --> B\n  class A bad", "classDef bad fill:#f00"},
+		{"state class", "stateDiagram-v2\n  classDef bad fill:#f00\n  A --> B\n  class A bad", "class A bad"},
+		{"er style", "erDiagram\n  CUSTOMER ||--o{ ORDER : places\n  style CUSTOMER fill:#f9f", "style CUSTOMER fill:#f9f"},
+		{"class suffix", "classDiagram\n  class Dog:::foo\n  class Cat\n  Dog <|-- Cat", "class Dog:::foo"},
+		{"mindmap classDef", "mindmap\n  root\n    A:::hot\n  classDef hot fill:#f00", "classDef hot fill:#f00"},
+		{"mindmap ClassDef", "mindmap\n  root\n    A:::hot\n  ClassDef hot fill:#f00", "ClassDef hot fill:#f00"},
+		{"treemap ClassDef", "treemap\n\"Root\"\n    \"Leaf\": 42 :::hot\nClassDef hot fill:#f96", "ClassDef hot fill:#f96"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := Format(tc.input)
+			if err != nil {
+				t.Fatalf("Format() error: %v", err)
+			}
+			if !strings.Contains(first, tc.want) {
+				t.Errorf("Format() dropped %q:\n%s", tc.want, first)
+			}
+			second, err := Format(first)
+			if err != nil {
+				t.Fatalf("second Format() error: %v", err)
+			}
+			if second != first {
+				t.Errorf("Format is not idempotent:\n%s\n---\n%s", first, second)
+			}
+		})
+	}
+}
+
+func TestFormatFixturesIdempotent(t *testing.T) {
+	files, err := filepath.Glob("../testdata/fixtures/*.mmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, err := Format(string(src))
+		if err != nil {
+			t.Errorf("%s: Format() error: %v", file, err)
+			continue
+		}
+		second, err := Format(first)
+		if err != nil {
+			t.Errorf("%s: second Format() error: %v", file, err)
+			continue
+		}
+		if second != first {
+			t.Errorf("%s: Format is not idempotent:\n%s\n---\n%s", file, first, second)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package printer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// defaultGanttDateFormat is the dateFormat parseGantt assumes when the
+// source does not declare one.
+const defaultGanttDateFormat = "YYYY-MM-DD"
+
+// printGantt writes a gantt chart: settings, then sections and tasks.
+func printGantt(out *writer, graph *ir.Graph) {
+	out.line("gantt")
+	out.indent()
+	if graph.GanttTitle != "" {
+		out.line("title ", graph.GanttTitle)
+	}
+	if graph.GanttDateFormat != "" && graph.GanttDateFormat != defaultGanttDateFormat {
+		out.line("dateFormat ", graph.GanttDateFormat)
+	}
+	if graph.GanttAxisFormat != "" {
+		out.line("axisFormat ", graph.GanttAxisFormat)
+	}
+	if graph.GanttTickInterval != "" {
+		out.line("tickInterval ", graph.GanttTickInterval)
+	}
+	if graph.GanttTodayMarker != "" {
+		out.line("todayMarker ", graph.GanttTodayMarker)
+	}
+	if graph.GanttWeekday != "" {
+		out.line("weekend ", graph.GanttWeekday)
+	}
+	if len(graph.GanttExcludes) > 0 {
+		out.line("excludes ", strings.Join(graph.GanttExcludes, ", "))
+	}
+	if graph.GanttInclusiveEndDates {
+		out.line("inclusiveEndDates")
+	}
+	if graph.GanttTopAxis {
+		out.line("topAxis")
+	}
+
+	for _, sec := range graph.GanttSections {
+		if sec.Title != "" {
+			out.line("section ", sec.Title)
+			out.indent()
+		}
+		for _, task := range sec.Tasks {
+			out.line(task.Label, " : ", ganttTaskMetadata(task))
+		}
+		if sec.Title != "" {
+			out.dedent()
+		}
+	}
+	printGanttClicks(out, graph)
+	out.dedent()
+}
+
+// printGanttClicks writes a click line for each task with a URL or
+// callback, in task ID order.
+func printGanttClicks(out *writer, graph *ir.Graph) {
+	ids := make([]string, 0, len(graph.NodeLinks)+len(graph.GanttCallbacks))
+	for id := range graph.NodeLinks {
+		ids = append(ids, id)
+	}
+	for id := range graph.GanttCallbacks {
+		if graph.NodeLinks[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		line := "click " + id
+		if callback := graph.GanttCallbacks[id]; callback != nil {
+			line += " call " + callback.Name + "(" + strings.Join(callback.Args, ", ") + ")"
+		}
+		if link := graph.NodeLinks[id]; link != nil {
+			line += ` href "` + link.URL + `"`
+		}
+		out.line(line)
+	}
+}
+
+// ganttTaskMetadata formats the comma-separated part of a task line in the
+// order parseGanttTask expects: tags, ID, start, until, end.
+func ganttTaskMetadata(task *ir.GanttTask) string {
+	parts := make([]string, 0, len(task.Tags)+4) //nolint:mnd // ID, start, until and end slots.
+	parts = append(parts, task.Tags...)
+	if task.ID != "" {
+		parts = append(parts, task.ID)
+	}
+	switch {
+	case len(task.AfterIDs) > 0:
+		parts = append(parts, "after "+strings.Join(task.AfterIDs, " "))
+	case task.StartStr != "":
+		parts = append(parts, task.StartStr)
+	}
+	if task.UntilID != "" {
+		parts = append(parts, "until "+task.UntilID)
+	}
+	if task.EndStr != "" {
+		parts = append(parts, task.EndStr)
+	}
+	return strings.Join(parts, ", ")
+}
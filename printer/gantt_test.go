@@ -0,0 +1,54 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintGanttRoundTrip(t *testing.T) {
+	input := `gantt
+    title Release
+    dateFormat YYYY-MM-DD
+    excludes weekends
+    section Build
+        Design : done, des, 2024-01-01, 3d
+        Code : active, code, after des, 5d
+    section Ship
+        Release : crit, milestone, 2024-01-15, 0d`
+	out := roundTrip(t, input)
+	if strings.Contains(out, "dateFormat") {
+		t.Errorf("default dateFormat should be omitted:\n%s", out)
+	}
+	for _, want := range []string{
+		"title Release",
+		"excludes weekends",
+		"section Build",
+		"Design : done, des, 2024-01-01, 3d",
+		"Code : active, code, after des, 5d",
+		"Release : crit, milestone, 2024-01-15, 0d",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintGanttClicksAndOptions(t *testing.T) {
+	input := `gantt
+    inclusiveEndDates
+    topAxis
+    Design : des, 2024-01-01, 2024-01-03
+    Freeze : vert, 2024-01-02, 0d
+    click des call show("des") href "https://example.com"`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"inclusiveEndDates",
+		"topAxis",
+		"Freeze : vert, 2024-01-02, 0d",
+		`click des call show(des) href "https://example.com"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package printer
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printGitGraph writes a git graph as its sequence of actions.
+func printGitGraph(out *writer, graph *ir.Graph) {
+	switch graph.GitDirection {
+	case ir.TopDown:
+		out.line("gitGraph TB:")
+	case ir.BottomTop:
+		out.line("gitGraph BT:")
+	case ir.LeftRight, ir.RightLeft:
+		out.line("gitGraph")
+	}
+	out.indent()
+	for _, action := range graph.GitActions {
+		switch act := action.(type) {
+		case *ir.GitCommit:
+			out.line("commit", gitOptions(act.ID, act.Tag, act.Type))
+		case *ir.GitBranch:
+			line := "branch " + act.Name
+			if act.Order >= 0 {
+				line += " order: " + strconv.Itoa(act.Order)
+			}
+			out.line(line)
+		case *ir.GitCheckout:
+			out.line("checkout ", act.Branch)
+		case *ir.GitMerge:
+			out.line("merge ", act.Branch, gitOptions(act.ID, act.Tag, act.Type))
+		case *ir.GitCherryPick:
+			line := "cherry-pick id: " + forceQuote(act.ID)
+			if act.Parent != "" {
+				line += " parent: " + forceQuote(act.Parent)
+			}
+			out.line(line)
+		}
+	}
+	out.dedent()
+}
+
+// gitOptions formats the id, tag and type options of a commit or merge,
+// each with a leading space.
+func gitOptions(id, tag string, commitType ir.GitCommitType) string {
+	opts := ""
+	if id != "" {
+		opts += " id: " + forceQuote(id)
+	}
+	if tag != "" {
+		opts += " tag: " + forceQuote(tag)
+	}
+	if commitType != ir.GitCommitNormal {
+		opts += " type: " + commitType.String()
+	}
+	return opts
+}
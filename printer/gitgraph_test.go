@@ -0,0 +1,29 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintGitGraphRoundTrip(t *testing.T) {
+	out := roundTrip(t, `gitGraph TB:
+    commit id: "init"
+    branch feature order: 1
+    checkout feature
+    commit type: HIGHLIGHT
+    checkout main
+    merge feature tag: "v1.0"
+    cherry-pick id: "init"`)
+	for _, want := range []string{
+		"gitGraph TB:",
+		`commit id: "init"`,
+		"branch feature order: 1",
+		"commit type: HIGHLIGHT",
+		`merge feature tag: "v1.0"`,
+		`cherry-pick id: "init"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printJourney writes a user journey: title, then tasks grouped by section.
+func printJourney(out *writer, graph *ir.Graph) {
+	out.line("journey")
+	out.indent()
+	if graph.JourneyTitle != "" {
+		out.line("title ", graph.JourneyTitle)
+	}
+	// Tasks declared before the first section have no section name.
+	for _, task := range graph.JourneyTasks {
+		if task.Section == "" {
+			journeyTask(out, task)
+		}
+	}
+	for _, sec := range graph.JourneySections {
+		out.line("section ", sec.Name)
+		out.indent()
+		for _, idx := range sec.Tasks {
+			journeyTask(out, graph.JourneyTasks[idx])
+		}
+		out.dedent()
+	}
+	out.dedent()
+}
+
+// journeyTask writes "name: score: actor, actor".
+func journeyTask(out *writer, task *ir.JourneyTask) {
+	line := task.Name + ": " + strconv.Itoa(task.Score)
+	if len(task.Actors) > 0 {
+		line += ": " + strings.Join(task.Actors, ", ")
+	}
+	out.line(line)
+}
@@ -0,0 +1,19 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintJourneyRoundTrip(t *testing.T) {
+	out := roundTrip(t, `journey
+    title My day
+    section Work
+      Make tea: 5: Me
+      Commute:3:Me,Cat`)
+	for _, want := range []string{"title My day", "section Work", "Make tea: 5: Me", "Commute: 3: Me, Cat"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printKanban writes a kanban board: columns with their cards indented
+// beneath them.
+func printKanban(out *writer, graph *ir.Graph) {
+	out.line("kanban")
+	out.indent()
+	for _, col := range graph.Columns {
+		if col.ID == col.Label {
+			out.line(col.Label)
+		} else {
+			out.line(col.ID, "[", col.Label, "]")
+		}
+		out.indent()
+		for _, card := range col.Cards {
+			out.line(card.ID, "[", card.Label, "]", kanbanMetadata(card))
+		}
+		out.dedent()
+	}
+	out.dedent()
+}
+
+// kanbanMetadata formats a card's @{...} metadata, or "" when it has none.
+func kanbanMetadata(card *ir.KanbanCard) string {
+	var entries []string
+	add := func(key, value string) {
+		if value != "" {
+			entries = append(entries, key+": '"+value+"'")
+		}
+	}
+	add("assigned", card.Assigned)
+	add("ticket", card.Ticket)
+	add("priority", card.Priority.String())
+	add("icon", card.Icon)
+	add("description", card.Description)
+	if len(entries) == 0 {
+		return ""
+	}
+	return "@{ " + strings.Join(entries, ", ") + " }"
+}
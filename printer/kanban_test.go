@@ -0,0 +1,24 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintKanbanRoundTrip(t *testing.T) {
+	out := roundTrip(t, `kanban
+  Todo
+    t1[Fix login bug]@{ assigned: 'alice', priority: 'Very High' }
+  done[Done]
+    t2[Ship]`)
+	for _, want := range []string{
+		"    Todo\n",
+		"t1[Fix login bug]@{ assigned: 'alice', priority: 'Very High' }",
+		"    done[Done]\n",
+		"        t2[Ship]\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
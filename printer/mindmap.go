@@ -0,0 +1,53 @@
+package printer
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printMindmap writes a mindmap as an indented outline of nodes.
+func printMindmap(out *writer, graph *ir.Graph) {
+	out.line("mindmap")
+	out.indent()
+	if graph.MindmapRoot != nil {
+		printMindmapNode(out, graph.MindmapRoot)
+	}
+	out.dedent()
+}
+
+// printMindmapNode writes a node and, one level deeper, its children.
+func printMindmapNode(out *writer, node *ir.MindmapNode) {
+	text := mindmapShapeText(node)
+	if node.Icon != "" {
+		text += " ::icon(" + node.Icon + ")"
+	}
+	if node.Class != "" {
+		text += " :::" + node.Class
+	}
+	out.line(text)
+	out.indent()
+	for _, child := range node.Children {
+		printMindmapNode(out, child)
+	}
+	out.dedent()
+}
+
+// mindmapShapeText wraps a node label in its shape delimiters, the
+// inverse of parseMindmapShape.
+func mindmapShapeText(node *ir.MindmapNode) string {
+	switch node.Shape {
+	case ir.MindmapSquare:
+		return "[" + node.Label + "]"
+	case ir.MindmapRounded:
+		return "(" + node.Label + ")"
+	case ir.MindmapCircle:
+		return "((" + node.Label + "))"
+	case ir.MindmapBang:
+		return "))" + node.Label + "(("
+	case ir.MindmapCloud:
+		return ")" + node.Label + "("
+	case ir.MindmapHexagon:
+		return "{{" + node.Label + "}}"
+	case ir.MindmapShapeDefault:
+	}
+	return node.Label
+}
@@ -0,0 +1,28 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintMindmapRoundTrip(t *testing.T) {
+	out := roundTrip(t, `mindmap
+  root((Center))
+    [Square] ::icon(fa fa-book)
+      Leaf:::urgent
+    ))Bang((
+    )Cloud(
+    {{Hexagon}}`)
+	for _, want := range []string{
+		"    ((Center))\n",
+		"        [Square] ::icon(fa fa-book)\n",
+		"            Leaf :::urgent\n",
+		"))Bang((",
+		")Cloud(",
+		"{{Hexagon}}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
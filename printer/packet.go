@@ -0,0 +1,21 @@
+package printer
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printPacket writes a packet diagram with one explicit bit range per field.
+func printPacket(out *writer, graph *ir.Graph) {
+	out.line("packet")
+	out.indent()
+	for _, field := range graph.Fields {
+		bits := strconv.Itoa(field.Start)
+		if field.End != field.Start {
+			bits += "-" + strconv.Itoa(field.End)
+		}
+		out.line(bits, `: "`, field.Description, `"`)
+	}
+	out.dedent()
+}
@@ -0,0 +1,18 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintPacketRoundTrip(t *testing.T) {
+	out := roundTrip(t, `packet-beta
++16: "Source Port"
+16: "Flag"
+17-31: "Rest"`)
+	for _, want := range []string{`0-15: "Source Port"`, `16: "Flag"`, `17-31: "Rest"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
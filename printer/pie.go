@@ -0,0 +1,29 @@
+package printer
+
+import (
+	"strconv"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printPie writes a pie chart: header, title and one line per slice.
+func printPie(out *writer, graph *ir.Graph) {
+	if graph.PieShowData {
+		out.line("pie showData")
+	} else {
+		out.line("pie")
+	}
+	out.indent()
+	if graph.PieTitle != "" {
+		out.line("title ", graph.PieTitle)
+	}
+	for _, slice := range graph.PieSlices {
+		out.line(`"`, slice.Label, `" : `, formatNumber(slice.Value))
+	}
+	out.dedent()
+}
+
+// formatNumber writes a value with the fewest digits that round-trip.
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,17 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintPieRoundTrip(t *testing.T) {
+	out := roundTrip(t, `pie showData title Pets
+    "Dogs" : 386
+    "Cats" : 85.5`)
+	for _, want := range []string{"pie showData", "title Pets", `"Dogs" : 386`, `"Cats" : 85.5`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package printer serializes an ir.Graph back into Mermaid source text.
+//
+// The output is canonical: one statement per line, four-space indentation
+// inside blocks, and labels quoted only where the parser needs it.
+package printer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// indentUnit is the indentation written per nesting level.
+const indentUnit = "    "
+
+var (
+	// identRe matches identifiers every parser accepts without quoting.
+	identRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+	// plainLabelRe matches labels that can be written without quotes.
+	plainLabelRe = regexp.MustCompile(`^[\p{L}\p{N}_ .,!?'+*/=-]*$`)
+)
+
+// UnsupportedKindError is returned when a diagram kind has no printer.
+type UnsupportedKindError struct {
+	Kind ir.DiagramKind
+}
+
+func (e *UnsupportedKindError) Error() string {
+	return fmt.Sprintf("printer: unsupported diagram kind %s", e.Kind)
+}
+
+// Print returns the Mermaid source for the given graph.
+func Print(graph *ir.Graph) (string, error) {
+	var out writer
+	switch graph.Kind {
+	case ir.Flowchart:
+		printFlowchart(&out, graph)
+	case ir.Sequence:
+		printSequence(&out, graph)
+	case ir.Class:
+		printClass(&out, graph)
+	case ir.State:
+		printState(&out, graph)
+	case ir.Er:
+		printER(&out, graph)
+	case ir.Gantt:
+		printGantt(&out, graph)
+	case ir.Pie:
+		printPie(&out, graph)
+	case ir.Journey:
+		printJourney(&out, graph)
+	case ir.Timeline:
+		printTimeline(&out, graph)
+	case ir.Sankey:
+		printSankey(&out, graph)
+	case ir.Mindmap:
+		printMindmap(&out, graph)
+	case ir.Requirement:
+		printRequirement(&out, graph)
+	case ir.GitGraph:
+		printGitGraph(&out, graph)
+	case ir.C4:
+		printC4(&out, graph)
+	case ir.Quadrant:
+		printQuadrant(&out, graph)
+	case ir.ZenUML:
+		printZenUML(&out, graph)
+	case ir.Block:
+		printBlock(&out, graph)
+	case ir.Packet:
+		printPacket(&out, graph)
+	case ir.Kanban:
+		printKanban(&out, graph)
+	case ir.Architecture:
+		printArchitecture(&out, graph)
+	case ir.Radar:
+		printRadar(&out, graph)
+	case ir.Treemap:
+		printTreemap(&out, graph)
+	case ir.XYChart:
+		printXYChart(&out, graph)
+	default:
+		return "", &UnsupportedKindError{Kind: graph.Kind}
+	}
+	return out.String(), nil
+}
+
+// ValidID reports whether id can be written as a bare identifier.
+func ValidID(id string) bool {
+	return identRe.MatchString(id)
+}
+
+// writer accumulates indented output lines.
+type writer struct {
+	buf   strings.Builder
+	depth int
+}
+
+// line writes one indented line built from the given parts.
+func (w *writer) line(parts ...string) {
+	w.buf.WriteString(strings.Repeat(indentUnit, w.depth))
+	for _, part := range parts {
+		w.buf.WriteString(part)
+	}
+	w.buf.WriteByte('\n')
+}
+
+// indent increases the nesting level.
+func (w *writer) indent() {
+	w.depth++
+}
+
+// dedent decreases the nesting level.
+func (w *writer) dedent() {
+	if w.depth > 0 {
+		w.depth--
+	}
+}
+
+// String returns the accumulated text.
+func (w *writer) String() string {
+	return w.buf.String()
+}
+
+// quoteLabel returns label unchanged when it is safe to write bare, and
+// otherwise wraps it in double quotes with embedded quotes written as #quot;.
+func quoteLabel(label string) string {
+	if label != "" && label == strings.TrimSpace(label) && plainLabelRe.MatchString(label) {
+		return label
+	}
+	return forceQuote(label)
+}
+
+// forceQuote always wraps label in double quotes.
+func forceQuote(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
+
+// brText converts newlines to <br/> for diagram kinds that decode it.
+func brText(text string) string {
+	return strings.ReplaceAll(text, "\n", "<br/>")
+}
+
+// directionToken returns the Mermaid token for a direction.
+func directionToken(dir ir.Direction) string {
+	switch dir {
+	case ir.LeftRight:
+		return "LR"
+	case ir.RightLeft:
+		return "RL"
+	case ir.BottomTop:
+		return "BT"
+	default:
+		return "TD"
+	}
+}
+
+// sortedNodeIDs returns node IDs in declaration order, breaking ties by ID.
+func sortedNodeIDs(graph *ir.Graph) []string {
+	ids := make([]string, 0, len(graph.Nodes))
+	for id := range graph.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(idxA, idxB int) bool {
+		orderA := graph.NodeOrder[ids[idxA]]
+		orderB := graph.NodeOrder[ids[idxB]]
+		if orderA != orderB {
+			return orderA < orderB
+		}
+		return ids[idxA] < ids[idxB]
+	})
+	return ids
+}
@@ -0,0 +1,84 @@
+package printer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/parser"
+)
+
+// roundTrip parses input, prints it, and checks that the printed source
+// parses to the same graph and prints the same text again. It returns the
+// printed source.
+func roundTrip(t *testing.T, input string) string {
+	t.Helper()
+	first, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(input) error: %v", err)
+	}
+	printed, err := Print(first.Graph)
+	if err != nil {
+		t.Fatalf("Print() error: %v", err)
+	}
+	second, err := parser.Parse(printed)
+	if err != nil {
+		t.Fatalf("Parse(printed) error: %v\n%s", err, printed)
+	}
+	if !reflect.DeepEqual(second.Graph, first.Graph) {
+		t.Errorf("printed source parses to a different graph:\n%s", printed)
+	}
+	reprinted, err := Print(second.Graph)
+	if err != nil {
+		t.Fatalf("Print(reparsed) error: %v", err)
+	}
+	if reprinted != printed {
+		t.Errorf("round trip not stable:\nfirst:\n%s\nsecond:\n%s", printed, reprinted)
+	}
+	return printed
+}
+
+func TestPrintUnsupportedKind(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart + 1
+	_, err := Print(graph)
+	var kindErr *UnsupportedKindError
+	if !errors.As(err, &kindErr) {
+		t.Fatalf("Print(unknown kind) error = %v, want UnsupportedKindError", err)
+	}
+}
+
+func TestValidID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"A", true},
+		{"node_1", true},
+		{"", false},
+		{"a b", false},
+		{"a-b", false},
+	}
+	for _, tc := range tests {
+		if got := ValidID(tc.id); got != tc.want {
+			t.Errorf("ValidID(%q) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestQuoteLabel(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Start", "Start"},
+		{"Is it ok?", "Is it ok?"},
+		{"a (b)", `"a (b)"`},
+		{`say "hi"`, `"say #quot;hi#quot;"`},
+	}
+	for _, tc := range tests {
+		if got := quoteLabel(tc.in); got != tc.want {
+			t.Errorf("quoteLabel(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package printer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printQuadrant writes a quadrant chart: title, axes, quadrant labels,
+// points and class definitions.
+func printQuadrant(out *writer, graph *ir.Graph) {
+	out.line("quadrantChart")
+	out.indent()
+	if graph.QuadrantTitle != "" {
+		out.line("title ", graph.QuadrantTitle)
+	}
+	printQuadrantAxis(out, "x-axis", graph.XAxisLeft, graph.XAxisRight)
+	printQuadrantAxis(out, "y-axis", graph.YAxisBottom, graph.YAxisTop)
+	for idx, label := range graph.QuadrantLabels {
+		if label != "" {
+			out.line("quadrant-", strconv.Itoa(idx+1), " ", label)
+		}
+	}
+	for _, point := range graph.QuadrantPoints {
+		line := point.Label
+		if point.Class != "" {
+			line += ":::" + point.Class
+		}
+		line += ": [" + formatNumber(point.X) + ", " + formatNumber(point.Y) + "]"
+		if style := quadrantStyle(point.Style); style != "" {
+			line += " " + style
+		}
+		out.line(line)
+	}
+
+	names := make([]string, 0, len(graph.QuadrantClasses))
+	for name := range graph.QuadrantClasses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.line("classDef ", name, " ", quadrantStyle(graph.QuadrantClasses[name]))
+	}
+	out.dedent()
+}
+
+// printQuadrantAxis writes an axis with one or two labels, if it has any.
+func printQuadrantAxis(out *writer, keyword, low, high string) {
+	switch {
+	case high != "":
+		out.line(keyword, " ", low, " --> ", high)
+	case low != "":
+		out.line(keyword, " ", low)
+	}
+}
+
+// quadrantStyle formats point styles the way parseQuadrantStyle reads them.
+func quadrantStyle(style ir.QuadrantPointStyle) string {
+	var entries []string
+	if style.Radius != nil {
+		entries = append(entries, "radius: "+strconv.FormatFloat(float64(*style.Radius), 'f', -1, 32))
+	}
+	if style.Color != nil {
+		entries = append(entries, "color: "+*style.Color)
+	}
+	if style.StrokeColor != nil {
+		entries = append(entries, "stroke-color: "+*style.StrokeColor)
+	}
+	if style.StrokeWidth != nil {
+		entries = append(entries, "stroke-width: "+strconv.FormatFloat(float64(*style.StrokeWidth), 'f', -1, 32)+"px")
+	}
+	return strings.Join(entries, ", ")
+}
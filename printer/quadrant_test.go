@@ -0,0 +1,30 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintQuadrantRoundTrip(t *testing.T) {
+	out := roundTrip(t, `quadrantChart
+    title Priorities
+    x-axis Low Effort --> High Effort
+    y-axis Value
+    quadrant-2 Quick wins
+    Search: [0.32, 0.62] radius: 10, stroke-width: 3px
+    Export:::urgent: [0.35, 0.6]
+    classDef urgent color: #109060`)
+	for _, want := range []string{
+		"title Priorities",
+		"x-axis Low Effort --> High Effort",
+		"y-axis Value\n",
+		"quadrant-2 Quick wins",
+		"Search: [0.32, 0.62] radius: 10, stroke-width: 3px",
+		"Export:::urgent: [0.35, 0.6]",
+		"classDef urgent color: #109060",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
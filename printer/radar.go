@@ -0,0 +1,56 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printRadar writes a radar chart: options, the axes on one line, then
+// one line per curve with positional values.
+func printRadar(out *writer, graph *ir.Graph) {
+	out.line("radar-beta")
+	out.indent()
+	if graph.RadarTitle != "" {
+		out.line("title ", forceQuote(graph.RadarTitle))
+	}
+	switch graph.RadarGraticuleType {
+	case ir.RadarGraticuleCircle:
+		out.line("graticule circle")
+	case ir.RadarGraticulePolygon:
+		out.line("graticule polygon")
+	case ir.RadarGraticuleNone:
+	}
+	if graph.RadarTicks != 0 {
+		out.line("ticks ", strconv.Itoa(graph.RadarTicks))
+	}
+	if graph.RadarMax != 0 {
+		out.line("max ", formatNumber(graph.RadarMax))
+	}
+	if graph.RadarMin != 0 {
+		out.line("min ", formatNumber(graph.RadarMin))
+	}
+	if graph.RadarShowLegend {
+		out.line("showLegend")
+	}
+	if len(graph.RadarAxes) > 0 {
+		axes := make([]string, len(graph.RadarAxes))
+		for idx, axis := range graph.RadarAxes {
+			axes[idx] = axis.ID + "[" + forceQuote(axis.Label) + "]"
+		}
+		out.line("axis ", strings.Join(axes, ", "))
+	}
+	for _, curve := range graph.RadarCurves {
+		head := "curve " + curve.ID
+		if curve.Label != "" {
+			head += "[" + forceQuote(curve.Label) + "]"
+		}
+		values := make([]string, len(curve.Values))
+		for idx, value := range curve.Values {
+			values[idx] = formatNumber(value)
+		}
+		out.line(head, "{", strings.Join(values, ", "), "}")
+	}
+	out.dedent()
+}
@@ -0,0 +1,32 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintRadarRoundTrip(t *testing.T) {
+	out := roundTrip(t, `radar-beta
+    title "Skills"
+    graticule polygon
+    ticks 4
+    max 100
+    showLegend
+    axis sp["Speed"], po["Power"]
+    curve a["Team A"]{80, 60.5}
+    curve b{po: 90, sp: 60}`)
+	for _, want := range []string{
+		`title "Skills"`,
+		"graticule polygon",
+		"ticks 4",
+		"max 100",
+		"showLegend",
+		`axis sp["Speed"], po["Power"]`,
+		`curve a["Team A"]{80, 60.5}`,
+		"curve b{60, 90}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printRequirement writes a requirement diagram: requirement and element
+// blocks in declaration order, then relationships.
+func printRequirement(out *writer, graph *ir.Graph) {
+	out.line("requirementDiagram")
+	out.indent()
+
+	requirements := make(map[string]*ir.RequirementDef, len(graph.Requirements))
+	for _, req := range graph.Requirements {
+		requirements[req.Name] = req
+	}
+	elements := make(map[string]*ir.ElementDef, len(graph.ReqElements))
+	for _, elem := range graph.ReqElements {
+		elements[elem.Name] = elem
+	}
+	for _, id := range sortedNodeIDs(graph) {
+		if req, ok := requirements[id]; ok {
+			out.line(req.Type.String(), " ", req.Name, " {")
+			out.indent()
+			printReqField(out, "id", req.ID)
+			printReqField(out, "text", req.Text)
+			printReqField(out, "risk", strings.ToLower(req.Risk.String()))
+			printReqField(out, "verifymethod", strings.ToLower(req.VerifyMethod.String()))
+			out.dedent()
+			out.line("}")
+		} else if elem, ok := elements[id]; ok {
+			out.line("element ", elem.Name, " {")
+			out.indent()
+			printReqField(out, "type", elem.Type)
+			printReqField(out, "docref", elem.DocRef)
+			out.dedent()
+			out.line("}")
+		}
+	}
+
+	for _, rel := range graph.ReqRelationships {
+		out.line(rel.Source, " - ", rel.Type.String(), " -> ", rel.Target)
+	}
+	out.dedent()
+}
+
+// printReqField writes one "key: value" field when value is set.
+func printReqField(out *writer, key, value string) {
+	if value != "" {
+		out.line(key, ": ", value)
+	}
+}
@@ -0,0 +1,32 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintRequirementRoundTrip(t *testing.T) {
+	out := roundTrip(t, `requirementDiagram
+element entity {
+type: simulation
+}
+functionalRequirement req {
+id: 1
+text: the test text.
+risk: High
+verifymethod: Test
+}
+entity - satisfies -> req`)
+	for _, want := range []string{
+		"    element entity {\n        type: simulation\n    }\n    functionalRequirement req {",
+		"id: 1",
+		"text: the test text.",
+		"risk: high",
+		"verifymethod: test",
+		"entity - satisfies -> req",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
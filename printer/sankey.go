@@ -0,0 +1,24 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printSankey writes a sankey diagram as CSV rows of source, target, value.
+func printSankey(out *writer, graph *ir.Graph) {
+	out.line("sankey-beta")
+	for _, link := range graph.SankeyLinks {
+		out.line(csvField(link.Source), ",", csvField(link.Target), ",", formatNumber(link.Value))
+	}
+}
+
+// csvField quotes a CSV field when it contains a comma or quote,
+// doubling embedded quotes as RFC 4180 requires.
+func csvField(field string) string {
+	if !strings.ContainsAny(field, `,"`) && field == strings.TrimSpace(field) {
+		return field
+	}
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
@@ -0,0 +1,17 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintSankeyRoundTrip(t *testing.T) {
+	out := roundTrip(t, `sankey-beta
+Coal,Power,25.5
+"Oil, crude","Fuel ""A""",10`)
+	for _, want := range []string{"Coal,Power,25.5", `"Oil, crude","Fuel ""A""",10`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
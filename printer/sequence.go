@@ -0,0 +1,229 @@
+package printer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// seqArrowTokens maps message kinds to their arrow tokens.
+//
+//nolint:gochecknoglobals // package-level lookup table is idiomatic for constant sets.
+var seqArrowTokens = map[ir.SeqMessageKind]string{
+	ir.MsgSolid:       "->",
+	ir.MsgDotted:      "-->",
+	ir.MsgSolidArrow:  "->>",
+	ir.MsgDottedArrow: "-->>",
+	ir.MsgSolidCross:  "-x",
+	ir.MsgDottedCross: "--x",
+	ir.MsgSolidOpen:   "-)",
+	ir.MsgDottedOpen:  "--)",
+	ir.MsgBiSolid:     "<<->>",
+	ir.MsgBiDotted:    "<<-->>",
+}
+
+// printSequence writes a sequence diagram: participant declarations
+// (grouped into boxes), participant metadata, then events in order.
+func printSequence(out *writer, graph *ir.Graph) {
+	out.line("sequenceDiagram")
+	out.indent()
+	if graph.Autonumber {
+		out.line("autonumber")
+	}
+
+	printSeqParticipants(out, graph)
+
+	for _, participant := range graph.Participants {
+		for _, link := range participant.Links {
+			out.line("link ", participant.ID, ": ", link.Label, " @ ", link.URL)
+		}
+		if len(participant.Properties) > 0 {
+			// json.Marshal sorts map keys, keeping the output stable.
+			body, err := json.Marshal(participant.Properties)
+			if err == nil {
+				out.line("properties ", participant.ID, ": ", string(body))
+			}
+		}
+	}
+
+	printSeqEvents(out, graph)
+	out.dedent()
+}
+
+// printSeqParticipants declares participants in order. Participants that
+// belong to a box are written inside it the first time the box is reached.
+// Created participants are declared by their create event instead.
+func printSeqParticipants(out *writer, graph *ir.Graph) {
+	boxOf := make(map[string]*ir.SeqBox)
+	for _, box := range graph.Boxes {
+		for _, id := range box.Participants {
+			boxOf[id] = box
+		}
+	}
+	byID := make(map[string]*ir.SeqParticipant, len(graph.Participants))
+	for _, participant := range graph.Participants {
+		byID[participant.ID] = participant
+	}
+
+	emittedBoxes := make(map[*ir.SeqBox]bool)
+	for _, participant := range graph.Participants {
+		if participant.IsCreated {
+			continue
+		}
+		box, inBox := boxOf[participant.ID]
+		if !inBox {
+			out.line(participantDecl(participant))
+			continue
+		}
+		if emittedBoxes[box] {
+			continue
+		}
+		emittedBoxes[box] = true
+		out.line(strings.TrimSpace("box " + strings.TrimSpace(box.Color+" "+box.Label)))
+		out.indent()
+		for _, id := range box.Participants {
+			if member, ok := byID[id]; ok && !member.IsCreated {
+				out.line(participantDecl(member))
+			}
+		}
+		out.dedent()
+		out.line("end")
+	}
+}
+
+// participantDecl returns the declaration line for a participant.
+func participantDecl(participant *ir.SeqParticipant) string {
+	var decl string
+	switch participant.Kind {
+	case ir.ParticipantBox:
+		decl = "participant " + participant.ID
+	case ir.ActorStickFigure:
+		decl = "actor " + participant.ID
+	default:
+		decl = "participant " + participant.ID + `@{ "type": "` + participant.Kind.String() + `" }`
+	}
+	if participant.Alias != "" {
+		decl += " as " + participant.Alias
+	}
+	return decl
+}
+
+// printSeqEvents writes the event timeline. Activation events generated
+// from the +/- message shorthand are folded back into the message.
+func printSeqEvents(out *writer, graph *ir.Graph) {
+	byID := make(map[string]*ir.SeqParticipant, len(graph.Participants))
+	for _, participant := range graph.Participants {
+		byID[participant.ID] = participant
+	}
+
+	var frames []ir.SeqFrameKind
+	events := graph.Events
+	for idx := 0; idx < len(events); idx++ {
+		event := events[idx]
+		switch event.Kind {
+		case ir.EvMessage:
+			msg := event.Message
+			shorthand := ""
+			if msg.ActivateTarget && nextIs(events, idx, ir.EvActivate, msg.To) {
+				shorthand = "+"
+				idx++
+			} else if msg.DeactivateSource && nextIs(events, idx, ir.EvDeactivate, msg.From) {
+				shorthand = "-"
+				idx++
+			}
+			out.line(msg.From, seqArrowTokens[msg.Kind], shorthand, msg.To, ": ", seqWrapPrefix(msg.Wrap), brText(msg.Text))
+		case ir.EvNote:
+			out.line(noteLine(event.Note))
+		case ir.EvActivate:
+			out.line("activate ", event.Target)
+		case ir.EvDeactivate:
+			out.line("deactivate ", event.Target)
+		case ir.EvFrameStart:
+			frames = append(frames, event.Frame.Kind)
+			if event.Frame.Kind == ir.FrameRect {
+				out.line("rect ", event.Frame.Color)
+			} else {
+				out.line(strings.TrimSpace(event.Frame.Kind.String() + " " + event.Frame.Label))
+			}
+			out.indent()
+		case ir.EvFrameMiddle:
+			keyword := "else"
+			if len(frames) > 0 {
+				keyword = frameMiddleKeyword(frames[len(frames)-1])
+			}
+			out.dedent()
+			out.line(strings.TrimSpace(keyword + " " + event.Frame.Label))
+			out.indent()
+		case ir.EvFrameEnd:
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+			out.dedent()
+			out.line("end")
+		case ir.EvCreate:
+			out.line("create ", createDecl(byID[event.Target], event.Target))
+		case ir.EvDestroy:
+			out.line("destroy ", event.Target)
+		}
+	}
+}
+
+// createDecl returns the declaration used by a create statement, which
+// only accepts the participant and actor keywords.
+func createDecl(participant *ir.SeqParticipant, target string) string {
+	if participant == nil {
+		return "participant " + target
+	}
+	keyword := "participant "
+	if participant.Kind == ir.ActorStickFigure {
+		keyword = "actor "
+	}
+	if participant.Alias != "" {
+		return keyword + participant.ID + " as " + participant.Alias
+	}
+	return keyword + participant.ID
+}
+
+// nextIs reports whether the event after idx has the given kind and target.
+func nextIs(events []*ir.SeqEvent, idx int, kind ir.SeqEventKind, target string) bool {
+	return idx+1 < len(events) && events[idx+1].Kind == kind && events[idx+1].Target == target
+}
+
+// frameMiddleKeyword returns the divider keyword for a frame kind.
+func frameMiddleKeyword(kind ir.SeqFrameKind) string {
+	switch kind {
+	case ir.FramePar:
+		return "and"
+	case ir.FrameCritical:
+		return "option"
+	default:
+		return "else"
+	}
+}
+
+// noteLine returns the statement for a sequence note.
+func noteLine(note *ir.SeqNote) string {
+	var position string
+	switch note.Position {
+	case ir.NoteLeft:
+		position = "left of "
+	case ir.NoteRight:
+		position = "right of "
+	default:
+		position = "over "
+	}
+	return "Note " + position + strings.Join(note.Participants, ",") + ": " + seqWrapPrefix(note.Wrap) + brText(note.Text)
+}
+
+// seqWrapPrefix returns the text prefix that selects a wrap setting.
+func seqWrapPrefix(wrap ir.SeqWrap) string {
+	switch wrap {
+	case ir.SeqWrapOn:
+		return "wrap: "
+	case ir.SeqWrapOff:
+		return "nowrap: "
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,52 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintSequenceRoundTrip(t *testing.T) {
+	input := `sequenceDiagram
+    autonumber
+    actor U as User
+    participant S as Server
+    U->>+S: request
+    alt ok
+        S-->>U: data
+    else failure
+        S--xU: error
+    end
+    S->>S: cleanup
+    deactivate S
+    Note over U,S: done`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"autonumber",
+		"actor U as User",
+		"U->>+S: request",
+		"alt ok",
+		"else failure",
+		"Note over U,S: done",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintSequenceWrapPrefix(t *testing.T) {
+	input := `sequenceDiagram
+    A->>B: wrap: long text
+    B-->>A: nowrap: reply
+    Note right of B: wrap: a note`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"A->>B: wrap: long text",
+		"B-->>A: nowrap: reply",
+		"Note right of B: wrap: a note",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// State diagram pseudo-node IDs produced by mapStarToken.
+const (
+	stateStartID = "__start__"
+	stateEndID   = "__end__"
+)
+
+// printState writes a state diagram with the stateDiagram-v2 header.
+func printState(out *writer, graph *ir.Graph) {
+	out.line("stateDiagram-v2")
+	out.indent()
+	printStateBody(out, graph)
+	out.dedent()
+}
+
+// printStateBody writes state declarations, composite states, transitions
+// and notes for one (possibly nested) state machine.
+func printStateBody(out *writer, graph *ir.Graph) {
+	if graph.Direction != ir.TopDown {
+		out.line("direction ", directionToken(graph.Direction))
+	}
+
+	related := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		related[edge.From] = true
+		related[edge.To] = true
+	}
+
+	// Declarations and transitions are interleaved so that states are
+	// introduced in their original order, which the layout follows.
+	introduced := make(map[string]bool)
+	nextEdge := 0
+	for _, id := range sortedNodeIDs(graph) {
+		if printStateDeclaration(out, graph, id, related[id]) {
+			introduced[id] = true
+			continue
+		}
+		for ; !introduced[id] && nextEdge < len(graph.Edges); nextEdge++ {
+			edge := graph.Edges[nextEdge]
+			printStateEdge(out, edge)
+			introduced[edge.From] = true
+			introduced[edge.To] = true
+		}
+	}
+	for _, edge := range graph.Edges[nextEdge:] {
+		printStateEdge(out, edge)
+	}
+
+	for _, note := range graph.Notes {
+		if !strings.Contains(note.Text, "\n") {
+			out.line("note ", note.Position, " ", note.Target, " : ", note.Text)
+			continue
+		}
+		out.line("note ", note.Position, " ", note.Target)
+		out.indent()
+		for _, textLine := range strings.Split(note.Text, "\n") {
+			out.line(textLine)
+		}
+		out.dedent()
+		out.line("end note")
+	}
+}
+
+// printStateDeclaration writes the statements declaring a state, if it
+// needs any, and reports whether it wrote one. States that only appear in
+// transitions need none.
+func printStateDeclaration(out *writer, graph *ir.Graph, id string, related bool) bool {
+	if id == stateStartID || id == stateEndID {
+		return false
+	}
+	ann, annotated := graph.StateAnnotations[id]
+	if annotated {
+		out.line("state ", id, " <<", ann.String(), ">>")
+	}
+	desc, described := graph.StateDescriptions[id]
+	if described {
+		if strings.ContainsAny(desc, "\"\n") {
+			out.line(id, " : ", desc)
+		} else {
+			out.line("state ", forceQuote(desc), " as ", id)
+		}
+	}
+	if cs, ok := graph.CompositeStates[id]; ok {
+		printCompositeState(out, cs)
+		return true
+	}
+	if !related && !annotated && !described {
+		out.line(id)
+		return true
+	}
+	return annotated || described
+}
+
+// printStateEdge writes one transition.
+func printStateEdge(out *writer, edge *ir.Edge) {
+	line := stateToken(edge.From) + " --> " + stateToken(edge.To)
+	if edge.Label != nil && *edge.Label != "" {
+		line += " : " + *edge.Label
+	}
+	out.line(line)
+}
+
+// printCompositeState writes a braced composite state, separating
+// concurrent regions with "--".
+func printCompositeState(out *writer, cs *ir.CompositeState) {
+	out.line("state ", cs.ID, " {")
+	out.indent()
+	if cs.Inner != nil {
+		printStateBody(out, cs.Inner)
+	}
+	for idx, region := range cs.Regions {
+		if idx > 0 {
+			out.line("--")
+		}
+		printStateBody(out, region)
+	}
+	out.dedent()
+	out.line("}")
+}
+
+// stateToken maps pseudo-state IDs back to [*].
+func stateToken(id string) string {
+	if id == stateStartID || id == stateEndID {
+		return "[*]"
+	}
+	return id
+}
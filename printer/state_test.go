@@ -0,0 +1,35 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintStateRoundTrip(t *testing.T) {
+	input := `stateDiagram-v2
+    [*] --> Idle
+    state "Waiting for input" as Idle
+    state Check <<choice>>
+    Idle --> Check : submit
+    Check --> Active
+    state Active {
+        [*] --> Running
+        Running --> [*]
+    }
+    Active --> [*]
+    note right of Idle : ready`
+	out := roundTrip(t, input)
+	for _, want := range []string{
+		"stateDiagram-v2",
+		`state "Waiting for input" as Idle`,
+		"state Check <<choice>>",
+		"Idle --> Check : submit",
+		"state Active {",
+		"[*] --> Running",
+		"note right of Idle : ready",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package printer
+
+import (
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printTimeline writes a timeline: title, then sections of periods.
+func printTimeline(out *writer, graph *ir.Graph) {
+	out.line("timeline")
+	out.indent()
+	if graph.TimelineTitle != "" {
+		out.line("title ", graph.TimelineTitle)
+	}
+	for _, sec := range graph.TimelineSections {
+		if sec.Title != "" {
+			out.line("section ", sec.Title)
+			out.indent()
+		}
+		for _, period := range sec.Periods {
+			line := period.Title + " :"
+			for idx, event := range period.Events {
+				if idx > 0 {
+					line += " :"
+				}
+				line += " " + event.Text
+			}
+			out.line(line)
+		}
+		if sec.Title != "" {
+			out.dedent()
+		}
+	}
+	out.dedent()
+}
@@ -0,0 +1,20 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintTimelineRoundTrip(t *testing.T) {
+	out := roundTrip(t, `timeline
+    title History
+    2002 : LinkedIn
+    section Later
+      2004 : Facebook : Google
+           : Gmail`)
+	for _, want := range []string{"title History", "2002 : LinkedIn", "section Later", "2004 : Facebook : Google : Gmail"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printTreemap writes a treemap as an indented outline of quoted labels,
+// with values on the leaves.
+func printTreemap(out *writer, graph *ir.Graph) {
+	out.line("treemap-beta")
+	out.indent()
+	if graph.TreemapTitle != "" {
+		out.line("title ", graph.TreemapTitle)
+	}
+	if graph.TreemapRoot != nil {
+		printTreemapNode(out, graph.TreemapRoot)
+	}
+	out.dedent()
+}
+
+// printTreemapNode writes a node and, one level deeper, its children.
+func printTreemapNode(out *writer, node *ir.TreemapNode) {
+	quote := `"`
+	if strings.Contains(node.Label, quote) {
+		quote = "'"
+	}
+	line := quote + node.Label + quote
+	if node.Value != 0 {
+		line += ": " + formatNumber(node.Value)
+	}
+	if node.Class != "" {
+		line += ":::" + node.Class
+	}
+	out.line(line)
+	out.indent()
+	for _, child := range node.Children {
+		printTreemapNode(out, child)
+	}
+	out.dedent()
+}
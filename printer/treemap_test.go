@@ -0,0 +1,25 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintTreemapRoundTrip(t *testing.T) {
+	out := roundTrip(t, `treemap-beta
+title Budget
+"Company"
+    "Engineering"
+        'Say "hi"': 200
+    "Sales": 250:::hot`)
+	for _, want := range []string{
+		"title Budget",
+		"    \"Company\"\n        \"Engineering\"\n",
+		`'Say "hi"': 200`,
+		`"Sales": 250:::hot`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
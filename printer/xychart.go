@@ -0,0 +1,59 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// printXYChart writes an XY chart: title, axes, stacking and series.
+func printXYChart(out *writer, graph *ir.Graph) {
+	if graph.XYHorizontal {
+		out.line("xychart-beta horizontal")
+	} else {
+		out.line("xychart-beta")
+	}
+	out.indent()
+	if graph.XYTitle != "" {
+		out.line("title ", forceQuote(graph.XYTitle))
+	}
+	printXYAxis(out, "x-axis", graph.XYXAxis)
+	printXYAxis(out, "y-axis", graph.XYYAxis)
+	printXYAxis(out, "y2-axis", graph.XYY2Axis)
+	if graph.XYStacked {
+		out.line("stacked")
+	}
+	for _, series := range graph.XYSeries {
+		line := series.Type.String()
+		if series.Secondary {
+			line += " y2"
+		}
+		if series.Name != "" {
+			line += " " + forceQuote(series.Name)
+		}
+		values := make([]string, len(series.Values))
+		for idx, value := range series.Values {
+			values[idx] = formatNumber(value)
+		}
+		out.line(line, " [", strings.Join(values, ", "), "]")
+	}
+	out.dedent()
+}
+
+// printXYAxis writes one axis statement, if the axis was declared.
+func printXYAxis(out *writer, keyword string, axis *ir.XYAxis) {
+	if axis == nil {
+		return
+	}
+	parts := []string{keyword}
+	if axis.Title != "" {
+		parts = append(parts, forceQuote(axis.Title))
+	}
+	switch {
+	case axis.Mode == ir.XYAxisBand:
+		parts = append(parts, "["+strings.Join(axis.Categories, ", ")+"]")
+	case axis.Min != 0 || axis.Max != 0:
+		parts = append(parts, formatNumber(axis.Min)+" --> "+formatNumber(axis.Max))
+	}
+	out.line(strings.Join(parts, " "))
+}
@@ -0,0 +1,31 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintXYChartRoundTrip(t *testing.T) {
+	out := roundTrip(t, `xychart-beta horizontal
+    title "Capacity"
+    x-axis [Q1, Q2]
+    y-axis "Requests" 0 --> 2500
+    y2-axis "Latency"
+    stacked
+    bar "EU" [1200, 1500.5]
+    line y2 [180, 220]`)
+	for _, want := range []string{
+		"xychart-beta horizontal",
+		`title "Capacity"`,
+		"x-axis [Q1, Q2]",
+		`y-axis "Requests" 0 --> 2500`,
+		`y2-axis "Latency"`,
+		"stacked",
+		`bar "EU" [1200, 1500.5]`,
+		"line y2 [180, 220]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
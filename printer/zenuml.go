@@ -0,0 +1,231 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/ir"
+)
+
+// zenCall is an open message block while printing ZenUML: the participant
+// that made the call and the one it activated.
+type zenCall struct {
+	caller string
+	target string
+}
+
+// zenPrinter tracks the calling context the ZenUML parser will see, so
+// messages are written in a form that reproduces their sender.
+type zenPrinter struct {
+	out    *writer
+	caller string
+	// blocks holds the open message blocks, nil entries marking frames.
+	blocks []*zenCall
+}
+
+// printZenUML writes a ZenUML diagram: participant declarations, then
+// the calls, returns and control-flow blocks of its event sequence.
+func printZenUML(out *writer, graph *ir.Graph) {
+	out.line("zenuml")
+	out.indent()
+	printZenParticipants(out, graph, zenCreatedTail(graph))
+
+	printer := &zenPrinter{out: out}
+	events := graph.Events
+	for idx := 0; idx < len(events); idx++ {
+		event := events[idx]
+		switch event.Kind {
+		case ir.EvMessage:
+			opened := idx+1 < len(events) && events[idx+1].Kind == ir.EvActivate
+			printer.message(event.Message, opened)
+			if opened {
+				idx++
+			}
+		case ir.EvCreate:
+			if idx+1 < len(events) && events[idx+1].Kind == ir.EvMessage &&
+				events[idx+1].Message.To == event.Target && strings.Contains(events[idx+1].Message.Text, "new "+event.Target+"(") {
+				continue
+			}
+			out.line("new ", event.Target, "()")
+		case ir.EvFrameStart:
+			out.line(zenFrameStart(event.Frame))
+			out.indent()
+			printer.blocks = append(printer.blocks, nil)
+		case ir.EvFrameMiddle:
+			out.dedent()
+			out.line("} ", zenFrameMiddle(event.Frame))
+			out.indent()
+		case ir.EvFrameEnd, ir.EvDeactivate:
+			printer.close()
+		case ir.EvNote, ir.EvActivate, ir.EvDestroy:
+		}
+	}
+	out.dedent()
+}
+
+// zenCreatedTail returns how many participants at the end of the list
+// need no declaration because a "new" call introduces them.
+func zenCreatedTail(graph *ir.Graph) int {
+	created := make(map[string]bool)
+	for _, event := range graph.Events {
+		if event.Kind == ir.EvCreate {
+			created[event.Target] = true
+		}
+	}
+	grouped := make(map[string]bool)
+	for _, box := range graph.Boxes {
+		for _, id := range box.Participants {
+			grouped[id] = true
+		}
+	}
+	tail := 0
+	for idx := len(graph.Participants) - 1; idx >= 0; idx-- {
+		participant := graph.Participants[idx]
+		if !created[participant.ID] || grouped[participant.ID] ||
+			participant.Kind != ir.ParticipantBox || participant.Alias != "" {
+			break
+		}
+		tail++
+	}
+	return tail
+}
+
+// printZenParticipants declares participants in order, wrapping grouped
+// participants in their group block and leaving out the last skip.
+func printZenParticipants(out *writer, graph *ir.Graph, skip int) {
+	boxOf := make(map[string]*ir.SeqBox)
+	for _, box := range graph.Boxes {
+		for _, id := range box.Participants {
+			boxOf[id] = box
+		}
+	}
+	printed := make(map[*ir.SeqBox]bool)
+	for _, participant := range graph.Participants[:len(graph.Participants)-skip] {
+		box, grouped := boxOf[participant.ID]
+		if !grouped {
+			out.line(zenParticipantDecl(participant))
+			continue
+		}
+		if printed[box] {
+			continue
+		}
+		printed[box] = true
+		out.line("group ", box.Label, " {")
+		out.indent()
+		for _, member := range graph.Participants {
+			if boxOf[member.ID] == box {
+				out.line(zenParticipantDecl(member))
+			}
+		}
+		out.dedent()
+		out.line("}")
+	}
+}
+
+// zenParticipantDecl declares a participant with its annotation and alias.
+func zenParticipantDecl(participant *ir.SeqParticipant) string {
+	decl := participant.ID
+	if participant.Kind != ir.ParticipantBox {
+		kind := participant.Kind.String()
+		decl = "@" + strings.ToUpper(kind[:1]) + kind[1:] + " " + decl
+	}
+	if participant.Alias != "" {
+		decl += " as " + participant.Alias
+	}
+	return decl
+}
+
+// message writes one message. Calls are written from the current caller,
+// switching the starter first at the top level when needed; opened calls
+// start a block that the matching deactivation closes.
+func (p *zenPrinter) message(msg *ir.SeqMessage, opened bool) {
+	switch msg.Kind {
+	case ir.MsgSolidOpen:
+		p.out.line(msg.From, "->", msg.To, ": ", msg.Text)
+		return
+	case ir.MsgDottedArrow:
+		if call := p.innermostCall(); call != nil && call.target == msg.From && call.caller == msg.To {
+			p.out.line(strings.TrimSpace("return " + msg.Text))
+		} else {
+			p.out.line("@return ", msg.From, "->", msg.To, ": ", msg.Text)
+		}
+		return
+	}
+
+	if p.caller != msg.From && !(p.caller == "" && msg.From == msg.To) && len(p.blocks) == 0 {
+		p.out.line("@Starter(", msg.From, ")")
+		p.caller = msg.From
+	}
+	text := msg.Text
+	if !strings.Contains(text, "new "+msg.To+"(") {
+		text = zenCallText(msg.To, text)
+	}
+	if !opened {
+		p.out.line(text)
+		return
+	}
+	p.out.line(text, " {")
+	p.out.indent()
+	p.blocks = append(p.blocks, &zenCall{caller: p.caller, target: msg.To})
+	p.caller = msg.To
+}
+
+// close ends the innermost block, restoring the caller of a message block.
+func (p *zenPrinter) close() {
+	p.out.dedent()
+	p.out.line("}")
+	if len(p.blocks) == 0 {
+		return
+	}
+	if call := p.blocks[len(p.blocks)-1]; call != nil {
+		p.caller = call.caller
+	}
+	p.blocks = p.blocks[:len(p.blocks)-1]
+}
+
+// innermostCall returns the innermost open message block, or nil.
+func (p *zenPrinter) innermostCall() *zenCall {
+	for idx := len(p.blocks) - 1; idx >= 0; idx-- {
+		if p.blocks[idx] != nil {
+			return p.blocks[idx]
+		}
+	}
+	return nil
+}
+
+// zenCallText writes a call to target, keeping an assignment prefix such
+// as "result = " in front of the target.
+func zenCallText(target, text string) string {
+	if name, call, ok := strings.Cut(text, " = "); ok && identRe.MatchString(name) {
+		return name + " = " + target + "." + call
+	}
+	return target + "." + text
+}
+
+// zenFrameStart writes the line opening a frame.
+func zenFrameStart(frame *ir.SeqFrame) string {
+	switch frame.Kind {
+	case ir.FrameLoop:
+		if frame.Label == "" {
+			return "loop {"
+		}
+		return "loop(" + frame.Label + ") {"
+	case ir.FrameOpt:
+		return "opt {"
+	case ir.FramePar:
+		return "par {"
+	}
+	if frame.Label == "try" {
+		return "try {"
+	}
+	return "if(" + frame.Label + ") {"
+}
+
+// zenFrameMiddle writes the continuation of an if or try frame after its
+// closing brace.
+func zenFrameMiddle(frame *ir.SeqFrame) string {
+	switch frame.Label {
+	case "else", "catch", "finally":
+		return frame.Label + " {"
+	}
+	return "else if(" + frame.Label + ") {"
+}
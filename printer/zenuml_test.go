@@ -0,0 +1,43 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintZenUMLRoundTrip(t *testing.T) {
+	out := roundTrip(t, `zenuml
+@Actor Client
+group Backend {
+  @Control Job as Runner
+}
+@Starter(Client)
+result = Job.Run() {
+  try {
+    obj = new Worker()
+    Worker->Job: done
+  } catch {
+    @return Job->Client: failed
+  }
+  loop(retry) {
+    Job.Step()
+  }
+  return result
+}`)
+	for _, want := range []string{
+		"    @Actor Client\n    group Backend {\n        @Control Job as Runner\n    }\n",
+		"@Starter(Client)",
+		"result = Job.Run() {",
+		"obj = new Worker()",
+		"Worker->Job: done",
+		"} catch {",
+		"return failed",
+		"loop(retry) {",
+		"Job.Step()",
+		"return result",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package raster converts the SVG produced by the render package into a
+// bitmap using a pure-Go vector rasteriser.
+//
+// The SVG is interpreted by the canvas package, which this package
+// implements a canvas.Backend for, so bitmaps draw exactly what the SVG
+// shows.
+package raster
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/vector"
+
+	"github.com/jamesainslie/gomd2svg/canvas"
+)
+
+// maxPixels bounds the output size to keep a bad scale or viewBox from
+// exhausting memory.
+const maxPixels = 1 << 28
+
+// ErrNotSVG is returned when the input has no <svg> root element.
+var ErrNotSVG = canvas.ErrNotSVG
+
+// Rasterize draws svg onto a new image. The image is the SVG's width and
+// height multiplied by scale; a scale of zero or less means 1.
+func Rasterize(svg string, scale float64) (*image.RGBA, error) {
+	doc, err := canvas.Parse(svg)
+	if err != nil {
+		return nil, err
+	}
+	if scale <= 0 {
+		scale = 1
+	}
+	pixelsX := int(math.Ceil(doc.Width * scale))
+	pixelsY := int(math.Ceil(doc.Height * scale))
+	if pixelsX <= 0 || pixelsY <= 0 {
+		return nil, fmt.Errorf("raster: invalid image size %gx%g", doc.Width, doc.Height)
+	}
+	if pixelsX*pixelsY > maxPixels {
+		return nil, fmt.Errorf("raster: image %dx%d is too large", pixelsX, pixelsY)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, pixelsX, pixelsY))
+	pnt := &painter{dst: dst, raster: vector.NewRasterizer(pixelsX, pixelsY)}
+	doc.Draw(pnt, canvas.Scaling(scale, scale), canvas.Options{})
+	return dst, nil
+}
+
+// PNG rasterises svg and returns it encoded as PNG.
+func PNG(svg string, scale float64) ([]byte, error) {
+	img, err := Rasterize(svg, scale)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// painter is the canvas.Backend that fills an image.
+type painter struct {
+	dst    *image.RGBA
+	raster *vector.Rasterizer
+}
+
+// Fill implements canvas.Backend.
+func (p *painter) Fill(shape canvas.Path, paint color.NRGBA) {
+	lines := shape.Flatten()
+	polys := make([][]canvas.Point, len(lines))
+	for idx, line := range lines {
+		polys[idx] = line.Points
+	}
+	p.fillPolygons(polys, paint)
+}
+
+// Stroke implements canvas.Backend.
+func (p *painter) Stroke(shape canvas.Path, stroke canvas.Stroke, paint color.NRGBA) {
+	p.fillPolygons(stroke.Outline(shape.Flatten()), paint)
+}
+
+// Text implements canvas.Backend.
+func (p *painter) Text(run canvas.TextRun) {
+	p.Fill(run.Outlines(), run.Paint)
+}
+
+// fillPolygons fills device-space polygons with the nonzero rule.
+func (p *painter) fillPolygons(polys [][]canvas.Point, paint color.NRGBA) {
+	if paint.A == 0 || len(polys) == 0 {
+		return
+	}
+	bounds := p.dst.Bounds()
+	p.raster.Reset(bounds.Dx(), bounds.Dy())
+	drawn := false
+	for _, poly := range polys {
+		if len(poly) < 3 { //nolint:mnd // a polygon needs three corners to cover area.
+			continue
+		}
+		p.raster.MoveTo(float32(poly[0].X), float32(poly[0].Y))
+		for _, pt := range poly[1:] {
+			p.raster.LineTo(float32(pt.X), float32(pt.Y))
+		}
+		p.raster.ClosePath()
+		drawn = true
+	}
+	if drawn {
+		p.raster.Draw(p.dst, bounds, image.NewUniform(paint), image.Point{})
+	}
+}
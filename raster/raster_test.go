@@ -0,0 +1,127 @@
+package raster
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// pixel returns the color at x, y as non-premultiplied RGBA.
+func pixel(t *testing.T, svg string, scale float64, x, y int) color.NRGBA {
+	t.Helper()
+	img, err := Rasterize(svg, scale)
+	if err != nil {
+		t.Fatalf("Rasterize() error: %v", err)
+	}
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA) //nolint:forcetypeassert // NRGBAModel always returns NRGBA.
+}
+
+func TestRasterizeSizeAndScale(t *testing.T) {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="30" viewBox="0 0 40 30"></svg>`
+	img, err := Rasterize(svg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got.X != 80 || got.Y != 60 {
+		t.Errorf("size = %v, want 80x60", got)
+	}
+}
+
+func TestRasterizeFilledRect(t *testing.T) {
+	svg := `<svg width="20" height="20"><rect x="5" y="5" width="10" height="10" fill="#ff0000"/></svg>`
+	if got := pixel(t, svg, 1, 10, 10); got != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("inside = %v, want opaque red", got)
+	}
+	if got := pixel(t, svg, 1, 2, 2); got.A != 0 {
+		t.Errorf("outside = %v, want transparent", got)
+	}
+}
+
+func TestRasterizeViewBoxAndTransform(t *testing.T) {
+	svg := `<svg width="20" height="20" viewBox="0 0 10 10">` +
+		`<g transform="translate(5,0)"><rect width="5" height="5" fill="blue"/></g></svg>`
+	if got := pixel(t, svg, 1, 15, 5); got != (color.NRGBA{B: 255, A: 255}) {
+		t.Errorf("translated rect = %v, want opaque blue", got)
+	}
+	if got := pixel(t, svg, 1, 5, 5); got.A != 0 {
+		t.Errorf("untranslated position = %v, want transparent", got)
+	}
+}
+
+func TestRasterizeOpacity(t *testing.T) {
+	svg := `<svg width="10" height="10"><g opacity="0.5"><rect width="10" height="10" fill="black" fill-opacity="0.5"/></g></svg>`
+	got := pixel(t, svg, 1, 5, 5)
+	if got.A < 60 || got.A > 68 {
+		t.Errorf("alpha = %d, want about 64", got.A)
+	}
+}
+
+func TestRasterizeStroke(t *testing.T) {
+	svg := `<svg width="20" height="20"><line x1="0" y1="10" x2="20" y2="10" stroke="lime" stroke-width="4"/></svg>`
+	if got := pixel(t, svg, 1, 10, 9); got != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("on line = %v, want opaque lime", got)
+	}
+	if got := pixel(t, svg, 1, 10, 3); got.A != 0 {
+		t.Errorf("off line = %v, want transparent", got)
+	}
+}
+
+func TestRasterizeMarker(t *testing.T) {
+	svg := `<svg width="40" height="20"><defs>` +
+		`<marker id="m" viewBox="0 0 10 10" refX="10" refY="5" markerUnits="userSpaceOnUse" markerWidth="10" markerHeight="10" orient="auto">` +
+		`<path d="M 0 0 L 10 5 L 0 10 z" fill="red"/></marker></defs>` +
+		`<line x1="0" y1="10" x2="30" y2="10" stroke="none" marker-end="url(#m)"/></svg>`
+	if got := pixel(t, svg, 1, 25, 10); got != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("arrowhead = %v, want opaque red", got)
+	}
+	if got := pixel(t, svg, 1, 32, 10); got.A != 0 {
+		t.Errorf("past the tip = %v, want transparent", got)
+	}
+}
+
+func TestRasterizeText(t *testing.T) {
+	svg := `<svg width="100" height="40"><text x="50" y="20" font-size="20" text-anchor="middle" dominant-baseline="middle" fill="black">MMM</text></svg>`
+	img, err := Rasterize(svg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inked := 0
+	for y := range 40 {
+		for x := range 100 {
+			if _, _, _, alpha := img.At(x, y).RGBA(); alpha > 0 {
+				inked++
+				if x < 15 || x > 85 {
+					t.Fatalf("ink at x=%d, outside the measured run", x)
+				}
+			}
+		}
+	}
+	if inked == 0 {
+		t.Error("expected text to draw glyphs")
+	}
+}
+
+func TestRasterizeNotSVG(t *testing.T) {
+	if _, err := Rasterize("<html></html>", 1); !errors.Is(err, ErrNotSVG) {
+		t.Errorf("error = %v, want ErrNotSVG", err)
+	}
+	if _, err := Rasterize("<svg", 1); err == nil {
+		t.Error("expected error for malformed XML")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	data, err := PNG(`<svg width="3" height="2"></svg>`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 3 || cfg.Height != 2 {
+		t.Errorf("size = %dx%d, want 3x2", cfg.Width, cfg.Height)
+	}
+}
@@ -27,11 +27,12 @@ const (
 	archEllipseYScale       float32 = 0.6
 	archCloudXScale         float32 = 1.2
 	archCloudYScale         float32 = 0.7
+	archIconColor                   = "#78909C"
 )
 
 // renderArchitecture renders all architecture diagram elements: groups,
 // edges, service nodes, and junctions.
-func renderArchitecture(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *config.Layout) {
+func renderArchitecture(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *config.Layout) {
 	data, ok := lay.Diagram.(layout.ArchitectureData)
 	if !ok {
 		return
@@ -61,7 +62,7 @@ func renderArchitecture(builder *svgBuilder, lay *layout.Layout, th *theme.Theme
 		}
 		// Simple icon next to label at top-right corner.
 		if grp.Icon != "" {
-			renderArchIcon(builder, grp.Icon, grp.X+grp.Width-archGroupIconOffsetX, grp.Y+archGroupIconOffsetY, archGroupIconSize)
+			renderArchIcon(builder, cfg, grp.Icon, grp.X+grp.Width-archGroupIconOffsetX, grp.Y+archGroupIconOffsetY, archGroupIconSize)
 		}
 	}
 
@@ -143,7 +144,7 @@ func renderArchitecture(builder *svgBuilder, lay *layout.Layout, th *theme.Theme
 		// Render icon above label if available.
 		svcInfo, hasSvcInfo := data.Services[id]
 		if hasSvcInfo && svcInfo.Icon != "" {
-			renderArchIcon(builder, svcInfo.Icon, node.X, node.Y-archServiceIconOffsetY, archServiceIconSize)
+			renderArchIcon(builder, cfg, svcInfo.Icon, node.X, node.Y-archServiceIconOffsetY, archServiceIconSize)
 		}
 
 		// Render label centered in the service box.
@@ -170,20 +171,24 @@ func renderArchitecture(builder *svgBuilder, lay *layout.Layout, th *theme.Theme
 	}
 }
 
-// renderArchIcon renders a simple SVG icon shape at the given center position.
-func renderArchIcon(builder *svgBuilder, icon string, cx, cy, size float32) {
+// renderArchIcon renders an icon at the given center position: one from
+// the configured icon registry, or else a simple built-in glyph.
+func renderArchIcon(builder *svgBuilder, cfg *config.Layout, icon string, cx, cy, size float32) {
+	if renderIcon(builder, cfg, icon, cx, cy, size, archIconColor) {
+		return
+	}
 	half := size / 2
 	switch icon {
 	case "database":
 		// Simple cylinder representation: ellipse.
 		builder.ellipse(cx, cy, half, half*archEllipseYScale,
-			"fill", "#78909C",
+			"fill", archIconColor,
 			"stroke", "none",
 		)
 	case "server":
 		// Simple box.
 		builder.rect(cx-half, cy-half, size, size, 2,
-			"fill", "#78909C",
+			"fill", archIconColor,
 			"stroke", "none",
 		)
 		// Two horizontal lines inside the box.
@@ -199,22 +204,22 @@ func renderArchIcon(builder *svgBuilder, icon string, cx, cy, size float32) {
 	case "cloud":
 		// Cloud-like ellipse.
 		builder.ellipse(cx, cy, half*archCloudXScale, half*archCloudYScale,
-			"fill", "#78909C",
+			"fill", archIconColor,
 			"stroke", "none",
 		)
 	case "internet":
 		// Globe: circle with a vertical and horizontal cross line.
 		builder.circle(cx, cy, half,
 			"fill", "none",
-			"stroke", "#78909C",
+			"stroke", archIconColor,
 			"stroke-width", "1.5",
 		)
 		builder.line(cx-half, cy, cx+half, cy,
-			"stroke", "#78909C",
+			"stroke", archIconColor,
 			"stroke-width", "1",
 		)
 		builder.line(cx, cy-half, cx, cy+half,
-			"stroke", "#78909C",
+			"stroke", archIconColor,
 			"stroke-width", "1",
 		)
 		// Curved arc approximation for globe effect.
@@ -225,13 +230,13 @@ func renderArchIcon(builder *svgBuilder, icon string, cx, cy, size float32) {
 		)
 		builder.path(pathData,
 			"fill", "none",
-			"stroke", "#78909C",
+			"stroke", archIconColor,
 			"stroke-width", "1",
 		)
 	case "disk":
 		// Filled circle.
 		builder.circle(cx, cy, half,
-			"fill", "#78909C",
+			"fill", archIconColor,
 			"stroke", "none",
 		)
 	}
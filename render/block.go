@@ -4,14 +4,29 @@ import (
 	"sort"
 
 	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/ir"
 	"github.com/jamesainslie/gomd2svg/layout"
 	"github.com/jamesainslie/gomd2svg/theme"
 )
 
-// renderBlock renders a block diagram: edges behind nodes, each node colored
-// by cycling through the theme's BlockColors palette.
+// Block diagram rendering constants.
+const (
+	blockCompositeRadius   float32 = 4
+	blockCompositeLabelPad float32 = 4
+	blockLabelFontScale    float32 = 0.9
+	// blockArrowShaft is a block arrow's shaft thickness as a fraction of
+	// its cross-axis size.
+	blockArrowShaft float32 = 0.5
+	// blockArrowHeadRatio limits a block arrow's head to this fraction of
+	// its length.
+	blockArrowHeadRatio float32 = 0.4
+)
+
+// renderBlock renders a block diagram: composite blocks first, then the
+// other blocks, each colored by cycling through the theme's BlockColors
+// palette, then the edges, which end at block borders, on top.
 func renderBlock(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *config.Layout) {
-	_, ok := lay.Diagram.(layout.BlockData)
+	bd, ok := lay.Diagram.(layout.BlockData)
 	if !ok {
 		return
 	}
@@ -26,9 +41,6 @@ func renderBlock(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *co
 		borderColor = "#3B6492"
 	}
 
-	// Render edges first so they appear behind nodes.
-	renderEdges(builder, lay, th)
-
 	// Sort node IDs for deterministic output.
 	ids := make([]string, 0, len(lay.Nodes))
 	for id := range lay.Nodes {
@@ -36,25 +48,133 @@ func renderBlock(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *co
 	}
 	sort.Strings(ids)
 
-	// Render each node using renderNodeShape with color cycling.
-	for i, id := range ids {
-		node := lay.Nodes[id]
-
-		fill := colors[i%len(colors)]
+	colorsOf := func(idx int, node *layout.NodeLayout) (string, string, string) {
+		fill := colors[idx%len(colors)]
 		if node.Style.Fill != nil {
 			fill = *node.Style.Fill
 		}
-
 		stroke := borderColor
 		if node.Style.Stroke != nil {
 			stroke = *node.Style.Stroke
 		}
-
 		textColor := th.PrimaryTextColor
 		if node.Style.TextColor != nil {
 			textColor = *node.Style.TextColor
 		}
+		return fill, stroke, textColor
+	}
+
+	// Composite blocks go first, outermost (largest) first, so their
+	// children are drawn on top.
+	var composites []int
+	for idx, id := range ids {
+		if bd.BlockInfos[id].HasChildren {
+			composites = append(composites, idx)
+		}
+	}
+	sort.SliceStable(composites, func(left, right int) bool {
+		nodeL, nodeR := lay.Nodes[ids[composites[left]]], lay.Nodes[ids[composites[right]]]
+		return nodeL.Width*nodeL.Height > nodeR.Width*nodeR.Height
+	})
+	for _, idx := range composites {
+		node := lay.Nodes[ids[idx]]
+		fill, stroke, textColor := colorsOf(idx, node)
+		renderBlockComposite(builder, node, fill, stroke, textColor, th)
+	}
 
+	// Render each remaining block with color cycling.
+	for idx, id := range ids {
+		node := lay.Nodes[id]
+		info := bd.BlockInfos[id]
+		if info.HasChildren {
+			continue
+		}
+		fill, stroke, textColor := colorsOf(idx, node)
+		if info.Kind == ir.BlockArrow {
+			strokeWidth := "1"
+			if node.Style.StrokeWidth != nil {
+				strokeWidth = fmtFloat(*node.Style.StrokeWidth)
+			}
+			builder.polygon(blockArrowPoints(node, info.ArrowDir),
+				"class", "block-arrow",
+				"fill", fill,
+				"stroke", stroke,
+				"stroke-width", strokeWidth,
+			)
+			renderNodeLabel(builder, node, textColor)
+			continue
+		}
 		renderNodeShape(builder, node, fill, stroke, textColor)
 	}
+
+	renderEdges(builder, lay, th)
+}
+
+// renderBlockComposite draws a composite block's rectangle with its label,
+// if any, at the top.
+func renderBlockComposite(builder *svgBuilder, node *layout.NodeLayout, fill, stroke, textColor string, th *theme.Theme) {
+	attrs := []string{"class", "block-composite", "fill", fill, "stroke", stroke, "stroke-width", "1"}
+	if node.Style.StrokeDasharray != nil {
+		attrs = append(attrs, "stroke-dasharray", *node.Style.StrokeDasharray)
+	}
+	builder.rect(node.X-node.Width/2, node.Y-node.Height/2, node.Width, node.Height, blockCompositeRadius, attrs...)
+
+	if len(node.Label.Lines) == 0 || node.Label.Lines[0] == "" {
+		return
+	}
+	fontSize := th.FontSize * blockLabelFontScale
+	builder.text(node.X, node.Y-node.Height/2+blockCompositeLabelPad+fontSize, node.Label.Lines[0],
+		"text-anchor", "middle",
+		"font-family", th.FontFamily,
+		"font-size", fmtFloat(fontSize),
+		"font-weight", "bold",
+		"fill", textColor,
+	)
+}
+
+// blockArrowPoints returns the outline of a block arrow filling a node's
+// box and pointing in dir; x and y arrows have a head at both ends.
+func blockArrowPoints(node *layout.NodeLayout, dir ir.BlockArrowDirection) [][2]float32 {
+	left, top := node.X-node.Width/2, node.Y-node.Height/2
+	right, bottom := node.X+node.Width/2, node.Y+node.Height/2
+
+	// Build the outline along a "length" axis u and a "cross" axis v,
+	// centred on v = 0, then map it onto the box.
+	vertical := dir == ir.BlockArrowUp || dir == ir.BlockArrowDown || dir == ir.BlockArrowY
+	length, cross := node.Width, node.Height
+	if vertical {
+		length, cross = node.Height, node.Width
+	}
+	head := min(cross/2, length*blockArrowHeadRatio) //nolint:mnd // head as deep as half the cross size.
+	shaft := cross * blockArrowShaft / 2             //nolint:mnd // half-thickness.
+	half := cross / 2                                //nolint:mnd // half the cross size.
+
+	var outline [][2]float32
+	if dir == ir.BlockArrowX || dir == ir.BlockArrowY {
+		outline = [][2]float32{
+			{0, 0}, {head, -half}, {head, -shaft}, {length - head, -shaft}, {length - head, -half},
+			{length, 0}, {length - head, half}, {length - head, shaft}, {head, shaft}, {head, half},
+		}
+	} else {
+		outline = [][2]float32{
+			{0, -shaft}, {length - head, -shaft}, {length - head, -half},
+			{length, 0}, {length - head, half}, {length - head, shaft}, {0, shaft},
+		}
+	}
+
+	points := make([][2]float32, len(outline))
+	for idx, pt := range outline {
+		along, across := pt[0], pt[1]
+		switch dir {
+		case ir.BlockArrowLeft:
+			points[idx] = [2]float32{right - along, node.Y + across}
+		case ir.BlockArrowDown, ir.BlockArrowY:
+			points[idx] = [2]float32{node.X + across, top + along}
+		case ir.BlockArrowUp:
+			points[idx] = [2]float32{node.X + across, bottom - along}
+		default:
+			points[idx] = [2]float32{left + along, node.Y + across}
+		}
+	}
+	return points
 }
@@ -53,3 +53,31 @@ func TestRenderBlockEmpty(t *testing.T) {
 		t.Error("missing <svg tag")
 	}
 }
+
+func TestRenderBlockArrowsAndComposites(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Block
+	for _, id := range []string{"group", "a", "next"} {
+		label := id
+		graph.EnsureNode(id, &label, nil)
+	}
+	graph.Blocks = append(graph.Blocks,
+		&ir.BlockDef{ID: "group", Label: "Group", Width: 1, Children: []*ir.BlockDef{{ID: "a", Label: "a", Width: 1}}},
+		&ir.BlockDef{ID: "next", Label: "next", Width: 1, Kind: ir.BlockArrow, ArrowDir: ir.BlockArrowRight},
+	)
+	fill := "#abcdef"
+	graph.NodeStyles["a"] = &ir.NodeStyle{Fill: &fill}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	for _, want := range []string{`class="block-composite"`, `class="block-arrow"`, `fill="#abcdef"`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Index(svg, "block-composite") > strings.Index(svg, "#abcdef") {
+		t.Error("composite block should be drawn before its children")
+	}
+}
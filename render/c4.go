@@ -17,6 +17,7 @@ const (
 	c4BoundaryLabelOffset float32 = 8
 	c4BoundaryLabelY      float32 = 16
 	c4BoundaryTypeY       float32 = 30
+	c4BoundaryLineStep    float32 = 14
 	c4BoundaryFontScale   float32 = 0.9
 	c4BoundaryTypeFScale  float32 = 0.8
 	c4NodeBorderRadius    float32 = 6
@@ -27,8 +28,14 @@ const (
 	c4PersonHeadCenterY   float32 = 18
 	c4PersonBodyWidth     float32 = 20
 	c4PersonBodyArcHeight float32 = 24
+	// c4OctagonCut is the corner cut of an EightSidedShape, as a fraction
+	// of the element's smaller side.
+	c4OctagonCut float32 = 0.2
 )
 
+// c4EightSidedShape is the UpdateElementStyle shape drawn as an octagon.
+const c4EightSidedShape = "EightSidedShape"
+
 // renderC4 renders all C4 diagram elements: boundaries, edges, and element nodes
 // with type-specific colors and person icons.
 func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *config.Layout) {
@@ -41,34 +48,14 @@ func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *con
 	lineH := th.FontSize * cfg.LabelLineHeight
 	smallLineH := smallFontSize * cfg.LabelLineHeight
 
-	// 1. Render boundaries first (behind everything).
+	// 1. Render boundaries first (behind everything). Deployment nodes
+	// have solid borders.
 	for _, boundary := range cd.Boundaries {
-		// Dashed rectangle.
-		builder.rect(boundary.X, boundary.Y, boundary.Width, boundary.Height, c4BoundaryRadius,
-			"fill", "none",
-			"stroke", th.C4BoundaryColor,
-			"stroke-width", "1",
-			"stroke-dasharray", "5,5",
-		)
-		// Boundary label at top-left.
-		builder.text(boundary.X+c4BoundaryLabelOffset, boundary.Y+c4BoundaryLabelY, boundary.Label,
-			"font-family", th.FontFamily,
-			"font-size", fmtFloat(th.FontSize*c4BoundaryFontScale),
-			"fill", th.C4BoundaryColor,
-			"font-weight", "bold",
-		)
-		// Type subtitle.
-		if boundary.Type != "" {
-			builder.text(boundary.X+c4BoundaryLabelOffset, boundary.Y+c4BoundaryTypeY, "["+boundary.Type+"]",
-				"font-family", th.FontFamily,
-				"font-size", fmtFloat(th.FontSize*c4BoundaryTypeFScale),
-				"fill", th.C4BoundaryColor,
-			)
-		}
+		renderC4Boundary(builder, boundary, cd.BoundaryStyles[boundary.ID], th)
 	}
 
 	// 2. Render edges.
-	renderEdges(builder, lay, th)
+	renderC4Edges(builder, lay, &cd, th)
 
 	// 3. Render nodes (elements) sorted by ID for deterministic order.
 	ids := make([]string, 0, len(lay.Nodes))
@@ -81,19 +68,28 @@ func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *con
 		node := lay.Nodes[id]
 		elem := cd.Elements[id]
 
-		color := c4ElementColor(elem, th)
+		style := c4ElementStyle(&cd, id, elem)
+		color := c4StyleValue(style.BgColor, c4ElementColor(elem, th))
+		textColor := c4StyleValue(style.FontColor, th.C4TextColor)
+		stroke := c4StyleValue(style.BorderColor, "none")
 
 		// Top-left from center coordinates.
 		posX := node.X - node.Width/2
 		posY := node.Y - node.Height/2
 
-		if elem != nil && elem.Type.IsPerson() {
-			renderC4Person(builder, posX, posY, node.Width, node.Height, color, th)
-		} else {
+		switch {
+		case elem != nil && elem.Type.IsPerson():
+			renderC4Person(builder, posX, posY, node.Width, node.Height, color, stroke, textColor)
+		case style.Shape == c4EightSidedShape:
+			builder.polygon(c4Octagon(posX, posY, node.Width, node.Height),
+				"fill", color,
+				"stroke", stroke,
+			)
+		default:
 			// Rounded rectangle for non-person elements.
 			builder.rect(posX, posY, node.Width, node.Height, c4NodeBorderRadius,
 				"fill", color,
-				"stroke", "none",
+				"stroke", stroke,
 			)
 		}
 
@@ -113,7 +109,7 @@ func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *con
 				"font-family", th.FontFamily,
 				"font-size", fmtFloat(th.FontSize),
 				"font-weight", "bold",
-				"fill", th.C4TextColor,
+				"fill", textColor,
 			)
 			curY += lineH
 		}
@@ -125,7 +121,7 @@ func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *con
 					"text-anchor", "middle",
 					"font-family", th.FontFamily,
 					"font-size", fmtFloat(smallFontSize),
-					"fill", th.C4TextColor,
+					"fill", textColor,
 				)
 				curY += smallLineH
 			}
@@ -136,27 +132,210 @@ func renderC4(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *con
 					"text-anchor", "middle",
 					"font-family", th.FontFamily,
 					"font-size", fmtFloat(smallFontSize),
-					"fill", th.C4TextColor,
+					"fill", textColor,
 				)
 			}
 		}
 	}
+
+	renderC4Legend(builder, &cd, th)
+}
+
+// renderC4Boundary draws a boundary's rectangle, label and subtitle lines,
+// applying any UpdateBoundaryStyle overrides.
+func renderC4Boundary(builder *svgBuilder, boundary *layout.C4BoundaryLayout, style *ir.C4Style, th *theme.Theme) {
+	if style == nil {
+		style = &ir.C4Style{}
+	}
+	textColor := c4StyleValue(style.FontColor, th.C4BoundaryColor)
+	attrs := []string{
+		"fill", c4StyleValue(style.BgColor, "none"),
+		"stroke", c4StyleValue(style.BorderColor, th.C4BoundaryColor),
+		"stroke-width", "1",
+	}
+	if !boundary.Deployment {
+		attrs = append(attrs, "stroke-dasharray", "5,5")
+	}
+	builder.rect(boundary.X, boundary.Y, boundary.Width, boundary.Height, c4BoundaryRadius, attrs...)
+
+	// Boundary label at top-left.
+	builder.text(boundary.X+c4BoundaryLabelOffset, boundary.Y+c4BoundaryLabelY, boundary.Label,
+		"font-family", th.FontFamily,
+		"font-size", fmtFloat(th.FontSize*c4BoundaryFontScale),
+		"fill", textColor,
+		"font-weight", "bold",
+	)
+	// Type subtitle and description.
+	var lines []string
+	if boundary.Type != "" {
+		lines = append(lines, "["+boundary.Type+"]")
+	}
+	if boundary.Description != "" {
+		lines = append(lines, boundary.Description)
+	}
+	curY := boundary.Y + c4BoundaryTypeY
+	for _, line := range lines {
+		builder.text(boundary.X+c4BoundaryLabelOffset, curY, line,
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(th.FontSize*c4BoundaryTypeFScale),
+			"fill", textColor,
+		)
+		curY += c4BoundaryLineStep
+	}
+}
+
+// renderC4Edges draws the relationships like renderEdges, using the line
+// and text colours of their UpdateRelStyle statements.
+func renderC4Edges(builder *svgBuilder, lay *layout.Layout, cd *layout.C4Data, th *theme.Theme) {
+	for edgeIdx, edge := range lay.Edges {
+		if len(edge.Points) < 2 {
+			continue
+		}
+		lineColor, textColor := th.LineColor, th.LabelTextColor
+		if edgeIdx < len(cd.Rels) && cd.Rels[edgeIdx] != nil {
+			lineColor = c4StyleValue(cd.Rels[edgeIdx].LineColor, lineColor)
+			textColor = c4StyleValue(cd.Rels[edgeIdx].TextColor, textColor)
+		}
+
+		attrs := []string{
+			"id", fmt.Sprintf("edge-%d", edgeIdx),
+			"class", "edgePath",
+			"d", pointsToPath(edge.Points),
+			"fill", "none",
+			"stroke", lineColor,
+			"stroke-width", "1.5",
+			"stroke-linecap", "round",
+			"stroke-linejoin", "round",
+		}
+		if edge.ArrowEnd {
+			attrs = append(attrs, "marker-end", "url(#arrowhead)")
+		}
+		if edge.ArrowStart {
+			attrs = append(attrs, "marker-start", "url(#arrowhead-start)")
+		}
+		builder.selfClose("path", attrs...)
+
+		if edge.Label == nil || len(edge.Label.Lines) == 0 {
+			continue
+		}
+		label := edge.Label
+		anchorX, anchorY := edge.LabelAnchor[0], edge.LabelAnchor[1]
+		bgW := label.Width + edgeLabelPadX*2
+		bgH := label.Height + edgeLabelPadY*2
+		builder.rect(anchorX-bgW/2, anchorY-bgH/2, bgW, bgH, 2,
+			"fill", th.EdgeLabelBackground,
+			"stroke", "none",
+		)
+		lineHeight := label.FontSize * edgeLabelLineHeight
+		startY := anchorY - lineHeight*float32(len(label.Lines))/2 + lineHeight*edgeLabelBaselineShift
+		for idx, line := range label.Lines {
+			builder.text(anchorX, startY+float32(idx)*lineHeight, line,
+				"text-anchor", "middle",
+				"fill", textColor,
+				"font-size", fmtFloat(label.FontSize),
+			)
+		}
+	}
+}
+
+// renderC4Legend draws a swatch and text for each legend entry. Element
+// swatches take the element's fill; boundary swatches its border.
+func renderC4Legend(builder *svgBuilder, cd *layout.C4Data, th *theme.Theme) {
+	for _, entry := range cd.Legend {
+		attrs := []string{"class", "c4-legend-swatch"}
+		if entry.Boundary {
+			style := cd.BoundaryStyles[entry.Key]
+			attrs = append(attrs,
+				"fill", c4StyleValue(style.BgColor, "none"),
+				"stroke", c4StyleValue(style.BorderColor, th.C4BoundaryColor),
+				"stroke-dasharray", "3,3",
+			)
+		} else {
+			style := cd.ElementStyles[entry.Key]
+			fill := c4ElementColor(cd.Elements[entry.Key], th)
+			if cd.Elements[entry.Key] == nil {
+				fill = c4ElementColor(&ir.C4Element{Type: c4TypeForStyleKey(entry.Key)}, th)
+			}
+			attrs = append(attrs,
+				"fill", c4StyleValue(style.BgColor, fill),
+				"stroke", c4StyleValue(style.BorderColor, "none"),
+			)
+		}
+		builder.rect(entry.X, entry.Y, cd.LegendSwatch, cd.LegendSwatch, 2, attrs...)
+		builder.text(entry.TextX, entry.Y+cd.LegendSwatch/2, entry.Text,
+			"class", "c4-legend",
+			"dominant-baseline", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(th.FontSize*c4SmallFontScale),
+			"fill", th.TextColor,
+		)
+	}
+}
+
+// c4ElementStyle merges the UpdateElementStyle overrides for an element:
+// those naming its ID win over those naming its type.
+func c4ElementStyle(cd *layout.C4Data, id string, elem *ir.C4Element) ir.C4Style {
+	var merged ir.C4Style
+	var styles []*ir.C4Style
+	if elem != nil {
+		styles = append(styles, cd.ElementStyles[elem.Type.StyleKey()])
+	}
+	styles = append(styles, cd.ElementStyles[id])
+	for _, style := range styles {
+		if style == nil {
+			continue
+		}
+		merged.BgColor = c4StyleValue(style.BgColor, merged.BgColor)
+		merged.FontColor = c4StyleValue(style.FontColor, merged.FontColor)
+		merged.BorderColor = c4StyleValue(style.BorderColor, merged.BorderColor)
+		merged.Shape = c4StyleValue(style.Shape, merged.Shape)
+	}
+	return merged
+}
+
+// c4TypeForStyleKey returns an element type with the given style key.
+func c4TypeForStyleKey(key string) ir.C4ElementType {
+	for elemType := ir.C4Person; elemType <= ir.C4ExternalComponent; elemType++ {
+		if elemType.StyleKey() == key {
+			return elemType
+		}
+	}
+	return ir.C4System
+}
+
+// c4StyleValue returns value, or fallback when value is empty.
+func c4StyleValue(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// c4Octagon returns the corners of an eight-sided element shape.
+func c4Octagon(posX, posY, width, height float32) [][2]float32 {
+	cut := min(width, height) * c4OctagonCut
+	return [][2]float32{
+		{posX + cut, posY}, {posX + width - cut, posY},
+		{posX + width, posY + cut}, {posX + width, posY + height - cut},
+		{posX + width - cut, posY + height}, {posX + cut, posY + height},
+		{posX, posY + height - cut}, {posX, posY + cut},
+	}
 }
 
 // renderC4Person draws a C4 person shape: a filled rounded rectangle with a
 // person icon (circle head + arc body) centered at the top.
-func renderC4Person(builder *svgBuilder, posX, posY, width, height float32, color string, th *theme.Theme) {
+func renderC4Person(builder *svgBuilder, posX, posY, width, height float32, color, stroke, iconColor string) {
 	// Body rectangle (rounded).
 	builder.rect(posX, posY, width, height, c4PersonNodeRadius,
 		"fill", color,
-		"stroke", "none",
+		"stroke", stroke,
 	)
 
 	// Person icon: head circle.
 	cx := posX + width/2
 	headCY := posY + c4PersonHeadCenterY
 	builder.circle(cx, headCY, c4PersonHeadRadius,
-		"fill", th.C4TextColor,
+		"fill", iconColor,
 	)
 
 	// Person icon: body arc (simple path).
@@ -168,7 +347,7 @@ func renderC4Person(builder *svgBuilder, posX, posY, width, height float32, colo
 	)
 	builder.path(pathData,
 		"fill", "none",
-		"stroke", th.C4TextColor,
+		"stroke", iconColor,
 		"stroke-width", "2",
 		"stroke-linecap", "round",
 	)
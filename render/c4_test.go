@@ -74,3 +74,49 @@ func TestRenderC4Empty(t *testing.T) {
 		t.Error("missing <svg tag")
 	}
 }
+
+func TestRenderC4Styles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.C4
+	graph.C4Elements = []*ir.C4Element{
+		{ID: "user", Label: "User", Type: ir.C4Person},
+		{ID: "legacy", Label: "Legacy", Type: ir.C4ExternalSystem},
+	}
+	for _, elem := range graph.C4Elements {
+		graph.EnsureNode(elem.ID, &elem.Label, nil)
+	}
+	graph.C4Boundaries = []*ir.C4Boundary{
+		{ID: "dc", Label: "Data Centre", Deployment: true, Children: []string{"legacy"}},
+	}
+	label := "Uses"
+	graph.C4Rels = []*ir.C4Rel{{From: "user", To: "legacy", Label: label, LineColor: "#1E88E5", TextColor: "#FF5722"}}
+	graph.Edges = []*ir.Edge{{From: "user", To: "legacy", Label: &label, Directed: true, ArrowEnd: true}}
+	graph.C4ElementStyles = map[string]*ir.C4Style{
+		"external_system": {BgColor: "#8B0000", Shape: "EightSidedShape", LegendText: "Third party"},
+		"legacy":          {FontColor: "#FFFF00"},
+	}
+	graph.C4BoundaryStyles = map[string]*ir.C4Style{"dc": {BorderColor: "#2E7D32"}}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	for _, want := range []string{
+		`<polygon`,       // eight-sided shape
+		`fill="#8B0000"`, // type style
+		`fill="#FFFF00"`, // element style merged over the type style
+		`stroke="#2E7D32"`,
+		`stroke="#1E88E5"`,
+		`fill="#FF5722"`,
+		`class="c4-legend-swatch"`,
+		`>Third party<`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	// Deployment nodes have solid borders.
+	if strings.Contains(svg, `stroke-dasharray="5,5"`) {
+		t.Error("deployment node should not be dashed")
+	}
+}
@@ -2,6 +2,7 @@ package render
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/layout"
@@ -14,6 +15,8 @@ const (
 	ganttSectionLabelGap float32 = 5
 	ganttAxisLabelOffset float32 = 12
 	ganttTaskLabelGap    float32 = 4
+	ganttTopAxisOffset   float32 = 6
+	ganttMarkerLabelGap  float32 = 4
 )
 
 func renderGantt(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *config.Layout) {
@@ -64,6 +67,14 @@ func renderGantt(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *co
 			"font-size", "9",
 			"fill", th.TextColor,
 		)
+		if gd.TopAxis {
+			builder.text(tick.X, gd.ChartY-ganttTopAxisOffset, tick.Label,
+				"text-anchor", "middle",
+				"font-family", th.FontFamily,
+				"font-size", "9",
+				"fill", th.TextColor,
+			)
+		}
 	}
 
 	// Task bars.
@@ -81,6 +92,7 @@ func renderGantt(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *co
 			if task.IsActive {
 				fill = th.GanttActiveFill
 			}
+			clickable := openGanttClick(builder, &task)
 
 			if task.IsMilestone {
 				// Render as diamond.
@@ -113,15 +125,74 @@ func renderGantt(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *co
 				"font-size", fmtFloat(th.FontSize-3),
 				"fill", th.TextColor,
 			)
+			if clickable {
+				builder.closeTag("a")
+			}
 		}
 	}
 
+	renderGanttMarkers(builder, &gd, th)
+
 	// Today marker.
 	if gd.ShowTodayMarker {
-		builder.line(gd.TodayMarkerX, gd.ChartY, gd.TodayMarkerX, gd.ChartY+gd.ChartHeight,
+		attrs := []string{
+			"class", "today",
 			"stroke", th.GanttTodayMarkerColor,
 			"stroke-width", "2",
 			"stroke-dasharray", "4,4",
+		}
+		// Inline style overrides the defaults, as in Mermaid.
+		if gd.TodayMarkerStyle != "" {
+			attrs = append(attrs, "style", strings.ReplaceAll(gd.TodayMarkerStyle, ",", ";"))
+		}
+		builder.line(gd.TodayMarkerX, gd.ChartY, gd.TodayMarkerX, gd.ChartY+gd.ChartHeight, attrs...)
+	}
+}
+
+// openGanttClick opens an <a> element around a task with a click action
+// and reports whether it did. A callback cannot run in a static SVG, so it
+// is recorded in data attributes for the host page to bind.
+func openGanttClick(builder *svgBuilder, task *layout.GanttTaskLayout) bool {
+	if task.Link == nil && task.Callback == nil {
+		return false
+	}
+	attrs := []string{"class", "gantt-click"}
+	if task.Link != nil {
+		attrs = append(attrs, "href", task.Link.URL)
+	}
+	if task.Callback != nil {
+		attrs = append(attrs, "data-callback", task.Callback.Name)
+		if len(task.Callback.Args) > 0 {
+			attrs = append(attrs, "data-callback-args", strings.Join(task.Callback.Args, ","))
+		}
+	}
+	builder.openTag("a", attrs...)
+	if task.Link != nil && task.Link.Title != nil {
+		builder.openTag("title")
+		builder.content(*task.Link.Title)
+		builder.closeTag("title")
+	}
+	return true
+}
+
+// renderGanttMarkers draws the vert task lines across every section, with
+// their labels above the chart and above any top axis labels.
+func renderGanttMarkers(builder *svgBuilder, gd *layout.GanttData, th *theme.Theme) {
+	labelY := gd.ChartY - ganttMarkerLabelGap
+	if gd.TopAxis {
+		labelY -= ganttAxisLabelOffset
+	}
+	for _, marker := range gd.Markers {
+		builder.line(marker.X, gd.ChartY, marker.X, gd.ChartY+gd.ChartHeight,
+			"class", "gantt-vert",
+			"stroke", th.GanttMilestoneFill,
+			"stroke-width", "2",
+		)
+		builder.text(marker.X, labelY, marker.Label,
+			"text-anchor", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(th.FontSize-3),
+			"fill", th.TextColor,
 		)
 	}
 }
@@ -3,6 +3,7 @@ package render
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -43,3 +44,57 @@ func TestRenderGantt(t *testing.T) {
 		t.Error("missing task bars")
 	}
 }
+
+func TestRenderGanttClicksAndMarkers(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "YYYY-MM-DD"
+	graph.GanttTopAxis = true
+	graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+		{ID: "t1", Label: "Design", StartStr: "2024-01-01", EndStr: "10d"},
+		{ID: "t2", Label: "Code", StartStr: "2024-01-11", EndStr: "5d"},
+		{Label: "Freeze", Tags: []string{"vert"}, StartStr: "2024-01-08", EndStr: "0d"},
+	}}}
+	graph.NodeLinks["t1"] = &ir.NodeLink{URL: "https://example.com/design"}
+	graph.GanttCallbacks = map[string]*ir.GanttCallback{"t2": {Name: "show", Args: []string{"t2", "5"}}}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if !strings.Contains(svg, `<a class="gantt-click" href="https://example.com/design">`) {
+		t.Error("missing link around the Design bar")
+	}
+	if !strings.Contains(svg, `data-callback="show" data-callback-args="t2,5"`) {
+		t.Error("missing callback attributes on the Code bar")
+	}
+	if strings.Count(svg, "</a>") != 2 {
+		t.Errorf("got %d closed links, want 2", strings.Count(svg, "</a>"))
+	}
+	if !strings.Contains(svg, `class="gantt-vert"`) || !strings.Contains(svg, ">Freeze<") {
+		t.Error("missing vert marker line or label")
+	}
+	// With topAxis every tick label appears above and below the chart.
+	if strings.Count(svg, ">2024-01-01<") != 2 {
+		t.Errorf("tick label appears %d times, want 2", strings.Count(svg, ">2024-01-01<"))
+	}
+}
+
+func TestRenderGanttTodayMarkerStyle(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Gantt
+	graph.GanttDateFormat = "YYYY-MM-DD"
+	graph.GanttTodayMarker = "stroke-width:5px,stroke:#0f0"
+	graph.GanttSections = []*ir.GanttSection{{Tasks: []*ir.GanttTask{
+		{Label: "Design", StartStr: "2024-01-01", EndStr: "10d"},
+	}}}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	cfg.Gantt.Now = func() time.Time { return time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC) }
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if !strings.Contains(svg, `class="today"`) || !strings.Contains(svg, `style="stroke-width:5px;stroke:#0f0"`) {
+		t.Errorf("missing styled today marker in:\n%s", svg)
+	}
+}
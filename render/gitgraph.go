@@ -26,9 +26,14 @@ func renderGitGraph(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cf
 	}
 
 	commitRadius := cfg.GitGraph.CommitRadius
+	vertical := ggd.Direction == ir.TopDown || ggd.Direction == ir.BottomTop
 
 	// Draw branch lines.
 	for _, br := range ggd.Branches {
+		if vertical {
+			renderGitVerticalBranch(builder, br, ggd.Direction, th)
+			continue
+		}
 		if br.StartX < br.EndX {
 			builder.line(br.StartX, br.Y, br.EndX, br.Y,
 				"stroke", br.Color,
@@ -105,10 +110,15 @@ func renderGitGraph(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cf
 			)
 		}
 
-		// Tag label.
+		// Tag label, above the commit on horizontal lanes and to its left
+		// on vertical ones.
 		if commit.Tag != "" {
 			tagX := commit.X
 			tagY := commit.Y - commitRadius - gitTagPadding
+			if vertical {
+				tagX = commit.X - commitRadius - gitTagPadding - gitTagHalfWidth
+				tagY = commit.Y + gitTagOffsetY - gitTagRectHeight/2
+			}
 			builder.rect(tagX-gitTagHalfWidth, tagY-gitTagOffsetY, gitTagRectWidth, gitTagRectHeight, gitTagBorderRadius,
 				"fill", th.GitTagFill,
 				"stroke", th.GitTagBorder,
@@ -124,3 +134,28 @@ func renderGitGraph(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cf
 		}
 	}
 }
+
+// renderGitVerticalBranch draws a vertical branch lane and its label,
+// rotated to read along the lane from where the branch starts.
+func renderGitVerticalBranch(builder *svgBuilder, br layout.GitGraphBranchLayout, direction ir.Direction, th *theme.Theme) {
+	if br.StartY < br.EndY {
+		builder.line(br.X, br.StartY, br.X, br.EndY,
+			"stroke", br.Color,
+			"stroke-width", "2",
+		)
+	}
+	// History runs down in TB, so the label sits above the first commit;
+	// in BT it sits below it.
+	labelY, anchor := br.StartY-gitBranchLabelOffset, "start"
+	if direction == ir.BottomTop {
+		labelY, anchor = br.EndY+gitBranchLabelOffset, "end"
+	}
+	builder.text(br.X, labelY, br.Name,
+		"text-anchor", anchor,
+		"dominant-baseline", "middle",
+		"transform", "rotate(-90 "+fmtFloat(br.X)+" "+fmtFloat(labelY)+")",
+		"font-family", th.FontFamily,
+		"font-size", fmtFloat(th.FontSize-2),
+		"fill", th.TextColor,
+	)
+}
@@ -41,3 +41,33 @@ func TestRenderGitGraph(t *testing.T) {
 		t.Error("missing branch label")
 	}
 }
+
+func TestRenderGitGraphVertical(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.GitGraph
+	graph.GitMainBranch = "main"
+	graph.GitDirection = ir.BottomTop
+	graph.GitActions = []ir.GitAction{
+		&ir.GitCommit{ID: "c1", Tag: "v1.0"},
+		&ir.GitCommit{ID: "c2"},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	l := layout.ComputeLayout(graph, th, cfg)
+	svg := RenderSVG(l, th, cfg)
+
+	ggd := l.Diagram.(layout.GitGraphData)
+	mainLane := ggd.Branches[0]
+	if !strings.Contains(svg, `transform="rotate(-90 `) || !strings.Contains(svg, `text-anchor="end"`) {
+		t.Error("BT branch label should be rotated and end-anchored")
+	}
+	if !strings.Contains(svg, `x1="`+fmtFloat(mainLane.X)+`"`) {
+		t.Error("missing vertical branch line")
+	}
+	// The tag box sits left of the commit.
+	commit := ggd.Commits[0]
+	if !strings.Contains(svg, `x="`+fmtFloat(commit.X-cfg.GitGraph.CommitRadius-gitTagPadding-gitTagRectWidth)+`"`) {
+		t.Error("tag box should sit left of the commit")
+	}
+}
@@ -21,10 +21,12 @@ const (
 	edgeLabelFontScale     float32 = 0.85
 	edgeLabelLineHeight    float32 = 1.2
 	edgeLabelBaselineShift float32 = 0.75
+	iconFormRadius         float32 = 8
+	iconPlaceholderDash            = "4,3"
 )
 
 // renderGraph renders all flowchart/graph elements: subgraphs, edges, and nodes.
-func renderGraph(builder *svgBuilder, computed *layout.Layout, th *theme.Theme, _ *config.Layout) {
+func renderGraph(builder *svgBuilder, computed *layout.Layout, th *theme.Theme, cfg *config.Layout) {
 	// Render subgraphs first (they appear behind nodes and edges).
 	renderSubgraphs(builder, computed, th)
 
@@ -32,7 +34,7 @@ func renderGraph(builder *svgBuilder, computed *layout.Layout, th *theme.Theme,
 	renderEdges(builder, computed, th)
 
 	// Render nodes (on top of edges).
-	renderNodes(builder, computed, th)
+	renderNodes(builder, computed, th, cfg)
 }
 
 // renderSubgraphs renders subgraph containers as rectangles with labels.
@@ -141,7 +143,7 @@ func renderEdgeLabel(builder *svgBuilder, edge *layout.EdgeLayout, th *theme.The
 }
 
 // renderNodes renders all nodes sorted by ID for deterministic output.
-func renderNodes(builder *svgBuilder, computed *layout.Layout, th *theme.Theme) {
+func renderNodes(builder *svgBuilder, computed *layout.Layout, th *theme.Theme, cfg *config.Layout) {
 	// Sort node IDs for deterministic rendering order.
 	ids := make([]string, 0, len(computed.Nodes))
 	for id := range computed.Nodes {
@@ -168,6 +170,50 @@ func renderNodes(builder *svgBuilder, computed *layout.Layout, th *theme.Theme)
 			textColor = *node.Style.TextColor
 		}
 
+		if node.Icon != nil {
+			renderIconNode(builder, node, fill, stroke, textColor, cfg)
+			continue
+		}
 		renderNodeShape(builder, node, fill, stroke, textColor)
 	}
 }
+
+// renderIconNode draws a flowchart icon node: the icon in its background
+// form, if any, with the label below it, or above it for pos: t. An icon
+// the registry does not resolve without a form shows a dashed placeholder.
+func renderIconNode(builder *svgBuilder, node *layout.NodeLayout, fill, stroke, textColor string, cfg *config.Layout) {
+	top := node.Y - node.Height/2
+	bottom := node.Y + node.Height/2
+	boxTop := top
+	if node.Icon.LabelTop {
+		boxTop = bottom - node.IconBox
+	}
+	boxCenterY := boxTop + node.IconBox/2
+	half := node.IconBox / 2
+
+	switch node.Icon.Form {
+	case "circle":
+		builder.circle(node.X, boxCenterY, half, "fill", fill, "stroke", stroke, "stroke-width", "1")
+	case "square":
+		builder.rect(node.X-half, boxTop, node.IconBox, node.IconBox, 0, "fill", fill, "stroke", stroke, "stroke-width", "1")
+	case "rounded":
+		builder.rect(node.X-half, boxTop, node.IconBox, node.IconBox, iconFormRadius, "fill", fill, "stroke", stroke, "stroke-width", "1")
+	}
+	if !renderIcon(builder, cfg, node.Icon.Name, node.X, boxCenterY, node.IconSize, textColor) && node.Icon.Form == "" {
+		builder.rect(node.X-node.IconSize/2, boxCenterY-node.IconSize/2, node.IconSize, node.IconSize, 0,
+			"fill", "none",
+			"stroke", stroke,
+			"stroke-width", "1",
+			"stroke-dasharray", iconPlaceholderDash,
+		)
+	}
+
+	// Centre the label in the space the icon leaves.
+	label := *node
+	if node.Icon.LabelTop {
+		label.Y = top + node.Label.Height/2
+	} else {
+		label.Y = bottom - node.Label.Height/2
+	}
+	renderNodeLabel(builder, &label, textColor)
+}
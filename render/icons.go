@@ -0,0 +1,122 @@
+package render
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/icons"
+	"github.com/jamesainslie/gomd2svg/layout"
+)
+
+// iconSymbolID returns the ID of the <symbol> holding an icon. References
+// that resolve to the same icon, like "fa:book" and "fa fa-book", share it.
+func iconSymbolID(ref string) string {
+	prefix, name, _ := icons.SplitName(ref)
+	return "icon-" + symbolIDPart(prefix) + "--" + symbolIDPart(name)
+}
+
+// symbolIDPart replaces characters not allowed in an ID with dashes.
+func symbolIDPart(text string) string {
+	return strings.Map(func(char rune) rune {
+		if char == '-' || char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
+			return char
+		}
+		return '-'
+	}, text)
+}
+
+// layoutIconRefs returns the icon references a layout uses, sorted and
+// without duplicates.
+func layoutIconRefs(computed *layout.Layout) []string {
+	seen := make(map[string]bool)
+	for _, node := range computed.Nodes {
+		if node.Icon != nil && node.Icon.Name != "" {
+			seen[node.Icon.Name] = true
+		}
+	}
+	switch data := computed.Diagram.(type) {
+	case layout.ArchitectureData:
+		for _, svc := range data.Services {
+			if svc.Icon != "" {
+				seen[svc.Icon] = true
+			}
+		}
+		for _, grp := range data.Groups {
+			if grp.Icon != "" {
+				seen[grp.Icon] = true
+			}
+		}
+	case layout.MindmapData:
+		var walk func(node *layout.MindmapNodeLayout)
+		walk = func(node *layout.MindmapNodeLayout) {
+			if node.Icon != "" {
+				seen[node.Icon] = true
+			}
+			for _, child := range node.Children {
+				walk(child)
+			}
+		}
+		if data.Root != nil {
+			walk(data.Root)
+		}
+	}
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// renderIconDefs writes a <defs> block with a <symbol> for each icon the
+// layout uses that the configured registry resolves. Nothing is written
+// when no icon resolves.
+func renderIconDefs(builder *svgBuilder, computed *layout.Layout, cfg *config.Layout) {
+	if cfg == nil || cfg.Icons == nil {
+		return
+	}
+	written := make(map[string]bool)
+	opened := false
+	for _, ref := range layoutIconRefs(computed) {
+		icon, ok := cfg.Icons.Lookup(ref)
+		id := iconSymbolID(ref)
+		if !ok || written[id] {
+			continue
+		}
+		if !opened {
+			builder.openTag("defs")
+			opened = true
+		}
+		written[id] = true
+		builder.openTag("symbol", "id", id, "viewBox", icon.ViewBox())
+		// Icon bodies are SVG markup from the registered icon sets.
+		builder.raw(icon.Body)
+		builder.closeTag("symbol")
+	}
+	if opened {
+		builder.closeTag("defs")
+	}
+}
+
+// renderIcon draws a registered icon as a <use> of its symbol, fitted in a
+// size x size box centred on (cx, cy). Icons painting with currentColor
+// take color. It reports false, drawing nothing, when the configured
+// registry does not resolve ref.
+func renderIcon(builder *svgBuilder, cfg *config.Layout, ref string, cx, cy, size float32, color string) bool {
+	if cfg == nil {
+		return false
+	}
+	if _, ok := cfg.Icons.Lookup(ref); !ok {
+		return false
+	}
+	builder.selfClose("use",
+		"href", "#"+iconSymbolID(ref),
+		"x", fmtFloat(cx-size/2),
+		"y", fmtFloat(cy-size/2),
+		"width", fmtFloat(size),
+		"height", fmtFloat(size),
+		"color", color,
+	)
+	return true
+}
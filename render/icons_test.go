@@ -0,0 +1,54 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamesainslie/gomd2svg/config"
+	"github.com/jamesainslie/gomd2svg/icons"
+	"github.com/jamesainslie/gomd2svg/ir"
+	"github.com/jamesainslie/gomd2svg/layout"
+	"github.com/jamesainslie/gomd2svg/theme"
+)
+
+func iconNodeGraph(ref string) *ir.Graph {
+	graph := ir.NewGraph()
+	label := "Lambda"
+	graph.EnsureNode("fn", &label, nil)
+	graph.Nodes["fn"].Icon = &ir.NodeIcon{Name: ref, Form: "square"}
+	return graph
+}
+
+func TestRenderIconNodeUsesSymbol(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	cfg.Icons = icons.NewRegistry()
+	if err := cfg.Icons.Load([]byte(`{"prefix": "test", "icons": {"dot": {"body": "<circle cx=\"8\" cy=\"8\" r=\"6\"/>"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	svg := RenderSVG(layout.ComputeLayout(iconNodeGraph("test:dot"), th, cfg), th, cfg)
+
+	for _, want := range []string{`<symbol id="icon-test--dot" viewBox="0 0 16 16">`, `<use href="#icon-test--dot"`, "Lambda"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Count(svg, "<symbol") != 1 {
+		t.Errorf("want one symbol, got %d", strings.Count(svg, "<symbol"))
+	}
+}
+
+func TestRenderIconNodeUnresolved(t *testing.T) {
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	graph := iconNodeGraph("test:missing")
+	graph.Nodes["fn"].Icon.Form = ""
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if strings.Contains(svg, "<symbol") || strings.Contains(svg, "<use") {
+		t.Error("unresolved icon should not be inlined")
+	}
+	if !strings.Contains(svg, iconPlaceholderDash) {
+		t.Error("unresolved icon without a form should draw a placeholder")
+	}
+}
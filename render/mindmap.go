@@ -19,7 +19,7 @@ const (
 	mindmapCloudScale    float32 = 1.1
 )
 
-func renderMindmap(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *config.Layout) {
+func renderMindmap(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *config.Layout) {
 	md, ok := lay.Diagram.(layout.MindmapData)
 	if !ok || md.Root == nil {
 		return
@@ -34,7 +34,7 @@ func renderMindmap(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *
 	renderMindmapConnections(builder, md.Root, branchColors)
 
 	// Draw nodes.
-	renderMindmapNode(builder, md.Root, branchColors, th)
+	renderMindmapNode(builder, md.Root, branchColors, th, cfg)
 }
 
 func renderMindmapConnections(builder *svgBuilder, node *layout.MindmapNodeLayout, colors []string) {
@@ -59,8 +59,16 @@ func renderMindmapConnections(builder *svgBuilder, node *layout.MindmapNodeLayou
 	}
 }
 
-func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colors []string, th *theme.Theme) {
+func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colors []string, th *theme.Theme, cfg *config.Layout) {
 	color := colors[node.ColorIndex%len(colors)]
+	// A node's ::: classes override the theme fill and the branch colour.
+	fill := th.MindmapNodeFill
+	if node.Style.Fill != nil {
+		fill = *node.Style.Fill
+	}
+	if node.Style.Stroke != nil {
+		color = *node.Style.Stroke
+	}
 	cx := node.X
 	cy := node.Y
 	hw := node.Width / 2
@@ -69,13 +77,13 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 	switch node.Shape {
 	case ir.MindmapSquare:
 		builder.rect(cx-hw, cy-hh, node.Width, node.Height, 0,
-			"fill", th.MindmapNodeFill,
+			"fill", fill,
 			"stroke", color,
 			"stroke-width", "2",
 		)
 	case ir.MindmapRounded:
 		builder.rect(cx-hw, cy-hh, node.Width, node.Height, mindmapRoundedRadius,
-			"fill", th.MindmapNodeFill,
+			"fill", fill,
 			"stroke", color,
 			"stroke-width", "2",
 		)
@@ -85,7 +93,7 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 			radius = hh
 		}
 		builder.circle(cx, cy, radius,
-			"fill", th.MindmapNodeFill,
+			"fill", fill,
 			"stroke", color,
 			"stroke-width", "2",
 		)
@@ -100,7 +108,7 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 			}
 		}
 		builder.polygon(pts,
-			"fill", th.MindmapNodeFill,
+			"fill", fill,
 			"stroke", color,
 			"stroke-width", "2",
 		)
@@ -110,23 +118,31 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 		if hh > radius {
 			radius = hh
 		}
+		bangFill := color
+		if node.Style.Fill != nil {
+			bangFill = fill
+		}
 		builder.circle(cx, cy, radius,
-			"fill", color,
+			"fill", bangFill,
 			"stroke", color,
 			"stroke-width", "4",
 		)
 	case ir.MindmapCloud:
 		// Cloud-like shape: ellipse with dashed stroke.
 		builder.ellipse(cx, cy, hw*mindmapCloudScale, hh*mindmapCloudScale,
-			"fill", th.MindmapNodeFill,
+			"fill", fill,
 			"stroke", color,
 			"stroke-width", "2",
 			"stroke-dasharray", "4 2",
 		)
-	default: // MindmapShapeDefault - no border, just text background.
+	default: // MindmapShapeDefault - no border unless a class gives one, just text background.
+		stroke := "none"
+		if node.Style.Stroke != nil {
+			stroke = color
+		}
 		builder.rect(cx-hw, cy-hh, node.Width, node.Height, mindmapDefaultRadius,
-			"fill", th.MindmapNodeFill,
-			"stroke", "none",
+			"fill", fill,
+			"stroke", stroke,
 		)
 	}
 
@@ -135,7 +151,16 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 	if node.Shape == ir.MindmapBang {
 		textColor = "#FFFFFF"
 	}
-	builder.text(cx, cy+th.FontSize/3, node.Label,
+	if node.Style.TextColor != nil {
+		textColor = *node.Style.TextColor
+	}
+	// An icon sits above the label, which moves down to make room.
+	labelY := cy
+	if node.IconSize > 0 {
+		renderIcon(builder, cfg, node.Icon, cx, cy-node.IconSize/2, node.IconSize, textColor)
+		labelY += node.IconSize / 2
+	}
+	builder.text(cx, labelY+th.FontSize/3, node.Label,
 		"text-anchor", "middle",
 		"font-family", th.FontFamily,
 		"font-size", fmtFloat(th.FontSize),
@@ -144,6 +169,6 @@ func renderMindmapNode(builder *svgBuilder, node *layout.MindmapNodeLayout, colo
 
 	// Recursively render children.
 	for _, child := range node.Children {
-		renderMindmapNode(builder, child, colors, th)
+		renderMindmapNode(builder, child, colors, th, cfg)
 	}
 }
@@ -53,3 +53,24 @@ func TestRenderMindmapEmpty(t *testing.T) {
 		t.Error("missing <svg tag")
 	}
 }
+
+func TestRenderMindmapClassStyles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Mindmap
+	fill, stroke, text := "#ffdddd", "#cc0000", "#990000"
+	graph.ClassDefs["urgent"] = &ir.NodeStyle{Fill: &fill, Stroke: &stroke, TextColor: &text}
+	graph.MindmapRoot = &ir.MindmapNode{
+		ID: "root", Label: "Central",
+		Children: []*ir.MindmapNode{{ID: "a", Label: "Hot", Class: "urgent"}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	for _, want := range []string{`fill="#ffdddd"`, `stroke="#cc0000"`, `fill="#990000"`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
@@ -11,6 +11,13 @@ import (
 // Packet diagram rendering constants.
 const (
 	packetSmallFontScale float32 = 0.7
+	// packetRulerTick is the length of the ruler's tick marks.
+	packetRulerTick float32 = 3
+	// packetRulerMinSpacing is the closest two numbered ruler bits may be;
+	// narrower bits number every second, fourth, ... bit instead.
+	packetRulerMinSpacing float32 = 16
+	// packetContinuedDash dashes the edges where a field crosses rows.
+	packetContinuedDash = "3,3"
 )
 
 func renderPacket(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *config.Layout) {
@@ -38,23 +45,33 @@ func renderPacket(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 
 	// Render rows and fields
 	for _, row := range pd.Rows {
+		if pd.BitRuler {
+			renderPacketRuler(builder, row, pd.BitsPerRow, th, pc.PaddingX, pc.BitWidth, smallFontSize)
+		}
 		for _, field := range row.Fields {
-			// Field rectangle
-			builder.rect(field.X, field.Y, field.Width, field.Height, 0,
-				"fill", th.PrimaryColor,
-				"stroke", th.NodeBorderColor,
-				"stroke-width", "1",
-			)
+			// Field rectangle. The edges where a field continues from or
+			// onto another row are dashed.
+			if field.ContinuesFrom || field.ContinuesTo {
+				renderPacketSplitField(builder, field, th)
+			} else {
+				builder.rect(field.X, field.Y, field.Width, field.Height, 0,
+					"fill", th.PrimaryColor,
+					"stroke", th.NodeBorderColor,
+					"stroke-width", "1",
+				)
+			}
 
-			// Field label (centered)
-			textX := field.X + field.Width/2
-			textY := field.Y + field.Height/2 + field.Label.FontSize/3
-			builder.text(textX, textY, field.Label.Lines[0],
-				"text-anchor", "middle",
-				"font-family", th.FontFamily,
-				"font-size", fmtFloat(field.Label.FontSize),
-				"fill", th.PrimaryTextColor,
-			)
+			// Field label (centered), unless it did not fit the cell.
+			if label := field.Label.Lines[0]; label != "" {
+				textX := field.X + field.Width/2
+				textY := field.Y + field.Height/2 + field.Label.FontSize/3
+				builder.text(textX, textY, label,
+					"text-anchor", "middle",
+					"font-family", th.FontFamily,
+					"font-size", fmtFloat(field.Label.FontSize),
+					"fill", th.PrimaryTextColor,
+				)
+			}
 
 			// Bit range labels at bottom-left and bottom-right of field
 			bitY := field.Y + field.Height - 2
@@ -74,3 +91,52 @@ func renderPacket(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 		}
 	}
 }
+
+// renderPacketRuler draws a row's absolute bit numbers above it, with a
+// tick at the start of each bit.
+func renderPacketRuler(builder *svgBuilder, row layout.PacketRowLayout, bitsPerRow int, th *theme.Theme, padX, bitW, fontSize float32) {
+	step := 1
+	for float32(step)*bitW < packetRulerMinSpacing {
+		step *= 2
+	}
+	for bit := range bitsPerRow {
+		left := padX + float32(bit)*bitW
+		builder.line(left, row.Y-packetRulerTick, left, row.Y,
+			"stroke", th.NodeBorderColor,
+			"stroke-width", "0.5",
+		)
+		if bit%step != 0 {
+			continue
+		}
+		builder.text(left+bitW/2, row.Y-packetRulerTick-1, strconv.Itoa(row.StartBit+bit),
+			"text-anchor", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(fontSize),
+			"fill", th.SecondaryTextColor,
+		)
+	}
+}
+
+// renderPacketSplitField draws the cell of a field that crosses a row
+// boundary, dashing the edge on each side where it continues.
+func renderPacketSplitField(builder *svgBuilder, field layout.PacketFieldLayout, th *theme.Theme) {
+	right := field.X + field.Width
+	bottom := field.Y + field.Height
+	builder.rect(field.X, field.Y, field.Width, field.Height, 0,
+		"fill", th.PrimaryColor,
+	)
+	builder.line(field.X, field.Y, right, field.Y,
+		"stroke", th.NodeBorderColor, "stroke-width", "1")
+	builder.line(field.X, bottom, right, bottom,
+		"stroke", th.NodeBorderColor, "stroke-width", "1")
+	for _, edge := range []struct {
+		x         float32
+		continued bool
+	}{{field.X, field.ContinuesFrom}, {right, field.ContinuesTo}} {
+		attrs := []string{"stroke", th.NodeBorderColor, "stroke-width", "1"}
+		if edge.continued {
+			attrs = append(attrs, "stroke-dasharray", packetContinuedDash)
+		}
+		builder.line(edge.x, field.Y, edge.x, bottom, attrs...)
+	}
+}
@@ -74,3 +74,29 @@ func TestRenderPacketValidSVG(t *testing.T) {
 		t.Error("SVG should end with </svg>")
 	}
 }
+
+func TestRenderPacketContinuation(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Packet
+	graph.Fields = []*ir.PacketField{
+		{Start: 0, End: 47, Description: "Address"},
+	}
+	bitRuler := true
+	graph.PacketBitRuler = &bitRuler
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	l := layout.ComputeLayout(graph, th, cfg)
+	svg := RenderSVG(l, th, cfg)
+
+	if got := strings.Count(svg, `stroke-dasharray="`+packetContinuedDash+`"`); got != 2 {
+		t.Errorf("dashed edges = %d, want 2 where the field crosses rows", got)
+	}
+	if !strings.Contains(svg, "Address (cont.)") {
+		t.Error("SVG should mark the continued cell")
+	}
+	// The second row's ruler numbers its bits from 32.
+	if !strings.Contains(svg, ">32<") || !strings.Contains(svg, ">63<") {
+		t.Error("SVG should number the second row's ruler from 32 to 63")
+	}
+}
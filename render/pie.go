@@ -3,6 +3,7 @@ package render
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/layout"
@@ -73,19 +74,6 @@ func renderPie(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *conf
 				"stroke-width", fmtFloat(th.PieStrokeWidth),
 			)
 		}
-
-		// Slice label.
-		labelText := slice.Label
-		if pd.ShowData {
-			labelText = fmt.Sprintf("%s (%.0f)", slice.Label, slice.Value)
-		}
-		builder.text(slice.LabelX, slice.LabelY, labelText,
-			"text-anchor", "middle",
-			"dominant-baseline", "middle",
-			"font-family", th.FontFamily,
-			"font-size", fmtFloat(th.PieSectionTextSize),
-			"fill", th.PieSectionTextColor,
-		)
 	}
 
 	// Outer stroke.
@@ -96,4 +84,68 @@ func renderPie(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *conf
 			"stroke-width", fmtFloat(th.PieOuterStrokeWidth),
 		)
 	}
+
+	renderPieLabels(builder, &pd, th)
+	renderPieLegend(builder, &pd, th)
+}
+
+// renderPieLabels draws the slice labels. Outside labels get a leader
+// line from the rim and are anchored towards the pie.
+func renderPieLabels(builder *svgBuilder, pd *layout.PieData, th *theme.Theme) {
+	for _, slice := range pd.Slices {
+		if !slice.Outside {
+			builder.text(slice.LabelX, slice.LabelY, slice.Text,
+				"text-anchor", "middle",
+				"dominant-baseline", "middle",
+				"font-family", th.FontFamily,
+				"font-size", fmtFloat(th.PieSectionTextSize),
+				"fill", th.PieSectionTextColor,
+			)
+			continue
+		}
+		points := make([]string, len(slice.Leader))
+		for idx, pt := range slice.Leader {
+			points[idx] = fmtFloat(pt[0]) + "," + fmtFloat(pt[1])
+		}
+		builder.path("M "+strings.Join(points, " L "),
+			"class", "pie-leader",
+			"fill", "none",
+			"stroke", th.LineColor,
+			"stroke-width", "1",
+		)
+		anchor := "start"
+		if slice.LabelX < pd.CenterX {
+			anchor = "end"
+		}
+		builder.text(slice.LabelX, slice.LabelY, slice.Text,
+			"text-anchor", anchor,
+			"dominant-baseline", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(th.PieSectionTextSize),
+			"fill", th.TextColor,
+		)
+	}
+}
+
+// renderPieLegend draws a colour swatch and text for each legend entry.
+func renderPieLegend(builder *svgBuilder, pd *layout.PieData, th *theme.Theme) {
+	for _, entry := range pd.Legend {
+		color := "#888888" // fallback
+		if len(th.PieColors) > 0 {
+			color = th.PieColors[entry.ColorIndex%len(th.PieColors)]
+		}
+		builder.rect(entry.X, entry.Y, pd.LegendSwatch, pd.LegendSwatch, 0,
+			"class", "pie-legend-swatch",
+			"fill", color,
+			"stroke", th.PieStrokeColor,
+			"stroke-width", "1",
+		)
+		builder.text(entry.TextX, entry.Y+pd.LegendSwatch/2, entry.Text, //nolint:mnd // centred on the swatch.
+			"class", "pie-legend",
+			"dominant-baseline", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(th.PieSectionTextSize),
+			"fill", th.TextColor,
+		)
+	}
 }
@@ -83,3 +83,34 @@ func TestRenderPieSingleSlice(t *testing.T) {
 		t.Error("missing label for single slice")
 	}
 }
+
+func TestRenderPieLegendAndLeaders(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Pie
+	graph.PieSlices = []*ir.PieSlice{
+		{Label: "Large", Value: 900},
+		{Label: "Tiny one", Value: 10},
+		{Label: "Tiny two", Value: 8},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	l := layout.ComputeLayout(graph, th, cfg)
+	svg := RenderSVG(l, th, cfg)
+
+	if got := strings.Count(svg, `class="pie-legend-swatch"`); got != 3 {
+		t.Errorf("legend swatches = %d, want 3", got)
+	}
+	if !strings.Contains(svg, `class="pie-legend"`) {
+		t.Error("missing legend text")
+	}
+	if got := strings.Count(svg, `class="pie-leader"`); got != 2 {
+		t.Errorf("leader lines = %d, want 2", got)
+	}
+
+	cfg.Pie.LegendPosition = config.LegendNone
+	svg = RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+	if strings.Contains(svg, "pie-legend") {
+		t.Error("legend drawn with LegendNone")
+	}
+}
@@ -109,15 +109,28 @@ func renderQuadrant(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cf
 			"transform", "rotate(-90,"+fmtFloat(cx-quadrantAxisLabelPad)+","+fmtFloat(cy)+")")
 	}
 
-	// Data points.
-	pointR := cfg.Quadrant.PointRadius
+	// Data points, styled by their classes and own styles, with labels
+	// where the layout placed them.
 	for _, point := range qd.Points {
-		builder.circle(point.X, point.Y, pointR,
-			"fill", th.QuadrantPointFill,
-			"stroke", th.LineColor,
-			"stroke-width", "1",
+		fill := th.QuadrantPointFill
+		if point.Style.Color != nil {
+			fill = *point.Style.Color
+		}
+		stroke := th.LineColor
+		if point.Style.StrokeColor != nil {
+			stroke = *point.Style.StrokeColor
+		}
+		strokeWidth := "1"
+		if point.Style.StrokeWidth != nil {
+			strokeWidth = fmtFloat(*point.Style.StrokeWidth)
+		}
+		builder.circle(point.X, point.Y, point.Radius,
+			"fill", fill,
+			"stroke", stroke,
+			"stroke-width", strokeWidth,
 		)
-		builder.text(point.X+pointR+3, point.Y+quadrantAxisLabelPad, point.Label,
+		builder.text(point.LabelX, point.LabelY, point.Label,
+			"text-anchor", point.LabelAnchor,
 			"font-family", th.FontFamily,
 			"font-size", fmtFloat(axisLabelSize),
 			"fill", th.TextColor,
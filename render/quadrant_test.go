@@ -78,3 +78,25 @@ func TestRenderQuadrantMinimal(t *testing.T) {
 		t.Error("missing data point circle")
 	}
 }
+
+func TestRenderQuadrantPointStyles(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Quadrant
+	radius, color, stroke, width := float32(12), "#ff3300", "#10f0f0", float32(5)
+	graph.QuadrantPoints = []*ir.QuadrantPoint{
+		{Label: "Styled", X: 0.3, Y: 0.6, Style: ir.QuadrantPointStyle{
+			Radius: &radius, Color: &color, StrokeColor: &stroke, StrokeWidth: &width,
+		}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if !strings.Contains(svg, `r="12" fill="#ff3300" stroke="#10f0f0" stroke-width="5"`) {
+		t.Error("missing styled point circle")
+	}
+	if !strings.Contains(svg, `text-anchor="start"`) {
+		t.Error("missing placed label anchor")
+	}
+}
@@ -2,6 +2,8 @@ package render
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/layout"
@@ -11,6 +13,15 @@ import (
 // Sankey diagram rendering constants.
 const (
 	sankeyLabelGap float32 = 4
+	// sankeyValueScale rounds node values to two decimal places.
+	sankeyValueScale = 100
+)
+
+// Sankey link colourings other than a plain colour.
+const (
+	sankeyLinkSource   = "source"
+	sankeyLinkTarget   = "target"
+	sankeyLinkGradient = "gradient"
 )
 
 func renderSankey(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *config.Layout) {
@@ -32,9 +43,38 @@ func renderSankey(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *c
 		linkOpacity = 0.4
 	}
 
+	// Gradient links each need their own gradient, running from the
+	// source node's colour to the target's across the link.
+	nodeColor := func(idx int) string {
+		return nodeColors[sd.Nodes[idx].ColorIndex%len(nodeColors)]
+	}
+	validLink := func(link layout.SankeyLinkLayout) bool {
+		return link.SourceIdx < len(sd.Nodes) && link.TargetIdx < len(sd.Nodes)
+	}
+	if sd.LinkColor == sankeyLinkGradient {
+		builder.openTag("defs")
+		for idx, link := range sd.Links {
+			if !validLink(link) {
+				continue
+			}
+			src := sd.Nodes[link.SourceIdx]
+			tgt := sd.Nodes[link.TargetIdx]
+			builder.openTag("linearGradient",
+				"id", sankeyGradientID(idx),
+				"gradientUnits", "userSpaceOnUse",
+				"x1", fmtFloat(src.X+src.Width),
+				"x2", fmtFloat(tgt.X),
+			)
+			builder.selfClose("stop", "offset", "0%", "stop-color", nodeColor(link.SourceIdx))
+			builder.selfClose("stop", "offset", "100%", "stop-color", nodeColor(link.TargetIdx))
+			builder.closeTag("linearGradient")
+		}
+		builder.closeTag("defs")
+	}
+
 	// Draw links first (behind nodes).
-	for _, link := range sd.Links {
-		if link.SourceIdx >= len(sd.Nodes) || link.TargetIdx >= len(sd.Nodes) {
+	for idx, link := range sd.Links {
+		if !validLink(link) {
 			continue
 		}
 		src := sd.Nodes[link.SourceIdx]
@@ -53,9 +93,21 @@ func renderSankey(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *c
 			fmtFloat(midX), fmtFloat(ty),
 			fmtFloat(tx), fmtFloat(ty),
 		)
+		stroke := linkColor
+		switch sd.LinkColor {
+		case "":
+		case sankeyLinkSource:
+			stroke = nodeColor(link.SourceIdx)
+		case sankeyLinkTarget:
+			stroke = nodeColor(link.TargetIdx)
+		case sankeyLinkGradient:
+			stroke = "url(#" + sankeyGradientID(idx) + ")"
+		default:
+			stroke = sd.LinkColor
+		}
 		builder.path(pathData,
 			"fill", "none",
-			"stroke", linkColor,
+			"stroke", stroke,
 			"stroke-width", fmtFloat(link.Width),
 			"stroke-opacity", fmt.Sprintf("%.2f", linkOpacity),
 		)
@@ -68,13 +120,35 @@ func renderSankey(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, _ *c
 			"fill", color,
 		)
 
-		// Label to the right of the node.
+		// Label beside the node, outside the chart's middle: to the right
+		// of nodes in the left half and to the left of the others.
+		label := node.Label
+		if sd.ShowValues {
+			label += " " + sankeyFormatValue(node.Value)
+		}
 		labelX := node.X + node.Width + sankeyLabelGap
+		anchor := "start"
+		if node.X+node.Width/2 > lay.Width/2 {
+			labelX = node.X - sankeyLabelGap
+			anchor = "end"
+		}
 		labelY := node.Y + node.Height/2 + th.FontSize/3
-		builder.text(labelX, labelY, node.Label,
+		builder.text(labelX, labelY, label,
+			"text-anchor", anchor,
 			"font-family", th.FontFamily,
 			"font-size", fmtFloat(th.FontSize),
 			"fill", th.TextColor,
 		)
 	}
 }
+
+// sankeyGradientID returns the id of the gradient for the link at idx.
+func sankeyGradientID(idx int) string {
+	return "sankey-link-" + strconv.Itoa(idx)
+}
+
+// sankeyFormatValue rounds a node value to two decimal places and drops
+// trailing zeros.
+func sankeyFormatValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*sankeyValueScale)/sankeyValueScale, 'f', -1, 64)
+}
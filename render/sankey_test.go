@@ -51,3 +51,39 @@ func TestRenderSankeyEmpty(t *testing.T) {
 		t.Error("missing <svg tag")
 	}
 }
+
+func TestRenderSankeyLinkColors(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Sankey
+	graph.SankeyLinks = []*ir.SankeyLink{
+		{Source: "Solar", Target: "Grid", Value: 60.125},
+		{Source: "Grid", Target: "Homes", Value: 60.125},
+	}
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+
+	cfg.Sankey.LinkColor = "gradient"
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+	if !strings.Contains(svg, `<linearGradient id="sankey-link-0" gradientUnits="userSpaceOnUse"`) {
+		t.Error("missing per-link gradient")
+	}
+	if !strings.Contains(svg, `stroke="url(#sankey-link-1)"`) {
+		t.Error("link does not stroke with its gradient")
+	}
+	if !strings.Contains(svg, "Solar 60.13") {
+		t.Error("missing rounded value in label")
+	}
+
+	cfg.Sankey.LinkColor = "#123456"
+	cfg.Sankey.ShowValues = false
+	svg = RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+	if !strings.Contains(svg, `stroke="#123456"`) {
+		t.Error("missing fixed link colour")
+	}
+	if strings.Contains(svg, "linearGradient") || strings.Contains(svg, "Solar 60") {
+		t.Error("unexpected gradient or value label")
+	}
+	if !strings.Contains(svg, `text-anchor="end"`) {
+		t.Error("right-hand node label should end at the node")
+	}
+}
@@ -2,7 +2,9 @@ package render
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/ir"
@@ -48,11 +50,19 @@ const (
 	seqPersonBodyArc      float32 = 24
 	seqPersonTextStart    float32 = 50
 	seqPersonBorderRadius float32 = 6
+	seqMenuRowScale       float32 = 1.8
+	seqMenuTextPadX       float32 = 10
+	seqMarkerFontScale    float32 = 0.8
 )
 
+// seqMenuStyle reveals a participant link menu while the pointer is over
+// its participant, or while it has keyboard focus. Menus are hidden with
+// a display attribute so renderers without CSS leave them out.
+const seqMenuStyle = ".seq-link-menu:hover .seq-link-items,.seq-link-menu:focus-within .seq-link-items{display:inline}"
+
 // renderSequence renders all sequence diagram elements in visual stacking
 // order (back to front): boxes, frames, lifelines, activations, messages,
-// notes, then participants.
+// notes, participants, then the page markers of a split diagram.
 func renderSequence(builder *svgBuilder, computed *layout.Layout, th *theme.Theme, _ *config.Layout) {
 	sd, ok := computed.Diagram.(layout.SequenceData)
 	if !ok {
@@ -65,6 +75,7 @@ func renderSequence(builder *svgBuilder, computed *layout.Layout, th *theme.Them
 	renderSeqMessages(builder, &sd, th)
 	renderSeqNotes(builder, &sd, th)
 	renderSeqParticipants(builder, &sd, th)
+	renderSeqPageMarkers(builder, &sd, th)
 }
 
 // renderSeqBoxes renders participant box groups as rounded rectangles.
@@ -124,8 +135,12 @@ func renderSeqFrames(builder *svgBuilder, sd *layout.SequenceData, th *theme.The
 		)
 
 		// Condition/label text after the tab.
-		if frame.Label != "" {
-			builder.text(frame.X+tabW+seqFrameLabelOffsetX, frame.Y+th.FontSize+1, frame.Label,
+		label := frame.Label
+		if frame.Continued {
+			label = strings.TrimSpace(label + " (continued)")
+		}
+		if label != "" {
+			builder.text(frame.X+tabW+seqFrameLabelOffsetX, frame.Y+th.FontSize+1, label,
 				"fill", th.LoopTextColor,
 				"font-size", fmtFloat(th.FontSize*seqFrameFontScale),
 			)
@@ -159,10 +174,28 @@ func renderSeqLifelines(builder *svgBuilder, sd *layout.SequenceData, th *theme.
 // renderSeqActivations renders narrow filled rectangles for activation bars.
 func renderSeqActivations(builder *svgBuilder, sd *layout.SequenceData, th *theme.Theme) {
 	for _, act := range sd.Activations {
-		builder.rect(act.X, act.TopY, act.Width, act.BottomY-act.TopY, 2,
+		attrs := []string{
 			"fill", th.ActivationBackground,
 			"stroke", th.ActivationBorderColor,
 			"stroke-width", "1",
+		}
+		// Activations carried over from an earlier page have a dashed
+		// outline.
+		if act.Continued {
+			attrs = append(attrs, "stroke-dasharray", "3,3")
+		}
+		builder.rect(act.X, act.TopY, act.Width, act.BottomY-act.TopY, 2, attrs...)
+	}
+}
+
+// renderSeqPageMarkers renders the "continued" notes of a split page.
+func renderSeqPageMarkers(builder *svgBuilder, sd *layout.SequenceData, th *theme.Theme) {
+	for _, marker := range sd.PageMarkers {
+		builder.text(marker.X, marker.Y, marker.Text,
+			"text-anchor", "middle",
+			"fill", th.SignalTextColor,
+			"font-size", fmtFloat(th.FontSize*seqMarkerFontScale),
+			"font-style", "italic",
 		)
 	}
 }
@@ -210,21 +243,25 @@ func renderSeqMessages(builder *svgBuilder, sd *layout.SequenceData, th *theme.T
 			builder.line(msg.FromX, msg.Y, msg.ToX, msg.Y, attrs...)
 		}
 
-		// Message text label above the arrow line.
-		if len(msg.Text.Lines) > 0 && msg.Text.Lines[0] != "" {
+		// Message text label above the arrow line; the last line sits
+		// just above it and earlier lines stack upwards.
+		if len(msg.Text.Lines) > 0 && strings.Join(msg.Text.Lines, "") != "" {
 			var textX float32
 			if isSelf {
 				textX = msg.FromX + seqSelfTextOffsetX
 			} else {
 				textX = (msg.FromX + msg.ToX) / 2
 			}
-			textY := msg.Y - seqTextAboveOffset
-
-			builder.text(textX, textY, msg.Text.Lines[0],
-				"text-anchor", "middle",
-				"fill", th.SignalTextColor,
-				"font-size", fmtFloat(th.FontSize),
-			)
+			lineH := msg.Text.Height / float32(len(msg.Text.Lines))
+			last := len(msg.Text.Lines) - 1
+			for idx, line := range msg.Text.Lines {
+				textY := msg.Y - seqTextAboveOffset - float32(last-idx)*lineH
+				builder.text(textX, textY, line,
+					"text-anchor", "middle",
+					"fill", th.SignalTextColor,
+					"font-size", fmtFloat(th.FontSize),
+				)
+			}
 		}
 
 		// Autonumber: filled circle with number at the start of the arrow.
@@ -278,13 +315,132 @@ func renderSeqNotes(builder *svgBuilder, sd *layout.SequenceData, th *theme.Them
 // and footers (at the bottom). Different participant kinds get different shapes.
 func renderSeqParticipants(builder *svgBuilder, sd *layout.SequenceData, th *theme.Theme) {
 	for _, participant := range sd.Participants {
+		if len(participant.Properties) > 0 {
+			builder.openTag("g", seqPropertyAttrs(&participant)...)
+		}
+
 		// Render header at top.
-		renderSeqParticipantShape(builder, &participant, participant.X, participant.Y, th)
+		renderSeqLinkedShape(builder, &participant, participant.Y, th)
 
 		// Render footer at bottom (mirror of header).
 		footerY := sd.DiagramHeight - participant.Height
-		renderSeqParticipantShape(builder, &participant, participant.X, footerY, th)
+		renderSeqLinkedShape(builder, &participant, footerY, th)
+
+		if len(participant.Properties) > 0 {
+			builder.closeTag("g")
+		}
+	}
+
+	// Link menus go on top of every participant so a menu is never
+	// hidden behind a neighbour.
+	styled := false
+	for _, participant := range sd.Participants {
+		if len(participant.Links) < 2 { //nolint:mnd // a single link is a plain anchor.
+			continue
+		}
+		if !styled {
+			builder.openTag("style")
+			builder.content(seqMenuStyle)
+			builder.closeTag("style")
+			styled = true
+		}
+		renderSeqLinkMenu(builder, &participant, participant.Y, true, th)
+		renderSeqLinkMenu(builder, &participant, sd.DiagramHeight-participant.Height, false, th)
+	}
+}
+
+// seqPropertyAttrs returns a participant's properties as data-*
+// attributes, in key order, along with the participant ID.
+func seqPropertyAttrs(participant *layout.SeqParticipantLayout) []string {
+	keys := make([]string, 0, len(participant.Properties))
+	for key := range participant.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := []string{"data-participant", participant.ID}
+	for _, key := range keys {
+		if name := dataAttrName(key); name != "" {
+			attrs = append(attrs, "data-"+name, participant.Properties[key])
+		}
+	}
+	return attrs
+}
+
+// dataAttrName converts a property key to a valid data-* attribute
+// suffix: lower case letters, digits and hyphens.
+func dataAttrName(key string) string {
+	var out strings.Builder
+	for _, ch := range strings.ToLower(key) {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9', ch == '-':
+			out.WriteRune(ch)
+		case ch == '_' || ch == ' ' || ch == '.':
+			out.WriteByte('-')
+		}
+	}
+	return strings.Trim(out.String(), "-")
+}
+
+// renderSeqLinkedShape renders a participant shape, wrapped in an anchor
+// when the participant has exactly one link.
+func renderSeqLinkedShape(builder *svgBuilder, participant *layout.SeqParticipantLayout, topY float32, th *theme.Theme) {
+	if len(participant.Links) != 1 {
+		renderSeqParticipantShape(builder, participant, participant.X, topY, th)
+		return
+	}
+	link := participant.Links[0]
+	builder.openTag("a", "href", link.URL, "target", "_blank")
+	builder.openTag("title")
+	builder.content(link.Label)
+	builder.closeTag("title")
+	renderSeqParticipantShape(builder, participant, participant.X, topY, th)
+	builder.closeTag("a")
+}
+
+// renderSeqLinkMenu renders the link menu of a participant with several
+// links: a transparent hotspot over the participant shape, a <title>
+// listing every link for viewers without CSS, and the menu items, which
+// open below a header or above a footer.
+func renderSeqLinkMenu(builder *svgBuilder, participant *layout.SeqParticipantLayout, topY float32, below bool, th *theme.Theme) {
+	rowH := th.FontSize * seqMenuRowScale
+	menuH := rowH * float32(len(participant.Links))
+	menuX := participant.X - participant.MenuWidth/2
+	menuY := topY + participant.Height
+	if !below {
+		menuY = topY - menuH
+	}
+
+	titles := make([]string, len(participant.Links))
+	for idx, link := range participant.Links {
+		titles[idx] = link.Label + ": " + link.URL
+	}
+
+	builder.openTag("g", "class", "seq-link-menu", "tabindex", "0")
+	builder.openTag("title")
+	builder.content(strings.Join(titles, "\n"))
+	builder.closeTag("title")
+	builder.rect(participant.X-participant.Width/2, topY, participant.Width, participant.Height, seqPartBorderRadius,
+		"fill", "transparent",
+	)
+	builder.openTag("g", "class", "seq-link-items", "display", "none")
+	builder.rect(menuX, menuY, participant.MenuWidth, menuH, seqPartBorderRadius,
+		"fill", th.ActorBackground,
+		"stroke", th.ActorBorder,
+		"stroke-width", "1",
+	)
+	for idx, link := range participant.Links {
+		rowY := menuY + float32(idx)*rowH
+		builder.openTag("a", "href", link.URL, "target", "_blank")
+		builder.text(menuX+seqMenuTextPadX, rowY+rowH/2, link.Label,
+			"dominant-baseline", "middle",
+			"fill", th.ActorTextColor,
+			"font-size", fmtFloat(th.FontSize),
+			"text-decoration", "underline",
+		)
+		builder.closeTag("a")
 	}
+	builder.closeTag("g")
+	builder.closeTag("g")
 }
 
 // renderSeqParticipantShape renders a single participant shape at the given position.
@@ -1,6 +1,8 @@
 package render
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -92,6 +94,44 @@ func TestRenderSequenceHasMessageText(t *testing.T) {
 	}
 }
 
+func TestRenderSequenceMultiLineMessage(t *testing.T) {
+	graph := buildSeqGraph(
+		[]string{"Alice", "Bob"},
+		[]*ir.SeqEvent{
+			{Kind: ir.EvMessage, Message: &ir.SeqMessage{
+				From: "Alice", To: "Bob", Text: "First line\nSecond line", Kind: ir.MsgSolidArrow,
+			}},
+		},
+	)
+
+	svg := renderSeqSVG(graph)
+
+	first := strings.Index(svg, ">First line<")
+	second := strings.Index(svg, ">Second line<")
+	if first < 0 || second < 0 {
+		t.Fatalf("expected both label lines as separate text elements:\n%s", svg)
+	}
+	if y1, y2 := textY(t, svg, first), textY(t, svg, second); y1 >= y2 {
+		t.Errorf("first line y = %v, want above second line y = %v", y1, y2)
+	}
+}
+
+// textY returns the y attribute of the text element whose content starts
+// at offset end.
+func textY(t *testing.T, svg string, end int) float64 {
+	t.Helper()
+	start := strings.LastIndex(svg[:end], "<text")
+	match := regexp.MustCompile(`\by="([-\d.]+)"`).FindStringSubmatch(svg[start:end])
+	if match == nil {
+		t.Fatalf("no y attribute in %q", svg[start:end])
+	}
+	y, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return y
+}
+
 func TestRenderSequenceHasActivations(t *testing.T) {
 	th := theme.Modern()
 
@@ -112,3 +152,55 @@ func TestRenderSequenceHasActivations(t *testing.T) {
 		t.Errorf("expected SVG to contain activation fill color %s", th.ActivationBackground)
 	}
 }
+
+func TestRenderSequenceParticipantLink(t *testing.T) {
+	graph := buildSeqGraph([]string{"Alice", "Bob"}, nil)
+	graph.Participants[0].Links = []ir.SeqLink{{Label: "Dashboard", URL: "https://example.com/dash"}}
+	svg := renderSeqSVG(graph)
+
+	// Header and footer each get an anchor.
+	if got := strings.Count(svg, `<a href="https://example.com/dash" target="_blank"><title>Dashboard</title>`); got != 2 {
+		t.Errorf("participant anchors = %d, want 2", got)
+	}
+	if strings.Contains(svg, "seq-link-menu") {
+		t.Error("a single link should not produce a menu")
+	}
+}
+
+func TestRenderSequenceParticipantLinkMenu(t *testing.T) {
+	graph := buildSeqGraph([]string{"API"}, nil)
+	graph.Participants[0].Links = []ir.SeqLink{
+		{Label: "Dashboard", URL: "https://example.com/dash"},
+		{Label: "Repository", URL: "https://example.com/repo"},
+	}
+	svg := renderSeqSVG(graph)
+
+	if strings.Count(svg, "<style>") != 1 {
+		t.Error("expected one menu style element")
+	}
+	if got := strings.Count(svg, `class="seq-link-menu"`); got != 2 {
+		t.Errorf("menus = %d, want header and footer", got)
+	}
+	if !strings.Contains(svg, `<g class="seq-link-items" display="none">`) {
+		t.Error("menu items should be hidden until hovered")
+	}
+	if !strings.Contains(svg, "<title>Dashboard: https://example.com/dash\nRepository: https://example.com/repo</title>") {
+		t.Error("missing title fallback listing every link")
+	}
+	if !strings.Contains(svg, `<a href="https://example.com/repo" target="_blank">`) {
+		t.Error("missing menu item anchor")
+	}
+}
+
+func TestRenderSequenceParticipantProperties(t *testing.T) {
+	graph := buildSeqGraph([]string{"API"}, nil)
+	graph.Participants[0].Properties = map[string]string{"team": "platform", "On Call": "alice", "!!": "dropped"}
+	svg := renderSeqSVG(graph)
+
+	if !strings.Contains(svg, `<g data-participant="API" data-on-call="alice" data-team="platform">`) {
+		t.Errorf("missing data attributes in:\n%s", svg)
+	}
+	if strings.Contains(svg, "dropped") {
+		t.Error("keys without valid characters should be skipped")
+	}
+}
@@ -204,7 +204,7 @@ func renderInnerLayout(builder *svgBuilder, computed *layout.Layout, th *theme.T
 	case layout.StateData:
 		renderStateNodes(builder, computed, th, cfg, &diag)
 	default:
-		renderNodes(builder, computed, th)
+		renderNodes(builder, computed, th, cfg)
 	}
 
 	builder.closeTag("g")
@@ -39,6 +39,7 @@ func RenderSVG(computed *layout.Layout, th *theme.Theme, cfg *config.Layout) str
 
 	// Arrow marker definitions.
 	renderDefs(&builder, th)
+	renderIconDefs(&builder, computed, cfg)
 
 	// Background.
 	builder.rect(0, 0, width, height, 0,
@@ -1,8 +1,6 @@
 package render
 
 import (
-	"fmt"
-
 	"github.com/jamesainslie/gomd2svg/config"
 	"github.com/jamesainslie/gomd2svg/layout"
 	"github.com/jamesainslie/gomd2svg/theme"
@@ -13,6 +11,9 @@ const (
 	treemapTitleOffsetY     float32 = 20
 	treemapSectionLabelPadX float32 = 4
 	treemapSectionLabelPadY float32 = 6
+	// treemapSectionTotalMinWidth is the narrowest section header that
+	// shows the section's total beside its name.
+	treemapSectionTotalMinWidth float32 = 60
 )
 
 func renderTreemap(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg *config.Layout) {
@@ -37,62 +38,93 @@ func renderTreemap(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 		)
 	}
 
-	// Draw rectangles.
+	// Draw rectangles. Classes of a node override its palette colours.
 	for _, rect := range td.Rects {
 		color := colors[rect.ColorIndex%len(colors)]
+		stroke := th.TreemapBorder
+		if rect.Style.Stroke != nil {
+			stroke = *rect.Style.Stroke
+		}
 
 		if rect.IsSection {
-			// Section: draw a container rect with header.
+			// Section: draw a container rect with a header bar holding the
+			// section's name and total.
 			builder.rect(rect.X, rect.Y, rect.Width, rect.Height, 2,
 				"fill", "none",
-				"stroke", th.TreemapBorder,
+				"stroke", stroke,
 				"stroke-width", "1",
 			)
-			// Header background.
 			headerH := cfg.Treemap.HeaderHeight
 			if headerH > rect.Height {
 				headerH = rect.Height
 			}
-			builder.rect(rect.X, rect.Y, rect.Width, headerH, 0,
-				"fill", color,
-				"opacity", "0.3",
-			)
-			// Section label.
-			builder.text(rect.X+treemapSectionLabelPadX, rect.Y+headerH-treemapSectionLabelPadY, rect.Label,
+			if rect.Style.Fill != nil {
+				builder.rect(rect.X, rect.Y, rect.Width, headerH, 0,
+					"fill", *rect.Style.Fill,
+				)
+			} else {
+				builder.rect(rect.X, rect.Y, rect.Width, headerH, 0,
+					"fill", color,
+					"opacity", "0.3",
+				)
+			}
+			textColor := th.TextColor
+			if rect.Style.TextColor != nil {
+				textColor = *rect.Style.TextColor
+			}
+			labelY := rect.Y + headerH - treemapSectionLabelPadY
+			builder.text(rect.X+treemapSectionLabelPadX, labelY, rect.Label,
 				"font-family", th.FontFamily,
 				"font-size", fmtFloat(cfg.Treemap.LabelFontSize),
-				"fill", th.TextColor,
-			)
-		} else {
-			// Leaf: fill with color, add label and value.
-			builder.rect(rect.X, rect.Y, rect.Width, rect.Height, 2,
-				"fill", color,
-				"stroke", th.TreemapBorder,
-				"stroke-width", "1",
+				"font-weight", "bold",
+				"fill", textColor,
 			)
+			if rect.Width > treemapSectionTotalMinWidth && rect.ValueLabel != "" {
+				builder.text(rect.X+rect.Width-treemapSectionLabelPadX, labelY, rect.ValueLabel,
+					"text-anchor", "end",
+					"font-family", th.FontFamily,
+					"font-size", fmtFloat(cfg.Treemap.ValueFontSize),
+					"fill", textColor,
+				)
+			}
+			continue
+		}
+
+		// Leaf: fill with color, add label and value.
+		if rect.Style.Fill != nil {
+			color = *rect.Style.Fill
+		}
+		textColor := th.TreemapTextColor
+		if rect.Style.TextColor != nil {
+			textColor = *rect.Style.TextColor
+		}
+		builder.rect(rect.X, rect.Y, rect.Width, rect.Height, 2,
+			"fill", color,
+			"stroke", stroke,
+			"stroke-width", "1",
+		)
 
-			// Only draw label if rect is large enough.
-			if rect.Width > 20 && rect.Height > 14 {
-				cx := rect.X + rect.Width/2
-				cy := rect.Y + rect.Height/2
+		// Only draw label if rect is large enough.
+		if rect.Width > 20 && rect.Height > 14 {
+			cx := rect.X + rect.Width/2
+			cy := rect.Y + rect.Height/2
 
-				builder.text(cx, cy, rect.Label,
+			builder.text(cx, cy, rect.Label,
+				"text-anchor", "middle",
+				"font-family", th.FontFamily,
+				"font-size", fmtFloat(cfg.Treemap.LabelFontSize),
+				"fill", textColor,
+			)
+
+			// Show value below label if there's room.
+			if rect.Height > 30 && rect.Value > 0 {
+				builder.text(cx, cy+cfg.Treemap.ValueFontSize+2, rect.ValueLabel,
 					"text-anchor", "middle",
 					"font-family", th.FontFamily,
-					"font-size", fmtFloat(cfg.Treemap.LabelFontSize),
-					"fill", th.TreemapTextColor,
+					"font-size", fmtFloat(cfg.Treemap.ValueFontSize),
+					"fill", textColor,
+					"opacity", "0.7",
 				)
-
-				// Show value below label if there's room.
-				if rect.Height > 30 && rect.Value > 0 {
-					builder.text(cx, cy+cfg.Treemap.ValueFontSize+2, fmt.Sprintf("%.0f", rect.Value),
-						"text-anchor", "middle",
-						"font-family", th.FontFamily,
-						"font-size", fmtFloat(cfg.Treemap.ValueFontSize),
-						"fill", th.TreemapTextColor,
-						"opacity", "0.7",
-					)
-				}
 			}
 		}
 	}
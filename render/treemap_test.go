@@ -54,3 +54,35 @@ func TestRenderTreemapEmpty(t *testing.T) {
 		t.Error("missing <svg tag")
 	}
 }
+
+func TestRenderTreemapSectionsAndClasses(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.Treemap
+	graph.TreemapValueFormat = "$,.0f"
+	fill, text := "#e4572e", "#fff"
+	graph.ClassDefs["hot"] = &ir.NodeStyle{Fill: &fill, TextColor: &text}
+	graph.TreemapRoot = &ir.TreemapNode{
+		Label: "Root",
+		Children: []*ir.TreemapNode{
+			{Label: "Engineering", Children: []*ir.TreemapNode{
+				{Label: "Backend", Value: 2000},
+				{Label: "Frontend", Value: 1500, Class: "hot"},
+			}},
+			{Label: "Support", Value: 1000},
+		},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if !strings.Contains(svg, ">$3,500</text>") {
+		t.Error("missing formatted section total")
+	}
+	if !strings.Contains(svg, ">$1,500</text>") {
+		t.Error("missing formatted leaf value")
+	}
+	if !strings.Contains(svg, `fill="#e4572e"`) {
+		t.Error("missing classDef fill")
+	}
+}
@@ -60,9 +60,22 @@ func renderXYChart(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 		)
 	}
 
+	// Secondary Y-axis ticks, labelled on the right.
+	for _, tick := range xyd.Y2Ticks {
+		builder.text(cx+cw+xyTickLabelPad, tick.Y+xyTickLabelPad, tick.Label,
+			"text-anchor", "start",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(cfg.XYChart.AxisFontSize),
+			"fill", th.TextColor,
+		)
+	}
+
 	// Axis lines.
 	builder.line(cx, cy, cx, cy+ch, "stroke", axisColor, "stroke-width", "1")       // Y-axis
 	builder.line(cx, cy+ch, cx+cw, cy+ch, "stroke", axisColor, "stroke-width", "1") // X-axis
+	if xyd.HasY2 {
+		builder.line(cx+cw, cy, cx+cw, cy+ch, "stroke", axisColor, "stroke-width", "1") // Secondary Y-axis
+	}
 
 	// X-axis labels.
 	for _, label := range xyd.XLabels {
@@ -76,10 +89,7 @@ func renderXYChart(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 
 	// Render each series.
 	for _, series := range xyd.Series {
-		color := "#4C78A8" // fallback
-		if len(th.XYChartColors) > 0 {
-			color = th.XYChartColors[series.ColorIndex%len(th.XYChartColors)]
-		}
+		color := xySeriesColor(th, series.ColorIndex)
 
 		switch series.Type {
 		case ir.XYSeriesBar:
@@ -113,4 +123,65 @@ func renderXYChart(builder *svgBuilder, lay *layout.Layout, th *theme.Theme, cfg
 			}
 		}
 	}
+
+	renderXYDataLabels(builder, &xyd, th, cfg)
+	renderXYLegend(builder, &xyd, th, cfg)
+}
+
+// renderXYDataLabels draws the value labels the layout placed on bars
+// and line points. They come after every series so lines never cover them.
+func renderXYDataLabels(builder *svgBuilder, xyd *layout.XYChartData, th *theme.Theme, cfg *config.Layout) {
+	for _, series := range xyd.Series {
+		for _, pt := range series.Points {
+			if pt.Label == "" {
+				continue
+			}
+			builder.text(pt.LabelX, pt.LabelY, pt.Label,
+				"class", "xychart-data-label",
+				"text-anchor", "middle",
+				"dominant-baseline", "middle",
+				"font-family", th.FontFamily,
+				"font-size", fmtFloat(cfg.XYChart.AxisFontSize),
+				"fill", th.TextColor,
+			)
+		}
+	}
+}
+
+// xySeriesColor returns the theme colour for a series.
+func xySeriesColor(th *theme.Theme, colorIndex int) string {
+	if len(th.XYChartColors) == 0 {
+		return "#4C78A8" // fallback
+	}
+	return th.XYChartColors[colorIndex%len(th.XYChartColors)]
+}
+
+// renderXYLegend draws a key and name for each legend entry: a filled
+// square for bar series and a short line with a point for line series.
+func renderXYLegend(builder *svgBuilder, xyd *layout.XYChartData, th *theme.Theme, cfg *config.Layout) {
+	for _, entry := range xyd.Legend {
+		color := xySeriesColor(th, entry.ColorIndex)
+		midY := entry.Y + xyd.LegendKey/2
+		if entry.Type == ir.XYSeriesLine {
+			builder.line(entry.X, midY, entry.X+xyd.LegendKey, midY,
+				"class", "xychart-legend-key",
+				"stroke", color,
+				"stroke-width", "2",
+			)
+			builder.circle(entry.X+xyd.LegendKey/2, midY, 3, "fill", color)
+		} else {
+			builder.rect(entry.X, entry.Y, xyd.LegendKey, xyd.LegendKey, 0,
+				"class", "xychart-legend-key",
+				"fill", color,
+				"stroke", "none",
+			)
+		}
+		builder.text(entry.TextX, midY, entry.Text,
+			"class", "xychart-legend",
+			"dominant-baseline", "middle",
+			"font-family", th.FontFamily,
+			"font-size", fmtFloat(cfg.XYChart.AxisFontSize),
+			"fill", th.TextColor,
+		)
+	}
 }
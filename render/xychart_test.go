@@ -62,3 +62,56 @@ func TestRenderXYChartValidSVG(t *testing.T) {
 		t.Error("SVG doesn't end with </svg>")
 	}
 }
+
+func TestRenderXYChartLegendAndSecondaryAxis(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYStacked = true
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Name: "EU", Values: []float64{10, 20}},
+		{Type: ir.XYSeriesBar, Name: "US", Values: []float64{5, 15}},
+		{Type: ir.XYSeriesLine, Name: "Latency", Secondary: true, Values: []float64{300, 450}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	l := layout.ComputeLayout(graph, th, cfg)
+	svg := RenderSVG(l, th, cfg)
+
+	if got := strings.Count(svg, `class="xychart-legend-key"`); got != 3 {
+		t.Errorf("legend keys = %d, want 3", got)
+	}
+	for _, name := range []string{">EU<", ">US<", ">Latency<"} {
+		if !strings.Contains(svg, name) {
+			t.Errorf("missing legend text %s", name)
+		}
+	}
+	// The secondary axis is labelled up to its own nice maximum.
+	if !strings.Contains(svg, ">500<") {
+		t.Error("missing secondary axis tick 500")
+	}
+}
+
+func TestRenderXYChartDataLabels(t *testing.T) {
+	graph := ir.NewGraph()
+	graph.Kind = ir.XYChart
+	graph.XYDataLabels = true
+	graph.XYNumberFmt = ".1%"
+	graph.XYSeries = []*ir.XYSeries{
+		{Type: ir.XYSeriesBar, Values: []float64{0.25, 0.5}},
+		{Type: ir.XYSeriesLine, Values: []float64{0.125, 0.75}},
+	}
+
+	th := theme.Modern()
+	cfg := config.DefaultLayout()
+	svg := RenderSVG(layout.ComputeLayout(graph, th, cfg), th, cfg)
+
+	if got := strings.Count(svg, `class="xychart-data-label"`); got != 4 {
+		t.Errorf("data labels = %d, want 4", got)
+	}
+	for _, label := range []string{">25.0%<", ">12.5%<", ">75.0%<"} {
+		if !strings.Contains(svg, label) {
+			t.Errorf("missing data label %s", label)
+		}
+	}
+}
@@ -67,6 +67,13 @@ func (m *Measurer) AverageCharWidth(fontFamily string, fontSize float32) float32
 	return total / float32(len(sample))
 }
 
+// Font returns the font used to measure text in fontFamily, or nil when
+// widths for that family come from the character-width heuristic.
+// Renderers that draw glyphs use it to match the measured layout.
+func (m *Measurer) Font(fontFamily string) *sfnt.Font {
+	return m.loadFont(fontFamily)
+}
+
 // measure computes text width, trying system fonts first, then falling back
 // to a heuristic estimation.
 func (m *Measurer) measure(text string, fontSize float32, fontFamily string) float32 {
@@ -46,3 +46,10 @@ func TestAverageCharWidth(t *testing.T) {
 		t.Errorf("AverageCharWidth = %f, unexpectedly large", w)
 	}
 }
+
+func TestFontUnknownFamily(t *testing.T) {
+	m := New()
+	if f := m.Font("no-such-family-for-tests"); f != nil {
+		t.Errorf("Font(unknown) = %v, want nil", f)
+	}
+}